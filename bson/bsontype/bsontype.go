@@ -38,6 +38,7 @@ const (
 	BinaryUUIDOld     byte = 0x03
 	BinaryUUID        byte = 0x04
 	BinaryMD5         byte = 0x05
+	BinaryVector      byte = 0x09
 	BinaryUserDefined byte = 0x80
 )
 
@@ -34,9 +34,9 @@ var NilObjectID ObjectID
 var objectIDCounter = readRandomUint32()
 var processUnique = processUniqueBytes()
 
-// NewObjectID generates a new ObjectID.
+// NewObjectID generates a new ObjectID using the generator set by SetObjectIDGenerator.
 func NewObjectID() ObjectID {
-	return NewObjectIDFromTimestamp(time.Now())
+	return globalObjectIDGenerator.Load().(generatorHolder).gen.NewObjectID()
 }
 
 // NewObjectIDFromTimestamp generates a new ObjectID based on the given time.
@@ -0,0 +1,111 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package primitive
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ObjectIDGenerator is a source of ObjectIDs. Implementations must be safe for concurrent use.
+type ObjectIDGenerator interface {
+	NewObjectID() ObjectID
+}
+
+// ObjectIDGeneratorFunc is an adapter that allows a function to be used as an ObjectIDGenerator.
+type ObjectIDGeneratorFunc func() ObjectID
+
+// NewObjectID implements the ObjectIDGenerator interface.
+func (f ObjectIDGeneratorFunc) NewObjectID() ObjectID {
+	return f()
+}
+
+// generatorHolder wraps an ObjectIDGenerator so that it can be stored in an atomic.Value, which requires all stored
+// values to have the same concrete type.
+type generatorHolder struct {
+	gen ObjectIDGenerator
+}
+
+var defaultObjectIDGenerator ObjectIDGenerator = ObjectIDGeneratorFunc(func() ObjectID {
+	return NewObjectIDFromTimestamp(time.Now())
+})
+
+// globalObjectIDGenerator is read without locking by NewObjectID. globalObjectIDGeneratorLock serializes
+// SetObjectIDGenerator so that each call returns the generator it replaced.
+var (
+	globalObjectIDGenerator     atomic.Value // holds a generatorHolder
+	globalObjectIDGeneratorLock sync.Mutex
+)
+
+func init() {
+	globalObjectIDGenerator.Store(generatorHolder{gen: defaultObjectIDGenerator})
+}
+
+// SetObjectIDGenerator sets the generator used by NewObjectID, and therefore by the driver when it adds an _id to a
+// document, for the whole process. A nil generator restores the default generator, which uses the current time, a
+// random process-unique value, and a counter with a random start value. It returns the previous generator so that
+// tests can restore it.
+func SetObjectIDGenerator(gen ObjectIDGenerator) ObjectIDGenerator {
+	if gen == nil {
+		gen = defaultObjectIDGenerator
+	}
+
+	globalObjectIDGeneratorLock.Lock()
+	defer globalObjectIDGeneratorLock.Unlock()
+
+	prev := globalObjectIDGenerator.Load().(generatorHolder)
+	globalObjectIDGenerator.Store(generatorHolder{gen: gen})
+	return prev.gen
+}
+
+// generator is an ObjectIDGenerator that uses fixed process-unique bytes and a counter. If now is nil, the timestamp
+// of each ObjectID is the timestamp of the generator.
+type generator struct {
+	now           func() time.Time
+	timestamp     uint32
+	processUnique [5]byte
+	counter       uint32
+}
+
+func (g *generator) NewObjectID() ObjectID {
+	var b [12]byte
+
+	timestamp := g.timestamp
+	if g.now != nil {
+		timestamp = uint32(g.now().Unix())
+	}
+	binary.BigEndian.PutUint32(b[0:4], timestamp)
+	copy(b[4:9], g.processUnique[:])
+	putUint24(b[9:12], atomic.AddUint32(&g.counter, 1))
+
+	return b
+}
+
+// NewDeterministicObjectIDGenerator creates an ObjectIDGenerator that always generates the same sequence of ObjectIDs.
+// All of them have the given timestamp and zero process-unique bytes, and their counters start at 1. This is intended
+// for tests that compare inserted documents against golden files. The ObjectIDs are only unique among those created
+// by the same generator.
+func NewDeterministicObjectIDGenerator(timestamp time.Time) ObjectIDGenerator {
+	return &generator{timestamp: uint32(timestamp.Unix())}
+}
+
+// NewObjectIDGeneratorWithEntropy creates an ObjectIDGenerator that uses the current time like the default generator,
+// but reads the process-unique bytes and the start value of the counter from entropy instead of crypto/rand. It
+// returns an error if entropy cannot provide 9 bytes.
+func NewObjectIDGeneratorWithEntropy(entropy io.Reader) (ObjectIDGenerator, error) {
+	var b [9]byte
+	if _, err := io.ReadFull(entropy, b[:]); err != nil {
+		return nil, fmt.Errorf("cannot read ObjectID entropy: %v", err)
+	}
+	g := &generator{now: time.Now, counter: binary.LittleEndian.Uint32(b[5:9])}
+	copy(g.processUnique[:], b[0:5])
+	return g, nil
+}
@@ -0,0 +1,87 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package primitive
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeterministicObjectIDGenerator(t *testing.T) {
+	timestamp := time.Unix(1600000000, 0)
+	first := NewDeterministicObjectIDGenerator(timestamp)
+	second := NewDeterministicObjectIDGenerator(timestamp)
+
+	for i := 1; i <= 3; i++ {
+		id := first.NewObjectID()
+		require.Equal(t, second.NewObjectID(), id)
+		require.Equal(t, timestamp.UTC(), id.Timestamp())
+		require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, byte(i)}, id[4:])
+	}
+}
+
+func TestObjectIDGeneratorWithEntropy(t *testing.T) {
+	entropy := []byte{1, 2, 3, 4, 5, 9, 0, 0, 0}
+	gen, err := NewObjectIDGeneratorWithEntropy(bytes.NewReader(entropy))
+	require.NoError(t, err)
+
+	id := gen.NewObjectID()
+	require.Equal(t, []byte{1, 2, 3, 4, 5}, id[4:9])
+	require.Equal(t, []byte{0, 0, 10}, id[9:])
+	require.WithinDuration(t, time.Now(), id.Timestamp(), 2*time.Second)
+
+	_, err = NewObjectIDGeneratorWithEntropy(bytes.NewReader(entropy[:8]))
+	require.Error(t, err)
+}
+
+func TestSetObjectIDGenerator(t *testing.T) {
+	timestamp := time.Unix(1600000000, 0)
+	prev := SetObjectIDGenerator(NewDeterministicObjectIDGenerator(timestamp))
+	defer SetObjectIDGenerator(prev)
+
+	require.Equal(t, NewDeterministicObjectIDGenerator(timestamp).NewObjectID(), NewObjectID())
+
+	SetObjectIDGenerator(nil)
+	require.NotEqual(t, timestamp.UTC(), NewObjectID().Timestamp())
+}
+
+func TestSetObjectIDGeneratorConcurrently(t *testing.T) {
+	const n = 50
+	gens := make([]ObjectIDGenerator, n)
+	for i := range gens {
+		gens[i] = NewDeterministicObjectIDGenerator(time.Unix(int64(i), 0))
+	}
+	first := NewDeterministicObjectIDGenerator(time.Unix(n, 0))
+	initial := SetObjectIDGenerator(first)
+
+	prevs := make([]ObjectIDGenerator, n)
+	var wg sync.WaitGroup
+	for i := range gens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			prevs[i] = SetObjectIDGenerator(gens[i])
+			_ = NewObjectID()
+		}(i)
+	}
+	wg.Wait()
+	last := SetObjectIDGenerator(initial)
+
+	// every generator must have been replaced exactly once
+	replaced := make(map[ObjectIDGenerator]int)
+	for _, prev := range append(prevs, last) {
+		replaced[prev]++
+	}
+	require.Equal(t, 1, replaced[first], "expected the first generator to be replaced once")
+	for i, gen := range gens {
+		require.Equal(t, 1, replaced[gen], "expected generator %d to be replaced once", i)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package primitive
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// These constants are the data types that can be stored in a Vector. The value of each constant is the dtype byte
+// that is written at the start of the binary payload.
+const (
+	Int8Vector      byte = 0x03
+	Float32Vector   byte = 0x27
+	PackedBitVector byte = 0x10
+)
+
+// ErrVectorPaddingNotZero is returned when a non-zero padding is specified for a vector that is not a PackedBitVector or
+// that does not contain any data.
+var ErrVectorPaddingNotZero = errors.New("padding must be 0 for non-packed-bit vectors and empty vectors")
+
+// Vector represents a BSON binary vector value (binary subtype 9). A Vector stores a densely packed array of int8,
+// float32, or single-bit values and is primarily used to hold embeddings for Atlas Vector Search.
+type Vector struct {
+	dType      byte
+	int8Data   []int8
+	float32s   []float32
+	bitData    []byte
+	bitPadding uint8
+}
+
+// NewInt8Vector creates a Vector that holds the given int8 values.
+func NewInt8Vector(data []int8) Vector {
+	return Vector{dType: Int8Vector, int8Data: data}
+}
+
+// NewFloat32Vector creates a Vector that holds the given float32 values.
+func NewFloat32Vector(data []float32) Vector {
+	return Vector{dType: Float32Vector, float32s: data}
+}
+
+// NewPackedBitVector creates a Vector that holds single-bit values packed into bytes. The padding parameter is the
+// number of least-significant bits of the final byte that should be ignored and must be between 0 and 7. Padding must
+// be 0 if data is empty.
+func NewPackedBitVector(data []byte, padding uint8) (Vector, error) {
+	if padding > 7 {
+		return Vector{}, fmt.Errorf("padding must be between 0 and 7, got %d", padding)
+	}
+	if padding > 0 && len(data) == 0 {
+		return Vector{}, ErrVectorPaddingNotZero
+	}
+	return Vector{dType: PackedBitVector, bitData: data, bitPadding: padding}, nil
+}
+
+// NewVectorFromBinary creates a Vector from a Binary value. An error is returned if the Binary does not have subtype
+// BinaryVector or if its data is not a valid vector payload.
+func NewVectorFromBinary(b Binary) (Vector, error) {
+	if b.Subtype != bsontype.BinaryVector {
+		return Vector{}, fmt.Errorf("cannot create a Vector from a Binary with subtype %#x", b.Subtype)
+	}
+	if len(b.Data) < 2 {
+		return Vector{}, errors.New("insufficient bytes to decode vector: expected at least 2 bytes")
+	}
+
+	dType, padding, data := b.Data[0], b.Data[1], b.Data[2:]
+	switch dType {
+	case Int8Vector:
+		if padding != 0 {
+			return Vector{}, ErrVectorPaddingNotZero
+		}
+		values := make([]int8, len(data))
+		for i, v := range data {
+			values[i] = int8(v)
+		}
+		return NewInt8Vector(values), nil
+	case Float32Vector:
+		if padding != 0 {
+			return Vector{}, ErrVectorPaddingNotZero
+		}
+		if len(data)%4 != 0 {
+			return Vector{}, fmt.Errorf("float32 vector data length must be a multiple of 4, got %d", len(data))
+		}
+		values := make([]float32, len(data)/4)
+		for i := range values {
+			values[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+		}
+		return NewFloat32Vector(values), nil
+	case PackedBitVector:
+		bits := make([]byte, len(data))
+		copy(bits, data)
+		return NewPackedBitVector(bits, padding)
+	default:
+		return Vector{}, fmt.Errorf("unsupported vector data type %#x", dType)
+	}
+}
+
+// Type returns the data type of the vector. The returned value is one of Int8Vector, Float32Vector, or
+// PackedBitVector, or 0 for the zero Vector.
+func (v Vector) Type() byte { return v.dType }
+
+// Int8OK returns the int8 values of the vector and true if the vector is an Int8Vector. Otherwise it returns nil and
+// false.
+func (v Vector) Int8OK() ([]int8, bool) {
+	if v.dType != Int8Vector {
+		return nil, false
+	}
+	return v.int8Data, true
+}
+
+// Float32OK returns the float32 values of the vector and true if the vector is a Float32Vector. Otherwise it returns
+// nil and false.
+func (v Vector) Float32OK() ([]float32, bool) {
+	if v.dType != Float32Vector {
+		return nil, false
+	}
+	return v.float32s, true
+}
+
+// PackedBitOK returns the packed bytes and padding of the vector and true if the vector is a PackedBitVector. Otherwise
+// it returns nil, 0, and false.
+func (v Vector) PackedBitOK() ([]byte, uint8, bool) {
+	if v.dType != PackedBitVector {
+		return nil, 0, false
+	}
+	return v.bitData, v.bitPadding, true
+}
+
+// Equal compares v to v2 and returns true if they are equal.
+func (v Vector) Equal(v2 Vector) bool {
+	return v.Binary().Equal(v2.Binary())
+}
+
+// IsZero returns if v is the zero Vector.
+func (v Vector) IsZero() bool {
+	return v.dType == 0
+}
+
+// Binary returns the Binary representation of the vector.
+func (v Vector) Binary() Binary {
+	var data []byte
+	switch v.dType {
+	case Int8Vector:
+		data = make([]byte, 2, 2+len(v.int8Data))
+		data[0] = Int8Vector
+		for _, val := range v.int8Data {
+			data = append(data, byte(val))
+		}
+	case Float32Vector:
+		data = make([]byte, 2+4*len(v.float32s))
+		data[0] = Float32Vector
+		for i, val := range v.float32s {
+			binary.LittleEndian.PutUint32(data[2+i*4:], math.Float32bits(val))
+		}
+	case PackedBitVector:
+		data = make([]byte, 2, 2+len(v.bitData))
+		data[0] = PackedBitVector
+		data[1] = v.bitPadding
+		data = append(data, v.bitData...)
+	}
+	return Binary{Subtype: bsontype.BinaryVector, Data: data}
+}
+
+// MarshalBSONValue implements the bsoncodec.ValueMarshaler interface. The Vector is encoded as a BSON binary value
+// with subtype 9.
+func (v Vector) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if v.IsZero() {
+		return bsontype.Type(0), nil, errors.New("cannot marshal a zero Vector")
+	}
+
+	b := v.Binary()
+	buf := make([]byte, 5, 5+len(b.Data))
+	binary.LittleEndian.PutUint32(buf, uint32(len(b.Data)))
+	buf[4] = b.Subtype
+	buf = append(buf, b.Data...)
+	return bsontype.Binary, buf, nil
+}
+
+// UnmarshalBSONValue implements the bsoncodec.ValueUnmarshaler interface. Only BSON binary values with subtype 9 can be
+// unmarshalled into a Vector.
+func (v *Vector) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t != bsontype.Binary {
+		return fmt.Errorf("cannot unmarshal a BSON %s into a Vector", t)
+	}
+	if len(data) < 5 {
+		return errors.New("insufficient bytes to decode binary value")
+	}
+
+	length := int(binary.LittleEndian.Uint32(data))
+	if len(data) < 5+length {
+		return errors.New("insufficient bytes to decode binary value")
+	}
+
+	vec, err := NewVectorFromBinary(Binary{Subtype: data[4], Data: data[5 : 5+length]})
+	if err != nil {
+		return err
+	}
+	*v = vec
+	return nil
+}
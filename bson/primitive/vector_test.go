@@ -0,0 +1,58 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package primitive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+func TestVector(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		packed, err := NewPackedBitVector([]byte{0xFF, 0xF0}, 4)
+		require.NoError(t, err)
+
+		testcases := []struct {
+			name string
+			vec  Vector
+		}{
+			{"int8", NewInt8Vector([]int8{-128, 0, 127})},
+			{"float32", NewFloat32Vector([]float32{1.5, -0.25, 3})},
+			{"packed bit", packed},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				typ, data, err := tc.vec.MarshalBSONValue()
+				require.NoError(t, err)
+				require.Equal(t, bsontype.Binary, typ)
+
+				var got Vector
+				require.NoError(t, got.UnmarshalBSONValue(typ, data))
+				require.Equal(t, tc.vec, got)
+			})
+		}
+	})
+	t.Run("binary layout", func(t *testing.T) {
+		b := NewInt8Vector([]int8{-1, 1}).Binary()
+		require.Equal(t, bsontype.BinaryVector, b.Subtype)
+		require.Equal(t, []byte{Int8Vector, 0, 0xFF, 0x01}, b.Data)
+	})
+	t.Run("invalid padding", func(t *testing.T) {
+		_, err := NewPackedBitVector([]byte{0x01}, 8)
+		require.Error(t, err)
+		_, err = NewPackedBitVector(nil, 1)
+		require.Equal(t, ErrVectorPaddingNotZero, err)
+		_, err = NewVectorFromBinary(Binary{Subtype: bsontype.BinaryVector, Data: []byte{Float32Vector, 1}})
+		require.Equal(t, ErrVectorPaddingNotZero, err)
+	})
+	t.Run("wrong subtype", func(t *testing.T) {
+		_, err := NewVectorFromBinary(Binary{Subtype: bsontype.BinaryGeneric, Data: []byte{Int8Vector, 0}})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package clock defines the source of time used by the driver for heartbeats, round trip time measurements, server
+// selection timeouts, and the expiry of server sessions. A Clock can be set with ClientOptions.SetClock. The Fake
+// clock only moves when it is advanced, so time-dependent behavior can be tested without sleeping.
+package clock // import "go.mongodb.org/mongo-driver/clock"
+
+import "time"
+
+// Clock is a source of time. Implementations must be safe for concurrent use.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer creates a Timer that sends the current time on its channel after at least the duration d.
+	NewTimer(d time.Duration) Timer
+	// NewTicker creates a Ticker that sends the current time on its channel every period d. It panics if d is not
+	// positive.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a single event created by Clock.NewTimer. It behaves like a time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered.
+	C() <-chan time.Time
+	// Stop prevents the Timer from firing. It returns false if the timer has already expired or been stopped.
+	Stop() bool
+	// Reset changes the timer to expire after the duration d. It returns true if the timer had been active.
+	Reset(d time.Duration) bool
+}
+
+// Ticker delivers ticks at intervals and is created by Clock.NewTicker. It behaves like a time.Ticker.
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the ticker.
+	Stop()
+}
+
+// System is the Clock backed by the time package. It is used when no Clock is configured.
+var System Clock = systemClock{}
+
+// Since returns the time elapsed since t according to c. If c is nil, System is used.
+func Since(c Clock, t time.Time) time.Duration {
+	return OrSystem(c).Now().Sub(t)
+}
+
+// OrSystem returns c, or System if c is nil.
+func OrSystem(c Clock) Clock {
+	if c == nil {
+		return System
+	}
+	return c
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
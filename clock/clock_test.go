@@ -0,0 +1,109 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+var start = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// received returns the value on c, or false if there is none.
+func received(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-c:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestSystem(t *testing.T) {
+	before := time.Now()
+	now := System.Now()
+	assert.False(t, now.Before(before), "expected %v to not be before %v", now, before)
+	assert.True(t, Since(nil, before) >= 0, "expected a non-negative duration")
+
+	timer := System.NewTimer(time.Millisecond)
+	<-timer.C()
+	assert.False(t, timer.Stop(), "expected Stop to report an expired timer")
+	ticker := System.NewTicker(time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+}
+
+func TestFake(t *testing.T) {
+	t.Run("now", func(t *testing.T) {
+		f := NewFake(start)
+		assert.Equal(t, start, f.Now(), "expected %v, got %v", start, f.Now())
+		f.Advance(time.Minute)
+		assert.Equal(t, time.Minute, Since(f, start), "expected 1m, got %v", Since(f, start))
+		f.Set(start)
+		assert.Equal(t, start.Add(time.Minute), f.Now(), "expected the clock to not move backwards, got %v", f.Now())
+	})
+	t.Run("timer", func(t *testing.T) {
+		f := NewFake(start)
+		timer := f.NewTimer(time.Second)
+		assert.Equal(t, 1, f.Waiters(), "expected 1 waiter, got %d", f.Waiters())
+
+		f.Advance(999 * time.Millisecond)
+		_, ok := received(timer.C())
+		assert.False(t, ok, "expected the timer to not fire early")
+		f.Advance(time.Second)
+		got, ok := received(timer.C())
+		assert.True(t, ok, "expected the timer to fire")
+		assert.Equal(t, start.Add(time.Second), got, "expected the deadline as the fire time, got %v", got)
+		assert.Equal(t, 0, f.Waiters(), "expected no waiters, got %d", f.Waiters())
+		assert.False(t, timer.Stop(), "expected Stop to report an expired timer")
+
+		assert.False(t, timer.Reset(time.Second), "expected Reset to report an expired timer")
+		assert.True(t, timer.Stop(), "expected Stop to report an active timer")
+		f.Advance(time.Hour)
+		_, ok = received(timer.C())
+		assert.False(t, ok, "expected a stopped timer to not fire")
+
+		immediate := f.NewTimer(0)
+		_, ok = received(immediate.C())
+		assert.True(t, ok, "expected a timer with no duration to fire immediately")
+	})
+	t.Run("ticker", func(t *testing.T) {
+		f := NewFake(start)
+		ticker := f.NewTicker(time.Second)
+		timer := f.NewTimer(1500 * time.Millisecond)
+
+		f.Advance(time.Second)
+		_, ok := received(ticker.C())
+		assert.True(t, ok, "expected a tick")
+		// ticks are dropped when the receiver falls behind
+		f.Advance(3 * time.Second)
+		got, ok := received(ticker.C())
+		assert.True(t, ok, "expected a tick")
+		assert.Equal(t, start.Add(2*time.Second), got, "expected the first undelivered tick, got %v", got)
+		got, ok = received(timer.C())
+		assert.True(t, ok, "expected the timer to fire")
+		assert.Equal(t, start.Add(1500*time.Millisecond), got, "expected the timer deadline, got %v", got)
+
+		ticker.Stop()
+		f.Advance(time.Minute)
+		_, ok = received(ticker.C())
+		assert.False(t, ok, "expected a stopped ticker to not tick")
+	})
+	t.Run("BlockUntil", func(t *testing.T) {
+		f := NewFake(start)
+		fired := make(chan time.Time)
+		go func() {
+			fired <- <-f.NewTimer(time.Minute).C()
+		}()
+		f.BlockUntil(1)
+		f.Advance(time.Minute)
+		got := <-fired
+		assert.Equal(t, start.Add(time.Minute), got, "expected %v, got %v", start.Add(time.Minute), got)
+	})
+}
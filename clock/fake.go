@@ -0,0 +1,202 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only changes when Advance or Set is called. Timers and tickers created by a Fake fire
+// during those calls, in the order of their deadlines. Like the channels of the time package, their channels have a
+// buffer of one and ticks are dropped if the receiver falls behind.
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+var _ Clock = &Fake{}
+
+// NewFake creates a Fake clock set to now.
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now implements the Clock interface.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// NewTimer implements the Clock interface. A timer with a non-positive duration fires immediately.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{clock: f, c: make(chan time.Time, 1)}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.schedule(w, d)
+	return fakeTimer{w}
+}
+
+// NewTicker implements the Clock interface.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{clock: f, c: make(chan time.Time, 1), period: d}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.schedule(w, d)
+	return fakeTicker{w}
+}
+
+// Advance moves the clock forward by d and fires the timers and tickers that expire in that time.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.advanceTo(f.now.Add(d))
+}
+
+// Set moves the clock to t and fires the timers and tickers that expire until then. The clock cannot move backwards;
+// a t before the current time is ignored.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if t.After(f.now) {
+		f.advanceTo(t)
+	}
+}
+
+// Waiters returns the number of timers and tickers that are active.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.waiters)
+}
+
+// BlockUntil blocks until at least n timers and tickers are active. This allows a test to wait until the code under
+// test has started waiting before advancing the clock.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// schedule activates w to expire after d. The caller must hold f.mu.
+func (f *Fake) schedule(w *fakeWaiter, d time.Duration) {
+	w.deadline = f.now.Add(d)
+	if d <= 0 && w.period == 0 {
+		w.fire(f.now)
+		return
+	}
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+}
+
+// remove deactivates w and returns whether it was active. The caller must hold f.mu.
+func (f *Fake) remove(w *fakeWaiter) bool {
+	for i, other := range f.waiters {
+		if other == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// advanceTo fires the waiters that expire until t in the order of their deadlines and then sets the time to t. The
+// caller must hold f.mu.
+func (f *Fake) advanceTo(t time.Time) {
+	for {
+		var next *fakeWaiter
+		for _, w := range f.waiters {
+			if !w.deadline.After(t) && (next == nil || w.deadline.Before(next.deadline)) {
+				next = w
+			}
+		}
+		if next == nil {
+			break
+		}
+		f.now = next.deadline
+		next.fire(f.now)
+		if next.period > 0 {
+			next.deadline = next.deadline.Add(next.period)
+		} else {
+			f.remove(next)
+		}
+	}
+	f.now = t
+}
+
+// fakeWaiter is a timer or ticker of a Fake clock.
+type fakeWaiter struct {
+	clock    *Fake
+	c        chan time.Time
+	deadline time.Time
+	period   time.Duration
+}
+
+func (w *fakeWaiter) fire(now time.Time) {
+	select {
+	case w.c <- now:
+	default:
+	}
+}
+
+func (w *fakeWaiter) stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+
+	return w.clock.remove(w)
+}
+
+type fakeTimer struct {
+	*fakeWaiter
+}
+
+func (t fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t fakeTimer) Stop() bool {
+	return t.stop()
+}
+
+func (t fakeTimer) Reset(d time.Duration) bool {
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	active := f.remove(t.fakeWaiter)
+	f.schedule(t.fakeWaiter, d)
+	return active
+}
+
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+func (t fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t fakeTicker) Stop() {
+	t.stop()
+}
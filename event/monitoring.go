@@ -8,8 +8,11 @@ package event // import "go.mongodb.org/mongo-driver/event"
 
 import (
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/tag"
 )
 
 // CommandStartedEvent represents an event generated when a command is sent to a server.
@@ -19,6 +22,17 @@ type CommandStartedEvent struct {
 	CommandName  string
 	RequestID    int64
 	ConnectionID string
+	// ServerConnectionID is the server's ID for the connection, or nil if the server did not report one.
+	ServerConnectionID *int32
+	// ServiceID is the ID of the backend server behind a load balancer, or nil if the deployment is not load balanced.
+	ServiceID *primitive.ObjectID
+	// Compressor is the name of the compressor used for the command, or empty if it was sent uncompressed.
+	Compressor string
+	// Retry is true if the command is a retry of a command that failed with a retryable error.
+	Retry bool
+	// WireMessage is the wire message sent to the server. It is only set if CommandMonitor.IncludeWireMessages is
+	// true and the command is not redacted.
+	WireMessage []byte
 }
 
 // CommandFinishedEvent represents a generic command finishing.
@@ -27,6 +41,19 @@ type CommandFinishedEvent struct {
 	CommandName   string
 	RequestID     int64
 	ConnectionID  string
+	// ServerConnectionID is the server's ID for the connection, or nil if the server did not report one.
+	ServerConnectionID *int32
+	// ServiceID is the ID of the backend server behind a load balancer, or nil if the deployment is not load balanced.
+	ServiceID *primitive.ObjectID
+	// ReplySize is the size in bytes of the reply wire message, or 0 if no reply was read.
+	ReplySize int
+	// Compressor is the name of the compressor used for the reply, or empty if it was not compressed.
+	Compressor string
+	// Retry is true if the command is a retry of a command that failed with a retryable error.
+	Retry bool
+	// WireMessage is the reply wire message. It is only set if CommandMonitor.IncludeWireMessages is true and the
+	// command is not redacted.
+	WireMessage []byte
 }
 
 // CommandSucceededEvent represents an event generated when a command's execution succeeds.
@@ -39,6 +66,9 @@ type CommandSucceededEvent struct {
 type CommandFailedEvent struct {
 	CommandFinishedEvent
 	Failure string
+	// Code is the error code returned by the server, or 0 if the command failed without a server error, e.g. because
+	// of a network error.
+	Code int32
 }
 
 // CommandMonitor represents a monitor that is triggered for different events.
@@ -46,6 +76,25 @@ type CommandMonitor struct {
 	Started   func(context.Context, *CommandStartedEvent)
 	Succeeded func(context.Context, *CommandSucceededEvent)
 	Failed    func(context.Context, *CommandFailedEvent)
+	// IncludeWireMessages specifies whether the raw wire messages of commands and replies are copied into events.
+	// Copying wire messages is expensive, so this should only be enabled for debugging.
+	IncludeWireMessages bool
+	// Filter, if set, is called with the name, database, and collection of each command before its events are
+	// constructed, and the events of the command are only published if it returns true. The collection is empty for
+	// commands whose first value is not a collection name, such as getMore.
+	Filter func(commandName, database, collection string) bool
+	// Sampler, if set, decides for each command that passes the Filter whether its events are published. The started
+	// and finished events of a command are either both published or both skipped.
+	Sampler Sampler
+	// RedactedCommands lists the names of commands whose commands and replies are left out of events, in addition to
+	// the security-sensitive commands that are always redacted, such as authentication commands. Names are compared
+	// case-insensitively.
+	RedactedCommands []string
+	// Redact, if set, is called with the name of the command and a copy of each command and reply that is not redacted
+	// before it is added to an event. The returned document is used in its place, so Redact can remove or mask
+	// individual fields; returning nil leaves the document out of the event. Wire messages cannot be partially
+	// redacted, so they are never included in events if Redact is set.
+	Redact func(commandName string, doc bson.Raw) bson.Raw
 }
 
 // strings for pool command monitoring reasons
@@ -55,6 +104,7 @@ const (
 	ReasonStale             = "stale"
 	ReasonConnectionErrored = "connectionError"
 	ReasonTimedOut          = "timeout"
+	ReasonRebalanced        = "rebalanced"
 )
 
 // strings for pool command monitoring types
@@ -67,6 +117,8 @@ const (
 	ConnectionReturned = "ConnectionCheckedIn"
 	PoolCleared        = "ConnectionPoolCleared"
 	PoolClosedEvent    = "ConnectionPoolClosed"
+	CheckOutQueued     = "ConnectionCheckOutQueued"
+	ConnectionReady    = "ConnectionReady"
 )
 
 // MonitorPoolOptions contains pool options as formatted in pool events
@@ -83,9 +135,370 @@ type PoolEvent struct {
 	ConnectionID uint64              `json:"connectionId"`
 	PoolOptions  *MonitorPoolOptions `json:"options"`
 	Reason       string              `json:"reason"`
+	// ServiceID is only set if the Type is PoolCleared and the server is deployed behind a load balancer. This field
+	// can be used to distinguish between individual servers in a load balanced deployment.
+	ServiceID *primitive.ObjectID `json:"serviceId"`
+
+	// WaitQueueLength is the number of check outs waiting because the maximum pool size has been reached. It is set
+	// for ConnectionCheckOutStarted, CheckOutQueued, GetSucceeded, and GetFailed events. A CheckOutQueued event is
+	// published each time a check out starts waiting, so the length of the wait queue can be tracked over time.
+	WaitQueueLength uint64 `json:"waitQueueLength"`
+	// Duration is the total time taken by the check out for GetSucceeded and GetFailed events, and the time taken to
+	// establish the connection for ConnectionReady events. It is not set for other events.
+	Duration time.Duration `json:"duration"`
+	// WaitQueueDuration is the time the check out spent in the wait queue and EstablishDuration is the time it spent
+	// establishing a new connection, which is zero if an idle connection was checked out. Both are only set for
+	// GetSucceeded and GetFailed events.
+	WaitQueueDuration time.Duration `json:"waitQueueDuration"`
+	EstablishDuration time.Duration `json:"establishDuration"`
+	// Timings breaks down the time taken to establish the connection by phase. It is only set for ConnectionReady
+	// events.
+	Timings *ConnectionTimings `json:"timings"`
+}
+
+// ConnectionTimings holds the time spent in each phase of establishing a connection. Phases that were not performed,
+// such as the TLS handshake for connections without TLS, are zero.
+type ConnectionTimings struct {
+	// DNS is the time spent resolving the host name of the server. It is only measured by dialers that use the
+	// standard library resolver, such as the default dialer, so it is zero for custom and proxy dialers.
+	DNS time.Duration `json:"dns"`
+	// TCPConnect is the time spent dialing the server, excluding the DNS lookup.
+	TCPConnect time.Duration `json:"tcpConnect"`
+	// TLS is the time spent in the TLS handshake.
+	TLS time.Duration `json:"tls"`
+	// Hello is the time spent in the initial handshake command that describes the server.
+	Hello time.Duration `json:"hello"`
+	// Auth is the time spent authenticating the connection.
+	Auth time.Duration `json:"auth"`
+}
+
+// PoolStats is a snapshot of the state of the connection pool for a single server.
+type PoolStats struct {
+	Address string
+
+	// InUse is the number of connections that are checked out of the pool.
+	InUse uint64
+	// Idle is the number of connections that are available in the pool.
+	Idle uint64
+	// Pending is the number of connections that are being established.
+	Pending uint64
+	// WaitQueueLength is the number of check outs that are waiting because the maximum pool size has been reached.
+	WaitQueueLength uint64
+
+	// CheckOutWaitP50, CheckOutWaitP90, and CheckOutWaitP99 are percentiles of the time taken by recent successful
+	// connection check outs, including any time spent in the wait queue and establishing a new connection.
+	CheckOutWaitP50 time.Duration
+	CheckOutWaitP90 time.Duration
+	CheckOutWaitP99 time.Duration
+	// WaitQueueP50, WaitQueueP90, and WaitQueueP99 are percentiles of the time spent in the wait queue by recent
+	// successful connection check outs.
+	WaitQueueP50 time.Duration
+	WaitQueueP90 time.Duration
+	WaitQueueP99 time.Duration
+	// EstablishP50, EstablishP90, and EstablishP99 are percentiles of the time taken to establish the new connections
+	// used by recent successful check outs, including the connection handshake and authentication.
+	EstablishP50 time.Duration
+	EstablishP90 time.Duration
+	EstablishP99 time.Duration
+
+	// Created is the total number of connections created by the pool.
+	Created uint64
+	// Closed is the total number of connections closed by the pool, keyed by the reason the connection was closed
+	// (e.g. ReasonIdle).
+	Closed map[string]uint64
+}
+
+// SessionPoolStats is a snapshot of the state of the server session pool of a client.
+type SessionPoolStats struct {
+	// CheckedOut is the number of server sessions that are in use by explicit or implicit sessions.
+	CheckedOut uint64
+	// Pooled is the number of server sessions that are available in the pool.
+	Pooled uint64
+	// Expired is the total number of server sessions that have been discarded because they expired.
+	Expired uint64
 }
 
 // PoolMonitor is a function that allows the user to gain access to events occurring in the pool
 type PoolMonitor struct {
 	Event func(*PoolEvent)
+	// Filter, if set, is called with the type of each event, e.g. ConnectionCreated, and the address of the server
+	// before the event is constructed, and the event is only published if it returns true.
+	Filter func(eventType, address string) bool
+	// Sampler, if set, decides for each event that passes the Filter whether it is published.
+	Sampler Sampler
+}
+
+// ServerDescription is a snapshot of what the driver knows about a single server in a deployment.
+type ServerDescription struct {
+	Address string
+	// Kind is the type of the server, e.g. "RSPrimary", "RSSecondary", "Mongos", or "Unknown" if the server has not
+	// been successfully checked.
+	Kind string
+	// SetName is the name of the replica set the server belongs to, if any.
+	SetName string
+	// Tags are the replica set tags of the server.
+	Tags tag.Set
+
+	// AverageRTT is the moving average of the round trip time to the server. It is zero if no round trip time has
+	// been measured.
+	AverageRTT time.Duration
+	// LastError is the error from the most recent check of the server, or nil if the check succeeded.
+	LastError error
+	// LastUpdateTime is the time the description of the server was last updated.
+	LastUpdateTime time.Time
+
+	// MinWireVersion and MaxWireVersion are the range of wire protocol versions supported by the server. Both are zero
+	// if the server has not been successfully checked.
+	MinWireVersion int32
+	MaxWireVersion int32
+}
+
+// RTTStats is a snapshot of the round trip times measured by the heartbeats of a single server.
+type RTTStats struct {
+	Address string
+
+	// Current is the round trip time of the most recent successful heartbeat.
+	Current time.Duration
+	// Average is the moving average of the round trip time that is used for server selection.
+	Average time.Duration
+	// Min and P90 are the minimum and 90th percentile of the round trip times of recent successful heartbeats.
+	Min time.Duration
+	P90 time.Duration
+	// Samples is the number of recent heartbeats that Min and P90 are computed from. If it is zero, no round trip time
+	// has been measured yet.
+	Samples int
+}
+
+// ServerError is an error returned by an operation or heartbeat run against a server.
+type ServerError struct {
+	Address string
+	// Time is when the error was returned.
+	Time time.Time
+	// Source is "operation" for errors returned by commands and "heartbeat" for errors returned by server checks.
+	Source string
+	Error  error
+}
+
+// TopologyDescription is a snapshot of what the driver knows about a deployment.
+type TopologyDescription struct {
+	// Kind is the type of the deployment, e.g. "Single", "ReplicaSetWithPrimary", "Sharded", or "Unknown" if it has
+	// not been determined yet.
+	Kind string
+	// Servers contains a description of each server in the deployment, ordered by address.
+	Servers []ServerDescription
+}
+
+// SRVHostsChangedEvent represents an event generated when polling the SRV records of a "mongodb+srv" URI discovers
+// that hosts have been added to or removed from the deployment.
+type SRVHostsChangedEvent struct {
+	// Added contains the addresses of the hosts that were added to the topology.
+	Added []string
+	// Removed contains the addresses of the hosts that were removed from the topology.
+	Removed []string
+}
+
+// ServerDescriptionChangedEvent represents an event generated when a heartbeat changes the type, replica set name,
+// tags, supported wire versions, or error of a server. Changes to only the round trip time of a server do not generate
+// an event.
+type ServerDescriptionChangedEvent struct {
+	Address             string
+	PreviousDescription ServerDescription
+	NewDescription      ServerDescription
+	// RTT contains the round trip times measured by the heartbeats of the server when the event was generated.
+	RTT RTTStats
+}
+
+// TopologyMonitor represents a monitor that is triggered for changes to the topology.
+type TopologyMonitor struct {
+	SRVHostsChanged          func(*SRVHostsChangedEvent)
+	ServerDescriptionChanged func(*ServerDescriptionChangedEvent)
+}
+
+// ServerSelectionStartedEvent represents an event generated when an operation starts selecting a server.
+type ServerSelectionStartedEvent struct {
+	// Selector is a description of the server selector, including the read preference of the operation, if any.
+	Selector string
+}
+
+// ServerSelectionWaitingEvent represents an event generated when no server in the current description of a deployment
+// is suitable for an operation, so server selection waits for the description to change.
+type ServerSelectionWaitingEvent struct {
+	Selector string
+	// RemainingTime is the time left before server selection times out, or zero if there is no timeout.
+	RemainingTime time.Duration
+}
+
+// ServerSelectionSucceededEvent represents an event generated when a server is selected for an operation.
+type ServerSelectionSucceededEvent struct {
+	Selector string
+	// Address is the address of the selected server.
+	Address string
+	// Candidates contains the addresses of the servers that were suitable for the operation, from which the selected
+	// server was chosen.
+	Candidates []string
+	// Duration is the total time taken to select the server, including any time spent waiting.
+	Duration time.Duration
+}
+
+// ServerSelectionFailedEvent represents an event generated when server selection fails, e.g. because no suitable
+// server was found before the server selection timeout.
+type ServerSelectionFailedEvent struct {
+	Selector string
+	Failure  error
+	// Candidates contains the addresses of the servers that were suitable for the operation in the last description
+	// of the deployment that was checked. It is usually empty.
+	Candidates []string
+	// TopologyDescription is a description of the deployment when server selection failed.
+	TopologyDescription string
+	Duration            time.Duration
+}
+
+// ServerSelectionMonitor represents a monitor that is triggered for the server selection of operations.
+type ServerSelectionMonitor struct {
+	Started   func(*ServerSelectionStartedEvent)
+	Waiting   func(*ServerSelectionWaitingEvent)
+	Succeeded func(*ServerSelectionSucceededEvent)
+	Failed    func(*ServerSelectionFailedEvent)
+}
+
+// ServerHeartbeatStartedEvent represents an event generated when a heartbeat is sent to a server.
+type ServerHeartbeatStartedEvent struct {
+	// ConnectionID is the address of the server followed by the ID of the monitoring connection, e.g.
+	// "db.example.com:27017[-3]".
+	ConnectionID string
+	// Awaited is true if the heartbeat waits for the server to report a change in its state, as in the streaming
+	// monitoring protocol. Heartbeats are currently always polled, so it is false.
+	Awaited bool
+}
+
+// ServerHeartbeatSucceededEvent represents an event generated when a heartbeat succeeds.
+type ServerHeartbeatSucceededEvent struct {
+	DurationNanos int64
+	// Reply is the hello or legacy hello reply of the server.
+	Reply        bson.Raw
+	ConnectionID string
+	Awaited      bool
+}
+
+// ServerHeartbeatFailedEvent represents an event generated when a heartbeat fails.
+type ServerHeartbeatFailedEvent struct {
+	DurationNanos int64
+	Failure       error
+	ConnectionID  string
+	Awaited       bool
+}
+
+// ServerMonitor represents a monitor that is triggered for the heartbeats sent to the servers of a deployment.
+type ServerMonitor struct {
+	ServerHeartbeatStarted   func(*ServerHeartbeatStartedEvent)
+	ServerHeartbeatSucceeded func(*ServerHeartbeatSucceededEvent)
+	ServerHeartbeatFailed    func(*ServerHeartbeatFailedEvent)
+}
+
+// CursorLeakEvent represents an event generated when a cursor has been open for longer than the cursor leak threshold
+// configured for a Client without being closed or exhausted.
+type CursorLeakEvent struct {
+	CursorID  int64
+	Namespace string
+	Created   time.Time
+	Age       time.Duration
+	// Stack is the stack trace of the goroutine that created the cursor.
+	Stack string
+}
+
+// CursorMonitor represents a monitor that is triggered for cursor leak events.
+type CursorMonitor struct {
+	Leaked func(*CursorLeakEvent)
+}
+
+// FailoverEvent represents an event generated when a failover client switches the cluster that operations are sent to.
+type FailoverEvent struct {
+	// Previous and Current are the names of the clusters that operations were sent to before and after the switch.
+	Previous string
+	Current  string
+	// Reason is "failover" if Previous failed its health checks, "failback" if operations were moved back to a
+	// preferred cluster that became healthy again, or "manual" if the switch was requested by the application.
+	Reason string
+	// Error is the error returned by the last failed health check of Previous. It is nil unless Reason is "failover".
+	Error error
+}
+
+// FailoverMonitor represents a monitor that is triggered when a failover client switches clusters.
+type FailoverMonitor struct {
+	Failover func(*FailoverEvent)
+}
+
+// SlowOperationEvent represents an event generated when a command takes longer than the slow operation threshold
+// configured for a Client.
+type SlowOperationEvent struct {
+	CommandName string
+	// Namespace is "<database>.<collection>" for commands that operate on a collection and "<database>" otherwise.
+	Namespace string
+	// Duration is the time from sending the command to receiving its reply.
+	Duration time.Duration
+	// Address is the address of the server the command was sent to.
+	Address string
+	// CommandSummary is the command as extended JSON with every value replaced by "?", so it shows the shape of the
+	// command without the data in it. It is empty for security-sensitive commands such as authentication commands.
+	CommandSummary string
+}
+
+// SlowOperationMonitor represents a monitor that is triggered for slow operation events.
+type SlowOperationMonitor struct {
+	SlowOperation func(*SlowOperationEvent)
+}
+
+// strings for transaction event types
+const (
+	TransactionStarted         = "TransactionStarted"
+	TransactionCommitStarted   = "TransactionCommitStarted"
+	TransactionCommitSucceeded = "TransactionCommitSucceeded"
+	TransactionCommitFailed    = "TransactionCommitFailed"
+	TransactionAborted         = "TransactionAborted"
+	TransactionRetried         = "TransactionRetried"
+)
+
+// TransactionEvent represents an event generated during the lifecycle of a transaction started in a Session.
+type TransactionEvent struct {
+	// Type is one of the transaction event type constants, e.g. TransactionStarted.
+	Type string
+	// TxnNumber is the transaction number of the transaction in its session.
+	TxnNumber int64
+	// Duration is the time since the transaction was started.
+	Duration time.Duration
+	// Reason is the error label that caused a TransactionRetried event: "TransientTransactionError" if the whole
+	// transaction is run again, or "UnknownTransactionCommitResult" if only the commit is retried. It is empty for the
+	// other event types.
+	Reason string
+	// Error is the error that caused a TransactionCommitFailed or TransactionRetried event.
+	Error error
+}
+
+// TransactionMonitor represents a monitor that is triggered for transaction events.
+type TransactionMonitor struct {
+	Event func(*TransactionEvent)
+}
+
+// strings for commit progress stages
+const (
+	CommitSent                = "CommitSent"
+	CommitWTimeoutApproaching = "CommitWTimeoutApproaching"
+	CommitRetriedWithMajority = "CommitRetriedWithMajority"
+)
+
+// CommitProgressEvent represents the progress of a commitTransaction command.
+type CommitProgressEvent struct {
+	// Stage is one of the commit progress stage constants. CommitSent is reported when the commitTransaction command
+	// is sent to the server, CommitRetriedWithMajority when the commit is retried, which always uses a write concern
+	// of "majority", and CommitWTimeoutApproaching when 80% of the wtimeout of the write concern has elapsed without a
+	// reply.
+	Stage string
+	// TxnNumber is the transaction number of the transaction in its session.
+	TxnNumber int64
+	// Attempt is 1 for the first commit of a transaction and is incremented each time the commit is retried.
+	Attempt int
+	// Elapsed is the time since the commit attempt was started.
+	Elapsed time.Duration
+	// WTimeout is the wtimeout of the write concern used for the commit attempt, or 0 if there is none.
+	WTimeout time.Duration
 }
@@ -0,0 +1,51 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package event
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// Sampler decides whether the events of a command or a connection pool action are published to a monitor. Sampling
+// decisions are made before events are constructed, so commands and actions that are not sampled do not allocate
+// events. Implementations must be safe for concurrent use.
+type Sampler interface {
+	Sample() bool
+}
+
+type rateSampler struct {
+	n     uint64
+	count uint64
+}
+
+// RateSampler returns a Sampler that samples the first of every n decisions. If n is 0 or 1, every decision is
+// sampled.
+func RateSampler(n uint64) Sampler {
+	return &rateSampler{n: n}
+}
+
+// Sample implements the Sampler interface.
+func (s *rateSampler) Sample() bool {
+	if s.n <= 1 {
+		return true
+	}
+	return atomic.AddUint64(&s.count, 1)%s.n == 1
+}
+
+type probabilitySampler float64
+
+// ProbabilitySampler returns a Sampler that samples each decision independently with probability p. A p of 0 or less
+// samples nothing, and a p of 1 or more samples everything.
+func ProbabilitySampler(p float64) Sampler {
+	return probabilitySampler(p)
+}
+
+// Sample implements the Sampler interface.
+func (p probabilitySampler) Sample() bool {
+	return rand.Float64() < float64(p)
+}
@@ -0,0 +1,50 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package event // import "go.mongodb.org/mongo-driver/event"
+
+import "context"
+
+// Span attribute keys set by the driver. The keys follow the OpenTelemetry semantic conventions for database clients.
+const (
+	AttributeDBSystem      = "db.system"
+	AttributeDBName        = "db.name"
+	AttributeDBOperation   = "db.operation"
+	AttributeDBCollection  = "db.mongodb.collection"
+	AttributeNetPeerName   = "net.peer.name"
+	AttributeNetPeerPort   = "net.peer.port"
+	AttributeRetryAttempts = "db.mongodb.retry_attempts"
+)
+
+// SpanAttribute is a key-value pair describing a span.
+type SpanAttribute struct {
+	Key string
+	// Value is a string, int, or bool.
+	Value interface{}
+}
+
+// Span represents a single traced unit of work, such as an operation or a transaction.
+type Span interface {
+	// SetName replaces the name the span was started with.
+	SetName(name string)
+	SetAttributes(attrs ...SpanAttribute)
+	// RecordError records that the work represented by the span failed with err.
+	RecordError(err error)
+	End()
+}
+
+// Tracer creates spans for the operations and transactions run by a Client. The driver starts one span for each
+// operation, named after the command and namespace (e.g. "find test.coll"), and one span for each transaction. The
+// span of an operation run in a transaction is linked to the span of the transaction.
+//
+// The driver does not depend on a tracing library. To export spans to OpenTelemetry, implement Tracer and Span by
+// wrapping an OpenTelemetry trace.Tracer and trace.Span, converting SpanAttribute values to attribute.KeyValue and
+// links to trace.Link.
+type Tracer interface {
+	// StartSpan starts a span that is a child of the span in ctx, if any, and is linked to the given spans. It returns
+	// a context containing the new span.
+	StartSpan(ctx context.Context, name string, links ...Span) (context.Context, Span)
+}
@@ -23,7 +23,8 @@ require (
 	github.com/xdg/stringprep v0.0.0-20180714160509-73f8eece6fdc
 	golang.org/x/crypto v0.0.0-20190530122614-20be4c3c3ed5 // indirect
 	golang.org/x/sync v0.0.0-20190423024810-112230192c58
-	golang.org/x/sys v0.0.0-20190531175056-4c3a928424d2 // indirect
+	golang.org/x/sys v0.0.0-20190531175056-4c3a928424d2
 	golang.org/x/text v0.3.2 // indirect
 	golang.org/x/tools v0.0.0-20190531172133-b3315ee88b7d
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,77 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// IOSink is a LogSink that writes each message to an io.Writer as a single line of JSON containing the time, level,
+// and message, followed by the keys and values of the message in order.
+type IOSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+var _ LogSink = (*IOSink)(nil)
+
+// NewIOSink creates an IOSink that writes to w.
+func NewIOSink(w io.Writer) *IOSink {
+	return &IOSink{w: w}
+}
+
+// Info implements the LogSink interface.
+func (s *IOSink) Info(level int, message string, keysAndValues ...interface{}) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"t":`)
+	writeJSON(&buf, time.Now().UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+	buf.WriteString(`,"s":`)
+	writeJSON(&buf, levelName(Level(level)))
+	buf.WriteString(`,"message":`)
+	writeJSON(&buf, message)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		buf.WriteByte(',')
+		writeJSON(&buf, fmt.Sprint(keysAndValues[i]))
+		buf.WriteByte(':')
+		if i+1 < len(keysAndValues) {
+			writeJSON(&buf, keysAndValues[i+1])
+		} else {
+			buf.WriteString("null")
+		}
+	}
+	buf.WriteString("}\n")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.w.Write(buf.Bytes())
+}
+
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(b)
+}
+
+func levelName(level Level) string {
+	switch level {
+	case LevelInfo:
+		return "info"
+	case LevelDebug:
+		return "debug"
+	}
+	return "off"
+}
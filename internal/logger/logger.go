@@ -0,0 +1,185 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package logger implements the logging of driver internals described by the MongoDB logging specification. Log
+// messages belong to a component and have a severity level, and are written to a LogSink if logging is enabled for the
+// component at that level.
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Component is a part of the driver that produces log messages.
+type Component int
+
+// Components that produce log messages.
+const (
+	// ComponentAll is used to configure the level of every component at once.
+	ComponentAll Component = iota
+	ComponentCommand
+	ComponentTopology
+	ComponentServerSelection
+	ComponentConnection
+	// ComponentClient logs warnings about the use of a Client, which are not part of the logging specification.
+	ComponentClient
+)
+
+// componentEnvVars are the environment variables that configure the level of each component.
+var componentEnvVars = map[Component]string{
+	ComponentAll:             "MONGODB_LOG_ALL",
+	ComponentCommand:         "MONGODB_LOG_COMMAND",
+	ComponentTopology:        "MONGODB_LOG_TOPOLOGY",
+	ComponentServerSelection: "MONGODB_LOG_SERVER_SELECTION",
+	ComponentConnection:      "MONGODB_LOG_CONNECTION",
+	ComponentClient:          "MONGODB_LOG_CLIENT",
+}
+
+// Level is the severity of a log message. A component logs messages at its configured level and all lower levels.
+type Level int
+
+// Severity levels. The levels of the logging specification are mapped to the Go levels as follows: "off" to
+// LevelOff; "emergency", "alert", "critical", "error", "warn", "notice", and "info" to LevelInfo; and "debug" and
+// "trace" to LevelDebug.
+const (
+	LevelOff Level = iota
+	LevelInfo
+	LevelDebug
+)
+
+// ParseLevel returns the Level for a level name of the logging specification, or LevelOff if the name is not known.
+// Names are case-insensitive.
+func ParseLevel(name string) Level {
+	switch strings.ToLower(name) {
+	case "emergency", "alert", "critical", "error", "warn", "warning", "notice", "info", "informational":
+		return LevelInfo
+	case "debug", "trace":
+		return LevelDebug
+	}
+	return LevelOff
+}
+
+// DefaultMaxDocumentLength is the length at which extended JSON documents in log messages are truncated if no maximum
+// length is configured.
+const DefaultMaxDocumentLength = 1000
+
+// TruncationSuffix is appended to documents that were truncated.
+const TruncationSuffix = "..."
+
+// LogSink receives log messages. The level is the Level of the message, and keysAndValues alternate between string
+// keys and their values. This method set is compatible with logr.LogSink.
+type LogSink interface {
+	Info(level int, message string, keysAndValues ...interface{})
+}
+
+// Logger writes log messages for the components that have logging enabled. A nil *Logger logs nothing.
+type Logger struct {
+	levels            map[Component]Level
+	sink              LogSink
+	maxDocumentLength uint
+	closer            io.Closer
+}
+
+// New creates a Logger. The given levels, sink, and maximum document length take precedence over the environment
+// variables MONGODB_LOG_ALL, MONGODB_LOG_COMMAND, MONGODB_LOG_TOPOLOGY, MONGODB_LOG_SERVER_SELECTION,
+// MONGODB_LOG_CONNECTION, MONGODB_LOG_CLIENT, MONGODB_LOG_PATH, and MONGODB_LOG_MAX_DOCUMENT_LENGTH. If sink is nil,
+// messages are written as JSON lines to the file named by MONGODB_LOG_PATH, which may also be "stdout" or "stderr", the
+// default. If logging is not enabled for any component, New returns nil.
+func New(sink LogSink, maxDocumentLength uint, levels map[Component]Level) (*Logger, error) {
+	l := &Logger{
+		levels:            make(map[Component]Level),
+		sink:              sink,
+		maxDocumentLength: maxDocumentLength,
+	}
+
+	envAll := ParseLevel(os.Getenv(componentEnvVars[ComponentAll]))
+	for c := ComponentCommand; c <= ComponentClient; c++ {
+		switch level, ok := levels[c]; {
+		case ok:
+			l.levels[c] = level
+		case levels[ComponentAll] != LevelOff:
+			l.levels[c] = levels[ComponentAll]
+		case os.Getenv(componentEnvVars[c]) != "":
+			l.levels[c] = ParseLevel(os.Getenv(componentEnvVars[c]))
+		default:
+			l.levels[c] = envAll
+		}
+	}
+
+	enabled := false
+	for _, level := range l.levels {
+		enabled = enabled || level > LevelOff
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	if l.maxDocumentLength == 0 {
+		l.maxDocumentLength = DefaultMaxDocumentLength
+		if env := os.Getenv("MONGODB_LOG_MAX_DOCUMENT_LENGTH"); env != "" {
+			n, err := strconv.ParseUint(env, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid MONGODB_LOG_MAX_DOCUMENT_LENGTH %q: %v", env, err)
+			}
+			l.maxDocumentLength = uint(n)
+		}
+	}
+
+	if l.sink == nil {
+		switch path := os.Getenv("MONGODB_LOG_PATH"); strings.ToLower(path) {
+		case "", "stderr":
+			l.sink = NewIOSink(os.Stderr)
+		case "stdout":
+			l.sink = NewIOSink(os.Stdout)
+		default:
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+			if err != nil {
+				return nil, fmt.Errorf("cannot open MONGODB_LOG_PATH %q: %v", path, err)
+			}
+			l.sink = NewIOSink(f)
+			l.closer = f
+		}
+	}
+	return l, nil
+}
+
+// Enabled returns whether messages of the given component are logged at the given level.
+func (l *Logger) Enabled(component Component, level Level) bool {
+	return l != nil && level > LevelOff && l.levels[component] >= level
+}
+
+// Print writes a message for the given component at the given level if it is enabled.
+func (l *Logger) Print(component Component, level Level, message string, keysAndValues ...interface{}) {
+	if l.Enabled(component, level) {
+		l.sink.Info(int(level), message, keysAndValues...)
+	}
+}
+
+// Truncate shortens an extended JSON document to the configured maximum document length, appending
+// TruncationSuffix if the document was shortened.
+func (l *Logger) Truncate(doc string) string {
+	if l == nil || uint(len(doc)) <= l.maxDocumentLength {
+		return doc
+	}
+	end := int(l.maxDocumentLength)
+	// Do not split a multi-byte character.
+	for end > 0 && end < len(doc) && doc[end]&0xC0 == 0x80 {
+		end--
+	}
+	return doc[:end] + TruncationSuffix
+}
+
+// Close closes the log file opened for MONGODB_LOG_PATH, if any.
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
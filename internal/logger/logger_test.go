@@ -0,0 +1,146 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+type message struct {
+	level int
+	msg   string
+	kvs   []interface{}
+}
+
+type testSink struct {
+	messages []message
+}
+
+func (s *testSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.messages = append(s.messages, message{level, msg, keysAndValues})
+}
+
+// setenv sets an environment variable and returns a function that restores its previous value.
+func setenv(t *testing.T, key, value string) func() {
+	t.Helper()
+	old, ok := os.LookupEnv(key)
+	assert.Nil(t, os.Setenv(key, value), "Setenv error")
+	return func() {
+		if ok {
+			_ = os.Setenv(key, old)
+			return
+		}
+		_ = os.Unsetenv(key)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		l, err := New(nil, 0, nil)
+		assert.Nil(t, err, "New error: %v", err)
+		assert.Nil(t, l, "expected nil logger when no component is enabled")
+		assert.False(t, l.Enabled(ComponentCommand, LevelInfo), "expected nil logger to be disabled")
+	})
+	t.Run("environment", func(t *testing.T) {
+		defer setenv(t, "MONGODB_LOG_ALL", "info")()
+		defer setenv(t, "MONGODB_LOG_COMMAND", "trace")()
+		defer setenv(t, "MONGODB_LOG_MAX_DOCUMENT_LENGTH", "5")()
+
+		l, err := New(&testSink{}, 0, nil)
+		assert.Nil(t, err, "New error: %v", err)
+		assert.True(t, l.Enabled(ComponentCommand, LevelDebug), "expected command debug logging")
+		assert.True(t, l.Enabled(ComponentTopology, LevelInfo), "expected topology info logging")
+		assert.False(t, l.Enabled(ComponentTopology, LevelDebug), "expected no topology debug logging")
+		assert.Equal(t, "abcde...", l.Truncate("abcdefgh"), "unexpected truncation")
+	})
+	t.Run("options take precedence", func(t *testing.T) {
+		defer setenv(t, "MONGODB_LOG_COMMAND", "debug")()
+
+		l, err := New(&testSink{}, 0, map[Component]Level{
+			ComponentAll:        LevelInfo,
+			ComponentConnection: LevelDebug,
+		})
+		assert.Nil(t, err, "New error: %v", err)
+		assert.False(t, l.Enabled(ComponentCommand, LevelDebug), "expected ComponentAll to override the environment")
+		assert.True(t, l.Enabled(ComponentConnection, LevelDebug), "expected connection debug logging")
+		assert.Equal(t, DefaultMaxDocumentLength, int(l.maxDocumentLength), "unexpected max document length")
+	})
+	t.Run("invalid max document length", func(t *testing.T) {
+		defer setenv(t, "MONGODB_LOG_MAX_DOCUMENT_LENGTH", "many")()
+
+		_, err := New(&testSink{}, 0, map[Component]Level{ComponentAll: LevelDebug})
+		assert.NotNil(t, err, "expected error for invalid MONGODB_LOG_MAX_DOCUMENT_LENGTH")
+	})
+}
+
+func TestIOSink(t *testing.T) {
+	var buf bytes.Buffer
+	NewIOSink(&buf).Info(int(LevelDebug), CommandStarted, "commandName", "find", "serverPort", 27017)
+
+	var doc map[string]interface{}
+	err := json.Unmarshal(buf.Bytes(), &doc)
+	assert.Nil(t, err, "invalid JSON %q: %v", buf.String(), err)
+	assert.Equal(t, "debug", doc["s"], "unexpected level")
+	assert.Equal(t, CommandStarted, doc["message"], "unexpected message")
+	assert.Equal(t, "find", doc["commandName"], "unexpected commandName")
+	assert.Equal(t, float64(27017), doc["serverPort"], "unexpected serverPort")
+}
+
+func TestMonitors(t *testing.T) {
+	sink := &testSink{}
+	l, err := New(sink, 0, map[Component]Level{ComponentAll: LevelDebug})
+	assert.Nil(t, err, "New error: %v", err)
+
+	t.Run("command", func(t *testing.T) {
+		sink.messages = nil
+		var failed bool
+		monitor := CommandMonitor(l, &event.CommandMonitor{
+			Failed: func(context.Context, *event.CommandFailedEvent) { failed = true },
+		})
+		monitor.Failed(context.Background(), &event.CommandFailedEvent{
+			CommandFinishedEvent: event.CommandFinishedEvent{
+				CommandName:   "insert",
+				ConnectionID:  "db.example.com:27017[-7]",
+				DurationNanos: 1500000,
+			},
+			Failure: "duplicate key",
+		})
+
+		assert.True(t, failed, "expected event to be passed to the next monitor")
+		assert.Equal(t, 1, len(sink.messages), "expected 1 message, got %d", len(sink.messages))
+		assert.Equal(t, CommandFailed, sink.messages[0].msg, "unexpected message")
+		want := []interface{}{
+			"serverHost", "db.example.com", "serverPort", 27017, "driverConnectionId", uint64(7),
+			"commandName", "insert", "requestId", int64(0), "durationMS", 1.5, "failure", "duplicate key",
+		}
+		assert.Equal(t, want, sink.messages[0].kvs, "unexpected keys and values")
+	})
+	t.Run("connection", func(t *testing.T) {
+		sink.messages = nil
+		PoolMonitor(l, nil).Event(&event.PoolEvent{
+			Type:         event.ConnectionClosed,
+			Address:      "db.example.com:27017",
+			ConnectionID: 3,
+			Reason:       event.ReasonIdle,
+		})
+
+		assert.Equal(t, 1, len(sink.messages), "expected 1 message, got %d", len(sink.messages))
+		assert.Equal(t, ConnectionClosed, sink.messages[0].msg, "unexpected message")
+	})
+	t.Run("disabled", func(t *testing.T) {
+		next := &event.CommandMonitor{}
+		assert.Equal(t, next, CommandMonitor(nil, next), "expected next monitor when logging is disabled")
+		assert.Nil(t, TopologyMonitor(nil, nil), "expected nil monitor when logging is disabled")
+	})
+}
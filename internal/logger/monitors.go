@@ -0,0 +1,217 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package logger
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// Messages logged for each component.
+const (
+	CommandStarted   = "Command started"
+	CommandSucceeded = "Command succeeded"
+	CommandFailed    = "Command failed"
+
+	ConnectionPoolCreated  = "Connection pool created"
+	ConnectionPoolCleared  = "Connection pool cleared"
+	ConnectionPoolClosed   = "Connection pool closed"
+	ConnectionCreated      = "Connection created"
+	ConnectionReady        = "Connection ready"
+	ConnectionClosed       = "Connection closed"
+	ConnectionCheckoutFail = "Connection checkout failed"
+	ConnectionCheckedOut   = "Connection checked out"
+	ConnectionCheckedIn    = "Connection checked in"
+
+	ServerDescriptionChanged = "Server description changed"
+	SRVHostsChanged          = "SRV hosts changed"
+
+	ServerSelectionStarted   = "Server selection started"
+	ServerSelectionSucceeded = "Server selection succeeded"
+	ServerSelectionFailed    = "Server selection failed"
+	ServerSelectionWaiting   = "Waiting for suitable server to become available"
+
+	UnknownURIOptions = "Unknown URI options ignored"
+	SessionNotEnded   = "Session not ended"
+	CursorNotClosed   = "Cursor not closed"
+)
+
+var poolMessages = map[string]string{
+	event.PoolCreated:        ConnectionPoolCreated,
+	event.PoolCleared:        ConnectionPoolCleared,
+	event.PoolClosedEvent:    ConnectionPoolClosed,
+	event.ConnectionCreated:  ConnectionCreated,
+	event.ConnectionReady:    ConnectionReady,
+	event.ConnectionClosed:   ConnectionClosed,
+	event.GetFailed:          ConnectionCheckoutFail,
+	event.GetSucceeded:       ConnectionCheckedOut,
+	event.ConnectionReturned: ConnectionCheckedIn,
+}
+
+// CommandMonitor returns a command monitor that logs command events at LevelDebug and passes them to next, if it is not
+// nil. The filter, sampler, and redaction settings of next also apply to the logged events. It returns next if command
+// logging is disabled.
+func CommandMonitor(l *Logger, next *event.CommandMonitor) *event.CommandMonitor {
+	if !l.Enabled(ComponentCommand, LevelDebug) {
+		return next
+	}
+	if next == nil {
+		next = &event.CommandMonitor{}
+	}
+
+	return &event.CommandMonitor{
+		IncludeWireMessages: next.IncludeWireMessages,
+		Filter:              next.Filter,
+		Sampler:             next.Sampler,
+		RedactedCommands:    next.RedactedCommands,
+		Redact:              next.Redact,
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			l.Print(ComponentCommand, LevelDebug, CommandStarted, append(connectionKeys(evt.ConnectionID),
+				"commandName", evt.CommandName,
+				"databaseName", evt.DatabaseName,
+				"requestId", evt.RequestID,
+				"command", l.Truncate(evt.Command.String()))...)
+			if next.Started != nil {
+				next.Started(ctx, evt)
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			l.Print(ComponentCommand, LevelDebug, CommandSucceeded, append(connectionKeys(evt.ConnectionID),
+				"commandName", evt.CommandName,
+				"requestId", evt.RequestID,
+				"durationMS", durationMS(evt.DurationNanos),
+				"reply", l.Truncate(evt.Reply.String()))...)
+			if next.Succeeded != nil {
+				next.Succeeded(ctx, evt)
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			l.Print(ComponentCommand, LevelDebug, CommandFailed, append(connectionKeys(evt.ConnectionID),
+				"commandName", evt.CommandName,
+				"requestId", evt.RequestID,
+				"durationMS", durationMS(evt.DurationNanos),
+				"failure", evt.Failure)...)
+			if next.Failed != nil {
+				next.Failed(ctx, evt)
+			}
+		},
+	}
+}
+
+// PoolMonitor returns a pool monitor that logs connection pool events at LevelDebug and passes them to next, if it is
+// not nil. The filter and sampler of next also apply to the logged events. It returns next if connection logging is
+// disabled.
+func PoolMonitor(l *Logger, next *event.PoolMonitor) *event.PoolMonitor {
+	if !l.Enabled(ComponentConnection, LevelDebug) {
+		return next
+	}
+	if next == nil {
+		next = &event.PoolMonitor{}
+	}
+
+	return &event.PoolMonitor{
+		Filter:  next.Filter,
+		Sampler: next.Sampler,
+		Event: func(evt *event.PoolEvent) {
+			if msg, ok := poolMessages[evt.Type]; ok {
+				host, port := SplitAddress(evt.Address)
+				kvs := []interface{}{"serverHost", host, "serverPort", port}
+				if evt.ConnectionID != 0 {
+					kvs = append(kvs, "driverConnectionId", evt.ConnectionID)
+				}
+				if evt.Reason != "" {
+					kvs = append(kvs, "reason", evt.Reason)
+				}
+				if evt.Type == event.GetSucceeded || evt.Type == event.GetFailed || evt.Type == event.ConnectionReady {
+					kvs = append(kvs, "durationMS", durationMS(int64(evt.Duration)))
+				}
+				if opts := evt.PoolOptions; opts != nil {
+					kvs = append(kvs, "maxPoolSize", opts.MaxPoolSize, "minPoolSize", opts.MinPoolSize)
+				}
+				if evt.ServiceID != nil {
+					kvs = append(kvs, "serviceId", evt.ServiceID.Hex())
+				}
+				l.Print(ComponentConnection, LevelDebug, msg, kvs...)
+			}
+			if next.Event != nil {
+				next.Event(evt)
+			}
+		},
+	}
+}
+
+// TopologyMonitor returns a topology monitor that logs topology events at LevelDebug and passes them to next, if it
+// is not nil. It returns next if topology logging is disabled.
+func TopologyMonitor(l *Logger, next *event.TopologyMonitor) *event.TopologyMonitor {
+	if !l.Enabled(ComponentTopology, LevelDebug) {
+		return next
+	}
+	if next == nil {
+		next = &event.TopologyMonitor{}
+	}
+
+	return &event.TopologyMonitor{
+		SRVHostsChanged: func(evt *event.SRVHostsChangedEvent) {
+			l.Print(ComponentTopology, LevelDebug, SRVHostsChanged,
+				"added", strings.Join(evt.Added, ","),
+				"removed", strings.Join(evt.Removed, ","))
+			if next.SRVHostsChanged != nil {
+				next.SRVHostsChanged(evt)
+			}
+		},
+		ServerDescriptionChanged: func(evt *event.ServerDescriptionChangedEvent) {
+			host, port := SplitAddress(evt.Address)
+			kvs := []interface{}{
+				"serverHost", host,
+				"serverPort", port,
+				"previousType", evt.PreviousDescription.Kind,
+				"newType", evt.NewDescription.Kind,
+			}
+			if evt.NewDescription.LastError != nil {
+				kvs = append(kvs, "failure", evt.NewDescription.LastError.Error())
+			}
+			l.Print(ComponentTopology, LevelDebug, ServerDescriptionChanged, kvs...)
+			if next.ServerDescriptionChanged != nil {
+				next.ServerDescriptionChanged(evt)
+			}
+		},
+	}
+}
+
+// connectionKeys returns the serverHost, serverPort, and driverConnectionId keys and values for a connection ID of the
+// form "host:port[-N]".
+func connectionKeys(connID string) []interface{} {
+	addr, id := connID, ""
+	if idx := strings.LastIndex(connID, "[-"); idx >= 0 && strings.HasSuffix(connID, "]") {
+		addr, id = connID[:idx], connID[idx+2:len(connID)-1]
+	}
+	host, port := SplitAddress(addr)
+	kvs := []interface{}{"serverHost", host, "serverPort", port}
+	if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+		kvs = append(kvs, "driverConnectionId", n)
+	}
+	return kvs
+}
+
+// SplitAddress splits a "host:port" address into its host and numeric port. The port is 0 if it cannot be parsed.
+func SplitAddress(addr string) (string, int) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, 0
+	}
+	port, _ := strconv.Atoi(portStr)
+	return host, port
+}
+
+func durationMS(nanos int64) float64 {
+	return float64(nanos) / float64(time.Millisecond)
+}
@@ -30,6 +30,12 @@ type batchCursor interface {
 	Close(context.Context) error
 }
 
+// batchSizeSetter is implemented by batch cursors that allow the batch size for subsequent getMore commands to be
+// changed during iteration.
+type batchSizeSetter interface {
+	SetBatchSize(int32)
+}
+
 // changeStreamCursor is the interface implemented by batch cursors that also provide the functionality for retrieving
 // a postBatchResumeToken from commands and allows for the cursor to be killed rather than closed
 type changeStreamCursor interface {
@@ -0,0 +1,82 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CausalToken carries the cluster time and operation time of a Session so that they can be applied to another Session,
+// possibly in another process. Applying the token of a causally consistent session to a second causally consistent
+// session guarantees that reads in the second session see the writes made by the first session before the token was
+// taken. This allows read-your-writes guarantees to follow a request through a chain of services:
+//
+//	// in the service that writes
+//	token := mongo.SessionCausalToken(sess).String()
+//
+//	// in the service that reads
+//	token, err := mongo.ParseCausalToken(s)
+//	if err != nil {
+//		return err
+//	}
+//	err = mongo.ApplyCausalToken(sess, token)
+type CausalToken struct {
+	ClusterTime   bson.Raw             `bson:"clusterTime,omitempty"`
+	OperationTime *primitive.Timestamp `bson:"operationTime,omitempty"`
+}
+
+// SessionCausalToken returns a CausalToken with the current cluster time and operation time of sess.
+func SessionCausalToken(sess Session) CausalToken {
+	return CausalToken{
+		ClusterTime:   sess.ClusterTime(),
+		OperationTime: sess.OperationTime(),
+	}
+}
+
+// ApplyCausalToken advances the cluster time and operation time of sess to those of token. The times of sess are not
+// changed if they are already later than those of token.
+func ApplyCausalToken(sess Session, token CausalToken) error {
+	if token.ClusterTime != nil {
+		if err := sess.AdvanceClusterTime(token.ClusterTime); err != nil {
+			return err
+		}
+	}
+	if token.OperationTime != nil {
+		if err := sess.AdvanceOperationTime(token.OperationTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String returns a compact representation of the token that is safe to use in URLs and HTTP headers. It can be parsed
+// with ParseCausalToken.
+func (t CausalToken) String() string {
+	doc, err := bson.Marshal(t)
+	if err != nil {
+		// The token only contains a document and a timestamp, so it can always be marshaled.
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(doc)
+}
+
+// ParseCausalToken parses a token returned by CausalToken.String.
+func ParseCausalToken(s string) (CausalToken, error) {
+	var t CausalToken
+	doc, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return t, fmt.Errorf("invalid causal token: %v", err)
+	}
+	if err = bson.Unmarshal(doc, &t); err != nil {
+		return t, fmt.Errorf("invalid causal token: %v", err)
+	}
+	return t, nil
+}
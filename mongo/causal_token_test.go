@@ -0,0 +1,76 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/session"
+)
+
+func TestCausalToken(t *testing.T) {
+	client := setupClient(&options.ClientOptions{Deployment: mockDeployment{}})
+	client.sessionPool = session.NewPool(nil)
+	newSession := func(t *testing.T) Session {
+		sess, err := client.StartSession()
+		assert.Nil(t, err, "StartSession error: %v", err)
+		return sess
+	}
+	clusterTime := func(ts primitive.Timestamp) bson.Raw {
+		doc, err := bson.Marshal(bson.D{{"$clusterTime", bson.D{{"clusterTime", ts}}}})
+		assert.Nil(t, err, "Marshal error: %v", err)
+		return doc
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		src := newSession(t)
+		defer src.EndSession(bgCtx)
+		ct := clusterTime(primitive.Timestamp{T: 20, I: 1})
+		_ = src.AdvanceClusterTime(ct)
+		_ = src.AdvanceOperationTime(&primitive.Timestamp{T: 19, I: 3})
+
+		token, err := ParseCausalToken(SessionCausalToken(src).String())
+		assert.Nil(t, err, "ParseCausalToken error: %v", err)
+
+		dst := newSession(t)
+		defer dst.EndSession(bgCtx)
+		err = ApplyCausalToken(dst, token)
+		assert.Nil(t, err, "ApplyCausalToken error: %v", err)
+		assert.Equal(t, ct, dst.ClusterTime(), "expected cluster time %v, got %v", ct, dst.ClusterTime())
+		assert.Equal(t, primitive.Timestamp{T: 19, I: 3}, *dst.OperationTime(),
+			"unexpected operation time %v", dst.OperationTime())
+	})
+	t.Run("later times are kept", func(t *testing.T) {
+		sess := newSession(t)
+		defer sess.EndSession(bgCtx)
+		ct := clusterTime(primitive.Timestamp{T: 30, I: 0})
+		_ = sess.AdvanceClusterTime(ct)
+		_ = sess.AdvanceOperationTime(&primitive.Timestamp{T: 30, I: 0})
+
+		err := ApplyCausalToken(sess, CausalToken{
+			ClusterTime:   clusterTime(primitive.Timestamp{T: 10, I: 0}),
+			OperationTime: &primitive.Timestamp{T: 10, I: 0},
+		})
+		assert.Nil(t, err, "ApplyCausalToken error: %v", err)
+		assert.Equal(t, ct, sess.ClusterTime(), "expected cluster time %v, got %v", ct, sess.ClusterTime())
+		assert.Equal(t, uint32(30), sess.OperationTime().T, "unexpected operation time %v", sess.OperationTime())
+	})
+	t.Run("empty token", func(t *testing.T) {
+		token, err := ParseCausalToken(CausalToken{}.String())
+		assert.Nil(t, err, "ParseCausalToken error: %v", err)
+		assert.Nil(t, token.ClusterTime, "expected no cluster time, got %v", token.ClusterTime)
+		assert.Nil(t, token.OperationTime, "expected no operation time, got %v", token.OperationTime)
+	})
+	t.Run("invalid token", func(t *testing.T) {
+		_, err := ParseCausalToken("not a token!")
+		assert.NotNil(t, err, "expected ParseCausalToken error, got nil")
+	})
+}
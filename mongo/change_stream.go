@@ -59,6 +59,7 @@ type ChangeStream struct {
 	options       *options.ChangeStreamOptions
 	selector      description.ServerSelector
 	operationTime *primitive.Timestamp
+	checkpoint    checkpointState
 }
 
 type changeStreamConfig struct {
@@ -126,6 +127,11 @@ func newChangeStream(ctx context.Context, config changeStreamConfig, pipeline in
 		return nil, fmt.Errorf("must supply a valid StreamType in config, instead of %v", cs.streamType)
 	}
 
+	if cs.err = cs.loadCheckpoint(ctx); cs.err != nil {
+		closeImplicitSession(cs.sess)
+		return nil, cs.Err()
+	}
+
 	// When starting a change stream, cache startAfter as the first resume token if it is set. If not, cache
 	// resumeAfter. If neither is set, do not cache a resume token.
 	resumeToken := cs.options.StartAfter
@@ -241,6 +247,13 @@ func (cs *ChangeStream) executeOperation(ctx context.Context, resuming bool) err
 
 	cr := cs.aggregate.ResultCursorResponse()
 	cr.Server = server
+	if pc, ok := conn.(driver.PinnedConnection); ok && cr.ID != 0 && cs.client.deployment.Kind() == description.LoadBalanced {
+		// The getMore commands for the change stream must be sent on the connection used for the aggregate.
+		if cs.err = pc.PinToCursor(); cs.err != nil {
+			return cs.Err()
+		}
+		cr.Connection = pc
+	}
 
 	cs.cursor, cs.err = driver.NewBatchCursor(cr, cs.sess, cs.client.clock, cs.cursorOptions)
 	if cs.err = replaceErrors(cs.err); cs.err != nil {
@@ -319,6 +332,16 @@ func (cs *ChangeStream) buildPipelineSlice(pipeline interface{}) error {
 		cs.pipelineSlice = append(cs.pipelineSlice, elem)
 	}
 
+	if cs.options.SplitLargeEvents != nil && *cs.options.SplitLargeEvents {
+		// The split stage must be the last stage in the pipeline.
+		splitIdx, splitDoc := bsoncore.AppendDocumentStart(nil)
+		splitDoc = bsoncore.AppendDocumentElement(splitDoc, "$changeStreamSplitLargeEvent", emptyDoc)
+		if splitDoc, cs.err = bsoncore.AppendDocumentEnd(splitDoc, splitIdx); cs.err != nil {
+			return cs.err
+		}
+		cs.pipelineSlice = append(cs.pipelineSlice, splitDoc)
+	}
+
 	return cs.err
 }
 
@@ -353,6 +376,10 @@ func (cs *ChangeStream) createPipelineOptionsDoc() bsoncore.Document {
 		plDoc = bsoncore.AppendDocumentElement(plDoc, "startAfter", saDoc)
 	}
 
+	if cs.options.ShowExpandedEvents != nil {
+		plDoc = bsoncore.AppendBooleanElement(plDoc, "showExpandedEvents", *cs.options.ShowExpandedEvents)
+	}
+
 	if cs.options.StartAtOperationTime != nil {
 		plDoc = bsoncore.AppendTimestampElement(plDoc, "startAtOperationTime", cs.options.StartAtOperationTime.T, cs.options.StartAtOperationTime.I)
 	}
@@ -447,8 +474,12 @@ func (cs *ChangeStream) Close(ctx context.Context) error {
 		return nil // cursor is already closed
 	}
 
+	checkpointErr := cs.storeCheckpoint(true)
 	cs.err = replaceErrors(cs.cursor.Close(ctx))
 	cs.cursor = nil
+	if cs.err == nil {
+		cs.err = checkpointErr
+	}
 	return cs.Err()
 }
 
@@ -494,6 +525,9 @@ func (cs *ChangeStream) next(ctx context.Context, nonBlocking bool) bool {
 		ctx = context.Background()
 	}
 
+	// asking for the next event means that the previous one has been processed
+	cs.ackReturned()
+
 	if len(cs.batch) == 0 {
 		cs.loopNext(ctx, nonBlocking)
 		if cs.err != nil {
@@ -501,6 +535,9 @@ func (cs *ChangeStream) next(ctx context.Context, nonBlocking bool) bool {
 			return false
 		}
 		if len(cs.batch) == 0 {
+			// The post batch resume token may have advanced even though there were no events.
+			cs.advanceProcessed()
+			_ = cs.storeCheckpoint(false)
 			return false
 		}
 	}
@@ -511,6 +548,14 @@ func (cs *ChangeStream) next(ctx context.Context, nonBlocking bool) bool {
 	if cs.err = cs.storeResumeToken(); cs.err != nil {
 		return false
 	}
+	if _, split := cs.Current.Lookup("splitEvent").DocumentOK(); split {
+		if cs.err = cs.reassembleSplitEvent(ctx); cs.err != nil {
+			cs.err = replaceErrors(cs.err)
+			return false
+		}
+	}
+	_ = cs.storeCheckpoint(false)
+	cs.setReturned()
 	return true
 }
 
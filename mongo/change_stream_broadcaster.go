@@ -0,0 +1,378 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrSubscriberOverflow is returned by Subscription.Err if the subscription was closed because its buffer was full and
+// its overflow policy is options.OverflowDisconnect.
+var ErrSubscriberOverflow = errors.New("subscriber buffer is full")
+
+// ErrBroadcasterClosed is returned by Subscription.Err if the subscription was closed because the broadcaster was
+// closed, and by ChangeStreamBroadcaster.Subscribe if the broadcaster has already been closed.
+var ErrBroadcasterClosed = errors.New("change stream broadcaster is closed")
+
+// BroadcastEvent is a change stream event delivered to a Subscription.
+type BroadcastEvent struct {
+	// Document is the change event. The same bytes are shared by every subscriber and must not be modified.
+	Document bson.Raw
+
+	// ResumeToken is the resume token of the change stream after this event.
+	ResumeToken bson.Raw
+
+	seq uint64
+}
+
+// ChangeStreamBroadcaster owns a single ChangeStream and delivers each of its events to every subscriber, so that
+// several in-process consumers can share one server-side change stream. Each subscriber has its own buffer and
+// overflow policy (see options.SubscribeOptions) and acknowledges events independently. The resume token returned by
+// AcknowledgedResumeToken can be persisted and used to restart the stream without skipping any event that has not
+// been acknowledged by every subscriber.
+//
+// A ChangeStreamBroadcaster is safe for concurrent use.
+type ChangeStreamBroadcaster struct {
+	cs   *ChangeStream
+	done chan struct{}
+
+	mu      sync.Mutex
+	subs    map[*Subscription]struct{}
+	seq     uint64
+	pending []pendingToken // tokens for events that have not been acknowledged by every subscriber, oldest first
+	ackSeq  uint64
+	ackTok  bson.Raw
+	running bool
+	closed  bool
+}
+
+type pendingToken struct {
+	seq   uint64
+	token bson.Raw
+}
+
+// NewChangeStreamBroadcaster creates a ChangeStreamBroadcaster for cs. The broadcaster takes ownership of cs, which
+// must not be iterated or closed by the caller. Events are not read from cs until Run is called.
+func NewChangeStreamBroadcaster(cs *ChangeStream) *ChangeStreamBroadcaster {
+	return &ChangeStreamBroadcaster{
+		cs:     cs,
+		done:   make(chan struct{}),
+		subs:   make(map[*Subscription]struct{}),
+		ackTok: cs.ResumeToken(),
+	}
+}
+
+// Subscribe registers a new subscriber. The subscriber receives every event broadcast after Subscribe returns.
+//
+// The opts parameter can be used to specify options for the subscription (see the options.SubscribeOptions
+// documentation).
+func (b *ChangeStreamBroadcaster) Subscribe(opts ...*options.SubscribeOptions) (*Subscription, error) {
+	so := options.MergeSubscribeOptions(opts...)
+	size := 0
+	if so.BufferSize != nil && *so.BufferSize > 0 {
+		size = *so.BufferSize
+	}
+	policy := options.OverflowBlock
+	if so.OverflowPolicy != nil {
+		policy = *so.OverflowPolicy
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBroadcasterClosed
+	}
+	sub := &Subscription{
+		b:         b,
+		events:    make(chan BroadcastEvent, size),
+		done:      make(chan struct{}),
+		policy:    policy,
+		acked:     b.seq,
+		delivered: b.seq,
+	}
+	b.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Run reads events from the change stream and broadcasts them to the subscribers until ctx expires, the change stream
+// returns an error, or Close is called. When Run returns, every subscription is closed and the change stream is
+// closed. Run returns the error that stopped it, or nil if Close was called. Run must only be called once.
+func (b *ChangeStreamBroadcaster) Run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.running = true
+	b.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-b.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for b.cs.Next(ctx) {
+		evt := BroadcastEvent{
+			Document:    append(bson.Raw(nil), b.cs.Current...),
+			ResumeToken: append(bson.Raw(nil), b.cs.ResumeToken()...),
+		}
+		b.broadcast(ctx, evt)
+	}
+
+	var err error
+	select {
+	case <-b.done:
+	default:
+		err = b.cs.Err()
+	}
+	b.closeSubscriptions(err)
+	_ = b.cs.Close(context.Background())
+	return err
+}
+
+func (b *ChangeStreamBroadcaster) broadcast(ctx context.Context, evt BroadcastEvent) {
+	b.mu.Lock()
+	b.seq++
+	evt.seq = b.seq
+	b.pending = append(b.pending, pendingToken{seq: evt.seq, token: evt.ResumeToken})
+	subs := make([]*Subscription, 0, len(b.subs))
+	for sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.deliver(ctx, evt)
+	}
+	b.updateAcknowledged()
+}
+
+// AcknowledgedResumeToken returns the resume token after the latest event that has been acknowledged by every
+// subscriber, or the resume token of the change stream when the broadcaster was created if no event has been
+// acknowledged by every subscriber. Events broadcast while there were no subscribers and events dropped by a
+// subscriber's overflow policy are considered acknowledged by that subscriber once it acknowledges a later event.
+func (b *ChangeStreamBroadcaster) AcknowledgedResumeToken() bson.Raw {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.ackTok
+}
+
+// updateAcknowledged recomputes the acknowledged resume token from the subscribers' acknowledgements.
+func (b *ChangeStreamBroadcaster) updateAcknowledged() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	min := b.seq
+	for sub := range b.subs {
+		if acked := sub.ackedSeq(); acked < min {
+			min = acked
+		}
+	}
+	if min <= b.ackSeq {
+		return
+	}
+
+	i := 0
+	for ; i < len(b.pending) && b.pending[i].seq <= min; i++ {
+		b.ackTok = b.pending[i].token
+	}
+	b.pending = b.pending[i:]
+	b.ackSeq = min
+}
+
+// Close stops Run, closes every subscription, and closes the change stream.
+func (b *ChangeStreamBroadcaster) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	running := b.running
+	close(b.done)
+	b.mu.Unlock()
+
+	b.closeSubscriptions(nil)
+	if running {
+		// Run closes the change stream when it returns.
+		return nil
+	}
+	return b.cs.Close(ctx)
+}
+
+func (b *ChangeStreamBroadcaster) closeSubscriptions(err error) {
+	if err == nil {
+		err = ErrBroadcasterClosed
+	}
+
+	b.mu.Lock()
+	subs := b.subs
+	b.subs = make(map[*Subscription]struct{})
+	b.mu.Unlock()
+
+	for sub := range subs {
+		sub.close(err)
+	}
+}
+
+// Subscription is a subscriber to a ChangeStreamBroadcaster.
+type Subscription struct {
+	b      *ChangeStreamBroadcaster
+	events chan BroadcastEvent
+	done   chan struct{}
+	policy options.OverflowPolicy
+
+	// sendMu is held while an event is being sent on events so that events is never closed during a send.
+	sendMu    sync.Mutex
+	closeOnce sync.Once
+	closed    bool
+
+	mu        sync.Mutex
+	acked     uint64 // every event up to and including this sequence number has been acknowledged
+	delivered uint64 // the sequence number of the last event delivered to events
+	err       error
+}
+
+// Events returns the channel on which events are delivered. The channel is closed when the subscription is closed.
+func (s *Subscription) Events() <-chan BroadcastEvent {
+	return s.events
+}
+
+// Ack acknowledges evt and every event delivered to this subscription before it.
+func (s *Subscription) Ack(evt BroadcastEvent) {
+	s.mu.Lock()
+	if evt.seq > s.acked {
+		s.acked = evt.seq
+	}
+	s.mu.Unlock()
+
+	s.b.updateAcknowledged()
+}
+
+// Err returns the reason the subscription was closed, or nil if it is open or was closed with Close.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+// Close unsubscribes from the broadcaster and closes the events channel. Events that have not been acknowledged are no
+// longer considered when computing the acknowledged resume token.
+func (s *Subscription) Close() {
+	s.b.mu.Lock()
+	delete(s.b.subs, s)
+	s.b.mu.Unlock()
+
+	s.close(nil)
+	s.b.updateAcknowledged()
+}
+
+func (s *Subscription) ackedSeq() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.acked
+}
+
+func (s *Subscription) close(err error) {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+
+		close(s.done)
+		s.sendMu.Lock()
+		s.closed = true
+		close(s.events)
+		s.sendMu.Unlock()
+	})
+}
+
+// deliver sends evt to the subscriber according to its overflow policy.
+func (s *Subscription) deliver(ctx context.Context, evt BroadcastEvent) {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.events <- evt:
+		s.markDelivered(evt.seq)
+		return
+	default:
+	}
+
+	switch s.policy {
+	case options.OverflowBlock:
+		select {
+		case s.events <- evt:
+			s.markDelivered(evt.seq)
+		case <-s.done:
+		case <-ctx.Done():
+		}
+	case options.OverflowDropNewest:
+		s.markDropped(evt.seq)
+	case options.OverflowDropOldest:
+		select {
+		case <-s.events:
+		default:
+		}
+		select {
+		case s.events <- evt:
+			s.markDelivered(evt.seq)
+		default:
+			s.markDropped(evt.seq)
+		}
+	case options.OverflowDisconnect:
+		go s.closeOverflow()
+	}
+}
+
+func (s *Subscription) closeOverflow() {
+	s.b.mu.Lock()
+	delete(s.b.subs, s)
+	s.b.mu.Unlock()
+
+	s.close(ErrSubscriberOverflow)
+	s.b.updateAcknowledged()
+}
+
+func (s *Subscription) markDelivered(seq uint64) {
+	s.mu.Lock()
+	s.delivered = seq
+	s.mu.Unlock()
+}
+
+// markDropped records that the event with the given sequence number will never be delivered. If every event delivered
+// before it has been acknowledged, the dropped event is considered acknowledged as well so that it does not hold back
+// the acknowledged resume token.
+func (s *Subscription) markDropped(seq uint64) {
+	s.mu.Lock()
+	if s.acked == s.delivered {
+		s.acked = seq
+	}
+	s.delivered = seq
+	s.mu.Unlock()
+}
@@ -0,0 +1,179 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+)
+
+// channelChangeStreamCursor is a changeStreamCursor that returns the batches sent on a channel and blocks until the
+// context passed to Next expires when no batch is available. The cursor fails with a non-resumable Interrupted error
+// when the context expires so the change stream does not try to resume.
+type channelChangeStreamCursor struct {
+	batches chan *bsoncore.DocumentSequence
+	batch   *bsoncore.DocumentSequence
+	err     error
+}
+
+func newChannelChangeStreamCursor() *channelChangeStreamCursor {
+	return &channelChangeStreamCursor{
+		batches: make(chan *bsoncore.DocumentSequence, 10),
+		batch:   new(bsoncore.DocumentSequence),
+	}
+}
+
+func (c *channelChangeStreamCursor) send(t *testing.T, ids ...int32) {
+	t.Helper()
+
+	var data []byte
+	for _, id := range ids {
+		doc, err := bson.Marshal(bson.D{{"_id", bson.D{{"token", id}}}, {"operationType", "insert"}})
+		assert.Nil(t, err, "Marshal error: %v", err)
+		data = append(data, doc...)
+	}
+	c.batches <- &bsoncore.DocumentSequence{Style: bsoncore.SequenceStyle, Data: data}
+}
+
+func (c *channelChangeStreamCursor) ID() int64 { return 1 }
+func (c *channelChangeStreamCursor) Next(ctx context.Context) bool {
+	select {
+	case c.batch = <-c.batches:
+		return true
+	case <-ctx.Done():
+		c.err = driver.Error{Code: errorInterrupted, Message: ctx.Err().Error()}
+		return false
+	}
+}
+func (c *channelChangeStreamCursor) Batch() *bsoncore.DocumentSequence       { return c.batch }
+func (c *channelChangeStreamCursor) Server() driver.Server                   { return nil }
+func (c *channelChangeStreamCursor) Err() error                              { return c.err }
+func (c *channelChangeStreamCursor) Close(context.Context) error             { return nil }
+func (c *channelChangeStreamCursor) PostBatchResumeToken() bsoncore.Document { return nil }
+func (c *channelChangeStreamCursor) KillCursor(context.Context) error        { return nil }
+
+func tokenOf(t *testing.T, token bson.Raw) int32 {
+	t.Helper()
+
+	if token == nil {
+		return 0
+	}
+	return token.Lookup("token").Int32()
+}
+
+func receive(t *testing.T, sub *Subscription) BroadcastEvent {
+	t.Helper()
+
+	select {
+	case evt, ok := <-sub.Events():
+		assert.True(t, ok, "expected event, subscription closed with error %v", sub.Err())
+		return evt
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return BroadcastEvent{}
+}
+
+func TestChangeStreamBroadcaster(t *testing.T) {
+	newBroadcaster := func() (*ChangeStreamBroadcaster, *channelChangeStreamCursor) {
+		cursor := newChannelChangeStreamCursor()
+		cs := &ChangeStream{cursor: cursor, registry: bson.DefaultRegistry}
+		return NewChangeStreamBroadcaster(cs), cursor
+	}
+	run := func(b *ChangeStreamBroadcaster) <-chan error {
+		errs := make(chan error, 1)
+		go func() { errs <- b.Run(context.Background()) }()
+		return errs
+	}
+
+	t.Run("fans out to every subscriber", func(t *testing.T) {
+		b, cursor := newBroadcaster()
+		sub1, err := b.Subscribe(options.Subscribe().SetBufferSize(10))
+		assert.Nil(t, err, "Subscribe error: %v", err)
+		sub2, err := b.Subscribe(options.Subscribe().SetBufferSize(10))
+		assert.Nil(t, err, "Subscribe error: %v", err)
+		errs := run(b)
+
+		cursor.send(t, 1, 2)
+		for _, sub := range []*Subscription{sub1, sub2} {
+			for i := int32(1); i <= 2; i++ {
+				evt := receive(t, sub)
+				assert.Equal(t, i, tokenOf(t, evt.ResumeToken), "expected token %v, got %v", i, evt.ResumeToken)
+			}
+		}
+
+		err = b.Close(context.Background())
+		assert.Nil(t, err, "Close error: %v", err)
+		assert.Nil(t, <-errs, "expected Run to return nil after Close")
+		_, ok := <-sub1.Events()
+		assert.False(t, ok, "expected subscription to be closed")
+		assert.Equal(t, ErrBroadcasterClosed, sub1.Err(), "expected error %v, got %v", ErrBroadcasterClosed, sub1.Err())
+	})
+	t.Run("acknowledged resume token", func(t *testing.T) {
+		b, _ := newBroadcaster()
+		sub1, err := b.Subscribe(options.Subscribe().SetBufferSize(10))
+		assert.Nil(t, err, "Subscribe error: %v", err)
+		sub2, err := b.Subscribe(options.Subscribe().SetBufferSize(10))
+		assert.Nil(t, err, "Subscribe error: %v", err)
+
+		for i := int32(1); i <= 3; i++ {
+			tok, err := bson.Marshal(bson.D{{"token", i}})
+			assert.Nil(t, err, "Marshal error: %v", err)
+			b.broadcast(context.Background(), BroadcastEvent{ResumeToken: tok})
+		}
+		evts := []BroadcastEvent{receive(t, sub1), receive(t, sub1), receive(t, sub1)}
+
+		sub1.Ack(evts[2])
+		got := tokenOf(t, b.AcknowledgedResumeToken())
+		assert.Equal(t, int32(0), got, "expected no acknowledged token, got %v", got)
+
+		sub2.Ack(evts[0])
+		got = tokenOf(t, b.AcknowledgedResumeToken())
+		assert.Equal(t, int32(1), got, "expected acknowledged token 1, got %v", got)
+
+		sub2.Close()
+		got = tokenOf(t, b.AcknowledgedResumeToken())
+		assert.Equal(t, int32(3), got, "expected acknowledged token 3, got %v", got)
+	})
+	t.Run("overflow policies", func(t *testing.T) {
+		b, _ := newBroadcaster()
+		dropNewest, err := b.Subscribe(options.Subscribe().SetBufferSize(1).SetOverflowPolicy(options.OverflowDropNewest))
+		assert.Nil(t, err, "Subscribe error: %v", err)
+		dropOldest, err := b.Subscribe(options.Subscribe().SetBufferSize(1).SetOverflowPolicy(options.OverflowDropOldest))
+		assert.Nil(t, err, "Subscribe error: %v", err)
+		disconnect, err := b.Subscribe(options.Subscribe().SetBufferSize(1).SetOverflowPolicy(options.OverflowDisconnect))
+		assert.Nil(t, err, "Subscribe error: %v", err)
+
+		for i := int32(1); i <= 2; i++ {
+			tok, err := bson.Marshal(bson.D{{"token", i}})
+			assert.Nil(t, err, "Marshal error: %v", err)
+			b.broadcast(context.Background(), BroadcastEvent{ResumeToken: tok})
+		}
+
+		got := tokenOf(t, receive(t, dropNewest).ResumeToken)
+		assert.Equal(t, int32(1), got, "expected first event to be kept, got %v", got)
+		got = tokenOf(t, receive(t, dropOldest).ResumeToken)
+		assert.Equal(t, int32(2), got, "expected second event to be kept, got %v", got)
+
+		receive(t, disconnect)
+		select {
+		case _, ok := <-disconnect.Events():
+			assert.False(t, ok, "expected subscription to be closed")
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for subscription to be closed")
+		}
+		assert.Equal(t, ErrSubscriberOverflow, disconnect.Err(), "expected error %v, got %v", ErrSubscriberOverflow,
+			disconnect.Err())
+	})
+}
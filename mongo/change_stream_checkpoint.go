@@ -0,0 +1,150 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// checkpointTimeout bounds the time taken by a checkpoint write, which does not use the caller's context.
+const checkpointTimeout = 10 * time.Second
+
+// checkpointState tracks when the resume token of a change stream was last stored.
+type checkpointState struct {
+	token     bson.Raw // the last resume token that was stored
+	processed bson.Raw // the resume token up to which all events have been processed
+	returned  bson.Raw // the resume token of the event returned by the last call to Next or TryNext
+	events    int      // the number of processed events since the last checkpoint
+	last      time.Time
+	err       error // the error of the last checkpoint write, cleared when a write succeeds
+}
+
+func (cs *ChangeStream) checkpointCollection() *Collection {
+	cp := cs.options.Checkpoint
+	return cs.client.Database(cp.Database).Collection(cp.Collection)
+}
+
+// loadCheckpoint validates the checkpoint options and, if no explicit starting point was given, configures the change
+// stream to resume after the stored resume token if there is one.
+func (cs *ChangeStream) loadCheckpoint(ctx context.Context) error {
+	cp := cs.options.Checkpoint
+	if cp == nil {
+		return nil
+	}
+	if cp.Database == "" || cp.Collection == "" || cp.ID == "" {
+		return errors.New("change stream checkpoint must specify a database, collection, and ID")
+	}
+	cs.checkpoint.last = time.Now()
+
+	if cs.options.ResumeAfter != nil || cs.options.StartAfter != nil || cs.options.StartAtOperationTime != nil {
+		return nil
+	}
+
+	var stored struct {
+		ResumeToken bson.Raw `bson:"resumeToken"`
+	}
+	err := cs.checkpointCollection().FindOne(ctx, bson.D{{"_id", cp.ID}}).Decode(&stored)
+	switch {
+	case err == ErrNoDocuments:
+		return nil
+	case err != nil:
+		return err
+	case stored.ResumeToken == nil:
+		return nil
+	}
+
+	cs.checkpoint.token = stored.ResumeToken
+	cs.options.SetResumeAfter(stored.ResumeToken)
+	return nil
+}
+
+// checkpointDue returns true if a checkpoint should be written according to the configured cadence.
+func checkpointDue(cp *options.ChangeStreamCheckpoint, state checkpointState, now time.Time) bool {
+	if cp.Events <= 0 && cp.Interval <= 0 {
+		return state.events > 0
+	}
+	if cp.Events > 0 && state.events >= cp.Events {
+		return true
+	}
+	return cp.Interval > 0 && now.Sub(state.last) >= cp.Interval
+}
+
+// ackReturned marks the event returned by the previous call to Next or TryNext as processed. An event is only
+// considered processed once the caller asks for the next one, so a checkpoint never skips an event whose processing
+// was interrupted.
+func (cs *ChangeStream) ackReturned() {
+	if cs.checkpoint.returned == nil {
+		return
+	}
+	cs.checkpoint.processed = cs.checkpoint.returned
+	cs.checkpoint.returned = nil
+	cs.checkpoint.events++
+}
+
+// setReturned records the resume token of the event that is about to be returned to the caller.
+func (cs *ChangeStream) setReturned() {
+	if cs.options == nil || cs.options.Checkpoint == nil {
+		return
+	}
+	cs.checkpoint.returned = append(bson.Raw(nil), cs.resumeToken...)
+}
+
+// advanceProcessed moves the processed resume token to the current resume token if no returned event is pending,
+// which is the case when the post batch resume token advances without new events.
+func (cs *ChangeStream) advanceProcessed() {
+	if cs.checkpoint.returned == nil && cs.resumeToken != nil {
+		cs.checkpoint.processed = append(bson.Raw(nil), cs.resumeToken...)
+	}
+}
+
+// storeCheckpoint writes the processed resume token to the checkpoint collection if checkpointing is enabled and a
+// checkpoint is due. If force is true, the cadence is ignored. If the write fails, the error is recorded for
+// CheckpointErr and the checkpoint stays due, so the write is retried by the next call.
+//
+// The write uses its own context so that it does not run in the caller's session or transaction and is not
+// interrupted by the caller's deadline. The context expires after checkpointTimeout so that an unreachable server
+// cannot block the change stream indefinitely.
+func (cs *ChangeStream) storeCheckpoint(force bool) error {
+	if cs.options == nil || cs.options.Checkpoint == nil || cs.checkpoint.processed == nil {
+		return nil
+	}
+	cp := cs.options.Checkpoint
+	now := time.Now()
+	if !force && !checkpointDue(cp, cs.checkpoint, now) {
+		return nil
+	}
+
+	if !bytes.Equal(cs.checkpoint.token, cs.checkpoint.processed) {
+		doc := bson.D{{"_id", cp.ID}, {"resumeToken", cs.checkpoint.processed}, {"updatedAt", now}}
+		ctx, cancel := context.WithTimeout(context.Background(), checkpointTimeout)
+		_, err := cs.checkpointCollection().ReplaceOne(ctx, bson.D{{"_id", cp.ID}}, doc,
+			options.Replace().SetUpsert(true))
+		cancel()
+		if err != nil {
+			cs.checkpoint.err = err
+			return err
+		}
+		cs.checkpoint.token = append(cs.checkpoint.token[:0], cs.checkpoint.processed...)
+	}
+	cs.checkpoint.events = 0
+	cs.checkpoint.last = now
+	cs.checkpoint.err = nil
+	return nil
+}
+
+// CheckpointErr returns the error of the last attempt to store a checkpoint, or nil if it succeeded or checkpointing
+// is not enabled. A failed checkpoint does not stop the change stream; it is retried by the following calls to Next
+// and TryNext.
+func (cs *ChangeStream) CheckpointErr() error {
+	return cs.checkpoint.err
+}
@@ -0,0 +1,128 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/mongofake"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestChangeStreamCheckpoint(t *testing.T) {
+	t.Run("checkpointDue", func(t *testing.T) {
+		now := time.Now()
+		testCases := []struct {
+			name   string
+			cp     options.ChangeStreamCheckpoint
+			events int
+			since  time.Duration
+			due    bool
+		}{
+			{"every event with new event", options.ChangeStreamCheckpoint{}, 1, 0, true},
+			{"every event without new event", options.ChangeStreamCheckpoint{}, 0, time.Hour, false},
+			{"event count reached", options.ChangeStreamCheckpoint{Events: 10}, 10, 0, true},
+			{"event count not reached", options.ChangeStreamCheckpoint{Events: 10}, 9, time.Hour, false},
+			{"interval elapsed", options.ChangeStreamCheckpoint{Interval: time.Minute}, 0, time.Minute, true},
+			{"interval not elapsed", options.ChangeStreamCheckpoint{Interval: time.Minute}, 100, time.Second, false},
+			{"either condition", options.ChangeStreamCheckpoint{Events: 5, Interval: time.Minute}, 1, 2 * time.Minute, true},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				state := checkpointState{events: tc.events, last: now.Add(-tc.since)}
+				got := checkpointDue(&tc.cp, state, now)
+				assert.Equal(t, tc.due, got, "expected due %v, got %v", tc.due, got)
+			})
+		}
+	})
+	t.Run("options must name a document", func(t *testing.T) {
+		cs := &ChangeStream{
+			options: options.ChangeStream().SetCheckpoint(options.ChangeStreamCheckpoint{Database: "db", ID: "id"}),
+		}
+		err := cs.loadCheckpoint(context.Background())
+		assert.NotNil(t, err, "expected error for missing collection, got nil")
+	})
+	t.Run("explicit starting point skips stored token", func(t *testing.T) {
+		opts := options.ChangeStream().
+			SetCheckpoint(options.ChangeStreamCheckpoint{Database: "db", Collection: "coll", ID: "id"}).
+			SetResumeAfter(map[string]interface{}{"_data": "foo"})
+		cs := &ChangeStream{options: opts}
+		err := cs.loadCheckpoint(context.Background())
+		assert.Nil(t, err, "loadCheckpoint error: %v", err)
+		assert.Nil(t, cs.checkpoint.token, "expected no stored token to be loaded")
+	})
+	t.Run("only processed events are stored", func(t *testing.T) {
+		ctx := context.Background()
+		client, err := Connect(ctx, mongofake.NewServer().ClientOptions())
+		assert.Nil(t, err, "Connect error: %v", err)
+		defer func() { _ = client.Disconnect(ctx) }()
+
+		cp := options.ChangeStreamCheckpoint{Database: "db", Collection: "checkpoints", ID: "id"}
+		cs := &ChangeStream{client: client, options: options.ChangeStream().SetCheckpoint(cp)}
+		stored := func() bson.Raw {
+			var doc struct {
+				ResumeToken bson.Raw `bson:"resumeToken"`
+			}
+			err := client.Database("db").Collection("checkpoints").FindOne(ctx, bson.D{}).Decode(&doc)
+			if err == ErrNoDocuments {
+				return nil
+			}
+			assert.Nil(t, err, "FindOne error: %v", err)
+			return doc.ResumeToken
+		}
+		token := func(data string) bson.Raw {
+			raw, err := bson.Marshal(bson.D{{"_data", data}})
+			assert.Nil(t, err, "Marshal error: %v", err)
+			return raw
+		}
+
+		// the first event is returned but not processed yet
+		cs.resumeToken = token("1")
+		cs.setReturned()
+		assert.Nil(t, cs.storeCheckpoint(true), "storeCheckpoint error")
+		assert.Nil(t, stored(), "expected no checkpoint before the event is processed")
+
+		// asking for the second event acknowledges the first one
+		cs.ackReturned()
+		cs.resumeToken = token("2")
+		cs.setReturned()
+		assert.Nil(t, cs.storeCheckpoint(false), "storeCheckpoint error")
+		assert.Equal(t, token("1"), stored(), "expected the token of the processed event, got %v", stored())
+
+		// the post batch resume token is only stored once all returned events are processed
+		cs.resumeToken = token("3")
+		cs.advanceProcessed()
+		assert.Equal(t, token("1"), cs.checkpoint.processed, "expected the pending event to block the post batch token")
+		cs.ackReturned()
+		cs.advanceProcessed()
+		assert.Equal(t, token("3"), cs.checkpoint.processed, "expected the post batch token to be processed")
+	})
+	t.Run("failed writes do not stop the stream", func(t *testing.T) {
+		ctx := context.Background()
+		// nothing listens on port 1, so server selection fails
+		opts := options.Client().SetHosts([]string{"127.0.0.1:1"}).SetServerSelectionTimeout(50 * time.Millisecond)
+		client, err := Connect(ctx, opts)
+		assert.Nil(t, err, "Connect error: %v", err)
+		defer func() { _ = client.Disconnect(ctx) }()
+
+		cp := options.ChangeStreamCheckpoint{Database: "db", Collection: "checkpoints", ID: "id"}
+		cs := &ChangeStream{client: client, options: options.ChangeStream().SetCheckpoint(cp)}
+		cs.checkpoint.processed = bson.Raw{5, 0, 0, 0, 0}
+		cs.checkpoint.events = 1
+
+		err = cs.storeCheckpoint(false)
+		assert.NotNil(t, err, "expected storeCheckpoint error, got nil")
+		assert.Equal(t, err, cs.CheckpointErr(), "expected CheckpointErr %v, got %v", err, cs.CheckpointErr())
+		assert.Nil(t, cs.err, "expected the change stream to not fail, got %v", cs.err)
+		assert.True(t, checkpointDue(cs.options.Checkpoint, cs.checkpoint, time.Now()),
+			"expected the checkpoint to remain due")
+	})
+}
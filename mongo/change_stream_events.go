@@ -0,0 +1,110 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// These constants are the operationType values of the DDL change events that are only reported when the
+// ShowExpandedEvents change stream option is set.
+const (
+	OperationTypeCreate            = "create"
+	OperationTypeCreateIndexes     = "createIndexes"
+	OperationTypeDropIndexes       = "dropIndexes"
+	OperationTypeModify            = "modify"
+	OperationTypeShardCollection   = "shardCollection"
+	OperationTypeReshardCollection = "reshardCollection"
+)
+
+// ChangeEventNamespace is the namespace affected by a change event.
+type ChangeEventNamespace struct {
+	Database   string `bson:"db"`
+	Collection string `bson:"coll"`
+}
+
+// DDLEventHeader contains the fields common to the DDL change events reported when the ShowExpandedEvents change
+// stream option is set.
+type DDLEventHeader struct {
+	// ID is the resume token for the event.
+	ID             bson.Raw             `bson:"_id"`
+	OperationType  string               `bson:"operationType"`
+	ClusterTime    primitive.Timestamp  `bson:"clusterTime"`
+	WallTime       time.Time            `bson:"wallTime"`
+	CollectionUUID primitive.Binary     `bson:"collectionUUID"`
+	Namespace      ChangeEventNamespace `bson:"ns"`
+	LSID           bson.Raw             `bson:"lsid,omitempty"`
+	TxnNumber      *int64               `bson:"txnNumber,omitempty"`
+}
+
+// CreateIndexesEvent is the change event for a createIndexes operation.
+type CreateIndexesEvent struct {
+	DDLEventHeader `bson:",inline"`
+
+	OperationDescription struct {
+		// Indexes contains the specifications of the created indexes.
+		Indexes []bson.Raw `bson:"indexes"`
+	} `bson:"operationDescription"`
+}
+
+// DropIndexesEvent is the change event for a dropIndexes operation.
+type DropIndexesEvent struct {
+	DDLEventHeader `bson:",inline"`
+
+	OperationDescription struct {
+		// Indexes contains the specifications of the dropped indexes.
+		Indexes []bson.Raw `bson:"indexes"`
+	} `bson:"operationDescription"`
+}
+
+// ModifyEvent is the change event for a collMod operation.
+type ModifyEvent struct {
+	DDLEventHeader `bson:",inline"`
+
+	// OperationDescription contains the collMod options that were changed. Its fields depend on what was modified,
+	// e.g. an "index" field for index modifications or "validator" for validation changes.
+	OperationDescription bson.Raw `bson:"operationDescription"`
+
+	StateBeforeChange struct {
+		// CollectionOptions contains the collection options before the change, if collection options were modified.
+		CollectionOptions bson.Raw `bson:"collectionOptions,omitempty"`
+		// IndexOptions contains the index options before the change, if an index was modified.
+		IndexOptions bson.Raw `bson:"indexOptions,omitempty"`
+	} `bson:"stateBeforeChange"`
+}
+
+// ShardCollectionEvent is the change event for a shardCollection operation.
+type ShardCollectionEvent struct {
+	DDLEventHeader `bson:",inline"`
+
+	OperationDescription struct {
+		ShardKey            bson.Raw `bson:"shardKey"`
+		Unique              bool     `bson:"unique"`
+		NumInitialChunks    int64    `bson:"numInitialChunks,omitempty"`
+		Collation           bson.Raw `bson:"collation,omitempty"`
+		Capped              bool     `bson:"capped,omitempty"`
+		PresplitHashedZones bool     `bson:"presplitHashedZones,omitempty"`
+	} `bson:"operationDescription"`
+}
+
+// ReshardCollectionEvent is the change event for a reshardCollection operation.
+type ReshardCollectionEvent struct {
+	DDLEventHeader `bson:",inline"`
+
+	OperationDescription struct {
+		ReshardUUID      primitive.Binary `bson:"reshardUUID"`
+		ShardKey         bson.Raw         `bson:"shardKey"`
+		OldShardKey      bson.Raw         `bson:"oldShardKey"`
+		Unique           bool             `bson:"unique"`
+		NumInitialChunks int64            `bson:"numInitialChunks,omitempty"`
+		Collation        bson.Raw         `bson:"collation,omitempty"`
+		Zones            []bson.Raw       `bson:"zones,omitempty"`
+	} `bson:"operationDescription"`
+}
@@ -0,0 +1,86 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestChangeStreamDDLEvents(t *testing.T) {
+	header := bson.D{
+		{"_id", bson.D{{"_data", "token"}}},
+		{"clusterTime", primitive.Timestamp{T: 10, I: 1}},
+		{"collectionUUID", primitive.Binary{Subtype: 4, Data: make([]byte, 16)}},
+		{"ns", bson.D{{"db", "db"}, {"coll", "coll"}}},
+	}
+	event := func(t *testing.T, opType string, elems ...bson.E) bson.Raw {
+		t.Helper()
+
+		doc := append(bson.D{{"operationType", opType}}, header...)
+		doc = append(doc, elems...)
+		b, err := bson.Marshal(doc)
+		assert.Nil(t, err, "Marshal error: %v", err)
+		return b
+	}
+
+	t.Run("showExpandedEvents option", func(t *testing.T) {
+		cs := &ChangeStream{options: options.ChangeStream().SetShowExpandedEvents(true)}
+		doc := cs.createPipelineOptionsDoc()
+		assert.Nil(t, cs.err, "createPipelineOptionsDoc error: %v", cs.err)
+		got, ok := doc.Lookup("showExpandedEvents").BooleanOK()
+		assert.True(t, ok && got, "expected showExpandedEvents to be true, got %v", doc)
+	})
+	t.Run("createIndexes", func(t *testing.T) {
+		raw := event(t, OperationTypeCreateIndexes, bson.E{"operationDescription", bson.D{
+			{"indexes", bson.A{bson.D{{"v", 2}, {"key", bson.D{{"x", 1}}}, {"name", "x_1"}}}},
+		}})
+		var evt CreateIndexesEvent
+		err := bson.Unmarshal(raw, &evt)
+		assert.Nil(t, err, "Unmarshal error: %v", err)
+		assert.Equal(t, OperationTypeCreateIndexes, evt.OperationType, "expected operationType %v, got %v",
+			OperationTypeCreateIndexes, evt.OperationType)
+		assert.Equal(t, ChangeEventNamespace{Database: "db", Collection: "coll"}, evt.Namespace,
+			"expected namespace db.coll, got %v", evt.Namespace)
+		assert.Equal(t, 1, len(evt.OperationDescription.Indexes), "expected 1 index, got %v",
+			len(evt.OperationDescription.Indexes))
+		assert.Equal(t, "x_1", evt.OperationDescription.Indexes[0].Lookup("name").StringValue(),
+			"expected index x_1, got %v", evt.OperationDescription.Indexes[0])
+	})
+	t.Run("modify", func(t *testing.T) {
+		raw := event(t, OperationTypeModify,
+			bson.E{"operationDescription", bson.D{{"index", bson.D{{"name", "x_1"}, {"hidden", true}}}}},
+			bson.E{"stateBeforeChange", bson.D{{"indexOptions", bson.D{{"hidden", false}}}}},
+		)
+		var evt ModifyEvent
+		err := bson.Unmarshal(raw, &evt)
+		assert.Nil(t, err, "Unmarshal error: %v", err)
+		hidden := evt.StateBeforeChange.IndexOptions.Lookup("hidden").Boolean()
+		assert.False(t, hidden, "expected previous hidden value false")
+		assert.True(t, evt.OperationDescription.Lookup("index", "hidden").Boolean(), "expected new hidden value true")
+	})
+	t.Run("reshardCollection", func(t *testing.T) {
+		raw := event(t, OperationTypeReshardCollection, bson.E{"operationDescription", bson.D{
+			{"reshardUUID", primitive.Binary{Subtype: 4, Data: make([]byte, 16)}},
+			{"shardKey", bson.D{{"y", 1}}},
+			{"oldShardKey", bson.D{{"x", 1}}},
+			{"unique", false},
+			{"numInitialChunks", int64(8)},
+		}})
+		var evt ReshardCollectionEvent
+		err := bson.Unmarshal(raw, &evt)
+		assert.Nil(t, err, "Unmarshal error: %v", err)
+		assert.Equal(t, int64(8), evt.OperationDescription.NumInitialChunks, "expected 8 chunks, got %v",
+			evt.OperationDescription.NumInitialChunks)
+		assert.Equal(t, "x", evt.OperationDescription.OldShardKey.Index(0).Key(), "expected old shard key x, got %v",
+			evt.OperationDescription.OldShardKey)
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// emptyDoc is an empty BSON document.
+var emptyDoc = bsoncore.Document{5, 0, 0, 0, 0}
+
+// reassembleSplitEvent reads the remaining fragments of the split event in cs.Current and replaces cs.Current with the
+// reassembled event. Fragments are returned by the server in order and contain disjoint sets of top-level fields, so
+// the event is rebuilt by concatenating those fields. The _id of the reassembled event is the _id of the last fragment,
+// which is the resume token stored by the change stream.
+func (cs *ChangeStream) reassembleSplitEvent(ctx context.Context) error {
+	fragment, of, err := splitEventPosition(cs.Current)
+	if err != nil {
+		return err
+	}
+	if fragment != 1 {
+		return fmt.Errorf("expected the first fragment of a split change event, got fragment %d of %d", fragment, of)
+	}
+
+	// Fragments must be copied because the batch they are in may be overwritten by a getMore.
+	fragments := make([]bson.Raw, 0, of)
+	fragments = append(fragments, append(bson.Raw(nil), cs.Current...))
+	for len(fragments) < of {
+		if len(cs.batch) == 0 {
+			cs.loopNext(ctx, false)
+			if cs.err != nil {
+				return cs.err
+			}
+			if len(cs.batch) == 0 {
+				return fmt.Errorf("change stream ended after fragment %d of %d of a split change event",
+					len(fragments), of)
+			}
+		}
+
+		cs.Current = bson.Raw(cs.batch[0])
+		cs.batch = cs.batch[1:]
+		if err = cs.storeResumeToken(); err != nil {
+			return err
+		}
+
+		fragment, fragmentOf, err := splitEventPosition(cs.Current)
+		if err != nil {
+			return err
+		}
+		if fragment != len(fragments)+1 || fragmentOf != of {
+			return fmt.Errorf("expected fragment %d of %d of a split change event, got fragment %d of %d",
+				len(fragments)+1, of, fragment, fragmentOf)
+		}
+		fragments = append(fragments, append(bson.Raw(nil), cs.Current...))
+	}
+
+	cs.Current = mergeSplitEventFragments(fragments)
+	return nil
+}
+
+// splitEventPosition returns the fragment number and total number of fragments from the splitEvent field of a change
+// event fragment.
+func splitEventPosition(doc bson.Raw) (fragment, of int, err error) {
+	split, ok := bsoncore.Document(doc).Lookup("splitEvent").DocumentOK()
+	if !ok {
+		return 0, 0, fmt.Errorf("change event fragment is missing the splitEvent field")
+	}
+	f, ok := split.Lookup("fragment").AsInt64OK()
+	if !ok {
+		return 0, 0, fmt.Errorf("splitEvent.fragment should be a number but is a BSON %s", split.Lookup("fragment").Type)
+	}
+	o, ok := split.Lookup("of").AsInt64OK()
+	if !ok || o < 1 {
+		return 0, 0, fmt.Errorf("splitEvent.of should be a positive number but is %v", split.Lookup("of"))
+	}
+	return int(f), int(o), nil
+}
+
+// mergeSplitEventFragments builds a single change event from its fragments.
+func mergeSplitEventFragments(fragments []bson.Raw) bson.Raw {
+	idx, doc := bsoncore.AppendDocumentStart(nil)
+	doc = bsoncore.AppendValueElement(doc, "_id", bsoncore.Document(fragments[len(fragments)-1]).Lookup("_id"))
+	for _, fragment := range fragments {
+		elems, _ := fragment.Elements()
+		for _, elem := range elems {
+			switch elem.Key() {
+			case "_id", "splitEvent":
+				continue
+			}
+			doc = append(doc, elem...)
+		}
+	}
+	doc, _ = bsoncore.AppendDocumentEnd(doc, idx)
+	return bson.Raw(doc)
+}
@@ -0,0 +1,87 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+func TestChangeStreamSplitEvents(t *testing.T) {
+	marshal := func(t *testing.T, docs ...bson.D) *bsoncore.DocumentSequence {
+		t.Helper()
+
+		var data []byte
+		for _, doc := range docs {
+			b, err := bson.Marshal(doc)
+			assert.Nil(t, err, "Marshal error: %v", err)
+			data = append(data, b...)
+		}
+		return &bsoncore.DocumentSequence{Style: bsoncore.SequenceStyle, Data: data}
+	}
+
+	t.Run("split stage is appended to the pipeline", func(t *testing.T) {
+		cs := &ChangeStream{
+			registry: bson.DefaultRegistry,
+			options:  options.ChangeStream().SetSplitLargeEvents(true),
+		}
+		err := cs.buildPipelineSlice(bson.A{bson.D{{"$match", bson.D{}}}})
+		assert.Nil(t, err, "buildPipelineSlice error: %v", err)
+		assert.Equal(t, 3, len(cs.pipelineSlice), "expected 3 stages, got %v", len(cs.pipelineSlice))
+		last := cs.pipelineSlice[2]
+		_, err = last.LookupErr("$changeStreamSplitLargeEvent")
+		assert.Nil(t, err, "expected last stage to be $changeStreamSplitLargeEvent, got %v", last)
+	})
+	t.Run("fragments are reassembled", func(t *testing.T) {
+		cursor := newChannelChangeStreamCursor()
+		cs := &ChangeStream{cursor: cursor, registry: bson.DefaultRegistry}
+
+		cursor.batches <- marshal(t,
+			bson.D{{"_id", bson.D{{"token", int32(1)}}}, {"splitEvent", bson.D{{"fragment", int32(1)}, {"of", int32(3)}}},
+				{"operationType", "update"}},
+			bson.D{{"_id", bson.D{{"token", int32(2)}}}, {"splitEvent", bson.D{{"fragment", int32(2)}, {"of", int32(3)}}},
+				{"fullDocument", bson.D{{"x", int32(1)}}}},
+		)
+		cursor.batches <- marshal(t,
+			bson.D{{"_id", bson.D{{"token", int32(3)}}}, {"splitEvent", bson.D{{"fragment", int32(3)}, {"of", int32(3)}}},
+				{"fullDocumentBeforeChange", bson.D{{"x", int32(0)}}}},
+			bson.D{{"_id", bson.D{{"token", int32(4)}}}, {"operationType", "insert"}},
+		)
+
+		assert.True(t, cs.Next(context.Background()), "expected event, got error %v", cs.Err())
+		expected, err := bson.Marshal(bson.D{
+			{"_id", bson.D{{"token", int32(3)}}},
+			{"operationType", "update"},
+			{"fullDocument", bson.D{{"x", int32(1)}}},
+			{"fullDocumentBeforeChange", bson.D{{"x", int32(0)}}},
+		})
+		assert.Nil(t, err, "Marshal error: %v", err)
+		assert.Equal(t, bson.Raw(expected), cs.Current, "expected event %v, got %v", bson.Raw(expected), cs.Current)
+		got := tokenOf(t, cs.ResumeToken())
+		assert.Equal(t, int32(3), got, "expected resume token 3, got %v", got)
+
+		assert.True(t, cs.Next(context.Background()), "expected event, got error %v", cs.Err())
+		assert.Equal(t, "insert", cs.Current.Lookup("operationType").StringValue(), "expected insert event, got %v",
+			cs.Current)
+	})
+	t.Run("out of order fragments", func(t *testing.T) {
+		cursor := newChannelChangeStreamCursor()
+		cs := &ChangeStream{cursor: cursor, registry: bson.DefaultRegistry}
+
+		cursor.batches <- marshal(t,
+			bson.D{{"_id", bson.D{{"token", int32(1)}}}, {"splitEvent", bson.D{{"fragment", int32(1)}, {"of", int32(2)}}}},
+			bson.D{{"_id", bson.D{{"token", int32(2)}}}, {"operationType", "insert"}},
+		)
+		assert.False(t, cs.Next(context.Background()), "expected Next to fail")
+		assert.NotNil(t, cs.Err(), "expected error, got nil")
+	})
+}
@@ -10,13 +10,20 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
+	"net"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/clock"
 	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/logger"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readconcern"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -26,6 +33,7 @@ import (
 	"go.mongodb.org/mongo-driver/x/mongo/driver/auth"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/dns"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/operation"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/session"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
@@ -53,13 +61,20 @@ type Client struct {
 	retryWrites     bool
 	retryReads      bool
 	clock           *session.ClusterClock
-	readPreference  *readpref.ReadPref
-	readConcern     *readconcern.ReadConcern
-	writeConcern    *writeconcern.WriteConcern
+	wallClock       clock.Clock
+	defaults        atomic.Value // holds a *clientDefaults
+	defaultsLock    sync.Mutex   // serializes updates to defaults
 	registry        *bsoncodec.Registry
 	marshaller      BSONAppender
+	idGenerator     primitive.ObjectIDGenerator
 	monitor         *event.CommandMonitor
+	tracer          event.Tracer
+	txnMonitor      *event.TransactionMonitor
+	logger          *logger.Logger
 	sessionPool     *session.Pool
+	cursorTracker   *cursorTracker
+	sessionTracker  *sessionTracker
+	options         bson.M // summary of the options for Diagnostics
 
 	// client-side encryption fields
 	keyVaultClient *Client
@@ -122,7 +137,7 @@ func NewClient(opts ...*options.ClientOptions) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	client := &Client{id: id}
+	client := &Client{id: id, options: summarizeOptions(clientOpt)}
 
 	err = client.configure(clientOpt)
 	if err != nil {
@@ -138,6 +153,26 @@ func NewClient(opts ...*options.ClientOptions) (*Client, error) {
 	return client, nil
 }
 
+// NewClientWith creates a new client configured with the given functional options, which are applied in order to an
+// empty options.ClientOptions (see the options.ClientOption documentation). The With functions in the options package
+// can be mixed with application-defined options, e.g.
+//
+//	client, err := mongo.NewClientWith(
+//		options.WithHosts("localhost:27017"),
+//		options.WithMaxPoolSize(50),
+//		withMetrics(registry),
+//	)
+//
+// An error is returned if an option fails or the combination of options is invalid. As with NewClient, Connect must be
+// called before the Client can be used.
+func NewClientWith(opts ...options.ClientOption) (*Client, error) {
+	clientOpts, err := options.NewClientOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(clientOpts)
+}
+
 // Connect initializes the Client by starting background monitoring goroutines.
 // If the Client was created using the NewClient function, this method must be called before a Client can be used.
 //
@@ -170,7 +205,7 @@ func (c *Client) Connect(ctx context.Context) error {
 		}
 		updateChan = sub.Updates
 	}
-	c.sessionPool = session.NewPool(updateChan)
+	c.sessionPool = session.NewPoolWithClock(updateChan, c.wallClock)
 	return nil
 }
 
@@ -202,12 +237,77 @@ func (c *Client) Disconnect(ctx context.Context) error {
 		c.crypt.Close()
 	}
 
+	defer c.logger.Close()
+
 	if disconnector, ok := c.deployment.(driver.Disconnector); ok {
 		return replaceErrors(disconnector.Disconnect(ctx))
 	}
 	return nil
 }
 
+// DrainReport describes the work that was still in progress when a Client finished draining.
+type DrainReport struct {
+	// Drained is true if all in-flight work finished before the context passed to Drain expired.
+	Drained bool
+
+	// AbandonedSessions is the number of sessions, including the implicit sessions used by in-flight operations and
+	// open cursors, that had not been ended when the client was disconnected.
+	AbandonedSessions int
+
+	// AbandonedConnections is the number of connections that were still checked out of a connection pool when the
+	// client was disconnected.
+	AbandonedConnections int
+}
+
+// drainPollInterval is the interval at which Drain checks whether in-flight work has finished.
+const drainPollInterval = 10 * time.Millisecond
+
+// Drain gracefully disconnects the client. It first stops the client from starting new operations, which will fail
+// with ErrClientDraining, while allowing operations, cursors, and sessions that were already started to continue. It
+// then waits until all sessions have been ended and all connections have been returned to their pools or until the
+// context expires, whichever happens first, and finally disconnects the client as Disconnect does. The returned
+// report describes the work that was abandoned when the context expired.
+func (c *Client) Drain(ctx context.Context) (DrainReport, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.sessionPool != nil {
+		c.sessionPool.Drain()
+	}
+
+	report := c.drainReport()
+	if !report.Drained {
+		ticker := time.NewTicker(drainPollInterval)
+		defer ticker.Stop()
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				break wait
+			case <-ticker.C:
+				if report = c.drainReport(); report.Drained {
+					break wait
+				}
+			}
+		}
+	}
+
+	return report, c.Disconnect(ctx)
+}
+
+// drainReport returns the work that is still in progress on the client.
+func (c *Client) drainReport() DrainReport {
+	var report DrainReport
+	if c.sessionPool != nil {
+		report.AbandonedSessions = c.sessionPool.CheckedOut()
+	}
+	for _, stats := range c.PoolStats() {
+		report.AbandonedConnections += int(stats.InUse)
+	}
+	report.Drained = report.AbandonedSessions == 0 && report.AbandonedConnections == 0
+	return report
+}
+
 // Ping sends a ping command to verify that the client can connect to the deployment.
 //
 // The rp paramter is used to determine which server is selected for the operation.
@@ -225,7 +325,7 @@ func (c *Client) Ping(ctx context.Context, rp *readpref.ReadPref) error {
 	}
 
 	if rp == nil {
-		rp = c.readPreference
+		rp = c.ReadPreference()
 	}
 
 	db := c.Database("admin")
@@ -246,10 +346,11 @@ func (c *Client) StartSession(opts ...*options.SessionOptions) (Session, error)
 	}
 
 	sopts := options.MergeSessionOptions(opts...)
+	defaults := c.loadDefaults()
 	coreOpts := &session.ClientOptions{
-		DefaultReadConcern:    c.readConcern,
-		DefaultReadPreference: c.readPreference,
-		DefaultWriteConcern:   c.writeConcern,
+		DefaultReadConcern:    defaults.readConcern,
+		DefaultReadPreference: defaults.readPreference,
+		DefaultWriteConcern:   defaults.writeConcern,
 	}
 	if sopts.CausalConsistency != nil {
 		coreOpts.CausalConsistency = sopts.CausalConsistency
@@ -266,6 +367,10 @@ func (c *Client) StartSession(opts ...*options.SessionOptions) (Session, error)
 	if sopts.DefaultMaxCommitTime != nil {
 		coreOpts.DefaultMaxCommitTime = sopts.DefaultMaxCommitTime
 	}
+	if sopts.Snapshot != nil {
+		coreOpts.Snapshot = sopts.Snapshot
+		coreOpts.SnapshotTime = sopts.SnapshotTime
+	}
 
 	sess, err := session.NewClientSession(c.sessionPool, c.id, session.Explicit, coreOpts)
 	if err != nil {
@@ -279,6 +384,7 @@ func (c *Client) StartSession(opts ...*options.SessionOptions) (Session, error)
 		clientSession: sess,
 		client:        c,
 		deployment:    c.deployment,
+		leakTimer:     c.sessionTracker.track(),
 	}, nil
 }
 
@@ -358,9 +464,16 @@ func (c *Client) configure(opts *options.ClientOptions) error {
 			func(opts ...string) []string { return append(opts, comps...) },
 		))
 	}
+	// LoadBalanced
+	loadBalanced := opts.LoadBalanced != nil && *opts.LoadBalanced
+	if loadBalanced {
+		topologyOpts = append(topologyOpts, topology.WithLoadBalanced(
+			func(bool) bool { return true },
+		))
+	}
 	// Handshaker
 	var handshaker = func(driver.Handshaker) driver.Handshaker {
-		return operation.NewIsMaster().AppName(appName).Compressors(comps)
+		return operation.NewIsMaster().AppName(appName).Compressors(comps).LoadBalanced(loadBalanced)
 	}
 	// Auth & Database & Password & Username
 	if opts.Auth != nil {
@@ -391,6 +504,7 @@ func (c *Client) configure(opts *options.ClientOptions) error {
 			AppName:       appName,
 			Authenticator: authenticator,
 			Compressors:   comps,
+			LoadBalanced:  loadBalanced,
 		}
 		if mechanism == "" {
 			// Required for SASL mechanism negotiation during handshake
@@ -423,6 +537,54 @@ func (c *Client) configure(opts *options.ClientOptions) error {
 			func(topology.Dialer) topology.Dialer { return opts.Dialer },
 		))
 	}
+	// KeepAliveInterval & KeepAliveCount & TCPUserTimeout
+	if opts.KeepAliveInterval != nil {
+		connOpts = append(connOpts, topology.WithKeepAlive(
+			func(time.Duration) time.Duration { return *opts.KeepAliveInterval },
+		))
+	}
+	if opts.KeepAliveCount != nil {
+		connOpts = append(connOpts, topology.WithKeepAliveCount(
+			func(int) int { return *opts.KeepAliveCount },
+		))
+	}
+	if opts.TCPUserTimeout != nil {
+		connOpts = append(connOpts, topology.WithTCPUserTimeout(
+			func(time.Duration) time.Duration { return *opts.TCPUserTimeout },
+		))
+	}
+	// Resolver
+	if opts.Resolver != nil {
+		topologyOpts = append(topologyOpts, topology.WithDNSResolver(
+			func(*dns.Resolver) *dns.Resolver { return dns.NewResolver(opts.Resolver) },
+		))
+		connOpts = append(connOpts, topology.WithHostResolver(
+			func(dns.HostResolver) dns.HostResolver { return opts.Resolver },
+		))
+	}
+	// Proxy
+	if opts.ProxyHost != nil && *opts.ProxyHost != "" {
+		port := 1080 // default SOCKS port
+		if opts.ProxyPort != nil {
+			port = *opts.ProxyPort
+		}
+		proxy := &topology.ProxyConfig{Address: net.JoinHostPort(*opts.ProxyHost, strconv.Itoa(port))}
+		if opts.ProxyUsername != nil {
+			proxy.Username = *opts.ProxyUsername
+		}
+		if opts.ProxyPassword != nil {
+			proxy.Password = *opts.ProxyPassword
+		}
+		if (proxy.Username == "") != (proxy.Password == "") {
+			return errors.New("proxy username and password must be specified together")
+		}
+
+		connOpts = append(connOpts, topology.WithProxy(
+			func(*topology.ProxyConfig) *topology.ProxyConfig { return proxy },
+		))
+	} else if opts.ProxyPort != nil || opts.ProxyUsername != nil || opts.ProxyPassword != nil {
+		return errors.New("proxy port, username, and password cannot be specified without a proxy host")
+	}
 	// Direct
 	if opts.Direct != nil && *opts.Direct {
 		topologyOpts = append(topologyOpts, topology.WithMode(
@@ -435,6 +597,18 @@ func (c *Client) configure(opts *options.ClientOptions) error {
 			func(time.Duration) time.Duration { return *opts.HeartbeatInterval },
 		))
 	}
+	// HeartbeatConnectTimeout
+	if opts.HeartbeatConnectTimeout != nil {
+		serverOpts = append(serverOpts, topology.WithHeartbeatConnectTimeout(
+			func(time.Duration) time.Duration { return *opts.HeartbeatConnectTimeout },
+		))
+	}
+	// MinHeartbeatInterval
+	if opts.MinHeartbeatInterval != nil {
+		serverOpts = append(serverOpts, topology.WithMinHeartbeatInterval(
+			func(time.Duration) time.Duration { return *opts.MinHeartbeatInterval },
+		))
+	}
 	// Hosts
 	hosts := []string{"localhost:27017"} // default host
 	if len(opts.Hosts) > 0 {
@@ -454,6 +628,54 @@ func (c *Client) configure(opts *options.ClientOptions) error {
 			func(time.Duration) time.Duration { return *opts.MaxConnIdleTime },
 		))
 	}
+	// ConnExpiryJitter
+	if opts.ConnExpiryJitter != nil {
+		connOpts = append(connOpts, topology.WithExpiryJitter(
+			func(time.Duration) time.Duration { return *opts.ConnExpiryJitter },
+		))
+	}
+	// MaxConnecting
+	if opts.MaxConnecting != nil {
+		serverOpts = append(
+			serverOpts,
+			topology.WithMaxConnecting(func(uint64) uint64 { return *opts.MaxConnecting }),
+		)
+	}
+	// MaxConnectionRate
+	if opts.MaxConnectionRate != nil {
+		serverOpts = append(
+			serverOpts,
+			topology.WithMaxConnectionRate(func(uint64) uint64 { return *opts.MaxConnectionRate }),
+		)
+	}
+	// PoolClearBackoff
+	if opts.PoolClearBackoff != nil {
+		serverOpts = append(
+			serverOpts,
+			topology.WithPoolClearBackoff(func(time.Duration) time.Duration { return *opts.PoolClearBackoff }),
+		)
+	}
+	// MaxPoolClearBackoff
+	if opts.MaxPoolClearBackoff != nil {
+		serverOpts = append(
+			serverOpts,
+			topology.WithMaxPoolClearBackoff(func(time.Duration) time.Duration { return *opts.MaxPoolClearBackoff }),
+		)
+	}
+	// MaxOperationsPerServer
+	if opts.MaxOperationsPerServer != nil {
+		serverOpts = append(
+			serverOpts,
+			topology.WithMaxOperations(func(uint64) uint64 { return *opts.MaxOperationsPerServer }),
+		)
+	}
+	// OperationQueueTimeout
+	if opts.OperationQueueTimeout != nil {
+		serverOpts = append(
+			serverOpts,
+			topology.WithOperationQueueTimeout(func(time.Duration) time.Duration { return *opts.OperationQueueTimeout }),
+		)
+	}
 	// MaxPoolSize
 	if opts.MaxPoolSize != nil {
 		serverOpts = append(
@@ -468,35 +690,74 @@ func (c *Client) configure(opts *options.ClientOptions) error {
 			topology.WithMinConnections(func(uint64) uint64 { return *opts.MinPoolSize }),
 		)
 	}
+	// LoggerOptions
+	lo := opts.LoggerOptions
+	if lo == nil {
+		lo = options.Logger()
+	}
+	levels := make(map[logger.Component]logger.Level, len(lo.ComponentLevels))
+	for component, level := range lo.ComponentLevels {
+		levels[logger.Component(component)] = logger.Level(level)
+	}
+	var sink logger.LogSink
+	if lo.Sink != nil {
+		sink = lo.Sink
+	}
+	log, err := logger.New(sink, lo.MaxDocumentLength, levels)
+	if err != nil {
+		return err
+	}
+	c.logger = log
+	if opts.UnknownURIOptionPolicy != nil && *opts.UnknownURIOptionPolicy == options.UnknownURIOptionWarn {
+		if names := opts.UnknownURIOptionNames(); len(names) > 0 {
+			log.Print(logger.ComponentClient, logger.LevelInfo, logger.UnknownURIOptions,
+				"options", strings.Join(names, ", "))
+		}
+	}
+	if log != nil {
+		topologyOpts = append(topologyOpts, topology.WithLogger(func(*logger.Logger) *logger.Logger { return log }))
+	}
 	// PoolMonitor
-	if opts.PoolMonitor != nil {
+	if poolMonitor := logger.PoolMonitor(log, opts.PoolMonitor); poolMonitor != nil {
 		serverOpts = append(
 			serverOpts,
-			topology.WithConnectionPoolMonitor(func(*event.PoolMonitor) *event.PoolMonitor { return opts.PoolMonitor }),
+			topology.WithConnectionPoolMonitor(func(*event.PoolMonitor) *event.PoolMonitor { return poolMonitor }),
 		)
 	}
+	// CursorLeakThreshold
+	if opts.CursorLeakThreshold != nil && *opts.CursorLeakThreshold > 0 {
+		c.cursorTracker = newCursorTracker(*opts.CursorLeakThreshold, opts.CursorMonitor, log)
+	}
+	// SessionLeakThreshold
+	// sessions are only tracked if the leaks can be logged
+	if opts.SessionLeakThreshold != nil && *opts.SessionLeakThreshold > 0 &&
+		log.Enabled(logger.ComponentClient, logger.LevelInfo) {
+		c.sessionTracker = newSessionTracker(*opts.SessionLeakThreshold, log)
+	}
 	// Monitor
-	if opts.Monitor != nil {
-		c.monitor = opts.Monitor
+	if monitor := logger.CommandMonitor(log, opts.Monitor); monitor != nil {
+		c.monitor = monitor
 		connOpts = append(connOpts, topology.WithMonitor(
-			func(*event.CommandMonitor) *event.CommandMonitor { return opts.Monitor },
+			func(*event.CommandMonitor) *event.CommandMonitor { return monitor },
 		))
 	}
 	// ReadConcern
-	c.readConcern = readconcern.New()
+	defaults := &clientDefaults{readConcern: readconcern.New()}
 	if opts.ReadConcern != nil {
-		c.readConcern = opts.ReadConcern
+		defaults.readConcern = opts.ReadConcern
 	}
 	// ReadPreference
-	c.readPreference = readpref.Primary()
+	defaults.readPreference = readpref.Primary()
 	if opts.ReadPreference != nil {
-		c.readPreference = opts.ReadPreference
+		defaults.readPreference = opts.ReadPreference
 	}
 	// Registry
 	c.registry = bson.DefaultRegistry
 	if opts.Registry != nil {
 		c.registry = opts.Registry
 	}
+	// ObjectIDGenerator
+	c.idGenerator = opts.ObjectIDGenerator
 	// ReplicaSet
 	if opts.ReplicaSet != nil {
 		topologyOpts = append(topologyOpts, topology.WithReplicaSetName(
@@ -518,6 +779,102 @@ func (c *Client) configure(opts *options.ClientOptions) error {
 			func(time.Duration) time.Duration { return *opts.ServerSelectionTimeout },
 		))
 	}
+	// ServerSelector
+	if opts.ServerSelector != nil {
+		topologyOpts = append(topologyOpts, topology.WithServerSelector(
+			func(description.ServerSelector) description.ServerSelector { return opts.ServerSelector },
+		))
+	}
+	// SRVPollingInterval
+	if opts.SRVPollingInterval != nil {
+		topologyOpts = append(topologyOpts, topology.WithSRVPollingInterval(
+			func(time.Duration) time.Duration { return *opts.SRVPollingInterval },
+		))
+	}
+	// ServerSelectionMonitor
+	if opts.ServerSelectionMonitor != nil {
+		topologyOpts = append(topologyOpts, topology.WithServerSelectionMonitor(
+			func(*event.ServerSelectionMonitor) *event.ServerSelectionMonitor { return opts.ServerSelectionMonitor },
+		))
+	}
+	// ServerMonitor
+	if opts.ServerMonitor != nil {
+		serverOpts = append(
+			serverOpts,
+			topology.WithServerMonitor(func(*event.ServerMonitor) *event.ServerMonitor { return opts.ServerMonitor }),
+		)
+	}
+	// TopologyMonitor
+	if topologyMonitor := logger.TopologyMonitor(log, opts.TopologyMonitor); topologyMonitor != nil {
+		topologyOpts = append(topologyOpts, topology.WithTopologyMonitor(
+			func(*event.TopologyMonitor) *event.TopologyMonitor { return topologyMonitor },
+		))
+	}
+	// SlowOperationThreshold & SlowOperationMonitor
+	if opts.SlowOperationThreshold != nil && *opts.SlowOperationThreshold > 0 {
+		topologyOpts = append(topologyOpts,
+			topology.WithSlowOperationThreshold(func(time.Duration) time.Duration { return *opts.SlowOperationThreshold }),
+			topology.WithSlowOperationMonitor(
+				func(*event.SlowOperationMonitor) *event.SlowOperationMonitor { return opts.SlowOperationMonitor },
+			),
+		)
+	}
+	// Tracer
+	if opts.Tracer != nil {
+		c.tracer = opts.Tracer
+		topologyOpts = append(topologyOpts, topology.WithTracer(
+			func(event.Tracer) event.Tracer { return opts.Tracer },
+		))
+	}
+	// TransactionMonitor
+	c.txnMonitor = opts.TransactionMonitor
+	// ProfilerLabels
+	if opts.ProfilerLabels != nil {
+		topologyOpts = append(topologyOpts, topology.WithProfilerLabels(
+			func(bool) bool { return *opts.ProfilerLabels },
+		))
+	}
+	// OperationProfilerLabels
+	if opts.OperationProfilerLabels != nil {
+		topologyOpts = append(topologyOpts, topology.WithOperationProfilerLabels(
+			func(bool) bool { return *opts.OperationProfilerLabels },
+		))
+	}
+	// CommentFunc
+	if opts.CommentFunc != nil {
+		commentFn := func(ctx context.Context) bsoncore.Value {
+			comment := opts.CommentFunc(ctx)
+			if comment == nil {
+				return bsoncore.Value{}
+			}
+			val, err := transformValue(c.registry, comment)
+			if err != nil {
+				return bsoncore.Value{}
+			}
+			return val
+		}
+		topologyOpts = append(topologyOpts, topology.WithCommentFunc(
+			func(func(context.Context) bsoncore.Value) func(context.Context) bsoncore.Value { return commentFn },
+		))
+	}
+	// WeightedServerSelection
+	if opts.WeightedServerSelection != nil {
+		topologyOpts = append(topologyOpts, topology.WithWeightedServerSelection(
+			func(bool) bool { return *opts.WeightedServerSelection },
+		))
+	}
+	// MongosSelection
+	if opts.MongosSelection != nil {
+		topologyOpts = append(topologyOpts, topology.WithMongosSelection(
+			func(string) string { return *opts.MongosSelection },
+		))
+	}
+	// MongosRebalanceInterval
+	if opts.MongosRebalanceInterval != nil {
+		topologyOpts = append(topologyOpts, topology.WithMongosRebalanceInterval(
+			func(time.Duration) time.Duration { return *opts.MongosRebalanceInterval },
+		))
+	}
 	// SocketTimeout
 	if opts.SocketTimeout != nil {
 		connOpts = append(
@@ -536,8 +893,9 @@ func (c *Client) configure(opts *options.ClientOptions) error {
 	}
 	// WriteConcern
 	if opts.WriteConcern != nil {
-		c.writeConcern = opts.WriteConcern
+		defaults.writeConcern = opts.WriteConcern
 	}
+	c.defaults.Store(defaults)
 	// AutoEncryptionOptions
 	if opts.AutoEncryptionOptions != nil {
 		if err := c.configureAutoEncryption(opts.AutoEncryptionOptions); err != nil {
@@ -547,19 +905,23 @@ func (c *Client) configure(opts *options.ClientOptions) error {
 
 	// ClusterClock
 	c.clock = new(session.ClusterClock)
+	// Clock
+	c.wallClock = clock.OrSystem(opts.Clock)
 
 	serverOpts = append(
 		serverOpts,
 		topology.WithClock(func(*session.ClusterClock) *session.ClusterClock { return c.clock }),
+		topology.WithServerWallClock(func(clock.Clock) clock.Clock { return c.wallClock }),
 		topology.WithConnectionOptions(func(...topology.ConnectionOption) []topology.ConnectionOption { return connOpts }),
 	)
-	c.topologyOptions = append(topologyOpts, topology.WithServerOptions(
-		func(...topology.ServerOption) []topology.ServerOption { return serverOpts },
-	))
+	c.topologyOptions = append(topologyOpts,
+		topology.WithWallClock(func(clock.Clock) clock.Clock { return c.wallClock }),
+		topology.WithServerOptions(func(...topology.ServerOption) []topology.ServerOption { return serverOpts }),
+	)
 
 	// Deployment
 	if opts.Deployment != nil {
-		if len(serverOpts) > 2 || len(topologyOpts) > 1 {
+		if len(serverOpts) > 3 || len(topologyOpts) > 1 {
 			return errors.New("cannot specify topology or server options with a deployment")
 		}
 		c.deployment = opts.Deployment
@@ -572,10 +934,14 @@ func (c *Client) configureAutoEncryption(opts *options.AutoEncryptionOptions) er
 	if err := c.configureKeyVault(opts); err != nil {
 		return err
 	}
-	if err := c.configureMongocryptd(opts); err != nil {
+	if err := c.configureCrypt(opts); err != nil {
 		return err
 	}
-	return c.configureCrypt(opts)
+	// mongocryptd is not needed if commands are marked by the crypt_shared library
+	if c.crypt.CryptSharedLibVersion() != "" {
+		return nil
+	}
+	return c.configureMongocryptd(opts)
 }
 
 func (c *Client) configureKeyVault(opts *options.AutoEncryptionOptions) error {
@@ -600,7 +966,10 @@ func (c *Client) configureKeyVault(opts *options.AutoEncryptionOptions) error {
 func (c *Client) configureMongocryptd(opts *options.AutoEncryptionOptions) error {
 	var err error
 	c.mongocryptd, err = newMcryptClient(opts)
-	return err
+	if err != nil {
+		return fmt.Errorf("the crypt_shared library was not loaded and mongocryptd could not be spawned: %v", err)
+	}
+	return nil
 }
 
 func (c *Client) configureCrypt(opts *options.AutoEncryptionOptions) error {
@@ -624,17 +993,41 @@ func (c *Client) configureCrypt(opts *options.AutoEncryptionOptions) error {
 	cryptOpts := &driver.CryptOptions{
 		CollInfoFn:           cir.cryptCollInfo,
 		KeyFn:                kr.cryptKeys,
-		MarkFn:               c.mongocryptd.markCommand,
+		MarkFn:               c.markCommand,
 		KmsProviders:         opts.KmsProviders,
+		TLSConfig:            opts.TLSConfig,
+		KmsCredentialsFn:     transformKmsCredentialsFuncs(opts.KmsCredentialsFunc),
 		BypassAutoEncryption: bypass,
 		SchemaMap:            cryptSchemaMap,
 	}
+	// the crypt_shared library is only used to mark commands, which is not done if auto encryption is bypassed
+	if !bypass {
+		cryptOpts.CryptSharedLibSearchPaths = opts.CryptSharedLibSearchPaths
+		if opts.CryptSharedLibPath != nil {
+			cryptOpts.CryptSharedLibPath = *opts.CryptSharedLibPath
+		}
+		if opts.CryptSharedLibRequired != nil {
+			cryptOpts.CryptSharedLibRequired = *opts.CryptSharedLibRequired
+		}
+		if opts.CryptSharedLibMinVersion != nil {
+			cryptOpts.CryptSharedLibMinVersion = *opts.CryptSharedLibMinVersion
+		}
+	}
 
 	var err error
 	c.crypt, err = driver.NewCrypt(cryptOpts)
 	return err
 }
 
+// markCommand marks the given command for encryption using mongocryptd. It is only called if the crypt_shared library
+// was not loaded.
+func (c *Client) markCommand(ctx context.Context, db string, cmd bsoncore.Document) (bsoncore.Document, error) {
+	if c.mongocryptd == nil {
+		return nil, errors.New("commands cannot be marked for encryption without mongocryptd or the crypt_shared library")
+	}
+	return c.mongocryptd.markCommand(ctx, db, cmd)
+}
+
 // validSession returns an error if the session doesn't belong to the client
 func (c *Client) validSession(sess *session.Client) error {
 	if sess != nil && !uuid.Equal(sess.ClientID, c.id) {
@@ -643,6 +1036,71 @@ func (c *Client) validSession(sess *session.Client) error {
 	return nil
 }
 
+// clientDefaults holds the read preference, read concern, and write concern used by a Client for operations that do
+// not specify their own. It is immutable once stored so that it can be read without locking.
+type clientDefaults struct {
+	readPreference *readpref.ReadPref
+	readConcern    *readconcern.ReadConcern
+	writeConcern   *writeconcern.WriteConcern
+}
+
+func (c *Client) loadDefaults() *clientDefaults {
+	return c.defaults.Load().(*clientDefaults)
+}
+
+// updateDefaults replaces the defaults of the Client with a copy that has been modified by update.
+func (c *Client) updateDefaults(update func(*clientDefaults)) {
+	c.defaultsLock.Lock()
+	defer c.defaultsLock.Unlock()
+
+	defaults := *c.loadDefaults()
+	update(&defaults)
+	c.defaults.Store(&defaults)
+}
+
+// ReadPreference returns the current default read preference of the Client.
+func (c *Client) ReadPreference() *readpref.ReadPref {
+	return c.loadDefaults().readPreference
+}
+
+// ReadConcern returns the current default read concern of the Client.
+func (c *Client) ReadConcern() *readconcern.ReadConcern {
+	return c.loadDefaults().readConcern
+}
+
+// WriteConcern returns the current default write concern of the Client. A nil write concern means that the server's
+// default write concern is used.
+func (c *Client) WriteConcern() *writeconcern.WriteConcern {
+	return c.loadDefaults().writeConcern
+}
+
+// SetReadPreference changes the default read preference of the Client. It can be called at any time, including while
+// operations are running, and is safe for concurrent use. Operations that start after SetReadPreference returns use
+// the new read preference, as do Database and Collection handles that were not configured with a read preference of
+// their own, including handles created before the change. Sessions that have already been started are not affected.
+// If rp is nil, the default read preference is reset to primary.
+func (c *Client) SetReadPreference(rp *readpref.ReadPref) {
+	if rp == nil {
+		rp = readpref.Primary()
+	}
+	c.updateDefaults(func(defaults *clientDefaults) { defaults.readPreference = rp })
+}
+
+// SetReadConcern changes the default read concern of the Client. It is applied in the same way as SetReadPreference.
+// If rc is nil, the default read concern is reset to the server's default.
+func (c *Client) SetReadConcern(rc *readconcern.ReadConcern) {
+	if rc == nil {
+		rc = readconcern.New()
+	}
+	c.updateDefaults(func(defaults *clientDefaults) { defaults.readConcern = rc })
+}
+
+// SetWriteConcern changes the default write concern of the Client. It is applied in the same way as
+// SetReadPreference. If wc is nil, the default write concern is reset to the server's default.
+func (c *Client) SetWriteConcern(wc *writeconcern.WriteConcern) {
+	c.updateDefaults(func(defaults *clientDefaults) { defaults.writeConcern = wc })
+}
+
 // Database returns a handle for a database with the given name configured with the given DatabaseOptions.
 func (c *Client) Database(name string, opts ...*options.DatabaseOptions) *Database {
 	return newDatabase(c, name, opts...)
@@ -691,7 +1149,7 @@ func (c *Client) ListDatabases(ctx context.Context, filter interface{}, opts ...
 
 	ldo := options.MergeListDatabasesOptions(opts...)
 	op := operation.NewListDatabases(filterDoc).
-		Session(sess).ReadPreference(c.readPreference).CommandMonitor(c.monitor).
+		Session(sess).ReadPreference(c.ReadPreference()).CommandMonitor(c.monitor).
 		ServerSelector(selector).ClusterClock(c.clock).Database("admin").Deployment(c.deployment).Crypt(c.crypt)
 	if ldo.NameOnly != nil {
 		op = op.NameOnly(*ldo.NameOnly)
@@ -777,6 +1235,20 @@ func (c *Client) UseSessionWithOptions(ctx context.Context, opts *options.Sessio
 	return fn(sessCtx)
 }
 
+// ReadAtSnapshot creates a snapshot session and runs fn with it, so that the reads in fn all see the data as it was at
+// a single point in time, even if they read from different databases and collections. The time is chosen by the server
+// for the first read in fn unless it is set with the SnapshotTime option, and can be retrieved with the
+// sessCtx.SnapshotTime method. The Snapshot option is always set to true. The session is ended when fn returns. Snapshot
+// reads are supported by the find, aggregate, and distinct operations and require MongoDB 5.0 or later.
+//
+// Any error returned by the fn callback will be returned without any modifications.
+func (c *Client) ReadAtSnapshot(ctx context.Context, fn func(sessCtx SessionContext) error,
+	opts ...*options.SessionOptions) error {
+
+	sopts := options.MergeSessionOptions(opts...).SetSnapshot(true)
+	return c.UseSessionWithOptions(ctx, sopts, fn)
+}
+
 // Watch returns a change stream for all changes on the deployment. See
 // https://docs.mongodb.com/manual/changeStreams/ for more information about change streams.
 //
@@ -796,9 +1268,10 @@ func (c *Client) Watch(ctx context.Context, pipeline interface{},
 		return nil, ErrClientDisconnected
 	}
 
+	defaults := c.loadDefaults()
 	csConfig := changeStreamConfig{
-		readConcern:    c.readConcern,
-		readPreference: c.readPreference,
+		readConcern:    defaults.readConcern,
+		readPreference: defaults.readPreference,
 		client:         c,
 		registry:       c.registry,
 		streamType:     ClientStream,
@@ -810,5 +1283,166 @@ func (c *Client) Watch(ctx context.Context, pipeline interface{},
 // NumberSessionsInProgress returns the number of sessions that have been started for this client but have not been
 // closed (i.e. EndSession has not been called).
 func (c *Client) NumberSessionsInProgress() int {
+	if c.sessionPool == nil {
+		// The session pool is created by Connect.
+		return 0
+	}
 	return c.sessionPool.CheckedOut()
 }
+
+// WarmUp blocks until the connection pool for each server that is suitable for the client's read preference has
+// established at least the number of connections configured with options.ClientOptions.SetMinPoolSize, so that the
+// first operations sent by the application do not have to wait for connection handshakes. Without a minimum pool size,
+// WarmUp only waits for a suitable server to be discovered. It returns an error if a connection cannot be established
+// or ctx expires first.
+//
+// WarmUp must be called after Connect.
+func (c *Client) WarmUp(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	t, ok := c.deployment.(*topology.Topology)
+	if !ok {
+		return nil
+	}
+	selector := description.CompositeSelector([]description.ServerSelector{
+		description.ReadPrefSelector(c.ReadPreference()),
+		description.LatencySelector(c.localThreshold),
+	})
+	return replaceErrors(t.WarmUp(ctx, selector))
+}
+
+// WaitUntilReady blocks until the deployment has servers that are ready to serve operations or ctx expires. By
+// default, it waits until a writable primary is selectable. The options can be used to wait for servers matching
+// another read preference, such as readpref.Nearest() for any data-bearing member, and for a minimum number of such
+// servers. Unlike Ping, WaitUntilReady is not bounded by the server selection timeout, so ctx should have a deadline.
+//
+// WaitUntilReady must be called after Connect.
+func (c *Client) WaitUntilReady(ctx context.Context, opts ...*options.WaitUntilReadyOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	t, ok := c.deployment.(*topology.Topology)
+	if !ok {
+		return nil
+	}
+
+	wuro := options.MergeWaitUntilReadyOptions(opts...)
+	rp := readpref.Primary()
+	if wuro.ReadPreference != nil {
+		rp = wuro.ReadPreference
+	}
+	n := 1
+	if wuro.MinServers != nil && *wuro.MinServers > 1 {
+		n = *wuro.MinServers
+	}
+	return replaceErrors(t.WaitForServers(ctx, description.ReadPrefSelector(rp), n))
+}
+
+// PoolStats returns a snapshot of the connection pool statistics for each server known to the client, ordered by server
+// address. See the event.PoolStats documentation for the statistics that are reported. If the client was not created
+// with a deployment that supports pool statistics, nil is returned.
+func (c *Client) PoolStats() []event.PoolStats {
+	t, ok := c.deployment.(*topology.Topology)
+	if !ok {
+		return nil
+	}
+	return t.PoolStats()
+}
+
+// UpdateHosts merges a new list of hosts, each in "host:port" form, into the deployment the client is connected to,
+// without recreating the client. It is intended for deployments whose membership is pushed from a control plane rather
+// than published in DNS. Servers are added for hosts the client does not know about. For sharded clusters, servers
+// whose hosts are not in the list are removed; the members of a replica set are still discovered from the replica set
+// configuration, so none are removed. UpdateHosts returns an error if the client uses a direct connection, a load
+// balancer, or a mongodb+srv URI, or if it was created with a custom deployment.
+func (c *Client) UpdateHosts(hosts []string) error {
+	t, ok := c.deployment.(*topology.Topology)
+	if !ok {
+		return errors.New("hosts can only be updated for clients created without a custom deployment")
+	}
+	return replaceErrors(t.UpdateHosts(hosts))
+}
+
+// RecycleConnections replaces every connection in the client's connection pools and the connections used to monitor
+// the servers without interrupting operations in progress, for example to rotate connections after TLS certificates or
+// credentials have changed. Idle connections are closed immediately, connections in use are closed when they are
+// checked back in, and new connections are established as they are needed. RecycleConnections blocks until all of the
+// pooled connections that were open when it was called have been closed or the context expires, in which case the
+// context's error is returned and the remaining connections are still replaced later. Connections pinned to a cursor
+// or transaction of a load balanced deployment are only checked in when the cursor is closed or the transaction ends,
+// so the context should have a deadline. If the client was not created with a deployment that supports this,
+// RecycleConnections does nothing.
+func (c *Client) RecycleConnections(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	t, ok := c.deployment.(*topology.Topology)
+	if !ok {
+		return nil
+	}
+	return t.RecycleConnections(ctx)
+}
+
+// Suspend stops the client from monitoring the deployment until Resume is called. Heartbeats and SRV polling are paused,
+// but operations can still be run. It is intended for function-as-a-service platforms such as AWS Lambda, where the
+// process is frozen between invocations: call Suspend before returning from an invocation and Resume at the start of
+// the next one. If the client was not created with a deployment that supports this, Suspend does nothing.
+func (c *Client) Suspend() {
+	if t, ok := c.deployment.(*topology.Topology); ok {
+		t.Suspend()
+	}
+}
+
+// Resume resumes monitoring stopped by Suspend. Each server is checked immediately. Existing connections are kept and
+// are only closed if the check or an operation using them fails, so resuming does not cause a burst of new connections.
+func (c *Client) Resume() {
+	if t, ok := c.deployment.(*topology.Topology); ok {
+		t.Resume()
+	}
+}
+
+// TopologyDescription returns a snapshot of what the client currently knows about the deployment it is connected to,
+// including the type of the deployment and the type, round trip time, last error, tags, and supported wire versions of
+// each server. The snapshot is not updated as the deployment changes. If the client was not created with a deployment
+// that supports this, the zero value is returned.
+func (c *Client) TopologyDescription() event.TopologyDescription {
+	t, ok := c.deployment.(*topology.Topology)
+	if !ok {
+		return event.TopologyDescription{}
+	}
+	return t.Snapshot()
+}
+
+// RTTStats returns a snapshot of the round trip times measured by the heartbeats of each server known to the client,
+// ordered by server address. See the event.RTTStats documentation for the statistics that are reported. If the client
+// was not created with a deployment that supports round trip time statistics, nil is returned.
+func (c *Client) RTTStats() []event.RTTStats {
+	t, ok := c.deployment.(*topology.Topology)
+	if !ok {
+		return nil
+	}
+	return t.RTTStats()
+}
+
+// SessionPoolStats returns a snapshot of the number of server sessions that are checked out by explicit and implicit
+// sessions, available in the client's session pool, and discarded because they expired. If the client is not
+// connected, the zero value is returned.
+func (c *Client) SessionPoolStats() event.SessionPoolStats {
+	if c.sessionPool == nil {
+		return event.SessionPoolStats{}
+	}
+	return c.sessionPool.Stats()
+}
+
+// OpenCursors returns the cursors created by this client that have not been closed or exhausted, oldest first. Cursor
+// tracking must be enabled with the options.ClientOptions.SetCursorLeakThreshold option. If it is not enabled, nil is
+// returned.
+func (c *Client) OpenCursors() []OpenCursor {
+	if c.cursorTracker == nil {
+		return nil
+	}
+	return c.cursorTracker.openCursors()
+}
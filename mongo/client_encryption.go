@@ -8,10 +8,12 @@ package mongo
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
@@ -46,9 +48,11 @@ func NewClientEncryption(keyVaultClient *Client, opts ...*options.ClientEncrypti
 	kr := keyRetriever{coll: ce.keyVaultColl}
 	cir := collInfoRetriever{client: ce.keyVaultClient}
 	ce.crypt, err = driver.NewCrypt(&driver.CryptOptions{
-		KeyFn:        kr.cryptKeys,
-		CollInfoFn:   cir.cryptCollInfo,
-		KmsProviders: ceo.KmsProviders,
+		KeyFn:            kr.cryptKeys,
+		CollInfoFn:       cir.cryptCollInfo,
+		KmsProviders:     ceo.KmsProviders,
+		TLSConfig:        ceo.TLSConfig,
+		KmsCredentialsFn: transformKmsCredentialsFuncs(ceo.KmsCredentialsFunc),
 	})
 	if err != nil {
 		return nil, err
@@ -88,8 +92,214 @@ func (ce *ClientEncryption) CreateDataKey(ctx context.Context, kmsProvider strin
 	return primitive.Binary{Subtype: subtype, Data: data}, nil
 }
 
+// CreateEncryptedCollection creates a collection with Queryable Encryption. For each field of encryptedFields whose
+// keyId is null, a data key is created with the given KMS provider and options, and its _id is filled in. The state
+// collections of Queryable Encryption, the collection with the resulting encryptedFields, and the index on
+// __safeContent__ are then created. The collection and the resulting encryptedFields document are returned.
+//
+// The encryptedFields parameter must be a document of the form {fields: [{path, bsonType, keyId, queries}, ...]},
+// such as one generated by an encryptschema.Generator with AllowMissingKeys set.
+//
+// If an error occurs after data keys were created, the returned encryptedFields document contains the _id of each
+// data key created so far, so that they can be used or deleted.
+func (ce *ClientEncryption) CreateEncryptedCollection(ctx context.Context, db *Database, coll string,
+	encryptedFields interface{}, kmsProvider string, opts ...*options.DataKeyOptions) (*Collection, bson.M, error) {
+
+	if encryptedFields == nil {
+		return nil, nil, errors.New("encryptedFields must not be nil")
+	}
+	efDoc, err := transformBsoncoreDocument(ce.keyVaultClient.registry, encryptedFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ef bson.M
+	if err = bson.Unmarshal(efDoc, &ef); err != nil {
+		return nil, nil, err
+	}
+
+	err = fillEncryptedFieldsKeys(ef, func() (primitive.Binary, error) {
+		return ce.CreateDataKey(ctx, kmsProvider, opts...)
+	})
+	if err != nil {
+		return nil, ef, err
+	}
+
+	// the state collections are clustered on _id
+	esc, ecoc := encryptedStateCollections(coll, ef)
+	for _, name := range []string{esc, ecoc} {
+		cmd := bson.D{{"create", name}, {"clusteredIndex", bson.D{{"key", bson.D{{"_id", 1}}}, {"unique", true}}}}
+		if err = db.RunCommand(ctx, cmd).Err(); err != nil {
+			return nil, ef, err
+		}
+	}
+	if err = db.RunCommand(ctx, bson.D{{"create", coll}, {"encryptedFields", ef}}).Err(); err != nil {
+		return nil, ef, err
+	}
+
+	c := db.Collection(coll)
+	if _, err = c.Indexes().CreateOne(ctx, IndexModel{Keys: bson.D{{"__safeContent__", 1}}}); err != nil {
+		return nil, ef, err
+	}
+	return c, ef, nil
+}
+
+// fillEncryptedFieldsKeys replaces each null keyId in the fields of the given encryptedFields document with the _id
+// of a data key created by createKey.
+func fillEncryptedFieldsKeys(ef bson.M, createKey func() (primitive.Binary, error)) error {
+	fields, ok := ef["fields"].(bson.A)
+	if !ok {
+		return errors.New("encryptedFields must contain a fields array")
+	}
+
+	for i, f := range fields {
+		field, ok := f.(bson.M)
+		if !ok {
+			return fmt.Errorf("encryptedFields field %d is not a document", i)
+		}
+		if keyID, ok := field["keyId"]; ok && keyID != nil {
+			continue
+		}
+
+		id, err := createKey()
+		if err != nil {
+			return fmt.Errorf("error creating data key for field %v: %v", field["path"], err)
+		}
+		field["keyId"] = id
+	}
+	return nil
+}
+
+// encryptedStateCollections returns the names of the ESC and ECOC state collections of the given collection.
+func encryptedStateCollections(coll string, ef bson.M) (string, string) {
+	esc, ok := ef["escCollection"].(string)
+	if !ok {
+		esc = "enxcol_." + coll + ".esc"
+	}
+	ecoc, ok := ef["ecocCollection"].(string)
+	if !ok {
+		ecoc = "enxcol_." + coll + ".ecoc"
+	}
+	return esc, ecoc
+}
+
+// RewrapManyDataKey re-encrypts the data keys in the key vault collection that match the given filter and updates
+// them in the collection. If a provider is given in the options, the data keys are encrypted with the new master key
+// of that provider. Otherwise, they are re-encrypted with their current master key, which rotates the key material
+// wrapping without changing the KMS.
+//
+// The filter parameter must be a document and is applied to the key vault collection. An empty document (e.g. bson.D{})
+// re-encrypts all data keys.
+func (ce *ClientEncryption) RewrapManyDataKey(ctx context.Context, filter interface{},
+	opts ...*options.RewrapManyDataKeyOptions) (*RewrapManyDataKeyResult, error) {
+
+	rmdko := options.MergeRewrapManyDataKeyOptions(opts...)
+	if rmdko.MasterKey != nil && rmdko.Provider == nil {
+		return nil, errors.New("expected provider to be set if master key is set")
+	}
+
+	filterDoc, err := transformBsoncoreDocument(ce.keyVaultClient.registry, filter)
+	if err != nil {
+		return nil, err
+	}
+	co := cryptOpts.RewrapManyDataKey()
+	if rmdko.Provider != nil {
+		co.SetProvider(*rmdko.Provider)
+	}
+	if rmdko.MasterKey != nil {
+		keyDoc, err := transformBsoncoreDocument(ce.keyVaultClient.registry, rmdko.MasterKey)
+		if err != nil {
+			return nil, err
+		}
+		co.SetMasterKey(keyDoc)
+	}
+
+	keys, err := ce.crypt.RewrapDataKey(ctx, filterDoc, co)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return &RewrapManyDataKeyResult{}, nil
+	}
+
+	models, err := rewrapWriteModels(keys)
+	if err != nil {
+		return nil, err
+	}
+	res, err := ce.keyVaultColl.BulkWrite(ctx, models)
+	if err != nil {
+		return nil, err
+	}
+	return &RewrapManyDataKeyResult{BulkWriteResult: res}, nil
+}
+
+// rewrapWriteModels returns the write models that store the re-encrypted master key and key material of the given
+// data keys.
+func rewrapWriteModels(keys []bsoncore.Document) ([]WriteModel, error) {
+	models := make([]WriteModel, 0, len(keys))
+	for _, key := range keys {
+		id, err := key.LookupErr("_id")
+		if err != nil {
+			return nil, fmt.Errorf("re-encrypted data key has no _id: %v", err)
+		}
+		masterKey, ok := key.Lookup("masterKey").DocumentOK()
+		if !ok {
+			return nil, errors.New("re-encrypted data key has no masterKey document")
+		}
+		keyMaterial := key.Lookup("keyMaterial")
+		if keyMaterial.Type != bsontype.Binary {
+			return nil, errors.New("re-encrypted data key has no keyMaterial")
+		}
+
+		// {$set: {masterKey: ..., keyMaterial: ...}, $currentDate: {updateDate: true}}
+		update := bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.BuildDocumentElement(nil, "$set",
+				bsoncore.AppendDocumentElement(nil, "masterKey", masterKey),
+				bsoncore.AppendValueElement(nil, "keyMaterial", keyMaterial),
+			),
+			bsoncore.BuildDocumentElement(nil, "$currentDate", bsoncore.AppendBooleanElement(nil, "updateDate", true)),
+		)
+		filter := bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendValueElement(nil, "_id", id))
+		models = append(models, NewUpdateOneModel().SetFilter(bson.Raw(filter)).SetUpdate(bson.Raw(update)))
+	}
+	return models, nil
+}
+
 // Encrypt encrypts a BSON value with the given key and algorithm. Returns an encrypted value (BSON binary of subtype 6).
 func (ce *ClientEncryption) Encrypt(ctx context.Context, val bson.RawValue, opts ...*options.EncryptOptions) (primitive.Binary, error) {
+	transformed := transformExplicitEncryptionOptions(opts...)
+	subtype, data, err := ce.crypt.EncryptExplicit(ctx, bsoncore.Value{Type: val.Type, Data: val.Value}, transformed)
+	if err != nil {
+		return primitive.Binary{}, err
+	}
+	return primitive.Binary{Subtype: subtype, Data: data}, nil
+}
+
+// EncryptExpression encrypts the bounds of a match or aggregate expression on a field encrypted with the Range
+// algorithm and decodes the resulting expression into result. The expression must be a document of the form
+// {$and: [{<field>: {$gt: <value>}}, {<field>: {$lt: <value>}}]} for a find filter or
+// {$and: [{$gt: [<fieldpath>, <value>]}, {$lt: [<fieldpath>, <value>]}]} for an aggregate expression, where $gt and
+// $lt may be replaced by $gte and $lte. The options must set the RangeAlgorithm algorithm, the QueryTypeRange query
+// type, and the range options of the field.
+//
+// The resulting expression can be used in a find filter or a $match stage of a collection with Queryable Encryption.
+func (ce *ClientEncryption) EncryptExpression(ctx context.Context, expr interface{}, result interface{},
+	opts ...*options.EncryptOptions) error {
+
+	exprDoc, err := transformBsoncoreDocument(ce.keyVaultClient.registry, expr)
+	if err != nil {
+		return err
+	}
+
+	transformed := transformExplicitEncryptionOptions(opts...)
+	encrypted, err := ce.crypt.EncryptExplicitExpression(ctx, exprDoc, transformed)
+	if err != nil {
+		return err
+	}
+	return bson.UnmarshalWithRegistry(ce.keyVaultClient.registry, encrypted, result)
+}
+
+// transformExplicitEncryptionOptions translates the given EncryptOptions into options for libmongocrypt.
+func transformExplicitEncryptionOptions(opts ...*options.EncryptOptions) *cryptOpts.ExplicitEncryptionOptions {
 	eo := options.MergeEncryptOptions(opts...)
 	transformed := cryptOpts.ExplicitEncryption()
 	if eo.KeyID != nil {
@@ -99,12 +309,26 @@ func (ce *ClientEncryption) Encrypt(ctx context.Context, val bson.RawValue, opts
 		transformed.SetKeyAltName(*eo.KeyAltName)
 	}
 	transformed.SetAlgorithm(eo.Algorithm)
+	transformed.SetQueryType(eo.QueryType)
+	if eo.ContentionFactor != nil {
+		transformed.SetContentionFactor(*eo.ContentionFactor)
+	}
 
-	subtype, data, err := ce.crypt.EncryptExplicit(ctx, bsoncore.Value{Type: val.Type, Data: val.Value}, transformed)
-	if err != nil {
-		return primitive.Binary{}, err
+	if ro := eo.RangeOptions; ro != nil {
+		transformedRange := cryptOpts.ExplicitRangeOptions{
+			Sparsity:   ro.Sparsity,
+			Precision:  ro.Precision,
+			TrimFactor: ro.TrimFactor,
+		}
+		if ro.Min != nil {
+			transformedRange.Min = &bsoncore.Value{Type: ro.Min.Type, Data: ro.Min.Value}
+		}
+		if ro.Max != nil {
+			transformedRange.Max = &bsoncore.Value{Type: ro.Max.Type, Data: ro.Max.Value}
+		}
+		transformed.SetRangeOptions(transformedRange)
 	}
-	return primitive.Binary{Subtype: subtype, Data: data}, nil
+	return transformed
 }
 
 // Decrypt decrypts an encrypted value (BSON binary of subtype 6) and returns the original BSON value.
@@ -124,6 +348,20 @@ func (ce *ClientEncryption) Close(ctx context.Context) error {
 	return ce.keyVaultClient.Disconnect(ctx)
 }
 
+// transformKmsCredentialsFuncs converts the given KMS credentials functions into callbacks for the driver.
+func transformKmsCredentialsFuncs(fns map[string]options.KmsCredentialsFunc) map[string]driver.KmsCredentialsFn {
+	if fns == nil {
+		return nil
+	}
+	transformed := make(map[string]driver.KmsCredentialsFn, len(fns))
+	for provider, fn := range fns {
+		if fn != nil {
+			transformed[provider] = driver.KmsCredentialsFn(fn)
+		}
+	}
+	return transformed
+}
+
 // splitNamespace takes a namespace in the form "database.collection" and returns (database name, collection name)
 func splitNamespace(ns string) (string, string) {
 	firstDot := strings.Index(ns, ".")
@@ -0,0 +1,249 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultPurgeMinAge is the default minimum age of the data keys deleted by PurgeUnusedKeys.
+const defaultPurgeMinAge = 24 * time.Hour
+
+// KeyAltNamesUpdate specifies alternate names to add to or remove from a data key.
+type KeyAltNamesUpdate struct {
+	// The _id of the data key.
+	KeyID primitive.Binary
+
+	// The alternate names to add or remove.
+	KeyAltNames []string
+}
+
+// ListKeys returns a cursor over the data keys in the key vault collection, optionally restricted to the data keys
+// with an alternate name or created in a time range.
+func (ce *ClientEncryption) ListKeys(ctx context.Context, opts ...*options.ListKeysOptions) (*Cursor, error) {
+	return ce.keyVaultColl.Find(ctx, listKeysFilter(options.MergeListKeysOptions(opts...)))
+}
+
+// listKeysFilter returns the filter of the key vault collection that selects the data keys matched by the options.
+func listKeysFilter(lko *options.ListKeysOptions) bson.D {
+	filter := bson.D{}
+	if lko.KeyAltName != nil {
+		filter = append(filter, bson.E{"keyAltNames", *lko.KeyAltName})
+	}
+	if lko.CreatedAfter != nil || lko.CreatedBefore != nil {
+		creationDate := bson.D{}
+		if lko.CreatedAfter != nil {
+			creationDate = append(creationDate, bson.E{"$gte", *lko.CreatedAfter})
+		}
+		if lko.CreatedBefore != nil {
+			creationDate = append(creationDate, bson.E{"$lt", *lko.CreatedBefore})
+		}
+		filter = append(filter, bson.E{"creationDate", creationDate})
+	}
+	return filter
+}
+
+// AddKeyAltNames adds alternate names to data keys in a single unordered bulk write. Alternate names that a data key
+// already has are ignored. Because alternate names are unique across the key vault collection, adding a name that is
+// used by another data key fails for that data key only, and the error is a BulkWriteException.
+func (ce *ClientEncryption) AddKeyAltNames(ctx context.Context, updates []KeyAltNamesUpdate) (*BulkWriteResult, error) {
+	models := make([]WriteModel, 0, len(updates))
+	for _, u := range updates {
+		models = append(models, NewUpdateOneModel().
+			SetFilter(bson.D{{"_id", u.KeyID}}).
+			SetUpdate(bson.D{{"$addToSet", bson.D{{"keyAltNames", bson.D{{"$each", u.KeyAltNames}}}}}}))
+	}
+	return ce.keyVaultColl.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+}
+
+// RemoveKeyAltNames removes alternate names from data keys in a single unordered bulk write. The keyAltNames field
+// of a data key whose last alternate name is removed is unset, so that it does not conflict with the unique index on
+// the field. This requires MongoDB 4.2 or later.
+func (ce *ClientEncryption) RemoveKeyAltNames(ctx context.Context, updates []KeyAltNamesUpdate) (*BulkWriteResult, error) {
+	models := make([]WriteModel, 0, len(updates))
+	for _, u := range updates {
+		models = append(models, NewUpdateOneModel().
+			SetFilter(bson.D{{"_id", u.KeyID}}).
+			SetUpdate(removeKeyAltNamesPipeline(u.KeyAltNames)))
+	}
+	return ce.keyVaultColl.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+}
+
+// removeKeyAltNamesPipeline returns the update pipeline that removes the given alternate names from a data key.
+func removeKeyAltNamesPipeline(keyAltNames []string) Pipeline {
+	return Pipeline{
+		{{"$set", bson.D{{"keyAltNames", bson.D{{"$setDifference", bson.A{
+			bson.D{{"$ifNull", bson.A{"$keyAltNames", bson.A{}}}},
+			keyAltNames,
+		}}}}}}},
+		{{"$set", bson.D{{"keyAltNames", bson.D{{"$cond", bson.A{
+			bson.D{{"$eq", bson.A{"$keyAltNames", bson.A{}}}},
+			"$$REMOVE",
+			"$keyAltNames",
+		}}}}}}},
+	}
+}
+
+// FindUnusedKeys returns the _id of each data key in the key vault collection that is not referenced by the
+// encryptedFields or the JSON schema validator of any collection in the deployment of dataClient. If dataClient is
+// nil, the key vault client is used.
+//
+// Data keys that are only used for explicit encryption, or by schema maps configured on clients, are not referenced
+// by a collection and are therefore reported as unused. If the JSON schema validator of a collection has a keyId that
+// is a JSON pointer, the data key is chosen by its alternate name for each document, so the used data keys cannot be
+// determined and an error is returned.
+func (ce *ClientEncryption) FindUnusedKeys(ctx context.Context, dataClient *Client) ([]primitive.Binary, error) {
+	return ce.unusedKeys(ctx, dataClient, nil)
+}
+
+// PurgeUnusedKeys deletes the data keys that FindUnusedKeys reports as unused and that are older than the minimum
+// age in the options, and returns their _id. Data keys created more recently are kept, because the collections that
+// use them may not have been created yet. Data keys are only deleted if the DryRun option is explicitly set to false;
+// by default, the data keys are returned but not deleted. Like FindUnusedKeys, PurgeUnusedKeys returns an error and
+// deletes nothing if a collection references data keys through a JSON pointer keyId.
+//
+// A data key cannot be recovered once it is deleted, and values encrypted with it can no longer be decrypted. Review
+// the data keys returned by a dry run and make sure that none of them are used for explicit encryption or by schema
+// maps configured on clients before setting DryRun to false.
+func (ce *ClientEncryption) PurgeUnusedKeys(ctx context.Context, dataClient *Client,
+	opts ...*options.PurgeUnusedKeysOptions) ([]primitive.Binary, error) {
+
+	puko := options.MergePurgeUnusedKeysOptions(opts...)
+	minAge := defaultPurgeMinAge
+	if puko.MinAge != nil {
+		minAge = *puko.MinAge
+	}
+	cutoff := time.Now().Add(-minAge)
+
+	ids, err := ce.unusedKeys(ctx, dataClient, &cutoff)
+	if err != nil || len(ids) == 0 || puko.DryRun == nil || *puko.DryRun {
+		return ids, err
+	}
+
+	// the creation date is checked again so that data keys are never deleted if they were replaced in the meantime
+	_, err = ce.keyVaultColl.DeleteMany(ctx, bson.D{
+		{"_id", bson.D{{"$in", ids}}},
+		{"creationDate", bson.D{{"$lt", cutoff}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// unusedKeys returns the _id of each data key that is not referenced by a collection. If createdBefore is not nil,
+// only data keys created before that time are returned.
+func (ce *ClientEncryption) unusedKeys(ctx context.Context, dataClient *Client,
+	createdBefore *time.Time) ([]primitive.Binary, error) {
+
+	if dataClient == nil {
+		dataClient = ce.keyVaultClient
+	}
+	used, err := usedKeyIDs(ctx, dataClient)
+	if err != nil {
+		return nil, err
+	}
+
+	lko := options.ListKeys()
+	if createdBefore != nil {
+		lko.SetCreatedBefore(*createdBefore)
+	}
+	cursor, err := ce.keyVaultColl.Find(ctx, listKeysFilter(lko), options.Find().SetProjection(bson.D{{"_id", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var unused []primitive.Binary
+	for cursor.Next(ctx) {
+		subtype, data, ok := cursor.Current.Lookup("_id").BinaryOK()
+		if !ok || used[string(data)] {
+			continue
+		}
+		unused = append(unused, primitive.Binary{Subtype: subtype, Data: data})
+	}
+	return unused, cursor.Err()
+}
+
+// usedKeyIDs returns the data key IDs referenced by the collections of all databases of the given client.
+func usedKeyIDs(ctx context.Context, client *Client) (map[string]bool, error) {
+	dbs, err := client.ListDatabaseNames(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	for _, db := range dbs {
+		cursor, err := client.Database(db).ListCollections(ctx, bson.D{})
+		if err != nil {
+			return nil, err
+		}
+		for cursor.Next(ctx) {
+			if pointer, ok := collectKeyIDs(cursor.Current.Lookup("options"), used); ok {
+				name, _ := cursor.Current.Lookup("name").StringValueOK()
+				_ = cursor.Close(ctx)
+				return nil, fmt.Errorf("collection %s.%s references data keys through the keyId JSON pointer %q, so "+
+					"the unused data keys cannot be determined", db, name, pointer)
+			}
+		}
+		err = cursor.Err()
+		_ = cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return used, nil
+}
+
+// collectKeyIDs adds the data key IDs in the given collection options to ids. Data keys are referenced by the keyId
+// fields of encryptedFields and of JSON schema validators, which hold either a single UUID or an array of UUIDs. A
+// keyId can also be a JSON pointer to a field that holds the alternate name of the data key. Such a data key cannot be
+// determined from the options, so collectKeyIDs stops and returns the first pointer and true if it finds one.
+func collectKeyIDs(val bson.RawValue, ids map[string]bool) (string, bool) {
+	var elems []bson.RawElement
+	switch val.Type {
+	case bsontype.EmbeddedDocument:
+		elems, _ = val.Document().Elements()
+	case bsontype.Array:
+		elems, _ = val.Array().Elements()
+	default:
+		return "", false
+	}
+
+	for _, elem := range elems {
+		v := elem.Value()
+		if elem.Key() != "keyId" {
+			if pointer, ok := collectKeyIDs(v, ids); ok {
+				return pointer, true
+			}
+			continue
+		}
+
+		switch v.Type {
+		case bsontype.String:
+			return v.StringValue(), true
+		case bsontype.Binary:
+			_, data := v.Binary()
+			ids[string(data)] = true
+		case bsontype.Array:
+			values, _ := v.Array().Values()
+			for _, id := range values {
+				if _, data, ok := id.BinaryOK(); ok {
+					ids[string(data)] = true
+				}
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,189 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+func TestRewrapWriteModels(t *testing.T) {
+	masterKey := bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendStringElement(nil, "provider", "local"))
+	key := bsoncore.BuildDocumentFromElements(nil,
+		bsoncore.AppendBinaryElement(nil, "_id", 4, []byte("0123456789abcdef")),
+		bsoncore.AppendBinaryElement(nil, "keyMaterial", 0, []byte("material")),
+		bsoncore.AppendDocumentElement(nil, "masterKey", masterKey),
+	)
+
+	t.Run("update", func(t *testing.T) {
+		models, err := rewrapWriteModels([]bsoncore.Document{key})
+		assert.Nil(t, err, "rewrapWriteModels error: %v", err)
+		assert.Equal(t, 1, len(models), "expected 1 model, got %d", len(models))
+
+		model := models[0].(*UpdateOneModel)
+		filter := model.Filter.(bson.Raw)
+		_, id := filter.Lookup("_id").Binary()
+		assert.Equal(t, []byte("0123456789abcdef"), id, "unexpected _id in filter %v", filter)
+
+		update := model.Update.(bson.Raw)
+		provider := update.Lookup("$set", "masterKey", "provider").StringValue()
+		assert.Equal(t, "local", provider, "unexpected master key provider in update %v", update)
+		_, material := update.Lookup("$set", "keyMaterial").Binary()
+		assert.Equal(t, []byte("material"), material, "unexpected key material in update %v", update)
+		assert.True(t, update.Lookup("$currentDate", "updateDate").Boolean(), "expected updateDate to be set")
+	})
+	t.Run("missing key material", func(t *testing.T) {
+		invalid := bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendBinaryElement(nil, "_id", 4, []byte("0123456789abcdef")),
+			bsoncore.AppendDocumentElement(nil, "masterKey", masterKey),
+		)
+		_, err := rewrapWriteModels([]bsoncore.Document{invalid})
+		assert.NotNil(t, err, "expected error for data key without key material")
+	})
+}
+
+func TestTransformExplicitEncryptionOptions(t *testing.T) {
+	min := bson.RawValue{Type: bsontype.Int32, Value: bsoncore.AppendInt32(nil, 0)}
+	max := bson.RawValue{Type: bsontype.Int32, Value: bsoncore.AppendInt32(nil, 200)}
+	ro := options.RangeOptions{}
+	ro.SetMin(min).SetMax(max).SetSparsity(1).SetTrimFactor(2)
+	eo := options.Encrypt().
+		SetAlgorithm(options.RangeAlgorithm).
+		SetQueryType(options.QueryTypeRange).
+		SetContentionFactor(0).
+		SetRangeOptions(ro)
+
+	transformed := transformExplicitEncryptionOptions(eo)
+	assert.Equal(t, options.RangeAlgorithm, transformed.Algorithm, "unexpected algorithm %q", transformed.Algorithm)
+	assert.Equal(t, options.QueryTypeRange, transformed.QueryType, "unexpected query type %q", transformed.QueryType)
+	assert.NotNil(t, transformed.ContentionFactor, "expected contention factor to be set")
+	assert.NotNil(t, transformed.RangeOptions, "expected range options to be set")
+
+	doc := transformed.RangeOptions.Document()
+	assert.Equal(t, int32(0), doc.Lookup("min").Int32(), "unexpected min in %v", doc)
+	assert.Equal(t, int32(200), doc.Lookup("max").Int32(), "unexpected max in %v", doc)
+	assert.Equal(t, int64(1), doc.Lookup("sparsity").Int64(), "unexpected sparsity in %v", doc)
+	assert.Equal(t, int32(2), doc.Lookup("trimFactor").Int32(), "unexpected trimFactor in %v", doc)
+	_, err := doc.LookupErr("precision")
+	assert.NotNil(t, err, "expected precision to be omitted from %v", doc)
+}
+
+func TestKeyVaultHelpers(t *testing.T) {
+	t.Run("listKeysFilter", func(t *testing.T) {
+		after := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		before := after.Add(24 * time.Hour)
+		filter := listKeysFilter(options.ListKeys().SetKeyAltName("users").SetCreatedAfter(after).
+			SetCreatedBefore(before))
+		expected := bson.D{
+			{"keyAltNames", "users"},
+			{"creationDate", bson.D{{"$gte", after}, {"$lt", before}}},
+		}
+		assert.Equal(t, expected, filter, "expected filter %v, got %v", expected, filter)
+		assert.Equal(t, bson.D{}, listKeysFilter(options.ListKeys()), "expected empty filter")
+	})
+	t.Run("collectKeyIDs", func(t *testing.T) {
+		id := func(b byte) primitive.Binary {
+			return primitive.Binary{Subtype: 4, Data: bytes.Repeat([]byte{b}, 16)}
+		}
+		collOpts, err := bson.Marshal(bson.D{
+			{"encryptedFields", bson.D{{"fields", bson.A{
+				bson.D{{"path", "ssn"}, {"bsonType", "string"}, {"keyId", id(1)}},
+			}}}},
+			{"validator", bson.D{{"$jsonSchema", bson.D{
+				{"encryptMetadata", bson.D{{"keyId", bson.A{id(2)}}}},
+				{"properties", bson.D{{"name", bson.D{{"encrypt", bson.D{
+					{"keyId", bson.A{id(3)}},
+				}}}}}},
+			}}}},
+		})
+		assert.Nil(t, err, "Marshal error: %v", err)
+
+		ids := make(map[string]bool)
+		_, found := collectKeyIDs(bson.RawValue{Type: bsontype.EmbeddedDocument, Value: collOpts}, ids)
+		assert.False(t, found, "expected no JSON pointer")
+		for _, b := range []byte{1, 2, 3} {
+			assert.True(t, ids[string(id(b).Data)], "expected key %d to be used", b)
+		}
+		assert.Equal(t, 3, len(ids), "expected 3 keys, got %d", len(ids))
+
+		// a JSON pointer selects the data key by alternate name, so the used data keys cannot be determined
+		collOpts, err = bson.Marshal(bson.D{
+			{"validator", bson.D{{"$jsonSchema", bson.D{
+				{"properties", bson.D{{"name", bson.D{{"encrypt", bson.D{
+					{"keyId", "/altNameField"},
+				}}}}}},
+			}}}},
+		})
+		assert.Nil(t, err, "Marshal error: %v", err)
+		pointer, found := collectKeyIDs(bson.RawValue{Type: bsontype.EmbeddedDocument, Value: collOpts}, ids)
+		assert.True(t, found, "expected a JSON pointer")
+		assert.Equal(t, "/altNameField", pointer, "expected pointer /altNameField, got %q", pointer)
+	})
+	t.Run("removeKeyAltNamesPipeline", func(t *testing.T) {
+		pipeline := removeKeyAltNamesPipeline([]string{"a"})
+		assert.Equal(t, 2, len(pipeline), "expected 2 stages, got %d", len(pipeline))
+		_, err := transformUpdateValue(bson.DefaultRegistry, pipeline, true)
+		assert.Nil(t, err, "expected valid update pipeline, got %v", err)
+	})
+}
+
+func TestCreateEncryptedCollectionHelpers(t *testing.T) {
+	existing := primitive.Binary{Subtype: 4, Data: bytes.Repeat([]byte{1}, 16)}
+	created := primitive.Binary{Subtype: 4, Data: bytes.Repeat([]byte{2}, 16)}
+	doc, err := bson.Marshal(bson.D{{"fields", bson.A{
+		bson.D{{"path", "ssn"}, {"bsonType", "string"}, {"keyId", nil}},
+		bson.D{{"path", "age"}, {"bsonType", "int"}, {"keyId", existing}},
+	}}})
+	assert.Nil(t, err, "Marshal error: %v", err)
+
+	t.Run("fillEncryptedFieldsKeys", func(t *testing.T) {
+		var ef bson.M
+		err := bson.Unmarshal(doc, &ef)
+		assert.Nil(t, err, "Unmarshal error: %v", err)
+
+		var calls int
+		err = fillEncryptedFieldsKeys(ef, func() (primitive.Binary, error) {
+			calls++
+			return created, nil
+		})
+		assert.Nil(t, err, "fillEncryptedFieldsKeys error: %v", err)
+		assert.Equal(t, 1, calls, "expected 1 data key to be created, got %d", calls)
+
+		fields := ef["fields"].(bson.A)
+		assert.Equal(t, created, fields[0].(bson.M)["keyId"], "expected created key for ssn")
+		assert.Equal(t, existing, fields[1].(bson.M)["keyId"], "expected existing key for age")
+	})
+	t.Run("fillEncryptedFieldsKeys error", func(t *testing.T) {
+		var ef bson.M
+		err := bson.Unmarshal(doc, &ef)
+		assert.Nil(t, err, "Unmarshal error: %v", err)
+
+		err = fillEncryptedFieldsKeys(ef, func() (primitive.Binary, error) {
+			return primitive.Binary{}, errors.New("kms unavailable")
+		})
+		assert.NotNil(t, err, "expected error")
+		err = fillEncryptedFieldsKeys(bson.M{}, nil)
+		assert.NotNil(t, err, "expected error for missing fields")
+	})
+	t.Run("encryptedStateCollections", func(t *testing.T) {
+		esc, ecoc := encryptedStateCollections("users", bson.M{})
+		assert.Equal(t, "enxcol_.users.esc", esc, "unexpected ESC collection %q", esc)
+		assert.Equal(t, "enxcol_.users.ecoc", ecoc, "unexpected ECOC collection %q", ecoc)
+
+		esc, _ = encryptedStateCollections("users", bson.M{"escCollection": "custom.esc"})
+		assert.Equal(t, "custom.esc", esc, "unexpected ESC collection %q", esc)
+	})
+}
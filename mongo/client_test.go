@@ -13,7 +13,9 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/mongofake"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readconcern"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -96,10 +98,10 @@ func TestClient(t *testing.T) {
 	t.Run("read preference", func(t *testing.T) {
 		t.Run("absent", func(t *testing.T) {
 			client := setupClient()
-			gotMode := client.readPreference.Mode()
+			gotMode := client.ReadPreference().Mode()
 			wantMode := readpref.PrimaryMode
 			assert.Equal(t, gotMode, wantMode, "expected mode %v, got %v", wantMode, gotMode)
-			_, flag := client.readPreference.MaxStaleness()
+			_, flag := client.ReadPreference().MaxStaleness()
 			assert.False(t, flag, "expected max staleness to not be set but was")
 		})
 		t.Run("specified", func(t *testing.T) {
@@ -121,11 +123,11 @@ func TestClient(t *testing.T) {
 			cs += "?readpreference=secondary&readPreferenceTags=one:1&readPreferenceTags=two:2&maxStaleness=5"
 
 			client := setupClient(options.Client().ApplyURI(cs))
-			gotMode := client.readPreference.Mode()
+			gotMode := client.ReadPreference().Mode()
 			assert.Equal(t, gotMode, readpref.SecondaryMode, "expected mode %v, got %v", readpref.SecondaryMode, gotMode)
-			gotTags := client.readPreference.TagSets()
+			gotTags := client.ReadPreference().TagSets()
 			assert.Equal(t, gotTags, tags, "expected tags %v, got %v", tags, gotTags)
-			gotStaleness, flag := client.readPreference.MaxStaleness()
+			gotStaleness, flag := client.ReadPreference().MaxStaleness()
 			assert.True(t, flag, "expected max staleness to be set but was not")
 			wantStaleness := time.Duration(5) * time.Second
 			assert.Equal(t, gotStaleness, wantStaleness, "expected staleness %v, got %v", wantStaleness, gotStaleness)
@@ -156,6 +158,61 @@ func TestClient(t *testing.T) {
 			})
 		})
 	})
+	t.Run("Drain", func(t *testing.T) {
+		t.Run("nothing in flight", func(t *testing.T) {
+			client := setupClient(&options.ClientOptions{Deployment: mockDeployment{}})
+			client.sessionPool = session.NewPool(nil)
+
+			report, err := client.Drain(bgCtx)
+			assert.Nil(t, err, "Drain error: %v", err)
+			assert.Equal(t, DrainReport{Drained: true}, report, "expected client to be drained")
+		})
+		t.Run("reports abandoned sessions", func(t *testing.T) {
+			client := setupClient(&options.ClientOptions{Deployment: mockDeployment{}})
+			client.sessionPool = session.NewPool(nil)
+			_, err := client.StartSession()
+			assert.Nil(t, err, "StartSession error: %v", err)
+
+			ctx, cancel := context.WithTimeout(bgCtx, 50*time.Millisecond)
+			defer cancel()
+			report, err := client.Drain(ctx)
+			assert.Nil(t, err, "Drain error: %v", err)
+			want := DrainReport{AbandonedSessions: 1}
+			assert.Equal(t, want, report, "expected report %v, got %v", want, report)
+
+			_, err = client.StartSession()
+			assert.Equal(t, ErrClientDraining, err, "expected error %v, got %v", ErrClientDraining, err)
+		})
+	})
+	t.Run("session identifiers", func(t *testing.T) {
+		client := setupClient(&options.ClientOptions{Deployment: mockDeployment{}})
+		client.sessionPool = session.NewPool(nil)
+		sess, err := client.StartSession()
+		assert.Nil(t, err, "StartSession error: %v", err)
+		defer sess.EndSession(bgCtx)
+
+		id, err := sess.LogicalSessionID().LookupErr("id")
+		assert.Nil(t, err, "expected id field in session ID, got %v", sess.LogicalSessionID())
+		subtype, _ := id.Binary()
+		assert.Equal(t, session.UUIDSubtype, subtype, "expected UUID subtype, got %v", subtype)
+		assert.Equal(t, int64(0), sess.TxnNumber(), "expected no transaction number, got %v", sess.TxnNumber())
+	})
+	t.Run("ReadAtSnapshot", func(t *testing.T) {
+		client := setupClient(&options.ClientOptions{Deployment: mockDeployment{}})
+		client.sessionPool = session.NewPool(nil)
+
+		snapshotTime := primitive.Timestamp{T: 10, I: 2}
+		sopts := options.Session().SetSnapshotTime(snapshotTime)
+		err := client.ReadAtSnapshot(bgCtx, func(sessCtx SessionContext) error {
+			got := sessCtx.SnapshotTime()
+			assert.NotNil(t, got, "expected snapshot time, got nil")
+			assert.Equal(t, snapshotTime, *got, "expected snapshot time %v, got %v", snapshotTime, *got)
+			return sessCtx.StartTransaction()
+		}, sopts)
+		assert.Equal(t, session.ErrSnapshotTransaction, err, "expected error %v, got %v",
+			session.ErrSnapshotTransaction, err)
+		assert.Nil(t, sopts.Snapshot, "expected options not to be modified")
+	})
 	t.Run("localThreshold", func(t *testing.T) {
 		testCases := []struct {
 			name              string
@@ -176,7 +233,7 @@ func TestClient(t *testing.T) {
 	t.Run("read concern", func(t *testing.T) {
 		rc := readconcern.Majority()
 		client := setupClient(options.Client().SetReadConcern(rc))
-		assert.Equal(t, rc, client.readConcern, "expected read concern %v, got %v", rc, client.readConcern)
+		assert.Equal(t, rc, client.ReadConcern(), "expected read concern %v, got %v", rc, client.ReadConcern())
 	})
 	t.Run("retry writes", func(t *testing.T) {
 		retryWritesURI := "mongodb://localhost:27017/?retryWrites=false"
@@ -237,6 +294,93 @@ func TestClient(t *testing.T) {
 	t.Run("write concern", func(t *testing.T) {
 		wc := writeconcern.New(writeconcern.WMajority())
 		client := setupClient(options.Client().SetWriteConcern(wc))
-		assert.Equal(t, wc, client.writeConcern, "mismatch; expected write concern %v, got %v", wc, client.writeConcern)
+		assert.Equal(t, wc, client.WriteConcern(), "mismatch; expected write concern %v, got %v", wc, client.WriteConcern())
+	})
+	t.Run("unknown URI options", func(t *testing.T) {
+		logged := make(chan string, 1)
+		loggerOpts := options.Logger().SetComponentLevel(options.LogComponentClient, options.LogLevelInfo).
+			SetSink(chanSink(logged))
+		uri := "mongodb://localhost:27017/?retryWrite=true"
+		_, err := NewClient(options.Client().ApplyURI(uri).SetLoggerOptions(loggerOpts))
+		assert.Nil(t, err, "NewClient error: %v", err)
+		assert.Equal(t, 0, len(logged), "expected no warnings with the default policy")
+
+		_, err = NewClient(options.Client().ApplyURI(uri).SetUnknownURIOptionPolicy(options.UnknownURIOptionWarn).
+			SetLoggerOptions(loggerOpts))
+		assert.Nil(t, err, "NewClient error: %v", err)
+		want := "Unknown URI options ignored options retrywrite\n"
+		assert.Equal(t, 1, len(logged), "expected 1 warning")
+		got := <-logged
+		assert.Equal(t, want, got, "expected warning %q, got %q", want, got)
+
+		_, err = NewClient(options.Client().ApplyURI(uri).SetUnknownURIOptionPolicy(options.UnknownURIOptionError))
+		assert.NotNil(t, err, "expected error, got nil")
+	})
+	t.Run("object ID generator", func(t *testing.T) {
+		ctx := context.Background()
+		timestamp := time.Unix(1600000000, 0)
+		opts := mongofake.NewServer().ClientOptions().
+			SetObjectIDGenerator(primitive.NewDeterministicObjectIDGenerator(timestamp))
+		client, err := Connect(ctx, opts)
+		assert.Nil(t, err, "Connect error: %v", err)
+		defer func() { _ = client.Disconnect(ctx) }()
+		coll := client.Database("db").Collection("coll")
+
+		expected := primitive.NewDeterministicObjectIDGenerator(timestamp)
+		one, err := coll.InsertOne(ctx, bson.D{{"x", 1}})
+		assert.Nil(t, err, "InsertOne error: %v", err)
+		many, err := coll.InsertMany(ctx, []interface{}{bson.D{{"x", 2}}, bson.D{{"_id", 3}}})
+		assert.Nil(t, err, "InsertMany error: %v", err)
+		_, err = coll.BulkWrite(ctx, []WriteModel{NewInsertOneModel().SetDocument(bson.D{{"x", 4}})})
+		assert.Nil(t, err, "BulkWrite error: %v", err)
+
+		got := []interface{}{one.InsertedID, many.InsertedIDs[0], many.InsertedIDs[1]}
+		want := []interface{}{expected.NewObjectID(), expected.NewObjectID(), int32(3)}
+		assert.Equal(t, want, got, "expected inserted IDs %v, got %v", want, got)
+		err = coll.FindOne(ctx, bson.D{{"x", 4}}).Err()
+		assert.Nil(t, err, "FindOne error: %v", err)
+		count, err := coll.CountDocuments(ctx, bson.D{{"_id", expected.NewObjectID()}})
+		assert.Nil(t, err, "CountDocuments error: %v", err)
+		assert.Equal(t, int64(1), count, "expected the bulk write to use the generator, got count %v", count)
+	})
+	t.Run("change defaults", func(t *testing.T) {
+		client := setupClient()
+		dbRp := readpref.Nearest()
+		db := client.Database("foo", options.Database().SetReadPreference(dbRp))
+		inheriting := db.Collection("bar")
+		collRc := readconcern.Local()
+		overriding := db.Collection("baz", options.Collection().SetReadConcern(collRc))
+
+		rp := readpref.Secondary()
+		rc := readconcern.Majority()
+		wc := writeconcern.New(writeconcern.W(2))
+		client.SetReadPreference(rp)
+		client.SetReadConcern(rc)
+		client.SetWriteConcern(wc)
+
+		assert.Equal(t, rp, client.ReadPreference(), "expected read preference %v, got %v", rp, client.ReadPreference())
+		assert.Equal(t, rc, client.ReadConcern(), "expected read concern %v, got %v", rc, client.ReadConcern())
+		assert.Equal(t, wc, client.WriteConcern(), "expected write concern %v, got %v", wc, client.WriteConcern())
+
+		// handles created before the change use the new defaults unless they were configured with their own values
+		assert.Equal(t, dbRp, inheriting.currentReadPreference(), "expected read preference %v, got %v", dbRp,
+			inheriting.currentReadPreference())
+		assert.Equal(t, rc, inheriting.currentReadConcern(), "expected read concern %v, got %v", rc,
+			inheriting.currentReadConcern())
+		assert.Equal(t, wc, inheriting.currentWriteConcern(), "expected write concern %v, got %v", wc,
+			inheriting.currentWriteConcern())
+		assert.Equal(t, collRc, overriding.currentReadConcern(), "expected read concern %v, got %v", collRc,
+			overriding.currentReadConcern())
+		assert.Equal(t, rp, client.Database("foo").ReadPreference(), "expected read preference %v, got %v", rp,
+			client.Database("foo").ReadPreference())
+
+		client.SetReadPreference(nil)
+		client.SetReadConcern(nil)
+		client.SetWriteConcern(nil)
+		assert.Equal(t, readpref.PrimaryMode, client.ReadPreference().Mode(), "expected mode %v, got %v",
+			readpref.PrimaryMode, client.ReadPreference().Mode())
+		assert.Equal(t, readconcern.New(), client.ReadConcern(), "expected empty read concern, got %v",
+			client.ReadConcern())
+		assert.Nil(t, client.WriteConcern(), "expected write concern to be nil, got %v", client.WriteConcern())
 	})
 }
@@ -29,13 +29,14 @@ import (
 
 // Collection is a handle to a MongoDB collection. It is safe for concurrent use by multiple goroutines.
 type Collection struct {
-	client         *Client
-	db             *Database
-	name           string
+	client *Client
+	db     *Database
+	name   string
+	// The read concern, write concern, and read preference are nil if they were not specified for the Collection, in
+	// which case the values of the Database are used.
 	readConcern    *readconcern.ReadConcern
 	writeConcern   *writeconcern.WriteConcern
 	readPreference *readpref.ReadPref
-	readSelector   description.ServerSelector
 	writeSelector  description.ServerSelector
 	registry       *bsoncodec.Registry
 }
@@ -66,31 +67,11 @@ func closeImplicitSession(sess *session.Client) {
 func newCollection(db *Database, name string, opts ...*options.CollectionOptions) *Collection {
 	collOpt := options.MergeCollectionOptions(opts...)
 
-	rc := db.readConcern
-	if collOpt.ReadConcern != nil {
-		rc = collOpt.ReadConcern
-	}
-
-	wc := db.writeConcern
-	if collOpt.WriteConcern != nil {
-		wc = collOpt.WriteConcern
-	}
-
-	rp := db.readPreference
-	if collOpt.ReadPreference != nil {
-		rp = collOpt.ReadPreference
-	}
-
 	reg := db.registry
 	if collOpt.Registry != nil {
 		reg = collOpt.Registry
 	}
 
-	readSelector := description.CompositeSelector([]description.ServerSelector{
-		description.ReadPrefSelector(rp),
-		description.LatencySelector(db.client.localThreshold),
-	})
-
 	writeSelector := description.CompositeSelector([]description.ServerSelector{
 		description.WriteSelector(),
 		description.LatencySelector(db.client.localThreshold),
@@ -100,10 +81,9 @@ func newCollection(db *Database, name string, opts ...*options.CollectionOptions
 		client:         db.client,
 		db:             db,
 		name:           name,
-		readPreference: rp,
-		readConcern:    rc,
-		writeConcern:   wc,
-		readSelector:   readSelector,
+		readPreference: collOpt.ReadPreference,
+		readConcern:    collOpt.ReadConcern,
+		writeConcern:   collOpt.WriteConcern,
 		writeSelector:  writeSelector,
 		registry:       reg,
 	}
@@ -119,7 +99,6 @@ func (coll *Collection) copy() *Collection {
 		readConcern:    coll.readConcern,
 		writeConcern:   coll.writeConcern,
 		readPreference: coll.readPreference,
-		readSelector:   coll.readSelector,
 		writeSelector:  coll.writeSelector,
 		registry:       coll.registry,
 	}
@@ -148,14 +127,36 @@ func (coll *Collection) Clone(opts ...*options.CollectionOptions) (*Collection,
 		copyColl.registry = optsColl.Registry
 	}
 
-	copyColl.readSelector = description.CompositeSelector([]description.ServerSelector{
-		description.ReadPrefSelector(copyColl.readPreference),
-		description.LatencySelector(copyColl.client.localThreshold),
-	})
-
 	return copyColl, nil
 }
 
+// currentReadConcern returns the read concern of the Collection, or the current read concern of the Database if the
+// Collection was not configured with one.
+func (coll *Collection) currentReadConcern() *readconcern.ReadConcern {
+	if coll.readConcern != nil {
+		return coll.readConcern
+	}
+	return coll.db.ReadConcern()
+}
+
+// currentWriteConcern returns the write concern of the Collection, or the current write concern of the Database if
+// the Collection was not configured with one.
+func (coll *Collection) currentWriteConcern() *writeconcern.WriteConcern {
+	if coll.writeConcern != nil {
+		return coll.writeConcern
+	}
+	return coll.db.WriteConcern()
+}
+
+// currentReadPreference returns the read preference of the Collection, or the current read preference of the
+// Database if the Collection was not configured with one.
+func (coll *Collection) currentReadPreference() *readpref.ReadPref {
+	if coll.readPreference != nil {
+		return coll.readPreference
+	}
+	return coll.db.ReadPreference()
+}
+
 // Name returns the name of the collection.
 func (coll *Collection) Name() string {
 	return coll.name
@@ -199,7 +200,7 @@ func (coll *Collection) BulkWrite(ctx context.Context, models []WriteModel,
 		return nil, err
 	}
 
-	wc := coll.writeConcern
+	wc := coll.currentWriteConcern()
 	if sess.TransactionRunning() {
 		wc = nil
 	}
@@ -244,7 +245,7 @@ func (coll *Collection) insert(ctx context.Context, documents []interface{},
 
 	for i, doc := range documents {
 		var err error
-		docs[i], result[i], err = transformAndEnsureIDv2(coll.registry, doc)
+		docs[i], result[i], err = transformAndEnsureIDv2(coll.registry, coll.client.idGenerator, doc)
 		if err != nil {
 			return nil, err
 		}
@@ -265,7 +266,7 @@ func (coll *Collection) insert(ctx context.Context, documents []interface{},
 		return nil, err
 	}
 
-	wc := coll.writeConcern
+	wc := coll.currentWriteConcern()
 	if sess.TransactionRunning() {
 		wc = nil
 	}
@@ -400,7 +401,7 @@ func (coll *Collection) delete(ctx context.Context, filter interface{}, deleteOn
 		return nil, err
 	}
 
-	wc := coll.writeConcern
+	wc := coll.currentWriteConcern()
 	if sess.TransactionRunning() {
 		wc = nil
 	}
@@ -526,7 +527,7 @@ func (coll *Collection) updateOrReplace(ctx context.Context, filter bsoncore.Doc
 		return nil, err
 	}
 
-	wc := coll.writeConcern
+	wc := coll.currentWriteConcern()
 	if sess.TransactionRunning() {
 		wc = nil
 	}
@@ -688,19 +689,20 @@ func (coll *Collection) ReplaceOne(ctx context.Context, filter interface{},
 // For more information about the command, see https://docs.mongodb.com/manual/reference/command/aggregate/.
 func (coll *Collection) Aggregate(ctx context.Context, pipeline interface{},
 	opts ...*options.AggregateOptions) (*Cursor, error) {
+	rp := coll.currentReadPreference()
 	a := aggregateParams{
 		ctx:            ctx,
 		pipeline:       pipeline,
 		client:         coll.client,
 		registry:       coll.registry,
-		readConcern:    coll.readConcern,
-		writeConcern:   coll.writeConcern,
+		readConcern:    coll.currentReadConcern(),
+		writeConcern:   coll.currentWriteConcern(),
 		retryRead:      coll.client.retryReads,
 		db:             coll.db.name,
 		col:            coll.name,
-		readSelector:   coll.readSelector,
+		readSelector:   makeReadSelector(rp, coll.client.localThreshold),
 		writeSelector:  coll.writeSelector,
-		readPreference: coll.readPreference,
+		readPreference: rp,
 		opts:           opts,
 	}
 	return aggregate(a)
@@ -808,8 +810,16 @@ func aggregate(a aggregateParams) (*Cursor, error) {
 		closeImplicitSession(sess)
 		return nil, replaceErrors(err)
 	}
-	cursor, err := newCursorWithSession(bc, a.registry, sess)
-	return cursor, replaceErrors(err)
+	var resultCursor batchCursor = bc
+	if canPrefetch(sess, ao.PrefetchDepth) {
+		resultCursor = newPrefetchBatchCursor(bc, int(*ao.PrefetchDepth))
+	}
+	cursor, err := newCursorWithSession(resultCursor, a.registry, sess)
+	if err != nil {
+		return nil, replaceErrors(err)
+	}
+	a.client.cursorTracker.track(cursor, namespace(a.db, a.col))
+	return cursor, nil
 }
 
 // CountDocuments returns the number of documents in the collection. For a fast count of the documents in the
@@ -846,13 +856,14 @@ func (coll *Collection) CountDocuments(ctx context.Context, filter interface{},
 		return 0, err
 	}
 
-	rc := coll.readConcern
+	rc := coll.currentReadConcern()
 	if sess.TransactionRunning() {
 		rc = nil
 	}
 
-	selector := makeReadPrefSelector(sess, coll.readSelector, coll.client.localThreshold)
-	op := operation.NewAggregate(pipelineArr).Session(sess).ReadConcern(rc).ReadPreference(coll.readPreference).
+	rp := coll.currentReadPreference()
+	selector := makeReadPrefSelector(sess, makeReadSelector(rp, coll.client.localThreshold), coll.client.localThreshold)
+	op := operation.NewAggregate(pipelineArr).Session(sess).ReadConcern(rc).ReadPreference(rp).
 		CommandMonitor(coll.client.monitor).ServerSelector(selector).ClusterClock(coll.client.clock).Database(coll.db.name).
 		Collection(coll.name).Deployment(coll.client.deployment).Crypt(coll.client.crypt)
 	if countOpts.Collation != nil {
@@ -927,15 +938,16 @@ func (coll *Collection) EstimatedDocumentCount(ctx context.Context,
 		return 0, err
 	}
 
-	rc := coll.readConcern
+	rc := coll.currentReadConcern()
 	if sess.TransactionRunning() {
 		rc = nil
 	}
 
-	selector := makeReadPrefSelector(sess, coll.readSelector, coll.client.localThreshold)
+	rp := coll.currentReadPreference()
+	selector := makeReadPrefSelector(sess, makeReadSelector(rp, coll.client.localThreshold), coll.client.localThreshold)
 	op := operation.NewCount().Session(sess).ClusterClock(coll.client.clock).
 		Database(coll.db.name).Collection(coll.name).CommandMonitor(coll.client.monitor).
-		Deployment(coll.client.deployment).ReadConcern(rc).ReadPreference(coll.readPreference).
+		Deployment(coll.client.deployment).ReadConcern(rc).ReadPreference(rp).
 		ServerSelector(selector).Crypt(coll.client.crypt)
 
 	co := options.MergeEstimatedDocumentCountOptions(opts...)
@@ -990,18 +1002,19 @@ func (coll *Collection) Distinct(ctx context.Context, fieldName string, filter i
 		return nil, err
 	}
 
-	rc := coll.readConcern
+	rc := coll.currentReadConcern()
 	if sess.TransactionRunning() {
 		rc = nil
 	}
 
-	selector := makeReadPrefSelector(sess, coll.readSelector, coll.client.localThreshold)
+	rp := coll.currentReadPreference()
+	selector := makeReadPrefSelector(sess, makeReadSelector(rp, coll.client.localThreshold), coll.client.localThreshold)
 	option := options.MergeDistinctOptions(opts...)
 
 	op := operation.NewDistinct(fieldName, bsoncore.Document(f)).
 		Session(sess).ClusterClock(coll.client.clock).
 		Database(coll.db.name).Collection(coll.name).CommandMonitor(coll.client.monitor).
-		Deployment(coll.client.deployment).ReadConcern(rc).ReadPreference(coll.readPreference).
+		Deployment(coll.client.deployment).ReadConcern(rc).ReadPreference(rp).
 		ServerSelector(selector).Crypt(coll.client.crypt)
 
 	if option.Collation != nil {
@@ -1079,14 +1092,15 @@ func (coll *Collection) Find(ctx context.Context, filter interface{},
 		return nil, err
 	}
 
-	rc := coll.readConcern
+	rc := coll.currentReadConcern()
 	if sess.TransactionRunning() {
 		rc = nil
 	}
 
-	selector := makeReadPrefSelector(sess, coll.readSelector, coll.client.localThreshold)
+	rp := coll.currentReadPreference()
+	selector := makeReadPrefSelector(sess, makeReadSelector(rp, coll.client.localThreshold), coll.client.localThreshold)
 	op := operation.NewFind(f).
-		Session(sess).ReadConcern(rc).ReadPreference(coll.readPreference).
+		Session(sess).ReadConcern(rc).ReadPreference(rp).
 		CommandMonitor(coll.client.monitor).ServerSelector(selector).
 		ClusterClock(coll.client.clock).Database(coll.db.name).Collection(coll.name).
 		Deployment(coll.client.deployment).Crypt(coll.client.crypt)
@@ -1122,6 +1136,9 @@ func (coll *Collection) Find(ctx context.Context, filter interface{},
 			op.AwaitData(true)
 		}
 	}
+	if fo.Exhaust != nil && (fo.CursorType == nil || *fo.CursorType == options.NonTailable) {
+		cursorOpts.Exhaust = *fo.Exhaust
+	}
 	if fo.Hint != nil {
 		hint, err := transformValue(coll.registry, fo.Hint)
 		if err != nil {
@@ -1211,7 +1228,16 @@ func (coll *Collection) Find(ctx context.Context, filter interface{},
 		closeImplicitSession(sess)
 		return nil, replaceErrors(err)
 	}
-	return newCursorWithSession(bc, coll.registry, sess)
+	var resultCursor batchCursor = bc
+	if canPrefetch(sess, fo.PrefetchDepth) && (fo.CursorType == nil || *fo.CursorType == options.NonTailable) {
+		resultCursor = newPrefetchBatchCursor(bc, int(*fo.PrefetchDepth))
+	}
+	cursor, err := newCursorWithSession(resultCursor, coll.registry, sess)
+	if err != nil {
+		return nil, err
+	}
+	coll.client.cursorTracker.track(cursor, namespace(coll.db.name, coll.name))
+	return cursor, nil
 }
 
 // FindOne executes a find command and returns a SingleResult for one document in the collection.
@@ -1280,7 +1306,7 @@ func (coll *Collection) findAndModify(ctx context.Context, op *operation.FindAnd
 		return &SingleResult{err: err}
 	}
 
-	wc := coll.writeConcern
+	wc := coll.currentWriteConcern()
 	if sess.TransactionRunning() {
 		wc = nil
 	}
@@ -1529,8 +1555,8 @@ func (coll *Collection) Watch(ctx context.Context, pipeline interface{},
 	opts ...*options.ChangeStreamOptions) (*ChangeStream, error) {
 
 	csConfig := changeStreamConfig{
-		readConcern:    coll.readConcern,
-		readPreference: coll.readPreference,
+		readConcern:    coll.currentReadConcern(),
+		readPreference: coll.currentReadPreference(),
 		client:         coll.client,
 		registry:       coll.registry,
 		streamType:     CollectionStream,
@@ -1540,6 +1566,21 @@ func (coll *Collection) Watch(ctx context.Context, pipeline interface{},
 	return newChangeStream(ctx, csConfig, pipeline, opts...)
 }
 
+// Tail creates a tailable-await cursor over the documents in a capped collection that match filter and returns a
+// TailCursor that re-creates the cursor if it is killed on the server, if a network error occurs, or if the server
+// closes it because there were no matching documents. Each new cursor resumes after the last document returned. See
+// https://docs.mongodb.com/manual/core/tailable-cursors/ for more information about tailable cursors.
+//
+// The filter parameter must be a document containing query operators and can be used to select which documents are
+// included in the result. It cannot be nil. An empty document (e.g. bson.D{}) should be used to include all documents.
+//
+// The opts parameter can be used to specify options for the operation (see the options.TailOptions documentation).
+func (coll *Collection) Tail(ctx context.Context, filter interface{},
+	opts ...*options.TailOptions) (*TailCursor, error) {
+
+	return newTailCursor(ctx, coll, filter, opts...)
+}
+
 // Indexes returns an IndexView instance that can be used to perform operations on the indexes for the collection.
 func (coll *Collection) Indexes() IndexView {
 	return IndexView{coll: coll}
@@ -1567,7 +1608,7 @@ func (coll *Collection) Drop(ctx context.Context) error {
 		return err
 	}
 
-	wc := coll.writeConcern
+	wc := coll.currentWriteConcern()
 	if sess.TransactionRunning() {
 		wc = nil
 	}
@@ -1604,6 +1645,15 @@ func makePinnedSelector(sess *session.Client, defaultSelector description.Server
 	}
 }
 
+// makeReadSelector returns a selector for the servers that match rp and are within localThreshold of the fastest
+// matching server.
+func makeReadSelector(rp *readpref.ReadPref, localThreshold time.Duration) description.ServerSelector {
+	return description.CompositeSelector([]description.ServerSelector{
+		description.ReadPrefSelector(rp),
+		description.LatencySelector(localThreshold),
+	})
+}
+
 func makeReadPrefSelector(sess *session.Client, selector description.ServerSelector, localThreshold time.Duration) description.ServerSelectorFunc {
 	if sess != nil && sess.TransactionRunning() {
 		selector = description.CompositeSelector([]description.ServerSelector{
@@ -28,12 +28,12 @@ func setupColl(name string, opts ...*options.CollectionOptions) *Collection {
 }
 
 func compareColls(t *testing.T, expected *Collection, got *Collection) {
-	assert.Equal(t, expected.readPreference, got.readPreference,
-		"mismatch; expected read preference %v, got %v", expected.readPreference, got.readPreference)
-	assert.Equal(t, expected.readConcern, got.readConcern,
-		"mismatch; expected read concern %v, got %v", expected.readConcern, got.readConcern)
-	assert.Equal(t, expected.writeConcern, got.writeConcern,
-		"mismatch; expected write concern %v, got %v", expected.writeConcern, got.writeConcern)
+	assert.Equal(t, expected.readPreference, got.currentReadPreference(),
+		"mismatch; expected read preference %v, got %v", expected.readPreference, got.currentReadPreference())
+	assert.Equal(t, expected.readConcern, got.currentReadConcern(),
+		"mismatch; expected read concern %v, got %v", expected.readConcern, got.currentReadConcern())
+	assert.Equal(t, expected.writeConcern, got.currentWriteConcern(),
+		"mismatch; expected write concern %v, got %v", expected.writeConcern, got.currentWriteConcern())
 }
 
 func TestCollection(t *testing.T) {
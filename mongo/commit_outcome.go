@@ -0,0 +1,59 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/session"
+)
+
+// CommitOutcome describes what is known about the outcome of committing a transaction.
+type CommitOutcome int
+
+// These constants are the possible outcomes of committing a transaction.
+const (
+	// CommitOutcomeUnknown means that the transaction may or may not have been committed, for example because the
+	// connection was closed before the server replied or the write concern was not satisfied in time. The commit can
+	// be retried safely.
+	CommitOutcomeUnknown CommitOutcome = iota
+	// CommitOutcomeCommitted means that the transaction was committed.
+	CommitOutcomeCommitted
+	// CommitOutcomeRolledBack means that the transaction was not committed and its writes were discarded. The whole
+	// transaction must be run again to apply them.
+	CommitOutcomeRolledBack
+)
+
+// String implements the fmt.Stringer interface.
+func (o CommitOutcome) String() string {
+	switch o {
+	case CommitOutcomeCommitted:
+		return "committed"
+	case CommitOutcomeRolledBack:
+		return "rolled back"
+	default:
+		return "unknown"
+	}
+}
+
+// CommitOutcomeOf returns the outcome of committing a transaction given the error returned by
+// Session.CommitTransaction or Session.WithTransaction. Errors with the UnknownTransactionCommitResult label and
+// errors that are not from the server, such as network errors, are reported as CommitOutcomeUnknown.
+func CommitOutcomeOf(err error) CommitOutcome {
+	if err == nil {
+		return CommitOutcomeCommitted
+	}
+	if err == session.ErrCommitAfterAbort {
+		return CommitOutcomeRolledBack
+	}
+
+	labeled, ok := err.(interface{ HasErrorLabel(string) bool })
+	if ok && labeled.HasErrorLabel(driver.TransientTransactionError) &&
+		!labeled.HasErrorLabel(driver.UnknownTransactionCommitResult) {
+		return CommitOutcomeRolledBack
+	}
+	return CommitOutcomeUnknown
+}
@@ -0,0 +1,72 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/session"
+)
+
+func TestCommitOutcomeOf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected CommitOutcome
+	}{
+		{"no error", nil, CommitOutcomeCommitted},
+		{"network error", errors.New("connection reset"), CommitOutcomeUnknown},
+		{"unknown commit result", CommandError{Labels: []string{driver.UnknownTransactionCommitResult}},
+			CommitOutcomeUnknown},
+		{"transient error", CommandError{Labels: []string{driver.TransientTransactionError}}, CommitOutcomeRolledBack},
+		{"both labels", CommandError{Labels: []string{driver.TransientTransactionError,
+			driver.UnknownTransactionCommitResult}}, CommitOutcomeUnknown},
+		{"unlabeled server error", CommandError{Code: 11600}, CommitOutcomeUnknown},
+		{"aborted transaction", session.ErrCommitAfterAbort, CommitOutcomeRolledBack},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := CommitOutcomeOf(tc.err)
+			assert.Equal(t, tc.expected, got, "expected outcome %v, got %v", tc.expected, got)
+		})
+	}
+}
+
+func TestCommitProgress(t *testing.T) {
+	events := make(chan *event.CommitProgressEvent, 3)
+	sess := &sessionImpl{
+		clientSession: &session.Client{
+			Server:         &session.Server{TxnNumber: 3},
+			CurrentWc:      writeconcern.New(writeconcern.W(1), writeconcern.WTimeout(time.Hour)),
+			RetryingCommit: true,
+		},
+		commitProgress: func(evt *event.CommitProgressEvent) { events <- evt },
+		commitAttempts: 1,
+	}
+
+	// the reported wtimeout is the one of the write concern that is sent, not of the session's write concern
+	wc := writeconcern.New(writeconcern.WMajority(), writeconcern.WTimeout(10*time.Millisecond))
+	stop := sess.startCommitProgress(wc)
+	defer stop()
+	for _, stage := range []string{event.CommitRetriedWithMajority, event.CommitSent, event.CommitWTimeoutApproaching} {
+		select {
+		case evt := <-events:
+			assert.Equal(t, stage, evt.Stage, "expected stage %v, got %v", stage, evt.Stage)
+			assert.Equal(t, int64(3), evt.TxnNumber, "expected transaction number 3, got %v", evt.TxnNumber)
+			assert.Equal(t, 2, evt.Attempt, "expected attempt 2, got %v", evt.Attempt)
+			assert.Equal(t, 10*time.Millisecond, evt.WTimeout, "expected wtimeout 10ms, got %v", evt.WTimeout)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %v", stage)
+		}
+	}
+}
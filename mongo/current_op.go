@@ -0,0 +1,153 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CurrentOperation describes an operation in progress on the server. It is returned by Client.CurrentOp.
+type CurrentOperation struct {
+	// OpID identifies the operation for Client.KillOp. It is a number for operations on a mongod and a string in the
+	// form "<shard>:<opid>" for operations on a shard that are reported by a mongos.
+	OpID interface{} `bson:"opid"`
+
+	Type             string `bson:"type"`
+	Host             string `bson:"host"`
+	Shard            string `bson:"shard"`
+	Desc             string `bson:"desc"`
+	ConnectionID     int64  `bson:"connectionId"`
+	Client           string `bson:"client"`
+	AppName          string `bson:"appName"`
+	Active           bool   `bson:"active"`
+	Op               string `bson:"op"`
+	Namespace        string `bson:"ns"`
+	PlanSummary      string `bson:"planSummary"`
+	NumYields        int64  `bson:"numYields"`
+	WaitingForLock   bool   `bson:"waitingForLock"`
+	KillPending      bool   `bson:"killPending"`
+	MicrosecsRunning int64  `bson:"microsecs_running"`
+
+	// Command is the command document of the operation.
+	Command bson.Raw `bson:"command"`
+
+	// EffectiveUsers are the users that run the operation.
+	EffectiveUsers []struct {
+		User string `bson:"user"`
+		DB   string `bson:"db"`
+	} `bson:"effectiveUsers"`
+
+	// Running is the time that the operation has been running for. It is derived from MicrosecsRunning.
+	Running time.Duration `bson:"-"`
+
+	// Raw is the complete document returned by the server, which contains fields that are not part of this type.
+	Raw bson.Raw `bson:"-"`
+}
+
+// CurrentOp returns the operations in progress on the deployment by running a $currentOp aggregation against the
+// admin database. The operations can be filtered by running time, namespace, and client using the options (see the
+// options.CurrentOpOptions documentation). Running $currentOp requires the inprog privilege to see the operations of
+// other users.
+//
+// For more information about the stage, see https://docs.mongodb.com/manual/reference/operator/aggregation/currentOp/.
+func (c *Client) CurrentOp(ctx context.Context, opts ...*options.CurrentOpOptions) ([]CurrentOperation, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cursor, err := c.Database("admin").Aggregate(ctx, currentOpPipeline(options.MergeCurrentOpOptions(opts...)))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	ops := make([]CurrentOperation, 0)
+	for cursor.Next(ctx) {
+		op, err := decodeCurrentOperation(cursor.Current)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, cursor.Err()
+}
+
+// KillOp terminates the operation with the given ID, which is the OpID of a CurrentOperation. The server marks the
+// operation as killed and it stops at its next interruption point, so it can still be reported by CurrentOp for a
+// short time after KillOp returns.
+//
+// For more information about the command, see https://docs.mongodb.com/manual/reference/command/killOp/.
+func (c *Client) KillOp(ctx context.Context, opID interface{}) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd := bson.D{{"killOp", 1}, {"op", opID}}
+	return c.Database("admin").RunCommand(ctx, cmd).Err()
+}
+
+func currentOpPipeline(opts *options.CurrentOpOptions) bson.A {
+	stage := bson.D{}
+	if opts.AllUsers != nil {
+		stage = append(stage, bson.E{"allUsers", *opts.AllUsers})
+	}
+	if opts.IdleConnections != nil {
+		stage = append(stage, bson.E{"idleConnections", *opts.IdleConnections})
+	}
+	if opts.IdleCursors != nil {
+		stage = append(stage, bson.E{"idleCursors", *opts.IdleCursors})
+	}
+	if opts.IdleSessions != nil {
+		stage = append(stage, bson.E{"idleSessions", *opts.IdleSessions})
+	}
+	if opts.LocalOps != nil {
+		stage = append(stage, bson.E{"localOps", *opts.LocalOps})
+	}
+	pipeline := bson.A{bson.D{{"$currentOp", stage}}}
+
+	filter := bson.D{}
+	if opts.MinRunningTime != nil && *opts.MinRunningTime > 0 {
+		micros := int64(*opts.MinRunningTime / time.Microsecond)
+		filter = append(filter, bson.E{"microsecs_running", bson.D{{"$gte", micros}}})
+	}
+	if opts.Namespace != nil {
+		filter = append(filter, bson.E{"ns", *opts.Namespace})
+	}
+	if opts.Client != nil {
+		// operations on shards that are reported by a mongos have the address in client_s
+		filter = append(filter, bson.E{"$or", bson.A{
+			bson.D{{"client", *opts.Client}},
+			bson.D{{"client_s", *opts.Client}},
+		}})
+	}
+	if opts.AppName != nil {
+		filter = append(filter, bson.E{"appName", *opts.AppName})
+	}
+	if len(filter) > 0 {
+		pipeline = append(pipeline, bson.D{{"$match", filter}})
+	}
+	if opts.Filter != nil {
+		pipeline = append(pipeline, bson.D{{"$match", opts.Filter}})
+	}
+	return pipeline
+}
+
+func decodeCurrentOperation(doc bson.Raw) (CurrentOperation, error) {
+	// copy the document because the cursor reuses its buffer and Command refers to the decoded bytes
+	raw := make(bson.Raw, len(doc))
+	copy(raw, doc)
+
+	var op CurrentOperation
+	if err := bson.Unmarshal(raw, &op); err != nil {
+		return CurrentOperation{}, err
+	}
+	op.Running = time.Duration(op.MicrosecsRunning) * time.Microsecond
+	op.Raw = raw
+	return op, nil
+}
@@ -0,0 +1,95 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/mongofake"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestCurrentOp(t *testing.T) {
+	t.Run("pipeline", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			opts     *options.CurrentOpOptions
+			expected bson.A
+		}{
+			{"default", options.CurrentOp(), bson.A{bson.D{{"$currentOp", bson.D{}}}}},
+			{
+				"stage options",
+				options.CurrentOp().SetAllUsers(true).SetIdleSessions(false).SetLocalOps(true),
+				bson.A{bson.D{{"$currentOp", bson.D{{"allUsers", true}, {"idleSessions", false}, {"localOps", true}}}}},
+			},
+			{
+				"filters",
+				options.CurrentOp().SetMinRunningTime(2 * time.Second).SetNamespace("db.coll").SetClient("10.0.0.1:5000").
+					SetAppName("app").SetFilter(bson.D{{"op", "query"}}),
+				bson.A{
+					bson.D{{"$currentOp", bson.D{}}},
+					bson.D{{"$match", bson.D{
+						{"microsecs_running", bson.D{{"$gte", int64(2000000)}}},
+						{"ns", "db.coll"},
+						{"$or", bson.A{bson.D{{"client", "10.0.0.1:5000"}}, bson.D{{"client_s", "10.0.0.1:5000"}}}},
+						{"appName", "app"},
+					}}},
+					bson.D{{"$match", bson.D{{"op", "query"}}}},
+				},
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				got := currentOpPipeline(options.MergeCurrentOpOptions(tc.opts))
+				assert.Equal(t, tc.expected, got, "expected pipeline %v, got %v", tc.expected, got)
+			})
+		}
+	})
+	t.Run("decode", func(t *testing.T) {
+		doc, err := bson.Marshal(bson.D{
+			{"type", "op"},
+			{"opid", "shard01:12345"},
+			{"active", true},
+			{"op", "command"},
+			{"ns", "db.coll"},
+			{"command", bson.D{{"find", "coll"}}},
+			{"microsecs_running", int64(1500)},
+			{"numYields", int32(3)},
+			{"connectionId", int32(7)},
+			{"effectiveUsers", bson.A{bson.D{{"user", "u"}, {"db", "admin"}}}},
+			{"lsid", bson.D{{"id", "x"}}},
+		})
+		assert.Nil(t, err, "Marshal error: %v", err)
+
+		op, err := decodeCurrentOperation(doc)
+		assert.Nil(t, err, "decodeCurrentOperation error: %v", err)
+		assert.Equal(t, "shard01:12345", op.OpID, "expected opid shard01:12345, got %v", op.OpID)
+		assert.Equal(t, "db.coll", op.Namespace, "expected namespace db.coll, got %v", op.Namespace)
+		assert.Equal(t, int64(3), op.NumYields, "expected 3 yields, got %v", op.NumYields)
+		assert.Equal(t, int64(7), op.ConnectionID, "expected connection 7, got %v", op.ConnectionID)
+		assert.Equal(t, 1500*time.Microsecond, op.Running, "expected 1.5ms running time, got %v", op.Running)
+		assert.Equal(t, "coll", op.Command.Lookup("find").StringValue(), "expected find command, got %v", op.Command)
+		assert.Equal(t, "u", op.EffectiveUsers[0].User, "expected user u, got %v", op.EffectiveUsers)
+		_, err = op.Raw.LookupErr("lsid")
+		assert.Nil(t, err, "expected lsid in raw document, got %v", err)
+	})
+	t.Run("KillOp error", func(t *testing.T) {
+		ctx := context.Background()
+		client, err := Connect(ctx, mongofake.NewServer().ClientOptions())
+		assert.Nil(t, err, "Connect error: %v", err)
+		defer func() { _ = client.Disconnect(ctx) }()
+
+		err = client.KillOp(ctx, int32(1))
+		cmdErr, ok := err.(CommandError)
+		assert.True(t, ok, "expected CommandError, got %T", err)
+		assert.Equal(t, "CommandNotFound", cmdErr.Name, "expected CommandNotFound, got %v", cmdErr.Name)
+	})
+}
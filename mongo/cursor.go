@@ -30,10 +30,24 @@ type Cursor struct {
 	batch         *bsoncore.DocumentSequence
 	registry      *bsoncodec.Registry
 	clientSession *session.Client
+	decodeErrFn   DecodeErrorHandler
+	tracker       *cursorTracker
+	trackingID    uint64
+	rawBatch      []bsoncore.Document
+
+	// adaptive batch sizing state
+	targetBatchBytes int32
+	seenDocs         int64
+	seenBytes        int64
 
 	err error
 }
 
+// DecodeErrorHandler is a function that is called with a document that could not be decoded and the error returned
+// while decoding it. The document is only valid until the handler returns. If continued access is required, a copy
+// must be made.
+type DecodeErrorHandler func(doc bson.Raw, err error)
+
 func newCursor(bc batchCursor, registry *bsoncodec.Registry) (*Cursor, error) {
 	return newCursorWithSession(bc, registry, nil)
 }
@@ -60,6 +74,64 @@ func newEmptyCursor() *Cursor {
 	return &Cursor{bc: driver.NewEmptyBatchCursor()}
 }
 
+// NewCursorFromDocuments creates a new Cursor pre-loaded with the provided documents, error and registry. If no registry
+// is provided, bson.DefaultRegistry will be used. The Cursor returns the documents in order and then reports err, if it
+// is not nil, from its Err method. This is useful for stubbing operations that return a Cursor in unit tests.
+//
+// The documents parameter must be a slice of documents. The slice may be nil or empty, but all elements must be
+// non-nil.
+func NewCursorFromDocuments(documents []interface{}, err error, registry *bsoncodec.Registry) (*Cursor, error) {
+	if registry == nil {
+		registry = bson.DefaultRegistry
+	}
+
+	var data []byte
+	for _, doc := range documents {
+		bsonDoc, marshalErr := transformBsoncoreDocument(registry, doc)
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+		data = append(data, bsonDoc...)
+	}
+	bc := &documentsBatchCursor{
+		batch: &bsoncore.DocumentSequence{Style: bsoncore.SequenceStyle, Data: data},
+		err:   err,
+	}
+	return newCursor(bc, registry)
+}
+
+// documentsBatchCursor is a batchCursor that returns a fixed batch of documents and is not backed by a server.
+type documentsBatchCursor struct {
+	batch    *bsoncore.DocumentSequence
+	err      error
+	returned bool
+}
+
+var _ batchCursor = (*documentsBatchCursor)(nil)
+
+func (bc *documentsBatchCursor) ID() int64 { return 0 }
+
+func (bc *documentsBatchCursor) Next(context.Context) bool {
+	if bc.returned {
+		return false
+	}
+	bc.returned = true
+	return true
+}
+
+func (bc *documentsBatchCursor) Batch() *bsoncore.DocumentSequence { return bc.batch }
+
+func (bc *documentsBatchCursor) Server() driver.Server { return nil }
+
+func (bc *documentsBatchCursor) Err() error {
+	if !bc.returned {
+		return nil
+	}
+	return bc.err
+}
+
+func (bc *documentsBatchCursor) Close(context.Context) error { return nil }
+
 // ID returns the ID of this cursor, or 0 if the cursor has been closed or exhausted.
 func (c *Cursor) ID() int64 { return c.bc.ID() }
 
@@ -112,6 +184,28 @@ func (c *Cursor) next(ctx context.Context, nonBlocking bool) bool {
 
 	// call the Next method in a loop until at least one document is returned in the next batch or
 	// the context times out.
+	for {
+		if !c.loadNextBatch(ctx, nonBlocking) {
+			return false
+		}
+
+		doc, err = c.batch.Next()
+		switch err {
+		case nil:
+			c.Current = bson.Raw(doc)
+			return true
+		case io.EOF: // Empty batch so we continue
+		default:
+			c.err = err
+			return false
+		}
+	}
+}
+
+// loadNextBatch retrieves batches from the batch cursor until a non-empty batch is returned, an error occurs, or the
+// cursor is exhausted. It returns true if c.batch was set to a new non-empty batch. If nonBlocking is true, it returns
+// false after the first empty batch.
+func (c *Cursor) loadNextBatch(ctx context.Context, nonBlocking bool) bool {
 	for {
 		// If we don't have a next batch
 		if !c.bc.Next(ctx) {
@@ -123,6 +217,7 @@ func (c *Cursor) next(ctx context.Context, nonBlocking bool) bool {
 			// Is the cursor ID zero?
 			if c.bc.ID() == 0 {
 				c.closeImplicitSession()
+				c.untrack()
 				return false
 			}
 			// empty batch, but cursor is still valid.
@@ -136,28 +231,159 @@ func (c *Cursor) next(ctx context.Context, nonBlocking bool) bool {
 		// close the implicit session if this was the last getMore
 		if c.bc.ID() == 0 {
 			c.closeImplicitSession()
+			c.untrack()
 		}
 
 		c.batch = c.bc.Batch()
-		doc, err = c.batch.Next()
-		switch err {
-		case nil:
-			c.Current = bson.Raw(doc)
+		c.adaptBatchSize()
+		return true
+	}
+}
+
+// SetBatchSize sets the number of documents requested by each getMore command sent for this cursor after SetBatchSize
+// returns. A size of 0 or less uses the server's default batch size. Calling SetBatchSize disables adaptive batch
+// sizing enabled with SetAdaptiveBatchSize.
+//
+// Batches that have already been retrieved, including batches retrieved ahead of time for cursors with a prefetch
+// depth, are not affected. SetBatchSize has no effect on a cursor that does not send getMore commands.
+func (c *Cursor) SetBatchSize(size int32) {
+	c.targetBatchBytes = 0
+	c.setBatchSize(size)
+}
+
+func (c *Cursor) setBatchSize(size int32) {
+	if bss, ok := c.bc.(batchSizeSetter); ok {
+		bss.SetBatchSize(size)
+	}
+}
+
+// SetAdaptiveBatchSize enables adaptive batch sizing for this cursor. After each batch is retrieved, the batch size for
+// subsequent getMore commands is set so that a batch is approximately targetBytes in size, based on the average size of
+// the documents returned by the cursor so far. A targetBytes of 0 or less disables adaptive batch sizing and leaves
+// the current batch size in place.
+func (c *Cursor) SetAdaptiveBatchSize(targetBytes int32) {
+	if targetBytes < 0 {
+		targetBytes = 0
+	}
+	c.targetBatchBytes = targetBytes
+	if targetBytes == 0 {
+		return
+	}
+	if c.seenDocs == 0 {
+		c.recordBatchSize()
+	}
+	c.applyAdaptiveBatchSize()
+}
+
+// adaptBatchSize records the size of the current batch and updates the batch size if adaptive batch sizing is enabled.
+func (c *Cursor) adaptBatchSize() {
+	if c.targetBatchBytes <= 0 {
+		return
+	}
+	c.recordBatchSize()
+	c.applyAdaptiveBatchSize()
+}
+
+func (c *Cursor) recordBatchSize() {
+	if c.batch == nil {
+		return
+	}
+	if n := c.batch.DocumentCount(); n > 0 {
+		c.seenDocs += int64(n)
+		c.seenBytes += int64(len(c.batch.Data))
+	}
+}
+
+func (c *Cursor) applyAdaptiveBatchSize() {
+	if c.seenDocs == 0 || c.seenBytes == 0 {
+		return
+	}
+
+	size := int64(c.targetBatchBytes) * c.seenDocs / c.seenBytes
+	if size < 1 {
+		size = 1
+	}
+	c.setBatchSize(int32(size))
+}
+
+// NextBatch advances the cursor to the next batch of documents, which can then be accessed through RawBatch. If some
+// documents in the current batch have not been returned by Next or TryNext, those documents form the next batch.
+// Otherwise, NextBatch blocks until a non-empty batch is retrieved from the server, an error occurs, or ctx expires.
+// It returns true if a batch is available and false if the cursor is exhausted or an error occurred.
+//
+// NextBatch can be mixed with Next and TryNext. After NextBatch returns, a call to Next or TryNext will begin with
+// the first document of the following batch.
+func (c *Cursor) NextBatch(ctx context.Context) bool {
+	c.rawBatch = c.rawBatch[:0]
+	c.Current = nil
+	if c.err != nil {
+		return false
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for {
+		for {
+			doc, err := c.batch.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				c.err = err
+				return false
+			}
+			c.rawBatch = append(c.rawBatch, doc)
+		}
+		if len(c.rawBatch) > 0 {
 			return true
-		case io.EOF: // Empty batch so we continue
-		default:
-			c.err = err
+		}
+
+		if !c.loadNextBatch(ctx, false) {
 			return false
 		}
 	}
 }
 
+// RawBatch returns the documents in the batch retrieved by the last call to NextBatch. The returned documents are not
+// copies: each one is a view over the buffer that the driver read the batch into, so selectively parsing them does not
+// require an allocation per document.
+//
+// The returned slice and the documents in it are only valid until the next call to NextBatch, Next, TryNext, All, or
+// Close, after which the underlying memory may be reused for the following batch. Callers must not modify the
+// documents and must make a copy of any document that needs to be retained for longer.
+func (c *Cursor) RawBatch() []bsoncore.Document {
+	return c.rawBatch
+}
+
 // Decode will unmarshal the current document into val and return any errors from the unmarshalling process without any
 // modification. If val is nil or is a typed nil, an error will be returned.
 func (c *Cursor) Decode(val interface{}) error {
 	return bson.UnmarshalWithRegistry(c.registry, c.Current, val)
 }
 
+// SetDecodeErrorHandler specifies a handler for documents that cannot be decoded by All, Documents, and Stream. If a
+// handler is set, a document that fails to decode is passed to the handler along with the decoding error and is then
+// skipped instead of aborting iteration. If fn is nil, decoding errors abort iteration and are returned to the caller,
+// which is the default. The handler is not used by Decode, which always returns the decoding error.
+//
+// This is useful when scanning collections that contain heterogeneous or legacy documents that do not match the type
+// being decoded into.
+func (c *Cursor) SetDecodeErrorHandler(fn DecodeErrorHandler) {
+	c.decodeErrFn = fn
+}
+
+// decodeDocument unmarshals doc into val. If unmarshalling fails and a decode error handler is set, the handler is
+// called, skipped is true, and a nil error is returned.
+func (c *Cursor) decodeDocument(doc bson.Raw, val interface{}) (skipped bool, err error) {
+	err = bson.UnmarshalWithRegistry(c.registry, doc, val)
+	if err == nil || c.decodeErrFn == nil {
+		return false, err
+	}
+	c.decodeErrFn(doc, err)
+	return true, nil
+}
+
 // Err returns the last error seen by the Cursor, or nil if no error has occurred.
 func (c *Cursor) Err() error { return c.err }
 
@@ -165,6 +391,7 @@ func (c *Cursor) Err() error { return c.err }
 // the first call, any subsequent calls will not change the state.
 func (c *Cursor) Close(ctx context.Context) error {
 	defer c.closeImplicitSession()
+	c.untrack()
 	return c.bc.Close(ctx)
 }
 
@@ -226,10 +453,16 @@ func (c *Cursor) addFromBatch(sliceVal reflect.Value, elemType reflect.Type, bat
 			sliceVal = sliceVal.Slice(0, sliceVal.Cap())
 		}
 
-		currElem := sliceVal.Index(index).Addr().Interface()
-		if err = bson.UnmarshalWithRegistry(c.registry, doc, currElem); err != nil {
+		currElem := sliceVal.Index(index)
+		skipped, err := c.decodeDocument(bson.Raw(doc), currElem.Addr().Interface())
+		if err != nil {
 			return sliceVal, index, err
 		}
+		if skipped {
+			// Clear anything that was partially decoded so the slot can be reused for the next document.
+			currElem.Set(reflect.Zero(elemType))
+			continue
+		}
 
 		index++
 	}
@@ -243,10 +476,22 @@ func (c *Cursor) closeImplicitSession() {
 	}
 }
 
+// untrack removes the cursor from the Client's cursor leak tracker, if it is being tracked.
+func (c *Cursor) untrack() {
+	if c.tracker != nil {
+		c.tracker.untrack(c.trackingID)
+		c.tracker = nil
+	}
+}
+
 // BatchCursorFromCursor returns a driver.BatchCursor for the given Cursor. If there is no underlying
 // driver.BatchCursor, nil is returned. This method is deprecated and does not have any stability guarantees. It may be
 // removed in the future.
 func BatchCursorFromCursor(c *Cursor) *driver.BatchCursor {
-	bc, _ := c.bc.(*driver.BatchCursor)
+	wrapped := c.bc
+	if pbc, ok := wrapped.(*prefetchBatchCursor); ok {
+		wrapped = pbc.bc
+	}
+	bc, _ := wrapped.(*driver.BatchCursor)
 	return bc
 }
@@ -0,0 +1,68 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build go1.23
+// +build go1.23
+
+package mongo
+
+import (
+	"context"
+	"iter"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Seq returns an iterator over the remaining documents in the cursor that can be used with a for-range loop. Each
+// iteration yields the current document as a bson.Raw and a nil error. If the cursor encounters an error, the
+// iterator yields a nil document and the error and then stops. The yielded bson.Raw is only valid until the next
+// iteration; if continued access is required, a copy must be made.
+//
+// The cursor is closed when the iterator stops, either because the cursor was exhausted, an error occurred, or the
+// loop body exited early. The ctx parameter is used for all getMore and killCursors commands sent by the iterator.
+//
+// This method requires Go 1.23 or later.
+func (c *Cursor) Seq(ctx context.Context) iter.Seq2[bson.Raw, error] {
+	return func(yield func(bson.Raw, error) bool) {
+		defer c.Close(ctx)
+
+		for c.Next(ctx) {
+			if !yield(c.Current, nil) {
+				return
+			}
+		}
+		if err := c.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// Documents returns an iterator that decodes each remaining document in cursor into a value of type T. If a document
+// cannot be decoded, the iterator yields the zero value of T and the decoding error, and iteration continues with the
+// next document unless the loop body exits. If the cursor itself encounters an error, the iterator yields the zero
+// value of T and the error and then stops. If a decode error handler has been set on the cursor with
+// SetDecodeErrorHandler, documents that cannot be decoded are passed to the handler and are not yielded.
+//
+// The cursor is closed when the iterator stops. The ctx parameter is used for all getMore and killCursors commands
+// sent by the iterator.
+//
+// This function requires Go 1.23 or later.
+func Documents[T any](ctx context.Context, cursor *Cursor) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for raw, err := range cursor.Seq(ctx) {
+			var val T
+			if err == nil {
+				var skipped bool
+				if skipped, err = cursor.decodeDocument(raw, &val); skipped {
+					continue
+				}
+			}
+			if !yield(val, err) {
+				return
+			}
+		}
+	}
+}
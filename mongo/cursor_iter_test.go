@@ -0,0 +1,75 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build go1.23
+// +build go1.23
+
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+func TestCursorIterators(t *testing.T) {
+	t.Run("Seq yields every document across batches", func(t *testing.T) {
+		tbc := newTestBatchCursor(2, 3)
+		cursor, err := newCursor(tbc, nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+
+		var count int
+		for doc, err := range cursor.Seq(context.Background()) {
+			assert.Nil(t, err, "Seq error: %v", err)
+			foo := doc.Lookup("foo").Int32()
+			assert.Equal(t, int32(count), foo, "expected foo %v, got %v", count, foo)
+			count++
+		}
+		assert.Equal(t, 6, count, "expected 6 documents, got %v", count)
+		assert.True(t, tbc.closed, "expected batch cursor to be closed but was not")
+	})
+	t.Run("Documents decodes into type", func(t *testing.T) {
+		cursor, err := newCursor(newTestBatchCursor(1, 5), nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+
+		type Document struct {
+			Foo int32 `bson:"foo"`
+		}
+		var docs []Document
+		for doc, err := range Documents[Document](context.Background(), cursor) {
+			assert.Nil(t, err, "Documents error: %v", err)
+			docs = append(docs, doc)
+		}
+		assert.Equal(t, 5, len(docs), "expected 5 documents, got %v", len(docs))
+		assert.Equal(t, Document{Foo: 4}, docs[4], "expected last document %v, got %v", Document{Foo: 4}, docs[4])
+	})
+	t.Run("Documents surfaces decode errors per element", func(t *testing.T) {
+		cursor, err := newCursor(newTestBatchCursor(1, 3), nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+
+		type Document struct {
+			Foo string `bson:"foo"`
+		}
+		var errCount int
+		for _, err := range Documents[Document](context.Background(), cursor) {
+			if err != nil {
+				errCount++
+			}
+		}
+		assert.Equal(t, 3, errCount, "expected 3 decode errors, got %v", errCount)
+	})
+	t.Run("breaking early closes the cursor", func(t *testing.T) {
+		tbc := newTestBatchCursor(2, 3)
+		cursor, err := newCursor(tbc, nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+
+		for range cursor.Seq(context.Background()) {
+			break
+		}
+		assert.True(t, tbc.closed, "expected batch cursor to be closed but was not")
+	})
+}
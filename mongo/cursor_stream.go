@@ -0,0 +1,76 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build go1.18
+// +build go1.18
+
+package mongo
+
+import (
+	"context"
+)
+
+// Stream decodes the remaining documents in cursor into values of type T in a background goroutine and sends them on
+// the returned value channel. Unlike Cursor.All, at most buffer decoded documents (plus the current batch held by the
+// cursor) are kept in memory at once: the goroutine blocks when the value channel is full until the caller receives
+// from it. A buffer less than zero is treated as zero.
+//
+// The value channel is closed when the cursor is exhausted, an error occurs, or ctx expires. If an error occurs while
+// iterating the cursor or decoding a document, it is sent on the error channel before the value channel is closed.
+// If ctx expires, ctx.Err() is sent on the error channel. The error channel has a buffer of one, is written to at
+// most once, and is closed after the value channel. Callers should drain the value channel and then check the error
+// channel. If a decode error handler has been set on the cursor with SetDecodeErrorHandler, documents that cannot be
+// decoded are passed to the handler and skipped instead, and the handler is called from the background goroutine.
+//
+// The cursor is closed when streaming stops and must not be used by the caller after Stream is called.
+//
+// This function requires Go 1.18 or later.
+func Stream[T any](ctx context.Context, cursor *Cursor, buffer int) (<-chan T, <-chan error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if buffer < 0 {
+		buffer = 0
+	}
+
+	values := make(chan T, buffer)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(values)
+		defer cursor.Close(context.Background())
+
+		for cursor.Next(ctx) {
+			if err := ctx.Err(); err != nil {
+				errs <- err
+				return
+			}
+
+			var val T
+			skipped, err := cursor.decodeDocument(cursor.Current, &val)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if skipped {
+				continue
+			}
+
+			select {
+			case values <- val:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			errs <- err
+		}
+	}()
+
+	return values, errs
+}
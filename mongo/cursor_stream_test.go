@@ -0,0 +1,67 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build go1.18
+// +build go1.18
+
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+func TestStream(t *testing.T) {
+	type Document struct {
+		Foo int32 `bson:"foo"`
+	}
+
+	t.Run("streams all documents", func(t *testing.T) {
+		tbc := newTestBatchCursor(3, 4)
+		cursor, err := newCursor(tbc, nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+
+		values, errs := Stream[Document](context.Background(), cursor, 2)
+		var count int32
+		for doc := range values {
+			assert.Equal(t, count, doc.Foo, "expected foo %v, got %v", count, doc.Foo)
+			count++
+		}
+		err = <-errs
+		assert.Nil(t, err, "Stream error: %v", err)
+		assert.Equal(t, int32(12), count, "expected 12 documents, got %v", count)
+		assert.True(t, tbc.closed, "expected batch cursor to be closed but was not")
+	})
+	t.Run("decode error is reported", func(t *testing.T) {
+		cursor, err := newCursor(newTestBatchCursor(1, 2), nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+
+		values, errs := Stream[struct {
+			Foo string `bson:"foo"`
+		}](context.Background(), cursor, 0)
+		for range values {
+		}
+		err = <-errs
+		assert.NotNil(t, err, "expected decode error, got nil")
+	})
+	t.Run("context cancellation stops the stream", func(t *testing.T) {
+		tbc := newTestBatchCursor(1, 5)
+		cursor, err := newCursor(tbc, nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		values, errs := Stream[Document](ctx, cursor, 0)
+		<-values
+		cancel()
+		for range values {
+		}
+		err = <-errs
+		assert.Equal(t, context.Canceled, err, "expected error %v, got %v", context.Canceled, err)
+		assert.True(t, tbc.closed, "expected batch cursor to be closed but was not")
+	})
+}
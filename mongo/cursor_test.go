@@ -8,6 +8,7 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -85,12 +86,101 @@ func (tbc *testBatchCursor) Close(context.Context) error {
 	return nil
 }
 
+// sizedBatchCursor is a testBatchCursor that records the batch sizes set on it.
+type sizedBatchCursor struct {
+	*testBatchCursor
+	sizes []int32
+}
+
+func (sbc *sizedBatchCursor) SetBatchSize(size int32) {
+	sbc.sizes = append(sbc.sizes, size)
+}
+
 func TestCursor(t *testing.T) {
 	t.Run("loops until docs available", func(t *testing.T) {})
 	t.Run("returns false on context cancellation", func(t *testing.T) {})
 	t.Run("returns false if error occurred", func(t *testing.T) {})
 	t.Run("returns false if ID is zero and no more docs", func(t *testing.T) {})
 
+	t.Run("TestNextBatch", func(t *testing.T) {
+		t.Run("returns each batch", func(t *testing.T) {
+			cursor, err := newCursor(newTestBatchCursor(3, 2), nil)
+			assert.Nil(t, err, "newCursor error: %v", err)
+
+			var foos []int32
+			var sizes []int
+			for cursor.NextBatch(context.Background()) {
+				sizes = append(sizes, len(cursor.RawBatch()))
+				for _, doc := range cursor.RawBatch() {
+					foos = append(foos, doc.Lookup("foo").Int32())
+				}
+			}
+			assert.Nil(t, cursor.Err(), "cursor error: %v", cursor.Err())
+			assert.Equal(t, []int{2, 2, 2}, sizes, "expected batch sizes %v, got %v", []int{2, 2, 2}, sizes)
+			assert.Equal(t, []int32{0, 1, 2, 3, 4, 5}, foos, "expected documents 0-5, got %v", foos)
+		})
+		t.Run("mixed with Next", func(t *testing.T) {
+			cursor, err := newCursor(newTestBatchCursor(2, 3), nil)
+			assert.Nil(t, err, "newCursor error: %v", err)
+
+			assert.True(t, cursor.Next(context.Background()), "expected Next to return true")
+			assert.Equal(t, int32(0), cursor.Current.Lookup("foo").Int32(), "expected first document")
+
+			assert.True(t, cursor.NextBatch(context.Background()), "expected NextBatch to return true")
+			batch := cursor.RawBatch()
+			assert.Equal(t, 2, len(batch), "expected rest of the first batch, got %v documents", len(batch))
+			assert.Equal(t, int32(1), batch[0].Lookup("foo").Int32(), "expected second document")
+
+			assert.True(t, cursor.Next(context.Background()), "expected Next to return true")
+			assert.Equal(t, int32(3), cursor.Current.Lookup("foo").Int32(), "expected first document of second batch")
+			assert.True(t, cursor.NextBatch(context.Background()), "expected NextBatch to return true")
+			assert.Equal(t, 2, len(cursor.RawBatch()), "expected 2 documents, got %v", len(cursor.RawBatch()))
+			assert.False(t, cursor.NextBatch(context.Background()), "expected cursor to be exhausted")
+			assert.Equal(t, 0, len(cursor.RawBatch()), "expected empty batch, got %v", len(cursor.RawBatch()))
+		})
+	})
+
+	t.Run("TestSetBatchSize", func(t *testing.T) {
+		t.Run("passed to batch cursor", func(t *testing.T) {
+			sbc := &sizedBatchCursor{testBatchCursor: newTestBatchCursor(2, 2)}
+			cursor, err := newCursor(sbc, nil)
+			assert.Nil(t, err, "newCursor error: %v", err)
+
+			cursor.SetBatchSize(5)
+			for cursor.Next(context.Background()) {
+			}
+			assert.Equal(t, []int32{5}, sbc.sizes, "expected batch sizes %v, got %v", []int32{5}, sbc.sizes)
+		})
+		t.Run("adaptive", func(t *testing.T) {
+			// each document is 14 bytes, so a 140 byte target should request 10 documents per batch.
+			sbc := &sizedBatchCursor{testBatchCursor: newTestBatchCursor(3, 2)}
+			cursor, err := newCursor(sbc, nil)
+			assert.Nil(t, err, "newCursor error: %v", err)
+
+			cursor.SetAdaptiveBatchSize(140)
+			assert.Equal(t, 0, len(sbc.sizes), "expected no batch size before any documents are seen, got %v", sbc.sizes)
+			assert.True(t, cursor.Next(context.Background()), "expected Next to return true")
+			assert.Equal(t, []int32{10}, sbc.sizes, "expected batch sizes %v, got %v", []int32{10}, sbc.sizes)
+
+			cursor.SetBatchSize(3)
+			for cursor.Next(context.Background()) {
+			}
+			expected := []int32{10, 3}
+			assert.Equal(t, expected, sbc.sizes, "expected batch sizes %v, got %v", expected, sbc.sizes)
+		})
+		t.Run("prefetch", func(t *testing.T) {
+			sbc := &sizedBatchCursor{testBatchCursor: newTestBatchCursor(1, 2)}
+			cursor, err := newCursor(newPrefetchBatchCursor(sbc, 1), nil)
+			assert.Nil(t, err, "newCursor error: %v", err)
+
+			cursor.SetBatchSize(7)
+			for cursor.Next(context.Background()) {
+			}
+			_ = cursor.Close(context.Background())
+			assert.Equal(t, []int32{7}, sbc.sizes, "expected batch sizes %v, got %v", []int32{7}, sbc.sizes)
+		})
+	})
+
 	t.Run("TestAll", func(t *testing.T) {
 		t.Run("errors if argument is not pointer to slice", func(t *testing.T) {
 			cursor, err := newCursor(newTestBatchCursor(1, 5), nil)
@@ -157,5 +247,71 @@ func TestCursor(t *testing.T) {
 			assert.Nil(t, err, "All error: %v", err)
 			assert.True(t, tbc.closed, "expected batch cursor to be closed but was not")
 		})
+
+		t.Run("decode errors", func(t *testing.T) {
+			type Document struct {
+				Foo int32 `bson:"foo"`
+			}
+			newMixedCursor := func(t *testing.T) *Cursor {
+				t.Helper()
+
+				var data []byte
+				for _, doc := range []bson.D{{{"foo", int32(1)}}, {{"foo", "bar"}}, {{"foo", int32(3)}}} {
+					b, err := bson.Marshal(doc)
+					assert.Nil(t, err, "Marshal error: %v", err)
+					data = append(data, b...)
+				}
+				tbc := &testBatchCursor{
+					batches: []*bsoncore.DocumentSequence{{Style: bsoncore.SequenceStyle, Data: data}},
+				}
+				cursor, err := newCursor(tbc, nil)
+				assert.Nil(t, err, "newCursor error: %v", err)
+				return cursor
+			}
+
+			t.Run("abort iteration by default", func(t *testing.T) {
+				var docs []Document
+				err := newMixedCursor(t).All(context.Background(), &docs)
+				assert.NotNil(t, err, "expected decode error, got nil")
+			})
+			t.Run("skipped with handler", func(t *testing.T) {
+				cursor := newMixedCursor(t)
+				var skipped []bson.Raw
+				cursor.SetDecodeErrorHandler(func(doc bson.Raw, err error) {
+					assert.NotNil(t, err, "expected handler to be called with an error")
+					skipped = append(skipped, append(bson.Raw(nil), doc...))
+				})
+
+				var docs []Document
+				err := cursor.All(context.Background(), &docs)
+				assert.Nil(t, err, "All error: %v", err)
+				expected := []Document{{Foo: 1}, {Foo: 3}}
+				assert.Equal(t, expected, docs, "expected docs %v, got %v", expected, docs)
+				assert.Equal(t, 1, len(skipped), "expected 1 skipped document, got %v", len(skipped))
+				assert.Equal(t, "bar", skipped[0].Lookup("foo").StringValue(),
+					"expected skipped document to be {foo: 'bar'}, got %v", skipped[0])
+			})
+		})
+	})
+	t.Run("NewCursorFromDocuments", func(t *testing.T) {
+		cursorErr := errors.New("cursor error")
+		cursor, err := NewCursorFromDocuments([]interface{}{bson.D{{"x", 1}}, bson.M{"x": 2}}, cursorErr, nil)
+		assert.Nil(t, err, "NewCursorFromDocuments error: %v", err)
+		assert.Equal(t, int64(0), cursor.ID(), "expected ID 0, got %v", cursor.ID())
+
+		var got []int32
+		for cursor.Next(context.Background()) {
+			got = append(got, cursor.Current.Lookup("x").Int32())
+		}
+		assert.Equal(t, []int32{1, 2}, got, "expected values [1 2], got %v", got)
+		assert.Equal(t, cursorErr, cursor.Err(), "expected error %v, got %v", cursorErr, cursor.Err())
+
+		cursor, err = NewCursorFromDocuments(nil, nil, nil)
+		assert.Nil(t, err, "NewCursorFromDocuments error: %v", err)
+		assert.False(t, cursor.Next(context.Background()), "expected empty cursor")
+		assert.Nil(t, cursor.Err(), "expected no error, got %v", cursor.Err())
+
+		_, err = NewCursorFromDocuments([]interface{}{nil}, nil, nil)
+		assert.Equal(t, ErrNilDocument, err, "expected error %v, got %v", ErrNilDocument, err)
 	})
 }
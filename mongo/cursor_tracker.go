@@ -0,0 +1,143 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/logger"
+)
+
+// OpenCursor describes a cursor created by a Client that has not been closed or exhausted.
+type OpenCursor struct {
+	ID        int64
+	Namespace string
+	Created   time.Time
+	// Stack is the stack trace of the goroutine that created the cursor.
+	Stack string
+}
+
+// cursorTracker keeps track of the open cursors for a Client and reports cursors that stay open for longer than a
+// threshold to the cursor monitor and the client's logger.
+type cursorTracker struct {
+	threshold time.Duration
+	monitor   *event.CursorMonitor
+	logger    *logger.Logger
+
+	mu      sync.Mutex
+	nextID  uint64
+	cursors map[uint64]*trackedCursor
+}
+
+type trackedCursor struct {
+	cursorID  int64
+	namespace string
+	created   time.Time
+	stack     string
+	timer     *time.Timer
+}
+
+func newCursorTracker(threshold time.Duration, monitor *event.CursorMonitor, log *logger.Logger) *cursorTracker {
+	return &cursorTracker{
+		threshold: threshold,
+		monitor:   monitor,
+		logger:    log,
+		cursors:   make(map[uint64]*trackedCursor),
+	}
+}
+
+// track starts tracking cursor. It is a no-op if t is nil or the cursor has already been exhausted.
+func (t *cursorTracker) track(cursor *Cursor, namespace string) {
+	if t == nil || cursor == nil || cursor.ID() == 0 {
+		return
+	}
+
+	tc := &trackedCursor{
+		cursorID:  cursor.ID(),
+		namespace: namespace,
+		created:   time.Now(),
+		stack:     string(debug.Stack()),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.nextID++
+	id := t.nextID
+	t.cursors[id] = tc
+	tc.timer = time.AfterFunc(t.threshold, func() { t.report(id) })
+
+	cursor.tracker = t
+	cursor.trackingID = id
+}
+
+// untrack stops tracking the cursor with the given tracking ID.
+func (t *cursorTracker) untrack(id uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if tc, ok := t.cursors[id]; ok {
+		tc.timer.Stop()
+		delete(t.cursors, id)
+	}
+}
+
+func (t *cursorTracker) report(id uint64) {
+	t.mu.Lock()
+	tc, ok := t.cursors[id]
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	evt := &event.CursorLeakEvent{
+		CursorID:  tc.cursorID,
+		Namespace: tc.namespace,
+		Created:   tc.created,
+		Age:       time.Since(tc.created),
+		Stack:     tc.stack,
+	}
+	t.logger.Print(logger.ComponentClient, logger.LevelInfo, logger.CursorNotClosed,
+		"cursorId", evt.CursorID,
+		"namespace", evt.Namespace,
+		"ageMS", float64(evt.Age)/float64(time.Millisecond),
+		"stack", evt.Stack)
+	if t.monitor != nil && t.monitor.Leaked != nil {
+		t.monitor.Leaked(evt)
+	}
+}
+
+// openCursors returns the cursors that are currently tracked, oldest first.
+func (t *cursorTracker) openCursors() []OpenCursor {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	open := make([]OpenCursor, 0, len(t.cursors))
+	for _, tc := range t.cursors {
+		open = append(open, OpenCursor{
+			ID:        tc.cursorID,
+			Namespace: tc.namespace,
+			Created:   tc.created,
+			Stack:     tc.stack,
+		})
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].Created.Before(open[j].Created) })
+	return open
+}
+
+// namespace returns the namespace string for a database and collection. If collection is empty, only the database
+// name is returned.
+func namespace(db, collection string) string {
+	if collection == "" {
+		return db
+	}
+	return db + "." + collection
+}
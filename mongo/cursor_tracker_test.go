@@ -0,0 +1,106 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/logger"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+func TestCursorTracker(t *testing.T) {
+	t.Run("reports cursors that are not closed", func(t *testing.T) {
+		leaked := make(chan *event.CursorLeakEvent, 1)
+		tracker := newCursorTracker(10*time.Millisecond, &event.CursorMonitor{
+			Leaked: func(evt *event.CursorLeakEvent) { leaked <- evt },
+		}, nil)
+
+		cursor, err := newCursor(newTestBatchCursor(2, 1), nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+		tracker.track(cursor, namespace("db", "coll"))
+
+		open := tracker.openCursors()
+		assert.Equal(t, 1, len(open), "expected 1 open cursor, got %v", len(open))
+		assert.Equal(t, "db.coll", open[0].Namespace, "expected namespace db.coll, got %v", open[0].Namespace)
+
+		select {
+		case evt := <-leaked:
+			assert.Equal(t, int64(10), evt.CursorID, "expected cursor ID 10, got %v", evt.CursorID)
+			assert.True(t, evt.Age >= 10*time.Millisecond, "expected age of at least 10ms, got %v", evt.Age)
+			assert.True(t, strings.Contains(evt.Stack, "TestCursorTracker"),
+				"expected creation stack to include the test function, got %v", evt.Stack)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for cursor leak event")
+		}
+	})
+	t.Run("closed cursors are not reported", func(t *testing.T) {
+		leaked := make(chan *event.CursorLeakEvent, 1)
+		tracker := newCursorTracker(10*time.Millisecond, &event.CursorMonitor{
+			Leaked: func(evt *event.CursorLeakEvent) { leaked <- evt },
+		}, nil)
+
+		cursor, err := newCursor(newTestBatchCursor(2, 1), nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+		tracker.track(cursor, namespace("db", "coll"))
+		err = cursor.Close(context.Background())
+		assert.Nil(t, err, "Close error: %v", err)
+
+		open := tracker.openCursors()
+		assert.Equal(t, 0, len(open), "expected no open cursors, got %v", len(open))
+		select {
+		case evt := <-leaked:
+			t.Fatalf("unexpected cursor leak event: %v", evt)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+	t.Run("logs cursors that are not closed", func(t *testing.T) {
+		logged := make(chan string, 1)
+		log, err := logger.New(chanSink(logged), 0, map[logger.Component]logger.Level{
+			logger.ComponentClient: logger.LevelInfo,
+		})
+		assert.Nil(t, err, "logger.New error: %v", err)
+		tracker := newCursorTracker(10*time.Millisecond, nil, log)
+
+		cursor, err := newCursor(newTestBatchCursor(2, 1), nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+		tracker.track(cursor, namespace("db", "coll"))
+
+		select {
+		case msg := <-logged:
+			assert.True(t, strings.HasPrefix(msg, "Cursor not closed cursorId 10 namespace db.coll"),
+				"unexpected message %v", msg)
+			assert.True(t, strings.Contains(msg, "TestCursorTracker"),
+				"expected creation stack to include the test function, got %v", msg)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for cursor leak message")
+		}
+	})
+	t.Run("exhausted cursors are untracked", func(t *testing.T) {
+		tracker := newCursorTracker(time.Minute, nil, nil)
+
+		cursor, err := newCursor(newTestBatchCursor(2, 1), nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+		tracker.track(cursor, namespace("db", "coll"))
+		for cursor.Next(context.Background()) {
+		}
+
+		open := tracker.openCursors()
+		assert.Equal(t, 0, len(open), "expected no open cursors, got %v", len(open))
+	})
+	t.Run("nil tracker", func(t *testing.T) {
+		var tracker *cursorTracker
+		cursor, err := newCursor(newTestBatchCursor(1, 1), nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+		tracker.track(cursor, "db.coll")
+		assert.Nil(t, cursor.tracker, "expected cursor not to be tracked")
+	})
+}
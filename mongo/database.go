@@ -30,12 +30,13 @@ var (
 
 // Database is a handle to a MongoDB database. It is safe for concurrent use by multiple goroutines.
 type Database struct {
-	client         *Client
-	name           string
+	client *Client
+	name   string
+	// The read concern, write concern, and read preference are nil if they were not specified for the Database, in
+	// which case the current defaults of the Client are used.
 	readConcern    *readconcern.ReadConcern
 	writeConcern   *writeconcern.WriteConcern
 	readPreference *readpref.ReadPref
-	readSelector   description.ServerSelector
 	writeSelector  description.ServerSelector
 	registry       *bsoncodec.Registry
 }
@@ -43,21 +44,6 @@ type Database struct {
 func newDatabase(client *Client, name string, opts ...*options.DatabaseOptions) *Database {
 	dbOpt := options.MergeDatabaseOptions(opts...)
 
-	rc := client.readConcern
-	if dbOpt.ReadConcern != nil {
-		rc = dbOpt.ReadConcern
-	}
-
-	rp := client.readPreference
-	if dbOpt.ReadPreference != nil {
-		rp = dbOpt.ReadPreference
-	}
-
-	wc := client.writeConcern
-	if dbOpt.WriteConcern != nil {
-		wc = dbOpt.WriteConcern
-	}
-
 	reg := client.registry
 	if dbOpt.Registry != nil {
 		reg = dbOpt.Registry
@@ -66,17 +52,12 @@ func newDatabase(client *Client, name string, opts ...*options.DatabaseOptions)
 	db := &Database{
 		client:         client,
 		name:           name,
-		readPreference: rp,
-		readConcern:    rc,
-		writeConcern:   wc,
+		readPreference: dbOpt.ReadPreference,
+		readConcern:    dbOpt.ReadConcern,
+		writeConcern:   dbOpt.WriteConcern,
 		registry:       reg,
 	}
 
-	db.readSelector = description.CompositeSelector([]description.ServerSelector{
-		description.ReadPrefSelector(db.readPreference),
-		description.LatencySelector(db.client.localThreshold),
-	})
-
 	db.writeSelector = description.CompositeSelector([]description.ServerSelector{
 		description.WriteSelector(),
 		description.LatencySelector(db.client.localThreshold),
@@ -114,18 +95,19 @@ func (db *Database) Collection(name string, opts ...*options.CollectionOptions)
 // For more information about the command, see https://docs.mongodb.com/manual/reference/command/aggregate/.
 func (db *Database) Aggregate(ctx context.Context, pipeline interface{},
 	opts ...*options.AggregateOptions) (*Cursor, error) {
+	rp := db.ReadPreference()
 	a := aggregateParams{
 		ctx:            ctx,
 		pipeline:       pipeline,
 		client:         db.client,
 		registry:       db.registry,
-		readConcern:    db.readConcern,
-		writeConcern:   db.writeConcern,
+		readConcern:    db.ReadConcern(),
+		writeConcern:   db.WriteConcern(),
 		retryRead:      db.client.retryReads,
 		db:             db.name,
-		readSelector:   db.readSelector,
+		readSelector:   makeReadSelector(rp, db.client.localThreshold),
 		writeSelector:  db.writeSelector,
-		readPreference: db.readPreference,
+		readPreference: rp,
 		opts:           opts,
 	}
 	return aggregate(a)
@@ -167,7 +149,7 @@ func (db *Database) processRunCommand(ctx context.Context, cmd interface{},
 	return operation.NewCommand(runCmdDoc).
 		Session(sess).CommandMonitor(db.client.monitor).
 		ServerSelector(readSelect).ClusterClock(db.client.clock).
-		Database(db.name).Deployment(db.client.deployment).ReadConcern(db.readConcern).Crypt(db.client.crypt), sess, nil
+		Database(db.name).Deployment(db.client.deployment).ReadConcern(db.ReadConcern()).Crypt(db.client.crypt), sess, nil
 }
 
 // RunCommand executes the given command against the database.
@@ -216,7 +198,7 @@ func (db *Database) RunCommandCursor(ctx context.Context, runCommand interface{}
 		return nil, replaceErrors(err)
 	}
 
-	if err = op.Execute(ctx); err != nil {
+	if err = op.CreateCursor(true).Execute(ctx); err != nil {
 		closeImplicitSession(sess)
 		return nil, replaceErrors(err)
 	}
@@ -227,7 +209,11 @@ func (db *Database) RunCommandCursor(ctx context.Context, runCommand interface{}
 		return nil, replaceErrors(err)
 	}
 	cursor, err := newCursorWithSession(bc, db.registry, sess)
-	return cursor, replaceErrors(err)
+	if err != nil {
+		return nil, replaceErrors(err)
+	}
+	db.client.cursorTracker.track(cursor, namespace(db.name, ""))
+	return cursor, nil
 }
 
 // Drop drops the database on the server. This method ignores "namespace not found" errors so it is safe to drop
@@ -252,7 +238,7 @@ func (db *Database) Drop(ctx context.Context) error {
 		return err
 	}
 
-	wc := db.writeConcern
+	wc := db.WriteConcern()
 	if sess.TransactionRunning() {
 		wc = nil
 	}
@@ -318,7 +304,7 @@ func (db *Database) ListCollections(ctx context.Context, filter interface{}, opt
 
 	lco := options.MergeListCollectionsOptions(opts...)
 	op := operation.NewListCollections(filterDoc).
-		Session(sess).ReadPreference(db.readPreference).CommandMonitor(db.client.monitor).
+		Session(sess).ReadPreference(db.ReadPreference()).CommandMonitor(db.client.monitor).
 		ServerSelector(selector).ClusterClock(db.client.clock).
 		Database(db.name).Deployment(db.client.deployment).Crypt(db.client.crypt)
 	if lco.NameOnly != nil {
@@ -342,7 +328,11 @@ func (db *Database) ListCollections(ctx context.Context, filter interface{}, opt
 		return nil, replaceErrors(err)
 	}
 	cursor, err := newCursorWithSession(bc, db.registry, sess)
-	return cursor, replaceErrors(err)
+	if err != nil {
+		return nil, replaceErrors(err)
+	}
+	db.client.cursorTracker.track(cursor, namespace(db.name, ""))
+	return cursor, nil
 }
 
 // ListCollectionNames executes a listCollections command and returns a slice containing the names of the collections
@@ -391,19 +381,31 @@ func (db *Database) ListCollectionNames(ctx context.Context, filter interface{},
 	return names, nil
 }
 
-// ReadConcern returns the read concern used to configure the Database object.
+// ReadConcern returns the read concern used to configure the Database object. If the Database was not configured with
+// a read concern, the current default read concern of the Client is returned.
 func (db *Database) ReadConcern() *readconcern.ReadConcern {
-	return db.readConcern
+	if db.readConcern != nil {
+		return db.readConcern
+	}
+	return db.client.ReadConcern()
 }
 
-// ReadPreference returns the read preference used to configure the Database object.
+// ReadPreference returns the read preference used to configure the Database object. If the Database was not
+// configured with a read preference, the current default read preference of the Client is returned.
 func (db *Database) ReadPreference() *readpref.ReadPref {
-	return db.readPreference
+	if db.readPreference != nil {
+		return db.readPreference
+	}
+	return db.client.ReadPreference()
 }
 
-// WriteConcern returns the write concern used to configure the Database object.
+// WriteConcern returns the write concern used to configure the Database object. If the Database was not configured
+// with a write concern, the current default write concern of the Client is returned.
 func (db *Database) WriteConcern() *writeconcern.WriteConcern {
-	return db.writeConcern
+	if db.writeConcern != nil {
+		return db.writeConcern
+	}
+	return db.client.WriteConcern()
 }
 
 // Watch returns a change stream for all changes to the corresponding database. See
@@ -423,8 +425,8 @@ func (db *Database) Watch(ctx context.Context, pipeline interface{},
 	opts ...*options.ChangeStreamOptions) (*ChangeStream, error) {
 
 	csConfig := changeStreamConfig{
-		readConcern:    db.readConcern,
-		readPreference: db.readPreference,
+		readConcern:    db.ReadConcern(),
+		readPreference: db.ReadPreference(),
 		client:         db.client,
 		registry:       db.registry,
 		streamType:     DatabaseStream,
@@ -27,12 +27,12 @@ func setupDb(name string, opts ...*options.DatabaseOptions) *Database {
 
 func compareDbs(t *testing.T, expected, got *Database) {
 	t.Helper()
-	assert.Equal(t, expected.readPreference, got.readPreference,
-		"expected read preference %v, got %v", expected.readPreference, got.readPreference)
-	assert.Equal(t, expected.readConcern, got.readConcern,
-		"expected read concern %v, got %v", expected.readConcern, got.readConcern)
-	assert.Equal(t, expected.writeConcern, got.writeConcern,
-		"expected write concern %v, got %v", expected.writeConcern, got.writeConcern)
+	assert.Equal(t, expected.readPreference, got.ReadPreference(),
+		"expected read preference %v, got %v", expected.readPreference, got.ReadPreference())
+	assert.Equal(t, expected.readConcern, got.ReadConcern(),
+		"expected read concern %v, got %v", expected.readConcern, got.ReadConcern())
+	assert.Equal(t, expected.writeConcern, got.WriteConcern(),
+		"expected write concern %v, got %v", expected.writeConcern, got.WriteConcern())
 	assert.Equal(t, expected.registry, got.registry,
 		"expected write concern %v, got %v", expected.registry, got.registry)
 }
@@ -0,0 +1,173 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"runtime"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/version"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+var readPrefModes = map[readpref.Mode]string{
+	readpref.PrimaryMode:            "primary",
+	readpref.PrimaryPreferredMode:   "primaryPreferred",
+	readpref.SecondaryMode:          "secondary",
+	readpref.SecondaryPreferredMode: "secondaryPreferred",
+	readpref.NearestMode:            "nearest",
+}
+
+// Diagnostics returns a snapshot of the state of the client that is suitable for attaching to support tickets. The
+// document contains the driver, Go, and platform versions, a summary of the client options, the topology description,
+// the statistics of each connection pool, the number of sessions in progress and open cursors, and the most recent
+// errors returned by operations and heartbeats. Credentials, proxy credentials, and the connection string are never
+// included in the options summary. Durations are reported in milliseconds.
+//
+// The returned document can be marshaled to both BSON and JSON.
+func (c *Client) Diagnostics() bson.M {
+	var errs []event.ServerError
+	if t, ok := c.deployment.(*topology.Topology); ok {
+		errs = t.RecentErrors()
+	}
+	return diagnostics(c.options, c.TopologyDescription(), c.PoolStats(), c.NumberSessionsInProgress(),
+		len(c.OpenCursors()), errs)
+}
+
+func diagnostics(opts bson.M, topo event.TopologyDescription, pools []event.PoolStats, sessions, cursors int,
+	errs []event.ServerError) bson.M {
+
+	servers := make([]bson.M, 0, len(topo.Servers))
+	for _, desc := range topo.Servers {
+		server := bson.M{
+			"address":        desc.Address,
+			"kind":           desc.Kind,
+			"averageRTTMS":   milliseconds(desc.AverageRTT),
+			"lastUpdateTime": desc.LastUpdateTime,
+			"minWireVersion": desc.MinWireVersion,
+			"maxWireVersion": desc.MaxWireVersion,
+		}
+		if desc.SetName != "" {
+			server["setName"] = desc.SetName
+		}
+		if desc.LastError != nil {
+			server["lastError"] = desc.LastError.Error()
+		}
+		servers = append(servers, server)
+	}
+
+	poolDocs := make([]bson.M, 0, len(pools))
+	for _, stats := range pools {
+		poolDocs = append(poolDocs, bson.M{
+			"address":           stats.Address,
+			"inUse":             int64(stats.InUse),
+			"idle":              int64(stats.Idle),
+			"pending":           int64(stats.Pending),
+			"waitQueueLength":   int64(stats.WaitQueueLength),
+			"checkOutWaitP99MS": milliseconds(stats.CheckOutWaitP99),
+			"created":           int64(stats.Created),
+		})
+	}
+
+	errDocs := make([]bson.M, 0, len(errs))
+	for _, e := range errs {
+		errDocs = append(errDocs, bson.M{
+			"address": e.Address,
+			"time":    e.Time,
+			"source":  e.Source,
+			"error":   e.Error.Error(),
+		})
+	}
+
+	return bson.M{
+		"time":          time.Now(),
+		"driverVersion": version.Driver,
+		"goVersion":     runtime.Version(),
+		"platform":      runtime.GOOS + "/" + runtime.GOARCH,
+		"options":       opts,
+		"topology": bson.M{
+			"kind":    topo.Kind,
+			"servers": servers,
+		},
+		"pools":              poolDocs,
+		"sessionsInProgress": sessions,
+		"openCursors":        cursors,
+		"recentErrors":       errDocs,
+	}
+}
+
+// summarizeOptions returns the client options that are useful for diagnosing problems. Secrets, such as credentials
+// and the connection string, are left out.
+func summarizeOptions(opts *options.ClientOptions) bson.M {
+	summary := bson.M{}
+	if len(opts.Hosts) > 0 {
+		summary["hosts"] = opts.Hosts
+	}
+	if opts.AppName != nil {
+		summary["appName"] = *opts.AppName
+	}
+	if opts.ReplicaSet != nil {
+		summary["replicaSet"] = *opts.ReplicaSet
+	}
+	if opts.Auth != nil {
+		summary["auth"] = bson.M{"mechanism": opts.Auth.AuthMechanism, "source": opts.Auth.AuthSource}
+	}
+	if opts.TLSConfig != nil {
+		summary["tls"] = true
+	}
+	if len(opts.Compressors) > 0 {
+		summary["compressors"] = opts.Compressors
+	}
+	if opts.Direct != nil {
+		summary["directConnection"] = *opts.Direct
+	}
+	if opts.LoadBalanced != nil {
+		summary["loadBalanced"] = *opts.LoadBalanced
+	}
+	if opts.MaxPoolSize != nil {
+		summary["maxPoolSize"] = int64(*opts.MaxPoolSize)
+	}
+	if opts.MinPoolSize != nil {
+		summary["minPoolSize"] = int64(*opts.MinPoolSize)
+	}
+	if opts.RetryReads != nil {
+		summary["retryReads"] = *opts.RetryReads
+	}
+	if opts.RetryWrites != nil {
+		summary["retryWrites"] = *opts.RetryWrites
+	}
+	if opts.ReadPreference != nil {
+		summary["readPreference"] = readPrefModes[opts.ReadPreference.Mode()]
+	}
+	if opts.ReadConcern != nil {
+		summary["readConcern"] = opts.ReadConcern.GetLevel()
+	}
+	if opts.WriteConcern != nil {
+		summary["writeConcern"] = bson.M{"w": opts.WriteConcern.GetW(), "j": opts.WriteConcern.GetJ()}
+	}
+	for name, d := range map[string]*time.Duration{
+		"connectTimeoutMS":         opts.ConnectTimeout,
+		"heartbeatFrequencyMS":     opts.HeartbeatInterval,
+		"localThresholdMS":         opts.LocalThreshold,
+		"maxIdleTimeMS":            opts.MaxConnIdleTime,
+		"serverSelectionTimeoutMS": opts.ServerSelectionTimeout,
+		"socketTimeoutMS":          opts.SocketTimeout,
+	} {
+		if d != nil {
+			summary[name] = milliseconds(*d)
+		}
+	}
+	return summary
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
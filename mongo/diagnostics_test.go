@@ -0,0 +1,77 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/version"
+)
+
+func TestDiagnostics(t *testing.T) {
+	t.Run("options summary omits secrets", func(t *testing.T) {
+		opts := options.Client().
+			ApplyURI("mongodb://user:pencil@a:27017,b:27017/?replicaSet=rs0&authSource=admin").
+			SetProxyHost("proxy.example.com").SetProxyUsername("proxyuser").SetProxyPassword("proxypass").
+			SetReadPreference(readpref.SecondaryPreferred()).
+			SetConnectTimeout(5 * time.Second)
+		summary := summarizeOptions(opts)
+
+		assert.Equal(t, []string{"a:27017", "b:27017"}, summary["hosts"], "unexpected hosts")
+		assert.Equal(t, "rs0", summary["replicaSet"], "unexpected replica set")
+		assert.Equal(t, "secondaryPreferred", summary["readPreference"], "unexpected read preference")
+		assert.Equal(t, float64(5000), summary["connectTimeoutMS"], "unexpected connect timeout")
+		assert.Equal(t, bson.M{"mechanism": "", "source": "admin"}, summary["auth"], "unexpected auth summary")
+
+		b, err := json.Marshal(summary)
+		assert.Nil(t, err, "Marshal error: %v", err)
+		for _, secret := range []string{"user", "pencil", "proxyuser", "proxypass"} {
+			assert.False(t, strings.Contains(string(b), secret), "expected %q to be left out of %s", secret, b)
+		}
+	})
+	t.Run("document", func(t *testing.T) {
+		topo := event.TopologyDescription{
+			Kind:    "ReplicaSetNoPrimary",
+			Servers: []event.ServerDescription{{Address: "a:27017", Kind: "Unknown", LastError: errors.New("refused")}},
+		}
+		pools := []event.PoolStats{{Address: "a:27017", InUse: 2}}
+		errs := []event.ServerError{{Address: "a:27017", Source: "heartbeat", Error: errors.New("refused")}}
+		doc := diagnostics(bson.M{"appName": "app"}, topo, pools, 3, 1, errs)
+
+		assert.Equal(t, version.Driver, doc["driverVersion"], "unexpected driver version")
+		assert.Equal(t, 3, doc["sessionsInProgress"], "unexpected sessions in progress")
+		recent := doc["recentErrors"].([]bson.M)
+		assert.Equal(t, 1, len(recent), "expected 1 recent error, got %d", len(recent))
+		assert.Equal(t, "refused", recent[0]["error"], "unexpected recent error")
+
+		_, err := json.Marshal(doc)
+		assert.Nil(t, err, "json.Marshal error: %v", err)
+		raw, err := bson.Marshal(doc)
+		assert.Nil(t, err, "bson.Marshal error: %v", err)
+		kind, err := bson.Raw(raw).LookupErr("topology", "kind")
+		assert.Nil(t, err, "LookupErr error: %v", err)
+		assert.Equal(t, "ReplicaSetNoPrimary", kind.StringValue(), "unexpected topology kind")
+	})
+	t.Run("client", func(t *testing.T) {
+		client, err := NewClient(options.Client().SetAppName("diagnostics"))
+		assert.Nil(t, err, "NewClient error: %v", err)
+
+		doc := client.Diagnostics()
+		assert.Equal(t, "diagnostics", doc["options"].(bson.M)["appName"], "unexpected options summary")
+		_, err = bson.Marshal(doc)
+		assert.Nil(t, err, "bson.Marshal error: %v", err)
+	})
+}
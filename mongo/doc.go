@@ -116,6 +116,15 @@
 //    }
 //    aeo.SetExtraOptions(mongocryptdOpts)
 // To specify a process URI for mongocryptd, the "mongocryptdURI" option can be passed in the ExtraOptions map as well.
+//
+// Instead of mongocryptd, automatic encryption can use the crypt_shared library, which is loaded into the process so that
+// no separate process needs to be spawned or managed. If the library is found, mongocryptd is not used:
+//
+//    aeo := options.AutoEncryption().
+//        SetCryptSharedLibSearchPaths([]string{"/usr/local/lib", "$SYSTEM"}).
+//        SetCryptSharedLibRequired(true)
+// If the crypt_shared library is not required and cannot be loaded, the driver falls back to mongocryptd, and creating
+// the client returns an error if mongocryptd cannot be spawned either.
 // See the ClientSideEncryption and ClientSideEncryptionCreateKey examples below for code samples about using this
 // feature.
 //
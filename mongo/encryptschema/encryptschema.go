@@ -0,0 +1,495 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package encryptschema generates the encryption configuration of collections from the struct tags of the Go types
+// stored in them, so that the configuration is kept next to the models and cannot drift from them.
+//
+// A field is encrypted if it has an encrypt tag. The first element of the tag is the algorithm, which is
+// "deterministic" or "random" for client-side field level encryption, and "indexed", "unindexed", or "range" for
+// Queryable Encryption. The remaining elements are options of the form key=value:
+//
+//	keyAltName  the alternate name of the data key, which is resolved to its _id with the Generator's KeyResolver.
+//	            For the random algorithm, a value that starts with "/" is a JSON pointer to a field of the document
+//	            that contains the alternate name.
+//	keyId       the _id of the data key as a UUID string, such as "6b2a7c1e-7d1c-4b6e-9d1c-1f2e3d4c5b6a".
+//	contention  the contention factor of an indexed or range field.
+//	min, max    the bounds of a range field, in the type of the field. Dates are given in RFC 3339 format.
+//	sparsity    the sparsity of a range field.
+//	precision   the precision of a double or decimal range field.
+//	trimFactor  the trim factor of a range field.
+//
+// For example:
+//
+//	type User struct {
+//		Name string `bson:"name"`
+//		SSN  string `bson:"ssn" encrypt:"deterministic,keyAltName=users"`
+//		Age  int32  `bson:"age" encrypt:"range,keyAltName=users,min=0,max=150"`
+//	}
+//
+// The names of the fields are taken from their bson tags. Fields of struct types that are not encrypted are
+// traversed, so encrypted fields of embedded documents are included.
+package encryptschema
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// These constants are the algorithms of client-side field level encryption used in JSON schemas.
+const (
+	DeterministicAlgorithm = "AEAD_AES_256_CBC_HMAC_SHA_512-Deterministic"
+	RandomAlgorithm        = "AEAD_AES_256_CBC_HMAC_SHA_512-Random"
+)
+
+// KeyResolver returns the _id of the data key with the given alternate name.
+type KeyResolver func(keyAltName string) (primitive.Binary, error)
+
+// KeyVaultResolver returns a KeyResolver that looks up data keys in the given key vault collection.
+func KeyVaultResolver(ctx context.Context, keyVault *mongo.Collection) KeyResolver {
+	return func(keyAltName string) (primitive.Binary, error) {
+		var key struct {
+			ID primitive.Binary `bson:"_id"`
+		}
+		err := keyVault.FindOne(ctx, bson.D{{"keyAltNames", keyAltName}}).Decode(&key)
+		if err == mongo.ErrNoDocuments {
+			return primitive.Binary{}, fmt.Errorf("no data key with keyAltName %q", keyAltName)
+		}
+		return key.ID, err
+	}
+}
+
+// Generator generates JSON schemas for client-side field level encryption and encryptedFields documents for
+// Queryable Encryption.
+type Generator struct {
+	// KeyResolver is used to resolve the keyAltName options of the encrypt tags. It is required if a tag specifies
+	// a keyAltName, except for JSON pointers of the random algorithm.
+	KeyResolver KeyResolver
+
+	// If AllowMissingKeys is true, fields of Queryable Encryption without a keyAltName or keyId get a null keyId, so
+	// that the data keys can be created by ClientEncryption.CreateEncryptedCollection.
+	AllowMissingKeys bool
+}
+
+// field is an encrypted field of a model.
+type field struct {
+	path     []string
+	bsonType string
+
+	algorithm  string
+	keyAltName string
+	keyID      *primitive.Binary
+	contention *int64
+	min, max   interface{}
+	sparsity   *int64
+	precision  *int32
+	trimFactor *int32
+}
+
+// Schema returns the JSON schema for client-side field level encryption of documents of the type of model, which must
+// be a struct or a pointer to a struct.
+func (g *Generator) Schema(model interface{}) (bson.M, error) {
+	fields, err := g.fields(model)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := bson.M{"bsonType": "object"}
+	for _, f := range fields {
+		var algorithm string
+		switch f.algorithm {
+		case "deterministic":
+			algorithm = DeterministicAlgorithm
+		case "random":
+			algorithm = RandomAlgorithm
+		default:
+			return nil, fmt.Errorf("field %q uses the Queryable Encryption algorithm %q, which cannot be used in a "+
+				"JSON schema", strings.Join(f.path, "."), f.algorithm)
+		}
+
+		encrypt := bson.M{"bsonType": f.bsonType, "algorithm": algorithm}
+		switch {
+		case f.keyID != nil:
+			encrypt["keyId"] = bson.A{*f.keyID}
+		case strings.HasPrefix(f.keyAltName, "/"):
+			if f.algorithm != "random" {
+				return nil, fmt.Errorf("field %q: a keyAltName pointer can only be used with the random algorithm",
+					strings.Join(f.path, "."))
+			}
+			encrypt["keyId"] = f.keyAltName
+		case f.keyAltName != "":
+			id, err := g.resolve(f)
+			if err != nil {
+				return nil, err
+			}
+			encrypt["keyId"] = bson.A{id}
+		default:
+			return nil, fmt.Errorf("field %q has no keyAltName or keyId", strings.Join(f.path, "."))
+		}
+		// random encryption of a field that can hold several types does not require a bsonType
+		if f.bsonType == "" {
+			delete(encrypt, "bsonType")
+		}
+
+		// nest the encrypt document in the properties of the embedded documents of its path
+		props := schema
+		for _, name := range f.path[:len(f.path)-1] {
+			if _, ok := props["properties"]; !ok {
+				props["properties"] = bson.M{}
+			}
+			sub, ok := props["properties"].(bson.M)[name].(bson.M)
+			if !ok {
+				sub = bson.M{"bsonType": "object"}
+				props["properties"].(bson.M)[name] = sub
+			}
+			props = sub
+		}
+		if _, ok := props["properties"]; !ok {
+			props["properties"] = bson.M{}
+		}
+		props["properties"].(bson.M)[f.path[len(f.path)-1]] = bson.M{"encrypt": encrypt}
+	}
+	return schema, nil
+}
+
+// EncryptedFields returns the encryptedFields document for Queryable Encryption of documents of the type of model,
+// which must be a struct or a pointer to a struct.
+func (g *Generator) EncryptedFields(model interface{}) (bson.M, error) {
+	fields, err := g.fields(model)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make(bson.A, 0, len(fields))
+	for _, f := range fields {
+		path := strings.Join(f.path, ".")
+		doc := bson.M{"path": path, "bsonType": f.bsonType}
+		if f.bsonType == "" {
+			return nil, fmt.Errorf("field %q has a type that cannot be encrypted with Queryable Encryption", path)
+		}
+
+		switch {
+		case f.keyID != nil:
+			doc["keyId"] = *f.keyID
+		case f.keyAltName != "":
+			id, err := g.resolve(f)
+			if err != nil {
+				return nil, err
+			}
+			doc["keyId"] = id
+		case g.AllowMissingKeys:
+			doc["keyId"] = nil
+		default:
+			return nil, fmt.Errorf("field %q has no keyAltName or keyId", path)
+		}
+
+		switch f.algorithm {
+		case "indexed":
+			queries := bson.M{"queryType": "equality"}
+			if f.contention != nil {
+				queries["contention"] = *f.contention
+			}
+			doc["queries"] = queries
+		case "range":
+			queries := bson.M{"queryType": "range"}
+			if f.contention != nil {
+				queries["contention"] = *f.contention
+			}
+			if f.min != nil {
+				queries["min"] = f.min
+			}
+			if f.max != nil {
+				queries["max"] = f.max
+			}
+			if f.sparsity != nil {
+				queries["sparsity"] = *f.sparsity
+			}
+			if f.precision != nil {
+				queries["precision"] = *f.precision
+			}
+			if f.trimFactor != nil {
+				queries["trimFactor"] = *f.trimFactor
+			}
+			doc["queries"] = queries
+		case "unindexed":
+		default:
+			return nil, fmt.Errorf("field %q uses the client-side field level encryption algorithm %q, which cannot "+
+				"be used in encryptedFields", path, f.algorithm)
+		}
+		docs = append(docs, doc)
+	}
+	return bson.M{"fields": docs}, nil
+}
+
+// SchemaMap returns a schema map for AutoEncryptionOptions.SetSchemaMap from a map of namespaces, in the form
+// "database.collection", to the models stored in them.
+func (g *Generator) SchemaMap(models map[string]interface{}) (map[string]interface{}, error) {
+	schemaMap := make(map[string]interface{}, len(models))
+	for ns, model := range models {
+		schema, err := g.Schema(model)
+		if err != nil {
+			return nil, fmt.Errorf("error generating schema for %q: %v", ns, err)
+		}
+		schemaMap[ns] = schema
+	}
+	return schemaMap, nil
+}
+
+// EncryptedFieldsMap returns a map of namespaces to encryptedFields documents from a map of namespaces, in the form
+// "database.collection", to the models stored in them.
+func (g *Generator) EncryptedFieldsMap(models map[string]interface{}) (map[string]interface{}, error) {
+	fieldsMap := make(map[string]interface{}, len(models))
+	for ns, model := range models {
+		fields, err := g.EncryptedFields(model)
+		if err != nil {
+			return nil, fmt.Errorf("error generating encryptedFields for %q: %v", ns, err)
+		}
+		fieldsMap[ns] = fields
+	}
+	return fieldsMap, nil
+}
+
+func (g *Generator) resolve(f field) (primitive.Binary, error) {
+	if g.KeyResolver == nil {
+		return primitive.Binary{}, fmt.Errorf("field %q has a keyAltName but the generator has no KeyResolver",
+			strings.Join(f.path, "."))
+	}
+	id, err := g.KeyResolver(f.keyAltName)
+	if err != nil {
+		return primitive.Binary{}, fmt.Errorf("error resolving keyAltName %q of field %q: %v", f.keyAltName,
+			strings.Join(f.path, "."), err)
+	}
+	return id, nil
+}
+
+// fields returns the encrypted fields of the type of model.
+func (g *Generator) fields(model interface{}) ([]field, error) {
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("model must be a struct or a pointer to a struct, got %T", model)
+	}
+	return collectFields(t, nil, map[reflect.Type]bool{})
+}
+
+func collectFields(t reflect.Type, prefix []string, visiting map[reflect.Type]bool) ([]field, error) {
+	if visiting[t] {
+		return nil, nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		tags, err := bsoncodec.DefaultStructTagParser(sf)
+		if err != nil {
+			return nil, err
+		}
+		if tags.Skip {
+			continue
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		path := prefix
+		if !tags.Inline {
+			path = append(append([]string(nil), prefix...), tags.Name)
+		}
+
+		tag, ok := sf.Tag.Lookup("encrypt")
+		if !ok || tag == "" || tag == "-" {
+			if ft.Kind() == reflect.Struct && !isValueType(ft) {
+				sub, err := collectFields(ft, path, visiting)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, sub...)
+			}
+			continue
+		}
+		if tags.Inline {
+			return nil, fmt.Errorf("inline field %q cannot be encrypted", sf.Name)
+		}
+
+		f, err := parseTag(tag, path, ft)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// parseTag parses the encrypt tag of the field with the given path and type.
+func parseTag(tag string, path []string, t reflect.Type) (field, error) {
+	name := strings.Join(path, ".")
+	parts := strings.Split(tag, ",")
+	f := field{path: path, bsonType: bsonTypeOf(t), algorithm: strings.TrimSpace(parts[0])}
+	switch f.algorithm {
+	case "deterministic", "random", "indexed", "unindexed", "range":
+	default:
+		return field{}, fmt.Errorf("field %q has an unknown encryption algorithm %q", name, f.algorithm)
+	}
+	if f.algorithm == "deterministic" && (f.bsonType == "" || f.bsonType == "object" || f.bsonType == "array" ||
+		f.bsonType == "bool" || f.bsonType == "double" || f.bsonType == "decimal") {
+		return field{}, fmt.Errorf("field %q has a type that cannot be encrypted deterministically", name)
+	}
+
+	for _, opt := range parts[1:] {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 {
+			return field{}, fmt.Errorf("field %q has an invalid encrypt option %q", name, opt)
+		}
+		key, val := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+
+		var err error
+		switch key {
+		case "keyAltName":
+			f.keyAltName = val
+		case "keyId":
+			var id primitive.Binary
+			if id, err = parseUUID(val); err == nil {
+				f.keyID = &id
+			}
+		case "contention":
+			var n int64
+			if n, err = strconv.ParseInt(val, 10, 64); err == nil {
+				f.contention = &n
+			}
+		case "sparsity":
+			var n int64
+			if n, err = strconv.ParseInt(val, 10, 64); err == nil {
+				f.sparsity = &n
+			}
+		case "precision":
+			var n int64
+			if n, err = strconv.ParseInt(val, 10, 32); err == nil {
+				p := int32(n)
+				f.precision = &p
+			}
+		case "trimFactor":
+			var n int64
+			if n, err = strconv.ParseInt(val, 10, 32); err == nil {
+				tf := int32(n)
+				f.trimFactor = &tf
+			}
+		case "min":
+			f.min, err = parseBound(val, t)
+		case "max":
+			f.max, err = parseBound(val, t)
+		default:
+			return field{}, fmt.Errorf("field %q has an unknown encrypt option %q", name, key)
+		}
+		if err != nil {
+			return field{}, fmt.Errorf("field %q has an invalid %s option: %v", name, key, err)
+		}
+	}
+	return f, nil
+}
+
+var (
+	tTime       = reflect.TypeOf(time.Time{})
+	tDateTime   = reflect.TypeOf(primitive.DateTime(0))
+	tObjectID   = reflect.TypeOf(primitive.ObjectID{})
+	tDecimal128 = reflect.TypeOf(primitive.Decimal128{})
+	tBinary     = reflect.TypeOf(primitive.Binary{})
+	tTimestamp  = reflect.TypeOf(primitive.Timestamp{})
+	tRegex      = reflect.TypeOf(primitive.Regex{})
+)
+
+// isValueType returns true for struct types that are encoded as a single BSON value rather than as a document.
+func isValueType(t reflect.Type) bool {
+	switch t {
+	case tTime, tObjectID, tDecimal128, tBinary, tTimestamp, tRegex:
+		return true
+	}
+	return false
+}
+
+// bsonTypeOf returns the JSON schema bsonType of the values of t, or the empty string if it cannot be determined.
+func bsonTypeOf(t reflect.Type) string {
+	switch t {
+	case tTime, tDateTime:
+		return "date"
+	case tObjectID:
+		return "objectId"
+	case tDecimal128:
+		return "decimal"
+	case tBinary:
+		return "binData"
+	case tTimestamp:
+		return "timestamp"
+	case tRegex:
+		return "regex"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return "int"
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return "long"
+	case reflect.Float32, reflect.Float64:
+		return "double"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "binData"
+		}
+		return "array"
+	}
+	return ""
+}
+
+// parseBound parses the min or max option of a range field of type t.
+func parseBound(val string, t reflect.Type) (interface{}, error) {
+	switch bsonTypeOf(t) {
+	case "int":
+		n, err := strconv.ParseInt(val, 10, 32)
+		return int32(n), err
+	case "long":
+		return strconv.ParseInt(val, 10, 64)
+	case "double":
+		return strconv.ParseFloat(val, 64)
+	case "decimal":
+		return primitive.ParseDecimal128(val)
+	case "date":
+		tm, err := time.Parse(time.RFC3339, val)
+		return primitive.NewDateTimeFromTime(tm), err
+	}
+	return nil, fmt.Errorf("type %v cannot be used with the range algorithm", t)
+}
+
+// parseUUID parses a UUID string into a binary of subtype 4.
+func parseUUID(s string) (primitive.Binary, error) {
+	b, err := hex.DecodeString(strings.Replace(s, "-", "", -1))
+	if err != nil || len(b) != 16 {
+		return primitive.Binary{}, errors.New("expected a UUID")
+	}
+	return primitive.Binary{Subtype: 4, Data: b}, nil
+}
@@ -0,0 +1,133 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package encryptschema
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+type address struct {
+	Street string `bson:"street" encrypt:"random,keyAltName=/keyName"`
+	City   string `bson:"city"`
+}
+
+type patient struct {
+	Name    string    `bson:"name"`
+	SSN     string    `bson:"ssn" encrypt:"deterministic,keyAltName=patients"`
+	Address address   `bson:"address"`
+	Visits  []string  `bson:"visits" encrypt:"random,keyId=6b2a7c1e-7d1c-4b6e-9d1c-1f2e3d4c5b6a"`
+	Updated time.Time `bson:"updated"`
+}
+
+type account struct {
+	Number  string `bson:"number" encrypt:"indexed,keyAltName=accounts,contention=4"`
+	Balance int32  `bson:"balance" encrypt:"range,keyAltName=accounts,min=0,max=1000,sparsity=1,trimFactor=2"`
+	Notes   string `bson:"notes" encrypt:"unindexed"`
+}
+
+func TestGenerator(t *testing.T) {
+	keyID := primitive.Binary{Subtype: 4, Data: []byte("0123456789abcdef")}
+	resolver := func(keyAltName string) (primitive.Binary, error) {
+		if keyAltName == "missing" {
+			return primitive.Binary{}, errors.New("not found")
+		}
+		return keyID, nil
+	}
+
+	t.Run("schema", func(t *testing.T) {
+		g := &Generator{KeyResolver: resolver}
+		schema, err := g.Schema(&patient{})
+		assert.Nil(t, err, "Schema error: %v", err)
+
+		uuid := primitive.Binary{Subtype: 4, Data: []byte{0x6b, 0x2a, 0x7c, 0x1e, 0x7d, 0x1c, 0x4b, 0x6e, 0x9d, 0x1c,
+			0x1f, 0x2e, 0x3d, 0x4c, 0x5b, 0x6a}}
+		expected := bson.M{
+			"bsonType": "object",
+			"properties": bson.M{
+				"ssn": bson.M{"encrypt": bson.M{
+					"bsonType": "string", "algorithm": DeterministicAlgorithm, "keyId": bson.A{keyID},
+				}},
+				"address": bson.M{
+					"bsonType": "object",
+					"properties": bson.M{
+						"street": bson.M{"encrypt": bson.M{
+							"bsonType": "string", "algorithm": RandomAlgorithm, "keyId": "/keyName",
+						}},
+					},
+				},
+				"visits": bson.M{"encrypt": bson.M{
+					"bsonType": "array", "algorithm": RandomAlgorithm, "keyId": bson.A{uuid},
+				}},
+			},
+		}
+		assert.Equal(t, expected, schema, "expected schema %v, got %v", expected, schema)
+	})
+	t.Run("encryptedFields", func(t *testing.T) {
+		g := &Generator{KeyResolver: resolver, AllowMissingKeys: true}
+		fields, err := g.EncryptedFields(account{})
+		assert.Nil(t, err, "EncryptedFields error: %v", err)
+
+		expected := bson.M{"fields": bson.A{
+			bson.M{"path": "number", "bsonType": "string", "keyId": keyID,
+				"queries": bson.M{"queryType": "equality", "contention": int64(4)}},
+			bson.M{"path": "balance", "bsonType": "int", "keyId": keyID,
+				"queries": bson.M{"queryType": "range", "min": int32(0), "max": int32(1000), "sparsity": int64(1),
+					"trimFactor": int32(2)}},
+			bson.M{"path": "notes", "bsonType": "string", "keyId": nil},
+		}}
+		assert.Equal(t, expected, fields, "expected encryptedFields %v, got %v", expected, fields)
+	})
+	t.Run("schema map", func(t *testing.T) {
+		g := &Generator{KeyResolver: resolver}
+		schemaMap, err := g.SchemaMap(map[string]interface{}{"db.patients": patient{}})
+		assert.Nil(t, err, "SchemaMap error: %v", err)
+		assert.NotNil(t, schemaMap["db.patients"], "expected schema for db.patients")
+	})
+	t.Run("errors", func(t *testing.T) {
+		testCases := []struct {
+			name  string
+			model interface{}
+			qe    bool
+			g     *Generator
+		}{
+			{"not a struct", 1, false, &Generator{}},
+			{"no resolver", patient{}, false, &Generator{}},
+			{"unresolved key", struct {
+				A string `encrypt:"deterministic,keyAltName=missing"`
+			}{}, false, &Generator{KeyResolver: resolver}},
+			{"unknown algorithm", struct {
+				A string `encrypt:"fast,keyAltName=a"`
+			}{}, false, &Generator{KeyResolver: resolver}},
+			{"deterministic double", struct {
+				A float64 `encrypt:"deterministic,keyAltName=a"`
+			}{}, false, &Generator{KeyResolver: resolver}},
+			{"queryable encryption in schema", account{}, false, &Generator{KeyResolver: resolver}},
+			{"client-side encryption in encryptedFields", patient{}, true, &Generator{KeyResolver: resolver}},
+			{"missing key", account{}, true, &Generator{KeyResolver: resolver}},
+			{"invalid bound", struct {
+				A int32 `encrypt:"range,keyAltName=a,min=x"`
+			}{}, true, &Generator{KeyResolver: resolver}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				var err error
+				if tc.qe {
+					_, err = tc.g.EncryptedFields(tc.model)
+				} else {
+					_, err = tc.g.Schema(tc.model)
+				}
+				assert.NotNil(t, err, "expected error")
+			})
+		}
+	})
+}
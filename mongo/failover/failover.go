@@ -0,0 +1,300 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package failover provides a client that sends operations to one of several MongoDB clusters, such as a primary
+// cluster and a disaster recovery cluster, and switches to another cluster when the one in use stops responding.
+//
+// The clusters are listed in order of preference. A Client starts by sending operations to the first cluster and
+// periodically runs a ping command with a primary read preference against every cluster. When the cluster in use fails
+// the configured number of consecutive health checks, the Client switches to the most preferred healthy cluster and
+// reports the switch to the configured event.FailoverMonitor:
+//
+//	fc, err := failover.NewClient([]failover.Cluster{
+//		{Name: "primary", Options: options.Client().ApplyURI("mongodb://primary.example.com")},
+//		{Name: "dr", Options: options.Client().ApplyURI("mongodb://dr.example.com")},
+//	}, options.Failover().SetFailBack(true))
+//	if err != nil { return err }
+//	if err = fc.Connect(ctx); err != nil { return err }
+//	defer fc.Disconnect(ctx)
+//
+//	coll := fc.Database("app").Collection("orders")
+//
+// A Database or Collection obtained from a Client is bound to the cluster that was active when it was obtained, so
+// applications should obtain them per operation or per request rather than caching them.
+//
+// Switching clusters does not replicate data between them. Writes that were acknowledged by a cluster before it failed
+// are only visible on another cluster if the clusters are kept in sync by other means.
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+	defaultFailureThreshold    = 3
+)
+
+// Reasons reported in event.FailoverEvent.
+const (
+	ReasonFailover = "failover"
+	ReasonFailBack = "failback"
+	ReasonManual   = "manual"
+)
+
+// ErrNoClusters is returned by NewClient if no clusters are given.
+var ErrNoClusters = errors.New("at least one cluster must be specified")
+
+// Cluster describes one of the clusters a Client can send operations to.
+type Cluster struct {
+	// Name identifies the cluster in failover events. Names must be unique.
+	Name string
+
+	// Options are used to create the mongo.Client for the cluster.
+	Options *options.ClientOptions
+}
+
+type cluster struct {
+	name     string
+	client   *mongo.Client
+	failures int
+	lastErr  error
+}
+
+// Client sends operations to the most preferred healthy cluster among several clusters. A Client is safe for
+// concurrent use by multiple goroutines.
+type Client struct {
+	clusters  []*cluster
+	interval  time.Duration
+	timeout   time.Duration
+	threshold int
+	failBack  bool
+	monitor   *event.FailoverMonitor
+
+	mu     sync.RWMutex // protects active and the health of each cluster
+	active int
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+
+	// ping runs a health check against a cluster. It is a field so that tests can simulate failures.
+	ping func(context.Context, *mongo.Client) error
+}
+
+// NewClient creates a new Client for the given clusters, listed in order of preference. The Client must be connected
+// with Connect before it is used.
+func NewClient(clusters []Cluster, opts ...*options.FailoverOptions) (*Client, error) {
+	if len(clusters) == 0 {
+		return nil, ErrNoClusters
+	}
+
+	fo := options.MergeFailoverOptions(opts...)
+	c := &Client{
+		interval:  defaultHealthCheckInterval,
+		timeout:   defaultHealthCheckTimeout,
+		threshold: defaultFailureThreshold,
+		monitor:   fo.Monitor,
+		done:      make(chan struct{}),
+		ping: func(ctx context.Context, client *mongo.Client) error {
+			return client.Ping(ctx, readpref.Primary())
+		},
+	}
+	if fo.HealthCheckInterval != nil && *fo.HealthCheckInterval > 0 {
+		c.interval = *fo.HealthCheckInterval
+	}
+	if fo.HealthCheckTimeout != nil && *fo.HealthCheckTimeout > 0 {
+		c.timeout = *fo.HealthCheckTimeout
+	}
+	if fo.FailureThreshold != nil && *fo.FailureThreshold > 0 {
+		c.threshold = *fo.FailureThreshold
+	}
+	if fo.FailBack != nil {
+		c.failBack = *fo.FailBack
+	}
+
+	names := make(map[string]bool, len(clusters))
+	for _, cl := range clusters {
+		if names[cl.Name] {
+			return nil, fmt.Errorf("duplicate cluster name %q", cl.Name)
+		}
+		names[cl.Name] = true
+
+		client, err := mongo.NewClient(cl.Options)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %q: %v", cl.Name, err)
+		}
+		c.clusters = append(c.clusters, &cluster{name: cl.Name, client: client})
+	}
+
+	return c, nil
+}
+
+// Connect connects the client of every cluster and starts health checking them. If a client cannot be connected, the
+// clients that were already connected are disconnected before the error is returned.
+func (c *Client) Connect(ctx context.Context) error {
+	for i, cl := range c.clusters {
+		if err := cl.client.Connect(ctx); err != nil {
+			for _, connected := range c.clusters[:i] {
+				_ = connected.client.Disconnect(ctx)
+			}
+			return fmt.Errorf("cluster %q: %v", cl.name, err)
+		}
+	}
+
+	c.wg.Add(1)
+	go c.monitorClusters()
+	return nil
+}
+
+// Disconnect stops health checking and disconnects the client of every cluster. It returns the first error returned
+// by a cluster's Disconnect.
+func (c *Client) Disconnect(ctx context.Context) error {
+	c.closeOnce.Do(func() { close(c.done) })
+	c.wg.Wait()
+
+	var firstErr error
+	for _, cl := range c.clusters {
+		if err := cl.client.Disconnect(ctx); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("cluster %q: %v", cl.name, err)
+		}
+	}
+	return firstErr
+}
+
+// Active returns the client of the cluster that operations are currently sent to.
+func (c *Client) Active() *mongo.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clusters[c.active].client
+}
+
+// ActiveCluster returns the name of the cluster that operations are currently sent to.
+func (c *Client) ActiveCluster() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clusters[c.active].name
+}
+
+// Database returns a handle for a database on the cluster that operations are currently sent to.
+func (c *Client) Database(name string, opts ...*options.DatabaseOptions) *mongo.Database {
+	return c.Active().Database(name, opts...)
+}
+
+// Failover sends subsequent operations to the named cluster regardless of its health.
+func (c *Client) Failover(name string) error {
+	for i, cl := range c.clusters {
+		if cl.name == name {
+			c.mu.Lock()
+			evt := c.switchTo(i, ReasonManual, nil)
+			c.mu.Unlock()
+			c.publish(evt)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown cluster %q", name)
+}
+
+func (c *Client) monitorClusters() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.check()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// check runs a health check against every cluster and switches clusters if the active cluster is unhealthy or, if
+// fail back is enabled, a more preferred cluster is healthy.
+func (c *Client) check() {
+	errs := make([]error, len(c.clusters))
+	var wg sync.WaitGroup
+	for i, cl := range c.clusters {
+		wg.Add(1)
+		go func(i int, client *mongo.Client) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+			defer cancel()
+			errs[i] = c.ping(ctx, client)
+		}(i, cl.client)
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	evt := c.evaluate(errs)
+	c.mu.Unlock()
+	c.publish(evt)
+}
+
+// evaluate records the results of a round of health checks and switches clusters if needed. c.mu must be held.
+func (c *Client) evaluate(errs []error) *event.FailoverEvent {
+	for i, cl := range c.clusters {
+		if errs[i] == nil {
+			cl.failures = 0
+			continue
+		}
+		cl.failures++
+		cl.lastErr = errs[i]
+	}
+
+	preferred := -1
+	for i, cl := range c.clusters {
+		if cl.failures < c.threshold {
+			preferred = i
+			break
+		}
+	}
+	if preferred == -1 || preferred == c.active {
+		return nil
+	}
+
+	switch active := c.clusters[c.active]; {
+	case active.failures >= c.threshold:
+		return c.switchTo(preferred, ReasonFailover, active.lastErr)
+	case c.failBack && preferred < c.active:
+		return c.switchTo(preferred, ReasonFailBack, nil)
+	}
+	return nil
+}
+
+// switchTo makes the cluster at index i the active cluster and returns the event describing the switch, or nil if i
+// is already the active cluster. c.mu must be held.
+func (c *Client) switchTo(i int, reason string, err error) *event.FailoverEvent {
+	if i == c.active {
+		return nil
+	}
+
+	previous := c.clusters[c.active].name
+	c.active = i
+	return &event.FailoverEvent{
+		Previous: previous,
+		Current:  c.clusters[i].name,
+		Reason:   reason,
+		Error:    err,
+	}
+}
+
+func (c *Client) publish(evt *event.FailoverEvent) {
+	if evt != nil && c.monitor != nil && c.monitor.Failover != nil {
+		c.monitor.Failover(evt)
+	}
+}
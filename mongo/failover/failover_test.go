@@ -0,0 +1,119 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failover
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func newTestClient(t *testing.T, opts *options.FailoverOptions, down map[string]bool) (*Client, *[]event.FailoverEvent) {
+	t.Helper()
+
+	var events []event.FailoverEvent
+	opts.SetMonitor(&event.FailoverMonitor{
+		Failover: func(evt *event.FailoverEvent) { events = append(events, *evt) },
+	})
+	c, err := NewClient([]Cluster{
+		{Name: "primary", Options: options.Client().ApplyURI("mongodb://primary.example.com")},
+		{Name: "dr", Options: options.Client().ApplyURI("mongodb://dr.example.com")},
+	}, opts)
+	assert.Nil(t, err, "NewClient error: %v", err)
+
+	clusters := make(map[*mongo.Client]string)
+	for _, cl := range c.clusters {
+		clusters[cl.client] = cl.name
+	}
+	c.ping = func(_ context.Context, client *mongo.Client) error {
+		if down[clusters[client]] {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	return c, &events
+}
+
+func TestClient(t *testing.T) {
+	t.Run("no clusters", func(t *testing.T) {
+		_, err := NewClient(nil)
+		assert.Equal(t, ErrNoClusters, err, "expected error %v, got %v", ErrNoClusters, err)
+	})
+	t.Run("duplicate names", func(t *testing.T) {
+		_, err := NewClient([]Cluster{{Name: "a"}, {Name: "a"}})
+		assert.NotNil(t, err, "expected error for duplicate cluster names, got nil")
+	})
+	t.Run("disconnects connected clusters if connect fails", func(t *testing.T) {
+		c, _ := newTestClient(t, options.Failover(), nil)
+		dr := c.clusters[1].client
+		err := dr.Connect(context.Background())
+		assert.Nil(t, err, "Connect error: %v", err)
+		defer func() { _ = dr.Disconnect(context.Background()) }()
+
+		err = c.Connect(context.Background())
+		assert.NotNil(t, err, "expected error connecting an already connected cluster, got nil")
+		err = c.clusters[0].client.Disconnect(context.Background())
+		assert.Equal(t, mongo.ErrClientDisconnected, err, "expected primary cluster to be disconnected, got %v", err)
+	})
+	t.Run("fails over after threshold", func(t *testing.T) {
+		down := map[string]bool{"primary": true}
+		c, events := newTestClient(t, options.Failover().SetFailureThreshold(2), down)
+
+		c.check()
+		assert.Equal(t, "primary", c.ActiveCluster(), "expected no failover before the threshold is reached")
+
+		c.check()
+		assert.Equal(t, "dr", c.ActiveCluster(), "expected failover once the threshold is reached")
+		assert.Equal(t, 1, len(*events), "expected 1 failover event, got %d", len(*events))
+		evt := (*events)[0]
+		assert.Equal(t, "primary", evt.Previous, "unexpected previous cluster")
+		assert.Equal(t, "dr", evt.Current, "unexpected current cluster")
+		assert.Equal(t, ReasonFailover, evt.Reason, "unexpected reason")
+		assert.NotNil(t, evt.Error, "expected health check error in event")
+
+		down["primary"] = false
+		c.check()
+		assert.Equal(t, "dr", c.ActiveCluster(), "expected no fail back when fail back is disabled")
+	})
+	t.Run("fails back to preferred cluster", func(t *testing.T) {
+		down := map[string]bool{"primary": true}
+		c, events := newTestClient(t, options.Failover().SetFailureThreshold(1).SetFailBack(true), down)
+
+		c.check()
+		assert.Equal(t, "dr", c.ActiveCluster(), "expected failover")
+
+		down["primary"] = false
+		c.check()
+		assert.Equal(t, "primary", c.ActiveCluster(), "expected fail back")
+		assert.Equal(t, 2, len(*events), "expected 2 failover events, got %d", len(*events))
+		assert.Equal(t, ReasonFailBack, (*events)[1].Reason, "unexpected reason")
+	})
+	t.Run("stays on active cluster if all clusters are down", func(t *testing.T) {
+		down := map[string]bool{"primary": true, "dr": true}
+		c, events := newTestClient(t, options.Failover().SetFailureThreshold(1), down)
+
+		c.check()
+		assert.Equal(t, "primary", c.ActiveCluster(), "expected no failover when no cluster is healthy")
+		assert.Equal(t, 0, len(*events), "expected no failover events, got %d", len(*events))
+	})
+	t.Run("manual failover", func(t *testing.T) {
+		c, events := newTestClient(t, options.Failover(), nil)
+
+		err := c.Failover("dr")
+		assert.Nil(t, err, "Failover error: %v", err)
+		assert.Equal(t, "dr", c.ActiveCluster(), "expected manual failover")
+		assert.Equal(t, ReasonManual, (*events)[0].Reason, "unexpected reason")
+
+		err = c.Failover("unknown")
+		assert.NotNil(t, err, "expected error for unknown cluster, got nil")
+	})
+}
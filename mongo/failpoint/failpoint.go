@@ -0,0 +1,365 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package failpoint provides helpers to configure server fail points from tests. Fail points make a MongoDB server
+// fail or delay commands on purpose so that error handling, retries, and timeouts can be tested. The server must be
+// started with enableTestCommands=1 for fail points to be available.
+//
+// The most common fail point is failCommand, which can be built with FailCommand, BlockConnection, and ErrorLabels:
+//
+//	func TestRetry(t *testing.T) {
+//		client := connect(t)
+//		defer failpoint.SetForTest(t, client, failpoint.FailCommand(failpoint.Times(1), 91, "insert"))()
+//		...
+//	}
+//
+// SetForTest disables the fail point when the test ends. For tests that need more control, a Configurator sets and
+// unsets fail points and keeps track of the ones that are still enabled.
+//
+// Fail points are configured per server. For sharded clusters, a Configurator sets each fail point on every mongos
+// that the client knows about, so that the fail point triggers regardless of the mongos an operation is routed to.
+// This requires the options that were used to create the client, which are used to connect to each mongos directly.
+// See https://github.com/mongodb/specifications/tree/master/source/transactions/tests#server-fail-point for more
+// information about fail points.
+package failpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// FailCommandName is the name of the failCommand fail point.
+const FailCommandName = "failCommand"
+
+// Fail point modes that are not a Mode value.
+const (
+	// AlwaysOn enables a fail point until it is disabled.
+	AlwaysOn = "alwaysOn"
+	// Off disables a fail point.
+	Off = "off"
+)
+
+// FailPoint is a representation of the configureFailPoint command.
+type FailPoint struct {
+	ConfigureFailPoint string `bson:"configureFailPoint"`
+	// Mode should be a string, Mode, or map[string]interface{}
+	Mode interface{} `bson:"mode"`
+	Data Data        `bson:"data"`
+}
+
+// Mode is a representation of the FailPoint.Mode field for fail points that trigger a limited number of times or
+// after skipping a number of matching commands.
+type Mode struct {
+	Times int32 `bson:"times"`
+	Skip  int32 `bson:"skip"`
+}
+
+// Times returns a Mode that enables a fail point for the next n matching commands.
+func Times(n int32) Mode {
+	return Mode{Times: n}
+}
+
+// Skip returns a Mode that enables a fail point after n matching commands have been skipped.
+func Skip(n int32) Mode {
+	return Mode{Skip: n}
+}
+
+// Data is a representation of the FailPoint.Data field.
+type Data struct {
+	FailCommands                  []string           `bson:"failCommands,omitempty"`
+	CloseConnection               bool               `bson:"closeConnection,omitempty"`
+	ErrorCode                     int32              `bson:"errorCode,omitempty"`
+	FailBeforeCommitExceptionCode int32              `bson:"failBeforeCommitExceptionCode,omitempty"`
+	ErrorLabels                   *[]string          `bson:"errorLabels,omitempty"`
+	WriteConcernError             *WriteConcernError `bson:"writeConcernError,omitempty"`
+	BlockConnection               bool               `bson:"blockConnection,omitempty"`
+	BlockTimeMS                   int32              `bson:"blockTimeMS,omitempty"`
+	AppName                       string             `bson:"appName,omitempty"`
+}
+
+// WriteConcernError is a representation of the FailPoint.Data.WriteConcernError field.
+type WriteConcernError struct {
+	Code        int32     `bson:"code"`
+	Name        string    `bson:"codeName"`
+	Errmsg      string    `bson:"errmsg"`
+	ErrorLabels *[]string `bson:"errorLabels,omitempty"`
+}
+
+// FailCommand returns a failCommand fail point that makes the given commands fail with errorCode.
+func FailCommand(mode interface{}, errorCode int32, commands ...string) FailPoint {
+	return FailPoint{
+		ConfigureFailPoint: FailCommandName,
+		Mode:               mode,
+		Data: Data{
+			FailCommands: commands,
+			ErrorCode:    errorCode,
+		},
+	}
+}
+
+// BlockConnection returns a failCommand fail point that makes the server wait for blockTime before it executes the
+// given commands. The precision of blockTime is one millisecond.
+func BlockConnection(mode interface{}, blockTime time.Duration, commands ...string) FailPoint {
+	return FailPoint{
+		ConfigureFailPoint: FailCommandName,
+		Mode:               mode,
+		Data: Data{
+			FailCommands:    commands,
+			BlockConnection: true,
+			BlockTimeMS:     int32(blockTime / time.Millisecond),
+		},
+	}
+}
+
+// ErrorLabels returns a failCommand fail point that makes the given commands fail with errorCode and the given error
+// labels instead of the labels the server would attach to the error. An empty labels slice removes all labels.
+func ErrorLabels(mode interface{}, errorCode int32, labels []string, commands ...string) FailPoint {
+	fp := FailCommand(mode, errorCode, commands...)
+	if labels == nil {
+		labels = []string{}
+	}
+	fp.Data.ErrorLabels = &labels
+	return fp
+}
+
+// Configurator sets and unsets fail points using a client. It keeps track of the fail points that it has set so that
+// they can all be disabled with Clear. A Configurator is safe for concurrent use.
+type Configurator struct {
+	client     *mongo.Client
+	clientOpts []*options.ClientOptions
+
+	mu      sync.Mutex
+	mongos  map[string]*mongo.Client // clients connected directly to each mongos of a sharded cluster
+	enabled []string
+}
+
+// NewConfigurator creates a Configurator that uses client. The options that were used to create client are only
+// needed if client is connected to a sharded cluster with more than one mongos. They are used to connect to each mongos
+// directly, and the hosts and direct connection settings are overridden.
+func NewConfigurator(client *mongo.Client, opts ...*options.ClientOptions) *Configurator {
+	return &Configurator{
+		client:     client,
+		clientOpts: opts,
+		mongos:     make(map[string]*mongo.Client),
+	}
+}
+
+// Set enables fp on every server that operations using the client can be sent to. Numeric values in a Mode given as
+// a map are converted to int32 values as required by the server. The commands that configure the fail point are
+// visible to the client's command monitor.
+func (c *Configurator) Set(ctx context.Context, fp FailPoint) error {
+	mode, err := normalizeMode(fp.Mode)
+	if err != nil {
+		return err
+	}
+	fp.Mode = mode
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	targets, err := c.targets(ctx)
+	if err != nil {
+		return err
+	}
+	for _, target := range targets {
+		if err := target.Database("admin").RunCommand(ctx, fp).Err(); err != nil {
+			return fmt.Errorf("error setting fail point %s: %v", fp.ConfigureFailPoint, err)
+		}
+	}
+	c.enabled = append(c.enabled, fp.ConfigureFailPoint)
+	return nil
+}
+
+// Unset disables the fail point with the given name on every server that operations using the client can be sent to.
+func (c *Configurator) Unset(ctx context.Context, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.unset(ctx, name)
+}
+
+// Clear disables every fail point that was set with c and disconnects the clients that c created. The first error is
+// returned, but c attempts to disable all of the fail points regardless.
+func (c *Configurator) Clear(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for _, name := range c.enabled {
+		if err := c.unset(ctx, name); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	c.enabled = c.enabled[:0]
+
+	for addr, client := range c.mongos {
+		_ = client.Disconnect(ctx)
+		delete(c.mongos, addr)
+	}
+	return firstErr
+}
+
+func (c *Configurator) unset(ctx context.Context, name string) error {
+	targets, err := c.targets(ctx)
+	if err != nil {
+		return err
+	}
+	cmd := bson.D{{"configureFailPoint", name}, {"mode", Off}}
+	for _, target := range targets {
+		if err := target.Database("admin").RunCommand(ctx, cmd).Err(); err != nil {
+			return fmt.Errorf("error clearing fail point %s: %v", name, err)
+		}
+	}
+
+	for i, enabled := range c.enabled {
+		if enabled == name {
+			c.enabled = append(c.enabled[:i], c.enabled[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// targets returns the clients that fail points must be configured with. This is the client itself unless it is
+// connected to a sharded cluster with more than one mongos, in which case there is a client for each mongos.
+func (c *Configurator) targets(ctx context.Context) ([]*mongo.Client, error) {
+	// make sure that the type of the deployment has been discovered
+	if err := c.client.Ping(ctx, readpref.Primary()); err != nil {
+		return nil, err
+	}
+
+	desc := c.client.TopologyDescription()
+	if desc.Kind != "Sharded" {
+		return []*mongo.Client{c.client}, nil
+	}
+	var addrs []string
+	for _, server := range desc.Servers {
+		if server.Kind == "Mongos" {
+			addrs = append(addrs, server.Address)
+		}
+	}
+	if len(addrs) <= 1 {
+		return []*mongo.Client{c.client}, nil
+	}
+	if len(c.clientOpts) == 0 {
+		return nil, errors.New("the client options are required to set fail points on a sharded cluster with " +
+			"multiple mongoses")
+	}
+
+	targets := make([]*mongo.Client, 0, len(addrs))
+	for _, addr := range addrs {
+		client, ok := c.mongos[addr]
+		if !ok {
+			opts := options.MergeClientOptions(c.clientOpts...).SetHosts([]string{addr}).SetDirect(true)
+			var err error
+			if client, err = mongo.Connect(ctx, opts); err != nil {
+				return nil, fmt.Errorf("error connecting to mongos %s: %v", addr, err)
+			}
+			c.mongos[addr] = client
+		}
+		targets = append(targets, client)
+	}
+	return targets, nil
+}
+
+// normalizeMode converts numeric values in a mode given as a map to int32 values, which the server requires.
+func normalizeMode(mode interface{}) (interface{}, error) {
+	var m map[string]interface{}
+	switch val := mode.(type) {
+	case map[string]interface{}:
+		m = val
+	case bson.M:
+		m = val
+	default:
+		return mode, nil
+	}
+
+	normalized := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		if key != "times" && key != "skip" {
+			normalized[key] = value
+			continue
+		}
+		n, err := toInt32(value)
+		if err != nil {
+			return nil, fmt.Errorf("error converting %s to int32: %v", key, err)
+		}
+		normalized[key] = n
+	}
+	return normalized, nil
+}
+
+func toInt32(i interface{}) (int32, error) {
+	switch conv := i.(type) {
+	case int:
+		return int32(conv), nil
+	case int32:
+		return conv, nil
+	case int64:
+		return int32(conv), nil
+	case float64:
+		return int32(conv), nil
+	}
+
+	return 0, fmt.Errorf("type %T cannot be converted to int32", i)
+}
+
+// Set enables fp using client. See Configurator.Set.
+func Set(ctx context.Context, client *mongo.Client, fp FailPoint) error {
+	return NewConfigurator(client).Set(ctx, fp)
+}
+
+// Unset disables the fail point with the given name using client. See Configurator.Unset.
+func Unset(ctx context.Context, client *mongo.Client, name string) error {
+	return NewConfigurator(client).Unset(ctx, name)
+}
+
+// TestingT is the subset of testing.TB used by SetForTest.
+type TestingT interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
+
+// cleaner is implemented by testing.TB in Go 1.14 and later.
+type cleaner interface {
+	Cleanup(func())
+}
+
+// SetForTest enables fp using client and fails the test if it cannot be set. The options that were used to create the
+// client are required for sharded clusters with more than one mongos (see NewConfigurator).
+//
+// If t supports registering cleanup functions, as testing.T does in Go 1.14 and later, the fail point is disabled
+// automatically when the test ends. The returned function also disables the fail point and can be deferred on earlier
+// Go versions. Calling it more than once has no effect.
+func SetForTest(t TestingT, client *mongo.Client, fp FailPoint, opts ...*options.ClientOptions) func() {
+	t.Helper()
+
+	c := NewConfigurator(client, opts...)
+	if err := c.Set(context.Background(), fp); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var once sync.Once
+	disable := func() {
+		once.Do(func() {
+			if err := c.Clear(context.Background()); err != nil {
+				t.Fatalf("%v", err)
+			}
+		})
+	}
+	if cl, ok := t.(cleaner); ok {
+		cl.Cleanup(disable)
+	}
+	return disable
+}
@@ -0,0 +1,112 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/mongofake"
+)
+
+type recordingT struct {
+	fatals []string
+}
+
+func (*recordingT) Helper() {}
+
+func (r *recordingT) Fatalf(format string, args ...interface{}) {
+	r.fatals = append(r.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestFailPoint(t *testing.T) {
+	t.Run("builders", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			fp       FailPoint
+			expected bson.D
+		}{
+			{
+				"fail command",
+				FailCommand(Times(2), 91, "insert", "find"),
+				bson.D{
+					{"configureFailPoint", "failCommand"},
+					{"mode", bson.D{{"times", int32(2)}, {"skip", int32(0)}}},
+					{"data", bson.D{{"failCommands", bson.A{"insert", "find"}}, {"errorCode", int32(91)}}},
+				},
+			},
+			{
+				"block connection",
+				BlockConnection(AlwaysOn, 1500*time.Millisecond, "ping"),
+				bson.D{
+					{"configureFailPoint", "failCommand"},
+					{"mode", "alwaysOn"},
+					{"data", bson.D{
+						{"failCommands", bson.A{"ping"}},
+						{"blockConnection", true},
+						{"blockTimeMS", int32(1500)},
+					}},
+				},
+			},
+			{
+				"error labels",
+				ErrorLabels(Skip(1), 112, nil, "commitTransaction"),
+				bson.D{
+					{"configureFailPoint", "failCommand"},
+					{"mode", bson.D{{"times", int32(0)}, {"skip", int32(1)}}},
+					{"data", bson.D{
+						{"failCommands", bson.A{"commitTransaction"}},
+						{"errorCode", int32(112)},
+						{"errorLabels", bson.A{}},
+					}},
+				},
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				got, err := bson.Marshal(tc.fp)
+				assert.Nil(t, err, "Marshal error: %v", err)
+				expected, err := bson.Marshal(tc.expected)
+				assert.Nil(t, err, "Marshal error: %v", err)
+				assert.Equal(t, bson.Raw(expected).String(), bson.Raw(got).String(), "unexpected fail point document")
+			})
+		}
+	})
+	t.Run("normalize mode", func(t *testing.T) {
+		mode, err := normalizeMode(map[string]interface{}{"times": 1.0, "skip": int64(2)})
+		assert.Nil(t, err, "normalizeMode error: %v", err)
+		expected := map[string]interface{}{"times": int32(1), "skip": int32(2)}
+		assert.Equal(t, expected, mode, "expected mode %v, got %v", expected, mode)
+
+		_, err = normalizeMode(bson.M{"times": "1"})
+		assert.NotNil(t, err, "expected error for a non-numeric times value, got nil")
+
+		mode, err = normalizeMode(AlwaysOn)
+		assert.Nil(t, err, "normalizeMode error: %v", err)
+		assert.Equal(t, AlwaysOn, mode, "expected mode %v, got %v", AlwaysOn, mode)
+	})
+	t.Run("set errors", func(t *testing.T) {
+		// the fake server does not support configureFailPoint, so every attempt fails
+		client, err := mongo.Connect(context.Background(), mongofake.NewServer().ClientOptions())
+		assert.Nil(t, err, "Connect error: %v", err)
+		defer func() { _ = client.Disconnect(context.Background()) }()
+
+		c := NewConfigurator(client)
+		err = c.Set(context.Background(), FailCommand(AlwaysOn, 1, "find"))
+		assert.NotNil(t, err, "expected Set error, got nil")
+		assert.Equal(t, 0, len(c.enabled), "expected no fail points to be tracked, got %v", c.enabled)
+
+		rt := &recordingT{}
+		SetForTest(rt, client, FailCommand(AlwaysOn, 1, "find"))
+		assert.Equal(t, 1, len(rt.fatals), "expected SetForTest to fail the test, got %v", rt.fatals)
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"context"
 
 	"io"
+	"io/ioutil"
 
 	"errors"
 
@@ -36,6 +37,16 @@ const DefaultChunkSize int32 = 255 * 1024 // 255 KiB
 // ErrFileNotFound occurs if a user asks to download a file with a file ID that isn't found in the files collection.
 var ErrFileNotFound = errors.New("file with given parameters not found")
 
+// ErrInvalidRange occurs if a user asks to download a range of bytes that is not within a file.
+var ErrInvalidRange = errors.New("range is not within the file")
+
+// ErrUploadFinished occurs if a user asks to resume an upload for a file that is already in the files collection.
+var ErrUploadFinished = errors.New("upload has already finished")
+
+// ErrInvalidUploadProgress occurs if a user asks to resume an upload with progress that does not match the chunks in
+// the chunks collection.
+var ErrInvalidUploadProgress = errors.New("upload progress does not match the chunks in the bucket")
+
 // Bucket represents a GridFS bucket.
 type Bucket struct {
 	db         *mongo.Database
@@ -48,6 +59,11 @@ type Bucket struct {
 	rc        *readconcern.ReadConcern
 	rp        *readpref.ReadPref
 
+	uploadConcurrency   int
+	downloadConcurrency int
+	checksumAlgorithm   string
+	chunkCompression    string
+
 	firstWriteDone bool
 	readBuf        []byte
 	writeBuf       []byte
@@ -58,8 +74,11 @@ type Bucket struct {
 
 // Upload contains options to upload a file to a bucket.
 type Upload struct {
-	chunkSize int32
-	metadata  bsonx.Doc
+	chunkSize         int32
+	metadata          bsonx.Doc
+	concurrency       int
+	checksumAlgorithm string
+	compression       string
 }
 
 // NewBucket creates a GridFS bucket.
@@ -89,6 +108,18 @@ func NewBucket(db *mongo.Database, opts ...*options.BucketOptions) (*Bucket, err
 	if bo.ReadPreference != nil {
 		b.rp = bo.ReadPreference
 	}
+	if bo.UploadConcurrency != nil {
+		b.uploadConcurrency = *bo.UploadConcurrency
+	}
+	if bo.DownloadConcurrency != nil {
+		b.downloadConcurrency = *bo.DownloadConcurrency
+	}
+	if bo.ChecksumAlgorithm != nil {
+		b.checksumAlgorithm = *bo.ChecksumAlgorithm
+	}
+	if bo.ChunkCompression != nil {
+		b.chunkCompression = *bo.ChunkCompression
+	}
 
 	var collOpts = options.Collection().SetWriteConcern(b.wc).SetReadConcern(b.rc).SetReadPreference(b.rp)
 
@@ -136,6 +167,110 @@ func (b *Bucket) OpenUploadStreamWithID(fileID interface{}, filename string, opt
 	return newUploadStream(upload, fileID, filename, b.chunksColl, b.filesColl), nil
 }
 
+// ResumeUploadStream creates an upload stream that resumes an interrupted upload from the given progress, as returned
+// by UploadStream.Progress. The chunks that were written before the upload was interrupted are validated, and any
+// chunks written after the progress was recorded are deleted. If the upload computes a checksum, the chunks that were
+// written are read to compute the checksum again. The caller must then write the file starting at offset
+// progress.Length. The chunk size and compression of the progress are used regardless of the options in opts.
+//
+// ErrUploadFinished is returned if the upload has already been closed, and ErrInvalidUploadProgress is returned if
+// the chunks in the bucket do not match the progress.
+func (b *Bucket) ResumeUploadStream(progress UploadProgress, opts ...*options.UploadOptions) (*UploadStream, error) {
+	if progress.ChunkSize <= 0 || progress.ChunksCommitted < 0 ||
+		progress.Length != int64(progress.ChunksCommitted)*int64(progress.ChunkSize) {
+		return nil, ErrInvalidUploadProgress
+	}
+	if progress.Compression != "" {
+		if err := checkCompression(progress.Compression); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := deadlineContext(b.writeDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	if err := b.checkFirstWrite(ctx); err != nil {
+		return nil, err
+	}
+
+	id, err := convertFileID(progress.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := b.filesColl.CountDocuments(ctx, bsonx.Doc{{"_id", id}})
+	if err != nil {
+		return nil, err
+	}
+	if files != 0 {
+		return nil, ErrUploadFinished
+	}
+
+	committed := bsonx.Document(bsonx.Doc{{"$lt", bsonx.Int32(progress.ChunksCommitted)}})
+	chunks, err := b.chunksColl.CountDocuments(ctx, bsonx.Doc{{"files_id", id}, {"n", committed}})
+	if err != nil {
+		return nil, err
+	}
+	if chunks != int64(progress.ChunksCommitted) {
+		return nil, ErrInvalidUploadProgress
+	}
+
+	if progress.ChunksCommitted > 0 {
+		// all committed chunks are full, so the last one must have the chunk size of the progress
+		last, err := b.chunksColl.FindOne(ctx,
+			bsonx.Doc{{"files_id", id}, {"n", bsonx.Int32(progress.ChunksCommitted - 1)}}).DecodeBytes()
+		if err != nil {
+			return nil, err
+		}
+		data, err := uploadedChunkData(last, progress.Compression)
+		if err != nil {
+			return nil, err
+		}
+		if int32(len(data)) != progress.ChunkSize {
+			return nil, ErrInvalidUploadProgress
+		}
+	}
+
+	uncommitted := bsonx.Document(bsonx.Doc{{"$gte", bsonx.Int32(progress.ChunksCommitted)}})
+	if _, err = b.chunksColl.DeleteMany(ctx, bsonx.Doc{{"files_id", id}, {"n", uncommitted}}); err != nil {
+		return nil, err
+	}
+
+	upload, err := b.parseUploadOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	upload.chunkSize = progress.ChunkSize
+	upload.compression = progress.Compression
+
+	us := newUploadStream(upload, progress.FileID, progress.Filename, b.chunksColl, b.filesColl)
+	us.chunkIndex = int(progress.ChunksCommitted)
+	us.fileLen = progress.Length
+	if us.checksum != nil && progress.ChunksCommitted > 0 {
+		// the checksum of the committed chunks has to be computed again
+		cursor, err := findChunks(ctx, b.chunksColl, progress.FileID, 0, progress.ChunksCommitted-1)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = cursor.Close(ctx)
+		}()
+		for cursor.Next(ctx) {
+			data, err := uploadedChunkData(cursor.Current, progress.Compression)
+			if err != nil {
+				return nil, err
+			}
+			_, _ = us.checksum.Write(data)
+		}
+		if err = cursor.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return us, nil
+}
+
 // UploadFromStream creates a fileID and uploads a file given a source stream.
 //
 // If this upload requires a custom write deadline to be set on the bucket, it cannot be done concurrently with other
@@ -195,6 +330,45 @@ func (b *Bucket) OpenDownloadStream(fileID interface{}) (*DownloadStream, error)
 	})
 }
 
+// DownloadRange opens a download stream that returns length bytes of the file with the given file ID, starting at
+// offset. Only the chunks that overlap the range are fetched from the server, so it can be used to serve HTTP range
+// requests. ErrInvalidRange is returned if the range is not within the file.
+//
+// The returned stream returns io.EOF after length bytes. Its Seek and ReadAt methods still use positions in the whole
+// file. The checksum of the file is only verified if the range covers the whole file.
+func (b *Bucket) DownloadRange(fileID interface{}, offset, length int64) (*DownloadStream, error) {
+	id, err := convertFileID(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := deadlineContext(b.readDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	cursor, err := b.findFile(ctx, bsonx.Doc{{"_id", id}})
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := b.newFileDownloadStream(cursor.Current)
+	if err != nil {
+		return nil, err
+	}
+	if offset < 0 || length < 0 || offset+length > ds.fileLen {
+		return nil, ErrInvalidRange
+	}
+
+	ds.end = offset + length
+	if offset != 0 || ds.end != ds.fileLen {
+		ds.checksum = nil
+	}
+	ds.seek(offset)
+	ds.done = ds.offset >= ds.end
+	return ds, nil
+}
+
 // DownloadToStream downloads the file with the specified fileID and writes it to the provided io.Writer.
 // Returns the number of bytes written to the steam and an error, or nil if there was no error.
 //
@@ -270,6 +444,36 @@ func (b *Bucket) Delete(fileID interface{}) error {
 	return b.deleteChunks(ctx, fileID)
 }
 
+// VerifyFile downloads the file with the given file ID and checks that its checksum matches the checksum stored in
+// the files collection. It returns ErrChecksumMismatch if the checksums do not match, ErrNoChecksum if the file was not
+// uploaded with a checksum, and an error if the checksum algorithm of the file is not registered.
+//
+// If this download requires a custom read deadline to be set on the bucket, it cannot be done concurrently with other
+// read operations operations on this bucket that also require a custom deadline.
+func (b *Bucket) VerifyFile(fileID interface{}) error {
+	ds, err := b.OpenDownloadStream(fileID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = ds.Close()
+	}()
+
+	if ds.checksumAlgorithm == "" {
+		return ErrNoChecksum
+	}
+	if ds.checksum == nil {
+		_, err = newChecksum(ds.checksumAlgorithm)
+		return err
+	}
+
+	if err = ds.SetReadDeadline(b.readDeadline); err != nil {
+		return err
+	}
+	_, err = io.Copy(ioutil.Discard, ds)
+	return err
+}
+
 // Find returns the files collection documents that match the given filter.
 //
 // If this download requires a custom read deadline to be set on the bucket, it cannot be done concurrently with other
@@ -336,6 +540,105 @@ func (b *Bucket) Rename(fileID interface{}, newFilename string) error {
 	return nil
 }
 
+// SetMetadata replaces the metadata document of the stored file with the specified file ID. If metadata is nil, the
+// metadata field is removed from the file.
+//
+// If this operation requires a custom write deadline to be set on the bucket, it cannot be done concurrently with other
+// write operations operations on this bucket that also require a custom deadline.
+func (b *Bucket) SetMetadata(fileID interface{}, metadata interface{}) error {
+	update := bsonx.Doc{{"$unset", bsonx.Document(bsonx.Doc{{"metadata", bsonx.String("")}})}}
+	if metadata != nil {
+		doc, err := marshalDoc(metadata)
+		if err != nil {
+			return err
+		}
+		update = bsonx.Doc{{"$set", bsonx.Document(bsonx.Doc{{"metadata", bsonx.Document(doc)}})}}
+	}
+
+	return b.updateFile(fileID, update)
+}
+
+// PatchMetadata updates fields of the metadata document of the stored file with the specified file ID in a single
+// atomic update. Each field of patch is set in the metadata document, and fields of patch with a null value are
+// removed from it. Fields of the metadata document that are not in patch are not changed.
+//
+// If this operation requires a custom write deadline to be set on the bucket, it cannot be done concurrently with other
+// write operations operations on this bucket that also require a custom deadline.
+func (b *Bucket) PatchMetadata(fileID interface{}, patch interface{}) error {
+	doc, err := marshalDoc(patch)
+	if err != nil {
+		return err
+	}
+
+	var set, unset bsonx.Doc
+	for _, elem := range doc {
+		key := "metadata." + elem.Key
+		if elem.Value.Type() == bsontype.Null {
+			unset = append(unset, bsonx.Elem{key, bsonx.String("")})
+			continue
+		}
+		set = append(set, bsonx.Elem{key, elem.Value})
+	}
+
+	var update bsonx.Doc
+	if len(set) > 0 {
+		update = append(update, bsonx.Elem{"$set", bsonx.Document(set)})
+	}
+	if len(unset) > 0 {
+		update = append(update, bsonx.Elem{"$unset", bsonx.Document(unset)})
+	}
+	if len(update) == 0 {
+		return nil
+	}
+
+	return b.updateFile(fileID, update)
+}
+
+// CopyFile copies the stored file with the specified file ID to the dst bucket, where it is stored with the ID
+// newFileID. dst must be in the same deployment as this bucket, and can be the same bucket. The chunks are copied by
+// the server with an aggregation, so the file is not downloaded, and the document in the files collection is written
+// after all chunks are copied. This operation requires MongoDB 4.2 or later.
+//
+// If this operation requires a custom write deadline to be set on the bucket, it cannot be done concurrently with other
+// write operations operations on this bucket that also require a custom deadline.
+func (b *Bucket) CopyFile(fileID interface{}, dst *Bucket, newFileID interface{}) error {
+	ctx, cancel := deadlineContext(b.writeDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	return b.copyFile(ctx, fileID, dst, newFileID)
+}
+
+// MoveFile moves the stored file with the specified file ID to the dst bucket, keeping its file ID. The file is
+// copied as described by CopyFile and then deleted from this bucket. This operation requires MongoDB 4.2 or later.
+//
+// If this operation requires a custom write deadline to be set on the bucket, it cannot be done concurrently with other
+// write operations operations on this bucket that also require a custom deadline.
+func (b *Bucket) MoveFile(fileID interface{}, dst *Bucket) error {
+	if b.sameCollections(dst) {
+		return nil
+	}
+
+	ctx, cancel := deadlineContext(b.writeDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	if err := b.copyFile(ctx, fileID, dst, fileID); err != nil {
+		return err
+	}
+
+	id, err := convertFileID(fileID)
+	if err != nil {
+		return err
+	}
+	if _, err = b.filesColl.DeleteOne(ctx, bsonx.Doc{{"_id", id}}); err != nil {
+		return err
+	}
+	return b.deleteChunks(ctx, fileID)
+}
+
 // Drop drops the files and chunks collections associated with this bucket.
 //
 // If this operation requires a custom write deadline to be set on the bucket, it cannot be done concurrently with other
@@ -354,6 +657,102 @@ func (b *Bucket) Drop() error {
 	return b.chunksColl.Drop(ctx)
 }
 
+// updateFile applies an update to the document of the file with the specified file ID in the files collection.
+func (b *Bucket) updateFile(fileID interface{}, update bsonx.Doc) error {
+	ctx, cancel := deadlineContext(b.writeDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	id, err := convertFileID(fileID)
+	if err != nil {
+		return err
+	}
+	res, err := b.filesColl.UpdateOne(ctx, bsonx.Doc{{"_id", id}}, update)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrFileNotFound
+	}
+
+	return nil
+}
+
+func (b *Bucket) copyFile(ctx context.Context, fileID interface{}, dst *Bucket, newFileID interface{}) error {
+	id, err := convertFileID(fileID)
+	if err != nil {
+		return err
+	}
+	newID, err := convertFileID(newFileID)
+	if err != nil {
+		return err
+	}
+
+	// must use primary read pref mode to read the file and run the aggregation with $merge
+	primary := options.Collection().SetReadPreference(readpref.Primary())
+	filesColl, err := b.filesColl.Clone(primary)
+	if err != nil {
+		return err
+	}
+	chunksColl, err := b.chunksColl.Clone(primary)
+	if err != nil {
+		return err
+	}
+
+	raw, err := filesColl.FindOne(ctx, bsonx.Doc{{"_id", id}}).DecodeBytes()
+	if err == mongo.ErrNoDocuments {
+		return ErrFileNotFound
+	}
+	if err != nil {
+		return err
+	}
+	file, err := bsonx.ReadDoc(raw)
+	if err != nil {
+		return err
+	}
+	file = file.Set("_id", newID)
+
+	if err = dst.checkFirstWrite(ctx); err != nil {
+		return err
+	}
+
+	pipeline := bsonx.Arr{
+		bsonx.Document(bsonx.Doc{{"$match", bsonx.Document(bsonx.Doc{{"files_id", id}})}}),
+		bsonx.Document(bsonx.Doc{{"$project", bsonx.Document(bsonx.Doc{
+			{"_id", bsonx.Int32(0)},
+			{"files_id", bsonx.Document(bsonx.Doc{{"$literal", newID}})},
+			{"n", bsonx.Int32(1)},
+			{"data", bsonx.Int32(1)},
+		})}}),
+		bsonx.Document(bsonx.Doc{{"$merge", bsonx.Document(bsonx.Doc{
+			{"into", bsonx.Document(bsonx.Doc{
+				{"db", bsonx.String(dst.db.Name())},
+				{"coll", bsonx.String(dst.chunksColl.Name())},
+			})},
+			{"whenMatched", bsonx.String("fail")},
+			{"whenNotMatched", bsonx.String("insert")},
+		})}}),
+	}
+	cursor, err := chunksColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	_ = cursor.Close(ctx)
+
+	if _, err = dst.filesColl.InsertOne(ctx, file); err != nil {
+		_ = dst.deleteChunks(ctx, newFileID) // the copied chunks would be orphaned
+		return err
+	}
+	return nil
+}
+
+// sameCollections returns true if b and other use the same files and chunks collections.
+func (b *Bucket) sameCollections(other *Bucket) bool {
+	return b.db.Name() == other.db.Name() && b.name == other.name
+}
+
 func (b *Bucket) openDownloadStream(filter interface{}, opts ...*options.FindOptions) (*DownloadStream, error) {
 	ctx, cancel := deadlineContext(b.readDeadline)
 	if cancel != nil {
@@ -365,11 +764,29 @@ func (b *Bucket) openDownloadStream(filter interface{}, opts ...*options.FindOpt
 		return nil, err
 	}
 
-	fileLenElem, err := cursor.Current.LookupErr("length")
+	ds, err := b.newFileDownloadStream(cursor.Current)
 	if err != nil {
 		return nil, err
 	}
-	fileIDElem, err := cursor.Current.LookupErr("_id")
+
+	if ds.fileLen > 0 && ds.concurrency <= 1 {
+		// with concurrency, chunks are read ahead by the stream, so no cursor is needed
+		ds.cursor, err = findChunks(ctx, b.chunksColl, ds.fileID, 0, -1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ds, nil
+}
+
+// newFileDownloadStream creates a download stream for the file described by a document in the files collection. The
+// stream does not have a cursor, so chunks are fetched by the first Read.
+func (b *Bucket) newFileDownloadStream(file bson.Raw) (*DownloadStream, error) {
+	fileLenElem, err := file.LookupErr("length")
+	if err != nil {
+		return nil, err
+	}
+	fileIDElem, err := file.LookupErr("_id")
 	if err != nil {
 		return nil, err
 	}
@@ -382,15 +799,30 @@ func (b *Bucket) openDownloadStream(filter interface{}, opts ...*options.FindOpt
 		fileLen = fileLenElem.Int64()
 	}
 
-	if fileLen == 0 {
-		return newDownloadStream(nil, b.chunkSize, 0), nil
+	// The file may have been uploaded with a different chunk size than the one configured for the bucket.
+	chunkSize := b.chunkSize
+	if chunkSizeElem, err := file.LookupErr("chunkSize"); err == nil {
+		if size, ok := chunkSizeElem.Int32OK(); ok && size > 0 {
+			chunkSize = size
+		}
 	}
 
-	chunksCursor, err := b.findChunks(ctx, fileIDElem)
-	if err != nil {
-		return nil, err
+	ds := newDownloadStream(nil, chunkSize, fileLen, fileIDElem, b.chunksColl)
+	if fileLen > 0 {
+		ds.concurrency = b.downloadConcurrency
+	}
+	if compression, ok := file.Lookup("compression").StringValueOK(); ok {
+		if err = checkCompression(compression); err != nil {
+			return nil, err
+		}
+		ds.compression = compression
 	}
-	return newDownloadStream(chunksCursor, b.chunkSize, int64(fileLen)), nil
+	if algorithm, sum, ok := parseChecksum(file); ok {
+		ds.checksumAlgorithm = algorithm
+		ds.expectedChecksum = sum
+		ds.checksum, _ = newChecksum(algorithm) // files with unknown algorithms are not verified
+	}
+	return ds, nil
 }
 
 func deadlineContext(deadline time.Time) (context.Context, context.CancelFunc) {
@@ -440,13 +872,24 @@ func (b *Bucket) findFile(ctx context.Context, filter interface{}, opts ...*opti
 	return cursor, nil
 }
 
-func (b *Bucket) findChunks(ctx context.Context, fileID interface{}) (*mongo.Cursor, error) {
+// findChunks returns a cursor over the chunks of a file with indexes from first to last, sorted by index. If last is
+// negative, all chunks from first to the end of the file are returned.
+func findChunks(ctx context.Context, chunksColl *mongo.Collection, fileID interface{}, first, last int32) (*mongo.Cursor, error) {
 	id, err := convertFileID(fileID)
 	if err != nil {
 		return nil, err
 	}
-	chunksCursor, err := b.chunksColl.Find(ctx,
-		bsonx.Doc{{"files_id", id}},
+	filter := bsonx.Doc{{"files_id", id}}
+	switch {
+	case last >= 0:
+		filter = append(filter, bsonx.Elem{"n", bsonx.Document(bsonx.Doc{
+			{"$gte", bsonx.Int32(first)},
+			{"$lte", bsonx.Int32(last)},
+		})})
+	case first > 0:
+		filter = append(filter, bsonx.Elem{"n", bsonx.Document(bsonx.Doc{{"$gte", bsonx.Int32(first)}})})
+	}
+	chunksCursor, err := chunksColl.Find(ctx, filter,
 		options.Find().SetSort(bsonx.Doc{{"n", bsonx.Int32(1)}})) // sort by chunk index
 	if err != nil {
 		return nil, err
@@ -554,13 +997,32 @@ func (b *Bucket) checkFirstWrite(ctx context.Context) error {
 
 func (b *Bucket) parseUploadOptions(opts ...*options.UploadOptions) (*Upload, error) {
 	upload := &Upload{
-		chunkSize: b.chunkSize, // upload chunk size defaults to bucket's value
+		chunkSize:         b.chunkSize, // upload chunk size defaults to bucket's value
+		concurrency:       b.uploadConcurrency,
+		checksumAlgorithm: b.checksumAlgorithm,
+		compression:       b.chunkCompression,
 	}
 
 	uo := options.MergeUploadOptions(opts...)
 	if uo.ChunkSizeBytes != nil {
 		upload.chunkSize = *uo.ChunkSizeBytes
 	}
+	if uo.ChecksumAlgorithm != nil {
+		upload.checksumAlgorithm = *uo.ChecksumAlgorithm
+	}
+	if upload.checksumAlgorithm != "" {
+		if _, err := newChecksum(upload.checksumAlgorithm); err != nil {
+			return nil, err
+		}
+	}
+	if uo.ChunkCompression != nil {
+		upload.compression = *uo.ChunkCompression
+	}
+	if upload.compression != "" {
+		if err := checkCompression(upload.compression); err != nil {
+			return nil, err
+		}
+	}
 	if uo.Registry == nil {
 		uo.Registry = bson.DefaultRegistry
 	}
@@ -579,6 +1041,28 @@ func (b *Bucket) parseUploadOptions(opts ...*options.UploadOptions) (*Upload, er
 	return upload, nil
 }
 
+// uploadedChunkData returns the uncompressed data of a chunk document.
+func uploadedChunkData(chunk bson.Raw, compression string) ([]byte, error) {
+	data, err := chunk.LookupErr("data")
+	if err != nil {
+		return nil, err
+	}
+	_, dataBytes := data.Binary()
+	if compression == "" {
+		return dataBytes, nil
+	}
+	return decompressChunk(compression, dataBytes)
+}
+
+// marshalDoc marshals a document with the default registry.
+func marshalDoc(doc interface{}) (bsonx.Doc, error) {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return bsonx.ReadDoc(raw)
+}
+
 type _convertFileID struct {
 	ID interface{} `bson:"_id"`
 }
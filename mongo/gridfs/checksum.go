@@ -0,0 +1,87 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package gridfs
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+// The names of the checksum algorithms that are registered by default.
+const (
+	ChecksumSHA256 = "sha256"
+	ChecksumSHA512 = "sha512"
+)
+
+// ErrChecksumMismatch is returned by a download stream or VerifyFile if the checksum of the downloaded file does not
+// match the checksum stored in the files collection.
+var ErrChecksumMismatch = errors.New("file checksum does not match stored checksum")
+
+// ErrNoChecksum is returned by VerifyFile if the file was not uploaded with a checksum.
+var ErrNoChecksum = errors.New("file does not have a checksum")
+
+var (
+	checksumAlgorithmsMu sync.RWMutex
+	checksumAlgorithms   = map[string]func() hash.Hash{
+		ChecksumSHA256: sha256.New,
+		ChecksumSHA512: sha512.New,
+	}
+)
+
+// RegisterChecksumAlgorithm registers a checksum algorithm with the given name, so that it can be used for the
+// ChecksumAlgorithm bucket and upload options and to verify the checksums of files uploaded with it. Registering a
+// name again replaces the algorithm that was registered with it.
+func RegisterChecksumAlgorithm(name string, newHash func() hash.Hash) {
+	checksumAlgorithmsMu.Lock()
+	defer checksumAlgorithmsMu.Unlock()
+
+	checksumAlgorithms[name] = newHash
+}
+
+func newChecksum(name string) (hash.Hash, error) {
+	checksumAlgorithmsMu.RLock()
+	defer checksumAlgorithmsMu.RUnlock()
+
+	newHash, ok := checksumAlgorithms[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown checksum algorithm %q", name)
+	}
+	return newHash(), nil
+}
+
+// checksumDoc returns the "checksum" field stored in the files collection for a file uploaded with the given
+// algorithm.
+func checksumDoc(algorithm string, sum []byte) bsonx.Val {
+	return bsonx.Document(bsonx.Doc{
+		{"algorithm", bsonx.String(algorithm)},
+		{"hash", bsonx.Binary(0x00, sum)},
+	})
+}
+
+// parseChecksum returns the algorithm and hash from the "checksum" field of a document in the files collection. ok is
+// false if the document does not have a checksum.
+func parseChecksum(file bson.Raw) (algorithm string, sum []byte, ok bool) {
+	val, err := file.LookupErr("checksum")
+	if err != nil || val.Type != bsontype.EmbeddedDocument {
+		return "", nil, false
+	}
+	doc := val.Document()
+	algorithm, ok = doc.Lookup("algorithm").StringValueOK()
+	if !ok {
+		return "", nil, false
+	}
+	_, sum, ok = doc.Lookup("hash").BinaryOK()
+	return algorithm, sum, ok
+}
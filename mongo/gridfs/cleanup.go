@@ -0,0 +1,247 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package gridfs
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+const (
+	defaultCleanupBatchSize   = 1000
+	defaultCleanupMinChunkAge = 24 * time.Hour
+)
+
+// CleanupReport describes the orphaned chunks and incomplete files found by Cleanup.
+type CleanupReport struct {
+	// The file IDs of the chunks that do not have a document in the files collection.
+	OrphanedFileIDs []bson.RawValue
+
+	// The number of chunks that do not have a document in the files collection.
+	OrphanedChunks int64
+
+	// The IDs of the documents in the files collection whose chunks are missing.
+	IncompleteFileIDs []bson.RawValue
+
+	// The number of chunks deleted.
+	DeletedChunks int64
+
+	// The number of documents deleted from the files collection.
+	DeletedFiles int64
+}
+
+// Cleanup finds the chunks that do not have a document in the files collection, which are left behind by uploads that
+// were interrupted or aborted, and the documents in the files collection that are missing chunks. If the Delete option
+// is true, the orphaned chunks are deleted, and the incomplete files are deleted with their remaining chunks. Deletes
+// are done in batches of the BatchSize option.
+//
+// Orphaned chunks are only reported if they are older than the MinChunkAge option, which is 24 hours by default,
+// because the chunks of uploads in progress and of paused uploads look the same. Deleting the chunks of a paused
+// upload makes it impossible to continue it with ResumeUploadStream, so MinChunkAge must be longer than uploads are
+// paused for.
+//
+// Finding incomplete files counts the chunks of every file in the bucket, so Cleanup should be run as a maintenance
+// task rather than in the request path of an application.
+//
+// If this operation requires a custom write deadline to be set on the bucket, it cannot be done concurrently with other
+// write operations operations on this bucket that also require a custom deadline.
+func (b *Bucket) Cleanup(opts ...*options.GridFSCleanupOptions) (*CleanupReport, error) {
+	co := options.MergeGridFSCleanupOptions(opts...)
+	batchSize := defaultCleanupBatchSize
+	if co.BatchSize != nil && *co.BatchSize > 0 {
+		batchSize = int(*co.BatchSize)
+	}
+
+	ctx, cancel := deadlineContext(b.writeDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	// must use primary read pref mode so that the reads see the latest writes
+	primary := options.Collection().SetReadPreference(readpref.Primary())
+	filesColl, err := b.filesColl.Clone(primary)
+	if err != nil {
+		return nil, err
+	}
+	chunksColl, err := b.chunksColl.Clone(primary)
+	if err != nil {
+		return nil, err
+	}
+
+	minAge := defaultCleanupMinChunkAge
+	if co.MinChunkAge != nil {
+		minAge = *co.MinChunkAge
+	}
+
+	report := &CleanupReport{}
+	if err = b.findOrphanedChunks(ctx, chunksColl, minAge, report); err != nil {
+		return nil, err
+	}
+	if err = b.findIncompleteFiles(ctx, filesColl, chunksColl, report); err != nil {
+		return nil, err
+	}
+
+	if co.Delete == nil || !*co.Delete {
+		return report, nil
+	}
+
+	for _, batch := range fileIDBatches(report.OrphanedFileIDs, batchSize) {
+		res, err := chunksColl.DeleteMany(ctx, bsonx.Doc{{"files_id", bsonx.Document(bsonx.Doc{{"$in", batch}})}})
+		if err != nil {
+			return report, err
+		}
+		report.DeletedChunks += res.DeletedCount
+	}
+	for _, batch := range fileIDBatches(report.IncompleteFileIDs, batchSize) {
+		// delete the files before their chunks, as Delete does
+		res, err := filesColl.DeleteMany(ctx, bsonx.Doc{{"_id", bsonx.Document(bsonx.Doc{{"$in", batch}})}})
+		if err != nil {
+			return report, err
+		}
+		report.DeletedFiles += res.DeletedCount
+
+		res, err = chunksColl.DeleteMany(ctx, bsonx.Doc{{"files_id", bsonx.Document(bsonx.Doc{{"$in", batch}})}})
+		if err != nil {
+			return report, err
+		}
+		report.DeletedChunks += res.DeletedCount
+	}
+
+	return report, nil
+}
+
+// findOrphanedChunks groups the chunks by file ID and reports the groups that do not have a document in the files
+// collection.
+func (b *Bucket) findOrphanedChunks(ctx context.Context, chunksColl *mongo.Collection, minAge time.Duration,
+	report *CleanupReport) error {
+
+	match := bsonx.Doc{{"file", bsonx.Document(bsonx.Doc{{"$size", bsonx.Int32(0)}})}}
+	if minAge > 0 {
+		// chunk IDs are ObjectIDs generated when the chunks are uploaded, so they contain the upload time
+		newest := primitive.NewObjectIDFromTimestamp(time.Now().Add(-minAge))
+		match = append(match, bsonx.Elem{"newest", bsonx.Document(bsonx.Doc{{"$lt", bsonx.ObjectID(newest)}})})
+	}
+
+	pipeline := bsonx.Arr{
+		bsonx.Document(bsonx.Doc{{"$group", bsonx.Document(bsonx.Doc{
+			{"_id", bsonx.String("$files_id")},
+			{"chunks", bsonx.Document(bsonx.Doc{{"$sum", bsonx.Int32(1)}})},
+			{"newest", bsonx.Document(bsonx.Doc{{"$max", bsonx.String("$_id")}})},
+		})}}),
+		bsonx.Document(bsonx.Doc{{"$lookup", bsonx.Document(bsonx.Doc{
+			{"from", bsonx.String(b.filesColl.Name())},
+			{"localField", bsonx.String("_id")},
+			{"foreignField", bsonx.String("_id")},
+			{"as", bsonx.String("file")},
+		})}}),
+		bsonx.Document(bsonx.Doc{{"$match", bsonx.Document(match)}}),
+		bsonx.Document(bsonx.Doc{{"$project", bsonx.Document(bsonx.Doc{{"chunks", bsonx.Int32(1)}})}}),
+	}
+	cursor, err := chunksColl.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	for cursor.Next(ctx) {
+		report.OrphanedFileIDs = append(report.OrphanedFileIDs, copyRawValue(cursor.Current.Lookup("_id")))
+		report.OrphanedChunks += int64Value(cursor.Current.Lookup("chunks"))
+	}
+	return cursor.Err()
+}
+
+// findIncompleteFiles counts the chunks of each document in the files collection and reports the files that have
+// fewer chunks than their length requires.
+func (b *Bucket) findIncompleteFiles(ctx context.Context, filesColl, chunksColl *mongo.Collection,
+	report *CleanupReport) error {
+
+	projection := bsonx.Doc{{"length", bsonx.Int32(1)}, {"chunkSize", bsonx.Int32(1)}}
+	cursor, err := filesColl.Find(ctx, bsonx.Doc{}, options.Find().SetProjection(projection))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	for cursor.Next(ctx) {
+		length := int64Value(cursor.Current.Lookup("length"))
+		chunkSize := int64Value(cursor.Current.Lookup("chunkSize"))
+		if length <= 0 || chunkSize <= 0 {
+			continue
+		}
+		numChunks := int64(math.Ceil(float64(length) / float64(chunkSize)))
+
+		fileID := cursor.Current.Lookup("_id")
+		id, err := convertFileID(fileID)
+		if err != nil {
+			return err
+		}
+		chunks, err := chunksColl.CountDocuments(ctx, bsonx.Doc{
+			{"files_id", id},
+			{"n", bsonx.Document(bsonx.Doc{{"$lt", bsonx.Int64(numChunks)}})},
+		})
+		if err != nil {
+			return err
+		}
+		if chunks < numChunks {
+			report.IncompleteFileIDs = append(report.IncompleteFileIDs, copyRawValue(fileID))
+		}
+	}
+	return cursor.Err()
+}
+
+// fileIDBatches splits file IDs into arrays of at most size IDs.
+func fileIDBatches(ids []bson.RawValue, size int) []bsonx.Val {
+	var batches []bsonx.Val
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		arr := make(bsonx.Arr, 0, end-start)
+		for _, id := range ids[start:end] {
+			var val bsonx.Val
+			if err := val.UnmarshalBSONValue(id.Type, id.Value); err == nil {
+				arr = append(arr, val)
+			}
+		}
+		batches = append(batches, bsonx.Array(arr))
+	}
+	return batches
+}
+
+// copyRawValue copies a value from a cursor document, which is only valid until the next call to Next.
+func copyRawValue(val bson.RawValue) bson.RawValue {
+	val.Value = append([]byte(nil), val.Value...)
+	return val
+}
+
+// int64Value returns the value of an integer or double field.
+func int64Value(val bson.RawValue) int64 {
+	switch val.Type {
+	case bsontype.Int32:
+		return int64(val.Int32())
+	case bsontype.Int64:
+		return val.Int64()
+	case bsontype.Double:
+		return int64(val.Double())
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package gridfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// The names of the codecs that can be used to compress chunks.
+const (
+	CompressionZstd = "zstd"
+	CompressionGzip = "gzip"
+)
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+// zstdCodec returns an encoder and a decoder that are shared by all streams. Their EncodeAll and DecodeAll methods
+// are safe for concurrent use.
+func zstdCodec() (*zstd.Encoder, *zstd.Decoder, error) {
+	zstdOnce.Do(func() {
+		zstdEncoder, zstdErr = zstd.NewWriter(nil)
+		if zstdErr != nil {
+			return
+		}
+		zstdDecoder, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdEncoder, zstdDecoder, zstdErr
+}
+
+func checkCompression(codec string) error {
+	switch codec {
+	case CompressionZstd, CompressionGzip:
+		return nil
+	default:
+		return fmt.Errorf("unknown chunk compression codec %q", codec)
+	}
+}
+
+// compressChunk compresses the data of a chunk with the given codec.
+func compressChunk(codec string, data []byte) ([]byte, error) {
+	switch codec {
+	case CompressionZstd:
+		encoder, _, err := zstdCodec()
+		if err != nil {
+			return nil, err
+		}
+		return encoder.EncodeAll(data, nil), nil
+	case CompressionGzip:
+		var b bytes.Buffer
+		w := gzip.NewWriter(&b)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	default:
+		return nil, checkCompression(codec)
+	}
+}
+
+// decompressChunk decompresses the data of a chunk that was compressed with the given codec.
+func decompressChunk(codec string, data []byte) ([]byte, error) {
+	switch codec {
+	case CompressionZstd:
+		_, decoder, err := zstdCodec()
+		if err != nil {
+			return nil, err
+		}
+		return decoder.DecodeAll(data, nil)
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = r.Close()
+		}()
+		return ioutil.ReadAll(r)
+	default:
+		return nil, checkCompression(codec)
+	}
+}
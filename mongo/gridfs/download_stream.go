@@ -7,12 +7,15 @@
 package gridfs
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"hash"
 	"io"
 	"math"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -24,7 +27,17 @@ var ErrWrongSize = errors.New("chunk size does not match expected size")
 
 var errNoMoreChunks = errors.New("no more chunks remaining")
 
-// DownloadStream is a io.Reader that can be used to download a file from a GridFS bucket.
+// readAheadBatchSize is the maximum number of bytes of chunks read by each find operation when a download stream reads
+// ahead concurrently.
+const readAheadBatchSize = 4 * 1024 * 1024 // 4 MiB
+
+var errInvalidWhence = errors.New("invalid whence")
+
+var errNegativePosition = errors.New("negative position")
+
+// DownloadStream is a io.Reader that can be used to download a file from a GridFS bucket. It also implements
+// io.Seeker and io.ReaderAt, so it can be used with http.ServeContent to serve ranges of a file. Chunks are fetched
+// from the server on demand, so seeking does not download the skipped parts of the file.
 type DownloadStream struct {
 	numChunks     int32
 	chunkSize     int32
@@ -37,18 +50,51 @@ type DownloadStream struct {
 	expectedChunk int32 // index of next expected chunk
 	readDeadline  time.Time
 	fileLen       int64
+	end           int64 // position in the file after the last byte returned by Read
+	offset        int64 // position in the file of the next byte returned by Read
+	fileID        interface{}
+	chunksColl    *mongo.Collection // collection to fetch chunks from after a seek
+
+	// Read-ahead state, used when concurrency is greater than 1. readAhead holds the pending batches in file order,
+	// starting at readAheadStart, and readAheadEnd is the position in the file of the next batch to start.
+	concurrency     int
+	readAhead       []chan readAheadBatch
+	readAheadStart  int64
+	readAheadEnd    int64
+	readAheadCtx    context.Context
+	readAheadCancel context.CancelFunc
+
+	// Checksum state. checksum is nil if the file does not have a checksum, if its algorithm is not registered, or if
+	// the stream was not read sequentially from the start of the file.
+	checksumAlgorithm string
+	checksum          hash.Hash
+	expectedChecksum  []byte
+	checksumErr       error
+
+	compression string // codec used to compress the chunks, if any
 }
 
-func newDownloadStream(cursor *mongo.Cursor, chunkSize int32, fileLen int64) *DownloadStream {
+type readAheadBatch struct {
+	offset int64
+	data   []byte
+	err    error
+}
+
+func newDownloadStream(cursor *mongo.Cursor, chunkSize int32, fileLen int64, fileID interface{},
+	chunksColl *mongo.Collection) *DownloadStream {
+
 	numChunks := int32(math.Ceil(float64(fileLen) / float64(chunkSize)))
 
 	return &DownloadStream{
-		numChunks: numChunks,
-		chunkSize: chunkSize,
-		cursor:    cursor,
-		buffer:    make([]byte, chunkSize),
-		done:      cursor == nil,
-		fileLen:   fileLen,
+		numChunks:  numChunks,
+		chunkSize:  chunkSize,
+		cursor:     cursor,
+		buffer:     make([]byte, chunkSize),
+		done:       fileLen == 0,
+		fileLen:    fileLen,
+		end:        fileLen,
+		fileID:     fileID,
+		chunksColl: chunksColl,
 	}
 }
 
@@ -59,6 +105,10 @@ func (ds *DownloadStream) Close() error {
 	}
 
 	ds.closed = true
+	if ds.cursor != nil {
+		_ = ds.cursor.Close(context.Background())
+	}
+	ds.stopReadAhead()
 	return nil
 }
 
@@ -72,14 +122,23 @@ func (ds *DownloadStream) SetReadDeadline(t time.Time) error {
 	return nil
 }
 
-// Read reads the file from the server and writes it to a destination byte slice.
+// Read reads the file from the server and writes it to a destination byte slice. If the file was uploaded with a
+// checksum and the stream is read sequentially from the start of the file, the checksum is verified when the end of
+// the file is reached, and ErrChecksumMismatch is returned instead of io.EOF if it does not match.
 func (ds *DownloadStream) Read(p []byte) (int, error) {
 	if ds.closed {
 		return 0, ErrStreamClosed
 	}
 
 	if ds.done {
-		return 0, io.EOF
+		return 0, ds.eof()
+	}
+	if ds.offset >= ds.end {
+		ds.done = true
+		return 0, ds.eof()
+	}
+	if remaining := ds.end - ds.offset; int64(len(p)) > remaining {
+		p = p[:remaining]
 	}
 
 	ctx, cancel := deadlineContext(ds.readDeadline)
@@ -97,7 +156,7 @@ func (ds *DownloadStream) Read(p []byte) (int, error) {
 				if err == errNoMoreChunks {
 					if bytesCopied == 0 {
 						ds.done = true
-						return 0, io.EOF
+						return 0, ds.eof()
 					}
 					return bytesCopied, nil
 				}
@@ -106,21 +165,92 @@ func (ds *DownloadStream) Read(p []byte) (int, error) {
 		}
 
 		copied := copy(p[bytesCopied:], ds.buffer[ds.bufferStart:ds.bufferEnd])
+		if ds.checksum != nil {
+			_, _ = ds.checksum.Write(p[bytesCopied : bytesCopied+copied])
+		}
 
 		bytesCopied += copied
 		ds.bufferStart += copied
+		ds.offset += int64(copied)
 	}
 
 	return len(p), nil
 }
 
-// Skip skips a given number of bytes in the file.
+// eof returns the error returned by Read at the end of the file, which is ErrChecksumMismatch if the checksum of the
+// file does not match and io.EOF otherwise.
+func (ds *DownloadStream) eof() error {
+	if ds.checksum != nil {
+		if !bytes.Equal(ds.checksum.Sum(nil), ds.expectedChecksum) {
+			ds.checksumErr = ErrChecksumMismatch
+		}
+		ds.checksum = nil
+	}
+	if ds.checksumErr != nil {
+		return ds.checksumErr
+	}
+	return io.EOF
+}
+
+// Skip skips a given number of bytes in the file. It returns the number of bytes skipped, which is less than skip if
+// the end of the file is reached.
 func (ds *DownloadStream) Skip(skip int64) (int64, error) {
 	if ds.closed {
 		return 0, ErrStreamClosed
 	}
 
-	if ds.done {
+	remaining := ds.end - ds.offset
+	if skip > remaining {
+		skip = remaining
+	}
+	if skip <= 0 {
+		return 0, nil
+	}
+
+	ds.seek(ds.offset + skip)
+	return skip, nil
+}
+
+// Seek sets the position of the next Read in the file according to whence, as described by io.Seeker, and returns
+// the new position. Seeking does not contact the server; the chunk containing the new position is fetched by the next
+// Read. Seeking past the end of the file is allowed, and subsequent calls to Read return io.EOF.
+func (ds *DownloadStream) Seek(offset int64, whence int) (int64, error) {
+	if ds.closed {
+		return 0, ErrStreamClosed
+	}
+
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = ds.offset + offset
+	case io.SeekEnd:
+		pos = ds.fileLen + offset
+	default:
+		return 0, errInvalidWhence
+	}
+	if pos < 0 {
+		return 0, errNegativePosition
+	}
+
+	ds.seek(pos)
+	return pos, nil
+}
+
+// ReadAt reads len(p) bytes of the file starting at offset off, as described by io.ReaderAt. Only the chunks that
+// overlap the requested range are fetched from the server. ReadAt does not change the position used by Read and Seek.
+func (ds *DownloadStream) ReadAt(p []byte, off int64) (int, error) {
+	if ds.closed {
+		return 0, ErrStreamClosed
+	}
+	if off < 0 {
+		return 0, errNegativePosition
+	}
+	if off >= ds.fileLen {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
 		return 0, nil
 	}
 
@@ -129,78 +259,240 @@ func (ds *DownloadStream) Skip(skip int64) (int64, error) {
 		defer cancel()
 	}
 
-	var skipped int64
-	var err error
+	return ds.readAt(ctx, p, off)
+}
 
-	for skipped < skip {
-		if ds.bufferStart == 0 {
-			err = ds.fillBuffer(ctx)
-			if err != nil {
-				if err == errNoMoreChunks {
-					return skipped, nil
-				}
+// readAt implements ReadAt. It only uses fields that do not change after the stream is created, so it can be called
+// concurrently by read-ahead goroutines.
+func (ds *DownloadStream) readAt(ctx context.Context, p []byte, off int64) (int, error) {
+	end := off + int64(len(p))
+	if end > ds.fileLen {
+		end = ds.fileLen
+	}
+	first := int32(off / int64(ds.chunkSize))
+	last := int32((end - 1) / int64(ds.chunkSize))
 
-				return skipped, err
+	cursor, err := findChunks(ctx, ds.chunksColl, ds.fileID, first, last)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	var n int
+	for index := first; index <= last; index++ {
+		if !cursor.Next(ctx) {
+			if err = cursor.Err(); err != nil {
+				return n, err
 			}
+			// the chunk is missing
+			return n, ErrWrongIndex
 		}
 
-		// try to skip whole chunk if possible
-		toSkip := 0
-		if skip-skipped < int64(len(ds.buffer)) {
-			// can skip whole chunk
-			toSkip = len(ds.buffer)
-		} else {
-			// can only skip part of buffer
-			toSkip = int(skip - skipped)
+		data, err := ds.chunkData(cursor.Current, index)
+		if err != nil {
+			return n, err
 		}
 
-		skipped += int64(toSkip)
-		ds.bufferStart = (ds.bufferStart + toSkip) % (int(ds.chunkSize))
+		chunkOffset := off + int64(n) - int64(index)*int64(ds.chunkSize)
+		n += copy(p[n:], data[chunkOffset:])
 	}
 
-	return skip, nil
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// seek moves the position of the next Read to pos. If pos is in the buffered chunk, the buffer is reused. Otherwise,
+// the buffer is discarded and fillBuffer fetches the chunk containing pos.
+func (ds *DownloadStream) seek(pos int64) {
+	if pos != ds.offset {
+		// the checksum can only be verified if the file is read sequentially
+		ds.checksum = nil
+	}
+
+	bufferOffset := ds.offset - int64(ds.bufferStart) // position in the file of ds.buffer[0]
+	if pos >= bufferOffset && pos < bufferOffset+int64(ds.bufferEnd) {
+		ds.bufferStart = int(pos - bufferOffset)
+		ds.offset = pos
+		ds.done = false
+		return
+	}
+
+	ds.bufferStart = 0
+	ds.bufferEnd = 0
+	ds.offset = pos
+	ds.done = pos >= ds.end
 }
 
 func (ds *DownloadStream) fillBuffer(ctx context.Context) error {
+	if ds.concurrency > 1 {
+		return ds.fillBufferReadAhead(ctx)
+	}
+
+	index := int32(ds.offset / int64(ds.chunkSize))
+	if ds.cursor != nil && index != ds.expectedChunk {
+		// the stream was moved by a seek, so the next chunk from the cursor is not the one that is needed
+		_ = ds.cursor.Close(ctx)
+		ds.cursor = nil
+	}
+	if ds.cursor == nil {
+		last := int32(-1)
+		if ds.end < ds.fileLen {
+			last = int32((ds.end - 1) / int64(ds.chunkSize))
+		}
+		cursor, err := findChunks(ctx, ds.chunksColl, ds.fileID, index, last)
+		if err != nil {
+			return err
+		}
+		ds.cursor = cursor
+		ds.expectedChunk = index
+	}
+
 	if !ds.cursor.Next(ctx) {
 		ds.done = true
 		return errNoMoreChunks
 	}
 
-	chunkIndex, err := ds.cursor.Current.LookupErr("n")
+	dataBytes, err := ds.chunkData(ds.cursor.Current, ds.expectedChunk)
 	if err != nil {
 		return err
 	}
 
-	if chunkIndex.Int32() != ds.expectedChunk {
-		return ErrWrongIndex
+	ds.expectedChunk++
+	copied := copy(ds.buffer, dataBytes)
+
+	ds.bufferStart = int(ds.offset - int64(index)*int64(ds.chunkSize))
+	ds.bufferEnd = copied
+
+	return nil
+}
+
+// fillBufferReadAhead fills the buffer with the batch of chunks containing the current position, and starts reading
+// the following batches concurrently so that up to ds.concurrency batches are pending.
+func (ds *DownloadStream) fillBufferReadAhead(ctx context.Context) error {
+	if ds.offset >= ds.end {
+		ds.done = true
+		return errNoMoreChunks
+	}
+
+	if ds.offset < ds.readAheadStart || ds.offset >= ds.readAheadEnd {
+		// the stream was moved outside of the pending batches by a seek
+		ds.stopReadAhead()
+		ds.readAheadStart = ds.offset - ds.offset%int64(ds.chunkSize)
+		ds.readAheadEnd = ds.readAheadStart
 	}
 
-	ds.expectedChunk++
-	data, err := ds.cursor.Current.LookupErr("data")
+	batchSize := int64(readAheadBatchSize) / int64(ds.chunkSize) * int64(ds.chunkSize)
+	if batchSize == 0 {
+		batchSize = int64(ds.chunkSize)
+	}
+	for ds.offset >= ds.readAheadStart+batchSize {
+		// skip batches that end before the current position
+		ds.readAhead = ds.readAhead[1:]
+		ds.readAheadStart += batchSize
+	}
+	ds.startReadAhead(batchSize)
+
+	var batch readAheadBatch
+	select {
+	case batch = <-ds.readAhead[0]:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	ds.readAhead = ds.readAhead[1:]
+	ds.readAheadStart += batchSize
+	if batch.err != nil {
+		ds.stopReadAhead()
+		return batch.err
+	}
+	ds.startReadAhead(batchSize)
+
+	ds.buffer = batch.data
+	ds.bufferStart = int(ds.offset - batch.offset)
+	ds.bufferEnd = len(batch.data)
+	return nil
+}
+
+// startReadAhead starts reading batches of chunks after the pending batches until ds.concurrency batches are pending
+// or the end of the file is reached.
+func (ds *DownloadStream) startReadAhead(batchSize int64) {
+	if ds.readAheadCancel == nil {
+		var ctx context.Context
+		ctx, ds.readAheadCancel = context.WithCancel(context.Background())
+		ds.readAheadCtx = ctx
+	}
+
+	for len(ds.readAhead) < ds.concurrency && ds.readAheadEnd < ds.end {
+		offset := ds.readAheadEnd
+		size := batchSize
+		if offset+size > ds.end {
+			size = ds.end - offset
+		}
+
+		ch := make(chan readAheadBatch, 1)
+		go func(ctx context.Context, deadline time.Time) {
+			if !deadline.IsZero() {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, deadline)
+				defer cancel()
+			}
+
+			data := make([]byte, size)
+			n, err := ds.readAt(ctx, data, offset)
+			if err == io.EOF && int64(n) == size {
+				err = nil
+			}
+			ch <- readAheadBatch{offset: offset, data: data[:n], err: err}
+		}(ds.readAheadCtx, ds.readDeadline)
+
+		ds.readAhead = append(ds.readAhead, ch)
+		ds.readAheadEnd += size
+	}
+}
+
+// stopReadAhead cancels the pending batches.
+func (ds *DownloadStream) stopReadAhead() {
+	if ds.readAheadCancel != nil {
+		ds.readAheadCancel()
+		ds.readAheadCancel = nil
+	}
+	ds.readAhead = nil
+	ds.readAheadStart = 0
+	ds.readAheadEnd = 0
+}
+
+// chunkData returns the uncompressed data of a chunk document after checking that the chunk has the expected index and
+// size.
+func (ds *DownloadStream) chunkData(chunk bson.Raw, expectedIndex int32) ([]byte, error) {
+	chunkIndex, err := chunk.LookupErr("n")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, dataBytes := data.Binary()
-	copied := copy(ds.buffer, dataBytes)
+	if chunkIndex.Int32() != expectedIndex {
+		return nil, ErrWrongIndex
+	}
 
-	bytesLen := int32(len(dataBytes))
-	if ds.expectedChunk == ds.numChunks {
+	dataBytes, err := uploadedChunkData(chunk, ds.compression)
+	if err != nil {
+		return nil, err
+	}
+
+	bytesLen := int64(len(dataBytes))
+	if expectedIndex == ds.numChunks-1 {
 		// final chunk can be fewer than ds.chunkSize bytes
-		bytesDownloaded := int64(ds.chunkSize) * (int64(ds.expectedChunk) - int64(1))
-		bytesRemaining := ds.fileLen - int64(bytesDownloaded)
+		bytesRemaining := ds.fileLen - int64(ds.chunkSize)*int64(expectedIndex)
 
-		if int64(bytesLen) != bytesRemaining {
-			return ErrWrongSize
+		if bytesLen != bytesRemaining {
+			return nil, ErrWrongSize
 		}
-	} else if bytesLen != ds.chunkSize {
+	} else if bytesLen != int64(ds.chunkSize) {
 		// all intermediate chunks must have size ds.chunkSize
-		return ErrWrongSize
+		return nil, ErrWrongSize
 	}
 
-	ds.bufferStart = 0
-	ds.bufferEnd = copied
-
-	return nil
+	return dataBytes, nil
 }
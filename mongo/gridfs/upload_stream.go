@@ -8,6 +8,7 @@ package gridfs
 
 import (
 	"errors"
+	"hash"
 
 	"context"
 	"time"
@@ -26,6 +27,29 @@ const UploadBufferSize = 16 * 1024 * 1024 // 16 MiB
 // ErrStreamClosed is an error returned if an operation is attempted on a closed/aborted stream.
 var ErrStreamClosed = errors.New("stream is closed or aborted")
 
+// UploadProgress describes the part of a file that has been written to a bucket by an UploadStream. It can be stored
+// as a BSON document.
+type UploadProgress struct {
+	// The ID of the file.
+	FileID interface{} `bson:"fileId"`
+
+	// The name of the file.
+	Filename string `bson:"filename"`
+
+	// The number of bytes in each chunk of the file.
+	ChunkSize int32 `bson:"chunkSize"`
+
+	// The number of chunks that have been written. The index of the last written chunk is ChunksCommitted - 1.
+	ChunksCommitted int32 `bson:"chunksCommitted"`
+
+	// The number of bytes of the file that have been written. An upload that is resumed must continue writing the
+	// file from this offset.
+	Length int64 `bson:"length"`
+
+	// The codec used to compress the chunks of the file, or an empty string if they are not compressed.
+	Compression string `bson:"compression,omitempty"`
+}
+
 // UploadStream is used to upload a file in chunks. This type implements the io.Writer interface and a file can be
 // uploaded using the Write method. After an upload is complete, the Close method must be called to write file
 // metadata.
@@ -42,11 +66,12 @@ type UploadStream struct {
 	bufferIndex   int
 	fileLen       int64
 	writeDeadline time.Time
+	checksum      hash.Hash // checksum of the chunks that have been written
 }
 
 // NewUploadStream creates a new upload stream.
 func newUploadStream(upload *Upload, fileID interface{}, filename string, chunks, files *mongo.Collection) *UploadStream {
-	return &UploadStream{
+	us := &UploadStream{
 		Upload: upload,
 		FileID: fileID,
 
@@ -55,6 +80,10 @@ func newUploadStream(upload *Upload, fileID interface{}, filename string, chunks
 		filesColl:  files,
 		buffer:     make([]byte, UploadBufferSize),
 	}
+	if upload.checksumAlgorithm != "" {
+		us.checksum, _ = newChecksum(upload.checksumAlgorithm) // the algorithm is validated by parseUploadOptions
+	}
+	return us
 }
 
 // Close writes file metadata to the files collection and cleans up any resources associated with the UploadStream.
@@ -82,6 +111,20 @@ func (us *UploadStream) Close() error {
 	return nil
 }
 
+// Progress returns the progress of the upload. Chunks are written in batches, so the progress only includes the data
+// that has been written to the server, which can be less than the data passed to Write. The progress can be persisted
+// and passed to Bucket.ResumeUploadStream to resume the upload if it is interrupted.
+func (us *UploadStream) Progress() UploadProgress {
+	return UploadProgress{
+		FileID:          us.FileID,
+		Filename:        us.filename,
+		ChunkSize:       us.chunkSize,
+		ChunksCommitted: int32(us.chunkIndex),
+		Length:          us.fileLen,
+		Compression:     us.compression,
+	}
+}
+
 // SetWriteDeadline sets the write deadline for this stream.
 func (us *UploadStream) SetWriteDeadline(t time.Time) error {
 	if us.closed {
@@ -167,7 +210,10 @@ func (us *UploadStream) uploadChunks(ctx context.Context, uploadPartial bool) er
 	if err != nil {
 		return err
 	}
-	begChunkIndex := us.chunkIndex
+	// The chunk index and file length are only updated once the chunks are inserted, so that Progress never reports
+	// chunks that were not written.
+	chunkIndex := us.chunkIndex
+	fileLen := us.fileLen
 	for i := 0; i < us.bufferIndex; i += int(us.chunkSize) {
 		endIndex := i + int(us.chunkSize)
 		if us.bufferIndex-i < int(us.chunkSize) {
@@ -178,20 +224,30 @@ func (us *UploadStream) uploadChunks(ctx context.Context, uploadPartial bool) er
 			endIndex = us.bufferIndex
 		}
 		chunkData := us.buffer[i:endIndex]
-		docs[us.chunkIndex-begChunkIndex] = bsonx.Doc{
+		storedData := chunkData
+		if us.compression != "" {
+			if storedData, err = compressChunk(us.compression, chunkData); err != nil {
+				return err
+			}
+		}
+		docs[chunkIndex-us.chunkIndex] = bsonx.Doc{
 			{"_id", bsonx.ObjectID(primitive.NewObjectID())},
 			{"files_id", id},
-			{"n", bsonx.Int32(int32(us.chunkIndex))},
-			{"data", bsonx.Binary(0x00, chunkData)},
+			{"n", bsonx.Int32(int32(chunkIndex))},
+			{"data", bsonx.Binary(0x00, storedData)},
 		}
-		us.chunkIndex++
-		us.fileLen += int64(len(chunkData))
+		chunkIndex++
+		fileLen += int64(len(chunkData))
 	}
 
-	_, err = us.chunksColl.InsertMany(ctx, docs)
-	if err != nil {
+	if err = us.insertChunks(ctx, docs); err != nil {
 		return err
 	}
+	if us.checksum != nil {
+		_, _ = us.checksum.Write(us.buffer[:fileLen-us.fileLen])
+	}
+	us.chunkIndex = chunkIndex
+	us.fileLen = fileLen
 
 	// copy any remaining bytes to beginning of buffer and set buffer index
 	bytesUploaded := numChunks * int(us.chunkSize)
@@ -202,6 +258,36 @@ func (us *UploadStream) uploadChunks(ctx context.Context, uploadPartial bool) er
 	return nil
 }
 
+// insertChunks inserts chunk documents into the chunks collection. If the upload has a concurrency greater than 1, the
+// documents are split between that many concurrent inserts.
+func (us *UploadStream) insertChunks(ctx context.Context, docs []interface{}) error {
+	parts := us.concurrency
+	if parts > len(docs) {
+		parts = len(docs)
+	}
+	if parts <= 1 {
+		_, err := us.chunksColl.InsertMany(ctx, docs)
+		return err
+	}
+
+	errs := make(chan error, parts)
+	for i := 0; i < parts; i++ {
+		part := docs[i*len(docs)/parts : (i+1)*len(docs)/parts]
+		go func() {
+			_, err := us.chunksColl.InsertMany(ctx, part)
+			errs <- err
+		}()
+	}
+
+	var err error
+	for i := 0; i < parts; i++ {
+		if partErr := <-errs; partErr != nil && err == nil {
+			err = partErr
+		}
+	}
+	return err
+}
+
 func (us *UploadStream) createFilesCollDoc(ctx context.Context) error {
 	id, err := convertFileID(us.FileID)
 	if err != nil {
@@ -218,6 +304,12 @@ func (us *UploadStream) createFilesCollDoc(ctx context.Context) error {
 	if us.metadata != nil {
 		doc = append(doc, bsonx.Elem{"metadata", bsonx.Document(us.metadata)})
 	}
+	if us.checksum != nil {
+		doc = append(doc, bsonx.Elem{"checksum", checksumDoc(us.checksumAlgorithm, us.checksum.Sum(nil))})
+	}
+	if us.compression != "" {
+		doc = append(doc, bsonx.Elem{"compression", bsonx.String(us.compression)})
+	}
 
 	_, err = us.filesColl.InsertOne(ctx, doc)
 	if err != nil {
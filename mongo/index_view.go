@@ -129,7 +129,11 @@ func (iv IndexView) List(ctx context.Context, opts ...*options.ListIndexesOption
 		return nil, replaceErrors(err)
 	}
 	cursor, err := newCursorWithSession(bc, iv.coll.registry, sess)
-	return cursor, replaceErrors(err)
+	if err != nil {
+		return nil, replaceErrors(err)
+	}
+	iv.coll.client.cursorTracker.track(cursor, namespace(iv.coll.db.name, iv.coll.name))
+	return cursor, nil
 }
 
 // CreateOne executes a createIndexes command to create an index on the collection and returns the name of the new
@@ -218,7 +222,7 @@ func (iv IndexView) CreateMany(ctx context.Context, models []IndexModel, opts ..
 		return nil, err
 	}
 
-	wc := iv.coll.writeConcern
+	wc := iv.coll.currentWriteConcern()
 	if sess.TransactionRunning() {
 		wc = nil
 	}
@@ -350,7 +354,7 @@ func (iv IndexView) drop(ctx context.Context, name string, opts ...*options.Drop
 		return nil, err
 	}
 
-	wc := iv.coll.writeConcern
+	wc := iv.coll.currentWriteConcern()
 	if sess.TransactionRunning() {
 		wc = nil
 	}
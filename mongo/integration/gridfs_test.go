@@ -9,16 +9,21 @@ package integration
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
 	"math/rand"
 	"runtime"
 	"testing"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/internal/testutil/assert"
 	"go.mongodb.org/mongo-driver/internal/testutil/israce"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/gridfs"
-	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/mongo/mtest"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/x/bsonx"
 )
@@ -45,6 +50,349 @@ func TestGridFS(x *testing.T) {
 		findIndex(findCtx, mt, mt.DB.Collection("fs.files"), false, "key", "filename")
 		findIndex(findCtx, mt, mt.DB.Collection("fs.chunks"), true, "key", "files_id")
 	})
+	mt.Run("seek and read at", func(mt *mtest.T) {
+		chunkSize := int32(4)
+		bucket, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(chunkSize))
+		assert.Nil(mt, err, "NewBucket error: %v", err)
+
+		data := []byte("abcdefghijklmnopqrstuvwxyz")
+		fileID, err := bucket.UploadFromStream("alphabet", bytes.NewReader(data))
+		assert.Nil(mt, err, "UploadFromStream error: %v", err)
+
+		ds, err := bucket.OpenDownloadStream(fileID)
+		assert.Nil(mt, err, "OpenDownloadStream error: %v", err)
+		defer func() {
+			_ = ds.Close()
+		}()
+
+		// Read part of the first chunk, then seek into a later chunk.
+		p := make([]byte, 2)
+		_, err = io.ReadFull(ds, p)
+		assert.Nil(mt, err, "ReadFull error: %v", err)
+		pos, err := ds.Seek(10, io.SeekStart)
+		assert.Nil(mt, err, "Seek error: %v", err)
+		assert.Equal(mt, int64(10), pos, "expected position 10, got %v", pos)
+		p = make([]byte, 5)
+		_, err = io.ReadFull(ds, p)
+		assert.Nil(mt, err, "ReadFull error: %v", err)
+		assert.Equal(mt, "klmno", string(p), "unexpected data after seek")
+
+		// Seek backwards relative to the end of the file and read to the end.
+		_, err = ds.Seek(-3, io.SeekEnd)
+		assert.Nil(mt, err, "Seek error: %v", err)
+		rest, err := ioutil.ReadAll(ds)
+		assert.Nil(mt, err, "ReadAll error: %v", err)
+		assert.Equal(mt, "xyz", string(rest), "unexpected data at end of file")
+
+		// ReadAt does not depend on the position of the stream.
+		p = make([]byte, 6)
+		n, err := ds.ReadAt(p, 7)
+		assert.Nil(mt, err, "ReadAt error: %v", err)
+		assert.Equal(mt, "hijklm", string(p[:n]), "unexpected data from ReadAt")
+		n, err = ds.ReadAt(p, 23)
+		assert.Equal(mt, io.EOF, err, "expected io.EOF, got %v", err)
+		assert.Equal(mt, "xyz", string(p[:n]), "unexpected data from ReadAt at end of file")
+
+		// Seeking back to the start re-reads the whole file.
+		_, err = ds.Seek(0, io.SeekStart)
+		assert.Nil(mt, err, "Seek error: %v", err)
+		all, err := ioutil.ReadAll(ds)
+		assert.Nil(mt, err, "ReadAll error: %v", err)
+		assert.Equal(mt, data, all, "unexpected data after seeking to start")
+	})
+	mt.Run("download range", func(mt *mtest.T) {
+		bucket, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(4))
+		assert.Nil(mt, err, "NewBucket error: %v", err)
+
+		data := []byte("abcdefghijklmnopqrstuvwxyz")
+		fileID, err := bucket.UploadFromStream("alphabet", bytes.NewReader(data))
+		assert.Nil(mt, err, "UploadFromStream error: %v", err)
+
+		testCases := []struct {
+			name           string
+			offset, length int64
+			expected       string
+		}{
+			{"within a chunk", 5, 2, "fg"},
+			{"across chunks", 3, 10, "defghijklm"},
+			{"end of file", 20, 6, "uvwxyz"},
+			{"whole file", 0, 26, string(data)},
+			{"empty", 8, 0, ""},
+		}
+		for _, tc := range testCases {
+			mt.Run(tc.name, func(mt *mtest.T) {
+				ds, err := bucket.DownloadRange(fileID, tc.offset, tc.length)
+				assert.Nil(mt, err, "DownloadRange error: %v", err)
+				defer func() {
+					_ = ds.Close()
+				}()
+
+				got, err := ioutil.ReadAll(ds)
+				assert.Nil(mt, err, "ReadAll error: %v", err)
+				assert.Equal(mt, tc.expected, string(got), "unexpected range")
+			})
+		}
+
+		_, err = bucket.DownloadRange(fileID, 20, 7)
+		assert.Equal(mt, gridfs.ErrInvalidRange, err, "expected ErrInvalidRange, got %v", err)
+	})
+	mt.Run("metadata", func(mt *mtest.T) {
+		bucket, err := gridfs.NewBucket(mt.DB)
+		assert.Nil(mt, err, "NewBucket error: %v", err)
+
+		uploadOpts := options.GridFSUpload().SetMetadata(bson.D{{"owner", "alice"}, {"tag", "draft"}})
+		fileID, err := bucket.UploadFromStream("file", bytes.NewReader([]byte("data")), uploadOpts)
+		assert.Nil(mt, err, "UploadFromStream error: %v", err)
+		metadata := func() bson.Raw {
+			file, err := mt.DB.Collection("fs.files").FindOne(mtest.Background, bson.D{{"_id", fileID}}).DecodeBytes()
+			assert.Nil(mt, err, "FindOne error: %v", err)
+			doc, _ := file.Lookup("metadata").DocumentOK()
+			return doc
+		}
+
+		err = bucket.PatchMetadata(fileID, bson.D{{"tag", "final"}, {"owner", nil}, {"size", 4}})
+		assert.Nil(mt, err, "PatchMetadata error: %v", err)
+		got := metadata()
+		assert.Equal(mt, "final", got.Lookup("tag").StringValue(), "expected tag to be updated")
+		assert.Equal(mt, int32(4), got.Lookup("size").Int32(), "expected size to be set")
+		_, err = got.LookupErr("owner")
+		assert.NotNil(mt, err, "expected owner to be removed")
+
+		err = bucket.SetMetadata(fileID, bson.D{{"replaced", true}})
+		assert.Nil(mt, err, "SetMetadata error: %v", err)
+		_, err = metadata().LookupErr("tag")
+		assert.NotNil(mt, err, "expected tag to be removed")
+		err = bucket.SetMetadata(fileID, nil)
+		assert.Nil(mt, err, "SetMetadata error: %v", err)
+		assert.Nil(mt, metadata(), "expected metadata to be removed")
+
+		err = bucket.PatchMetadata(primitive.NewObjectID(), bson.D{{"tag", "x"}})
+		assert.Equal(mt, gridfs.ErrFileNotFound, err, "expected ErrFileNotFound, got %v", err)
+	})
+	mt.RunOpts("copy and move", mtest.NewOptions().MinServerVersion("4.2"), func(mt *mtest.T) {
+		src, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(4))
+		assert.Nil(mt, err, "NewBucket error: %v", err)
+		dst, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetName("archive"))
+		assert.Nil(mt, err, "NewBucket error: %v", err)
+
+		data := []byte("copied and moved")
+		fileID, err := src.UploadFromStream("file", bytes.NewReader(data))
+		assert.Nil(mt, err, "UploadFromStream error: %v", err)
+		download := func(b *gridfs.Bucket, id interface{}) []byte {
+			var w bytes.Buffer
+			_, err := b.DownloadToStream(id, &w)
+			assert.Nil(mt, err, "DownloadToStream error: %v", err)
+			return w.Bytes()
+		}
+
+		copyID := primitive.NewObjectID()
+		err = src.CopyFile(fileID, src, copyID)
+		assert.Nil(mt, err, "CopyFile error: %v", err)
+		assert.Equal(mt, data, download(src, copyID), "unexpected copied file")
+
+		err = src.MoveFile(fileID, dst)
+		assert.Nil(mt, err, "MoveFile error: %v", err)
+		assert.Equal(mt, data, download(dst, fileID), "unexpected moved file")
+		_, err = src.OpenDownloadStream(fileID)
+		assert.Equal(mt, gridfs.ErrFileNotFound, err, "expected ErrFileNotFound, got %v", err)
+		chunks, err := mt.DB.Collection("fs.chunks").CountDocuments(mtest.Background, bson.D{{"files_id", fileID}})
+		assert.Nil(mt, err, "CountDocuments error: %v", err)
+		assert.Equal(mt, int64(0), chunks, "expected source chunks to be deleted")
+	})
+	mt.Run("cleanup", func(mt *mtest.T) {
+		bucket, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(4))
+		assert.Nil(mt, err, "NewBucket error: %v", err)
+
+		data := []byte("twelve bytes")
+		completeID, err := bucket.UploadFromStream("complete", bytes.NewReader(data))
+		assert.Nil(mt, err, "UploadFromStream error: %v", err)
+		incompleteID, err := bucket.UploadFromStream("incomplete", bytes.NewReader(data))
+		assert.Nil(mt, err, "UploadFromStream error: %v", err)
+		_, err = mt.DB.Collection("fs.chunks").DeleteOne(mtest.Background,
+			bson.D{{"files_id", incompleteID}, {"n", 2}})
+		assert.Nil(mt, err, "DeleteOne error: %v", err)
+
+		// Simulate an interrupted upload by writing chunks without a files document.
+		orphanID := primitive.NewObjectID()
+		_, err = mt.DB.Collection("fs.chunks").InsertMany(mtest.Background, []interface{}{
+			bson.D{{"files_id", orphanID}, {"n", 0}, {"data", primitive.Binary{Data: []byte("abcd")}}},
+			bson.D{{"files_id", orphanID}, {"n", 1}, {"data", primitive.Binary{Data: []byte("ef")}}},
+		})
+		assert.Nil(mt, err, "InsertMany error: %v", err)
+
+		report, err := bucket.Cleanup()
+		assert.Nil(mt, err, "Cleanup error: %v", err)
+		assert.Equal(mt, 0, len(report.OrphanedFileIDs), "expected recent chunks to be skipped by default")
+
+		noMinAge := options.GridFSCleanup().SetMinChunkAge(0)
+		report, err = bucket.Cleanup(noMinAge)
+		assert.Nil(mt, err, "Cleanup error: %v", err)
+		assert.Equal(mt, 1, len(report.OrphanedFileIDs), "expected 1 orphaned file ID, got %v", report.OrphanedFileIDs)
+		assert.Equal(mt, orphanID, report.OrphanedFileIDs[0].ObjectID(), "unexpected orphaned file ID")
+		assert.Equal(mt, int64(2), report.OrphanedChunks, "expected 2 orphaned chunks")
+		assert.Equal(mt, 1, len(report.IncompleteFileIDs), "expected 1 incomplete file, got %v", report.IncompleteFileIDs)
+		assert.Equal(mt, incompleteID, report.IncompleteFileIDs[0].ObjectID(), "unexpected incomplete file ID")
+		assert.Equal(mt, int64(0), report.DeletedChunks, "expected nothing to be deleted")
+
+		report, err = bucket.Cleanup(options.GridFSCleanup().SetDelete(true).SetBatchSize(1).SetMinChunkAge(0))
+		assert.Nil(mt, err, "Cleanup error: %v", err)
+		assert.Equal(mt, int64(4), report.DeletedChunks, "expected 4 deleted chunks")
+		assert.Equal(mt, int64(1), report.DeletedFiles, "expected 1 deleted file")
+
+		report, err = bucket.Cleanup(noMinAge)
+		assert.Nil(mt, err, "Cleanup error: %v", err)
+		assert.Equal(mt, 0, len(report.OrphanedFileIDs)+len(report.IncompleteFileIDs), "expected a clean bucket")
+		err = bucket.VerifyFile(completeID)
+		assert.Equal(mt, gridfs.ErrNoChecksum, err, "expected complete file to be kept, got %v", err)
+	})
+	mt.Run("chunk compression", func(mt *mtest.T) {
+		bucket, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(1024).
+			SetChunkCompression(gridfs.CompressionZstd))
+		assert.Nil(mt, err, "NewBucket error: %v", err)
+
+		data := bytes.Repeat([]byte("compressible "), 300)
+		for _, codec := range []string{gridfs.CompressionZstd, gridfs.CompressionGzip} {
+			fileID, err := bucket.UploadFromStream(codec, bytes.NewReader(data),
+				options.GridFSUpload().SetChunkCompression(codec))
+			assert.Nil(mt, err, "UploadFromStream error: %v", err)
+
+			file, err := mt.DB.Collection("fs.files").FindOne(mtest.Background, bson.D{{"_id", fileID}}).DecodeBytes()
+			assert.Nil(mt, err, "FindOne error: %v", err)
+			assert.Equal(mt, codec, file.Lookup("compression").StringValue(), "unexpected compression codec")
+			assert.Equal(mt, int64(len(data)), file.Lookup("length").Int64(), "expected uncompressed length")
+			chunk, err := mt.DB.Collection("fs.chunks").FindOne(mtest.Background,
+				bson.D{{"files_id", fileID}, {"n", 0}}).DecodeBytes()
+			assert.Nil(mt, err, "FindOne error: %v", err)
+			_, stored := chunk.Lookup("data").Binary()
+			assert.True(mt, len(stored) < 1024, "expected compressed chunk, got %v bytes", len(stored))
+
+			var w bytes.Buffer
+			_, err = bucket.DownloadToStream(fileID, &w)
+			assert.Nil(mt, err, "DownloadToStream error: %v", err)
+			assert.Equal(mt, data, w.Bytes(), "downloaded file did not match uploaded file")
+
+			ds, err := bucket.DownloadRange(fileID, 1020, 10)
+			assert.Nil(mt, err, "DownloadRange error: %v", err)
+			got, err := ioutil.ReadAll(ds)
+			assert.Nil(mt, err, "ReadAll error: %v", err)
+			assert.Equal(mt, data[1020:1030], got, "unexpected range across compressed chunks")
+			_ = ds.Close()
+		}
+
+		_, err = bucket.OpenUploadStream("unknown", options.GridFSUpload().SetChunkCompression("lzma"))
+		assert.NotNil(mt, err, "expected error for unknown codec")
+	})
+	mt.Run("concurrent transfers", func(mt *mtest.T) {
+		bucketOpts := options.GridFSBucket().SetChunkSizeBytes(1024).SetUploadConcurrency(4).SetDownloadConcurrency(3)
+		bucket, err := gridfs.NewBucket(mt.DB, bucketOpts)
+		assert.Nil(mt, err, "NewBucket error: %v", err)
+
+		// Use a file that spans several upload and read-ahead batches and ends with a partial chunk.
+		data := make([]byte, 2*gridfs.UploadBufferSize+1500)
+		for i := range data {
+			data[i] = byte(rand.Intn(256))
+		}
+		fileID, err := bucket.UploadFromStream("large", bytes.NewReader(data))
+		assert.Nil(mt, err, "UploadFromStream error: %v", err)
+
+		var w bytes.Buffer
+		_, err = bucket.DownloadToStream(fileID, &w)
+		assert.Nil(mt, err, "DownloadToStream error: %v", err)
+		assert.True(mt, bytes.Equal(data, w.Bytes()), "downloaded file did not match uploaded file")
+
+		// Seeking backwards discards the pending batches.
+		ds, err := bucket.OpenDownloadStream(fileID)
+		assert.Nil(mt, err, "OpenDownloadStream error: %v", err)
+		defer func() {
+			_ = ds.Close()
+		}()
+		_, err = ds.Seek(int64(len(data)-100), io.SeekStart)
+		assert.Nil(mt, err, "Seek error: %v", err)
+		p := make([]byte, 100)
+		_, err = io.ReadFull(ds, p)
+		assert.Nil(mt, err, "ReadFull error: %v", err)
+		assert.True(mt, bytes.Equal(data[len(data)-100:], p), "unexpected data at end of file")
+		_, err = ds.Seek(10, io.SeekStart)
+		assert.Nil(mt, err, "Seek error: %v", err)
+		_, err = io.ReadFull(ds, p)
+		assert.Nil(mt, err, "ReadFull error: %v", err)
+		assert.True(mt, bytes.Equal(data[10:110], p), "unexpected data after seeking backwards")
+	})
+	mt.Run("resume upload", func(mt *mtest.T) {
+		bucket, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(1024))
+		assert.Nil(mt, err, "NewBucket error: %v", err)
+
+		data := make([]byte, gridfs.UploadBufferSize+1500)
+		for i := range data {
+			data[i] = byte(rand.Intn(256))
+		}
+
+		// Write more than one batch of chunks and abandon the stream without closing it.
+		us, err := bucket.OpenUploadStream("resumed")
+		assert.Nil(mt, err, "OpenUploadStream error: %v", err)
+		_, err = us.Write(data[:gridfs.UploadBufferSize+100])
+		assert.Nil(mt, err, "Write error: %v", err)
+		progress := us.Progress()
+		assert.Equal(mt, int64(gridfs.UploadBufferSize), progress.Length, "unexpected committed length")
+		assert.Equal(mt, int32(gridfs.UploadBufferSize/1024), progress.ChunksCommitted,
+			"unexpected committed chunks")
+
+		invalid := progress
+		invalid.ChunksCommitted++
+		invalid.Length += 1024
+		_, err = bucket.ResumeUploadStream(invalid)
+		assert.Equal(mt, gridfs.ErrInvalidUploadProgress, err, "expected ErrInvalidUploadProgress, got %v", err)
+
+		us, err = bucket.ResumeUploadStream(progress)
+		assert.Nil(mt, err, "ResumeUploadStream error: %v", err)
+		_, err = us.Write(data[progress.Length:])
+		assert.Nil(mt, err, "Write error: %v", err)
+		err = us.Close()
+		assert.Nil(mt, err, "Close error: %v", err)
+
+		var w bytes.Buffer
+		_, err = bucket.DownloadToStream(progress.FileID, &w)
+		assert.Nil(mt, err, "DownloadToStream error: %v", err)
+		assert.True(mt, bytes.Equal(data, w.Bytes()), "downloaded file did not match uploaded file")
+
+		_, err = bucket.ResumeUploadStream(progress)
+		assert.Equal(mt, gridfs.ErrUploadFinished, err, "expected ErrUploadFinished, got %v", err)
+	})
+	mt.Run("checksums", func(mt *mtest.T) {
+		bucket, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(4).
+			SetChecksumAlgorithm(gridfs.ChecksumSHA256))
+		assert.Nil(mt, err, "NewBucket error: %v", err)
+
+		data := []byte("hello, checksums")
+		fileID, err := bucket.UploadFromStream("checksummed", bytes.NewReader(data))
+		assert.Nil(mt, err, "UploadFromStream error: %v", err)
+		err = bucket.VerifyFile(fileID)
+		assert.Nil(mt, err, "VerifyFile error: %v", err)
+
+		sum := sha256.Sum256(data)
+		file, err := mt.DB.Collection("fs.files").FindOne(mtest.Background, bson.D{{"_id", fileID}}).DecodeBytes()
+		assert.Nil(mt, err, "FindOne error: %v", err)
+		assert.Equal(mt, gridfs.ChecksumSHA256, file.Lookup("checksum", "algorithm").StringValue(),
+			"unexpected checksum algorithm")
+		_, stored := file.Lookup("checksum", "hash").Binary()
+		assert.Equal(mt, sum[:], stored, "unexpected stored checksum")
+
+		// Corrupt a chunk without changing its size.
+		_, err = mt.DB.Collection("fs.chunks").UpdateOne(mtest.Background,
+			bson.D{{"files_id", fileID}, {"n", 1}},
+			bson.D{{"$set", bson.D{{"data", primitive.Binary{Data: []byte("XXXX")}}}}})
+		assert.Nil(mt, err, "UpdateOne error: %v", err)
+		err = bucket.VerifyFile(fileID)
+		assert.Equal(mt, gridfs.ErrChecksumMismatch, err, "expected ErrChecksumMismatch, got %v", err)
+		_, err = bucket.DownloadToStream(fileID, ioutil.Discard)
+		assert.Equal(mt, gridfs.ErrChecksumMismatch, err, "expected ErrChecksumMismatch, got %v", err)
+
+		fileID, err = bucket.UploadFromStream("unchecksummed", bytes.NewReader(data),
+			options.GridFSUpload().SetChecksumAlgorithm(""))
+		assert.Nil(mt, err, "UploadFromStream error: %v", err)
+		err = bucket.VerifyFile(fileID)
+		assert.Equal(mt, gridfs.ErrNoChecksum, err, "expected ErrNoChecksum, got %v", err)
+	})
 	mt.RunOpts("round trip", mtest.NewOptions().MaxServerVersion("3.6"), func(mt *mtest.T) {
 		skipRoundTripTest(mt)
 		oneK := 1024
@@ -11,7 +11,7 @@ import (
 	"os"
 	"testing"
 
-	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/mongo/mtest"
 )
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,66 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package mtest is an alias of go.mongodb.org/mongo-driver/mongo/mtest.
+//
+// Deprecated: Use go.mongodb.org/mongo-driver/mongo/mtest instead.
+package mtest // import "go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+import "go.mongodb.org/mongo-driver/mongo/mtest"
+
+// These types are aliases of the types in the mtest package.
+type (
+	T                     = mtest.T
+	Options               = mtest.Options
+	TopologyKind          = mtest.TopologyKind
+	ClientType            = mtest.ClientType
+	RunOnBlock            = mtest.RunOnBlock
+	Collection            = mtest.Collection
+	FailPoint             = mtest.FailPoint
+	FailPointMode         = mtest.FailPointMode
+	FailPointData         = mtest.FailPointData
+	WriteConcernErrorData = mtest.WriteConcernErrorData
+	BatchIdentifier       = mtest.BatchIdentifier
+	CommandError          = mtest.CommandError
+	WriteError            = mtest.WriteError
+	WriteConcernError     = mtest.WriteConcernError
+)
+
+// These constants are the constants of the mtest package.
+const (
+	ReplicaSet = mtest.ReplicaSet
+	Sharded    = mtest.Sharded
+	Single     = mtest.Single
+
+	Default = mtest.Default
+	Pinned  = mtest.Pinned
+	Mock    = mtest.Mock
+
+	FirstBatch = mtest.FirstBatch
+	NextBatch  = mtest.NextBatch
+
+	TestDb = mtest.TestDb
+)
+
+// These variables refer to the variables and functions of the mtest package.
+var (
+	Background = mtest.Background
+	MajorityWc = mtest.MajorityWc
+	PrimaryRp  = mtest.PrimaryRp
+	LocalRc    = mtest.LocalRc
+	MajorityRc = mtest.MajorityRc
+
+	New        = mtest.New
+	NewOptions = mtest.NewOptions
+	Setup      = mtest.Setup
+	Teardown   = mtest.Teardown
+
+	CreateCursorResponse            = mtest.CreateCursorResponse
+	CreateCommandErrorResponse      = mtest.CreateCommandErrorResponse
+	CreateWriteErrorsResponse       = mtest.CreateWriteErrorsResponse
+	CreateWriteConcernErrorResponse = mtest.CreateWriteConcernErrorResponse
+	CreateSuccessResponse           = mtest.CreateSuccessResponse
+)
@@ -0,0 +1,110 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package metrics
+
+import (
+	"expvar"
+	"runtime"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/version"
+)
+
+// PublishExpvar publishes the state of client as an expvar variable with the given name, so that it is served with the
+// other variables of the process by the /debug/vars endpoint of the expvar package:
+//
+//	metrics.PublishExpvar("mongodb", client)
+//
+// The variable is a JSON object that is computed each time it is read. It contains the driver and Go versions, the
+// topology description, the statistics of each connection pool, the heartbeat round trip times of each server, the
+// number of sessions in progress, and the number of open cursors. Durations are reported in milliseconds.
+//
+// Like expvar.Publish, PublishExpvar panics if a variable with the name has already been published.
+func PublishExpvar(name string, client *mongo.Client) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return debugVars(client.TopologyDescription(), client.PoolStats(), client.RTTStats(),
+			client.NumberSessionsInProgress(), len(client.OpenCursors()))
+	}))
+}
+
+func debugVars(topo event.TopologyDescription, pools []event.PoolStats, rtts []event.RTTStats, sessions,
+	cursors int) map[string]interface{} {
+
+	servers := make([]map[string]interface{}, 0, len(topo.Servers))
+	for _, desc := range topo.Servers {
+		server := map[string]interface{}{
+			"address":        desc.Address,
+			"kind":           desc.Kind,
+			"setName":        desc.SetName,
+			"averageRTTMS":   milliseconds(desc.AverageRTT),
+			"lastUpdateTime": desc.LastUpdateTime,
+			"minWireVersion": desc.MinWireVersion,
+			"maxWireVersion": desc.MaxWireVersion,
+		}
+		if desc.LastError != nil {
+			server["lastError"] = desc.LastError.Error()
+		}
+		servers = append(servers, server)
+	}
+
+	poolVars := make([]map[string]interface{}, 0, len(pools))
+	for _, stats := range pools {
+		poolVars = append(poolVars, map[string]interface{}{
+			"address":           stats.Address,
+			"inUse":             stats.InUse,
+			"idle":              stats.Idle,
+			"pending":           stats.Pending,
+			"waitQueueLength":   stats.WaitQueueLength,
+			"checkOutWaitP50MS": milliseconds(stats.CheckOutWaitP50),
+			"checkOutWaitP90MS": milliseconds(stats.CheckOutWaitP90),
+			"checkOutWaitP99MS": milliseconds(stats.CheckOutWaitP99),
+			"waitQueueP50MS":    milliseconds(stats.WaitQueueP50),
+			"waitQueueP90MS":    milliseconds(stats.WaitQueueP90),
+			"waitQueueP99MS":    milliseconds(stats.WaitQueueP99),
+			"establishP50MS":    milliseconds(stats.EstablishP50),
+			"establishP90MS":    milliseconds(stats.EstablishP90),
+			"establishP99MS":    milliseconds(stats.EstablishP99),
+			"created":           stats.Created,
+			"closed":            stats.Closed,
+		})
+	}
+
+	rttVars := make([]map[string]interface{}, 0, len(rtts))
+	for _, stats := range rtts {
+		rttVars = append(rttVars, map[string]interface{}{
+			"address":   stats.Address,
+			"currentMS": milliseconds(stats.Current),
+			"averageMS": milliseconds(stats.Average),
+			"minMS":     milliseconds(stats.Min),
+			"p90MS":     milliseconds(stats.P90),
+			"samples":   stats.Samples,
+		})
+	}
+
+	return map[string]interface{}{
+		"build": map[string]interface{}{
+			"driverVersion": version.Driver,
+			"goVersion":     runtime.Version(),
+			"os":            runtime.GOOS,
+			"arch":          runtime.GOARCH,
+		},
+		"topology": map[string]interface{}{
+			"kind":    topo.Kind,
+			"servers": servers,
+		},
+		"pools":              poolVars,
+		"rtt":                rttVars,
+		"sessionsInProgress": sessions,
+		"openCursors":        cursors,
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
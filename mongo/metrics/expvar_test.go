@@ -0,0 +1,69 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/version"
+)
+
+func TestExpvar(t *testing.T) {
+	t.Run("debugVars", func(t *testing.T) {
+		topo := event.TopologyDescription{
+			Kind: "ReplicaSetNoPrimary",
+			Servers: []event.ServerDescription{
+				{Address: "a:27017", Kind: "Unknown", LastError: errors.New("connection refused")},
+			},
+		}
+		pools := []event.PoolStats{{Address: "a:27017", InUse: 2, CheckOutWaitP99: 1500 * time.Microsecond}}
+		rtts := []event.RTTStats{{Address: "a:27017", Average: 3 * time.Millisecond, Samples: 4}}
+
+		b, err := json.Marshal(debugVars(topo, pools, rtts, 5, 1))
+		assert.Nil(t, err, "Marshal error: %v", err)
+		var got struct {
+			Build struct {
+				DriverVersion string
+			}
+			Topology struct {
+				Kind    string
+				Servers []map[string]interface{}
+			}
+			Pools              []map[string]interface{}
+			RTT                []map[string]interface{}
+			SessionsInProgress int
+			OpenCursors        int
+		}
+		err = json.Unmarshal(b, &got)
+		assert.Nil(t, err, "Unmarshal error: %v", err)
+
+		assert.Equal(t, version.Driver, got.Build.DriverVersion, "unexpected driver version")
+		assert.Equal(t, "ReplicaSetNoPrimary", got.Topology.Kind, "unexpected topology kind")
+		assert.Equal(t, "connection refused", got.Topology.Servers[0]["lastError"], "unexpected last error")
+		assert.Equal(t, float64(2), got.Pools[0]["inUse"], "unexpected connections in use")
+		assert.Equal(t, 1.5, got.Pools[0]["checkOutWaitP99MS"], "unexpected check out wait")
+		assert.Equal(t, float64(3), got.RTT[0]["averageMS"], "unexpected average RTT")
+		assert.Equal(t, 5, got.SessionsInProgress, "unexpected sessions in progress")
+		assert.Equal(t, 1, got.OpenCursors, "unexpected open cursors")
+	})
+	t.Run("PublishExpvar", func(t *testing.T) {
+		client, err := mongo.NewClient()
+		assert.Nil(t, err, "NewClient error: %v", err)
+
+		PublishExpvar("mongodb-test", client)
+		v := expvar.Get("mongodb-test")
+		assert.NotNil(t, v, "expected variable to be published")
+		assert.True(t, json.Valid([]byte(v.String())), "expected JSON, got %q", v.String())
+	})
+}
@@ -0,0 +1,226 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package metrics collects metrics about the operations, connection pools, and heartbeats of a mongo.Client and
+// reports them to an Exporter.
+//
+// A Collector measures operations and connection check outs through command and pool monitors, which must be set on
+// the client options, and samples the connection pools and heartbeat round trip times of a connected Client at a
+// fixed interval:
+//
+//	exp := metrics.NewPrometheusExporter()
+//	col := metrics.NewCollector(exp)
+//	opts := options.Client().ApplyURI(uri).SetMonitor(col.CommandMonitor(nil)).SetPoolMonitor(col.PoolMonitor(nil))
+//	client, err := mongo.Connect(ctx, opts)
+//	if err != nil { return err }
+//	col.Start(client, 15*time.Second)
+//	defer col.Stop()
+//
+//	http.Handle("/metrics", exp)
+//
+// Two exporters are provided. PrometheusExporter keeps the metrics in memory and serves them in the Prometheus text
+// exposition format. OTelExporter records the metrics with OpenTelemetry instruments, using the names of the
+// OpenTelemetry semantic conventions for database clients. Other monitoring systems can be supported by implementing
+// Exporter.
+//
+// PublishExpvar publishes the state of a Client, such as its topology description and connection pool statistics, as an
+// expvar variable for processes that already expose /debug/vars.
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Names of the metrics reported by a Collector.
+const (
+	// OperationDuration is a histogram of the time taken by each command, in seconds. Its labels are command, address,
+	// and status, which is "success" or "failure".
+	OperationDuration = "mongodb_operation_duration_seconds"
+	// OperationErrors counts failed commands. Its labels are command, address, and code, which is the server error
+	// code or "0" if the command failed without a server error, e.g. because of a network error.
+	OperationErrors = "mongodb_operation_errors_total"
+	// PoolConnections is the number of connections in the pool for a server. Its labels are address and state, which
+	// is "in_use", "idle", or "pending".
+	PoolConnections = "mongodb_pool_connections"
+	// PoolWaitQueueLength is the number of connection check outs waiting for a connection to a server. Its label is
+	// address.
+	PoolWaitQueueLength = "mongodb_pool_wait_queue_length"
+	// PoolCheckOutDuration is a histogram of the time taken by each successful connection check out from the pool for
+	// a server, in seconds. Its label is address.
+	PoolCheckOutDuration = "mongodb_pool_checkout_duration_seconds"
+	// PoolWaitQueueDuration is a histogram of the time spent in the wait queue by each successful connection check
+	// out, in seconds. Its label is address.
+	PoolWaitQueueDuration = "mongodb_pool_wait_queue_duration_seconds"
+	// PoolEstablishDuration is a histogram of the time taken to establish each new connection used by a check out,
+	// including the connection handshake and authentication, in seconds. Its label is address.
+	PoolEstablishDuration = "mongodb_pool_establish_duration_seconds"
+	// HeartbeatRTT is the round trip time of the most recent successful heartbeat to a server, in seconds. Its label is
+	// address.
+	HeartbeatRTT = "mongodb_heartbeat_rtt_seconds"
+	// HeartbeatRTTAverage is the moving average of the heartbeat round trip time to a server that is used for server
+	// selection, in seconds. Its label is address.
+	HeartbeatRTTAverage = "mongodb_heartbeat_rtt_average_seconds"
+)
+
+// Labels are the dimensions of a metric value, keyed by label name.
+type Labels map[string]string
+
+// Exporter receives the metric values measured by a Collector. Implementations must be safe for concurrent use.
+type Exporter interface {
+	// ObserveHistogram records a value in the histogram with the given name and labels.
+	ObserveHistogram(name string, labels Labels, value float64)
+	// SetGauge sets the value of the gauge with the given name and labels.
+	SetGauge(name string, labels Labels, value float64)
+	// AddCounter adds delta to the counter with the given name and labels.
+	AddCounter(name string, labels Labels, delta float64)
+}
+
+// Collector measures the operations, connection pools, and heartbeats of a Client and reports them to an Exporter.
+type Collector struct {
+	exporter Exporter
+
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewCollector creates a Collector that reports metrics to exporter.
+func NewCollector(exporter Exporter) *Collector {
+	return &Collector{
+		exporter: exporter,
+		done:     make(chan struct{}),
+	}
+}
+
+// CommandMonitor returns a command monitor that measures the commands run by a Client. The returned monitor also
+// passes every event to next, if it is not nil, so that it can be combined with an existing monitor. The filter,
+// sampler, and redaction settings of next are kept, so metrics only measure the commands whose events are published.
+func (c *Collector) CommandMonitor(next *event.CommandMonitor) *event.CommandMonitor {
+	if next == nil {
+		next = &event.CommandMonitor{}
+	}
+	return &event.CommandMonitor{
+		IncludeWireMessages: next.IncludeWireMessages,
+		Filter:              next.Filter,
+		Sampler:             next.Sampler,
+		RedactedCommands:    next.RedactedCommands,
+		Redact:              next.Redact,
+		Started:             next.Started,
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			c.observeCommand(evt.CommandFinishedEvent, "success")
+			if next.Succeeded != nil {
+				next.Succeeded(ctx, evt)
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			c.observeCommand(evt.CommandFinishedEvent, "failure")
+			c.exporter.AddCounter(OperationErrors, Labels{
+				"command": evt.CommandName,
+				"address": connectionAddress(evt.ConnectionID),
+				"code":    strconv.Itoa(int(evt.Code)),
+			}, 1)
+			if next.Failed != nil {
+				next.Failed(ctx, evt)
+			}
+		},
+	}
+}
+
+// PoolMonitor returns a pool monitor that measures the connection check outs of a Client. The returned monitor also
+// passes every event to next, if it is not nil, so that it can be combined with an existing monitor. The filter,
+// sampler of next are kept, so metrics only measure the check outs whose events are published.
+func (c *Collector) PoolMonitor(next *event.PoolMonitor) *event.PoolMonitor {
+	if next == nil {
+		next = &event.PoolMonitor{}
+	}
+	return &event.PoolMonitor{
+		Filter:  next.Filter,
+		Sampler: next.Sampler,
+		Event: func(evt *event.PoolEvent) {
+			if evt.Type == event.GetSucceeded {
+				labels := Labels{"address": evt.Address}
+				c.exporter.ObserveHistogram(PoolCheckOutDuration, labels, evt.Duration.Seconds())
+				c.exporter.ObserveHistogram(PoolWaitQueueDuration, labels, evt.WaitQueueDuration.Seconds())
+				if evt.EstablishDuration > 0 {
+					c.exporter.ObserveHistogram(PoolEstablishDuration, labels, evt.EstablishDuration.Seconds())
+				}
+			}
+			if next.Event != nil {
+				next.Event(evt)
+			}
+		},
+	}
+}
+
+func (c *Collector) observeCommand(evt event.CommandFinishedEvent, status string) {
+	c.exporter.ObserveHistogram(OperationDuration, Labels{
+		"command": evt.CommandName,
+		"address": connectionAddress(evt.ConnectionID),
+		"status":  status,
+	}, time.Duration(evt.DurationNanos).Seconds())
+}
+
+// Collect samples the connection pools and heartbeat round trip times of client.
+func (c *Collector) Collect(client *mongo.Client) {
+	c.record(client.PoolStats(), client.RTTStats())
+}
+
+func (c *Collector) record(pools []event.PoolStats, rtts []event.RTTStats) {
+	for _, stats := range pools {
+		for state, n := range map[string]uint64{"in_use": stats.InUse, "idle": stats.Idle, "pending": stats.Pending} {
+			c.exporter.SetGauge(PoolConnections, Labels{"address": stats.Address, "state": state}, float64(n))
+		}
+		c.exporter.SetGauge(PoolWaitQueueLength, Labels{"address": stats.Address}, float64(stats.WaitQueueLength))
+	}
+	for _, stats := range rtts {
+		if stats.Samples == 0 {
+			continue
+		}
+		c.exporter.SetGauge(HeartbeatRTT, Labels{"address": stats.Address}, stats.Current.Seconds())
+		c.exporter.SetGauge(HeartbeatRTTAverage, Labels{"address": stats.Address}, stats.Average.Seconds())
+	}
+}
+
+// Start samples the connection pools and heartbeat round trip times of client every interval until Stop is called.
+// Start must be called at most once.
+func (c *Collector) Start(client *mongo.Client, interval time.Duration) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			c.Collect(client)
+			select {
+			case <-ticker.C:
+			case <-c.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the sampling started by Start and waits for it to finish.
+func (c *Collector) Stop() {
+	c.stopOnce.Do(func() { close(c.done) })
+	c.wg.Wait()
+}
+
+// connectionAddress returns the address of the server from a connection ID of the form "host:port[-N]".
+func connectionAddress(connID string) string {
+	if idx := strings.LastIndex(connID, "[-"); idx >= 0 {
+		return connID[:idx]
+	}
+	return connID
+}
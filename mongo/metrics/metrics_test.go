@@ -0,0 +1,171 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+type sample struct {
+	kind   string
+	name   string
+	labels Labels
+	value  float64
+}
+
+type testExporter struct {
+	mu      sync.Mutex
+	samples []sample
+}
+
+func (e *testExporter) add(kind, name string, labels Labels, value float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.samples = append(e.samples, sample{kind, name, labels, value})
+}
+
+func (e *testExporter) ObserveHistogram(name string, labels Labels, value float64) {
+	e.add(kindHistogram, name, labels, value)
+}
+func (e *testExporter) SetGauge(name string, labels Labels, value float64) {
+	e.add(kindGauge, name, labels, value)
+}
+func (e *testExporter) AddCounter(name string, labels Labels, delta float64) {
+	e.add(kindCounter, name, labels, delta)
+}
+
+func (e *testExporter) find(name string, labels Labels) (sample, bool) {
+	for _, s := range e.samples {
+		if s.name == name && fmt.Sprint(s.labels) == fmt.Sprint(labels) {
+			return s, true
+		}
+	}
+	return sample{}, false
+}
+
+func TestCollector(t *testing.T) {
+	t.Run("command monitor", func(t *testing.T) {
+		exp := new(testExporter)
+		var failed int
+		monitor := NewCollector(exp).CommandMonitor(&event.CommandMonitor{
+			Failed: func(context.Context, *event.CommandFailedEvent) { failed++ },
+		})
+
+		finished := event.CommandFinishedEvent{
+			CommandName:   "find",
+			ConnectionID:  "db.example.com:27017[-3]",
+			DurationNanos: int64(250 * time.Millisecond),
+		}
+		monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+		monitor.Failed(context.Background(), &event.CommandFailedEvent{CommandFinishedEvent: finished, Code: 11600})
+		assert.Equal(t, 1, failed, "expected event to be passed to the next monitor")
+
+		s, ok := exp.find(OperationDuration, Labels{"command": "find", "address": "db.example.com:27017", "status": "success"})
+		assert.True(t, ok, "expected a successful operation duration sample")
+		assert.Equal(t, 0.25, s.value, "unexpected duration")
+		_, ok = exp.find(OperationDuration, Labels{"command": "find", "address": "db.example.com:27017", "status": "failure"})
+		assert.True(t, ok, "expected a failed operation duration sample")
+		s, ok = exp.find(OperationErrors, Labels{"command": "find", "address": "db.example.com:27017", "code": "11600"})
+		assert.True(t, ok, "expected an operation error sample")
+		assert.Equal(t, float64(1), s.value, "unexpected error count")
+	})
+	t.Run("pool monitor", func(t *testing.T) {
+		exp := new(testExporter)
+		var events int
+		monitor := NewCollector(exp).PoolMonitor(&event.PoolMonitor{
+			Event: func(*event.PoolEvent) { events++ },
+		})
+
+		monitor.Event(&event.PoolEvent{
+			Type:              event.GetSucceeded,
+			Address:           "a:27017",
+			Duration:          300 * time.Millisecond,
+			WaitQueueDuration: 100 * time.Millisecond,
+		})
+		monitor.Event(&event.PoolEvent{Type: event.ConnectionCreated, Address: "a:27017"})
+		assert.Equal(t, 2, events, "expected events to be passed to the next monitor")
+
+		s, ok := exp.find(PoolCheckOutDuration, Labels{"address": "a:27017"})
+		assert.True(t, ok, "expected a check out duration sample")
+		assert.Equal(t, 0.3, s.value, "unexpected check out duration")
+		s, _ = exp.find(PoolWaitQueueDuration, Labels{"address": "a:27017"})
+		assert.Equal(t, 0.1, s.value, "unexpected wait queue duration")
+		_, ok = exp.find(PoolEstablishDuration, Labels{"address": "a:27017"})
+		assert.False(t, ok, "expected no establish duration sample for an idle connection")
+	})
+	t.Run("pools and heartbeats", func(t *testing.T) {
+		exp := new(testExporter)
+		NewCollector(exp).record(
+			[]event.PoolStats{{Address: "a:27017", InUse: 3, Idle: 2, Pending: 1, WaitQueueLength: 4}},
+			[]event.RTTStats{
+				{Address: "a:27017", Current: 20 * time.Millisecond, Average: 10 * time.Millisecond, Samples: 5},
+				{Address: "b:27017"},
+			},
+		)
+
+		for state, want := range map[string]float64{"in_use": 3, "idle": 2, "pending": 1} {
+			s, ok := exp.find(PoolConnections, Labels{"address": "a:27017", "state": state})
+			assert.True(t, ok, "expected a %s connections sample", state)
+			assert.Equal(t, want, s.value, "unexpected %s connections", state)
+		}
+		s, _ := exp.find(PoolWaitQueueLength, Labels{"address": "a:27017"})
+		assert.Equal(t, float64(4), s.value, "unexpected wait queue length")
+		s, _ = exp.find(HeartbeatRTT, Labels{"address": "a:27017"})
+		assert.Equal(t, 0.02, s.value, "unexpected heartbeat RTT")
+		s, _ = exp.find(HeartbeatRTTAverage, Labels{"address": "a:27017"})
+		assert.Equal(t, 0.01, s.value, "unexpected average heartbeat RTT")
+		_, ok := exp.find(HeartbeatRTT, Labels{"address": "b:27017"})
+		assert.False(t, ok, "expected no heartbeat RTT sample for a server without measurements")
+	})
+}
+
+type testRecorder struct {
+	name, unit string
+	exp        *testExporter
+}
+
+func (r *testRecorder) Record(value float64, labels Labels) {
+	r.exp.add("", r.name, labels, value)
+}
+
+type testMeter struct {
+	exp *testExporter
+}
+
+func (m testMeter) Float64Histogram(name, unit, _ string) (Recorder, error) {
+	return &testRecorder{name, unit, m.exp}, nil
+}
+func (m testMeter) Float64Gauge(name, unit, _ string) (Recorder, error) {
+	return &testRecorder{name, unit, m.exp}, nil
+}
+func (m testMeter) Float64Counter(name, unit, _ string) (Recorder, error) {
+	return nil, fmt.Errorf("counters not supported")
+}
+
+func TestOTelExporter(t *testing.T) {
+	exp := new(testExporter)
+	otel := NewOTelExporter(testMeter{exp})
+
+	otel.ObserveHistogram(OperationDuration, Labels{"command": "find", "address": "a:27017", "status": "success"}, 0.5)
+	otel.SetGauge("custom_gauge", Labels{"other": "x"}, 2)
+	otel.AddCounter(OperationErrors, Labels{"command": "find"}, 1)
+
+	assert.Equal(t, 2, len(exp.samples), "expected 2 samples, got %d", len(exp.samples))
+	s, ok := exp.find("db.client.operation.duration",
+		Labels{"db.operation.name": "find", "server.address": "a:27017", "db.mongodb.status": "success"})
+	assert.True(t, ok, "expected operation duration to be recorded with semantic convention names")
+	assert.Equal(t, 0.5, s.value, "unexpected duration")
+	_, ok = exp.find("custom_gauge", Labels{"other": "x"})
+	assert.True(t, ok, "expected unknown metric to be recorded with its own name")
+}
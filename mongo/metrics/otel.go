@@ -0,0 +1,120 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package metrics
+
+import "sync"
+
+// Recorder records values for a single OpenTelemetry instrument. For a counter, the value is added to the counter;
+// for a histogram or gauge, the value is recorded as a measurement.
+type Recorder interface {
+	Record(value float64, labels Labels)
+}
+
+// Meter creates OpenTelemetry instruments. The driver does not depend on OpenTelemetry, so applications implement
+// Meter by wrapping an OpenTelemetry metric.Meter: each method creates the corresponding Float64 instrument with the
+// given name, unit, and description, and the returned Recorder converts labels to attribute.KeyValue pairs.
+type Meter interface {
+	Float64Histogram(name, unit, description string) (Recorder, error)
+	Float64Gauge(name, unit, description string) (Recorder, error)
+	Float64Counter(name, unit, description string) (Recorder, error)
+}
+
+type otelInstrument struct {
+	name        string
+	unit        string
+	description string
+}
+
+// otelInstruments maps the metrics reported by a Collector to the OpenTelemetry semantic conventions for database
+// clients where one exists.
+var otelInstruments = map[string]otelInstrument{
+	OperationDuration:     {"db.client.operation.duration", "s", "Duration of database client operations."},
+	OperationErrors:       {"db.client.operation.errors", "{error}", "Number of failed database client operations."},
+	PoolConnections:       {"db.client.connection.count", "{connection}", "Number of connections in each state."},
+	PoolWaitQueueLength:   {"db.client.connection.pending_requests", "{request}", "Number of pending connection requests."},
+	PoolCheckOutDuration:  {"db.client.connection.wait_time", "s", "Time it took to obtain an open connection from the pool."},
+	PoolWaitQueueDuration: {"mongodb.pool.wait_queue.duration", "s", "Time connection requests spent in the wait queue."},
+	PoolEstablishDuration: {"db.client.connection.create_time", "s", "Time it took to create a new connection."},
+	HeartbeatRTT:          {"mongodb.heartbeat.rtt", "s", "Round trip time of the most recent server heartbeat."},
+	HeartbeatRTTAverage:   {"mongodb.heartbeat.rtt.average", "s", "Moving average of the server heartbeat round trip time."},
+}
+
+// otelLabels maps the labels reported by a Collector to OpenTelemetry attribute names.
+var otelLabels = map[string]string{
+	"command": "db.operation.name",
+	"address": "server.address",
+	"code":    "db.response.status_code",
+	"status":  "db.mongodb.status",
+	"state":   "db.client.connection.state",
+}
+
+// OTelExporter is an Exporter that records metric values with OpenTelemetry instruments. Metric and label names are
+// translated to the OpenTelemetry semantic conventions for database clients where one exists, e.g. OperationDuration
+// is recorded by the "db.client.operation.duration" histogram. An OTelExporter is safe for concurrent use.
+type OTelExporter struct {
+	meter Meter
+
+	mu          sync.Mutex
+	instruments map[string]Recorder
+}
+
+var _ Exporter = (*OTelExporter)(nil)
+
+// NewOTelExporter creates an OTelExporter that creates its instruments with meter. Instruments are created when a
+// metric is first reported. If meter returns an error, values for that metric are dropped.
+func NewOTelExporter(meter Meter) *OTelExporter {
+	return &OTelExporter{
+		meter:       meter,
+		instruments: make(map[string]Recorder),
+	}
+}
+
+// ObserveHistogram implements the Exporter interface.
+func (e *OTelExporter) ObserveHistogram(name string, labels Labels, value float64) {
+	e.record(name, e.meter.Float64Histogram, labels, value)
+}
+
+// SetGauge implements the Exporter interface.
+func (e *OTelExporter) SetGauge(name string, labels Labels, value float64) {
+	e.record(name, e.meter.Float64Gauge, labels, value)
+}
+
+// AddCounter implements the Exporter interface.
+func (e *OTelExporter) AddCounter(name string, labels Labels, delta float64) {
+	e.record(name, e.meter.Float64Counter, labels, delta)
+}
+
+func (e *OTelExporter) record(name string, create func(name, unit, description string) (Recorder, error),
+	labels Labels, value float64) {
+
+	e.mu.Lock()
+	rec, ok := e.instruments[name]
+	if !ok {
+		inst, known := otelInstruments[name]
+		if !known {
+			inst = otelInstrument{name: name}
+		}
+		var err error
+		if rec, err = create(inst.name, inst.unit, inst.description); err != nil {
+			rec = nil
+		}
+		e.instruments[name] = rec
+	}
+	e.mu.Unlock()
+	if rec == nil {
+		return
+	}
+
+	attrs := make(Labels, len(labels))
+	for k, v := range labels {
+		if mapped, ok := otelLabels[k]; ok {
+			k = mapped
+		}
+		attrs[k] = v
+	}
+	rec.Record(value, attrs)
+}
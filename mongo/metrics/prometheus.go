@@ -0,0 +1,204 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the histogram buckets used by a PrometheusExporter if no
+// buckets are given.
+var DefaultBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+const (
+	kindHistogram = "histogram"
+	kindGauge     = "gauge"
+	kindCounter   = "counter"
+)
+
+// PrometheusExporter is an Exporter that keeps metric values in memory and serves them over HTTP in the Prometheus
+// text exposition format. A PrometheusExporter is safe for concurrent use.
+type PrometheusExporter struct {
+	buckets []float64
+
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+var _ Exporter = (*PrometheusExporter)(nil)
+var _ http.Handler = (*PrometheusExporter)(nil)
+
+type family struct {
+	kind   string
+	series map[string]*series // keyed by formatted labels
+}
+
+type series struct {
+	value  float64  // gauge or counter value, or histogram sum
+	counts []uint64 // histogram bucket counts, not cumulative
+	count  uint64   // histogram observation count
+}
+
+// NewPrometheusExporter creates a PrometheusExporter whose histograms use the given bucket upper bounds, or
+// DefaultBuckets if none are given.
+func NewPrometheusExporter(buckets ...float64) *PrometheusExporter {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	sorted := make([]float64, len(buckets))
+	copy(sorted, buckets)
+	sort.Float64s(sorted)
+
+	return &PrometheusExporter{
+		buckets:  sorted,
+		families: make(map[string]*family),
+	}
+}
+
+// ObserveHistogram implements the Exporter interface.
+func (e *PrometheusExporter) ObserveHistogram(name string, labels Labels, value float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := e.series(name, kindHistogram, labels)
+	if s.counts == nil {
+		s.counts = make([]uint64, len(e.buckets))
+	}
+	if idx := sort.SearchFloat64s(e.buckets, value); idx < len(e.buckets) {
+		s.counts[idx]++
+	}
+	s.value += value
+	s.count++
+}
+
+// SetGauge implements the Exporter interface.
+func (e *PrometheusExporter) SetGauge(name string, labels Labels, value float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.series(name, kindGauge, labels).value = value
+}
+
+// AddCounter implements the Exporter interface.
+func (e *PrometheusExporter) AddCounter(name string, labels Labels, delta float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.series(name, kindCounter, labels).value += delta
+}
+
+// series returns the series for the given metric and labels, creating it if needed. e.mu must be held.
+func (e *PrometheusExporter) series(name, kind string, labels Labels) *series {
+	f, ok := e.families[name]
+	if !ok {
+		f = &family{kind: kind, series: make(map[string]*series)}
+		e.families[name] = f
+	}
+	key := formatLabels(labels)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{}
+		f.series[key] = s
+	}
+	return s
+}
+
+// ServeHTTP writes the current metric values in the Prometheus text exposition format.
+func (e *PrometheusExporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = e.Write(w)
+}
+
+// Write writes the current metric values to w in the Prometheus text exposition format. Metrics are ordered by name
+// and series by labels.
+func (e *PrometheusExporter) Write(w io.Writer) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	names := make([]string, 0, len(e.families))
+	for name := range e.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := e.families[name]
+		bw.WriteString("# TYPE " + name + " " + f.kind + "\n")
+
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s := f.series[key]
+			if f.kind != kindHistogram {
+				writeSample(bw, name, key, "", s.value)
+				continue
+			}
+
+			var cumulative uint64
+			for i, bound := range e.buckets {
+				cumulative += s.counts[i]
+				writeSample(bw, name+"_bucket", key, formatFloat(bound), float64(cumulative))
+			}
+			writeSample(bw, name+"_bucket", key, "+Inf", float64(s.count))
+			writeSample(bw, name+"_sum", key, "", s.value)
+			writeSample(bw, name+"_count", key, "", float64(s.count))
+		}
+	}
+	return bw.Flush()
+}
+
+func writeSample(w *bufio.Writer, name, labels, le string, value float64) {
+	w.WriteString(name)
+	if le != "" {
+		if labels != "" {
+			labels += ","
+		}
+		labels += `le="` + le + `"`
+	}
+	if labels != "" {
+		w.WriteString("{" + labels + "}")
+	}
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+// formatLabels formats labels as a comma-separated list of name="value" pairs ordered by name.
+func formatLabels(labels Labels) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + labelEscaper.Replace(labels[name]) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,43 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+func TestPrometheusExporter(t *testing.T) {
+	exp := NewPrometheusExporter(0.5, 0.1)
+	exp.ObserveHistogram("op_seconds", Labels{"command": "find"}, 0.05)
+	exp.ObserveHistogram("op_seconds", Labels{"command": "find"}, 0.3)
+	exp.ObserveHistogram("op_seconds", Labels{"command": "find"}, 2)
+	exp.SetGauge("conns", Labels{"address": "a:27017"}, 4)
+	exp.SetGauge("conns", Labels{"address": "a:27017"}, 3)
+	exp.AddCounter("errors_total", Labels{"msg": "say \"hi\"\n"}, 1)
+	exp.AddCounter("errors_total", Labels{"msg": "say \"hi\"\n"}, 2)
+
+	rec := httptest.NewRecorder()
+	exp.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	want := `# TYPE conns gauge
+conns{address="a:27017"} 3
+# TYPE errors_total counter
+errors_total{msg="say \"hi\"\n"} 3
+# TYPE op_seconds histogram
+op_seconds_bucket{command="find",le="0.1"} 1
+op_seconds_bucket{command="find",le="0.5"} 2
+op_seconds_bucket{command="find",le="+Inf"} 3
+op_seconds_sum{command="find"} 2.35
+op_seconds_count{command="find"} 3
+`
+	assert.Equal(t, want, rec.Body.String(), "unexpected exposition output")
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"),
+		"unexpected content type")
+}
@@ -134,8 +134,10 @@ func transformAndEnsureID(registry *bsoncodec.Registry, val interface{}) (bsonx.
 }
 
 // transformAndEnsureIDv2 is a hack that makes it easy to get a RawValue as the _id value. This will
-// be removed when we switch from using bsonx to bsoncore for the driver package.
-func transformAndEnsureIDv2(registry *bsoncodec.Registry, val interface{}) (bsoncore.Document, interface{}, error) {
+// be removed when we switch from using bsonx to bsoncore for the driver package. If the document does not have an _id,
+// one is created with idGen, or with primitive.NewObjectID if idGen is nil.
+func transformAndEnsureIDv2(registry *bsoncodec.Registry, idGen primitive.ObjectIDGenerator,
+	val interface{}) (bsoncore.Document, interface{}, error) {
 	if registry == nil {
 		registry = bson.NewRegistryBuilder().Build()
 	}
@@ -161,7 +163,11 @@ func transformAndEnsureIDv2(registry *bsoncodec.Registry, val interface{}) (bson
 	value := doc.Lookup("_id")
 	switch value.Type {
 	case bsontype.Type(0):
-		value = bsoncore.Value{Type: bsontype.ObjectID, Data: bsoncore.AppendObjectID(nil, primitive.NewObjectID())}
+		if idGen == nil {
+			idGen = primitive.ObjectIDGeneratorFunc(primitive.NewObjectID)
+		}
+		oid := idGen.NewObjectID()
+		value = bsoncore.Value{Type: bsontype.ObjectID, Data: bsoncore.AppendObjectID(nil, oid)}
 		olddoc := doc
 		doc = make(bsoncore.Document, 0, len(olddoc)+17) // type byte + _id + null byte + object ID
 		_, doc = bsoncore.ReserveLength(doc)
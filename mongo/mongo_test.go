@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/bsoncodec"
@@ -120,6 +121,23 @@ func TestMongoHelpers(t *testing.T) {
 			assert.Equal(t, got, want, "expected document %v, got %v", got, want)
 		})
 	})
+	t.Run("transform and ensure ID with generator", func(t *testing.T) {
+		gen := primitive.NewDeterministicObjectIDGenerator(time.Unix(1600000000, 0))
+		expected := primitive.NewDeterministicObjectIDGenerator(time.Unix(1600000000, 0)).NewObjectID()
+
+		doc, id, err := transformAndEnsureIDv2(bson.DefaultRegistry, gen, bson.D{{"x", 1}})
+		assert.Nil(t, err, "transformAndEnsureIDv2 error: %v", err)
+		assert.Equal(t, expected, id, "expected _id %v, got %v", expected, id)
+		got := doc.Lookup("_id").ObjectID()
+		assert.Equal(t, expected, got, "expected document _id %v, got %v", expected, got)
+
+		// an existing _id is kept and does not consume an ObjectID
+		_, id, err = transformAndEnsureIDv2(bson.DefaultRegistry, gen, bson.D{{"_id", 1}})
+		assert.Nil(t, err, "transformAndEnsureIDv2 error: %v", err)
+		assert.Equal(t, int32(1), id, "expected _id 1, got %v", id)
+		next := gen.NewObjectID()
+		assert.Equal(t, byte(2), next[11], "expected counter 2, got %v", next[11])
+	})
 	t.Run("transform aggregate pipeline", func(t *testing.T) {
 		index, arr := bsoncore.AppendArrayStart(nil)
 		dindex, arr := bsoncore.AppendDocumentElementStart(arr, "0")
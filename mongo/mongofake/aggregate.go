@@ -0,0 +1,267 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongofake
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// applyStage applies a single aggregation pipeline stage to docs.
+func applyStage(docs []bson.D, stage bson.E) ([]bson.D, error) {
+	switch stage.Key {
+	case "$match":
+		filter, ok := stage.Value.(bson.D)
+		if !ok {
+			return nil, badValue("the match filter must be an expression in an object")
+		}
+		var matched []bson.D
+		for _, doc := range docs {
+			ok, err := matchDocument(doc, filter)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matched = append(matched, doc)
+			}
+		}
+		return matched, nil
+	case "$sort":
+		spec, ok := stage.Value.(bson.D)
+		if !ok {
+			return nil, badValue("the $sort key specification must be an object")
+		}
+		sorted := append([]bson.D(nil), docs...)
+		return sorted, sortDocuments(sorted, spec)
+	case "$skip", "$limit":
+		n, ok := intValue(stage.Value)
+		if !ok {
+			if f, isFloat := stage.Value.(float64); isFloat {
+				n, ok = int64(f), true
+			}
+		}
+		if !ok || n < 0 || (stage.Key == "$limit" && n == 0) {
+			return nil, badValue("invalid argument to %s stage: %v", stage.Key, stage.Value)
+		}
+		if stage.Key == "$skip" {
+			return skipDocuments(docs, n), nil
+		}
+		return limitDocuments(docs, n), nil
+	case "$project":
+		spec, ok := stage.Value.(bson.D)
+		if !ok {
+			return nil, badValue("$project specification must be an object")
+		}
+		projected := make([]bson.D, 0, len(docs))
+		for _, doc := range docs {
+			p, err := project(doc, spec)
+			if err != nil {
+				return nil, err
+			}
+			projected = append(projected, p)
+		}
+		return projected, nil
+	case "$count":
+		field, ok := stage.Value.(string)
+		if !ok || field == "" || strings.HasPrefix(field, "$") || strings.Contains(field, ".") {
+			return nil, badValue("the count field must be a non-empty string without '$' or '.'")
+		}
+		if len(docs) == 0 {
+			return nil, nil
+		}
+		return []bson.D{{{field, int32(len(docs))}}}, nil
+	case "$unwind":
+		path, ok := stage.Value.(string)
+		if !ok || !strings.HasPrefix(path, "$") {
+			return nil, notImplemented("only the short form of $unwind is supported")
+		}
+		path = path[1:]
+		var unwound []bson.D
+		for _, doc := range docs {
+			v, found := getPath(doc, strings.Split(path, "."))
+			arr, isArray := v.(bson.A)
+			if !found || isNull(v) || (isArray && len(arr) == 0) {
+				continue
+			}
+			if !isArray {
+				unwound = append(unwound, doc)
+				continue
+			}
+			for _, elem := range arr {
+				out, err := setPath(copyDocument(doc), strings.Split(path, "."), elem)
+				if err != nil {
+					return nil, err
+				}
+				unwound = append(unwound, out)
+			}
+		}
+		return unwound, nil
+	case "$group":
+		spec, ok := stage.Value.(bson.D)
+		if !ok {
+			return nil, badValue("a group's fields must be specified in an object")
+		}
+		return group(docs, spec)
+	default:
+		return nil, notImplemented("unsupported aggregation stage %s", stage.Key)
+	}
+}
+
+// group implements the $group stage. The groups are returned in the order of their _id values.
+func group(docs []bson.D, spec bson.D) ([]bson.D, error) {
+	idExpr, ok := lookupKey(spec, "_id")
+	if !ok {
+		return nil, badValue("a group specification must include an _id")
+	}
+	var fields bson.D
+	for _, e := range spec {
+		if e.Key == "_id" {
+			continue
+		}
+		acc, ok := e.Value.(bson.D)
+		if !ok || len(acc) != 1 {
+			return nil, badValue("the field '%s' must be an accumulator object", e.Key)
+		}
+		fields = append(fields, e)
+	}
+
+	groups := newBTree(defaultDegree, compareValues)
+	for _, doc := range docs {
+		id, err := evaluate(doc, idExpr)
+		if err != nil {
+			return nil, err
+		}
+		state, ok := groups.Get(id)
+		if !ok {
+			state = make([]accumulator, len(fields))
+			groups.Set(id, state)
+		}
+		accs := state.([]accumulator)
+		for i, f := range fields {
+			acc := f.Value.(bson.D)[0]
+			v, err := evaluate(doc, acc.Value)
+			if err != nil {
+				return nil, err
+			}
+			if err := accs[i].add(acc.Key, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var out []bson.D
+	groups.Ascend(func(id, state interface{}) bool {
+		doc := bson.D{{"_id", id}}
+		for i, f := range fields {
+			doc = append(doc, bson.E{Key: f.Key, Value: state.([]accumulator)[i].result(f.Value.(bson.D)[0].Key)})
+		}
+		out = append(out, doc)
+		return true
+	})
+	return out, nil
+}
+
+// accumulator holds the state of a $group accumulator for one group.
+type accumulator struct {
+	value interface{}
+	count int
+	set   bool
+	items bson.A
+}
+
+func (a *accumulator) add(op string, v interface{}) error {
+	switch op {
+	case "$sum", "$avg":
+		if !a.set {
+			a.value, a.set = int32(0), true
+		}
+		if isNumber(v) {
+			if a.value = addNumbers(a.value, v); a.value == nil {
+				return notImplemented("%s is not supported for decimal values", op)
+			}
+			a.count++
+		}
+	case "$min", "$max":
+		if isNull(v) {
+			return nil
+		}
+		if c := compareValues(v, a.value); !a.set || (op == "$min" && c < 0) || (op == "$max" && c > 0) {
+			a.value, a.set = v, true
+		}
+	case "$first":
+		if !a.set {
+			a.value, a.set = v, true
+		}
+	case "$last":
+		a.value, a.set = v, true
+	case "$push":
+		a.items = append(a.items, v)
+	case "$addToSet":
+		if !containsValue(a.items, v) {
+			a.items = append(a.items, v)
+		}
+	default:
+		return notImplemented("unsupported accumulator %s", op)
+	}
+	return nil
+}
+
+func (a *accumulator) result(op string) interface{} {
+	switch op {
+	case "$avg":
+		if a.count == 0 {
+			return primitive.Null{}
+		}
+		return floatValue(a.value) / float64(a.count)
+	case "$push", "$addToSet":
+		if a.items == nil {
+			return bson.A{}
+		}
+		return a.items
+	default:
+		if !a.set {
+			return primitive.Null{}
+		}
+		return a.value
+	}
+}
+
+// evaluate evaluates an aggregation expression against doc. Only field paths, literals, and documents of expressions
+// are supported.
+func evaluate(doc bson.D, expr interface{}) (interface{}, error) {
+	switch e := expr.(type) {
+	case string:
+		if !strings.HasPrefix(e, "$") {
+			return e, nil
+		}
+		v, ok := getPath(doc, strings.Split(e[1:], "."))
+		if !ok {
+			return primitive.Null{}, nil
+		}
+		return v, nil
+	case bson.D:
+		if _, ok := operatorDocument(e); ok {
+			if len(e) == 1 && e[0].Key == "$literal" {
+				return e[0].Value, nil
+			}
+			return nil, notImplemented("unsupported expression operator %s", e[0].Key)
+		}
+		out := make(bson.D, 0, len(e))
+		for _, field := range e {
+			v, err := evaluate(doc, field.Value)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, bson.E{Key: field.Key, Value: v})
+		}
+		return out, nil
+	default:
+		return expr, nil
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongofake
+
+import "sort"
+
+// defaultDegree is the minimum degree of the B-trees that store documents and index entries. Every node except the
+// root has between defaultDegree-1 and 2*defaultDegree-1 items.
+const defaultDegree = 16
+
+// btreeItem is a key-value pair stored in a btree.
+type btreeItem struct {
+	key   interface{}
+	value interface{}
+}
+
+type btreeNode struct {
+	items    []btreeItem
+	children []*btreeNode // empty for leaves
+}
+
+// btree is a B-tree that maps keys to values in the order defined by compare. It is not safe for concurrent use.
+type btree struct {
+	root    *btreeNode
+	degree  int
+	length  int
+	compare func(a, b interface{}) int
+}
+
+func newBTree(degree int, compare func(a, b interface{}) int) *btree {
+	return &btree{degree: degree, compare: compare}
+}
+
+// Len returns the number of items in the tree.
+func (t *btree) Len() int {
+	return t.length
+}
+
+// Get returns the value for key and whether the key was found.
+func (t *btree) Get(key interface{}) (interface{}, bool) {
+	for n := t.root; n != nil; {
+		i, found := n.find(key, t.compare)
+		if found {
+			return n.items[i].value, true
+		}
+		if len(n.children) == 0 {
+			break
+		}
+		n = n.children[i]
+	}
+	return nil, false
+}
+
+// Set stores value for key and reports whether an existing value was replaced.
+func (t *btree) Set(key, value interface{}) bool {
+	item := btreeItem{key: key, value: value}
+	if t.root == nil {
+		t.root = &btreeNode{items: []btreeItem{item}}
+		t.length++
+		return false
+	}
+	if len(t.root.items) == t.maxItems() {
+		old := t.root
+		t.root = &btreeNode{children: []*btreeNode{old}}
+		t.root.split(0, t.degree)
+	}
+	replaced := t.root.insert(item, t)
+	if !replaced {
+		t.length++
+	}
+	return replaced
+}
+
+// Delete removes key from the tree and reports whether it was present.
+func (t *btree) Delete(key interface{}) bool {
+	if t.root == nil {
+		return false
+	}
+	removed := t.root.remove(key, t)
+	if len(t.root.items) == 0 {
+		if len(t.root.children) > 0 {
+			t.root = t.root.children[0]
+		} else {
+			t.root = nil
+		}
+	}
+	if removed {
+		t.length--
+	}
+	return removed
+}
+
+// Ascend calls fn for every item in key order until fn returns false.
+func (t *btree) Ascend(fn func(key, value interface{}) bool) {
+	if t.root != nil {
+		t.root.ascend(nil, t.compare, fn)
+	}
+}
+
+// AscendFrom calls fn in key order for every item whose key is greater than or equal to from until fn returns false.
+func (t *btree) AscendFrom(from interface{}, fn func(key, value interface{}) bool) {
+	if t.root != nil {
+		t.root.ascend(&from, t.compare, fn)
+	}
+}
+
+// Clone returns a copy of the tree. The keys and values are shared.
+func (t *btree) Clone() *btree {
+	clone := newBTree(t.degree, t.compare)
+	t.Ascend(func(key, value interface{}) bool {
+		clone.Set(key, value)
+		return true
+	})
+	return clone
+}
+
+func (t *btree) maxItems() int {
+	return 2*t.degree - 1
+}
+
+// find returns the index of the first item whose key is greater than or equal to key and whether it is equal.
+func (n *btreeNode) find(key interface{}, compare func(a, b interface{}) int) (int, bool) {
+	i := sort.Search(len(n.items), func(i int) bool { return compare(n.items[i].key, key) >= 0 })
+	return i, i < len(n.items) && compare(n.items[i].key, key) == 0
+}
+
+// split splits the full child at index i into two nodes and moves its middle item into n.
+func (n *btreeNode) split(i, degree int) {
+	child := n.children[i]
+	mid := child.items[degree-1]
+	right := &btreeNode{items: append([]btreeItem(nil), child.items[degree:]...)}
+	if len(child.children) > 0 {
+		right.children = append([]*btreeNode(nil), child.children[degree:]...)
+		child.children = child.children[:degree:degree]
+	}
+	child.items = child.items[: degree-1 : degree-1]
+
+	n.items = append(n.items, btreeItem{})
+	copy(n.items[i+1:], n.items[i:])
+	n.items[i] = mid
+	n.children = append(n.children, nil)
+	copy(n.children[i+2:], n.children[i+1:])
+	n.children[i+1] = right
+}
+
+// insert adds item to the subtree rooted at n, which must not be full.
+func (n *btreeNode) insert(item btreeItem, t *btree) bool {
+	i, found := n.find(item.key, t.compare)
+	if found {
+		n.items[i] = item
+		return true
+	}
+	if len(n.children) == 0 {
+		n.items = append(n.items, btreeItem{})
+		copy(n.items[i+1:], n.items[i:])
+		n.items[i] = item
+		return false
+	}
+	if len(n.children[i].items) == t.maxItems() {
+		n.split(i, t.degree)
+		switch c := t.compare(item.key, n.items[i].key); {
+		case c == 0:
+			n.items[i] = item
+			return true
+		case c > 0:
+			i++
+		}
+	}
+	return n.children[i].insert(item, t)
+}
+
+// remove deletes key from the subtree rooted at n. Before descending into a child, it makes sure that the child has
+// at least degree items so that removing an item from it does not leave it with too few.
+func (n *btreeNode) remove(key interface{}, t *btree) bool {
+	i, found := n.find(key, t.compare)
+	if len(n.children) == 0 {
+		if !found {
+			return false
+		}
+		n.items = append(n.items[:i], n.items[i+1:]...)
+		return true
+	}
+
+	if found {
+		left, right := n.children[i], n.children[i+1]
+		switch {
+		case len(left.items) >= t.degree:
+			pred := left.max()
+			n.items[i] = pred
+			return left.remove(pred.key, t)
+		case len(right.items) >= t.degree:
+			succ := right.min()
+			n.items[i] = succ
+			return right.remove(succ.key, t)
+		default:
+			n.merge(i)
+			return left.remove(key, t)
+		}
+	}
+
+	if len(n.children[i].items) < t.degree {
+		i = n.grow(i, t.degree)
+	}
+	return n.children[i].remove(key, t)
+}
+
+// grow gives the child at index i at least degree items by moving an item from a sibling or merging it with a
+// sibling. It returns the index of the child that covers the same keys afterwards.
+func (n *btreeNode) grow(i, degree int) int {
+	child := n.children[i]
+	switch {
+	case i > 0 && len(n.children[i-1].items) >= degree:
+		left := n.children[i-1]
+		child.items = append([]btreeItem{n.items[i-1]}, child.items...)
+		n.items[i-1] = left.items[len(left.items)-1]
+		left.items = left.items[:len(left.items)-1]
+		if len(left.children) > 0 {
+			child.children = append([]*btreeNode{left.children[len(left.children)-1]}, child.children...)
+			left.children = left.children[:len(left.children)-1]
+		}
+		return i
+	case i < len(n.items) && len(n.children[i+1].items) >= degree:
+		right := n.children[i+1]
+		child.items = append(child.items, n.items[i])
+		n.items[i] = right.items[0]
+		right.items = append(right.items[:0], right.items[1:]...)
+		if len(right.children) > 0 {
+			child.children = append(child.children, right.children[0])
+			right.children = append(right.children[:0], right.children[1:]...)
+		}
+		return i
+	case i < len(n.items):
+		n.merge(i)
+		return i
+	default:
+		n.merge(i - 1)
+		return i - 1
+	}
+}
+
+// merge moves the item at index i and the child to its right into the child to its left.
+func (n *btreeNode) merge(i int) {
+	left, right := n.children[i], n.children[i+1]
+	left.items = append(left.items, n.items[i])
+	left.items = append(left.items, right.items...)
+	left.children = append(left.children, right.children...)
+	n.items = append(n.items[:i], n.items[i+1:]...)
+	n.children = append(n.children[:i+1], n.children[i+2:]...)
+}
+
+func (n *btreeNode) min() btreeItem {
+	for len(n.children) > 0 {
+		n = n.children[0]
+	}
+	return n.items[0]
+}
+
+func (n *btreeNode) max() btreeItem {
+	for len(n.children) > 0 {
+		n = n.children[len(n.children)-1]
+	}
+	return n.items[len(n.items)-1]
+}
+
+// ascend calls fn for the items of the subtree in key order, starting at from if it is not nil. It returns false if fn
+// stopped the iteration.
+func (n *btreeNode) ascend(from *interface{}, compare func(a, b interface{}) int,
+	fn func(key, value interface{}) bool) bool {
+	start := 0
+	if from != nil {
+		start, _ = n.find(*from, compare)
+	}
+	for i := start; i <= len(n.items); i++ {
+		if len(n.children) > 0 {
+			childFrom := from
+			if i > start {
+				childFrom = nil
+			}
+			if !n.children[i].ascend(childFrom, compare, fn) {
+				return false
+			}
+		}
+		if i < len(n.items) && !fn(n.items[i].key, n.items[i].value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongofake
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+func TestBTree(t *testing.T) {
+	t.Run("matches a map", func(t *testing.T) {
+		// a small degree forces frequent splits and merges
+		tree := newBTree(2, compareValues)
+		expected := make(map[int32]int)
+		r := rand.New(rand.NewSource(1))
+
+		for i := 0; i < 5000; i++ {
+			key := int32(r.Intn(500))
+			if r.Intn(3) == 0 {
+				_, exists := expected[key]
+				delete(expected, key)
+				removed := tree.Delete(key)
+				assert.Equal(t, exists, removed, "expected Delete(%d) to return %v, got %v", key, exists, removed)
+				continue
+			}
+			_, exists := expected[key]
+			expected[key] = i
+			replaced := tree.Set(key, i)
+			assert.Equal(t, exists, replaced, "expected Set(%d) to return %v, got %v", key, exists, replaced)
+		}
+
+		assert.Equal(t, len(expected), tree.Len(), "expected length %d, got %d", len(expected), tree.Len())
+		var keys []int32
+		for key, value := range expected {
+			keys = append(keys, key)
+			got, ok := tree.Get(key)
+			assert.True(t, ok, "expected key %d to be found", key)
+			assert.Equal(t, value, got, "expected value %d for key %d, got %v", value, key, got)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+		var got []int32
+		tree.Ascend(func(key, _ interface{}) bool {
+			got = append(got, key.(int32))
+			return true
+		})
+		assert.Equal(t, keys, got, "expected keys %v, got %v", keys, got)
+	})
+	t.Run("ascend from", func(t *testing.T) {
+		tree := newBTree(2, compareValues)
+		for i := int32(0); i < 100; i += 2 {
+			tree.Set(i, nil)
+		}
+
+		var got []int32
+		tree.AscendFrom(int32(51), func(key, _ interface{}) bool {
+			got = append(got, key.(int32))
+			return len(got) < 3
+		})
+		assert.Equal(t, []int32{52, 54, 56}, got, "expected keys [52 54 56], got %v", got)
+	})
+	t.Run("clone", func(t *testing.T) {
+		tree := newBTree(2, compareValues)
+		for i := int32(0); i < 20; i++ {
+			tree.Set(i, nil)
+		}
+		clone := tree.Clone()
+		tree.Delete(int32(5))
+		clone.Set(int32(100), nil)
+
+		_, ok := clone.Get(int32(5))
+		assert.True(t, ok, "expected the clone not to be affected by deletes from the original")
+		_, ok = tree.Get(int32(100))
+		assert.False(t, ok, "expected the original not to be affected by inserts into the clone")
+	})
+}
@@ -0,0 +1,920 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongofake
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultBatchSize is the number of documents returned in the first batch of a cursor if no batch size is given.
+const defaultBatchSize = 101
+
+// commandError is an error returned to the driver as a command failure or, for writes, as a write error.
+type commandError struct {
+	Code    int32
+	Name    string
+	Message string
+}
+
+// Error implements the error interface.
+func (e commandError) Error() string {
+	return e.Message
+}
+
+func toCommandError(err error) commandError {
+	if ce, ok := err.(commandError); ok {
+		return ce
+	}
+	return commandError{Code: 1, Name: "InternalError", Message: err.Error()}
+}
+
+func duplicateKey(indexName string, key bson.D) error {
+	return commandError{Code: 11000, Name: "DuplicateKey",
+		Message: fmt.Sprintf("E11000 duplicate key error index: %s dup key: %s", indexName, formatDocument(key))}
+}
+
+func namespaceNotFound(ns string) error {
+	return commandError{Code: 26, Name: "NamespaceNotFound", Message: "ns not found: " + ns}
+}
+
+func formatDocument(doc bson.D) string {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return fmt.Sprint(doc)
+	}
+	return bson.Raw(raw).String()
+}
+
+type commandFunc func(s *Server, db string, cmd bson.D) (bson.D, error)
+
+var commands map[string]commandFunc
+
+func init() {
+	commands = map[string]commandFunc{
+		"ping":              func(*Server, string, bson.D) (bson.D, error) { return bson.D{}, nil },
+		"hello":             (*Server).hello,
+		"isMaster":          (*Server).hello,
+		"ismaster":          (*Server).hello,
+		"buildInfo":         (*Server).buildInfo,
+		"buildinfo":         (*Server).buildInfo,
+		"endSessions":       (*Server).endSessions,
+		"commitTransaction": (*Server).commitTransaction,
+		"abortTransaction":  (*Server).abortTransaction,
+		"insert":            (*Server).insert,
+		"update":            (*Server).update,
+		"delete":            (*Server).delete,
+		"find":              (*Server).find,
+		"getMore":           (*Server).getMore,
+		"killCursors":       (*Server).killCursors,
+		"aggregate":         (*Server).aggregate,
+		"count":             (*Server).count,
+		"distinct":          (*Server).distinct,
+		"findAndModify":     (*Server).findAndModify,
+		"listCollections":   (*Server).listCollections,
+		"listDatabases":     (*Server).listDatabases,
+		"create":            (*Server).create,
+		"drop":              (*Server).drop,
+		"dropDatabase":      (*Server).dropDatabase,
+		"createIndexes":     (*Server).createIndexes,
+		"listIndexes":       (*Server).listIndexes,
+		"dropIndexes":       (*Server).dropIndexes,
+	}
+}
+
+// execute runs a command and returns the reply.
+func (s *Server) execute(cmd bson.D) bson.D {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reply, err := s.executeLocked(cmd)
+	if err != nil {
+		ce := toCommandError(err)
+		return bson.D{{"ok", 0.0}, {"errmsg", ce.Message}, {"code", ce.Code}, {"codeName", ce.Name}}
+	}
+	return append(reply, bson.E{Key: "ok", Value: 1.0})
+}
+
+func (s *Server) executeLocked(cmd bson.D) (bson.D, error) {
+	if len(cmd) == 0 {
+		return nil, badValue("empty command")
+	}
+	name := cmd[0].Key
+	fn, ok := commands[name]
+	if !ok {
+		return nil, commandError{Code: 59, Name: "CommandNotFound", Message: fmt.Sprintf("no such command: '%s'", name)}
+	}
+	db, _ := lookupKey(cmd, "$db")
+	dbName, _ := db.(string)
+	if dbName == "" {
+		return nil, badValue("command %s is missing $db", name)
+	}
+
+	if start, _ := lookupKey(cmd, "startTransaction"); start == true {
+		txnNumber, _ := lookupKey(cmd, "txnNumber")
+		n, _ := intValue(txnNumber)
+		s.transactions[sessionKey(cmd)] = &transaction{txnNumber: n, snapshot: s.snapshot()}
+	}
+	return fn(s, dbName, cmd)
+}
+
+// sessionKey returns the ID of the session that cmd was sent on, or an empty string if there is none.
+func sessionKey(cmd bson.D) string {
+	lsid, _ := lookupKey(cmd, "lsid")
+	if d, ok := lsid.(bson.D); ok {
+		id, _ := lookupKey(d, "id")
+		if bin, ok := id.(primitive.Binary); ok {
+			return string(bin.Data)
+		}
+	}
+	return ""
+}
+
+func (s *Server) hello(string, bson.D) (bson.D, error) {
+	return bson.D{
+		{"ismaster", true},
+		{"isWritablePrimary", true},
+		{"maxBsonObjectSize", int32(maxDocumentSize)},
+		{"maxMessageSizeBytes", int32(maxMessageSize)},
+		{"maxWriteBatchSize", int32(maxBatchCount)},
+		{"localTime", primitive.NewDateTimeFromTime(time.Now())},
+		{"logicalSessionTimeoutMinutes", int32(sessionTimeoutMinutes)},
+		{"minWireVersion", int32(0)},
+		{"maxWireVersion", maxWireVersion},
+		{"readOnly", false},
+	}, nil
+}
+
+func (s *Server) buildInfo(string, bson.D) (bson.D, error) {
+	return bson.D{
+		{"version", "4.2.0"},
+		{"versionArray", bson.A{int32(4), int32(2), int32(0), int32(0)}},
+		{"maxBsonObjectSize", int32(maxDocumentSize)},
+	}, nil
+}
+
+func (s *Server) endSessions(_ string, cmd bson.D) (bson.D, error) {
+	ids, _ := cmd[0].Value.(bson.A)
+	for _, id := range ids {
+		if key := sessionKey(bson.D{{"lsid", id}}); key != "" {
+			s.rollback(key)
+		}
+	}
+	return bson.D{}, nil
+}
+
+func (s *Server) commitTransaction(_ string, cmd bson.D) (bson.D, error) {
+	delete(s.transactions, sessionKey(cmd))
+	return bson.D{}, nil
+}
+
+func (s *Server) abortTransaction(_ string, cmd bson.D) (bson.D, error) {
+	s.rollback(sessionKey(cmd))
+	return bson.D{}, nil
+}
+
+// rollback restores the snapshot taken when the transaction on the given session started.
+func (s *Server) rollback(key string) {
+	if txn, ok := s.transactions[key]; ok {
+		s.databases = txn.snapshot
+		s.cursors = make(map[int64]*cursor)
+		delete(s.transactions, key)
+	}
+}
+
+func (s *Server) insert(db string, cmd bson.D) (bson.D, error) {
+	collName, err := collectionName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	docs := arrayField(cmd, "documents")
+	ordered := boolField(cmd, "ordered", true)
+	coll := s.collection(db, collName, true)
+
+	n := 0
+	var writeErrors bson.A
+	for i, v := range docs {
+		doc, ok := v.(bson.D)
+		if !ok {
+			return nil, badValue("documents to insert must be objects")
+		}
+		if _, hasID := lookupKey(doc, "_id"); hasID {
+			doc = copyDocument(doc)
+		} else {
+			doc = append(bson.D{{"_id", primitive.NewObjectID()}}, copyDocument(doc)...)
+		}
+		if err := coll.put(nil, doc); err != nil {
+			writeErrors = append(writeErrors, writeError(i, err))
+			if ordered {
+				break
+			}
+			continue
+		}
+		n++
+	}
+	return writeReply(bson.D{{"n", int32(n)}}, writeErrors), nil
+}
+
+func (s *Server) update(db string, cmd bson.D) (bson.D, error) {
+	collName, err := collectionName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	ordered := boolField(cmd, "ordered", true)
+
+	var n, nModified int
+	var upserted, writeErrors bson.A
+	for i, v := range arrayField(cmd, "updates") {
+		spec, _ := v.(bson.D)
+		matched, modified, id, err := s.updateOne(db, collName, spec)
+		if err != nil {
+			writeErrors = append(writeErrors, writeError(i, err))
+			if ordered {
+				break
+			}
+			continue
+		}
+		n += matched
+		nModified += modified
+		if id != nil {
+			upserted = append(upserted, bson.D{{"index", int32(i)}, {"_id", id}})
+			n++
+		}
+	}
+
+	reply := bson.D{{"n", int32(n)}, {"nModified", int32(nModified)}}
+	if len(upserted) > 0 {
+		reply = append(reply, bson.E{Key: "upserted", Value: upserted})
+	}
+	return writeReply(reply, writeErrors), nil
+}
+
+// updateOne executes a single statement of an update command. It returns the number of matched and modified documents
+// and the _id of the upserted document, if any.
+func (s *Server) updateOne(db, collName string, spec bson.D) (int, int, interface{}, error) {
+	filter := docField(spec, "q")
+	update, _ := lookupKey(spec, "u")
+	if _, isPipeline := update.(bson.A); isPipeline {
+		return 0, 0, nil, notImplemented("pipeline updates are not supported")
+	}
+	updateDoc, ok := update.(bson.D)
+	if !ok {
+		return 0, 0, nil, badValue("update must be an object")
+	}
+	if _, ok := lookupKey(spec, "arrayFilters"); ok {
+		return 0, 0, nil, notImplemented("arrayFilters are not supported")
+	}
+	multi := boolField(spec, "multi", false)
+	if multi && isReplacement(updateDoc) {
+		return 0, 0, nil, commandError{Code: 9, Name: "FailedToParse",
+			Message: "multi update is not supported for replacement-style update"}
+	}
+
+	coll := s.collection(db, collName, false)
+	docs, err := filterDocuments(coll, filter)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if !multi && len(docs) > 1 {
+		docs = docs[:1]
+	}
+
+	if len(docs) == 0 && boolField(spec, "upsert", false) {
+		doc, err := upsertDocument(filter, updateDoc)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		if err := s.collection(db, collName, true).put(nil, doc); err != nil {
+			return 0, 0, nil, err
+		}
+		id, _ := lookupKey(doc, "_id")
+		return 0, 0, id, nil
+	}
+
+	modified := 0
+	for _, doc := range docs {
+		updated, err := applyUpdate(doc, updateDoc, false)
+		if err != nil {
+			return 0, modified, nil, err
+		}
+		if sameDocument(doc, updated) {
+			continue
+		}
+		if err := coll.put(doc, updated); err != nil {
+			return 0, modified, nil, err
+		}
+		modified++
+	}
+	return len(docs), modified, nil, nil
+}
+
+// upsertDocument returns the document inserted by an upsert. Update operators are applied to the equality conditions
+// of the filter, and replacement documents only take their _id from the filter.
+func upsertDocument(filter, update bson.D) (bson.D, error) {
+	base := bson.D{}
+	for _, e := range equalityFields(filter) {
+		if !isReplacement(update) || e.Key == "_id" {
+			var err error
+			if base, err = setPath(base, strings.Split(e.Key, "."), copyValue(e.Value)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	doc, err := applyUpdate(base, update, true)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := lookupKey(doc, "_id"); !ok {
+		doc = append(bson.D{{"_id", primitive.NewObjectID()}}, doc...)
+	}
+	return doc, nil
+}
+
+func (s *Server) delete(db string, cmd bson.D) (bson.D, error) {
+	collName, err := collectionName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	ordered := boolField(cmd, "ordered", true)
+	coll := s.collection(db, collName, false)
+
+	n := 0
+	var writeErrors bson.A
+	for i, v := range arrayField(cmd, "deletes") {
+		spec, _ := v.(bson.D)
+		docs, err := filterDocuments(coll, docField(spec, "q"))
+		if err != nil {
+			writeErrors = append(writeErrors, writeError(i, err))
+			if ordered {
+				break
+			}
+			continue
+		}
+		if limit, _ := lookupKey(spec, "limit"); floatValue(limit) == 1 && len(docs) > 1 {
+			docs = docs[:1]
+		}
+		for _, doc := range docs {
+			coll.remove(doc)
+		}
+		n += len(docs)
+	}
+	return writeReply(bson.D{{"n", int32(n)}}, writeErrors), nil
+}
+
+func (s *Server) find(db string, cmd bson.D) (bson.D, error) {
+	collName, err := collectionName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := filterDocuments(s.collection(db, collName, false), docField(cmd, "filter"))
+	if err != nil {
+		return nil, err
+	}
+	if err := sortDocuments(docs, docField(cmd, "sort")); err != nil {
+		return nil, err
+	}
+
+	singleBatch := boolField(cmd, "singleBatch", false)
+	docs = skipDocuments(docs, intField(cmd, "skip"))
+	if limit := intField(cmd, "limit"); limit != 0 {
+		if limit < 0 {
+			limit, singleBatch = -limit, true
+		}
+		docs = limitDocuments(docs, limit)
+	}
+	if projection := docField(cmd, "projection"); len(projection) > 0 {
+		for i, doc := range docs {
+			if docs[i], err = project(doc, projection); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s.cursorReply(db+"."+collName, docs, intField(cmd, "batchSize"), singleBatch), nil
+}
+
+// cursorReply returns the reply for a command that returns a cursor. The documents that do not fit in the first batch
+// are kept in a server-side cursor unless singleBatch is true.
+func (s *Server) cursorReply(ns string, docs []bson.D, batchSize int64, singleBatch bool) bson.D {
+	first := int64(defaultBatchSize)
+	if batchSize > 0 {
+		first = batchSize
+	}
+	if singleBatch || first > int64(len(docs)) {
+		first = int64(len(docs))
+	}
+
+	var id int64
+	if rest := docs[first:]; len(rest) > 0 && !singleBatch {
+		s.nextCursorID++
+		id = s.nextCursorID
+		s.cursors[id] = &cursor{ns: ns, docs: rest}
+	}
+	return bson.D{{"cursor", bson.D{
+		{"firstBatch", documentsArray(docs[:first])},
+		{"id", id},
+		{"ns", ns},
+	}}}
+}
+
+func (s *Server) getMore(db string, cmd bson.D) (bson.D, error) {
+	id, ok := intValue(cmd[0].Value)
+	if !ok {
+		return nil, typeMismatch("getMore requires a cursor id of type long")
+	}
+	c, ok := s.cursors[id]
+	if !ok {
+		return nil, commandError{Code: 43, Name: "CursorNotFound", Message: fmt.Sprintf("cursor id %d not found", id)}
+	}
+
+	batch := c.docs
+	if batchSize := intField(cmd, "batchSize"); batchSize > 0 && batchSize < int64(len(batch)) {
+		batch = batch[:batchSize]
+	}
+	c.docs = c.docs[len(batch):]
+	if len(c.docs) == 0 {
+		delete(s.cursors, id)
+		id = 0
+	}
+	return bson.D{{"cursor", bson.D{
+		{"nextBatch", documentsArray(batch)},
+		{"id", id},
+		{"ns", c.ns},
+	}}}, nil
+}
+
+func (s *Server) killCursors(_ string, cmd bson.D) (bson.D, error) {
+	killed, notFound := bson.A{}, bson.A{}
+	for _, v := range arrayField(cmd, "cursors") {
+		id, _ := intValue(v)
+		if _, ok := s.cursors[id]; ok {
+			delete(s.cursors, id)
+			killed = append(killed, id)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+	return bson.D{
+		{"cursorsKilled", killed},
+		{"cursorsNotFound", notFound},
+		{"cursorsAlive", bson.A{}},
+		{"cursorsUnknown", bson.A{}},
+	}, nil
+}
+
+func (s *Server) aggregate(db string, cmd bson.D) (bson.D, error) {
+	collName, err := collectionName(cmd)
+	if err != nil {
+		return nil, notImplemented("database aggregations are not supported")
+	}
+	docs, err := filterDocuments(s.collection(db, collName, false), nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range arrayField(cmd, "pipeline") {
+		stage, ok := v.(bson.D)
+		if !ok || len(stage) != 1 {
+			return nil, badValue("a pipeline stage specification object must contain exactly one field")
+		}
+		if docs, err = applyStage(docs, stage[0]); err != nil {
+			return nil, err
+		}
+	}
+	return s.cursorReply(db+"."+collName, docs, intField(docField(cmd, "cursor"), "batchSize"), false), nil
+}
+
+func (s *Server) count(db string, cmd bson.D) (bson.D, error) {
+	collName, err := collectionName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := filterDocuments(s.collection(db, collName, false), docField(cmd, "query"))
+	if err != nil {
+		return nil, err
+	}
+	docs = skipDocuments(docs, intField(cmd, "skip"))
+	if limit := intField(cmd, "limit"); limit != 0 {
+		if limit < 0 {
+			limit = -limit
+		}
+		docs = limitDocuments(docs, limit)
+	}
+	return bson.D{{"n", int32(len(docs))}}, nil
+}
+
+func (s *Server) distinct(db string, cmd bson.D) (bson.D, error) {
+	collName, err := collectionName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	key, _ := lookupKey(cmd, "key")
+	path, ok := key.(string)
+	if !ok || path == "" {
+		return nil, badValue("distinct requires a key")
+	}
+	docs, err := filterDocuments(s.collection(db, collName, false), docField(cmd, "query"))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := newBTree(defaultDegree, compareValues)
+	for _, doc := range docs {
+		values, _ := lookupPath(doc, strings.Split(path, "."))
+		for _, v := range values {
+			if arr, ok := v.(bson.A); ok {
+				for _, elem := range arr {
+					seen.Set(elem, nil)
+				}
+				continue
+			}
+			seen.Set(v, nil)
+		}
+	}
+	values := bson.A{}
+	seen.Ascend(func(key, _ interface{}) bool {
+		values = append(values, key)
+		return true
+	})
+	return bson.D{{"values", values}}, nil
+}
+
+func (s *Server) findAndModify(db string, cmd bson.D) (bson.D, error) {
+	collName, err := collectionName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	remove := boolField(cmd, "remove", false)
+	update, hasUpdate := lookupKey(cmd, "update")
+	if remove == hasUpdate {
+		return nil, commandError{Code: 9, Name: "FailedToParse",
+			Message: "either an update or remove=true must be specified"}
+	}
+	if _, ok := lookupKey(cmd, "arrayFilters"); ok {
+		return nil, notImplemented("arrayFilters are not supported")
+	}
+
+	coll := s.collection(db, collName, false)
+	docs, err := filterDocuments(coll, docField(cmd, "query"))
+	if err != nil {
+		return nil, err
+	}
+	if err = sortDocuments(docs, docField(cmd, "sort")); err != nil {
+		return nil, err
+	}
+
+	var before, after bson.D
+	lastError := bson.D{{"n", int32(0)}}
+	switch {
+	case len(docs) > 0 && remove:
+		before = docs[0]
+		coll.remove(before)
+		lastError = bson.D{{"n", int32(1)}}
+	case len(docs) > 0:
+		updateDoc, ok := update.(bson.D)
+		if !ok {
+			return nil, notImplemented("pipeline updates are not supported")
+		}
+		before = docs[0]
+		if after, err = applyUpdate(before, updateDoc, false); err != nil {
+			return nil, err
+		}
+		if !sameDocument(before, after) {
+			if err = coll.put(before, after); err != nil {
+				return nil, err
+			}
+		}
+		lastError = bson.D{{"n", int32(1)}, {"updatedExisting", true}}
+	case !remove && boolField(cmd, "upsert", false):
+		updateDoc, ok := update.(bson.D)
+		if !ok {
+			return nil, notImplemented("pipeline updates are not supported")
+		}
+		if after, err = upsertDocument(docField(cmd, "query"), updateDoc); err != nil {
+			return nil, err
+		}
+		if err = s.collection(db, collName, true).put(nil, after); err != nil {
+			return nil, err
+		}
+		id, _ := lookupKey(after, "_id")
+		lastError = bson.D{{"n", int32(1)}, {"updatedExisting", false}, {"upserted", id}}
+	}
+
+	result := before
+	if boolField(cmd, "new", false) && !remove {
+		result = after
+	}
+	var value interface{} = primitive.Null{}
+	if result != nil {
+		projected, err := project(result, docField(cmd, "fields"))
+		if err != nil {
+			return nil, err
+		}
+		value = projected
+	}
+	return bson.D{{"lastErrorObject", lastError}, {"value", value}}, nil
+}
+
+func (s *Server) listCollections(db string, cmd bson.D) (bson.D, error) {
+	filter := docField(cmd, "filter")
+	var infos []bson.D
+	if d := s.database(db, false); d != nil {
+		for _, name := range sortedKeys(d.collections) {
+			info := bson.D{
+				{"name", name},
+				{"type", "collection"},
+				{"options", bson.D{}},
+				{"info", bson.D{{"readOnly", false}}},
+			}
+			matched, err := matchDocument(info, filter)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				infos = append(infos, info)
+			}
+		}
+	}
+	return s.cursorReply(db+".$cmd.listCollections", infos, intField(docField(cmd, "cursor"), "batchSize"), false), nil
+}
+
+func (s *Server) listDatabases(_ string, cmd bson.D) (bson.D, error) {
+	filter := docField(cmd, "filter")
+	var names []string
+	for name := range s.databases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dbs := bson.A{}
+	var totalSize int64
+	for _, name := range names {
+		var size int64
+		for _, coll := range s.databases[name].collections {
+			for _, doc := range coll.documents() {
+				raw, _ := bson.Marshal(doc)
+				size += int64(len(raw))
+			}
+		}
+		info := bson.D{{"name", name}, {"sizeOnDisk", size}, {"empty", size == 0}}
+		matched, err := matchDocument(info, filter)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			dbs = append(dbs, info)
+			totalSize += size
+		}
+	}
+	return bson.D{{"databases", dbs}, {"totalSize", totalSize}}, nil
+}
+
+func (s *Server) create(db string, cmd bson.D) (bson.D, error) {
+	collName, err := collectionName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if s.collection(db, collName, false) != nil {
+		return nil, commandError{Code: 48, Name: "NamespaceExists",
+			Message: fmt.Sprintf("Collection already exists. NS: %s.%s", db, collName)}
+	}
+	s.collection(db, collName, true)
+	return bson.D{}, nil
+}
+
+func (s *Server) drop(db string, cmd bson.D) (bson.D, error) {
+	collName, err := collectionName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if s.collection(db, collName, false) == nil {
+		return nil, namespaceNotFound(db + "." + collName)
+	}
+	delete(s.databases[db].collections, collName)
+	return bson.D{{"ns", db + "." + collName}}, nil
+}
+
+func (s *Server) dropDatabase(db string, _ bson.D) (bson.D, error) {
+	delete(s.databases, db)
+	return bson.D{{"dropped", db}}, nil
+}
+
+func (s *Server) createIndexes(db string, cmd bson.D) (bson.D, error) {
+	collName, err := collectionName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	created := s.collection(db, collName, false) == nil
+	coll := s.collection(db, collName, true)
+	before := len(coll.indexes)
+
+	for _, v := range arrayField(cmd, "indexes") {
+		spec, _ := v.(bson.D)
+		keys := docField(spec, "key")
+		if len(keys) == 0 {
+			return nil, badValue("index specification must contain a key")
+		}
+		name, _ := lookupKey(spec, "name")
+		idx := &index{
+			keys:   keys,
+			unique: boolField(spec, "unique", false),
+			sparse: boolField(spec, "sparse", false),
+		}
+		if idx.name, _ = name.(string); idx.name == "" {
+			idx.name = indexName(keys)
+		}
+		if existing := coll.index(idx.name); existing != nil {
+			if !sameDocument(existing.keys, keys) {
+				return nil, commandError{Code: 86, Name: "IndexKeySpecsConflict",
+					Message: "an existing index has the same name as the requested index: " + idx.name}
+			}
+			continue
+		}
+		if err := coll.addIndex(idx); err != nil {
+			return nil, err
+		}
+	}
+	return bson.D{
+		{"createdCollectionAutomatically", created},
+		{"numIndexesBefore", int32(before)},
+		{"numIndexesAfter", int32(len(coll.indexes))},
+	}, nil
+}
+
+// indexName returns the default name of an index with the given keys, e.g. "a_1_b_-1".
+func indexName(keys bson.D) string {
+	parts := make([]string, 0, 2*len(keys))
+	for _, k := range keys {
+		parts = append(parts, k.Key, fmt.Sprint(k.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+func (coll *collection) index(name string) *index {
+	for _, idx := range coll.indexes {
+		if idx.name == name {
+			return idx
+		}
+	}
+	return nil
+}
+
+func (s *Server) listIndexes(db string, cmd bson.D) (bson.D, error) {
+	collName, err := collectionName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	coll := s.collection(db, collName, false)
+	if coll == nil {
+		return nil, namespaceNotFound(db + "." + collName)
+	}
+
+	specs := make([]bson.D, 0, len(coll.indexes))
+	for _, idx := range coll.indexes {
+		spec := bson.D{{"v", int32(2)}, {"key", idx.keys}, {"name", idx.name}}
+		if idx.unique {
+			spec = append(spec, bson.E{Key: "unique", Value: true})
+		}
+		if idx.sparse {
+			spec = append(spec, bson.E{Key: "sparse", Value: true})
+		}
+		specs = append(specs, spec)
+	}
+	return s.cursorReply(db+"."+collName, specs, intField(docField(cmd, "cursor"), "batchSize"), false), nil
+}
+
+func (s *Server) dropIndexes(db string, cmd bson.D) (bson.D, error) {
+	collName, err := collectionName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	coll := s.collection(db, collName, false)
+	if coll == nil {
+		return nil, namespaceNotFound(db + "." + collName)
+	}
+	was := len(coll.indexes)
+
+	target, _ := lookupKey(cmd, "index")
+	if target == "*" {
+		coll.indexes = coll.indexes[:1]
+		return bson.D{{"nIndexesWas", int32(was)}}, nil
+	}
+	for i, idx := range coll.indexes {
+		if keys, ok := target.(bson.D); idx.name != target && (!ok || !sameDocument(idx.keys, keys)) {
+			continue
+		}
+		if idx.name == "_id_" {
+			return nil, commandError{Code: 72, Name: "InvalidOptions", Message: "cannot drop _id index"}
+		}
+		coll.indexes = append(coll.indexes[:i:i], coll.indexes[i+1:]...)
+		return bson.D{{"nIndexesWas", int32(was)}}, nil
+	}
+	return nil, commandError{Code: 27, Name: "IndexNotFound", Message: fmt.Sprintf("index not found with name [%v]",
+		target)}
+}
+
+// filterDocuments returns the documents in coll that match filter. A nil collection has no documents.
+func filterDocuments(coll *collection, filter bson.D) ([]bson.D, error) {
+	var matched []bson.D
+	for _, doc := range coll.documents() {
+		ok, err := matchDocument(doc, filter)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, doc)
+		}
+	}
+	return matched, nil
+}
+
+func skipDocuments(docs []bson.D, skip int64) []bson.D {
+	if skip <= 0 {
+		return docs
+	}
+	if skip >= int64(len(docs)) {
+		return nil
+	}
+	return docs[skip:]
+}
+
+func limitDocuments(docs []bson.D, limit int64) []bson.D {
+	if limit > 0 && limit < int64(len(docs)) {
+		return docs[:limit]
+	}
+	return docs
+}
+
+func documentsArray(docs []bson.D) bson.A {
+	arr := make(bson.A, len(docs))
+	for i, doc := range docs {
+		arr[i] = doc
+	}
+	return arr
+}
+
+func sameDocument(a, b bson.D) bool {
+	ab, aerr := bson.Marshal(a)
+	bb, berr := bson.Marshal(b)
+	return aerr == nil && berr == nil && bytes.Equal(ab, bb)
+}
+
+func writeError(i int, err error) bson.D {
+	ce := toCommandError(err)
+	return bson.D{{"index", int32(i)}, {"code", ce.Code}, {"errmsg", ce.Message}}
+}
+
+func writeReply(reply bson.D, writeErrors bson.A) bson.D {
+	if len(writeErrors) > 0 {
+		reply = append(reply, bson.E{Key: "writeErrors", Value: writeErrors})
+	}
+	return reply
+}
+
+func collectionName(cmd bson.D) (string, error) {
+	name, ok := cmd[0].Value.(string)
+	if !ok || name == "" {
+		return "", commandError{Code: 73, Name: "InvalidNamespace",
+			Message: fmt.Sprintf("collection name has invalid type %T", cmd[0].Value)}
+	}
+	return name, nil
+}
+
+func docField(d bson.D, key string) bson.D {
+	v, _ := lookupKey(d, key)
+	doc, _ := v.(bson.D)
+	return doc
+}
+
+func arrayField(d bson.D, key string) bson.A {
+	v, _ := lookupKey(d, key)
+	arr, _ := v.(bson.A)
+	return arr
+}
+
+func boolField(d bson.D, key string, def bool) bool {
+	v, ok := lookupKey(d, key)
+	if !ok {
+		return def
+	}
+	return truthy(v)
+}
+
+func intField(d bson.D, key string) int64 {
+	v, _ := lookupKey(d, key)
+	if n, ok := intValue(v); ok {
+		return n
+	}
+	if f, ok := v.(float64); ok {
+		return int64(f)
+	}
+	return 0
+}
@@ -0,0 +1,221 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongofake
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// typeOrder returns the rank of the type of v in the order that MongoDB uses to compare values of different types.
+// Numbers of every type have the same rank, as do strings and symbols.
+func typeOrder(v interface{}) int {
+	switch v.(type) {
+	case primitive.MinKey:
+		return 1
+	case nil, primitive.Null, primitive.Undefined:
+		return 2
+	case int32, int64, float64, primitive.Decimal128:
+		return 3
+	case string, primitive.Symbol:
+		return 4
+	case bson.D:
+		return 5
+	case bson.A:
+		return 6
+	case primitive.Binary:
+		return 7
+	case primitive.ObjectID:
+		return 8
+	case bool:
+		return 9
+	case primitive.DateTime:
+		return 10
+	case primitive.Timestamp:
+		return 11
+	case primitive.Regex:
+		return 12
+	case primitive.MaxKey:
+		return 100
+	default:
+		return 50
+	}
+}
+
+// compareValues returns -1, 0, or 1 if a is less than, equal to, or greater than b in the order that MongoDB uses to
+// sort values.
+func compareValues(a, b interface{}) int {
+	if ta, tb := typeOrder(a), typeOrder(b); ta != tb {
+		return compareInts(int64(ta), int64(tb))
+	}
+
+	switch av := a.(type) {
+	case int32, int64, float64, primitive.Decimal128:
+		return compareNumbers(a, b)
+	case string:
+		return strings.Compare(av, stringValue(b))
+	case primitive.Symbol:
+		return strings.Compare(string(av), stringValue(b))
+	case bson.D:
+		bv := b.(bson.D)
+		for i := 0; i < len(av) && i < len(bv); i++ {
+			if c := strings.Compare(av[i].Key, bv[i].Key); c != 0 {
+				return c
+			}
+			if c := compareValues(av[i].Value, bv[i].Value); c != 0 {
+				return c
+			}
+		}
+		return compareInts(int64(len(av)), int64(len(bv)))
+	case bson.A:
+		bv := b.(bson.A)
+		for i := 0; i < len(av) && i < len(bv); i++ {
+			if c := compareValues(av[i], bv[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(int64(len(av)), int64(len(bv)))
+	case primitive.Binary:
+		bv := b.(primitive.Binary)
+		if c := compareInts(int64(len(av.Data)), int64(len(bv.Data))); c != 0 {
+			return c
+		}
+		if c := compareInts(int64(av.Subtype), int64(bv.Subtype)); c != 0 {
+			return c
+		}
+		return bytes.Compare(av.Data, bv.Data)
+	case primitive.ObjectID:
+		bv := b.(primitive.ObjectID)
+		return bytes.Compare(av[:], bv[:])
+	case bool:
+		bv := b.(bool)
+		switch {
+		case av == bv:
+			return 0
+		case !av:
+			return -1
+		default:
+			return 1
+		}
+	case primitive.DateTime:
+		return compareInts(int64(av), int64(b.(primitive.DateTime)))
+	case primitive.Timestamp:
+		bv := b.(primitive.Timestamp)
+		if c := compareInts(int64(av.T), int64(bv.T)); c != 0 {
+			return c
+		}
+		return compareInts(int64(av.I), int64(bv.I))
+	case primitive.Regex:
+		bv := b.(primitive.Regex)
+		if c := strings.Compare(av.Pattern, bv.Pattern); c != 0 {
+			return c
+		}
+		return strings.Compare(av.Options, bv.Options)
+	default:
+		// values of the remaining types, such as JavaScript code, are only compared for equality
+		if equalValues(a, b) {
+			return 0
+		}
+		return compareInts(int64(typeOrder(a)), int64(typeOrder(b))+1)
+	}
+}
+
+// equalValues reports whether a and b are equal. Numbers of different types are equal if they have the same value.
+func equalValues(a, b interface{}) bool {
+	switch a.(type) {
+	case int32, int64, float64, primitive.Decimal128, string, primitive.Symbol, bson.D, bson.A, primitive.Binary,
+		primitive.ObjectID, bool, primitive.DateTime, primitive.Timestamp, primitive.Regex, nil, primitive.Null,
+		primitive.Undefined, primitive.MinKey, primitive.MaxKey:
+		return compareValues(a, b) == 0
+	}
+	ab, aerr := bson.Marshal(bson.D{{"v", a}})
+	bb, berr := bson.Marshal(bson.D{{"v", b}})
+	return aerr == nil && berr == nil && bytes.Equal(ab, bb)
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareNumbers compares two numeric values. Integers are compared exactly and other numbers as float64 values.
+func compareNumbers(a, b interface{}) int {
+	ai, aInt := intValue(a)
+	bi, bInt := intValue(b)
+	if aInt && bInt {
+		return compareInts(ai, bi)
+	}
+
+	af, bf := floatValue(a), floatValue(b)
+	switch {
+	case math.IsNaN(af) && math.IsNaN(bf):
+		return 0
+	case math.IsNaN(af):
+		return -1
+	case math.IsNaN(bf):
+		return 1
+	case af < bf:
+		return -1
+	case af > bf:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func intValue(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+func floatValue(v interface{}) float64 {
+	switch n := v.(type) {
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	case primitive.Decimal128:
+		f, err := strconv.ParseFloat(n.String(), 64)
+		if err != nil {
+			return math.NaN()
+		}
+		return f
+	default:
+		return math.NaN()
+	}
+}
+
+func isNumber(v interface{}) bool {
+	return typeOrder(v) == typeOrder(int32(0))
+}
+
+func stringValue(v interface{}) string {
+	if s, ok := v.(primitive.Symbol); ok {
+		return string(s)
+	}
+	s, _ := v.(string)
+	return s
+}
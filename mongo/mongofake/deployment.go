@@ -0,0 +1,233 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongofake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+const (
+	serverAddress                = address.Address("mongofake:27017")
+	maxDocumentSize       uint32 = 16777216
+	maxMessageSize        uint32 = 48000000
+	maxBatchCount         uint32 = 100000
+	sessionTimeoutMinutes uint32 = 30
+	maxWireVersion        int32  = 8
+)
+
+var connectionID uint64
+
+// deployment implements the driver.Deployment interface by handing out connections that execute commands directly
+// against a Server. No network connections are made.
+type deployment struct {
+	server  *Server
+	once    sync.Once
+	updates chan description.Topology
+}
+
+var _ driver.Deployment = &deployment{}
+var _ driver.Server = &deployment{}
+var _ driver.Connector = &deployment{}
+var _ driver.Disconnector = &deployment{}
+var _ driver.Subscriber = &deployment{}
+
+// SelectServer implements the driver.Deployment interface. It always returns the deployment itself.
+func (d *deployment) SelectServer(context.Context, description.ServerSelector) (driver.Server, error) {
+	return d, nil
+}
+
+// SupportsRetryWrites implements the driver.Deployment interface.
+func (d *deployment) SupportsRetryWrites() bool {
+	return true
+}
+
+// Kind implements the driver.Deployment interface. It always returns description.Single.
+func (d *deployment) Kind() description.TopologyKind {
+	return description.Single
+}
+
+// Connection implements the driver.Server interface.
+func (d *deployment) Connection(context.Context) (driver.Connection, error) {
+	return &connection{
+		server: d.server,
+		id:     fmt.Sprintf("mongofake[%d]", atomic.AddUint64(&connectionID, 1)),
+	}, nil
+}
+
+// Connect is a no-op method which implements the driver.Connector interface.
+func (d *deployment) Connect() error {
+	return nil
+}
+
+// Disconnect is a no-op method which implements the driver.Disconnector interface.
+func (d *deployment) Disconnect(context.Context) error {
+	return nil
+}
+
+// Subscribe implements the driver.Subscriber interface. The subscription receives a single topology description that
+// advertises support for sessions.
+func (d *deployment) Subscribe() (*driver.Subscription, error) {
+	d.once.Do(func() {
+		d.updates = make(chan description.Topology, 1)
+		d.updates <- description.Topology{
+			SessionTimeoutMinutes: sessionTimeoutMinutes,
+		}
+	})
+	return &driver.Subscription{
+		Updates: d.updates,
+	}, nil
+}
+
+// Unsubscribe is a no-op method which implements the driver.Subscriber interface.
+func (d *deployment) Unsubscribe(*driver.Subscription) error {
+	return nil
+}
+
+// connection implements the driver.Connection interface. Each OP_MSG written to it is executed by the server and the
+// reply is returned by the next call to ReadWireMessage.
+type connection struct {
+	server  *Server
+	id      string
+	replies []bson.D
+}
+
+var _ driver.Connection = &connection{}
+
+// WriteWireMessage implements the driver.Connection interface.
+func (c *connection) WriteWireMessage(_ context.Context, wm []byte) error {
+	cmd, moreToCome, err := parseMessage(wm)
+	if err != nil {
+		return err
+	}
+	reply := c.server.execute(cmd)
+	if !moreToCome {
+		c.replies = append(c.replies, reply)
+	}
+	return nil
+}
+
+// ReadWireMessage implements the driver.Connection interface.
+func (c *connection) ReadWireMessage(_ context.Context, dst []byte) ([]byte, error) {
+	if len(c.replies) == 0 {
+		return dst, errors.New("mongofake: no reply available")
+	}
+	reply := c.replies[0]
+	c.replies = c.replies[1:]
+
+	replyBytes, err := bson.Marshal(reply)
+	if err != nil {
+		return dst, err
+	}
+	var wmindex int32
+	wmindex, dst = wiremessage.AppendHeaderStart(dst, wiremessage.NextRequestID(), 0, wiremessage.OpMsg)
+	dst = wiremessage.AppendMsgFlags(dst, 0)
+	dst = wiremessage.AppendMsgSectionType(dst, wiremessage.SingleDocument)
+	dst = append(dst, replyBytes...)
+	dst = bsoncore.UpdateLength(dst, wmindex, int32(len(dst[wmindex:])))
+	return dst, nil
+}
+
+// Description implements the driver.Connection interface.
+func (c *connection) Description() description.Server {
+	return description.Server{
+		Addr:                  serverAddress,
+		CanonicalAddr:         serverAddress,
+		MaxDocumentSize:       maxDocumentSize,
+		MaxMessageSize:        maxMessageSize,
+		MaxBatchCount:         maxBatchCount,
+		SessionTimeoutMinutes: sessionTimeoutMinutes,
+		Kind:                  description.RSPrimary,
+		WireVersion: &description.VersionRange{
+			Max: maxWireVersion,
+		},
+	}
+}
+
+// Close is a no-op method which implements the driver.Connection interface.
+func (*connection) Close() error {
+	return nil
+}
+
+// ID implements the driver.Connection interface.
+func (c *connection) ID() string {
+	return c.id
+}
+
+// Address implements the driver.Connection interface.
+func (*connection) Address() address.Address {
+	return serverAddress
+}
+
+// parseMessage decodes the command in an OP_MSG wire message. Document sequences are added to the command as arrays.
+func parseMessage(wm []byte) (bson.D, bool, error) {
+	_, _, _, opcode, rem, ok := wiremessage.ReadHeader(wm)
+	if !ok {
+		return nil, false, errors.New("mongofake: malformed wire message header")
+	}
+	if opcode != wiremessage.OpMsg {
+		return nil, false, fmt.Errorf("mongofake: unsupported opcode %v", opcode)
+	}
+	flags, rem, ok := wiremessage.ReadMsgFlags(rem)
+	if !ok {
+		return nil, false, errors.New("mongofake: malformed OP_MSG flags")
+	}
+	if flags&wiremessage.ChecksumPresent != 0 {
+		rem = rem[:len(rem)-4]
+	}
+
+	var cmd bson.D
+	var sequences bson.D
+	for len(rem) > 0 {
+		var stype wiremessage.SectionType
+		stype, rem, ok = wiremessage.ReadMsgSectionType(rem)
+		if !ok {
+			return nil, false, errors.New("mongofake: malformed OP_MSG section")
+		}
+		switch stype {
+		case wiremessage.SingleDocument:
+			var doc bsoncore.Document
+			if doc, rem, ok = wiremessage.ReadMsgSectionSingleDocument(rem); !ok {
+				return nil, false, errors.New("mongofake: malformed OP_MSG document section")
+			}
+			if err := bson.Unmarshal(doc, &cmd); err != nil {
+				return nil, false, err
+			}
+		case wiremessage.DocumentSequence:
+			var identifier string
+			var docs []bsoncore.Document
+			if identifier, docs, rem, ok = wiremessage.ReadMsgSectionDocumentSequence(rem); !ok {
+				return nil, false, errors.New("mongofake: malformed OP_MSG document sequence section")
+			}
+			arr := make(bson.A, 0, len(docs))
+			for _, doc := range docs {
+				var d bson.D
+				if err := bson.Unmarshal(doc, &d); err != nil {
+					return nil, false, err
+				}
+				arr = append(arr, d)
+			}
+			sequences = append(sequences, bson.E{Key: identifier, Value: arr})
+		default:
+			return nil, false, fmt.Errorf("mongofake: unsupported OP_MSG section type %d", stype)
+		}
+	}
+	if cmd == nil {
+		return nil, false, errors.New("mongofake: OP_MSG without a command document")
+	}
+	return append(cmd, sequences...), flags&wiremessage.MoreToCome != 0, nil
+}
@@ -0,0 +1,34 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package mongofake provides an in-memory fake of a MongoDB server for unit tests. A client connected to the fake
+// uses the regular driver API, but commands are executed in the same process without opening any network
+// connections, so tests do not need a MongoDB deployment, Docker, or mock responses:
+//
+//	server := mongofake.NewServer()
+//	client, err := mongo.Connect(ctx, server.ClientOptions())
+//	if err != nil { return err }
+//	coll := client.Database("shop").Collection("orders")
+//
+// The fake supports the CRUD commands (insert, update, delete, find, findAndModify, count, distinct, and aggregate),
+// the commands to list, create, and drop databases, collections, and indexes, and sessions and transactions.
+//
+// Documents are stored in B-trees ordered by _id, and queries that do not specify a sort return documents in _id
+// order. Queries support the comparison, logical, element, and array query operators ($eq, $ne, $gt, $gte, $lt, $lte,
+// $in, $nin, $and, $or, $nor, $not, $exists, $regex, $size, $all, and $elemMatch) and inclusion and exclusion
+// projections. Updates support the field and array update operators ($set, $unset, $setOnInsert, $inc, $mul, $min,
+// $max, $rename, $currentDate, $push, $addToSet, $pull, $pullAll, and $pop) and replacement documents. Aggregations
+// support the $match, $sort, $skip, $limit, $project, $count, $unwind, and $group stages. Unsupported operators and
+// stages fail with a NotImplemented error instead of being ignored.
+//
+// Indexes are recorded and unique indexes are enforced, but indexes are not used to answer queries. Transactions are
+// not isolated from other sessions: writes are visible immediately, and aborting a transaction restores the data
+// that existed when the transaction started, including changes made by other sessions in the meantime. Read and
+// write concerns, collations, and read preferences are ignored.
+//
+// This package is intended for unit tests only. Behavior that depends on a real deployment, such as replication,
+// sharding, authentication, or change streams, must be tested against a MongoDB server.
+package mongofake
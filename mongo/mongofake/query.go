@@ -0,0 +1,588 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongofake
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// lookupPath returns the values at the dotted path in v. Arrays along the path are traversed element-wise, so the
+// path can resolve to more than one value. The second return value is false if the path does not exist.
+func lookupPath(v interface{}, path []string) ([]interface{}, bool) {
+	if len(path) == 0 {
+		return []interface{}{v}, true
+	}
+
+	switch val := v.(type) {
+	case bson.D:
+		for _, e := range val {
+			if e.Key == path[0] {
+				return lookupPath(e.Value, path[1:])
+			}
+		}
+		return nil, false
+	case bson.A:
+		var values []interface{}
+		var found bool
+		if idx, err := strconv.Atoi(path[0]); err == nil && idx >= 0 && idx < len(val) {
+			values, found = lookupPath(val[idx], path[1:])
+		}
+		for _, elem := range val {
+			if _, ok := elem.(bson.D); !ok {
+				continue
+			}
+			if elemValues, ok := lookupPath(elem, path); ok {
+				values = append(values, elemValues...)
+				found = true
+			}
+		}
+		return values, found
+	default:
+		return nil, false
+	}
+}
+
+// candidates returns the values at path in doc that query operators are applied to. Arrays are expanded so that an
+// operator matches if it matches the array itself or any of its elements.
+func candidates(doc bson.D, path string) ([]interface{}, bool) {
+	values, found := lookupPath(doc, strings.Split(path, "."))
+	expanded := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		expanded = append(expanded, v)
+		if arr, ok := v.(bson.A); ok {
+			expanded = append(expanded, arr...)
+		}
+	}
+	return expanded, found
+}
+
+// matchDocument reports whether doc matches the query filter.
+func matchDocument(doc, filter bson.D) (bool, error) {
+	for _, e := range filter {
+		matched, err := matchElement(doc, e)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchElement(doc bson.D, e bson.E) (bool, error) {
+	switch e.Key {
+	case "$and", "$or", "$nor":
+		arr, ok := e.Value.(bson.A)
+		if !ok || len(arr) == 0 {
+			return false, badValue("%s must be a nonempty array", e.Key)
+		}
+		for _, clause := range arr {
+			sub, ok := clause.(bson.D)
+			if !ok {
+				return false, badValue("%s argument's entries must be objects", e.Key)
+			}
+			matched, err := matchDocument(doc, sub)
+			if err != nil {
+				return false, err
+			}
+			switch {
+			case e.Key == "$and" && !matched:
+				return false, nil
+			case e.Key == "$or" && matched:
+				return true, nil
+			case e.Key == "$nor" && matched:
+				return false, nil
+			}
+		}
+		return e.Key != "$or", nil
+	case "$comment":
+		return true, nil
+	}
+	if strings.HasPrefix(e.Key, "$") {
+		return false, notImplemented("unsupported query operator %s", e.Key)
+	}
+
+	values, found := candidates(doc, e.Key)
+	if ops, ok := operatorDocument(e.Value); ok {
+		return matchOperators(doc, e.Key, values, found, ops)
+	}
+	if re, ok := e.Value.(primitive.Regex); ok {
+		return matchOperator(doc, e.Key, values, found, bson.E{Key: "$regex", Value: re})
+	}
+	return matchOperator(doc, e.Key, values, found, bson.E{Key: "$eq", Value: e.Value})
+}
+
+// operatorDocument returns v as a document of query operators if its first key starts with "$".
+func operatorDocument(v interface{}) (bson.D, bool) {
+	d, ok := v.(bson.D)
+	if !ok || len(d) == 0 || !strings.HasPrefix(d[0].Key, "$") {
+		return nil, false
+	}
+	return d, true
+}
+
+func matchOperators(doc bson.D, path string, values []interface{}, found bool, ops bson.D) (bool, error) {
+	for _, op := range ops {
+		switch op.Key {
+		case "$options":
+			continue
+		case "$regex":
+			options, _ := lookupKey(ops, "$options")
+			op.Value = toRegex(op.Value, stringValue(options))
+		}
+		matched, err := matchOperator(doc, path, values, found, op)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchOperator(doc bson.D, path string, values []interface{}, found bool, op bson.E) (bool, error) {
+	switch op.Key {
+	case "$eq":
+		return matchAny(values, found, op.Value, equalValues), nil
+	case "$ne":
+		return !matchAny(values, found, op.Value, equalValues), nil
+	case "$gt", "$gte", "$lt", "$lte":
+		return matchAny(values, found, op.Value, func(v, arg interface{}) bool {
+			if typeOrder(v) != typeOrder(arg) {
+				return false
+			}
+			c := compareValues(v, arg)
+			switch op.Key {
+			case "$gt":
+				return c > 0
+			case "$gte":
+				return c >= 0
+			case "$lt":
+				return c < 0
+			default:
+				return c <= 0
+			}
+		}), nil
+	case "$in", "$nin":
+		arr, ok := op.Value.(bson.A)
+		if !ok {
+			return false, badValue("%s needs an array", op.Key)
+		}
+		in := false
+		for _, arg := range arr {
+			matched := false
+			if re, ok := arg.(primitive.Regex); ok {
+				matched, _ = matchOperator(doc, path, values, found, bson.E{Key: "$regex", Value: re})
+			} else {
+				matched = matchAny(values, found, arg, equalValues)
+			}
+			if matched {
+				in = true
+				break
+			}
+		}
+		return in == (op.Key == "$in"), nil
+	case "$exists":
+		return found == truthy(op.Value), nil
+	case "$not":
+		var matched bool
+		var err error
+		if ops, ok := operatorDocument(op.Value); ok {
+			matched, err = matchOperators(doc, path, values, found, ops)
+		} else if re, ok := op.Value.(primitive.Regex); ok {
+			matched, err = matchOperator(doc, path, values, found, bson.E{Key: "$regex", Value: re})
+		} else {
+			return false, badValue("$not needs a regex or a document")
+		}
+		return !matched && err == nil, err
+	case "$regex":
+		re, err := compileRegex(op.Value)
+		if err != nil {
+			return false, err
+		}
+		for _, v := range values {
+			if s, ok := v.(string); ok && re.MatchString(s) {
+				return true, nil
+			}
+			if other, ok := v.(primitive.Regex); ok && other == op.Value {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$size":
+		size, ok := intValue(op.Value)
+		if !ok {
+			if f, isFloat := op.Value.(float64); isFloat && f == float64(int64(f)) {
+				size, ok = int64(f), true
+			}
+		}
+		if !ok {
+			return false, badValue("$size needs a number")
+		}
+		for _, arr := range arrays(doc, path) {
+			if int64(len(arr)) == size {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$all":
+		args, ok := op.Value.(bson.A)
+		if !ok {
+			return false, badValue("$all needs an array")
+		}
+		if len(args) == 0 {
+			return false, nil
+		}
+		for _, arg := range args {
+			if !matchAny(values, found, arg, equalValues) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "$elemMatch":
+		cond, ok := op.Value.(bson.D)
+		if !ok {
+			return false, badValue("$elemMatch needs an Object")
+		}
+		for _, arr := range arrays(doc, path) {
+			for _, elem := range arr {
+				matched, err := matchElemMatch(elem, cond)
+				if err != nil {
+					return false, err
+				}
+				if matched {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	default:
+		return false, notImplemented("unsupported query operator %s", op.Key)
+	}
+}
+
+// matchElemMatch reports whether an array element matches the condition of an $elemMatch operator. The condition is
+// either a query that is applied to embedded documents or a document of operators that is applied to the element.
+func matchElemMatch(elem interface{}, cond bson.D) (bool, error) {
+	if ops, ok := operatorDocument(cond); ok {
+		wrapper := bson.D{{"v", elem}}
+		values, found := candidates(wrapper, "v")
+		if _, isArray := elem.(bson.A); isArray {
+			values = []interface{}{elem}
+		}
+		return matchOperators(wrapper, "v", values, found, ops)
+	}
+	doc, ok := elem.(bson.D)
+	if !ok {
+		return false, nil
+	}
+	return matchDocument(doc, cond)
+}
+
+// matchAny reports whether match returns true for arg and any of values. A null argument also matches a path that
+// does not exist.
+func matchAny(values []interface{}, found bool, arg interface{}, match func(v, arg interface{}) bool) bool {
+	if !found && isNull(arg) {
+		return true
+	}
+	for _, v := range values {
+		if match(v, arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// arrays returns the arrays at path in doc without expanding them.
+func arrays(doc bson.D, path string) []bson.A {
+	values, _ := lookupPath(doc, strings.Split(path, "."))
+	var arrs []bson.A
+	for _, v := range values {
+		if arr, ok := v.(bson.A); ok {
+			arrs = append(arrs, arr)
+		}
+	}
+	return arrs
+}
+
+func toRegex(v interface{}, options string) interface{} {
+	switch re := v.(type) {
+	case string:
+		return primitive.Regex{Pattern: re, Options: options}
+	case primitive.Regex:
+		if options != "" {
+			re.Options = options
+		}
+		return re
+	default:
+		return v
+	}
+}
+
+func compileRegex(v interface{}) (*regexp.Regexp, error) {
+	re, ok := v.(primitive.Regex)
+	if !ok {
+		return nil, badValue("$regex has to be a string")
+	}
+	var flags string
+	for _, o := range re.Options {
+		switch o {
+		case 'i', 'm', 's':
+			flags += string(o)
+		case 'x', 'u', 'l':
+		default:
+			return nil, badValue("invalid flag in regex options: %c", o)
+		}
+	}
+	pattern := re.Pattern
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, badValue("invalid regular expression: %v", err)
+	}
+	return compiled, nil
+}
+
+func isNull(v interface{}) bool {
+	switch v.(type) {
+	case nil, primitive.Null:
+		return true
+	default:
+		return false
+	}
+}
+
+// truthy reports whether v is considered true when used as a flag, e.g. in a projection or an $exists operator.
+func truthy(v interface{}) bool {
+	switch val := v.(type) {
+	case bool:
+		return val
+	case nil, primitive.Null, primitive.Undefined:
+		return false
+	case int32, int64, float64, primitive.Decimal128:
+		return floatValue(val) != 0
+	default:
+		return true
+	}
+}
+
+func lookupKey(d bson.D, key string) (interface{}, bool) {
+	for _, e := range d {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+// project applies a projection to doc. The projection either includes or excludes fields, with the exception of _id,
+// which is included unless it is explicitly excluded.
+func project(doc, projection bson.D) (bson.D, error) {
+	if len(projection) == 0 {
+		return doc, nil
+	}
+
+	inclusion := false
+	for _, e := range projection {
+		if _, ok := e.Value.(bson.D); ok {
+			return nil, notImplemented("unsupported projection for field %s", e.Key)
+		}
+		if e.Key != "_id" && truthy(e.Value) {
+			inclusion = true
+		}
+	}
+	for _, e := range projection {
+		if e.Key != "_id" && truthy(e.Value) != inclusion {
+			return nil, badValue("cannot do %s on field %s in %s projection", projectionKind(!inclusion), e.Key,
+				projectionKind(inclusion))
+		}
+	}
+
+	tree := bson.D{}
+	for _, e := range projection {
+		tree = addProjectionPath(tree, strings.Split(e.Key, "."), truthy(e.Value))
+	}
+	if _, ok := lookupKey(tree, "_id"); !ok && inclusion {
+		tree = append(tree, bson.E{Key: "_id", Value: true})
+	}
+	return projectValue(doc, tree, inclusion).(bson.D), nil
+}
+
+func projectionKind(inclusion bool) string {
+	if inclusion {
+		return "inclusion"
+	}
+	return "exclusion"
+}
+
+// addProjectionPath adds a dotted projection path to a tree of nested documents whose leaves are booleans.
+func addProjectionPath(tree bson.D, path []string, include bool) bson.D {
+	for i, e := range tree {
+		if e.Key != path[0] {
+			continue
+		}
+		if sub, ok := e.Value.(bson.D); ok && len(path) > 1 {
+			tree[i].Value = addProjectionPath(sub, path[1:], include)
+		}
+		return tree
+	}
+	if len(path) == 1 {
+		return append(tree, bson.E{Key: path[0], Value: include})
+	}
+	return append(tree, bson.E{Key: path[0], Value: addProjectionPath(bson.D{}, path[1:], include)})
+}
+
+func projectValue(v interface{}, tree bson.D, inclusion bool) interface{} {
+	switch val := v.(type) {
+	case bson.D:
+		out := bson.D{}
+		for _, e := range val {
+			spec, ok := lookupKey(tree, e.Key)
+			switch {
+			case !ok && !inclusion:
+				out = append(out, e)
+			case !ok:
+			case isProjectionTree(spec):
+				if projected := projectValue(e.Value, spec.(bson.D), inclusion); projected != nil {
+					out = append(out, bson.E{Key: e.Key, Value: projected})
+				}
+			case spec.(bool):
+				out = append(out, e)
+			}
+		}
+		return out
+	case bson.A:
+		out := bson.A{}
+		for _, elem := range val {
+			switch elem.(type) {
+			case bson.D, bson.A:
+				out = append(out, projectValue(elem, tree, inclusion))
+			default:
+				if !inclusion {
+					out = append(out, elem)
+				}
+			}
+		}
+		return out
+	default:
+		if inclusion {
+			return nil
+		}
+		return v
+	}
+}
+
+func isProjectionTree(v interface{}) bool {
+	_, ok := v.(bson.D)
+	return ok
+}
+
+// sortDocuments sorts docs in place by the given sort specification. Documents that compare equal keep their order.
+func sortDocuments(docs []bson.D, spec bson.D) error {
+	if len(spec) == 0 {
+		return nil
+	}
+	for _, e := range spec {
+		if _, ok := e.Value.(bson.D); ok {
+			return notImplemented("unsupported sort specification for field %s", e.Key)
+		}
+		if d := floatValue(e.Value); d != 1 && d != -1 {
+			return badValue("bad sort specification for field %s", e.Key)
+		}
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, e := range spec {
+			desc := floatValue(e.Value) < 0
+			c := compareValues(sortKey(docs[i], e.Key, desc), sortKey(docs[j], e.Key, desc))
+			if desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+// sortKey returns the value that doc is sorted by for path. Arrays sort by their smallest element in ascending
+// sorts and by their largest element in descending sorts, and missing fields sort as null.
+func sortKey(doc bson.D, path string, desc bool) interface{} {
+	values, found := lookupPath(doc, strings.Split(path, "."))
+	if !found || len(values) == 0 {
+		return nil
+	}
+
+	var key interface{}
+	first := true
+	consider := func(v interface{}) {
+		if first {
+			key, first = v, false
+			return
+		}
+		c := compareValues(v, key)
+		if (desc && c > 0) || (!desc && c < 0) {
+			key = v
+		}
+	}
+	for _, v := range values {
+		if arr, ok := v.(bson.A); ok && len(arr) > 0 {
+			for _, elem := range arr {
+				consider(elem)
+			}
+			continue
+		}
+		consider(v)
+	}
+	return key
+}
+
+// equalityFields returns the fields of filter that an upsert copies into the inserted document: top-level equality
+// conditions and $eq operators.
+func equalityFields(filter bson.D) bson.D {
+	var fields bson.D
+	for _, e := range filter {
+		if strings.HasPrefix(e.Key, "$") {
+			if e.Key != "$and" {
+				continue
+			}
+			clauses, _ := e.Value.(bson.A)
+			for _, clause := range clauses {
+				if sub, ok := clause.(bson.D); ok {
+					fields = append(fields, equalityFields(sub)...)
+				}
+			}
+			continue
+		}
+		if ops, ok := operatorDocument(e.Value); ok {
+			if v, ok := lookupKey(ops, "$eq"); ok {
+				fields = append(fields, bson.E{Key: e.Key, Value: v})
+			}
+			continue
+		}
+		if _, ok := e.Value.(primitive.Regex); ok {
+			continue
+		}
+		fields = append(fields, e)
+	}
+	return fields
+}
+
+func badValue(format string, args ...interface{}) error {
+	return commandError{Code: 2, Name: "BadValue", Message: fmt.Sprintf(format, args...)}
+}
+
+func notImplemented(format string, args ...interface{}) error {
+	return commandError{Code: 238, Name: "NotImplemented", Message: fmt.Sprintf(format, args...)}
+}
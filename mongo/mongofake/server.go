@@ -0,0 +1,262 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongofake
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Server is an in-memory fake of a MongoDB server. Its zero value is not usable; use NewServer to create one. A
+// Server is safe for concurrent use, but commands are executed one at a time.
+type Server struct {
+	mu           sync.Mutex
+	databases    map[string]*database
+	cursors      map[int64]*cursor
+	nextCursorID int64
+	transactions map[string]*transaction // keyed by session ID
+}
+
+// NewServer creates a new, empty Server.
+func NewServer() *Server {
+	return &Server{
+		databases:    make(map[string]*database),
+		cursors:      make(map[int64]*cursor),
+		transactions: make(map[string]*transaction),
+	}
+}
+
+// ClientOptions returns options that connect a client to s. Additional options can be merged into the returned
+// options, but options that configure the topology or servers, such as hosts, cannot be used together with them.
+func (s *Server) ClientOptions() *options.ClientOptions {
+	opts := options.Client()
+	opts.Deployment = &deployment{server: s}
+	return opts
+}
+
+// Reset removes all databases, cursors, and transactions from s.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.databases = make(map[string]*database)
+	s.cursors = make(map[int64]*cursor)
+	s.transactions = make(map[string]*transaction)
+}
+
+type database struct {
+	collections map[string]*collection
+}
+
+type collection struct {
+	docs    *btree // _id -> bson.D
+	indexes []*index
+}
+
+type index struct {
+	name    string
+	keys    bson.D
+	unique  bool
+	sparse  bool
+	entries *btree // index key -> _id, only maintained for unique indexes
+}
+
+// cursor holds the remaining results of a find or aggregate command.
+type cursor struct {
+	ns   string
+	docs []bson.D
+}
+
+// transaction holds a snapshot of the databases that is restored if the transaction is aborted.
+type transaction struct {
+	txnNumber int64
+	snapshot  map[string]*database
+}
+
+func newCollection() *collection {
+	return &collection{
+		docs:    newBTree(defaultDegree, compareValues),
+		indexes: []*index{{name: "_id_", keys: bson.D{{"_id", int32(1)}}}},
+	}
+}
+
+// database returns the database with the given name, creating it if create is true.
+func (s *Server) database(name string, create bool) *database {
+	db, ok := s.databases[name]
+	if !ok && create {
+		db = &database{collections: make(map[string]*collection)}
+		s.databases[name] = db
+	}
+	return db
+}
+
+// collection returns the collection with the given name, creating it and its database if create is true.
+func (s *Server) collection(dbName, collName string, create bool) *collection {
+	db := s.database(dbName, create)
+	if db == nil {
+		return nil
+	}
+	coll, ok := db.collections[collName]
+	if !ok && create {
+		coll = newCollection()
+		db.collections[collName] = coll
+	}
+	return coll
+}
+
+// documents returns the documents in coll in _id order.
+func (coll *collection) documents() []bson.D {
+	if coll == nil {
+		return nil
+	}
+	docs := make([]bson.D, 0, coll.docs.Len())
+	coll.docs.Ascend(func(_, value interface{}) bool {
+		docs = append(docs, value.(bson.D))
+		return true
+	})
+	return docs
+}
+
+// indexKey returns the key of doc in idx and whether the document is indexed. Documents without any of the indexed
+// fields are not indexed by sparse indexes.
+func (idx *index) indexKey(doc bson.D) (bson.A, bool) {
+	key := make(bson.A, 0, len(idx.keys))
+	present := false
+	for _, k := range idx.keys {
+		v, ok := getPath(doc, strings.Split(k.Key, "."))
+		present = present || ok
+		key = append(key, v)
+	}
+	return key, present || !idx.sparse
+}
+
+// put stores doc in coll, replacing old if it is not nil. It returns a duplicate key error without modifying coll if
+// doc would violate a unique index.
+func (coll *collection) put(old, doc bson.D) error {
+	id, _ := lookupKey(doc, "_id")
+	if old == nil {
+		if _, exists := coll.docs.Get(id); exists {
+			return duplicateKey("_id_", bson.D{{"_id", id}})
+		}
+	}
+
+	for _, idx := range coll.indexes {
+		if !idx.unique {
+			continue
+		}
+		key, ok := idx.indexKey(doc)
+		if !ok {
+			continue
+		}
+		if owner, exists := idx.entries.Get(key); exists && compareValues(owner, id) != 0 {
+			return duplicateKey(idx.name, keyDocument(idx.keys, key))
+		}
+	}
+
+	if old != nil {
+		coll.unindex(old)
+	}
+	for _, idx := range coll.indexes {
+		if !idx.unique {
+			continue
+		}
+		if key, ok := idx.indexKey(doc); ok {
+			idx.entries.Set(key, id)
+		}
+	}
+	coll.docs.Set(id, doc)
+	return nil
+}
+
+// remove deletes doc from coll.
+func (coll *collection) remove(doc bson.D) {
+	id, _ := lookupKey(doc, "_id")
+	coll.unindex(doc)
+	coll.docs.Delete(id)
+}
+
+func (coll *collection) unindex(doc bson.D) {
+	for _, idx := range coll.indexes {
+		if !idx.unique {
+			continue
+		}
+		if key, ok := idx.indexKey(doc); ok {
+			idx.entries.Delete(key)
+		}
+	}
+}
+
+// addIndex adds idx to coll and builds its entries. It returns an error if the existing documents violate the index.
+func (coll *collection) addIndex(idx *index) error {
+	if idx.unique {
+		idx.entries = newBTree(defaultDegree, compareValues)
+		var err error
+		coll.docs.Ascend(func(id, value interface{}) bool {
+			key, ok := idx.indexKey(value.(bson.D))
+			if !ok {
+				return true
+			}
+			if _, exists := idx.entries.Get(key); exists {
+				err = duplicateKey(idx.name, keyDocument(idx.keys, key))
+				return false
+			}
+			idx.entries.Set(key, id)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	coll.indexes = append(coll.indexes, idx)
+	return nil
+}
+
+func (coll *collection) clone() *collection {
+	clone := &collection{docs: coll.docs.Clone()}
+	for _, idx := range coll.indexes {
+		idxClone := *idx
+		if idx.entries != nil {
+			idxClone.entries = idx.entries.Clone()
+		}
+		clone.indexes = append(clone.indexes, &idxClone)
+	}
+	return clone
+}
+
+// snapshot returns a copy of the databases of s. Documents are never modified in place, so they are shared.
+func (s *Server) snapshot() map[string]*database {
+	snapshot := make(map[string]*database, len(s.databases))
+	for name, db := range s.databases {
+		dbClone := &database{collections: make(map[string]*collection, len(db.collections))}
+		for collName, coll := range db.collections {
+			dbClone.collections[collName] = coll.clone()
+		}
+		snapshot[name] = dbClone
+	}
+	return snapshot
+}
+
+func keyDocument(keys bson.D, values bson.A) bson.D {
+	doc := make(bson.D, len(keys))
+	for i, k := range keys {
+		doc[i] = bson.E{Key: k.Key, Value: values[i]}
+	}
+	return doc
+}
+
+func sortedKeys(m map[string]*collection) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,305 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongofake
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func newTestCollection(t *testing.T) (*mongo.Client, *mongo.Collection) {
+	t.Helper()
+
+	client, err := mongo.Connect(context.Background(), NewServer().ClientOptions())
+	assert.Nil(t, err, "Connect error: %v", err)
+	return client, client.Database("db").Collection("coll")
+}
+
+func findAll(t *testing.T, coll *mongo.Collection, filter interface{}, opts ...*options.FindOptions) []bson.D {
+	t.Helper()
+
+	cursor, err := coll.Find(context.Background(), filter, opts...)
+	assert.Nil(t, err, "Find error: %v", err)
+	var docs []bson.D
+	err = cursor.All(context.Background(), &docs)
+	assert.Nil(t, err, "All error: %v", err)
+	return docs
+}
+
+func ids(docs []bson.D) []interface{} {
+	var ids []interface{}
+	for _, doc := range docs {
+		id, _ := lookupKey(doc, "_id")
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("crud", func(t *testing.T) {
+		client, coll := newTestCollection(t)
+		defer func() { _ = client.Disconnect(ctx) }()
+
+		res, err := coll.InsertOne(ctx, bson.D{{"x", 1}})
+		assert.Nil(t, err, "InsertOne error: %v", err)
+		assert.NotNil(t, res.InsertedID, "expected an inserted ID")
+
+		_, err = coll.InsertMany(ctx, []interface{}{
+			bson.D{{"_id", 1}, {"x", 2}},
+			bson.D{{"_id", 2}, {"x", 3}},
+		})
+		assert.Nil(t, err, "InsertMany error: %v", err)
+
+		var found bson.M
+		err = coll.FindOne(ctx, bson.D{{"_id", 2}}).Decode(&found)
+		assert.Nil(t, err, "FindOne error: %v", err)
+		assert.Equal(t, int32(3), found["x"], "expected x to be 3, got %v", found["x"])
+
+		updateRes, err := coll.UpdateMany(ctx, bson.D{{"x", bson.D{{"$gte", 2}}}}, bson.D{{"$inc", bson.D{{"x", 10}}}})
+		assert.Nil(t, err, "UpdateMany error: %v", err)
+		assert.Equal(t, int64(2), updateRes.MatchedCount, "expected 2 matched, got %d", updateRes.MatchedCount)
+		assert.Equal(t, int64(2), updateRes.ModifiedCount, "expected 2 modified, got %d", updateRes.ModifiedCount)
+
+		upsertRes, err := coll.UpdateOne(ctx, bson.D{{"_id", 3}}, bson.D{{"$set", bson.D{{"x", 4}}}},
+			options.Update().SetUpsert(true))
+		assert.Nil(t, err, "UpdateOne error: %v", err)
+		assert.Equal(t, int32(3), upsertRes.UpsertedID, "expected upserted ID 3, got %v", upsertRes.UpsertedID)
+
+		_, err = coll.ReplaceOne(ctx, bson.D{{"_id", 3}}, bson.D{{"y", "replaced"}})
+		assert.Nil(t, err, "ReplaceOne error: %v", err)
+		err = coll.FindOne(ctx, bson.D{{"y", "replaced"}}).Err()
+		assert.Nil(t, err, "expected the replacement to be found, got %v", err)
+
+		count, err := coll.CountDocuments(ctx, bson.D{})
+		assert.Nil(t, err, "CountDocuments error: %v", err)
+		assert.Equal(t, int64(4), count, "expected 4 documents, got %d", count)
+
+		deleteRes, err := coll.DeleteMany(ctx, bson.D{{"x", bson.D{{"$exists", true}}}})
+		assert.Nil(t, err, "DeleteMany error: %v", err)
+		assert.Equal(t, int64(3), deleteRes.DeletedCount, "expected 3 deleted, got %d", deleteRes.DeletedCount)
+
+		estimated, err := coll.EstimatedDocumentCount(ctx)
+		assert.Nil(t, err, "EstimatedDocumentCount error: %v", err)
+		assert.Equal(t, int64(1), estimated, "expected 1 document, got %d", estimated)
+
+		err = coll.FindOne(ctx, bson.D{{"x", 1}}).Err()
+		assert.Equal(t, mongo.ErrNoDocuments, err, "expected error %v, got %v", mongo.ErrNoDocuments, err)
+	})
+	t.Run("queries", func(t *testing.T) {
+		client, coll := newTestCollection(t)
+		defer func() { _ = client.Disconnect(ctx) }()
+
+		_, err := coll.InsertMany(ctx, []interface{}{
+			bson.D{{"_id", 1}, {"name", "apple"}, {"qty", 5}, {"tags", bson.A{"red", "fruit"}}},
+			bson.D{{"_id", 2}, {"name", "banana"}, {"qty", 12.5}, {"tags", bson.A{"yellow", "fruit"}}},
+			bson.D{{"_id", 3}, {"name", "carrot"}, {"qty", int64(20)}, {"tags", bson.A{"orange"}}},
+			bson.D{{"_id", 4}, {"name", "date"}, {"items", bson.A{bson.D{{"size", 1}}, bson.D{{"size", 3}}}}},
+		})
+		assert.Nil(t, err, "InsertMany error: %v", err)
+
+		testCases := []struct {
+			name   string
+			filter bson.D
+			ids    []interface{}
+		}{
+			{"mixed numeric types", bson.D{{"qty", bson.D{{"$gt", 5}, {"$lte", 20}}}}, []interface{}{int32(2), int32(3)}},
+			{"array element", bson.D{{"tags", "fruit"}}, []interface{}{int32(1), int32(2)}},
+			{"in", bson.D{{"name", bson.D{{"$in", bson.A{"apple", "date"}}}}}, []interface{}{int32(1), int32(4)}},
+			{"or", bson.D{{"$or", bson.A{bson.D{{"_id", 1}}, bson.D{{"qty", 12.5}}}}}, []interface{}{int32(1), int32(2)}},
+			{"regex", bson.D{{"name", bson.D{{"$regex", "^C"}, {"$options", "i"}}}}, []interface{}{int32(3)}},
+			{"not exists", bson.D{{"qty", bson.D{{"$exists", false}}}}, []interface{}{int32(4)}},
+			{"null matches missing", bson.D{{"tags", nil}}, []interface{}{int32(4)}},
+			{"size", bson.D{{"tags", bson.D{{"$size", 1}}}}, []interface{}{int32(3)}},
+			{"all", bson.D{{"tags", bson.D{{"$all", bson.A{"fruit", "red"}}}}}, []interface{}{int32(1)}},
+			{"nested path in array", bson.D{{"items.size", 3}}, []interface{}{int32(4)}},
+			{"elemMatch", bson.D{{"items", bson.D{{"$elemMatch", bson.D{{"size", bson.D{{"$gt", 2}}}}}}}},
+				[]interface{}{int32(4)}},
+			{"not", bson.D{{"qty", bson.D{{"$not", bson.D{{"$lt", 10}}}}}}, []interface{}{int32(2), int32(3), int32(4)}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				got := ids(findAll(t, coll, tc.filter))
+				assert.Equal(t, tc.ids, got, "expected IDs %v, got %v", tc.ids, got)
+			})
+		}
+
+		opts := options.Find().SetSort(bson.D{{"qty", -1}}).SetSkip(1).SetLimit(2).SetProjection(bson.D{{"name", 1}})
+		docs := findAll(t, coll, bson.D{}, opts)
+		expected := []bson.D{{{"_id", int32(2)}, {"name", "banana"}}, {{"_id", int32(1)}, {"name", "apple"}}}
+		assert.Equal(t, expected, docs, "expected documents %v, got %v", expected, docs)
+
+		docs = findAll(t, coll, bson.D{}, options.Find().SetBatchSize(1))
+		assert.Equal(t, 4, len(docs), "expected 4 documents across batches, got %d", len(docs))
+
+		values, err := coll.Distinct(ctx, "tags", bson.D{})
+		assert.Nil(t, err, "Distinct error: %v", err)
+		assert.Equal(t, []interface{}{"fruit", "orange", "red", "yellow"}, values, "unexpected distinct values %v",
+			values)
+
+		_, err = coll.Find(ctx, bson.D{{"$where", "true"}})
+		assert.NotNil(t, err, "expected an error for an unsupported operator")
+	})
+	t.Run("update operators", func(t *testing.T) {
+		client, coll := newTestCollection(t)
+		defer func() { _ = client.Disconnect(ctx) }()
+
+		_, err := coll.InsertOne(ctx, bson.D{{"_id", 1}, {"a", bson.D{{"b", 1}}}, {"list", bson.A{1, 2, 3}}})
+		assert.Nil(t, err, "InsertOne error: %v", err)
+
+		update := bson.D{
+			{"$set", bson.D{{"a.c", "new"}}},
+			{"$unset", bson.D{{"a.b", ""}}},
+			{"$push", bson.D{{"list", bson.D{{"$each", bson.A{4, 5}}}}}},
+			{"$pull", bson.D{{"list", bson.D{{"$lt", 3}}}}},
+			{"$max", bson.D{{"score", 10}}},
+			{"$rename", bson.D{{"a", "renamed"}}},
+		}
+		var after bson.D
+		err = coll.FindOneAndUpdate(ctx, bson.D{{"_id", 1}}, update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&after)
+		assert.Nil(t, err, "FindOneAndUpdate error: %v", err)
+		expected := bson.D{
+			{"_id", int32(1)},
+			{"list", bson.A{int32(3), int32(4), int32(5)}},
+			{"score", int32(10)},
+			{"renamed", bson.D{{"c", "new"}}},
+		}
+		assert.Equal(t, expected, after, "expected document %v, got %v", expected, after)
+
+		_, err = coll.UpdateOne(ctx, bson.D{{"_id", 1}}, bson.D{{"$inc", bson.D{{"renamed", 1}}}})
+		assert.NotNil(t, err, "expected an error incrementing a document")
+
+		_, err = coll.UpdateOne(ctx, bson.D{{"_id", 1}}, bson.D{{"$set", bson.D{{"_id", 2}}}})
+		assert.NotNil(t, err, "expected an error modifying _id")
+	})
+	t.Run("unique indexes", func(t *testing.T) {
+		client, coll := newTestCollection(t)
+		defer func() { _ = client.Disconnect(ctx) }()
+
+		name, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{"email", 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		assert.Nil(t, err, "CreateOne error: %v", err)
+		assert.Equal(t, "email_1", name, "expected index name email_1, got %v", name)
+
+		_, err = coll.InsertOne(ctx, bson.D{{"_id", 1}, {"email", "a@example.com"}})
+		assert.Nil(t, err, "InsertOne error: %v", err)
+
+		_, err = coll.InsertOne(ctx, bson.D{{"_id", 2}, {"email", "a@example.com"}})
+		var we mongo.WriteException
+		assert.True(t, errors.As(err, &we), "expected WriteException, got %v", err)
+		assert.Equal(t, 11000, we.WriteErrors[0].Code, "expected duplicate key error, got %v", we)
+
+		_, err = coll.InsertOne(ctx, bson.D{{"_id", 1}})
+		assert.True(t, errors.As(err, &we), "expected WriteException for duplicate _id, got %v", err)
+
+		_, err = coll.UpdateOne(ctx, bson.D{{"_id", 1}}, bson.D{{"$set", bson.D{{"email", "b@example.com"}}}})
+		assert.Nil(t, err, "UpdateOne error: %v", err)
+		_, err = coll.InsertOne(ctx, bson.D{{"_id", 2}, {"email", "a@example.com"}})
+		assert.Nil(t, err, "expected the old key to be released after an update, got %v", err)
+
+		cursor, err := coll.Indexes().List(ctx)
+		assert.Nil(t, err, "List error: %v", err)
+		var specs []bson.M
+		err = cursor.All(ctx, &specs)
+		assert.Nil(t, err, "All error: %v", err)
+		assert.Equal(t, 2, len(specs), "expected 2 indexes, got %d", len(specs))
+
+		_, err = coll.Indexes().DropOne(ctx, "email_1")
+		assert.Nil(t, err, "DropOne error: %v", err)
+		_, err = coll.InsertOne(ctx, bson.D{{"_id", 3}, {"email", "a@example.com"}})
+		assert.Nil(t, err, "expected duplicates to be allowed after dropping the index, got %v", err)
+	})
+	t.Run("aggregate", func(t *testing.T) {
+		client, coll := newTestCollection(t)
+		defer func() { _ = client.Disconnect(ctx) }()
+
+		_, err := coll.InsertMany(ctx, []interface{}{
+			bson.D{{"kind", "a"}, {"n", 1}},
+			bson.D{{"kind", "b"}, {"n", 2}},
+			bson.D{{"kind", "a"}, {"n", 3}},
+		})
+		assert.Nil(t, err, "InsertMany error: %v", err)
+
+		cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+			{{"$group", bson.D{{"_id", "$kind"}, {"total", bson.D{{"$sum", "$n"}}}}}},
+			{{"$sort", bson.D{{"total", -1}}}},
+		})
+		assert.Nil(t, err, "Aggregate error: %v", err)
+		var docs []bson.D
+		err = cursor.All(ctx, &docs)
+		assert.Nil(t, err, "All error: %v", err)
+		expected := []bson.D{{{"_id", "a"}, {"total", int32(4)}}, {{"_id", "b"}, {"total", int32(2)}}}
+		assert.Equal(t, expected, docs, "expected documents %v, got %v", expected, docs)
+	})
+	t.Run("databases and collections", func(t *testing.T) {
+		client, coll := newTestCollection(t)
+		defer func() { _ = client.Disconnect(ctx) }()
+
+		db := coll.Database()
+		err := db.RunCommand(ctx, bson.D{{"create", "created"}}).Err()
+		assert.Nil(t, err, "create error: %v", err)
+		err = db.RunCommand(ctx, bson.D{{"create", "created"}}).Err()
+		assert.NotNil(t, err, "expected an error creating an existing collection")
+		_, err = coll.InsertOne(ctx, bson.D{})
+		assert.Nil(t, err, "InsertOne error: %v", err)
+
+		names, err := db.ListCollectionNames(ctx, bson.D{})
+		assert.Nil(t, err, "ListCollectionNames error: %v", err)
+		assert.Equal(t, []string{"coll", "created"}, names, "unexpected collection names %v", names)
+
+		err = db.Collection("created").Drop(ctx)
+		assert.Nil(t, err, "Drop error: %v", err)
+		err = db.Collection("missing").Drop(ctx)
+		assert.Nil(t, err, "expected dropping a missing collection to succeed, got %v", err)
+
+		dbNames, err := client.ListDatabaseNames(ctx, bson.D{})
+		assert.Nil(t, err, "ListDatabaseNames error: %v", err)
+		assert.Equal(t, []string{"db"}, dbNames, "unexpected database names %v", dbNames)
+
+		err = db.Drop(ctx)
+		assert.Nil(t, err, "Drop error: %v", err)
+		dbNames, err = client.ListDatabaseNames(ctx, bson.D{})
+		assert.Nil(t, err, "ListDatabaseNames error: %v", err)
+		assert.Equal(t, 0, len(dbNames), "expected no databases, got %v", dbNames)
+	})
+	t.Run("transactions", func(t *testing.T) {
+		client, coll := newTestCollection(t)
+		defer func() { _ = client.Disconnect(ctx) }()
+
+		sess, err := client.StartSession()
+		assert.Nil(t, err, "StartSession error: %v", err)
+		defer sess.EndSession(ctx)
+
+		_, err = sess.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+			return coll.InsertOne(sc, bson.D{{"_id", "committed"}})
+		})
+		assert.Nil(t, err, "WithTransaction error: %v", err)
+
+		err = sess.StartTransaction()
+		assert.Nil(t, err, "StartTransaction error: %v", err)
+		err = mongo.WithSession(ctx, sess, func(sc mongo.SessionContext) error {
+			_, err := coll.InsertOne(sc, bson.D{{"_id", "aborted"}})
+			return err
+		})
+		assert.Nil(t, err, "InsertOne error: %v", err)
+		err = sess.AbortTransaction(ctx)
+		assert.Nil(t, err, "AbortTransaction error: %v", err)
+
+		got := ids(findAll(t, coll, bson.D{}))
+		assert.Equal(t, []interface{}{"committed"}, got, "expected only the committed document, got %v", got)
+	})
+}
@@ -0,0 +1,414 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongofake
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// copyValue returns a deep copy of v. Stored documents are never modified in place, so that documents handed out to
+// cursors and transaction snapshots are not affected by later updates.
+func copyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case bson.D:
+		out := make(bson.D, len(val))
+		for i, e := range val {
+			out[i] = bson.E{Key: e.Key, Value: copyValue(e.Value)}
+		}
+		return out
+	case bson.A:
+		out := make(bson.A, len(val))
+		for i, elem := range val {
+			out[i] = copyValue(elem)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func copyDocument(doc bson.D) bson.D {
+	return copyValue(doc).(bson.D)
+}
+
+// isReplacement reports whether update is a replacement document rather than a document of update operators.
+func isReplacement(update bson.D) bool {
+	return len(update) == 0 || !strings.HasPrefix(update[0].Key, "$")
+}
+
+// applyUpdate returns the result of applying update to a copy of doc. If insert is true, the update is part of an
+// upsert that inserts doc and $setOnInsert is applied.
+func applyUpdate(doc, update bson.D, insert bool) (bson.D, error) {
+	if isReplacement(update) {
+		for _, e := range update {
+			if strings.HasPrefix(e.Key, "$") {
+				return nil, badValue("the replacement document cannot contain operator %s", e.Key)
+			}
+		}
+		out := bson.D{}
+		if id, ok := lookupKey(doc, "_id"); ok {
+			if newID, ok := lookupKey(update, "_id"); ok && !equalValues(id, newID) {
+				return nil, commandError{Code: 66, Name: "ImmutableField",
+					Message: "the (immutable) field '_id' was found to have been altered"}
+			}
+			out = append(out, bson.E{Key: "_id", Value: id})
+		}
+		for _, e := range update {
+			if e.Key != "_id" || len(out) == 0 {
+				out = append(out, bson.E{Key: e.Key, Value: copyValue(e.Value)})
+			}
+		}
+		return out, nil
+	}
+
+	out := copyDocument(doc)
+	oldID, hadID := lookupKey(doc, "_id")
+	for _, e := range update {
+		args, ok := e.Value.(bson.D)
+		if !ok {
+			return nil, commandError{Code: 9, Name: "FailedToParse",
+				Message: "modifiers operate on fields but we found another type instead for " + e.Key}
+		}
+		for _, arg := range args {
+			var err error
+			out, err = applyOperator(out, e.Key, arg.Key, arg.Value, insert)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if newID, ok := lookupKey(out, "_id"); hadID && (!ok || !equalValues(oldID, newID)) {
+		return nil, commandError{Code: 66, Name: "ImmutableField",
+			Message: "performing an update on the path '_id' would modify the immutable field '_id'"}
+	}
+	return out, nil
+}
+
+func applyOperator(doc bson.D, op, path string, arg interface{}, insert bool) (bson.D, error) {
+	parts := strings.Split(path, ".")
+	current, exists := getPath(doc, parts)
+
+	switch op {
+	case "$set":
+		return setPath(doc, parts, copyValue(arg))
+	case "$setOnInsert":
+		if !insert {
+			return doc, nil
+		}
+		return setPath(doc, parts, copyValue(arg))
+	case "$unset":
+		return unsetPath(doc, parts), nil
+	case "$inc", "$mul":
+		if !isNumber(arg) {
+			return nil, typeMismatch("cannot %s with non-numeric argument: {%s: %v}", op[1:], path, arg)
+		}
+		if !exists {
+			if op == "$mul" {
+				arg = multiplyNumbers(arg, int32(0))
+			}
+			return setPath(doc, parts, arg)
+		}
+		if !isNumber(current) {
+			return nil, typeMismatch("cannot apply %s to a value of non-numeric type for field %s", op, path)
+		}
+		result := addNumbers(current, arg)
+		if op == "$mul" {
+			result = multiplyNumbers(current, arg)
+		}
+		if result == nil {
+			return nil, notImplemented("%s is not supported for decimal values", op)
+		}
+		return setPath(doc, parts, result)
+	case "$min", "$max":
+		c := compareValues(arg, current)
+		if !exists || (op == "$min" && c < 0) || (op == "$max" && c > 0) {
+			return setPath(doc, parts, copyValue(arg))
+		}
+		return doc, nil
+	case "$currentDate":
+		now := time.Now()
+		var value interface{} = primitive.NewDateTimeFromTime(now)
+		if spec, ok := arg.(bson.D); ok {
+			if typ, _ := lookupKey(spec, "$type"); typ == "timestamp" {
+				value = primitive.Timestamp{T: uint32(now.Unix())}
+			}
+		}
+		return setPath(doc, parts, value)
+	case "$rename":
+		newPath, ok := arg.(string)
+		if !ok {
+			return nil, badValue("the 'to' field for $rename must be a string: %s", path)
+		}
+		if !exists {
+			return doc, nil
+		}
+		return setPath(unsetPath(doc, parts), strings.Split(newPath, "."), current)
+	case "$push", "$addToSet", "$pull", "$pullAll", "$pop":
+		var arr bson.A
+		if exists {
+			var ok bool
+			if arr, ok = current.(bson.A); !ok {
+				return nil, typeMismatch("the field '%s' must be an array", path)
+			}
+		} else if op != "$push" && op != "$addToSet" {
+			return doc, nil
+		}
+		result, err := applyArrayOperator(op, append(bson.A(nil), arr...), arg)
+		if err != nil {
+			return nil, err
+		}
+		return setPath(doc, parts, result)
+	default:
+		return nil, commandError{Code: 9, Name: "FailedToParse", Message: "unknown modifier: " + op}
+	}
+}
+
+func applyArrayOperator(op string, arr bson.A, arg interface{}) (bson.A, error) {
+	switch op {
+	case "$push", "$addToSet":
+		each := bson.A{arg}
+		var slice interface{}
+		if spec, ok := arg.(bson.D); ok && len(spec) > 0 && spec[0].Key == "$each" {
+			values, ok := spec[0].Value.(bson.A)
+			if !ok {
+				return nil, badValue("the argument to $each in %s must be an array", op)
+			}
+			each = values
+			for _, e := range spec[1:] {
+				if e.Key != "$slice" || op != "$push" {
+					return nil, notImplemented("unsupported %s modifier %s", op, e.Key)
+				}
+				slice = e.Value
+			}
+		}
+		for _, v := range each {
+			if op == "$addToSet" && containsValue(arr, v) {
+				continue
+			}
+			arr = append(arr, copyValue(v))
+		}
+		if slice != nil {
+			n, ok := intValue(slice)
+			if !ok {
+				return nil, badValue("$slice must be an integer")
+			}
+			switch {
+			case n >= 0 && int(n) < len(arr):
+				arr = arr[:n]
+			case n < 0 && int(-n) < len(arr):
+				arr = arr[len(arr)+int(n):]
+			}
+		}
+		return arr, nil
+	case "$pull", "$pullAll":
+		remove := func(elem interface{}) (bool, error) {
+			if op == "$pullAll" {
+				values, ok := arg.(bson.A)
+				if !ok {
+					return false, badValue("$pullAll requires an array argument")
+				}
+				return containsValue(values, elem), nil
+			}
+			if cond, ok := arg.(bson.D); ok {
+				return matchElemMatch(elem, cond)
+			}
+			return equalValues(elem, arg), nil
+		}
+		out := bson.A{}
+		for _, elem := range arr {
+			removed, err := remove(elem)
+			if err != nil {
+				return nil, err
+			}
+			if !removed {
+				out = append(out, elem)
+			}
+		}
+		return out, nil
+	default: // $pop
+		if len(arr) == 0 {
+			return arr, nil
+		}
+		if floatValue(arg) < 0 {
+			return arr[1:], nil
+		}
+		return arr[:len(arr)-1], nil
+	}
+}
+
+func containsValue(arr bson.A, v interface{}) bool {
+	for _, elem := range arr {
+		if equalValues(elem, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// getPath returns the value at a dotted path without traversing arrays element-wise. Numeric path components index
+// into arrays.
+func getPath(v interface{}, path []string) (interface{}, bool) {
+	for _, key := range path {
+		switch val := v.(type) {
+		case bson.D:
+			var ok bool
+			if v, ok = lookupKey(val, key); !ok {
+				return nil, false
+			}
+		case bson.A:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(val) {
+				return nil, false
+			}
+			v = val[idx]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// setPath sets the value at a dotted path in doc, creating embedded documents as needed.
+func setPath(doc bson.D, path []string, value interface{}) (bson.D, error) {
+	out, err := setValue(doc, path, value)
+	if err != nil {
+		return nil, err
+	}
+	return out.(bson.D), nil
+}
+
+func setValue(container interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	switch val := container.(type) {
+	case bson.D:
+		for i, e := range val {
+			if e.Key == path[0] {
+				child, err := setValue(e.Value, path[1:], value)
+				if err != nil {
+					return nil, err
+				}
+				val[i].Value = child
+				return val, nil
+			}
+		}
+		child, err := setValue(bson.D{}, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		return append(val, bson.E{Key: path[0], Value: child}), nil
+	case bson.A:
+		idx, err := strconv.Atoi(path[0])
+		if err != nil || idx < 0 {
+			return nil, commandError{Code: 28, Name: "PathNotViable",
+				Message: "cannot create field '" + path[0] + "' in an array"}
+		}
+		for len(val) <= idx {
+			val = append(val, nil)
+		}
+		var elem interface{} = bson.D{}
+		if val[idx] != nil {
+			elem = val[idx]
+		}
+		child, err := setValue(elem, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		val[idx] = child
+		return val, nil
+	default:
+		return nil, commandError{Code: 28, Name: "PathNotViable",
+			Message: "cannot create field '" + path[0] + "' in a non-document value"}
+	}
+}
+
+// unsetPath removes the field at a dotted path from doc. Array elements are set to null rather than removed.
+func unsetPath(doc bson.D, path []string) bson.D {
+	return unsetValue(doc, path).(bson.D)
+}
+
+func unsetValue(container interface{}, path []string) interface{} {
+	switch val := container.(type) {
+	case bson.D:
+		for i, e := range val {
+			if e.Key != path[0] {
+				continue
+			}
+			if len(path) == 1 {
+				return append(val[:i:i], val[i+1:]...)
+			}
+			val[i].Value = unsetValue(e.Value, path[1:])
+			break
+		}
+	case bson.A:
+		idx, err := strconv.Atoi(path[0])
+		if err != nil || idx < 0 || idx >= len(val) {
+			break
+		}
+		if len(path) == 1 {
+			val[idx] = nil
+		} else {
+			val[idx] = unsetValue(val[idx], path[1:])
+		}
+	}
+	return container
+}
+
+// addNumbers returns a+b using the widest type of the operands. Integer results that overflow an int32 are promoted to
+// int64. Nil is returned if either operand is a decimal.
+func addNumbers(a, b interface{}) interface{} {
+	if isDecimal(a) || isDecimal(b) {
+		return nil
+	}
+	ai, aInt := intValue(a)
+	bi, bInt := intValue(b)
+	if !aInt || !bInt {
+		return floatValue(a) + floatValue(b)
+	}
+	return narrowInt(ai+bi, a, b)
+}
+
+// multiplyNumbers returns a*b using the same type rules as addNumbers.
+func multiplyNumbers(a, b interface{}) interface{} {
+	if isDecimal(a) || isDecimal(b) {
+		return nil
+	}
+	ai, aInt := intValue(a)
+	bi, bInt := intValue(b)
+	if !aInt || !bInt {
+		return floatValue(a) * floatValue(b)
+	}
+	return narrowInt(ai*bi, a, b)
+}
+
+func narrowInt(n int64, a, b interface{}) interface{} {
+	_, a32 := a.(int32)
+	_, b32 := b.(int32)
+	if a32 && b32 && n >= math.MinInt32 && n <= math.MaxInt32 {
+		return int32(n)
+	}
+	return n
+}
+
+func isDecimal(v interface{}) bool {
+	_, ok := v.(primitive.Decimal128)
+	return ok
+}
+
+func typeMismatch(format string, args ...interface{}) error {
+	err := badValue(format, args...).(commandError)
+	err.Code, err.Name = 14, "TypeMismatch"
+	return err
+}
@@ -0,0 +1,545 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongoiface
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+func notMocked(mock, method string) string {
+	return fmt.Sprintf("mongoiface: %s.%s called, but %sFunc is nil", mock, method, method)
+}
+
+// ClientMock is a Client whose methods call the function fields with the same name and a Func suffix, e.g.
+// Connect calls ConnectFunc. Calling a method whose function field is nil panics.
+type ClientMock struct {
+	ConnectFunc func(context.Context) error
+
+	DisconnectFunc func(context.Context) error
+
+	PingFunc func(context.Context, *readpref.ReadPref) error
+
+	StartSessionFunc func(...*options.SessionOptions) (mongo.Session, error)
+
+	DatabaseFunc func(string, ...*options.DatabaseOptions) Database
+
+	ListDatabasesFunc func(context.Context, interface{},
+		...*options.ListDatabasesOptions) (mongo.ListDatabasesResult, error)
+
+	ListDatabaseNamesFunc func(context.Context, interface{}, ...*options.ListDatabasesOptions) ([]string, error)
+
+	UseSessionFunc func(context.Context, func(mongo.SessionContext) error) error
+
+	UseSessionWithOptionsFunc func(context.Context, *options.SessionOptions, func(mongo.SessionContext) error) error
+
+	WatchFunc func(context.Context, interface{}, ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error)
+
+	NumberSessionsInProgressFunc func() int
+}
+
+var _ Client = (*ClientMock)(nil)
+
+// Connect calls ConnectFunc.
+func (m *ClientMock) Connect(ctx context.Context) error {
+	if m.ConnectFunc == nil {
+		panic(notMocked("ClientMock", "Connect"))
+	}
+	return m.ConnectFunc(ctx)
+}
+
+// Disconnect calls DisconnectFunc.
+func (m *ClientMock) Disconnect(ctx context.Context) error {
+	if m.DisconnectFunc == nil {
+		panic(notMocked("ClientMock", "Disconnect"))
+	}
+	return m.DisconnectFunc(ctx)
+}
+
+// Ping calls PingFunc.
+func (m *ClientMock) Ping(ctx context.Context, rp *readpref.ReadPref) error {
+	if m.PingFunc == nil {
+		panic(notMocked("ClientMock", "Ping"))
+	}
+	return m.PingFunc(ctx, rp)
+}
+
+// StartSession calls StartSessionFunc.
+func (m *ClientMock) StartSession(opts ...*options.SessionOptions) (mongo.Session, error) {
+	if m.StartSessionFunc == nil {
+		panic(notMocked("ClientMock", "StartSession"))
+	}
+	return m.StartSessionFunc(opts...)
+}
+
+// Database calls DatabaseFunc.
+func (m *ClientMock) Database(name string, opts ...*options.DatabaseOptions) Database {
+	if m.DatabaseFunc == nil {
+		panic(notMocked("ClientMock", "Database"))
+	}
+	return m.DatabaseFunc(name, opts...)
+}
+
+// ListDatabases calls ListDatabasesFunc.
+func (m *ClientMock) ListDatabases(ctx context.Context, filter interface{},
+	opts ...*options.ListDatabasesOptions) (mongo.ListDatabasesResult, error) {
+	if m.ListDatabasesFunc == nil {
+		panic(notMocked("ClientMock", "ListDatabases"))
+	}
+	return m.ListDatabasesFunc(ctx, filter, opts...)
+}
+
+// ListDatabaseNames calls ListDatabaseNamesFunc.
+func (m *ClientMock) ListDatabaseNames(ctx context.Context, filter interface{},
+	opts ...*options.ListDatabasesOptions) ([]string, error) {
+	if m.ListDatabaseNamesFunc == nil {
+		panic(notMocked("ClientMock", "ListDatabaseNames"))
+	}
+	return m.ListDatabaseNamesFunc(ctx, filter, opts...)
+}
+
+// UseSession calls UseSessionFunc.
+func (m *ClientMock) UseSession(ctx context.Context, fn func(mongo.SessionContext) error) error {
+	if m.UseSessionFunc == nil {
+		panic(notMocked("ClientMock", "UseSession"))
+	}
+	return m.UseSessionFunc(ctx, fn)
+}
+
+// UseSessionWithOptions calls UseSessionWithOptionsFunc.
+func (m *ClientMock) UseSessionWithOptions(ctx context.Context, opts *options.SessionOptions,
+	fn func(mongo.SessionContext) error) error {
+	if m.UseSessionWithOptionsFunc == nil {
+		panic(notMocked("ClientMock", "UseSessionWithOptions"))
+	}
+	return m.UseSessionWithOptionsFunc(ctx, opts, fn)
+}
+
+// Watch calls WatchFunc.
+func (m *ClientMock) Watch(ctx context.Context, pipeline interface{},
+	opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	if m.WatchFunc == nil {
+		panic(notMocked("ClientMock", "Watch"))
+	}
+	return m.WatchFunc(ctx, pipeline, opts...)
+}
+
+// NumberSessionsInProgress calls NumberSessionsInProgressFunc.
+func (m *ClientMock) NumberSessionsInProgress() int {
+	if m.NumberSessionsInProgressFunc == nil {
+		panic(notMocked("ClientMock", "NumberSessionsInProgress"))
+	}
+	return m.NumberSessionsInProgressFunc()
+}
+
+// DatabaseMock is a Database whose methods call the function fields with the same name and a Func suffix, e.g.
+// RunCommand calls RunCommandFunc. Calling a method whose function field is nil panics.
+type DatabaseMock struct {
+	ClientFunc func() Client
+
+	NameFunc func() string
+
+	CollectionFunc func(string, ...*options.CollectionOptions) Collection
+
+	AggregateFunc func(context.Context, interface{}, ...*options.AggregateOptions) (*mongo.Cursor, error)
+
+	RunCommandFunc func(context.Context, interface{}, ...*options.RunCmdOptions) *mongo.SingleResult
+
+	RunCommandCursorFunc func(context.Context, interface{}, ...*options.RunCmdOptions) (*mongo.Cursor, error)
+
+	DropFunc func(context.Context) error
+
+	ListCollectionsFunc func(context.Context, interface{}, ...*options.ListCollectionsOptions) (*mongo.Cursor, error)
+
+	ListCollectionNamesFunc func(context.Context, interface{}, ...*options.ListCollectionsOptions) ([]string, error)
+
+	ReadConcernFunc func() *readconcern.ReadConcern
+
+	ReadPreferenceFunc func() *readpref.ReadPref
+
+	WriteConcernFunc func() *writeconcern.WriteConcern
+
+	WatchFunc func(context.Context, interface{}, ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error)
+}
+
+var _ Database = (*DatabaseMock)(nil)
+
+// Client calls ClientFunc.
+func (m *DatabaseMock) Client() Client {
+	if m.ClientFunc == nil {
+		panic(notMocked("DatabaseMock", "Client"))
+	}
+	return m.ClientFunc()
+}
+
+// Name calls NameFunc.
+func (m *DatabaseMock) Name() string {
+	if m.NameFunc == nil {
+		panic(notMocked("DatabaseMock", "Name"))
+	}
+	return m.NameFunc()
+}
+
+// Collection calls CollectionFunc.
+func (m *DatabaseMock) Collection(name string, opts ...*options.CollectionOptions) Collection {
+	if m.CollectionFunc == nil {
+		panic(notMocked("DatabaseMock", "Collection"))
+	}
+	return m.CollectionFunc(name, opts...)
+}
+
+// Aggregate calls AggregateFunc.
+func (m *DatabaseMock) Aggregate(ctx context.Context, pipeline interface{},
+	opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	if m.AggregateFunc == nil {
+		panic(notMocked("DatabaseMock", "Aggregate"))
+	}
+	return m.AggregateFunc(ctx, pipeline, opts...)
+}
+
+// RunCommand calls RunCommandFunc.
+func (m *DatabaseMock) RunCommand(ctx context.Context, runCommand interface{},
+	opts ...*options.RunCmdOptions) *mongo.SingleResult {
+	if m.RunCommandFunc == nil {
+		panic(notMocked("DatabaseMock", "RunCommand"))
+	}
+	return m.RunCommandFunc(ctx, runCommand, opts...)
+}
+
+// RunCommandCursor calls RunCommandCursorFunc.
+func (m *DatabaseMock) RunCommandCursor(ctx context.Context, runCommand interface{},
+	opts ...*options.RunCmdOptions) (*mongo.Cursor, error) {
+	if m.RunCommandCursorFunc == nil {
+		panic(notMocked("DatabaseMock", "RunCommandCursor"))
+	}
+	return m.RunCommandCursorFunc(ctx, runCommand, opts...)
+}
+
+// Drop calls DropFunc.
+func (m *DatabaseMock) Drop(ctx context.Context) error {
+	if m.DropFunc == nil {
+		panic(notMocked("DatabaseMock", "Drop"))
+	}
+	return m.DropFunc(ctx)
+}
+
+// ListCollections calls ListCollectionsFunc.
+func (m *DatabaseMock) ListCollections(ctx context.Context, filter interface{},
+	opts ...*options.ListCollectionsOptions) (*mongo.Cursor, error) {
+	if m.ListCollectionsFunc == nil {
+		panic(notMocked("DatabaseMock", "ListCollections"))
+	}
+	return m.ListCollectionsFunc(ctx, filter, opts...)
+}
+
+// ListCollectionNames calls ListCollectionNamesFunc.
+func (m *DatabaseMock) ListCollectionNames(ctx context.Context, filter interface{},
+	opts ...*options.ListCollectionsOptions) ([]string, error) {
+	if m.ListCollectionNamesFunc == nil {
+		panic(notMocked("DatabaseMock", "ListCollectionNames"))
+	}
+	return m.ListCollectionNamesFunc(ctx, filter, opts...)
+}
+
+// ReadConcern calls ReadConcernFunc.
+func (m *DatabaseMock) ReadConcern() *readconcern.ReadConcern {
+	if m.ReadConcernFunc == nil {
+		panic(notMocked("DatabaseMock", "ReadConcern"))
+	}
+	return m.ReadConcernFunc()
+}
+
+// ReadPreference calls ReadPreferenceFunc.
+func (m *DatabaseMock) ReadPreference() *readpref.ReadPref {
+	if m.ReadPreferenceFunc == nil {
+		panic(notMocked("DatabaseMock", "ReadPreference"))
+	}
+	return m.ReadPreferenceFunc()
+}
+
+// WriteConcern calls WriteConcernFunc.
+func (m *DatabaseMock) WriteConcern() *writeconcern.WriteConcern {
+	if m.WriteConcernFunc == nil {
+		panic(notMocked("DatabaseMock", "WriteConcern"))
+	}
+	return m.WriteConcernFunc()
+}
+
+// Watch calls WatchFunc.
+func (m *DatabaseMock) Watch(ctx context.Context, pipeline interface{},
+	opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	if m.WatchFunc == nil {
+		panic(notMocked("DatabaseMock", "Watch"))
+	}
+	return m.WatchFunc(ctx, pipeline, opts...)
+}
+
+// CollectionMock is a Collection whose methods call the function fields with the same name and a Func suffix, e.g.
+// InsertOne calls InsertOneFunc. Calling a method whose function field is nil panics.
+type CollectionMock struct {
+	CloneFunc func(...*options.CollectionOptions) (Collection, error)
+
+	NameFunc func() string
+
+	DatabaseFunc func() Database
+
+	BulkWriteFunc func(context.Context, []mongo.WriteModel,
+		...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error)
+
+	InsertOneFunc func(context.Context, interface{}, ...*options.InsertOneOptions) (*mongo.InsertOneResult, error)
+
+	InsertManyFunc func(context.Context, []interface{}, ...*options.InsertManyOptions) (*mongo.InsertManyResult, error)
+
+	DeleteOneFunc func(context.Context, interface{}, ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+
+	DeleteManyFunc func(context.Context, interface{}, ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+
+	UpdateOneFunc func(context.Context, interface{}, interface{},
+		...*options.UpdateOptions) (*mongo.UpdateResult, error)
+
+	UpdateManyFunc func(context.Context, interface{}, interface{},
+		...*options.UpdateOptions) (*mongo.UpdateResult, error)
+
+	ReplaceOneFunc func(context.Context, interface{}, interface{},
+		...*options.ReplaceOptions) (*mongo.UpdateResult, error)
+
+	AggregateFunc func(context.Context, interface{}, ...*options.AggregateOptions) (*mongo.Cursor, error)
+
+	CountDocumentsFunc func(context.Context, interface{}, ...*options.CountOptions) (int64, error)
+
+	EstimatedDocumentCountFunc func(context.Context, ...*options.EstimatedDocumentCountOptions) (int64, error)
+
+	DistinctFunc func(context.Context, string, interface{}, ...*options.DistinctOptions) ([]interface{}, error)
+
+	FindFunc func(context.Context, interface{}, ...*options.FindOptions) (*mongo.Cursor, error)
+
+	FindOneFunc func(context.Context, interface{}, ...*options.FindOneOptions) *mongo.SingleResult
+
+	FindOneAndDeleteFunc func(context.Context, interface{}, ...*options.FindOneAndDeleteOptions) *mongo.SingleResult
+
+	FindOneAndReplaceFunc func(context.Context, interface{}, interface{},
+		...*options.FindOneAndReplaceOptions) *mongo.SingleResult
+
+	FindOneAndUpdateFunc func(context.Context, interface{}, interface{},
+		...*options.FindOneAndUpdateOptions) *mongo.SingleResult
+
+	WatchFunc func(context.Context, interface{}, ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error)
+
+	IndexesFunc func() mongo.IndexView
+
+	DropFunc func(context.Context) error
+}
+
+var _ Collection = (*CollectionMock)(nil)
+
+// Clone calls CloneFunc.
+func (m *CollectionMock) Clone(opts ...*options.CollectionOptions) (Collection, error) {
+	if m.CloneFunc == nil {
+		panic(notMocked("CollectionMock", "Clone"))
+	}
+	return m.CloneFunc(opts...)
+}
+
+// Name calls NameFunc.
+func (m *CollectionMock) Name() string {
+	if m.NameFunc == nil {
+		panic(notMocked("CollectionMock", "Name"))
+	}
+	return m.NameFunc()
+}
+
+// Database calls DatabaseFunc.
+func (m *CollectionMock) Database() Database {
+	if m.DatabaseFunc == nil {
+		panic(notMocked("CollectionMock", "Database"))
+	}
+	return m.DatabaseFunc()
+}
+
+// BulkWrite calls BulkWriteFunc.
+func (m *CollectionMock) BulkWrite(ctx context.Context, models []mongo.WriteModel,
+	opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	if m.BulkWriteFunc == nil {
+		panic(notMocked("CollectionMock", "BulkWrite"))
+	}
+	return m.BulkWriteFunc(ctx, models, opts...)
+}
+
+// InsertOne calls InsertOneFunc.
+func (m *CollectionMock) InsertOne(ctx context.Context, document interface{},
+	opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	if m.InsertOneFunc == nil {
+		panic(notMocked("CollectionMock", "InsertOne"))
+	}
+	return m.InsertOneFunc(ctx, document, opts...)
+}
+
+// InsertMany calls InsertManyFunc.
+func (m *CollectionMock) InsertMany(ctx context.Context, documents []interface{},
+	opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	if m.InsertManyFunc == nil {
+		panic(notMocked("CollectionMock", "InsertMany"))
+	}
+	return m.InsertManyFunc(ctx, documents, opts...)
+}
+
+// DeleteOne calls DeleteOneFunc.
+func (m *CollectionMock) DeleteOne(ctx context.Context, filter interface{},
+	opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	if m.DeleteOneFunc == nil {
+		panic(notMocked("CollectionMock", "DeleteOne"))
+	}
+	return m.DeleteOneFunc(ctx, filter, opts...)
+}
+
+// DeleteMany calls DeleteManyFunc.
+func (m *CollectionMock) DeleteMany(ctx context.Context, filter interface{},
+	opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	if m.DeleteManyFunc == nil {
+		panic(notMocked("CollectionMock", "DeleteMany"))
+	}
+	return m.DeleteManyFunc(ctx, filter, opts...)
+}
+
+// UpdateOne calls UpdateOneFunc.
+func (m *CollectionMock) UpdateOne(ctx context.Context, filter interface{}, update interface{},
+	opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	if m.UpdateOneFunc == nil {
+		panic(notMocked("CollectionMock", "UpdateOne"))
+	}
+	return m.UpdateOneFunc(ctx, filter, update, opts...)
+}
+
+// UpdateMany calls UpdateManyFunc.
+func (m *CollectionMock) UpdateMany(ctx context.Context, filter interface{}, update interface{},
+	opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	if m.UpdateManyFunc == nil {
+		panic(notMocked("CollectionMock", "UpdateMany"))
+	}
+	return m.UpdateManyFunc(ctx, filter, update, opts...)
+}
+
+// ReplaceOne calls ReplaceOneFunc.
+func (m *CollectionMock) ReplaceOne(ctx context.Context, filter interface{}, replacement interface{},
+	opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	if m.ReplaceOneFunc == nil {
+		panic(notMocked("CollectionMock", "ReplaceOne"))
+	}
+	return m.ReplaceOneFunc(ctx, filter, replacement, opts...)
+}
+
+// Aggregate calls AggregateFunc.
+func (m *CollectionMock) Aggregate(ctx context.Context, pipeline interface{},
+	opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	if m.AggregateFunc == nil {
+		panic(notMocked("CollectionMock", "Aggregate"))
+	}
+	return m.AggregateFunc(ctx, pipeline, opts...)
+}
+
+// CountDocuments calls CountDocumentsFunc.
+func (m *CollectionMock) CountDocuments(ctx context.Context, filter interface{},
+	opts ...*options.CountOptions) (int64, error) {
+	if m.CountDocumentsFunc == nil {
+		panic(notMocked("CollectionMock", "CountDocuments"))
+	}
+	return m.CountDocumentsFunc(ctx, filter, opts...)
+}
+
+// EstimatedDocumentCount calls EstimatedDocumentCountFunc.
+func (m *CollectionMock) EstimatedDocumentCount(ctx context.Context,
+	opts ...*options.EstimatedDocumentCountOptions) (int64, error) {
+	if m.EstimatedDocumentCountFunc == nil {
+		panic(notMocked("CollectionMock", "EstimatedDocumentCount"))
+	}
+	return m.EstimatedDocumentCountFunc(ctx, opts...)
+}
+
+// Distinct calls DistinctFunc.
+func (m *CollectionMock) Distinct(ctx context.Context, fieldName string, filter interface{},
+	opts ...*options.DistinctOptions) ([]interface{}, error) {
+	if m.DistinctFunc == nil {
+		panic(notMocked("CollectionMock", "Distinct"))
+	}
+	return m.DistinctFunc(ctx, fieldName, filter, opts...)
+}
+
+// Find calls FindFunc.
+func (m *CollectionMock) Find(ctx context.Context, filter interface{},
+	opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	if m.FindFunc == nil {
+		panic(notMocked("CollectionMock", "Find"))
+	}
+	return m.FindFunc(ctx, filter, opts...)
+}
+
+// FindOne calls FindOneFunc.
+func (m *CollectionMock) FindOne(ctx context.Context, filter interface{},
+	opts ...*options.FindOneOptions) *mongo.SingleResult {
+	if m.FindOneFunc == nil {
+		panic(notMocked("CollectionMock", "FindOne"))
+	}
+	return m.FindOneFunc(ctx, filter, opts...)
+}
+
+// FindOneAndDelete calls FindOneAndDeleteFunc.
+func (m *CollectionMock) FindOneAndDelete(ctx context.Context, filter interface{},
+	opts ...*options.FindOneAndDeleteOptions) *mongo.SingleResult {
+	if m.FindOneAndDeleteFunc == nil {
+		panic(notMocked("CollectionMock", "FindOneAndDelete"))
+	}
+	return m.FindOneAndDeleteFunc(ctx, filter, opts...)
+}
+
+// FindOneAndReplace calls FindOneAndReplaceFunc.
+func (m *CollectionMock) FindOneAndReplace(ctx context.Context, filter interface{}, replacement interface{},
+	opts ...*options.FindOneAndReplaceOptions) *mongo.SingleResult {
+	if m.FindOneAndReplaceFunc == nil {
+		panic(notMocked("CollectionMock", "FindOneAndReplace"))
+	}
+	return m.FindOneAndReplaceFunc(ctx, filter, replacement, opts...)
+}
+
+// FindOneAndUpdate calls FindOneAndUpdateFunc.
+func (m *CollectionMock) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{},
+	opts ...*options.FindOneAndUpdateOptions) *mongo.SingleResult {
+	if m.FindOneAndUpdateFunc == nil {
+		panic(notMocked("CollectionMock", "FindOneAndUpdate"))
+	}
+	return m.FindOneAndUpdateFunc(ctx, filter, update, opts...)
+}
+
+// Watch calls WatchFunc.
+func (m *CollectionMock) Watch(ctx context.Context, pipeline interface{},
+	opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	if m.WatchFunc == nil {
+		panic(notMocked("CollectionMock", "Watch"))
+	}
+	return m.WatchFunc(ctx, pipeline, opts...)
+}
+
+// Indexes calls IndexesFunc.
+func (m *CollectionMock) Indexes() mongo.IndexView {
+	if m.IndexesFunc == nil {
+		panic(notMocked("CollectionMock", "Indexes"))
+	}
+	return m.IndexesFunc()
+}
+
+// Drop calls DropFunc.
+func (m *CollectionMock) Drop(ctx context.Context) error {
+	if m.DropFunc == nil {
+		panic(notMocked("CollectionMock", "Drop"))
+	}
+	return m.DropFunc(ctx)
+}
@@ -0,0 +1,171 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package mongoiface provides interfaces for the Client, Database, and Collection types of the mongo package so that
+// code using the driver can be unit tested without a MongoDB deployment.
+//
+// Application code depends on the interfaces and receives implementations that wrap the driver types in production:
+//
+//	type Store struct {
+//		orders mongoiface.Collection
+//	}
+//
+//	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+//	if err != nil { return err }
+//	store := &Store{orders: mongoiface.WrapClient(client).Database("shop").Collection("orders")}
+//
+// Tests use the mocks in this package instead, setting the function fields for the methods the code under test calls:
+//
+//	orders := &mongoiface.CollectionMock{
+//		FindOneFunc: func(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+//			return mongo.NewSingleResultFromDocument(bson.D{{"_id", 1}, {"total", 42}}, nil, nil)
+//		},
+//	}
+//	store := &Store{orders: orders}
+//
+// Operations that return a Cursor or SingleResult can be stubbed with mongo.NewCursorFromDocuments and
+// mongo.NewSingleResultFromDocument. The interfaces contain the methods used for CRUD operations and are extended when
+// the corresponding methods are added to the driver types.
+package mongoiface
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// Client is the interface implemented by a wrapped *mongo.Client and by ClientMock. See the mongo.Client documentation
+// for a description of each method.
+type Client interface {
+	Connect(ctx context.Context) error
+	Disconnect(ctx context.Context) error
+	Ping(ctx context.Context, rp *readpref.ReadPref) error
+	StartSession(opts ...*options.SessionOptions) (mongo.Session, error)
+	Database(name string, opts ...*options.DatabaseOptions) Database
+	ListDatabases(ctx context.Context, filter interface{},
+		opts ...*options.ListDatabasesOptions) (mongo.ListDatabasesResult, error)
+	ListDatabaseNames(ctx context.Context, filter interface{}, opts ...*options.ListDatabasesOptions) ([]string, error)
+	UseSession(ctx context.Context, fn func(mongo.SessionContext) error) error
+	UseSessionWithOptions(ctx context.Context, opts *options.SessionOptions, fn func(mongo.SessionContext) error) error
+	Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error)
+	NumberSessionsInProgress() int
+}
+
+// Database is the interface implemented by a wrapped *mongo.Database and by DatabaseMock. See the mongo.Database
+// documentation for a description of each method.
+type Database interface {
+	Client() Client
+	Name() string
+	Collection(name string, opts ...*options.CollectionOptions) Collection
+	Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error)
+	RunCommand(ctx context.Context, runCommand interface{}, opts ...*options.RunCmdOptions) *mongo.SingleResult
+	RunCommandCursor(ctx context.Context, runCommand interface{}, opts ...*options.RunCmdOptions) (*mongo.Cursor, error)
+	Drop(ctx context.Context) error
+	ListCollections(ctx context.Context, filter interface{},
+		opts ...*options.ListCollectionsOptions) (*mongo.Cursor, error)
+	ListCollectionNames(ctx context.Context, filter interface{},
+		opts ...*options.ListCollectionsOptions) ([]string, error)
+	ReadConcern() *readconcern.ReadConcern
+	ReadPreference() *readpref.ReadPref
+	WriteConcern() *writeconcern.WriteConcern
+	Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error)
+}
+
+// Collection is the interface implemented by a wrapped *mongo.Collection and by CollectionMock. See the
+// mongo.Collection documentation for a description of each method.
+type Collection interface {
+	Clone(opts ...*options.CollectionOptions) (Collection, error)
+	Name() string
+	Database() Database
+	BulkWrite(ctx context.Context, models []mongo.WriteModel,
+		opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error)
+	InsertOne(ctx context.Context, document interface{},
+		opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error)
+	InsertMany(ctx context.Context, documents []interface{},
+		opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error)
+	DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+	DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+	UpdateOne(ctx context.Context, filter interface{}, update interface{},
+		opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	UpdateMany(ctx context.Context, filter interface{}, update interface{},
+		opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	ReplaceOne(ctx context.Context, filter interface{}, replacement interface{},
+		opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error)
+	Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error)
+	CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error)
+	EstimatedDocumentCount(ctx context.Context, opts ...*options.EstimatedDocumentCountOptions) (int64, error)
+	Distinct(ctx context.Context, fieldName string, filter interface{},
+		opts ...*options.DistinctOptions) ([]interface{}, error)
+	Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error)
+	FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult
+	FindOneAndDelete(ctx context.Context, filter interface{},
+		opts ...*options.FindOneAndDeleteOptions) *mongo.SingleResult
+	FindOneAndReplace(ctx context.Context, filter interface{}, replacement interface{},
+		opts ...*options.FindOneAndReplaceOptions) *mongo.SingleResult
+	FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{},
+		opts ...*options.FindOneAndUpdateOptions) *mongo.SingleResult
+	Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error)
+	Indexes() mongo.IndexView
+	Drop(ctx context.Context) error
+}
+
+// WrapClient returns a Client that sends operations to c. The Database and Collection handles obtained from the
+// returned Client are wrapped as well.
+func WrapClient(c *mongo.Client) Client {
+	return client{c}
+}
+
+// WrapDatabase returns a Database that sends operations to db.
+func WrapDatabase(db *mongo.Database) Database {
+	return database{db}
+}
+
+// WrapCollection returns a Collection that sends operations to coll.
+func WrapCollection(coll *mongo.Collection) Collection {
+	return collection{coll}
+}
+
+// client adapts a *mongo.Client to the Client interface. The methods that return driver handles are overridden to
+// return the wrapped handles.
+type client struct {
+	*mongo.Client
+}
+
+func (c client) Database(name string, opts ...*options.DatabaseOptions) Database {
+	return WrapDatabase(c.Client.Database(name, opts...))
+}
+
+type database struct {
+	*mongo.Database
+}
+
+func (db database) Client() Client {
+	return WrapClient(db.Database.Client())
+}
+
+func (db database) Collection(name string, opts ...*options.CollectionOptions) Collection {
+	return WrapCollection(db.Database.Collection(name, opts...))
+}
+
+type collection struct {
+	*mongo.Collection
+}
+
+func (coll collection) Clone(opts ...*options.CollectionOptions) (Collection, error) {
+	clone, err := coll.Collection.Clone(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return WrapCollection(clone), nil
+}
+
+func (coll collection) Database() Database {
+	return WrapDatabase(coll.Collection.Database())
+}
@@ -0,0 +1,71 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongoiface
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestWrap(t *testing.T) {
+	mc, err := mongo.NewClient(options.Client())
+	assert.Nil(t, err, "NewClient error: %v", err)
+
+	wrapped := WrapClient(mc)
+	db := wrapped.Database("db", options.Database().SetReadPreference(readpref.Secondary()))
+	coll := db.Collection("coll")
+	assert.Equal(t, "coll", coll.Name(), "expected collection name %q, got %q", "coll", coll.Name())
+	assert.Equal(t, "db", coll.Database().Name(), "expected database name %q, got %q", "db", coll.Database().Name())
+	assert.Equal(t, readpref.SecondaryMode, db.ReadPreference().Mode(), "expected mode %v, got %v",
+		readpref.SecondaryMode, db.ReadPreference().Mode())
+
+	unwrapped, ok := db.Client().(client)
+	assert.True(t, ok, "expected Database.Client to return a wrapped client, got %T", db.Client())
+	assert.True(t, unwrapped.Client == mc, "expected the wrapped client to be the original client")
+
+	clone, err := coll.Clone()
+	assert.Nil(t, err, "Clone error: %v", err)
+	_, ok = clone.(collection)
+	assert.True(t, ok, "expected Clone to return a wrapped collection, got %T", clone)
+}
+
+// orderTotal is an example of application code that depends on a Collection.
+func orderTotal(ctx context.Context, orders Collection, id int) (int, error) {
+	var order struct{ Total int }
+	if err := orders.FindOne(ctx, bson.D{{"_id", id}}).Decode(&order); err != nil {
+		return 0, err
+	}
+	return order.Total, nil
+}
+
+func TestCollectionMock(t *testing.T) {
+	var gotFilter interface{}
+	orders := &CollectionMock{
+		FindOneFunc: func(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+			gotFilter = filter
+			return mongo.NewSingleResultFromDocument(bson.D{{"_id", 1}, {"total", 42}}, nil, nil)
+		},
+	}
+
+	total, err := orderTotal(context.Background(), orders, 1)
+	assert.Nil(t, err, "orderTotal error: %v", err)
+	assert.Equal(t, 42, total, "expected total 42, got %v", total)
+	assert.Equal(t, bson.D{{"_id", 1}}, gotFilter, "expected filter {_id: 1}, got %v", gotFilter)
+
+	defer func() {
+		r := recover()
+		want := "mongoiface: CollectionMock.InsertOne called, but InsertOneFunc is nil"
+		assert.Equal(t, want, r, "expected panic %q, got %v", want, r)
+	}()
+	_, _ = orders.InsertOne(context.Background(), bson.D{})
+}
@@ -0,0 +1,68 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package mtest is the test harness used by the driver's own integration tests. It is a supported package so that
+// libraries built on top of the driver can be tested the same way.
+//
+// A test binary calls Setup in TestMain before any tests run and Teardown after they finish:
+//
+//	func TestMain(m *testing.M) {
+//		if err := mtest.Setup(); err != nil {
+//			log.Fatal(err)
+//		}
+//		code := m.Run()
+//		if err := mtest.Teardown(); err != nil {
+//			log.Fatal(err)
+//		}
+//		os.Exit(code)
+//	}
+//
+// Setup connects to the deployment in the MONGODB_URI environment variable, or to localhost:27017 if it is not set.
+// The MONGO_GO_DRIVER_CA_FILE and MONGO_GO_DRIVER_COMPRESSOR environment variables are also read to configure TLS and
+// compression.
+//
+// Each test creates a T with New and closes it when it is done. Sub-tests are run with Run or RunOpts:
+//
+//	func TestInsert(t *testing.T) {
+//		mt := mtest.New(t, mtest.NewOptions().CreateClient(false))
+//		defer mt.Close()
+//
+//		mt.RunOpts("replica set", mtest.NewOptions().Topologies(mtest.ReplicaSet), func(mt *mtest.T) {
+//			_, err := mt.Coll.InsertOne(mtest.Background, bson.D{{"x", 1}})
+//			...
+//		})
+//	}
+//
+// Tests are skipped automatically if the deployment does not match the constraints in their Options, such as
+// MinServerVersion, Topologies, or Auth.
+//
+// # Namespaces
+//
+// Every T gets a database and a collection named after the test, which are available as T.DB and T.Coll. Collections
+// created with T.CreateCollection are dropped and fail points set with T.SetFailPoint are disabled when the test
+// finishes.
+//
+// # Events
+//
+// The client of a T monitors commands. The events can be inspected in order with T.GetStartedEvent,
+// T.GetSucceededEvent, and T.GetFailedEvent, or all at once with T.GetAllStartedEvents and friends. T.ClearEvents
+// discards the events seen so far, which is useful to ignore the commands run to prepare a test.
+//
+// # Mock deployments
+//
+// Tests with the Mock ClientType run against an in-memory deployment that does not need a server. Replies are queued
+// with T.AddMockResponses or Options.MockResponses and returned in order, one per command. CreateSuccessResponse,
+// CreateCursorResponse, CreateCommandErrorResponse, CreateWriteErrorsResponse, and CreateWriteConcernErrorResponse
+// build common replies.
+//
+// # Compatibility
+//
+// The exported API of this package follows the same semantic versioning guarantees as the rest of the driver:
+// exported identifiers are not removed or changed in a backwards-incompatible way within a major version. The set of
+// commands that a T runs against the deployment to prepare and clean up a test is not part of these guarantees. The
+// package was previously located at go.mongodb.org/mongo-driver/mongo/integration/mtest, which remains as a deprecated
+// alias.
+package mtest // import "go.mongodb.org/mongo-driver/mongo/mtest"
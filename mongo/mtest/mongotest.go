@@ -16,6 +16,7 @@ import (
 	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/internal/testutil/assert"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/failpoint"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readconcern"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -42,36 +43,16 @@ const (
 // FailPoint is a representation of a server fail point.
 // See https://github.com/mongodb/specifications/tree/master/source/transactions/tests#server-fail-point
 // for more information regarding fail points.
-type FailPoint struct {
-	ConfigureFailPoint string `bson:"configureFailPoint"`
-	// Mode should be a string, FailPointMode, or map[string]interface{}
-	Mode interface{}   `bson:"mode"`
-	Data FailPointData `bson:"data"`
-}
+type FailPoint = failpoint.FailPoint
 
 // FailPointMode is a representation of the Failpoint.Mode field.
-type FailPointMode struct {
-	Times int32 `bson:"times"`
-	Skip  int32 `bson:"skip"`
-}
+type FailPointMode = failpoint.Mode
 
 // FailPointData is a representation of the FailPoint.Data field.
-type FailPointData struct {
-	FailCommands                  []string               `bson:"failCommands,omitempty"`
-	CloseConnection               bool                   `bson:"closeConnection,omitempty"`
-	ErrorCode                     int32                  `bson:"errorCode,omitempty"`
-	FailBeforeCommitExceptionCode int32                  `bson:"failBeforeCommitExceptionCode,omitempty"`
-	ErrorLabels                   *[]string              `bson:"errorLabels,omitempty"`
-	WriteConcernError             *WriteConcernErrorData `bson:"writeConcernError,omitempty"`
-}
+type FailPointData = failpoint.Data
 
 // WriteConcernErrorData is a representation of the FailPoint.Data.WriteConcern field.
-type WriteConcernErrorData struct {
-	Code        int32     `bson:"code"`
-	Name        string    `bson:"codeName"`
-	Errmsg      string    `bson:"errmsg"`
-	ErrorLabels *[]string `bson:"errorLabels,omitempty"`
-}
+type WriteConcernErrorData = failpoint.WriteConcernError
 
 // T is a wrapper around testing.T.
 type T struct {
@@ -17,8 +17,8 @@ type TopologyKind string
 // These constants specify valid values for TopologyKind
 const (
 	ReplicaSet TopologyKind = "replicaset"
-	Sharded                 = "sharded"
-	Single                  = "single"
+	Sharded    TopologyKind = "sharded"
+	Single     TopologyKind = "single"
 )
 
 // ClientType specifies the type of Client that should be created for a test.
@@ -44,6 +44,12 @@ type AggregateOptions struct {
 	// as a document. The hint does not apply to $lookup and $graphLookup aggregation stages. The default value is nil,
 	// which means that no hint will be sent.
 	Hint interface{}
+
+	// The number of batches to fetch from the server in the background while the application is still iterating the
+	// current batch. Prefetching overlaps network time with processing for large scans at the cost of holding up to
+	// PrefetchDepth extra batches in memory. The default value is 0, which means that batches are only fetched when
+	// the current batch has been exhausted. This option is ignored for operations executed with an explicit session.
+	PrefetchDepth *int32
 }
 
 // Aggregate creates a new AggregateOptions instance.
@@ -99,6 +105,12 @@ func (ao *AggregateOptions) SetHint(h interface{}) *AggregateOptions {
 	return ao
 }
 
+// SetPrefetchDepth sets the value for the PrefetchDepth field.
+func (ao *AggregateOptions) SetPrefetchDepth(i int32) *AggregateOptions {
+	ao.PrefetchDepth = &i
+	return ao
+}
+
 // MergeAggregateOptions combines the given AggregateOptions instances into a single AggregateOptions in a last-one-wins
 // fashion.
 func MergeAggregateOptions(opts ...*AggregateOptions) *AggregateOptions {
@@ -131,7 +143,22 @@ func MergeAggregateOptions(opts ...*AggregateOptions) *AggregateOptions {
 		if ao.Hint != nil {
 			aggOpts.Hint = ao.Hint
 		}
+		if ao.PrefetchDepth != nil {
+			aggOpts.PrefetchDepth = ao.PrefetchDepth
+		}
 	}
 
 	return aggOpts
 }
+
+// Clone returns a deep copy of the AggregateOptions. Values that are stored as interfaces, such as documents, are
+// shared with the copy.
+func (ao *AggregateOptions) Clone() *AggregateOptions {
+	return cloneOptions(ao).(*AggregateOptions)
+}
+
+// Merge returns a copy of the AggregateOptions that is updated with the options that are set in other, using the same
+// rules as MergeAggregateOptions.
+func (ao *AggregateOptions) Merge(other *AggregateOptions) *AggregateOptions {
+	return MergeAggregateOptions(ao, other).Clone()
+}
@@ -6,6 +6,10 @@
 
 package options
 
+import (
+	"crypto/tls"
+)
+
 // AutoEncryptionOptions represents options used to configure auto encryption/decryption behavior for a mongo.Client
 // instance.
 //
@@ -24,9 +28,16 @@ type AutoEncryptionOptions struct {
 	KeyVaultClientOptions *ClientOptions
 	KeyVaultNamespace     string
 	KmsProviders          map[string]map[string]interface{}
+	TLSConfig             map[string]*tls.Config
+	KmsCredentialsFunc    map[string]KmsCredentialsFunc
 	SchemaMap             map[string]interface{}
 	BypassAutoEncryption  *bool
 	ExtraOptions          map[string]interface{}
+
+	CryptSharedLibPath        *string
+	CryptSharedLibSearchPaths []string
+	CryptSharedLibRequired    *bool
+	CryptSharedLibMinVersion  *string
 }
 
 // AutoEncryption creates a new AutoEncryptionOptions configured with default values.
@@ -47,12 +58,27 @@ func (a *AutoEncryptionOptions) SetKeyVaultNamespace(ns string) *AutoEncryptionO
 	return a
 }
 
-// SetKmsProviders specifies options for KMS providers. This is required.
+// SetKmsProviders specifies options for KMS providers. This is required. See
+// ClientEncryptionOptions.SetKmsProviders for the supported providers.
 func (a *AutoEncryptionOptions) SetKmsProviders(providers map[string]map[string]interface{}) *AutoEncryptionOptions {
 	a.KmsProviders = providers
 	return a
 }
 
+// SetTLSConfig specifies the TLS configuration to use to connect to the KMS of each provider, keyed by provider name.
+// See ClientEncryptionOptions.SetTLSConfig for more information.
+func (a *AutoEncryptionOptions) SetTLSConfig(cfg map[string]*tls.Config) *AutoEncryptionOptions {
+	a.TLSConfig = cfg
+	return a
+}
+
+// SetKmsCredentialsFunc specifies functions that supply the credentials of KMS providers, keyed by provider name. See
+// ClientEncryptionOptions.SetKmsCredentialsFunc for more information.
+func (a *AutoEncryptionOptions) SetKmsCredentialsFunc(fns map[string]KmsCredentialsFunc) *AutoEncryptionOptions {
+	a.KmsCredentialsFunc = fns
+	return a
+}
+
 // SetSchemaMap specifies a map from namespace to local schema document. Schemas supplied in the schemaMap only apply
 // to configuring automatic encryption for client side encryption. Other validation rules in the JSON schema will not
 // be enforced by the driver and will result in an error.
@@ -77,6 +103,37 @@ func (a *AutoEncryptionOptions) SetExtraOptions(extraOpts map[string]interface{}
 	return a
 }
 
+// SetCryptSharedLibPath specifies the path of the crypt_shared library. If the library is loaded, it is used to mark
+// commands for encryption and mongocryptd is not spawned. If this is set, CryptSharedLibSearchPaths is not used.
+func (a *AutoEncryptionOptions) SetCryptSharedLibPath(path string) *AutoEncryptionOptions {
+	a.CryptSharedLibPath = &path
+	return a
+}
+
+// SetCryptSharedLibSearchPaths specifies the directories that are searched for the crypt_shared library, in order. A
+// path that starts with "$ORIGIN" is relative to the directory of the libmongocrypt library, and the special value
+// "$SYSTEM" searches the default library paths of the system. If the library is loaded, it is used to mark commands for
+// encryption and mongocryptd is not spawned. If the library is not found, mongocryptd is used.
+func (a *AutoEncryptionOptions) SetCryptSharedLibSearchPaths(paths []string) *AutoEncryptionOptions {
+	a.CryptSharedLibSearchPaths = paths
+	return a
+}
+
+// SetCryptSharedLibRequired specifies whether the crypt_shared library must be loaded. If this is true and the
+// library cannot be loaded, creating the client returns an error instead of falling back to mongocryptd. The default
+// is false.
+func (a *AutoEncryptionOptions) SetCryptSharedLibRequired(required bool) *AutoEncryptionOptions {
+	a.CryptSharedLibRequired = &required
+	return a
+}
+
+// SetCryptSharedLibMinVersion specifies the minimum version of the crypt_shared library in the form
+// "major.minor.patch". If an older version of the library is loaded, creating the client returns an error.
+func (a *AutoEncryptionOptions) SetCryptSharedLibMinVersion(version string) *AutoEncryptionOptions {
+	a.CryptSharedLibMinVersion = &version
+	return a
+}
+
 // MergeAutoEncryptionOptions combines the argued AutoEncryptionOptions in a last-one wins fashion.
 func MergeAutoEncryptionOptions(opts ...*AutoEncryptionOptions) *AutoEncryptionOptions {
 	aeo := AutoEncryption()
@@ -94,6 +151,12 @@ func MergeAutoEncryptionOptions(opts ...*AutoEncryptionOptions) *AutoEncryptionO
 		if opt.KmsProviders != nil {
 			aeo.KmsProviders = opt.KmsProviders
 		}
+		if opt.TLSConfig != nil {
+			aeo.TLSConfig = opt.TLSConfig
+		}
+		if opt.KmsCredentialsFunc != nil {
+			aeo.KmsCredentialsFunc = opt.KmsCredentialsFunc
+		}
 		if opt.SchemaMap != nil {
 			aeo.SchemaMap = opt.SchemaMap
 		}
@@ -103,6 +166,18 @@ func MergeAutoEncryptionOptions(opts ...*AutoEncryptionOptions) *AutoEncryptionO
 		if opt.ExtraOptions != nil {
 			aeo.ExtraOptions = opt.ExtraOptions
 		}
+		if opt.CryptSharedLibPath != nil {
+			aeo.CryptSharedLibPath = opt.CryptSharedLibPath
+		}
+		if opt.CryptSharedLibSearchPaths != nil {
+			aeo.CryptSharedLibSearchPaths = opt.CryptSharedLibSearchPaths
+		}
+		if opt.CryptSharedLibRequired != nil {
+			aeo.CryptSharedLibRequired = opt.CryptSharedLibRequired
+		}
+		if opt.CryptSharedLibMinVersion != nil {
+			aeo.CryptSharedLibMinVersion = opt.CryptSharedLibMinVersion
+		}
 	}
 
 	return aeo
@@ -58,3 +58,15 @@ func MergeBulkWriteOptions(opts ...*BulkWriteOptions) *BulkWriteOptions {
 
 	return b
 }
+
+// Clone returns a deep copy of the BulkWriteOptions. Values that are stored as interfaces, such as documents, are
+// shared with the copy.
+func (b *BulkWriteOptions) Clone() *BulkWriteOptions {
+	return cloneOptions(b).(*BulkWriteOptions)
+}
+
+// Merge returns a copy of the BulkWriteOptions that is updated with the options that are set in other, using the same
+// rules as MergeBulkWriteOptions.
+func (b *BulkWriteOptions) Merge(other *BulkWriteOptions) *BulkWriteOptions {
+	return MergeBulkWriteOptions(b, other).Clone()
+}
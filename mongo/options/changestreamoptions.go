@@ -17,6 +17,11 @@ type ChangeStreamOptions struct {
 	// The maximum number of documents to be included in each batch returned by the server.
 	BatchSize *int32
 
+	// Specifies a collection in which the change stream's resume token is periodically stored. When the change stream
+	// is created, it resumes from the stored resume token if one exists and none of ResumeAfter, StartAfter, or
+	// StartAtOperationTime are set. The default value is nil, which means that resume tokens are not stored.
+	Checkpoint *ChangeStreamCheckpoint
+
 	// Specifies a collation to use for string comparisons during the operation. This option is only valid for MongoDB
 	// versions >= 3.4. For previous server versions, the driver will return an error if this option is used. The
 	// default value is nil, which means the default collation of the collection will be used.
@@ -30,6 +35,17 @@ type ChangeStreamOptions struct {
 	// The maximum amount of time that the server should wait for new documents to satisfy a tailable cursor query.
 	MaxAwaitTime *time.Duration
 
+	// If true, the change stream will include events for DDL operations that are not reported by default, such as
+	// createIndexes, dropIndexes, modify, create, shardCollection, and reshardCollection, and will include additional
+	// fields in some events. This option is only valid for MongoDB versions >= 6.0. The default value is false.
+	ShowExpandedEvents *bool
+
+	// If true, a $changeStreamSplitLargeEvent stage is appended to the pipeline so events that exceed the 16MB BSON
+	// size limit are split into fragments by the server instead of causing the change stream to fail. The change
+	// stream reassembles the fragments into a single event before returning it, so the event's _id is the resume token
+	// of the last fragment. This option is only valid for MongoDB versions >= 7.0. The default value is false.
+	SplitLargeEvents *bool
+
 	// A document specifying the logical starting point for the change stream. Only changes corresponding to an oplog
 	// entry immediately after the resume token will be returned. If this is specified, StartAtOperationTime and
 	// StartAfter must not be set.
@@ -48,6 +64,37 @@ type ChangeStreamOptions struct {
 	StartAfter interface{}
 }
 
+// ChangeStreamCheckpoint specifies where and how often a change stream's resume token is stored so the change stream
+// can be restarted from where it left off, for example after the process restarts. The resume token is stored in a
+// document of the form {_id: <ID>, resumeToken: <token>, updatedAt: <date>} using an upsert.
+//
+// An event is considered processed when Next or TryNext is called again after returning it, and only the resume
+// tokens of processed events are stored. If the process stops while an event is handled, that event is delivered
+// again when the change stream is resumed from the checkpoint, so events are delivered at least once.
+//
+// Checkpoints are only written from the change stream's Next, TryNext, and Close methods, so Interval is a minimum
+// rather than an exact cadence. A final checkpoint is written when the change stream is closed; the event returned
+// last is not considered processed by Close. If writing a checkpoint fails, the change stream continues, the error is
+// returned by the change stream's CheckpointErr method, and the write is retried by the next call to Next or TryNext.
+// The error of the final checkpoint is also returned by Close.
+type ChangeStreamCheckpoint struct {
+	// The database and collection in which the checkpoint document is stored. These must be set.
+	Database   string
+	Collection string
+
+	// The _id of the checkpoint document. Change streams that store checkpoints in the same collection must use
+	// different IDs. This must be set.
+	ID string
+
+	// The number of events after which a checkpoint is written. If this is 0, checkpoints are not written based on
+	// the number of events.
+	Events int
+
+	// The minimum amount of time between checkpoints. If this is 0, checkpoints are not written based on time. If both
+	// Events and Interval are 0, a checkpoint is written after every event.
+	Interval time.Duration
+}
+
 // ChangeStream creates a new ChangeStreamOptions instance.
 func ChangeStream() *ChangeStreamOptions {
 	cso := &ChangeStreamOptions{}
@@ -61,6 +108,12 @@ func (cso *ChangeStreamOptions) SetBatchSize(i int32) *ChangeStreamOptions {
 	return cso
 }
 
+// SetCheckpoint sets the value for the Checkpoint field.
+func (cso *ChangeStreamOptions) SetCheckpoint(cp ChangeStreamCheckpoint) *ChangeStreamOptions {
+	cso.Checkpoint = &cp
+	return cso
+}
+
 // SetCollation sets the value for the Collation field.
 func (cso *ChangeStreamOptions) SetCollation(c Collation) *ChangeStreamOptions {
 	cso.Collation = &c
@@ -85,6 +138,18 @@ func (cso *ChangeStreamOptions) SetResumeAfter(rt interface{}) *ChangeStreamOpti
 	return cso
 }
 
+// SetShowExpandedEvents sets the value for the ShowExpandedEvents field.
+func (cso *ChangeStreamOptions) SetShowExpandedEvents(b bool) *ChangeStreamOptions {
+	cso.ShowExpandedEvents = &b
+	return cso
+}
+
+// SetSplitLargeEvents sets the value for the SplitLargeEvents field.
+func (cso *ChangeStreamOptions) SetSplitLargeEvents(b bool) *ChangeStreamOptions {
+	cso.SplitLargeEvents = &b
+	return cso
+}
+
 // SetStartAtOperationTime sets the value for the StartAtOperationTime field.
 func (cso *ChangeStreamOptions) SetStartAtOperationTime(t *primitive.Timestamp) *ChangeStreamOptions {
 	cso.StartAtOperationTime = t
@@ -108,6 +173,9 @@ func MergeChangeStreamOptions(opts ...*ChangeStreamOptions) *ChangeStreamOptions
 		if cso.BatchSize != nil {
 			csOpts.BatchSize = cso.BatchSize
 		}
+		if cso.Checkpoint != nil {
+			csOpts.Checkpoint = cso.Checkpoint
+		}
 		if cso.Collation != nil {
 			csOpts.Collation = cso.Collation
 		}
@@ -120,6 +188,12 @@ func MergeChangeStreamOptions(opts ...*ChangeStreamOptions) *ChangeStreamOptions
 		if cso.ResumeAfter != nil {
 			csOpts.ResumeAfter = cso.ResumeAfter
 		}
+		if cso.ShowExpandedEvents != nil {
+			csOpts.ShowExpandedEvents = cso.ShowExpandedEvents
+		}
+		if cso.SplitLargeEvents != nil {
+			csOpts.SplitLargeEvents = cso.SplitLargeEvents
+		}
 		if cso.StartAtOperationTime != nil {
 			csOpts.StartAtOperationTime = cso.StartAtOperationTime
 		}
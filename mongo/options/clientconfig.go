@@ -0,0 +1,511 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"go.mongodb.org/mongo-driver/tag"
+)
+
+// ClientOptionsFromFile creates a ClientOptions instance from a YAML or JSON config file, so that driver settings can
+// be managed as configuration instead of code. The format is determined by the extension of the file, which must be
+// .yaml, .yml, or .json. The file must contain a mapping (or a JSON object) with the following keys, all of which are
+// optional:
+//
+//	uri: mongodb://localhost:27017/?replicaSet=rs0  # applied before all other keys
+//	hosts: [db1.example.com:27017, db2.example.com:27017]
+//	appName: inventory
+//	replicaSet: rs0
+//	directConnection: false
+//	loadBalanced: false
+//	retryReads: true
+//	retryWrites: true
+//	compressors: [zstd, snappy]
+//	connectTimeoutMS: 10000
+//	heartbeatFrequencyMS: 10000
+//	localThresholdMS: 15
+//	serverSelectionTimeoutMS: 30000
+//	socketTimeoutMS: 0
+//	pool:
+//	  maxSize: 100
+//	  minSize: 0
+//	  maxConnecting: 2
+//	  maxIdleTimeMS: 0
+//	tls:
+//	  enabled: true
+//	  caFile: /etc/mongodb/ca.pem
+//	  certificateKeyFile: /etc/mongodb/client.pem
+//	  certificateKeyFilePassword: secret
+//	  insecure: false
+//	auth:
+//	  mechanism: SCRAM-SHA-256
+//	  source: admin
+//	  username: app
+//	  password: secret
+//	  mechanismProperties: {SERVICE_NAME: mongodb}
+//	readConcern:
+//	  level: majority
+//	writeConcern:
+//	  w: majority  # or a number
+//	  journal: true
+//	  wtimeoutMS: 5000
+//	readPreference:
+//	  mode: secondaryPreferred
+//	  tagSets: [{dc: east}, {}]
+//	  maxStalenessSeconds: 120
+//
+// TLS is enabled if tls.enabled is true or if a certificate file is given. The username of MONGODB-X509 credentials
+// defaults to the subject of the client certificate, as it does for URIs.
+//
+// A JSON file uses the same keys, with the nested mappings as nested objects. Unknown keys and values of the wrong
+// type are rejected. If the file cannot be parsed or does not match the schema, the error is a *ConfigError. Only
+// syntax errors contain a position, because neither gopkg.in/yaml.v2 nor encoding/json report the positions of values.
+// For YAML files, the position is only the line.
+func ClientOptionsFromFile(path string) (*ClientOptions, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return clientOptionsFromConfig(path, data)
+}
+
+func clientOptionsFromConfig(file string, data []byte) (*ClientOptions, error) {
+	doc, err := parseConfig(file, data)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &configDecoder{file: file}
+	c := Client()
+	entries, ok := doc.val.([]configEntry)
+	if !ok {
+		return nil, d.errorf(doc, "", "expected an object, got %s", configTypeName(doc))
+	}
+	// the URI is applied first so that the other keys override the options in it
+	for _, e := range entries {
+		if e.key != "uri" {
+			continue
+		}
+		uri, err := d.str(e.val, "uri")
+		if err != nil {
+			return nil, err
+		}
+		if err := c.ApplyURI(uri).Validate(); err != nil {
+			return nil, d.errorf(e.val, "uri", "%v", err)
+		}
+	}
+
+	var x509Subject string
+	err = d.mapping(doc, "", map[string]func(*configValue, string) error{
+		"uri": func(*configValue, string) error { return nil },
+		"hosts": func(v *configValue, path string) error {
+			hosts, err := d.strs(v, path)
+			c.Hosts = hosts
+			return err
+		},
+		"appName":          d.setStr(&c.AppName),
+		"replicaSet":       d.setStr(&c.ReplicaSet),
+		"directConnection": d.setBool(&c.Direct),
+		"loadBalanced":     d.setBool(&c.LoadBalanced),
+		"retryReads":       d.setBool(&c.RetryReads),
+		"retryWrites":      d.setBool(&c.RetryWrites),
+		"compressors": func(v *configValue, path string) error {
+			comps, err := d.strs(v, path)
+			c.SetCompressors(comps)
+			return err
+		},
+		"connectTimeoutMS":         d.setMS(&c.ConnectTimeout),
+		"heartbeatFrequencyMS":     d.setMS(&c.HeartbeatInterval),
+		"localThresholdMS":         d.setMS(&c.LocalThreshold),
+		"serverSelectionTimeoutMS": d.setMS(&c.ServerSelectionTimeout),
+		"socketTimeoutMS":          d.setMS(&c.SocketTimeout),
+		"pool": func(v *configValue, path string) error {
+			return d.mapping(v, path, map[string]func(*configValue, string) error{
+				"maxSize":       d.setUint(&c.MaxPoolSize),
+				"minSize":       d.setUint(&c.MinPoolSize),
+				"maxConnecting": d.setUint(&c.MaxConnecting),
+				"maxIdleTimeMS": d.setMS(&c.MaxConnIdleTime),
+			})
+		},
+		"tls": func(v *configValue, path string) (err error) {
+			x509Subject, err = d.tls(c, v, path)
+			return err
+		},
+		"auth":           func(v *configValue, path string) error { return d.auth(c, v, path) },
+		"readConcern":    func(v *configValue, path string) error { return d.readConcern(c, v, path) },
+		"writeConcern":   func(v *configValue, path string) error { return d.writeConcern(c, v, path) },
+		"readPreference": func(v *configValue, path string) error { return d.readPreference(c, v, path) },
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Hosts) == 0 {
+		return nil, d.errorf(doc, "", "either hosts or uri must be specified")
+	}
+	if c.Auth != nil && strings.ToLower(c.Auth.AuthMechanism) == "mongodb-x509" && c.Auth.Username == "" {
+		c.Auth.Username = extractX509UsernameFromSubject(x509Subject)
+	}
+	return c, nil
+}
+
+// configDecoder decodes the values of a parsed config file into client options.
+type configDecoder struct {
+	file string
+}
+
+func (d *configDecoder) errorf(v *configValue, path, format string, args ...interface{}) error {
+	return &ConfigError{File: d.file, Line: v.line, Column: v.col, Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+// mapping decodes each entry of the object v with the function for its key. Unknown keys are rejected.
+func (d *configDecoder) mapping(v *configValue, path string, fields map[string]func(*configValue, string) error) error {
+	entries, ok := v.val.([]configEntry)
+	if !ok {
+		return d.errorf(v, path, "expected an object, got %s", configTypeName(v))
+	}
+	for _, e := range entries {
+		entryPath := e.key
+		if path != "" {
+			entryPath = path + "." + e.key
+		}
+		fn, ok := fields[e.key]
+		if !ok {
+			msg := "unknown option; valid options are " + strings.Join(sortedKeys(fields), ", ")
+			for name := range fields {
+				if strings.EqualFold(name, e.key) {
+					msg = fmt.Sprintf("unknown option, did you mean %q?", name)
+				}
+			}
+			return &ConfigError{File: d.file, Line: e.line, Column: e.col, Path: entryPath, Message: msg}
+		}
+		if err := fn(e.val, entryPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys(fields map[string]func(*configValue, string) error) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (d *configDecoder) str(v *configValue, path string) (string, error) {
+	s, ok := v.val.(string)
+	if !ok {
+		return "", d.errorf(v, path, "expected a string, got %s", configTypeName(v))
+	}
+	return s, nil
+}
+
+func (d *configDecoder) strs(v *configValue, path string) ([]string, error) {
+	items, ok := v.val.([]*configValue)
+	if !ok {
+		return nil, d.errorf(v, path, "expected an array, got %s", configTypeName(v))
+	}
+	strs := make([]string, 0, len(items))
+	for i, item := range items {
+		s, err := d.str(item, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		strs = append(strs, s)
+	}
+	return strs, nil
+}
+
+func (d *configDecoder) boolean(v *configValue, path string) (bool, error) {
+	b, ok := v.val.(bool)
+	if !ok {
+		return false, d.errorf(v, path, "expected a boolean, got %s", configTypeName(v))
+	}
+	return b, nil
+}
+
+func (d *configDecoder) nonNegative(v *configValue, path string) (int64, error) {
+	i, ok := v.val.(int64)
+	if !ok || i < 0 {
+		return 0, d.errorf(v, path, "expected a non-negative integer, got %s", configTypeName(v))
+	}
+	return i, nil
+}
+
+func (d *configDecoder) setStr(dst **string) func(*configValue, string) error {
+	return func(v *configValue, path string) error {
+		s, err := d.str(v, path)
+		*dst = &s
+		return err
+	}
+}
+
+func (d *configDecoder) setBool(dst **bool) func(*configValue, string) error {
+	return func(v *configValue, path string) error {
+		b, err := d.boolean(v, path)
+		*dst = &b
+		return err
+	}
+}
+
+func (d *configDecoder) setUint(dst **uint64) func(*configValue, string) error {
+	return func(v *configValue, path string) error {
+		i, err := d.nonNegative(v, path)
+		u := uint64(i)
+		*dst = &u
+		return err
+	}
+}
+
+func (d *configDecoder) setMS(dst **time.Duration) func(*configValue, string) error {
+	return func(v *configValue, path string) error {
+		i, err := d.nonNegative(v, path)
+		ms := time.Duration(i) * time.Millisecond
+		*dst = &ms
+		return err
+	}
+}
+
+// tls sets the TLS configuration and returns the subject of the client certificate, if there is one.
+func (d *configDecoder) tls(c *ClientOptions, v *configValue, path string) (string, error) {
+	var enabled, insecure *bool
+	var caFile, certKeyFile, password *string
+	err := d.mapping(v, path, map[string]func(*configValue, string) error{
+		"enabled":                    d.setBool(&enabled),
+		"caFile":                     d.setStr(&caFile),
+		"certificateKeyFile":         d.setStr(&certKeyFile),
+		"certificateKeyFilePassword": d.setStr(&password),
+		"insecure":                   d.setBool(&insecure),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if enabled == nil {
+		enabled = new(bool)
+		*enabled = caFile != nil || certKeyFile != nil
+	}
+	if !*enabled {
+		if caFile != nil || certKeyFile != nil || password != nil || insecure != nil {
+			return "", d.errorf(v, path, "TLS options cannot be specified if TLS is disabled")
+		}
+		return "", nil
+	}
+	if password != nil && certKeyFile == nil {
+		return "", d.errorf(v, path, "certificateKeyFilePassword requires certificateKeyFile")
+	}
+
+	cfg := new(tls.Config)
+	if caFile != nil {
+		if err := addCACertFromFile(cfg, *caFile); err != nil {
+			return "", d.errorf(v, path+".caFile", "%v", err)
+		}
+	}
+	var subject string
+	if certKeyFile != nil {
+		var keyPasswd string
+		if password != nil {
+			keyPasswd = *password
+		}
+		if subject, err = addClientCertFromConcatenatedFile(cfg, *certKeyFile, keyPasswd); err != nil {
+			return "", d.errorf(v, path+".certificateKeyFile", "%v", err)
+		}
+	}
+	cfg.InsecureSkipVerify = insecure != nil && *insecure
+	c.TLSConfig = cfg
+	return subject, nil
+}
+
+func (d *configDecoder) auth(c *ClientOptions, v *configValue, path string) error {
+	var cred Credential
+	err := d.mapping(v, path, map[string]func(*configValue, string) error{
+		"mechanism": func(v *configValue, path string) (err error) {
+			cred.AuthMechanism, err = d.str(v, path)
+			return err
+		},
+		"source": func(v *configValue, path string) (err error) {
+			cred.AuthSource, err = d.str(v, path)
+			return err
+		},
+		"username": func(v *configValue, path string) (err error) {
+			cred.Username, err = d.str(v, path)
+			return err
+		},
+		"password": func(v *configValue, path string) (err error) {
+			cred.Password, err = d.str(v, path)
+			cred.PasswordSet = true
+			return err
+		},
+		"mechanismProperties": func(v *configValue, path string) error {
+			entries, ok := v.val.([]configEntry)
+			if !ok {
+				return d.errorf(v, path, "expected an object, got %s", configTypeName(v))
+			}
+			cred.AuthMechanismProperties = make(map[string]string, len(entries))
+			for _, e := range entries {
+				switch e.val.val.(type) {
+				case string, bool, int64, float64:
+					cred.AuthMechanismProperties[e.key] = fmt.Sprint(e.val.val)
+				default:
+					return d.errorf(e.val, path+"."+e.key, "expected a scalar, got %s", configTypeName(e.val))
+				}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	c.SetAuth(cred)
+	return nil
+}
+
+func (d *configDecoder) readConcern(c *ClientOptions, v *configValue, path string) error {
+	var level *string
+	err := d.mapping(v, path, map[string]func(*configValue, string) error{
+		"level": d.setStr(&level),
+	})
+	if err != nil {
+		return err
+	}
+	if level == nil {
+		c.ReadConcern = readconcern.New()
+	} else {
+		c.ReadConcern = readconcern.New(readconcern.Level(*level))
+	}
+	return nil
+}
+
+func (d *configDecoder) writeConcern(c *ClientOptions, v *configValue, path string) error {
+	var opts []writeconcern.Option
+	err := d.mapping(v, path, map[string]func(*configValue, string) error{
+		"w": func(v *configValue, path string) error {
+			switch w := v.val.(type) {
+			case string:
+				opts = append(opts, writeconcern.WTagSet(w))
+			case int64:
+				if w < 0 {
+					return d.errorf(v, path, "expected a non-negative integer or a string, got %d", w)
+				}
+				opts = append(opts, writeconcern.W(int(w)))
+			default:
+				return d.errorf(v, path, "expected an integer or a string, got %s", configTypeName(v))
+			}
+			return nil
+		},
+		"journal": func(v *configValue, path string) error {
+			j, err := d.boolean(v, path)
+			opts = append(opts, writeconcern.J(j))
+			return err
+		},
+		"wtimeoutMS": func(v *configValue, path string) error {
+			ms, err := d.nonNegative(v, path)
+			opts = append(opts, writeconcern.WTimeout(time.Duration(ms)*time.Millisecond))
+			return err
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	wc := writeconcern.New(opts...)
+	if !wc.IsValid() {
+		return d.errorf(v, path, "an unacknowledged write concern cannot be journaled")
+	}
+	c.WriteConcern = wc
+	return nil
+}
+
+func (d *configDecoder) readPreference(c *ClientOptions, v *configValue, path string) error {
+	var modeVal *configValue
+	var modeStr string
+	var opts []readpref.Option
+	err := d.mapping(v, path, map[string]func(*configValue, string) error{
+		"mode": func(v *configValue, path string) (err error) {
+			modeVal = v
+			modeStr, err = d.str(v, path)
+			return err
+		},
+		"tagSets": func(v *configValue, path string) error {
+			items, ok := v.val.([]*configValue)
+			if !ok {
+				return d.errorf(v, path, "expected an array, got %s", configTypeName(v))
+			}
+			tagSets := make([]map[string]string, 0, len(items))
+			for i, item := range items {
+				itemPath := fmt.Sprintf("%s[%d]", path, i)
+				entries, ok := item.val.([]configEntry)
+				if !ok {
+					return d.errorf(item, itemPath, "expected an object, got %s", configTypeName(item))
+				}
+				tags := make(map[string]string, len(entries))
+				for _, e := range entries {
+					val, err := d.str(e.val, itemPath+"."+e.key)
+					if err != nil {
+						return err
+					}
+					tags[e.key] = val
+				}
+				tagSets = append(tagSets, tags)
+			}
+			opts = append(opts, readpref.WithTagSets(tag.NewTagSetsFromMaps(tagSets)...))
+			return nil
+		},
+		"maxStalenessSeconds": func(v *configValue, path string) error {
+			secs, err := d.nonNegative(v, path)
+			opts = append(opts, readpref.WithMaxStaleness(time.Duration(secs)*time.Second))
+			return err
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if modeVal == nil {
+		return d.errorf(v, path, "mode is required")
+	}
+
+	mode, err := readpref.ModeFromString(modeStr)
+	if err != nil {
+		return d.errorf(modeVal, path+".mode", "%v", err)
+	}
+	if c.ReadPreference, err = readpref.New(mode, opts...); err != nil {
+		return d.errorf(v, path, "%v", err)
+	}
+	return nil
+}
+
+// configTypeName returns a description of the type of a config value for error messages.
+func configTypeName(v *configValue) string {
+	switch val := v.val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return fmt.Sprintf("boolean %v", val)
+	case int64:
+		return fmt.Sprintf("integer %d", val)
+	case float64:
+		return fmt.Sprintf("number %v", val)
+	case string:
+		return fmt.Sprintf("string %q", val)
+	case []*configValue:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
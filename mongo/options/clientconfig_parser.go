@@ -0,0 +1,203 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigError is returned by ClientOptionsFromFile if a config file is malformed or does not match the config schema.
+// Line and Column are 1-based and are zero if the error does not refer to a position in the file. Column is also zero
+// if only the line is known.
+type ConfigError struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ConfigError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.File)
+	if e.Line > 0 {
+		fmt.Fprintf(&b, ":%d", e.Line)
+	}
+	if e.Line > 0 && e.Column > 0 {
+		fmt.Fprintf(&b, ":%d", e.Column)
+	}
+	b.WriteString(": ")
+	if e.Path != "" {
+		b.WriteString(e.Path + ": ")
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// configValue is a value parsed from a config file along with its position, which is zero if the format does not
+// report positions. The value is nil, a bool, an int64, a float64, a string, a []*configValue for an array, or a
+// []configEntry for an object.
+type configValue struct {
+	line, col int
+	val       interface{}
+}
+
+// configEntry is a key-value pair of an object in a config file.
+type configEntry struct {
+	key       string
+	line, col int
+	val       *configValue
+}
+
+// parseConfig parses a config file in the format given by the extension of its name.
+func parseConfig(file string, data []byte) (*configValue, error) {
+	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	case ".json":
+		return parseJSONConfig(file, data)
+	case ".yaml", ".yml":
+		return parseYAMLConfig(file, data)
+	default:
+		msg := fmt.Sprintf("unsupported config file extension %q, the file must be a .json, .yaml, or .yml file", ext)
+		return nil, &ConfigError{File: file, Message: msg}
+	}
+}
+
+func parseJSONConfig(file string, data []byte) (*configValue, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	err := dec.Decode(&doc)
+	if err == nil && dec.More() {
+		err = fmt.Errorf("unexpected content after the end of the document")
+	}
+	if err != nil {
+		ce := &ConfigError{File: file, Message: err.Error()}
+		if se, ok := err.(*json.SyntaxError); ok {
+			ce.Line, ce.Column = offsetPosition(data, se.Offset)
+		}
+		return nil, ce
+	}
+	return jsonConfigValue(doc), nil
+}
+
+// offsetPosition returns the 1-based line and column of the byte before offset, which is where encoding/json reports
+// syntax errors.
+func offsetPosition(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset > 0 {
+		offset--
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// jsonConfigValue converts a document decoded by encoding/json into a configValue. encoding/json does not report
+// positions, so the values have none. The keys of objects are sorted to make errors deterministic.
+func jsonConfigValue(v interface{}) *configValue {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return &configValue{val: i}
+		}
+		f, _ := val.Float64()
+		return &configValue{val: f}
+	case []interface{}:
+		items := make([]*configValue, 0, len(val))
+		for _, item := range val {
+			items = append(items, jsonConfigValue(item))
+		}
+		return &configValue{val: items}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		entries := make([]configEntry, 0, len(keys))
+		for _, key := range keys {
+			entries = append(entries, configEntry{key: key, val: jsonConfigValue(val[key])})
+		}
+		return &configValue{val: entries}
+	default:
+		// nil, bool, or string
+		return &configValue{val: val}
+	}
+}
+
+func parseYAMLConfig(file string, data []byte) (*configValue, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		// yaml.v2 reports the line of a syntax error as a "line N: " prefix of the message, but not the column
+		ce := &ConfigError{File: file, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+		var line int
+		if n, _ := fmt.Sscanf(ce.Message, "line %d:", &line); n == 1 {
+			ce.Line = line
+			ce.Message = strings.TrimSpace(strings.SplitN(ce.Message, ":", 2)[1])
+		}
+		return nil, ce
+	}
+	return yamlConfigValue(file, doc, "")
+}
+
+// yamlConfigValue converts a document decoded by yaml.v2 into a configValue. yaml.v2 does not report the positions of
+// values, so the values have none. Mappings are decoded as yaml.MapSlice to keep the order of their keys.
+func yamlConfigValue(file string, v interface{}, path string) (*configValue, error) {
+	switch val := v.(type) {
+	case int:
+		return &configValue{val: int64(val)}, nil
+	case uint64:
+		// only values that do not fit into an int64 are decoded as uint64
+		return &configValue{val: float64(val)}, nil
+	case []interface{}:
+		items := make([]*configValue, 0, len(val))
+		for i, item := range val {
+			itemVal, err := yamlConfigValue(file, item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, itemVal)
+		}
+		return &configValue{val: items}, nil
+	case yaml.MapSlice:
+		entries := make([]configEntry, 0, len(val))
+		seen := make(map[string]bool, len(val))
+		for _, item := range val {
+			key := fmt.Sprint(item.Key)
+			entryPath := key
+			if path != "" {
+				entryPath = path + "." + key
+			}
+			if seen[key] {
+				return nil, &ConfigError{File: file, Path: entryPath, Message: "duplicate key"}
+			}
+			seen[key] = true
+			entryVal, err := yamlConfigValue(file, item.Value, entryPath)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, configEntry{key: key, val: entryVal})
+		}
+		return &configValue{val: entries}, nil
+	default:
+		// nil, bool, int64, float64, or string
+		return &configValue{val: val}, nil
+	}
+}
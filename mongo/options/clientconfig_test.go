@@ -0,0 +1,156 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestClientOptionsFromFile(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		const config = `
+# driver settings for the inventory service
+uri: mongodb://localhost:27017/?appName=fromURI&maxPoolSize=5
+hosts:
+- db1.example.com:27017
+- "db2.example.com:27017"
+appName: inventory  # overrides the URI
+retryWrites: false
+compressors: [zstd, snappy]
+connectTimeoutMS: 2500
+pool:
+  maxSize: 50
+  maxIdleTimeMS: 60000
+tls:
+  caFile: testdata/ca.pem
+  certificateKeyFile: testdata/nopass/certificate.pem
+auth:
+  mechanism: MONGODB-X509
+readConcern: {level: majority}
+writeConcern:
+  w: majority
+  journal: true
+readPreference:
+  mode: secondaryPreferred
+  tagSets:
+  - dc: east
+    rack: "1"
+  - {}
+  maxStalenessSeconds: 120
+`
+		c, err := clientOptionsFromConfig("config.yaml", []byte(config))
+		assert.Nil(t, err, "clientOptionsFromConfig error: %v", err)
+
+		assert.Equal(t, []string{"db1.example.com:27017", "db2.example.com:27017"}, c.Hosts, "unexpected hosts")
+		assert.Equal(t, "inventory", *c.AppName, "unexpected app name")
+		assert.False(t, *c.RetryWrites, "expected retryable writes to be disabled")
+		assert.Equal(t, []string{"zstd", "snappy"}, c.Compressors, "unexpected compressors")
+		assert.Equal(t, 2500*time.Millisecond, *c.ConnectTimeout, "unexpected connect timeout")
+		assert.Equal(t, uint64(50), *c.MaxPoolSize, "unexpected max pool size")
+		assert.Equal(t, time.Minute, *c.MaxConnIdleTime, "unexpected max idle time")
+		assert.NotNil(t, c.TLSConfig.RootCAs, "expected root CAs to be set")
+		assert.Equal(t, 1, len(c.TLSConfig.Certificates), "expected 1 client certificate")
+		assert.NotEqual(t, "", c.Auth.Username, "expected X509 username from the client certificate")
+		assert.Equal(t, "majority", c.ReadConcern.GetLevel(), "unexpected read concern")
+		assert.Equal(t, "majority", c.WriteConcern.GetW(), "unexpected write concern w")
+		assert.True(t, c.WriteConcern.GetJ(), "expected journaled write concern")
+		assert.Equal(t, readpref.SecondaryPreferredMode, c.ReadPreference.Mode(), "unexpected read preference mode")
+		assert.Equal(t, 2, len(c.ReadPreference.TagSets()), "unexpected tag sets")
+	})
+	t.Run("json file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "clientconfig")
+		assert.Nil(t, err, "TempDir error: %v", err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "config.json")
+		const config = `{
+  "hosts": ["localhost:27017"],
+  "auth": {"username": "app", "password": "", "mechanismProperties": {"CANONICALIZE_HOST_NAME": true}},
+  "writeConcern": {"w": 2, "wtimeoutMS": 1000},
+  "tls": {"enabled": true, "insecure": true}
+}`
+		err = ioutil.WriteFile(path, []byte(config), 0600)
+		assert.Nil(t, err, "WriteFile error: %v", err)
+
+		c, err := ClientOptionsFromFile(path)
+		assert.Nil(t, err, "ClientOptionsFromFile error: %v", err)
+		assert.Equal(t, "app", c.Auth.Username, "unexpected username")
+		assert.True(t, c.Auth.PasswordSet, "expected password to be set")
+		assert.Equal(t, "true", c.Auth.AuthMechanismProperties["CANONICALIZE_HOST_NAME"], "unexpected property")
+		assert.Equal(t, 2, c.WriteConcern.GetW(), "unexpected write concern w")
+		assert.Equal(t, time.Second, c.WriteConcern.GetWTimeout(), "unexpected wtimeout")
+		assert.True(t, c.TLSConfig.InsecureSkipVerify, "expected insecure TLS")
+	})
+	t.Run("errors", func(t *testing.T) {
+		testCases := []struct {
+			name   string
+			config string
+			line   int
+			path   string
+		}{
+			{"unknown key", "hosts: [localhost]\npool:\n  maxsize: 5\n", 0, "pool.maxsize"},
+			{"wrong type", "hosts: [localhost]\nretryWrites: \"yes\"\n", 0, "retryWrites"},
+			{"negative duration", "hosts: [localhost]\nsocketTimeoutMS: -1\n", 0, "socketTimeoutMS"},
+			{"wrong element type", "hosts: [27017]\n", 0, "hosts[0]"},
+			{"yaml syntax", "hosts: [localhost]\nappName: \"inventory\n", 3, ""},
+			{"bad indentation", "hosts: [localhost]\npool:\n  maxSize: 5\n minSize: 1\n", 3, ""},
+			{"duplicate key", "hosts: [localhost]\nhosts: [other]\n", 0, "hosts"},
+			{"missing hosts", "appName: inventory\n", 0, ""},
+			{"invalid mode", "hosts: [localhost]\nreadPreference:\n  mode: fastest\n", 0, "readPreference.mode"},
+			{"tls disabled", "hosts: [localhost]\ntls:\n  enabled: false\n  caFile: ca.pem\n", 0, "tls"},
+			{"missing file", "hosts: [localhost]\ntls: {caFile: missing.pem}\n", 0, "tls.caFile"},
+			{"invalid uri", "uri: localhost\n", 0, "uri"},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := clientOptionsFromConfig("config.yml", []byte(tc.config))
+				ce, ok := err.(*ConfigError)
+				assert.True(t, ok, "expected error of type %T, got %v", ce, err)
+				assert.Equal(t, tc.line, ce.Line, "unexpected line in %v", err)
+				assert.Equal(t, tc.path, ce.Path, "unexpected path in %v", err)
+			})
+		}
+	})
+	t.Run("json errors", func(t *testing.T) {
+		testCases := []struct {
+			name         string
+			config       string
+			line, column int
+			path         string
+		}{
+			{"wrong type", "{\n  \"hosts\": [\"localhost\"],\n  \"appName\": 1\n}", 0, 0, "appName"},
+			{"wrong element type", "{\"hosts\": [\"localhost\", 27017]}", 0, 0, "hosts[1]"},
+			{"syntax", "{\n  \"hosts\": [\"localhost\"\n  \"appName\": \"x\"\n}", 3, 3, ""},
+			{"trailing content", "{\"hosts\": [\"localhost\"]} {}", 0, 0, ""},
+			{"missing hosts", "{}", 0, 0, ""},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := clientOptionsFromConfig("config.json", []byte(tc.config))
+				ce, ok := err.(*ConfigError)
+				assert.True(t, ok, "expected error of type %T, got %v", ce, err)
+				assert.Equal(t, tc.line, ce.Line, "unexpected line in %v", err)
+				assert.Equal(t, tc.column, ce.Column, "unexpected column in %v", err)
+				assert.Equal(t, tc.path, ce.Path, "unexpected path in %v", err)
+			})
+		}
+	})
+	t.Run("unsupported extension", func(t *testing.T) {
+		_, err := clientOptionsFromConfig("config.toml", []byte("hosts = [\"localhost\"]\n"))
+		ce, ok := err.(*ConfigError)
+		assert.True(t, ok, "expected error of type %T, got %v", ce, err)
+		assert.True(t, strings.Contains(ce.Message, ".json, .yaml, or .yml"), "unexpected message %q", ce.Message)
+	})
+}
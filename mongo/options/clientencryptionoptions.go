@@ -6,10 +6,26 @@
 
 package options
 
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// KmsCredentialsFunc returns the credentials of the KMS provider with the given name, in the format of the options of
+// the provider given to SetKmsProviders, and the time at which they expire. The credentials are cached until shortly
+// before they expire. If the expiration is the zero time, the credentials are not cached and the function is called
+// each time they are needed.
+type KmsCredentialsFunc func(ctx context.Context, provider string) (map[string]interface{}, time.Time, error)
+
 // ClientEncryptionOptions represents all possible options used to configure a ClientEncryption instance.
 type ClientEncryptionOptions struct {
-	KeyVaultNamespace string
-	KmsProviders      map[string]map[string]interface{}
+	KeyVaultNamespace  string
+	KmsProviders       map[string]map[string]interface{}
+	TLSConfig          map[string]*tls.Config
+	KmsCredentialsFunc map[string]KmsCredentialsFunc
 }
 
 // ClientEncryption creates a new ClientEncryptionOptions instance.
@@ -23,12 +39,108 @@ func (c *ClientEncryptionOptions) SetKeyVaultNamespace(ns string) *ClientEncrypt
 	return c
 }
 
-// SetKmsProviders specifies options for KMS providers. This is required.
+// SetKmsProviders specifies options for KMS providers. This is required. The supported providers are "aws", with the
+// "accessKeyId" and "secretAccessKey" options, "local", with the "key" option, and "kmip", with the "endpoint" option,
+// which is the host and optional port of the KMIP server. The "azure" and "gcp" providers are configured with the
+// options documented by libmongocrypt.
+//
+// Several providers of the same type can be configured by naming them "<type>:<name>", for example "aws:primary" and
+// "aws:dr", so that data keys in different accounts or tenants can be used by one client. Data keys are created with
+// the full provider name.
+//
+// If the options of the "aws", "azure", or "gcp" provider are an empty map, the credentials are fetched when they are
+// needed. The credentials of any provider can also be supplied on demand by a function given to SetKmsCredentialsFunc.
+// AWS credentials are read from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN environment
+// variables or from the ECS container credentials endpoint, Azure credentials from the Azure Instance Metadata Service,
+// and GCP credentials from the GCP metadata server of the default service account.
 func (c *ClientEncryptionOptions) SetKmsProviders(providers map[string]map[string]interface{}) *ClientEncryptionOptions {
 	c.KmsProviders = providers
 	return c
 }
 
+// SetTLSConfig specifies the TLS configuration to use to connect to the KMS of each provider, keyed by the full provider
+// name, such as "kmip" or "aws:primary". A KMIP server typically requires a client certificate, which can be given in
+// the Certificates field of the tls.Config for the "kmip" provider. Providers without a configuration use the default
+// TLS configuration. BuildTLSConfig can be used to create a tls.Config from certificate files. Configurations that
+// skip the verification of the certificate of the KMS are not allowed.
+func (c *ClientEncryptionOptions) SetTLSConfig(cfg map[string]*tls.Config) *ClientEncryptionOptions {
+	c.TLSConfig = cfg
+	return c
+}
+
+// SetKmsCredentialsFunc specifies functions that supply the credentials of KMS providers, keyed by provider name. A
+// function is called when the credentials of its provider are needed and the cached credentials are missing or about
+// to expire, which allows short-lived credentials, such as tokens from HashiCorp Vault or AWS STS, to be used without
+// recreating the client. The options of each of these providers must be an empty map in SetKmsProviders. A function
+// takes precedence over the credentials the driver fetches from the environment for the "aws", "azure", and "gcp"
+// providers.
+func (c *ClientEncryptionOptions) SetKmsCredentialsFunc(fns map[string]KmsCredentialsFunc) *ClientEncryptionOptions {
+	c.KmsCredentialsFunc = fns
+	return c
+}
+
+// BuildTLSConfig specifies tls.Config options for a KMS provider, such as a KMIP server, an AWS endpoint reached through
+// a private link, or a custom Azure endpoint, from a map of option names to values. The supported options are
+// "tlsCAFile", the path to the certificate authorities used to verify the certificate of the KMS,
+// "tlsCertificateKeyFile", the path to the client certificate and private key concatenated into one file, and
+// "tlsCertificateKeyFilePassword", the password to decrypt the private key. Options that disable the verification of
+// the certificate of the KMS, such as "tlsInsecure", are not supported and result in an error.
+func BuildTLSConfig(tlsOpts map[string]interface{}) (*tls.Config, error) {
+	cfg := &tls.Config{}
+	if len(tlsOpts) == 0 {
+		return cfg, nil
+	}
+
+	strOpt := func(name string) (string, error) {
+		v, ok := tlsOpts[name]
+		if !ok {
+			return "", nil
+		}
+		s, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("expected %q TLS option to be a string, got %T", name, v)
+		}
+		return s, nil
+	}
+	for name := range tlsOpts {
+		switch name {
+		case "tlsCAFile", "tlsCertificateKeyFile", "tlsCertificateKeyFilePassword":
+		case "tlsInsecure", "tlsAllowInvalidCertificates", "tlsAllowInvalidHostnames", "tlsDisableOCSPEndpointCheck",
+			"tlsDisableCertificateRevocationCheck":
+			return nil, fmt.Errorf("insecure TLS option %q is not supported for KMS providers", name)
+		default:
+			return nil, fmt.Errorf("unrecognized TLS option %q", name)
+		}
+	}
+
+	caFile, err := strOpt("tlsCAFile")
+	if err != nil {
+		return nil, err
+	}
+	if caFile != "" {
+		if err = addCACertFromFile(cfg, caFile); err != nil {
+			return nil, err
+		}
+	}
+
+	certKeyFile, err := strOpt("tlsCertificateKeyFile")
+	if err != nil {
+		return nil, err
+	}
+	password, err := strOpt("tlsCertificateKeyFilePassword")
+	if err != nil {
+		return nil, err
+	}
+	if certKeyFile != "" {
+		if _, err = addClientCertFromConcatenatedFile(cfg, certKeyFile, password); err != nil {
+			return nil, err
+		}
+	} else if password != "" {
+		return nil, errors.New("the tlsCertificateKeyFilePassword TLS option requires tlsCertificateKeyFile")
+	}
+	return cfg, nil
+}
+
 // MergeClientEncryptionOptions combines the argued ClientEncryptionOptions in a last-one wins fashion.
 func MergeClientEncryptionOptions(opts ...*ClientEncryptionOptions) *ClientEncryptionOptions {
 	ceo := ClientEncryption()
@@ -43,6 +155,12 @@ func MergeClientEncryptionOptions(opts ...*ClientEncryptionOptions) *ClientEncry
 		if opt.KmsProviders != nil {
 			ceo.KmsProviders = opt.KmsProviders
 		}
+		if opt.TLSConfig != nil {
+			ceo.TLSConfig = opt.TLSConfig
+		}
+		if opt.KmsCredentialsFunc != nil {
+			ceo.KmsCredentialsFunc = opt.KmsCredentialsFunc
+		}
 	}
 
 	return ceo
@@ -0,0 +1,44 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+func TestBuildTLSConfig(t *testing.T) {
+	t.Run("certificate files", func(t *testing.T) {
+		cfg, err := BuildTLSConfig(map[string]interface{}{
+			"tlsCAFile":             "testdata/ca.pem",
+			"tlsCertificateKeyFile": "testdata/nopass/certificate.pem",
+		})
+		assert.Nil(t, err, "BuildTLSConfig error: %v", err)
+		assert.NotNil(t, cfg.RootCAs, "expected root CAs to be set")
+		assert.Equal(t, 1, len(cfg.Certificates), "expected 1 client certificate, got %d", len(cfg.Certificates))
+		assert.False(t, cfg.InsecureSkipVerify, "expected certificates to be verified")
+	})
+	t.Run("errors", func(t *testing.T) {
+		testCases := []struct {
+			name string
+			opts map[string]interface{}
+		}{
+			{"insecure", map[string]interface{}{"tlsInsecure": true}},
+			{"unknown option", map[string]interface{}{"tlsCaFile": "testdata/ca.pem"}},
+			{"wrong type", map[string]interface{}{"tlsCAFile": 1}},
+			{"missing file", map[string]interface{}{"tlsCAFile": "testdata/missing.pem"}},
+			{"password without file", map[string]interface{}{"tlsCertificateKeyFilePassword": "pass"}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := BuildTLSConfig(tc.opts)
+				assert.NotNil(t, err, "expected error")
+			})
+		}
+	})
+}
@@ -0,0 +1,283 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// clientEnvVar is an environment variable read by ApplyEnv and the function that applies its value.
+type clientEnvVar struct {
+	name  string
+	apply func(c *ClientOptions, val string) error
+}
+
+// clientEnvVars contains the environment variables read by ApplyEnv in the order in which they are applied.
+var clientEnvVars = []clientEnvVar{
+	{"MONGODB_URI", func(c *ClientOptions, val string) error { return c.ApplyURI(val).err }},
+	{"MONGODB_HOSTS", func(c *ClientOptions, val string) error {
+		c.Hosts = splitEnvList(val)
+		return nil
+	}},
+	{"MONGODB_APP_NAME", func(c *ClientOptions, val string) error {
+		c.AppName = &val
+		return nil
+	}},
+	{"MONGODB_REPLICA_SET", func(c *ClientOptions, val string) error {
+		c.ReplicaSet = &val
+		return nil
+	}},
+	{"MONGODB_DIRECT_CONNECTION", envBool(func(c *ClientOptions) **bool { return &c.Direct })},
+	{"MONGODB_LOAD_BALANCED", envBool(func(c *ClientOptions) **bool { return &c.LoadBalanced })},
+	{"MONGODB_RETRY_READS", envBool(func(c *ClientOptions) **bool { return &c.RetryReads })},
+	{"MONGODB_RETRY_WRITES", envBool(func(c *ClientOptions) **bool { return &c.RetryWrites })},
+	{"MONGODB_COMPRESSORS", func(c *ClientOptions, val string) error {
+		c.Compressors = splitEnvList(val)
+		return nil
+	}},
+	{"MONGODB_MAX_POOL_SIZE", envUint(func(c *ClientOptions) **uint64 { return &c.MaxPoolSize })},
+	{"MONGODB_MIN_POOL_SIZE", envUint(func(c *ClientOptions) **uint64 { return &c.MinPoolSize })},
+	{"MONGODB_MAX_CONNECTING", envUint(func(c *ClientOptions) **uint64 { return &c.MaxConnecting })},
+	{"MONGODB_MAX_IDLE_TIME_MS", envMS(func(c *ClientOptions) **time.Duration { return &c.MaxConnIdleTime })},
+	{"MONGODB_CONNECT_TIMEOUT_MS", envMS(func(c *ClientOptions) **time.Duration { return &c.ConnectTimeout })},
+	{"MONGODB_HEARTBEAT_FREQUENCY_MS", envMS(func(c *ClientOptions) **time.Duration { return &c.HeartbeatInterval })},
+	{"MONGODB_LOCAL_THRESHOLD_MS", envMS(func(c *ClientOptions) **time.Duration { return &c.LocalThreshold })},
+	{"MONGODB_SERVER_SELECTION_TIMEOUT_MS", envMS(func(c *ClientOptions) **time.Duration {
+		return &c.ServerSelectionTimeout
+	})},
+	{"MONGODB_SOCKET_TIMEOUT_MS", envMS(func(c *ClientOptions) **time.Duration { return &c.SocketTimeout })},
+	{"MONGODB_USERNAME", envAuth(func(cred *Credential, val string) { cred.Username = val })},
+	{"MONGODB_PASSWORD", envAuth(func(cred *Credential, val string) {
+		cred.Password = val
+		cred.PasswordSet = true
+	})},
+	{"MONGODB_AUTH_SOURCE", envAuth(func(cred *Credential, val string) { cred.AuthSource = val })},
+	{"MONGODB_AUTH_MECHANISM", envAuth(func(cred *Credential, val string) { cred.AuthMechanism = val })},
+	{"MONGODB_READ_PREFERENCE", func(c *ClientOptions, val string) error {
+		mode, err := readpref.ModeFromString(val)
+		if err != nil {
+			return err
+		}
+		c.ReadPreference, err = readpref.New(mode)
+		return err
+	}},
+	{"MONGODB_READ_CONCERN_LEVEL", func(c *ClientOptions, val string) error {
+		c.ReadConcern = readconcern.New(readconcern.Level(val))
+		return nil
+	}},
+	{"MONGODB_W", func(c *ClientOptions, val string) error {
+		opt := writeconcern.WTagSet(val)
+		if w, err := strconv.Atoi(val); err == nil {
+			if w < 0 {
+				return fmt.Errorf("w must be non-negative, got %d", w)
+			}
+			opt = writeconcern.W(w)
+		}
+		c.WriteConcern = envWriteConcern(c).WithOptions(opt)
+		return nil
+	}},
+	{"MONGODB_JOURNAL", func(c *ClientOptions, val string) error {
+		j, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		c.WriteConcern = envWriteConcern(c).WithOptions(writeconcern.J(j))
+		return nil
+	}},
+	{"MONGODB_TLS", func(c *ClientOptions, val string) error {
+		enabled, err := strconv.ParseBool(val)
+		switch {
+		case err != nil:
+			return err
+		case !enabled:
+			c.TLSConfig = nil
+		case c.TLSConfig == nil:
+			c.TLSConfig = new(tls.Config)
+		}
+		return nil
+	}},
+	{"MONGODB_TLS_CA_FILE", envTLS(func(cfg *tls.Config, val string) error { return addCACertFromFile(cfg, val) })},
+	{"MONGODB_TLS_CERTIFICATE_KEY_FILE", func(c *ClientOptions, val string) error {
+		cfg, err := envTLSConfig(c)
+		if err != nil {
+			return err
+		}
+		passwd, _ := lookupEnv("MONGODB_TLS_CERTIFICATE_KEY_FILE_PASSWORD")
+		subject, err := addClientCertFromConcatenatedFile(cfg, val, passwd)
+		if err != nil {
+			return err
+		}
+		if c.Auth != nil && strings.ToLower(c.Auth.AuthMechanism) == "mongodb-x509" && c.Auth.Username == "" {
+			cred := *c.Auth
+			cred.Username = extractX509UsernameFromSubject(subject)
+			c.Auth = &cred
+		}
+		return nil
+	}},
+	{"MONGODB_TLS_INSECURE", envTLS(func(cfg *tls.Config, val string) error {
+		insecure, err := strconv.ParseBool(val)
+		cfg.InsecureSkipVerify = insecure
+		return err
+	})},
+}
+
+// lookupEnv looks up environment variables. It is a variable so that it can be overridden in tests.
+var lookupEnv = os.LookupEnv
+
+// ApplyEnv sets options from environment variables, for deployments that keep their configuration in the
+// environment. MONGODB_URI is applied first, as if it was passed to ApplyURI, and the following variables override
+// the options that correspond to them:
+//
+//	MONGODB_HOSTS                        comma-separated list of hosts
+//	MONGODB_APP_NAME                     SetAppName
+//	MONGODB_REPLICA_SET                  SetReplicaSet
+//	MONGODB_DIRECT_CONNECTION            SetDirect
+//	MONGODB_LOAD_BALANCED                SetLoadBalanced
+//	MONGODB_RETRY_READS                  SetRetryReads
+//	MONGODB_RETRY_WRITES                 SetRetryWrites
+//	MONGODB_COMPRESSORS                  comma-separated list of compressors
+//	MONGODB_MAX_POOL_SIZE                SetMaxPoolSize
+//	MONGODB_MIN_POOL_SIZE                SetMinPoolSize
+//	MONGODB_MAX_CONNECTING               SetMaxConnecting
+//	MONGODB_MAX_IDLE_TIME_MS             SetMaxConnIdleTime, in milliseconds
+//	MONGODB_CONNECT_TIMEOUT_MS           SetConnectTimeout, in milliseconds
+//	MONGODB_HEARTBEAT_FREQUENCY_MS       SetHeartbeatInterval, in milliseconds
+//	MONGODB_LOCAL_THRESHOLD_MS           SetLocalThreshold, in milliseconds
+//	MONGODB_SERVER_SELECTION_TIMEOUT_MS  SetServerSelectionTimeout, in milliseconds
+//	MONGODB_SOCKET_TIMEOUT_MS            SetSocketTimeout, in milliseconds
+//	MONGODB_USERNAME                     Credential.Username
+//	MONGODB_PASSWORD                     Credential.Password
+//	MONGODB_AUTH_SOURCE                  Credential.AuthSource
+//	MONGODB_AUTH_MECHANISM               Credential.AuthMechanism
+//	MONGODB_READ_PREFERENCE              read preference mode
+//	MONGODB_READ_CONCERN_LEVEL           read concern level
+//	MONGODB_W                            write concern w, a number or a tag such as "majority"
+//	MONGODB_JOURNAL                      write concern j
+//	MONGODB_TLS                          enables or disables TLS
+//	MONGODB_TLS_CA_FILE                  CA file, like the "tlsCAFile" URI option
+//	MONGODB_TLS_CERTIFICATE_KEY_FILE     client certificate file, like the "tlsCertificateKeyFile" URI option
+//	MONGODB_TLS_CERTIFICATE_KEY_FILE_PASSWORD
+//	                                     password for the client certificate file
+//	MONGODB_TLS_INSECURE                 disables certificate and host name verification
+//
+// Variables that are unset or empty are ignored. Boolean variables accept the values accepted by strconv.ParseBool.
+// The credential variables override the individual fields of a credential from MONGODB_URI, and the write concern
+// variables override individual fields of a write concern from MONGODB_URI. The TLS file and insecure variables only
+// be used if TLS is enabled by MONGODB_URI or MONGODB_TLS.
+//
+// Like ApplyURI, ApplyEnv overwrites options that have previously been set, and options set after it is called
+// override the environment. If a variable has an invalid value, an error is recorded and can be retrieved by calling
+// Validate.
+func (c *ClientOptions) ApplyEnv() *ClientOptions {
+	for _, v := range clientEnvVars {
+		if c.err != nil {
+			return c
+		}
+		val, ok := lookupEnv(v.name)
+		if !ok || val == "" {
+			continue
+		}
+		if err := v.apply(c, val); err != nil {
+			c.err = fmt.Errorf("invalid value for environment variable %s: %v", v.name, err)
+		}
+	}
+	return c
+}
+
+func splitEnvList(val string) []string {
+	items := strings.Split(val, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
+
+func envBool(field func(*ClientOptions) **bool) func(*ClientOptions, string) error {
+	return func(c *ClientOptions, val string) error {
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		*field(c) = &b
+		return nil
+	}
+}
+
+func envUint(field func(*ClientOptions) **uint64) func(*ClientOptions, string) error {
+	return func(c *ClientOptions, val string) error {
+		u, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			return err
+		}
+		*field(c) = &u
+		return nil
+	}
+}
+
+func envMS(field func(*ClientOptions) **time.Duration) func(*ClientOptions, string) error {
+	return func(c *ClientOptions, val string) error {
+		ms, err := strconv.ParseUint(val, 10, 32)
+		if err != nil {
+			return err
+		}
+		d := time.Duration(ms) * time.Millisecond
+		*field(c) = &d
+		return nil
+	}
+}
+
+// envAuth returns a function that sets a field of a copy of the credential, which is created if there is none.
+func envAuth(set func(*Credential, string)) func(*ClientOptions, string) error {
+	return func(c *ClientOptions, val string) error {
+		var cred Credential
+		if c.Auth != nil {
+			cred = *c.Auth
+		}
+		set(&cred, val)
+		c.Auth = &cred
+		return nil
+	}
+}
+
+// envWriteConcern returns the write concern to which the write concern variables are added.
+func envWriteConcern(c *ClientOptions) *writeconcern.WriteConcern {
+	if c.WriteConcern == nil {
+		return writeconcern.New()
+	}
+	return c.WriteConcern
+}
+
+// envTLSConfig replaces the TLS configuration of c with a copy that can be modified and returns it. It returns an
+// error if TLS is not enabled.
+func envTLSConfig(c *ClientOptions) (*tls.Config, error) {
+	if c.TLSConfig == nil {
+		return nil, errors.New("TLS is not enabled, set MONGODB_TLS=true or the tls URI option")
+	}
+	c.TLSConfig = c.TLSConfig.Clone()
+	return c.TLSConfig, nil
+}
+
+// envTLS returns a function that modifies the TLS configuration.
+func envTLS(set func(*tls.Config, string) error) func(*ClientOptions, string) error {
+	return func(c *ClientOptions, val string) error {
+		cfg, err := envTLSConfig(c)
+		if err != nil {
+			return err
+		}
+		return set(cfg, val)
+	}
+}
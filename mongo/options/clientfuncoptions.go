@@ -0,0 +1,262 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/clock"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// ClientOption is a function that configures a ClientOptions instance. The With functions in this package return
+// ClientOptions that call the corresponding setters, and applications can define their own ClientOption functions to
+// group related settings. A ClientOption that returns an error stops the remaining options from being applied.
+type ClientOption func(*ClientOptions) error
+
+// NewClientOptions creates a new ClientOptions instance and applies opts to it in order. Options applied later override
+// options applied earlier. An error is returned if an option fails or the resulting combination of options is invalid
+// (e.g. a direct connection to multiple hosts).
+func NewClientOptions(opts ...ClientOption) (*ClientOptions, error) {
+	c := Client().With(opts...)
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// With applies opts to c in order and then checks that the combination of options is valid. Nil options are ignored.
+// If an option fails or the combination is invalid, an error is recorded and can be retrieved by calling Validate.
+func (c *ClientOptions) With(opts ...ClientOption) *ClientOptions {
+	for _, opt := range opts {
+		if c.err != nil {
+			return c
+		}
+		if opt == nil {
+			continue
+		}
+		if err := opt(c); err != nil {
+			c.err = err
+		}
+	}
+	if c.err == nil {
+		c.err = c.validateCombinations()
+	}
+	return c
+}
+
+// validateCombinations returns an error if c contains options that cannot be used together. The checks match the
+// ones that are performed for connection strings.
+func (c *ClientOptions) validateCombinations() error {
+	direct := c.Direct != nil && *c.Direct
+	if c.LoadBalanced != nil && *c.LoadBalanced {
+		switch {
+		case len(c.Hosts) > 1:
+			return errors.New("loadBalanced cannot be set to true if multiple hosts are specified")
+		case c.ReplicaSet != nil:
+			return errors.New("loadBalanced cannot be set to true if a replica set name is specified")
+		case direct:
+			return errors.New("loadBalanced cannot be set to true if a direct connection is specified")
+		case c.SRVMaxHosts != nil && *c.SRVMaxHosts > 0:
+			return errors.New("loadBalanced cannot be set to true if srvMaxHosts is specified")
+		}
+	}
+	if direct && len(c.Hosts) > 1 {
+		return errors.New("a direct connection cannot be made if multiple hosts are specified")
+	}
+	if c.SRVMaxHosts != nil && *c.SRVMaxHosts > 0 && c.ReplicaSet != nil {
+		return errors.New("srvMaxHosts cannot be specified with replicaSet")
+	}
+	if c.MinPoolSize != nil && c.MaxPoolSize != nil && *c.MaxPoolSize != 0 && *c.MinPoolSize > *c.MaxPoolSize {
+		return fmt.Errorf("minPoolSize %d cannot be greater than maxPoolSize %d", *c.MinPoolSize, *c.MaxPoolSize)
+	}
+	if (c.ProxyUsername == nil) != (c.ProxyPassword == nil) {
+		return errors.New("proxyUsername and proxyPassword must be specified together")
+	}
+	if c.ProxyHost == nil && (c.ProxyPort != nil || c.ProxyUsername != nil) {
+		return errors.New("proxyPort, proxyUsername, and proxyPassword cannot be specified without proxyHost")
+	}
+	return nil
+}
+
+func clientSetter(set func(*ClientOptions)) ClientOption {
+	return func(c *ClientOptions) error {
+		set(c)
+		return nil
+	}
+}
+
+// WithOptions returns a ClientOption that merges opts into the ClientOptions being configured, so that options built
+// with the setters can be combined with ClientOption functions. The options are merged with MergeClientOptions.
+func WithOptions(opts ...*ClientOptions) ClientOption {
+	return func(c *ClientOptions) error {
+		*c = *MergeClientOptions(append([]*ClientOptions{c}, opts...)...)
+		return c.err
+	}
+}
+
+// WithURI returns a ClientOption that applies the given connection string. See ClientOptions.ApplyURI.
+func WithURI(uri string) ClientOption {
+	return func(c *ClientOptions) error {
+		return c.ApplyURI(uri).err
+	}
+}
+
+// WithHosts returns a ClientOption that sets the hosts of the deployment. See ClientOptions.SetHosts.
+func WithHosts(hosts ...string) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetHosts(hosts) })
+}
+
+// WithAppName returns a ClientOption that sets the application name. See ClientOptions.SetAppName.
+func WithAppName(name string) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetAppName(name) })
+}
+
+// WithAuth returns a ClientOption that sets the authentication credential. See ClientOptions.SetAuth.
+func WithAuth(auth Credential) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetAuth(auth) })
+}
+
+// WithReplicaSet returns a ClientOption that sets the replica set name. See ClientOptions.SetReplicaSet.
+func WithReplicaSet(name string) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetReplicaSet(name) })
+}
+
+// WithDirect returns a ClientOption that sets whether a direct connection is made. See ClientOptions.SetDirect.
+func WithDirect(direct bool) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetDirect(direct) })
+}
+
+// WithLoadBalanced returns a ClientOption that sets whether the deployment is behind a load balancer. See
+// ClientOptions.SetLoadBalanced.
+func WithLoadBalanced(lb bool) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetLoadBalanced(lb) })
+}
+
+// WithMaxPoolSize returns a ClientOption that sets the maximum size of each connection pool. See
+// ClientOptions.SetMaxPoolSize.
+func WithMaxPoolSize(size uint64) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetMaxPoolSize(size) })
+}
+
+// WithMinPoolSize returns a ClientOption that sets the minimum size of each connection pool. See
+// ClientOptions.SetMinPoolSize.
+func WithMinPoolSize(size uint64) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetMinPoolSize(size) })
+}
+
+// WithMaxConnecting returns a ClientOption that sets the maximum number of connections a pool can establish
+// concurrently. See ClientOptions.SetMaxConnecting.
+func WithMaxConnecting(n uint64) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetMaxConnecting(n) })
+}
+
+// WithMaxConnIdleTime returns a ClientOption that sets how long a connection can be idle before it is closed. See
+// ClientOptions.SetMaxConnIdleTime.
+func WithMaxConnIdleTime(d time.Duration) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetMaxConnIdleTime(d) })
+}
+
+// WithConnectTimeout returns a ClientOption that sets the timeout for establishing connections. See
+// ClientOptions.SetConnectTimeout.
+func WithConnectTimeout(d time.Duration) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetConnectTimeout(d) })
+}
+
+// WithServerSelectionTimeout returns a ClientOption that sets the server selection timeout. See
+// ClientOptions.SetServerSelectionTimeout.
+func WithServerSelectionTimeout(d time.Duration) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetServerSelectionTimeout(d) })
+}
+
+// WithSocketTimeout returns a ClientOption that sets the socket timeout. See ClientOptions.SetSocketTimeout.
+func WithSocketTimeout(d time.Duration) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetSocketTimeout(d) })
+}
+
+// WithHeartbeatInterval returns a ClientOption that sets the interval between server checks. See
+// ClientOptions.SetHeartbeatInterval.
+func WithHeartbeatInterval(d time.Duration) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetHeartbeatInterval(d) })
+}
+
+// WithLocalThreshold returns a ClientOption that sets the latency window for server selection. See
+// ClientOptions.SetLocalThreshold.
+func WithLocalThreshold(d time.Duration) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetLocalThreshold(d) })
+}
+
+// WithReadPreference returns a ClientOption that sets the default read preference. See
+// ClientOptions.SetReadPreference.
+func WithReadPreference(rp *readpref.ReadPref) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetReadPreference(rp) })
+}
+
+// WithReadConcern returns a ClientOption that sets the default read concern. See ClientOptions.SetReadConcern.
+func WithReadConcern(rc *readconcern.ReadConcern) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetReadConcern(rc) })
+}
+
+// WithWriteConcern returns a ClientOption that sets the default write concern. See ClientOptions.SetWriteConcern.
+func WithWriteConcern(wc *writeconcern.WriteConcern) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetWriteConcern(wc) })
+}
+
+// WithRetryReads returns a ClientOption that sets whether reads are retried. See ClientOptions.SetRetryReads.
+func WithRetryReads(retry bool) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetRetryReads(retry) })
+}
+
+// WithRetryWrites returns a ClientOption that sets whether writes are retried. See ClientOptions.SetRetryWrites.
+func WithRetryWrites(retry bool) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetRetryWrites(retry) })
+}
+
+// WithTLSConfig returns a ClientOption that sets the TLS configuration. See ClientOptions.SetTLSConfig.
+func WithTLSConfig(cfg *tls.Config) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetTLSConfig(cfg) })
+}
+
+// WithCompressors returns a ClientOption that sets the compressors that can be used. See
+// ClientOptions.SetCompressors.
+func WithCompressors(comps ...string) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetCompressors(comps) })
+}
+
+// WithRegistry returns a ClientOption that sets the BSON registry. See ClientOptions.SetRegistry.
+func WithRegistry(registry *bsoncodec.Registry) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetRegistry(registry) })
+}
+
+// WithClock returns a ClientOption that sets the source of time. See ClientOptions.SetClock.
+func WithClock(clk clock.Clock) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetClock(clk) })
+}
+
+// WithObjectIDGenerator returns a ClientOption that sets the generator for the _id of inserted documents. See
+// ClientOptions.SetObjectIDGenerator.
+func WithObjectIDGenerator(gen primitive.ObjectIDGenerator) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetObjectIDGenerator(gen) })
+}
+
+// WithMonitor returns a ClientOption that sets the command monitor. See ClientOptions.SetMonitor.
+func WithMonitor(m *event.CommandMonitor) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetMonitor(m) })
+}
+
+// WithPoolMonitor returns a ClientOption that sets the connection pool monitor. See ClientOptions.SetPoolMonitor.
+func WithPoolMonitor(m *event.PoolMonitor) ClientOption {
+	return clientSetter(func(c *ClientOptions) { c.SetPoolMonitor(m) })
+}
@@ -0,0 +1,89 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestNewClientOptions(t *testing.T) {
+	t.Run("applies options in order", func(t *testing.T) {
+		withDefaults := func(c *ClientOptions) error {
+			c.SetAppName("defaults").SetMaxPoolSize(10)
+			return nil
+		}
+		opts, err := NewClientOptions(
+			withDefaults,
+			WithURI("mongodb://localhost:27017/?readPreference=secondary"),
+			WithHosts("a:27017", "b:27017"),
+			WithMaxPoolSize(50),
+			nil,
+			WithOptions(Client().SetConnectTimeout(5*time.Second)),
+		)
+		assert.Nil(t, err, "NewClientOptions error: %v", err)
+		assert.Equal(t, "defaults", *opts.AppName, "expected app name %q, got %q", "defaults", *opts.AppName)
+		assert.Equal(t, uint64(50), *opts.MaxPoolSize, "expected max pool size 50, got %d", *opts.MaxPoolSize)
+		assert.Equal(t, []string{"a:27017", "b:27017"}, opts.Hosts, "expected hosts to be overridden, got %v",
+			opts.Hosts)
+		assert.Equal(t, readpref.SecondaryMode, opts.ReadPreference.Mode(), "expected mode %v, got %v",
+			readpref.SecondaryMode, opts.ReadPreference.Mode())
+		assert.Equal(t, 5*time.Second, *opts.ConnectTimeout, "expected connect timeout 5s, got %v",
+			*opts.ConnectTimeout)
+	})
+	t.Run("option error", func(t *testing.T) {
+		optErr := errors.New("option error")
+		applied := false
+		_, err := NewClientOptions(
+			func(*ClientOptions) error { return optErr },
+			func(*ClientOptions) error {
+				applied = true
+				return nil
+			},
+		)
+		assert.Equal(t, optErr, err, "expected error %v, got %v", optErr, err)
+		assert.False(t, applied, "expected options after the failing option not to be applied")
+
+		_, err = NewClientOptions(WithURI("not-a-uri://"))
+		assert.NotNil(t, err, "expected error for invalid URI, got nil")
+	})
+	t.Run("invalid combinations", func(t *testing.T) {
+		testCases := []struct {
+			name string
+			opts []ClientOption
+			err  string
+		}{
+			{"direct with multiple hosts", []ClientOption{WithHosts("a", "b"), WithDirect(true)},
+				"a direct connection cannot be made if multiple hosts are specified"},
+			{"load balanced with replica set", []ClientOption{WithLoadBalanced(true), WithReplicaSet("rs")},
+				"loadBalanced cannot be set to true if a replica set name is specified"},
+			{"min pool size above max", []ClientOption{WithMinPoolSize(20), WithMaxPoolSize(10)},
+				"minPoolSize 20 cannot be greater than maxPoolSize 10"},
+			{"proxy port without host", []ClientOption{WithOptions(Client().SetProxyPort(1080))},
+				"proxyPort, proxyUsername, and proxyPassword cannot be specified without proxyHost"},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := NewClientOptions(tc.opts...)
+				assert.NotNil(t, err, "expected error, got nil")
+				assert.Equal(t, tc.err, err.Error(), "expected error %q, got %q", tc.err, err.Error())
+			})
+		}
+
+		_, err := NewClientOptions(WithMinPoolSize(20), WithMaxPoolSize(0))
+		assert.Nil(t, err, "expected no error for an unlimited max pool size, got %v", err)
+	})
+	t.Run("With records errors", func(t *testing.T) {
+		opts := Client().SetAppName("app").With(WithHosts("a", "b"), WithDirect(true))
+		assert.NotNil(t, opts.Validate(), "expected error, got nil")
+		assert.Equal(t, "app", *opts.AppName, "expected app name %q, got %q", "app", *opts.AppName)
+	})
+}
@@ -16,10 +16,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
+	"sort"
 	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/clock"
 	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo/readconcern"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -27,6 +30,8 @@ import (
 	"go.mongodb.org/mongo-driver/tag"
 	"go.mongodb.org/mongo-driver/x/mongo/driver"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/dns"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
 )
 
@@ -38,6 +43,15 @@ type ContextDialer interface {
 	DialContext(ctx context.Context, network, address string) (net.Conn, error)
 }
 
+// Resolver is an interface that can be implemented by types that can resolve host names and SRV and TXT records. It
+// should be used to provide a custom resolver, such as one backed by a service discovery system, when configuring a
+// Client. *net.Resolver implements Resolver.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
 // Credential can be used to provide authentication options when configuring a Client.
 //
 // AuthMechanism: the mechanism to use for authentication. Supported values include "SCRAM-SHA-256", "SCRAM-SHA-1",
@@ -86,38 +100,96 @@ type Credential struct {
 	PasswordSet             bool
 }
 
+// UnknownURIOptionPolicy specifies how a Client handles options in a connection string that the driver does not
+// recognize.
+type UnknownURIOptionPolicy string
+
+// These constants are the valid values for UnknownURIOptionPolicy.
+const (
+	// UnknownURIOptionIgnore silently ignores unknown options.
+	UnknownURIOptionIgnore UnknownURIOptionPolicy = "ignore"
+	// UnknownURIOptionWarn logs unknown options when the Client is created. They are logged by the LogComponentClient
+	// component at LogLevelInfo, so logging must be enabled for that component.
+	UnknownURIOptionWarn UnknownURIOptionPolicy = "warn"
+	// UnknownURIOptionError makes Validate and Client creation fail if there are unknown options.
+	UnknownURIOptionError UnknownURIOptionPolicy = "error"
+)
+
 // ClientOptions contains options to configure a Client instance. Each option can be set through setter functions. See
 // documentation for each setter function for an explanation of the option.
 type ClientOptions struct {
-	AppName                *string
-	Auth                   *Credential
-	ConnectTimeout         *time.Duration
-	Compressors            []string
-	Dialer                 ContextDialer
-	HeartbeatInterval      *time.Duration
-	Hosts                  []string
-	LocalThreshold         *time.Duration
-	MaxConnIdleTime        *time.Duration
-	MaxPoolSize            *uint64
-	MinPoolSize            *uint64
-	PoolMonitor            *event.PoolMonitor
-	Monitor                *event.CommandMonitor
-	ReadConcern            *readconcern.ReadConcern
-	ReadPreference         *readpref.ReadPref
-	Registry               *bsoncodec.Registry
-	ReplicaSet             *string
-	RetryWrites            *bool
-	RetryReads             *bool
-	ServerSelectionTimeout *time.Duration
-	Direct                 *bool
-	SocketTimeout          *time.Duration
-	TLSConfig              *tls.Config
-	WriteConcern           *writeconcern.WriteConcern
-	ZlibLevel              *int
-	ZstdLevel              *int
-	AutoEncryptionOptions  *AutoEncryptionOptions
-
-	err error
+	AppName                 *string
+	Auth                    *Credential
+	Clock                   clock.Clock
+	ConnectTimeout          *time.Duration
+	CommentFunc             func(context.Context) interface{}
+	Compressors             []string
+	CursorLeakThreshold     *time.Duration
+	CursorMonitor           *event.CursorMonitor
+	Dialer                  ContextDialer
+	HeartbeatConnectTimeout *time.Duration
+	HeartbeatInterval       *time.Duration
+	Hosts                   []string
+	KeepAliveCount          *int
+	KeepAliveInterval       *time.Duration
+	LoadBalanced            *bool
+	LocalThreshold          *time.Duration
+	MaxConnIdleTime         *time.Duration
+	ConnExpiryJitter        *time.Duration
+	MinHeartbeatInterval    *time.Duration
+	MaxConnecting           *uint64
+	MaxConnectionRate       *uint64
+	MaxOperationsPerServer  *uint64
+	MaxPoolClearBackoff     *time.Duration
+	MaxPoolSize             *uint64
+	MinPoolSize             *uint64
+	MongosRebalanceInterval *time.Duration
+	MongosSelection         *string
+	ObjectIDGenerator       primitive.ObjectIDGenerator
+	OperationQueueTimeout   *time.Duration
+	PoolClearBackoff        *time.Duration
+	PoolMonitor             *event.PoolMonitor
+	ProfilerLabels          *bool
+	OperationProfilerLabels *bool
+	ProxyHost               *string
+	ProxyPort               *int
+	ProxyUsername           *string
+	ProxyPassword           *string
+	Monitor                 *event.CommandMonitor
+	ReadConcern             *readconcern.ReadConcern
+	ReadPreference          *readpref.ReadPref
+	Registry                *bsoncodec.Registry
+	ReplicaSet              *string
+	Resolver                Resolver
+	RetryWrites             *bool
+	RetryReads              *bool
+	ServerSelectionTimeout  *time.Duration
+	ServerSelectionMonitor  *event.ServerSelectionMonitor
+	ServerMonitor           *event.ServerMonitor
+	SessionLeakThreshold    *time.Duration
+	SlowOperationThreshold  *time.Duration
+	SlowOperationMonitor    *event.SlowOperationMonitor
+	TransactionMonitor      *event.TransactionMonitor
+	SRVMaxHosts             *int
+	SRVPollingInterval      *time.Duration
+	SRVServiceName          *string
+	ServerSelector          description.ServerSelector
+	WeightedServerSelection *bool
+	TopologyMonitor         *event.TopologyMonitor
+	Tracer                  event.Tracer
+	LoggerOptions           *LoggerOptions
+	Direct                  *bool
+	SocketTimeout           *time.Duration
+	TCPUserTimeout          *time.Duration
+	TLSConfig               *tls.Config
+	UnknownURIOptionPolicy  *UnknownURIOptionPolicy
+	WriteConcern            *writeconcern.WriteConcern
+	ZlibLevel               *int
+	ZstdLevel               *int
+	AutoEncryptionOptions   *AutoEncryptionOptions
+
+	err               error
+	unknownURIOptions map[string][]string
 
 	// These options are for internal use only and should not be set. They are deprecated and are
 	// not part of the stability guarantee. They may be removed in the future.
@@ -131,7 +203,27 @@ func Client() *ClientOptions {
 }
 
 // Validate validates the client options. This method will return the first error found.
-func (c *ClientOptions) Validate() error { return c.err }
+func (c *ClientOptions) Validate() error {
+	if c.err != nil {
+		return c.err
+	}
+	if c.SRVPollingInterval != nil && *c.SRVPollingInterval <= 0 {
+		return fmt.Errorf("SRV polling interval must be positive, got %v", *c.SRVPollingInterval)
+	}
+	if c.UnknownURIOptionPolicy == nil {
+		return nil
+	}
+	switch policy := *c.UnknownURIOptionPolicy; policy {
+	case UnknownURIOptionIgnore, UnknownURIOptionWarn:
+	case UnknownURIOptionError:
+		if len(c.unknownURIOptions) > 0 {
+			return fmt.Errorf("unknown URI options: %s", strings.Join(c.UnknownURIOptionNames(), ", "))
+		}
+	default:
+		return fmt.Errorf("invalid unknown URI option policy %q", policy)
+	}
+	return nil
+}
 
 // ApplyURI parses the given URI and sets options accordingly. The URI can contain host names, IPv4/IPv6 literals, or
 // an SRV record that will be resolved when the Client is created. When using an SRV record, TLS support is
@@ -152,7 +244,19 @@ func (c *ClientOptions) ApplyURI(uri string) *ClientOptions {
 		return c
 	}
 
-	cs, err := connstring.Parse(uri)
+	var srvServiceName string
+	if c.SRVServiceName != nil {
+		srvServiceName = *c.SRVServiceName
+	}
+	var srvMaxHosts int
+	if c.SRVMaxHosts != nil {
+		srvMaxHosts = *c.SRVMaxHosts
+	}
+	resolver := dns.DefaultResolver
+	if c.Resolver != nil {
+		resolver = dns.NewResolver(c.Resolver)
+	}
+	cs, err := connstring.ParseWithResolver(uri, resolver, srvServiceName, srvMaxHosts)
 	if err != nil {
 		c.err = err
 		return c
@@ -201,6 +305,10 @@ func (c *ClientOptions) ApplyURI(uri string) *ClientOptions {
 
 	c.Hosts = cs.Hosts
 
+	if cs.LoadBalancedSet {
+		c.LoadBalanced = &cs.LoadBalanced
+	}
+
 	if cs.LocalThresholdSet {
 		c.LocalThreshold = &cs.LocalThreshold
 	}
@@ -209,6 +317,10 @@ func (c *ClientOptions) ApplyURI(uri string) *ClientOptions {
 		c.MaxConnIdleTime = &cs.MaxConnIdleTime
 	}
 
+	if cs.MaxConnectingSet {
+		c.MaxConnecting = &cs.MaxConnecting
+	}
+
 	if cs.MaxPoolSizeSet {
 		c.MaxPoolSize = &cs.MaxPoolSize
 	}
@@ -217,6 +329,19 @@ func (c *ClientOptions) ApplyURI(uri string) *ClientOptions {
 		c.MinPoolSize = &cs.MinPoolSize
 	}
 
+	if cs.ProxyHost != "" {
+		c.ProxyHost = &cs.ProxyHost
+	}
+
+	if cs.ProxyPortSet {
+		c.ProxyPort = &cs.ProxyPort
+	}
+
+	if cs.ProxyUsername != "" {
+		c.ProxyUsername = &cs.ProxyUsername
+		c.ProxyPassword = &cs.ProxyPassword
+	}
+
 	if cs.ReadConcernLevel != "" {
 		c.ReadConcern = readconcern.New(readconcern.Level(cs.ReadConcernLevel))
 	}
@@ -257,6 +382,11 @@ func (c *ClientOptions) ApplyURI(uri string) *ClientOptions {
 		c.ReplicaSet = &cs.ReplicaSet
 	}
 
+	if cs.Scheme == connstring.SchemeMongoDBSRV {
+		c.SRVMaxHosts = &cs.SRVMaxHosts
+		c.SRVServiceName = &cs.SRVServiceName
+	}
+
 	if cs.ServerSelectionTimeoutSet {
 		c.ServerSelectionTimeout = &cs.ServerSelectionTimeout
 	}
@@ -333,9 +463,33 @@ func (c *ClientOptions) ApplyURI(uri string) *ClientOptions {
 		c.ZstdLevel = &cs.ZstdLevel
 	}
 
+	c.unknownURIOptions = cs.UnknownOptions
+
 	return c
 }
 
+// UnknownURIOptions returns the options in the connection string applied by the last call to ApplyURI that the driver
+// did not recognize, e.g. "retryWrite" instead of "retryWrites". The keys are the lower-case option names and the
+// values are the values given for each option. The returned map is a copy and is empty if all options were
+// recognized. See SetUnknownURIOptionPolicy for how unknown options are handled.
+func (c *ClientOptions) UnknownURIOptions() map[string][]string {
+	unknown := make(map[string][]string, len(c.unknownURIOptions))
+	for name, values := range c.unknownURIOptions {
+		unknown[name] = append([]string(nil), values...)
+	}
+	return unknown
+}
+
+// UnknownURIOptionNames returns the sorted names of the options returned by UnknownURIOptions.
+func (c *ClientOptions) UnknownURIOptionNames() []string {
+	names := make([]string, 0, len(c.unknownURIOptions))
+	for name := range c.unknownURIOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // SetAppName specifies an application name that is sent to the server when creating new connections. It is used by the
 // server to log connection and profiling information (e.g. slow query logs). This can also be set through the "appName"
 // URI option (e.g "appName=example_application"). The default is empty, meaning no app name will be sent.
@@ -352,6 +506,25 @@ func (c *ClientOptions) SetAuth(auth Credential) *ClientOptions {
 	return c
 }
 
+// SetClock specifies the source of time used for server heartbeats, round trip time measurements, server selection
+// timeouts, and the expiry of pooled server sessions. A clock.Fake allows tests to exercise this behavior without
+// sleeping. Network deadlines and the deadlines of contexts are not affected. The default is nil, meaning clock.System
+// is used.
+func (c *ClientOptions) SetClock(clk clock.Clock) *ClientOptions {
+	c.Clock = clk
+	return c
+}
+
+// SetCommentFunc specifies a function that derives a comment from the context of each operation, such as a trace ID
+// or request ID stored by the application. The comment is added to every command sent to servers with version 4.4 or
+// later that does not already have one, so that server logs and profiler output can be correlated with application
+// traces. The function must return a string or a document, or nil to send the command without a comment. It is called
+// once for every command and must be safe for concurrent use. The default is nil, meaning no comments are added.
+func (c *ClientOptions) SetCommentFunc(fn func(ctx context.Context) interface{}) *ClientOptions {
+	c.CommentFunc = fn
+	return c
+}
+
 // SetCompressors sets the compressors that can be used when communicating with a server. Valid values are:
 //
 // 1. "snappy" - requires server version >= 3.4
@@ -384,8 +557,30 @@ func (c *ClientOptions) SetConnectTimeout(d time.Duration) *ClientOptions {
 	return c
 }
 
+// SetCursorLeakThreshold enables cursor leak detection. When enabled, the Client tracks each cursor it creates along
+// with the stack trace of the goroutine that created it. If a cursor is neither closed nor exhausted within the given
+// duration, it is reported to the CursorMonitor set through SetCursorMonitor and logged by the LogComponentClient
+// component at LogLevelInfo, if logging is enabled for that component. Nothing is printed by default. Cursors that are
+// still open can be listed at any time with Client.OpenCursors. Capturing stack traces has a cost for every cursor
+// created, so this is intended for debugging. The default is 0, which means that cursors are not tracked.
+func (c *ClientOptions) SetCursorLeakThreshold(d time.Duration) *ClientOptions {
+	c.CursorLeakThreshold = &d
+	return c
+}
+
+// SetCursorMonitor specifies a CursorMonitor to receive cursor leak events. This option is ignored if cursor leak
+// detection is not enabled through SetCursorLeakThreshold. See the event.CursorMonitor documentation for more
+// information about the structure of the monitor and events that can be received.
+func (c *ClientOptions) SetCursorMonitor(m *event.CursorMonitor) *ClientOptions {
+	c.CursorMonitor = m
+	return c
+}
+
 // SetDialer specifies a custom ContextDialer to be used to create new connections to the server. The default is a
-// net.Dialer instance with a 300 second keepalive time.
+// net.Dialer instance with a 300 second keepalive time. The dialer is called with the host names of the servers, unless
+// a Resolver is set through SetResolver. In that case, the driver resolves host names before calling the dialer, so
+// the dialer is called with IP addresses. If a host has several addresses, connection attempts to them are raced as
+// described in RFC 8305, so the dialer may be called concurrently for the same host.
 func (c *ClientOptions) SetDialer(d ContextDialer) *ClientOptions {
 	c.Dialer = d
 	return c
@@ -406,6 +601,14 @@ func (c *ClientOptions) SetDirect(b bool) *ClientOptions {
 	return c
 }
 
+// SetHeartbeatConnectTimeout specifies the timeout for establishing the connections used for background server checks,
+// independently of the timeout set with SetConnectTimeout for connections used by operations. This is useful in
+// environments where monitoring connections should fail fast. The default is 0, meaning the connect timeout is used.
+func (c *ClientOptions) SetHeartbeatConnectTimeout(d time.Duration) *ClientOptions {
+	c.HeartbeatConnectTimeout = &d
+	return c
+}
+
 // SetHeartbeatInterval specifies the amount of time to wait between periodic background server checks. This can also be
 // set through the "heartbeatIntervalMS" URI option (e.g. "heartbeatIntervalMS=10000"). The default is 10 seconds.
 func (c *ClientOptions) SetHeartbeatInterval(d time.Duration) *ClientOptions {
@@ -423,6 +626,33 @@ func (c *ClientOptions) SetHosts(s []string) *ClientOptions {
 	return c
 }
 
+// SetKeepAliveInterval specifies the TCP keepalive period for connections to the cluster. On Linux, this is both how
+// long a connection is idle before the first keepalive probe is sent and the interval between subsequent probes. A
+// negative value disables keepalive probes. This option is ignored if a custom Dialer is set. The default is 0,
+// meaning the Go default of 15 seconds is used.
+func (c *ClientOptions) SetKeepAliveInterval(d time.Duration) *ClientOptions {
+	c.KeepAliveInterval = &d
+	return c
+}
+
+// SetKeepAliveCount specifies the number of unanswered TCP keepalive probes after which a connection is considered
+// dead and closed. This option is only supported on Linux and is ignored if a custom Dialer is set. The default is 0,
+// meaning the operating system default is used.
+func (c *ClientOptions) SetKeepAliveCount(n int) *ClientOptions {
+	c.KeepAliveCount = &n
+	return c
+}
+
+// SetLoadBalanced specifies whether or not the driver is connecting to a load balancer fronting a sharded cluster. If
+// set to true, the driver will not monitor the deployment and will route every operation through the single host
+// provided, pinning connections to cursors and transactions as needed. This option cannot be combined with multiple
+// hosts, a replica set name, or a direct connection. This can also be set through the "loadBalanced" URI option (e.g.
+// "loadBalanced=true"). The default is false.
+func (c *ClientOptions) SetLoadBalanced(lb bool) *ClientOptions {
+	c.LoadBalanced = &lb
+	return c
+}
+
 // SetLocalThreshold specifies the width of the 'latency window': when choosing between multiple suitable servers for an
 // operation, this is the acceptable non-negative delta between shortest and longest average round-trip times. A server
 // within the latency window is selected randomly. This can also be set through the "localThresholdMS" URI option (e.g.
@@ -432,6 +662,24 @@ func (c *ClientOptions) SetLocalThreshold(d time.Duration) *ClientOptions {
 	return c
 }
 
+// SetMinHeartbeatInterval specifies the minimum amount of time between background checks of a server. Checks requested
+// immediately, such as after a network error or during server selection, are delayed until this interval has passed
+// since the previous check. Lower values allow the driver to react to topology changes faster at the cost of more
+// monitoring traffic. The default is 500 milliseconds.
+func (c *ClientOptions) SetMinHeartbeatInterval(d time.Duration) *ClientOptions {
+	c.MinHeartbeatInterval = &d
+	return c
+}
+
+// SetConnExpiryJitter specifies the maximum amount of time by which the idle timeout and lifetime of each connection
+// are randomly shortened. Connections that are created at the same time, for example when a pool is warmed up or
+// refilled after being cleared, would otherwise expire at the same time and be replaced in a burst of handshakes.
+// The jitter applied to a timeout is at most half of that timeout. The default is 0, meaning no jitter is applied.
+func (c *ClientOptions) SetConnExpiryJitter(d time.Duration) *ClientOptions {
+	c.ConnExpiryJitter = &d
+	return c
+}
+
 // SetMaxConnIdleTime specifies the maximum amount of time that a connection will remain idle in a connection pool
 // before it is removed from the pool and closed. This can also be set through the "maxIdleTimeMS" URI option (e.g.
 // "maxIdleTimeMS=10000"). The default is 0, meaning a connection can remain unused indefinitely.
@@ -440,6 +688,68 @@ func (c *ClientOptions) SetMaxConnIdleTime(d time.Duration) *ClientOptions {
 	return c
 }
 
+// SetMaxConnecting specifies the maximum number of connections to each server that can be establishing a connection at
+// the same time. Establishing a connection includes dialing and the connection handshake, so lower values reduce the
+// load on the server when a pool grows quickly, while higher values allow a pool to grow faster. Operations that need
+// a new connection while this limit is reached wait for an in-progress connection attempt to finish. This can also be
+// set through the "maxConnecting" URI option (e.g. "maxConnecting=2"). The default is 2. If this is 0, the default
+// will be used.
+func (c *ClientOptions) SetMaxConnecting(u uint64) *ClientOptions {
+	c.MaxConnecting = &u
+	return c
+}
+
+// SetMaxConnectionRate specifies the maximum number of new connections per second that can be established to each
+// server. This caps the rate at which a pool reconnects after it is cleared. The default is 0, which means there is no
+// limit.
+func (c *ClientOptions) SetMaxConnectionRate(u uint64) *ClientOptions {
+	c.MaxConnectionRate = &u
+	return c
+}
+
+// SetPoolClearBackoff specifies how long to wait before establishing new connections to a server after its connection
+// pool is cleared due to errors. The wait doubles with each consecutive clear, up to the maximum set with
+// SetMaxPoolClearBackoff, and is reset once a connection is established. A random jitter of up to half the wait is
+// applied so that clients do not reconnect in lockstep. The default is 0, which means connections are re-established
+// immediately.
+func (c *ClientOptions) SetPoolClearBackoff(d time.Duration) *ClientOptions {
+	c.PoolClearBackoff = &d
+	return c
+}
+
+// SetMaxPoolClearBackoff specifies the upper bound on the wait configured with SetPoolClearBackoff. The default is 10
+// seconds. If this is 0, the default will be used.
+func (c *ClientOptions) SetMaxPoolClearBackoff(d time.Duration) *ClientOptions {
+	c.MaxPoolClearBackoff = &d
+	return c
+}
+
+// SetMaxOperationsPerServer specifies the maximum number of operations that can be in progress on each server at the
+// same time. Unlike SetMaxPoolSize, which bounds the number of connections, this bounds the load the client places on
+// a server. Operations over the limit wait for one to finish for up to the timeout set with SetOperationQueueTimeout
+// and then fail with a topology.ServerOverloadedError. The default is 0, which means there is no limit.
+func (c *ClientOptions) SetMaxOperationsPerServer(u uint64) *ClientOptions {
+	c.MaxOperationsPerServer = &u
+	return c
+}
+
+// SetObjectIDGenerator specifies the generator used for the ObjectIDs that the Client adds as the _id of inserted
+// documents that do not have one. Collections and bulk writes of the Client use it for InsertOne, InsertMany, and
+// InsertOneModel. A deterministic generator such as primitive.NewDeterministicObjectIDGenerator makes the inserted
+// documents predictable in tests. The default is nil, meaning the generator set with primitive.SetObjectIDGenerator is
+// used.
+func (c *ClientOptions) SetObjectIDGenerator(gen primitive.ObjectIDGenerator) *ClientOptions {
+	c.ObjectIDGenerator = gen
+	return c
+}
+
+// SetOperationQueueTimeout specifies how long an operation waits for a slot on a server that has reached the limit set
+// with SetMaxOperationsPerServer. The default is 0, which means operations wait until their context is done.
+func (c *ClientOptions) SetOperationQueueTimeout(d time.Duration) *ClientOptions {
+	c.OperationQueueTimeout = &d
+	return c
+}
+
 // SetMaxPoolSize specifies that maximum number of connections allowed in the driver's connection pool to each server.
 // Requests to a server will block if this maximum is reached. This can also be set through the "maxPoolSize" URI option
 // (e.g. "maxPoolSize=100"). The default is 100. If this is 0, it will be set to math.MaxInt64.
@@ -470,6 +780,36 @@ func (c *ClientOptions) SetMonitor(m *event.CommandMonitor) *ClientOptions {
 	return c
 }
 
+// SetProxyHost specifies the host name or IP address of a SOCKS5 proxy that all connections to the cluster will be
+// routed through. This can also be set through the "proxyHost" URI option (e.g. "proxyHost=proxy.example.com"). The
+// default is "", meaning no proxy is used.
+func (c *ClientOptions) SetProxyHost(host string) *ClientOptions {
+	c.ProxyHost = &host
+	return c
+}
+
+// SetProxyPort specifies the port of the SOCKS5 proxy set with SetProxyHost. This can also be set through the
+// "proxyPort" URI option (e.g. "proxyPort=1080"). The default is 1080.
+func (c *ClientOptions) SetProxyPort(port int) *ClientOptions {
+	c.ProxyPort = &port
+	return c
+}
+
+// SetProxyUsername specifies the username used to authenticate to the SOCKS5 proxy set with SetProxyHost. A password
+// must also be set with SetProxyPassword. This can also be set through the "proxyUsername" URI option (e.g.
+// "proxyUsername=user"). The default is "", meaning no authentication is performed.
+func (c *ClientOptions) SetProxyUsername(username string) *ClientOptions {
+	c.ProxyUsername = &username
+	return c
+}
+
+// SetProxyPassword specifies the password used to authenticate to the SOCKS5 proxy set with SetProxyHost. This can
+// also be set through the "proxyPassword" URI option (e.g. "proxyPassword=pencil").
+func (c *ClientOptions) SetProxyPassword(password string) *ClientOptions {
+	c.ProxyPassword = &password
+	return c
+}
+
 // SetReadConcern specifies the read concern to use for read operations. A read concern level can also be set through
 // the "readConcernLevel" URI option (e.g. "readConcernLevel=majority"). The default is nil, meaning the server will use
 // its configured default.
@@ -515,6 +855,14 @@ func (c *ClientOptions) SetReplicaSet(s string) *ClientOptions {
 	return c
 }
 
+// SetResolver specifies a custom Resolver used to look up the addresses of hosts and the SRV and TXT records of a
+// "mongodb+srv" URI. To be used for the SRV and TXT lookups done while parsing a URI, this must be called before
+// ApplyURI. The default is nil, meaning the system DNS resolver is used.
+func (c *ClientOptions) SetResolver(r Resolver) *ClientOptions {
+	c.Resolver = r
+	return c
+}
+
 // SetRetryWrites specifies whether supported write operations should be retried once on certain errors, such as network
 // errors.
 //
@@ -545,6 +893,76 @@ func (c *ClientOptions) SetRetryReads(b bool) *ClientOptions {
 	return c
 }
 
+// SetSRVMaxHosts specifies the maximum number of hosts to connect to when the hosts are discovered through SRV records
+// using a "mongodb+srv" URI. If more hosts are discovered, the hosts to connect to are chosen at random. Because the
+// SRV records are looked up when the URI is parsed, this must be set before ApplyURI is called. This can also be set
+// through the "srvMaxHosts" URI option (e.g. "srvMaxHosts=3"), which takes precedence. The default is 0, meaning there
+// is no maximum.
+func (c *ClientOptions) SetSRVMaxHosts(n int) *ClientOptions {
+	c.SRVMaxHosts = &n
+	return c
+}
+
+// SetSRVPollingInterval specifies how often the SRV records of a "mongodb+srv" URI are polled to discover hosts that
+// have been added to or removed from a sharded cluster. Polling is not done for other types of deployments. Changes
+// to the hosts are reported to the TopologyMonitor set through SetTopologyMonitor. The interval must be positive, or
+// Validate and Client creation fail. The default is 60 seconds.
+func (c *ClientOptions) SetSRVPollingInterval(d time.Duration) *ClientOptions {
+	c.SRVPollingInterval = &d
+	return c
+}
+
+// SetSRVServiceName specifies the service name used to look up SRV records for a "mongodb+srv" URI. Because the SRV
+// records are looked up when the URI is parsed, this must be set before ApplyURI is called. This can also be set
+// through the "srvServiceName" URI option (e.g. "srvServiceName=customname"), which takes precedence. The default is
+// "mongodb".
+func (c *ClientOptions) SetSRVServiceName(name string) *ClientOptions {
+	c.SRVServiceName = &name
+	return c
+}
+
+// SetServerSelector specifies a server selector that is applied after the driver's default server selection logic for
+// every operation. The selector is passed the servers that are suitable for the operation according to its read
+// preference and the latency window and can narrow them down further, for example to prefer servers in the
+// application's availability zone or to avoid hosts that are failing. If the selector returns no servers, server
+// selection is retried until a suitable server is found or the server selection timeout expires. Because the selector
+// runs for every operation, including operations in transactions that are pinned to a mongos, it should not remove
+// every suitable server unless no server should be used. The default is nil, meaning only the default selection logic
+// is used.
+func (c *ClientOptions) SetServerSelector(ss description.ServerSelector) *ClientOptions {
+	c.ServerSelector = ss
+	return c
+}
+
+// SetWeightedServerSelection specifies whether the driver should use weighted selection to choose among the servers
+// that are suitable for an operation and within the latency window. If true, two of those servers are chosen at random
+// and the operation is sent to the one with fewer operations in progress, with ties broken by the lower average round
+// trip time. This "power of two choices" strategy reduces tail latency when the servers in a replica set or sharded
+// cluster have different capacities. The default is false, meaning a suitable server is chosen uniformly at random.
+func (c *ClientOptions) SetWeightedServerSelection(b bool) *ClientOptions {
+	c.WeightedServerSelection = &b
+	return c
+}
+
+// SetMongosSelection specifies how the driver chooses among the mongos servers that are suitable for an operation in
+// a sharded cluster. Transactions and cursors stay pinned to the mongos that ran their first operation, so this also
+// determines how they are spread across mongos servers. Valid values are "random", "roundRobin", which cycles through
+// the mongos servers in address order, and "leastOperations", which chooses the mongos with the fewest operations in
+// progress. The default is "random", meaning a mongos is chosen as described for SetWeightedServerSelection.
+func (c *ClientOptions) SetMongosSelection(policy string) *ClientOptions {
+	c.MongosSelection = &policy
+	return c
+}
+
+// SetMongosRebalanceInterval specifies how often the driver rebalances connections across the mongos servers of a
+// sharded cluster. On each rebalance, idle connections to a mongos that holds more than its share of the open
+// connections are closed, so that connections are spread evenly again after a mongos is added or restarted, for
+// example during a deploy. The default is 0, meaning connections are not rebalanced.
+func (c *ClientOptions) SetMongosRebalanceInterval(d time.Duration) *ClientOptions {
+	c.MongosRebalanceInterval = &d
+	return c
+}
+
 // SetServerSelectionTimeout specifies how long the driver will wait to find an available, suitable server to execute an
 // operation. This can also be set through the "serverSelectionTimeoutMS" URI option (e.g.
 // "serverSelectionTimeoutMS=30000"). The default value is 30 seconds.
@@ -553,6 +971,44 @@ func (c *ClientOptions) SetServerSelectionTimeout(d time.Duration) *ClientOption
 	return c
 }
 
+// SetServerSelectionMonitor specifies a ServerSelectionMonitor to receive events when operations start selecting a
+// server, wait for a suitable server, and succeed or fail to select one. The events describe the selector, the
+// suitable candidates, and the total time taken, so that server selection timeouts can be diagnosed. See the
+// event.ServerSelectionMonitor documentation for more information about the structure of the monitor and events that
+// can be received.
+func (c *ClientOptions) SetServerSelectionMonitor(m *event.ServerSelectionMonitor) *ClientOptions {
+	c.ServerSelectionMonitor = m
+	return c
+}
+
+// SetSessionLeakThreshold enables session leak detection. When enabled, the Client records the stack trace of the
+// goroutine that starts each session with Client.StartSession. If a session is not ended within the given duration, it
+// is logged along with that stack trace by the LogComponentClient component at LogLevelInfo, so logging must be
+// enabled for that component through SetLoggerOptions or the MONGODB_LOG_CLIENT environment variable. Capturing stack
+// traces has a cost for every session started, so this is intended for debugging. The default is 0, which means that
+// sessions are not tracked.
+func (c *ClientOptions) SetSessionLeakThreshold(d time.Duration) *ClientOptions {
+	c.SessionLeakThreshold = &d
+	return c
+}
+
+// SetSlowOperationThreshold enables slow operation reporting. When enabled, every command that takes longer than the
+// given duration from being sent to the server until its reply is received is reported to the SlowOperationMonitor
+// set through SetSlowOperationMonitor. Unlike a CommandMonitor, this does not copy every command and reply, so it is
+// cheap enough to leave enabled in production. The default is 0, which means that slow operations are not reported.
+func (c *ClientOptions) SetSlowOperationThreshold(d time.Duration) *ClientOptions {
+	c.SlowOperationThreshold = &d
+	return c
+}
+
+// SetSlowOperationMonitor specifies a SlowOperationMonitor to receive slow operation events. This option is ignored if
+// slow operation reporting is not enabled through SetSlowOperationThreshold. See the event.SlowOperationEvent
+// documentation for the information reported about each operation.
+func (c *ClientOptions) SetSlowOperationMonitor(m *event.SlowOperationMonitor) *ClientOptions {
+	c.SlowOperationMonitor = m
+	return c
+}
+
 // SetSocketTimeout specifies how long the driver will wait for a socket read or write to return before returning a
 // network error. This can also be set through the "socketTimeoutMS" URI option (e.g. "socketTimeoutMS=1000"). The
 // default value is 0, meaning no timeout is used and socket operations can block indefinitely.
@@ -561,6 +1017,16 @@ func (c *ClientOptions) SetSocketTimeout(d time.Duration) *ClientOptions {
 	return c
 }
 
+// SetTCPUserTimeout specifies the TCP_USER_TIMEOUT socket option for connections to the cluster: the maximum amount
+// of time that data written to a connection may remain unacknowledged by the peer before the connection is closed.
+// Together with SetKeepAliveInterval and SetKeepAliveCount, this allows dead peers behind NATs and load balancers to be
+// detected in seconds. This option is only supported on Linux and is ignored if a custom Dialer is set. The default
+// is 0, meaning the operating system default is used.
+func (c *ClientOptions) SetTCPUserTimeout(d time.Duration) *ClientOptions {
+	c.TCPUserTimeout = &d
+	return c
+}
+
 // SetTLSConfig specifies a tls.Config instance to use use to configure TLS on all connections created to the cluster.
 // This can also be set through the following URI options:
 //
@@ -589,6 +1055,77 @@ func (c *ClientOptions) SetTLSConfig(cfg *tls.Config) *ClientOptions {
 	return c
 }
 
+// SetServerMonitor specifies a ServerMonitor to receive server heartbeat events. Each event includes the duration of
+// the heartbeat, and a successful heartbeat also includes the hello reply of the server, so changes to the state of a
+// deployment can be diagnosed from the events alone. See the event.ServerMonitor documentation for more information
+// about the structure of the monitor and events that can be received.
+func (c *ClientOptions) SetServerMonitor(m *event.ServerMonitor) *ClientOptions {
+	c.ServerMonitor = m
+	return c
+}
+
+// SetTopologyMonitor specifies a TopologyMonitor to receive topology events, such as hosts being added to or removed
+// from the deployment by SRV polling. See the event.TopologyMonitor documentation for more information about the
+// structure of the monitor and events that can be received.
+func (c *ClientOptions) SetTopologyMonitor(m *event.TopologyMonitor) *ClientOptions {
+	c.TopologyMonitor = m
+	return c
+}
+
+// SetLoggerOptions specifies options for logging driver internals, such as commands, connection pool events, server
+// selection, and topology changes. See the LoggerOptions documentation for the environment variables that configure
+// logging when options are not set. The default is nil, meaning logging is configured only by the environment.
+func (c *ClientOptions) SetLoggerOptions(opts *LoggerOptions) *ClientOptions {
+	c.LoggerOptions = opts
+	return c
+}
+
+// SetProfilerLabels specifies whether the background goroutines of the Client, such as the goroutines that monitor
+// each server, are labeled with pprof labels, so that CPU profiles attribute their work to the driver. The labels are
+// "mongodb.component", e.g. "monitor", and "mongodb.address" for goroutines that work for a single server. The default
+// is false.
+func (c *ClientOptions) SetProfilerLabels(b bool) *ClientOptions {
+	c.ProfilerLabels = &b
+	return c
+}
+
+// SetOperationProfilerLabels specifies whether the goroutines that run operations are labeled with pprof labels while
+// each command runs, so that CPU profiles attribute the time spent sending commands and decoding replies to specific
+// MongoDB operations. The labels are "mongodb.command", e.g. "find", and "mongodb.namespace", e.g. "db.coll". The
+// labels of the goroutine are restored when the operation returns. The default is false.
+func (c *ClientOptions) SetOperationProfilerLabels(b bool) *ClientOptions {
+	c.OperationProfilerLabels = &b
+	return c
+}
+
+// SetTracer specifies a Tracer used to create a span for each operation and transaction run by the Client. Spans
+// carry the OpenTelemetry database semantic attributes, such as db.name, db.operation, and net.peer.name, as well as
+// the number of times the operation was retried. See the event.Tracer documentation for how to export the spans to
+// OpenTelemetry. The default is nil, meaning operations are not traced.
+func (c *ClientOptions) SetTracer(t event.Tracer) *ClientOptions {
+	c.Tracer = t
+	return c
+}
+
+// SetTransactionMonitor specifies a TransactionMonitor to receive events when transactions are started, committed,
+// aborted, and retried by WithTransaction. Events carry the time since the transaction was started and, for retries,
+// the error label that caused the retry. See the event.TransactionEvent documentation for the event types. The default
+// is nil, meaning transaction events are not published.
+func (c *ClientOptions) SetTransactionMonitor(m *event.TransactionMonitor) *ClientOptions {
+	c.TransactionMonitor = m
+	return c
+}
+
+// SetUnknownURIOptionPolicy specifies how options in the connection string that the driver does not recognize are
+// handled. With UnknownURIOptionWarn, the unknown options are logged by the LogComponentClient component when the
+// Client is created. With UnknownURIOptionError, Validate returns an error and the Client cannot be created, which
+// catches typos such as "retryWrite=true". The policy applies regardless of whether it is set before or after
+// ApplyURI. The default is UnknownURIOptionIgnore.
+func (c *ClientOptions) SetUnknownURIOptionPolicy(policy UnknownURIOptionPolicy) *ClientOptions {
+	c.UnknownURIOptionPolicy = &policy
+	return c
+}
+
 // SetWriteConcern specifies the write concern to use to for write operations. This can also be set through the following
 // URI options:
 //
@@ -658,24 +1195,75 @@ func MergeClientOptions(opts ...*ClientOptions) *ClientOptions {
 		if opt.AuthenticateToAnything != nil {
 			c.AuthenticateToAnything = opt.AuthenticateToAnything
 		}
+		if opt.Clock != nil {
+			c.Clock = opt.Clock
+		}
+		if opt.CommentFunc != nil {
+			c.CommentFunc = opt.CommentFunc
+		}
 		if opt.Compressors != nil {
 			c.Compressors = opt.Compressors
 		}
 		if opt.ConnectTimeout != nil {
 			c.ConnectTimeout = opt.ConnectTimeout
 		}
+		if opt.CursorLeakThreshold != nil {
+			c.CursorLeakThreshold = opt.CursorLeakThreshold
+		}
+		if opt.CursorMonitor != nil {
+			c.CursorMonitor = opt.CursorMonitor
+		}
+		if opt.HeartbeatConnectTimeout != nil {
+			c.HeartbeatConnectTimeout = opt.HeartbeatConnectTimeout
+		}
 		if opt.HeartbeatInterval != nil {
 			c.HeartbeatInterval = opt.HeartbeatInterval
 		}
 		if len(opt.Hosts) > 0 {
 			c.Hosts = opt.Hosts
 		}
+		if opt.KeepAliveCount != nil {
+			c.KeepAliveCount = opt.KeepAliveCount
+		}
+		if opt.KeepAliveInterval != nil {
+			c.KeepAliveInterval = opt.KeepAliveInterval
+		}
+		if opt.LoadBalanced != nil {
+			c.LoadBalanced = opt.LoadBalanced
+		}
 		if opt.LocalThreshold != nil {
 			c.LocalThreshold = opt.LocalThreshold
 		}
 		if opt.MaxConnIdleTime != nil {
 			c.MaxConnIdleTime = opt.MaxConnIdleTime
 		}
+		if opt.ConnExpiryJitter != nil {
+			c.ConnExpiryJitter = opt.ConnExpiryJitter
+		}
+		if opt.MinHeartbeatInterval != nil {
+			c.MinHeartbeatInterval = opt.MinHeartbeatInterval
+		}
+		if opt.MaxConnecting != nil {
+			c.MaxConnecting = opt.MaxConnecting
+		}
+		if opt.MaxConnectionRate != nil {
+			c.MaxConnectionRate = opt.MaxConnectionRate
+		}
+		if opt.MaxOperationsPerServer != nil {
+			c.MaxOperationsPerServer = opt.MaxOperationsPerServer
+		}
+		if opt.ObjectIDGenerator != nil {
+			c.ObjectIDGenerator = opt.ObjectIDGenerator
+		}
+		if opt.OperationQueueTimeout != nil {
+			c.OperationQueueTimeout = opt.OperationQueueTimeout
+		}
+		if opt.PoolClearBackoff != nil {
+			c.PoolClearBackoff = opt.PoolClearBackoff
+		}
+		if opt.MaxPoolClearBackoff != nil {
+			c.MaxPoolClearBackoff = opt.MaxPoolClearBackoff
+		}
 		if opt.MaxPoolSize != nil {
 			c.MaxPoolSize = opt.MaxPoolSize
 		}
@@ -688,6 +1276,18 @@ func MergeClientOptions(opts ...*ClientOptions) *ClientOptions {
 		if opt.Monitor != nil {
 			c.Monitor = opt.Monitor
 		}
+		if opt.ProxyHost != nil {
+			c.ProxyHost = opt.ProxyHost
+		}
+		if opt.ProxyPort != nil {
+			c.ProxyPort = opt.ProxyPort
+		}
+		if opt.ProxyUsername != nil {
+			c.ProxyUsername = opt.ProxyUsername
+		}
+		if opt.ProxyPassword != nil {
+			c.ProxyPassword = opt.ProxyPassword
+		}
 		if opt.ReadConcern != nil {
 			c.ReadConcern = opt.ReadConcern
 		}
@@ -700,6 +1300,9 @@ func MergeClientOptions(opts ...*ClientOptions) *ClientOptions {
 		if opt.ReplicaSet != nil {
 			c.ReplicaSet = opt.ReplicaSet
 		}
+		if opt.Resolver != nil {
+			c.Resolver = opt.Resolver
+		}
 		if opt.RetryWrites != nil {
 			c.RetryWrites = opt.RetryWrites
 		}
@@ -709,15 +1312,78 @@ func MergeClientOptions(opts ...*ClientOptions) *ClientOptions {
 		if opt.ServerSelectionTimeout != nil {
 			c.ServerSelectionTimeout = opt.ServerSelectionTimeout
 		}
+		if opt.ServerSelector != nil {
+			c.ServerSelector = opt.ServerSelector
+		}
+		if opt.ServerSelectionMonitor != nil {
+			c.ServerSelectionMonitor = opt.ServerSelectionMonitor
+		}
+		if opt.ServerMonitor != nil {
+			c.ServerMonitor = opt.ServerMonitor
+		}
+		if opt.SessionLeakThreshold != nil {
+			c.SessionLeakThreshold = opt.SessionLeakThreshold
+		}
+		if opt.SRVMaxHosts != nil {
+			c.SRVMaxHosts = opt.SRVMaxHosts
+		}
+		if opt.SRVPollingInterval != nil {
+			c.SRVPollingInterval = opt.SRVPollingInterval
+		}
+		if opt.SRVServiceName != nil {
+			c.SRVServiceName = opt.SRVServiceName
+		}
+		if opt.TopologyMonitor != nil {
+			c.TopologyMonitor = opt.TopologyMonitor
+		}
+		if opt.SlowOperationThreshold != nil {
+			c.SlowOperationThreshold = opt.SlowOperationThreshold
+		}
+		if opt.SlowOperationMonitor != nil {
+			c.SlowOperationMonitor = opt.SlowOperationMonitor
+		}
+		if opt.ProfilerLabels != nil {
+			c.ProfilerLabels = opt.ProfilerLabels
+		}
+		if opt.OperationProfilerLabels != nil {
+			c.OperationProfilerLabels = opt.OperationProfilerLabels
+		}
+		if opt.Tracer != nil {
+			c.Tracer = opt.Tracer
+		}
+		if opt.TransactionMonitor != nil {
+			c.TransactionMonitor = opt.TransactionMonitor
+		}
+		if opt.LoggerOptions != nil {
+			c.LoggerOptions = opt.LoggerOptions
+		}
+		if opt.WeightedServerSelection != nil {
+			c.WeightedServerSelection = opt.WeightedServerSelection
+		}
+		if opt.MongosSelection != nil {
+			c.MongosSelection = opt.MongosSelection
+		}
+		if opt.MongosRebalanceInterval != nil {
+			c.MongosRebalanceInterval = opt.MongosRebalanceInterval
+		}
 		if opt.Direct != nil {
 			c.Direct = opt.Direct
 		}
 		if opt.SocketTimeout != nil {
 			c.SocketTimeout = opt.SocketTimeout
 		}
+		if opt.TCPUserTimeout != nil {
+			c.TCPUserTimeout = opt.TCPUserTimeout
+		}
 		if opt.TLSConfig != nil {
 			c.TLSConfig = opt.TLSConfig
 		}
+		if opt.UnknownURIOptionPolicy != nil {
+			c.UnknownURIOptionPolicy = opt.UnknownURIOptionPolicy
+		}
+		if opt.unknownURIOptions != nil {
+			c.unknownURIOptions = opt.unknownURIOptions
+		}
 		if opt.WriteConcern != nil {
 			c.WriteConcern = opt.WriteConcern
 		}
@@ -742,6 +1408,19 @@ func MergeClientOptions(opts ...*ClientOptions) *ClientOptions {
 	return c
 }
 
+// Clone returns a deep copy of the ClientOptions, so that the copy can be modified without affecting the original. The
+// TLS configuration is cloned with tls.Config.Clone. Monitors, dialers, resolvers, registries, and read and write
+// concerns are shared with the copy.
+func (c *ClientOptions) Clone() *ClientOptions {
+	return cloneOptions(c).(*ClientOptions)
+}
+
+// Merge returns a copy of the ClientOptions that is updated with the options that are set in other, using the same
+// rules as MergeClientOptions.
+func (c *ClientOptions) Merge(other *ClientOptions) *ClientOptions {
+	return MergeClientOptions(c, other).Clone()
+}
+
 // addCACertFromFile adds a root CA certificate to the configuration given a path
 // to the containing file.
 func addCACertFromFile(cfg *tls.Config, file string) error {
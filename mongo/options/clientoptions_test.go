@@ -57,10 +57,32 @@ func TestClientOptions(t *testing.T) {
 			{"Compressors", (*ClientOptions).SetCompressors, []string{"zstd", "snappy", "zlib"}, "Compressors", true},
 			{"ConnectTimeout", (*ClientOptions).SetConnectTimeout, 5 * time.Second, "ConnectTimeout", true},
 			{"Dialer", (*ClientOptions).SetDialer, testDialer{Num: 12345}, "Dialer", true},
+			{"HeartbeatConnectTimeout", (*ClientOptions).SetHeartbeatConnectTimeout, 2 * time.Second, "HeartbeatConnectTimeout", true},
 			{"HeartbeatInterval", (*ClientOptions).SetHeartbeatInterval, 5 * time.Second, "HeartbeatInterval", true},
 			{"Hosts", (*ClientOptions).SetHosts, []string{"localhost:27017", "localhost:27018", "localhost:27019"}, "Hosts", true},
+			{"KeepAliveCount", (*ClientOptions).SetKeepAliveCount, 3, "KeepAliveCount", true},
+			{"KeepAliveInterval", (*ClientOptions).SetKeepAliveInterval, 10 * time.Second, "KeepAliveInterval", true},
+			{"LoadBalanced", (*ClientOptions).SetLoadBalanced, true, "LoadBalanced", true},
+			{"ProxyHost", (*ClientOptions).SetProxyHost, "proxy.example.com", "ProxyHost", true},
+			{"ProxyPort", (*ClientOptions).SetProxyPort, 1081, "ProxyPort", true},
+			{"ProxyUsername", (*ClientOptions).SetProxyUsername, "user", "ProxyUsername", true},
+			{"ProxyPassword", (*ClientOptions).SetProxyPassword, "pencil", "ProxyPassword", true},
 			{"LocalThreshold", (*ClientOptions).SetLocalThreshold, 5 * time.Second, "LocalThreshold", true},
 			{"MaxConnIdleTime", (*ClientOptions).SetMaxConnIdleTime, 5 * time.Second, "MaxConnIdleTime", true},
+			{"MinHeartbeatInterval", (*ClientOptions).SetMinHeartbeatInterval, 100 * time.Millisecond, "MinHeartbeatInterval", true},
+			{"ConnExpiryJitter", (*ClientOptions).SetConnExpiryJitter, time.Minute, "ConnExpiryJitter", true},
+			{"MaxConnecting", (*ClientOptions).SetMaxConnecting, uint64(5), "MaxConnecting", true},
+			{"MaxConnectionRate", (*ClientOptions).SetMaxConnectionRate, uint64(50), "MaxConnectionRate", true},
+			{"PoolClearBackoff", (*ClientOptions).SetPoolClearBackoff, 100 * time.Millisecond, "PoolClearBackoff", true},
+			{"MaxPoolClearBackoff", (*ClientOptions).SetMaxPoolClearBackoff, 5 * time.Second, "MaxPoolClearBackoff", true},
+			{"MaxOperationsPerServer", (*ClientOptions).SetMaxOperationsPerServer, uint64(10), "MaxOperationsPerServer", true},
+			{"OperationQueueTimeout", (*ClientOptions).SetOperationQueueTimeout, time.Second, "OperationQueueTimeout", true},
+			{"SRVMaxHosts", (*ClientOptions).SetSRVMaxHosts, 2, "SRVMaxHosts", true},
+			{"SRVPollingInterval", (*ClientOptions).SetSRVPollingInterval, 5 * time.Second, "SRVPollingInterval", true},
+			{"SRVServiceName", (*ClientOptions).SetSRVServiceName, "customname", "SRVServiceName", true},
+			{"ServerSelectionMonitor", (*ClientOptions).SetServerSelectionMonitor, &event.ServerSelectionMonitor{}, "ServerSelectionMonitor", false},
+			{"ServerMonitor", (*ClientOptions).SetServerMonitor, &event.ServerMonitor{}, "ServerMonitor", false},
+			{"TopologyMonitor", (*ClientOptions).SetTopologyMonitor, &event.TopologyMonitor{}, "TopologyMonitor", false},
 			{"MaxPoolSize", (*ClientOptions).SetMaxPoolSize, uint64(250), "MaxPoolSize", true},
 			{"MinPoolSize", (*ClientOptions).SetMinPoolSize, uint64(10), "MinPoolSize", true},
 			{"PoolMonitor", (*ClientOptions).SetPoolMonitor, &event.PoolMonitor{}, "PoolMonitor", false},
@@ -69,11 +91,22 @@ func TestClientOptions(t *testing.T) {
 			{"ReadPreference", (*ClientOptions).SetReadPreference, readpref.SecondaryPreferred(), "ReadPreference", false},
 			{"Registry", (*ClientOptions).SetRegistry, bson.NewRegistryBuilder().Build(), "Registry", false},
 			{"ReplicaSet", (*ClientOptions).SetReplicaSet, "example-replicaset", "ReplicaSet", true},
+			{"Resolver", (*ClientOptions).SetResolver, testResolver{Num: 12345}, "Resolver", true},
 			{"RetryWrites", (*ClientOptions).SetRetryWrites, true, "RetryWrites", true},
 			{"ServerSelectionTimeout", (*ClientOptions).SetServerSelectionTimeout, 5 * time.Second, "ServerSelectionTimeout", true},
+			{"WeightedServerSelection", (*ClientOptions).SetWeightedServerSelection, true, "WeightedServerSelection", true},
+			{"MongosSelection", (*ClientOptions).SetMongosSelection, "roundRobin", "MongosSelection", true},
+			{"MongosRebalanceInterval", (*ClientOptions).SetMongosRebalanceInterval, time.Minute, "MongosRebalanceInterval", true},
+			{"SlowOperationThreshold", (*ClientOptions).SetSlowOperationThreshold, time.Second, "SlowOperationThreshold", true},
+			{"SlowOperationMonitor", (*ClientOptions).SetSlowOperationMonitor, &event.SlowOperationMonitor{}, "SlowOperationMonitor", false},
 			{"Direct", (*ClientOptions).SetDirect, true, "Direct", true},
 			{"SocketTimeout", (*ClientOptions).SetSocketTimeout, 5 * time.Second, "SocketTimeout", true},
+			{"TCPUserTimeout", (*ClientOptions).SetTCPUserTimeout, 20 * time.Second, "TCPUserTimeout", true},
 			{"TLSConfig", (*ClientOptions).SetTLSConfig, &tls.Config{}, "TLSConfig", false},
+			{"LoggerOptions", (*ClientOptions).SetLoggerOptions, Logger().SetMaxDocumentLength(500), "LoggerOptions", false},
+			{"ProfilerLabels", (*ClientOptions).SetProfilerLabels, true, "ProfilerLabels", true},
+			{"OperationProfilerLabels", (*ClientOptions).SetOperationProfilerLabels, true, "OperationProfilerLabels", true},
+			{"Tracer", (*ClientOptions).SetTracer, testTracer{Name: "tracer"}, "Tracer", true},
 			{"WriteConcern", (*ClientOptions).SetWriteConcern, writeconcern.New(writeconcern.WMajority()), "WriteConcern", false},
 			{"ZlibLevel", (*ClientOptions).SetZlibLevel, 6, "ZlibLevel", true},
 		}
@@ -296,6 +329,17 @@ func TestClientOptions(t *testing.T) {
 				"mongodb://localhost:27017,localhost:27018,localhost:27019/",
 				baseClient().SetHosts([]string{"localhost:27017", "localhost:27018", "localhost:27019"}),
 			},
+			{
+				"LoadBalanced",
+				"mongodb://localhost/?loadBalanced=true",
+				baseClient().SetLoadBalanced(true),
+			},
+			{
+				"Proxy",
+				"mongodb://localhost/?proxyHost=proxy.example.com&proxyPort=1081&proxyUsername=user&proxyPassword=pencil",
+				baseClient().SetProxyHost("proxy.example.com").SetProxyPort(1081).
+					SetProxyUsername("user").SetProxyPassword("pencil"),
+			},
 			{
 				"LocalThreshold",
 				"mongodb://localhost/?localThresholdMS=200",
@@ -452,6 +496,114 @@ func TestClientOptions(t *testing.T) {
 			})
 		}
 	})
+	t.Run("ApplyURI/uses custom resolver for SRV lookups", func(t *testing.T) {
+		co := Client().SetResolver(testResolver{}).ApplyURI("mongodb+srv://test.example.com/")
+		if err := co.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"db1.example.com:27017", "db2.example.com:27018"}
+		if !cmp.Equal(co.Hosts, want) {
+			t.Errorf("hosts do not match. got %v; want %v", co.Hosts, want)
+		}
+		if co.ReplicaSet == nil || *co.ReplicaSet != "rs0" {
+			t.Errorf("expected replica set from TXT record to be applied, got %v", co.ReplicaSet)
+		}
+	})
+	t.Run("Clone", func(t *testing.T) {
+		orig := Client().
+			SetHosts([]string{"localhost:27017"}).
+			SetMaxPoolSize(10).
+			SetAuth(Credential{Username: "user", AuthMechanismProperties: map[string]string{"SERVICE_NAME": "mongodb"}}).
+			SetTLSConfig(&tls.Config{ServerName: "localhost"}).
+			SetWriteConcern(writeconcern.New(writeconcern.WMajority()))
+		cp := orig.Clone()
+		if !cmp.Equal(orig.Hosts, cp.Hosts) || *cp.MaxPoolSize != 10 || cp.Auth.Username != "user" ||
+			cp.TLSConfig.ServerName != "localhost" || cp.WriteConcern != orig.WriteConcern {
+			t.Fatalf("clone does not match the original: %+v", cp)
+		}
+
+		cp.Hosts[0] = "other:27017"
+		*cp.MaxPoolSize = 20
+		cp.Auth.AuthMechanismProperties["SERVICE_NAME"] = "other"
+		cp.TLSConfig.ServerName = "other"
+		if orig.Hosts[0] != "localhost:27017" || *orig.MaxPoolSize != 10 ||
+			orig.Auth.AuthMechanismProperties["SERVICE_NAME"] != "mongodb" || orig.TLSConfig.ServerName != "localhost" {
+			t.Errorf("modifying the clone modified the original: %+v", orig)
+		}
+		if (*ClientOptions)(nil).Clone() != nil {
+			t.Errorf("expected clone of nil options to be nil")
+		}
+	})
+	t.Run("Merge", func(t *testing.T) {
+		defaults := Client().SetAppName("defaults").SetMaxPoolSize(10)
+		merged := defaults.Merge(Client().SetMaxPoolSize(20))
+		if *merged.AppName != "defaults" || *merged.MaxPoolSize != 20 {
+			t.Errorf("unexpected merged options: app name %q, max pool size %d", *merged.AppName, *merged.MaxPoolSize)
+		}
+		*merged.AppName = "merged"
+		if *defaults.AppName != "defaults" || *defaults.MaxPoolSize != 10 {
+			t.Errorf("merging modified the receiver: %+v", defaults)
+		}
+
+		find := Find().SetLimit(5).SetSort(bson.D{{"a", 1}}).SetCollation(&Collation{Locale: "en"})
+		findMerged := find.Merge(Find().SetLimit(10))
+		findMerged.Collation.Locale = "fr"
+		if *findMerged.Limit != 10 || *find.Limit != 5 || find.Collation.Locale != "en" {
+			t.Errorf("unexpected merged find options: limit %d, original limit %d, original locale %q",
+				*findMerged.Limit, *find.Limit, find.Collation.Locale)
+		}
+	})
+	t.Run("unknown URI options", func(t *testing.T) {
+		uri := "mongodb://localhost/?retryWrite=true&appName=app&foo=1&foo=2"
+		want := map[string][]string{"retrywrite": {"true"}, "foo": {"1", "2"}}
+
+		opts := Client().ApplyURI(uri)
+		if err := opts.Validate(); err != nil {
+			t.Fatalf("unexpected error with the default policy: %v", err)
+		}
+		if got := opts.UnknownURIOptions(); !cmp.Equal(got, want) {
+			t.Errorf("unknown options mismatch; got %v, want %v", got, want)
+		}
+		opts.UnknownURIOptions()["foo"][0] = "modified"
+		if got := opts.UnknownURIOptions()["foo"][0]; got != "1" {
+			t.Errorf("modifying the returned map changed the options; got %q", got)
+		}
+
+		for _, policy := range []UnknownURIOptionPolicy{UnknownURIOptionIgnore, UnknownURIOptionWarn} {
+			if err := Client().SetUnknownURIOptionPolicy(policy).ApplyURI(uri).Validate(); err != nil {
+				t.Errorf("unexpected error with policy %q: %v", policy, err)
+			}
+		}
+
+		wantErr := "unknown URI options: foo, retrywrite"
+		err := Client().SetUnknownURIOptionPolicy(UnknownURIOptionError).ApplyURI(uri).Validate()
+		if err == nil || err.Error() != wantErr {
+			t.Errorf("expected error %q, got %v", wantErr, err)
+		}
+		err = Client().ApplyURI(uri).SetUnknownURIOptionPolicy(UnknownURIOptionError).Validate()
+		if err == nil || err.Error() != wantErr {
+			t.Errorf("expected error %q when the policy is set after ApplyURI, got %v", wantErr, err)
+		}
+		merged := MergeClientOptions(Client().ApplyURI(uri), Client().SetUnknownURIOptionPolicy(UnknownURIOptionError))
+		if err := merged.Validate(); err == nil || err.Error() != wantErr {
+			t.Errorf("expected error %q for merged options, got %v", wantErr, err)
+		}
+
+		err = Client().SetUnknownURIOptionPolicy("fail").Validate()
+		if err == nil || err.Error() != `invalid unknown URI option policy "fail"` {
+			t.Errorf("expected invalid policy error, got %v", err)
+		}
+	})
+	t.Run("SRV polling interval", func(t *testing.T) {
+		for _, interval := range []time.Duration{0, -time.Second} {
+			if err := Client().SetSRVPollingInterval(interval).Validate(); err == nil {
+				t.Errorf("expected error for SRV polling interval %v, got nil", interval)
+			}
+		}
+		if err := Client().SetSRVPollingInterval(time.Second).Validate(); err != nil {
+			t.Errorf("unexpected error for a positive SRV polling interval: %v", err)
+		}
+	})
 }
 
 type testDialer struct {
@@ -462,6 +614,33 @@ func (testDialer) DialContext(ctx context.Context, network, address string) (net
 	return nil, nil
 }
 
+type testResolver struct {
+	Num int
+}
+
+func (testResolver) LookupHost(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+
+func (testResolver) LookupSRV(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	return "", []*net.SRV{
+		{Target: "db1.example.com.", Port: 27017},
+		{Target: "db2.example.com.", Port: 27018},
+	}, nil
+}
+
+func (testResolver) LookupTXT(context.Context, string) ([]string, error) {
+	return []string{"replicaSet=rs0"}, nil
+}
+
+type testTracer struct {
+	Name string
+}
+
+func (testTracer) StartSpan(ctx context.Context, _ string, _ ...event.Span) (context.Context, event.Span) {
+	return ctx, nil
+}
+
 func compareTLSConfig(cfg1, cfg2 *tls.Config) bool {
 	if cfg1 == nil && cfg2 == nil {
 		return true
@@ -86,3 +86,15 @@ func MergeCollectionOptions(opts ...*CollectionOptions) *CollectionOptions {
 
 	return c
 }
+
+// Clone returns a deep copy of the CollectionOptions. Values that are stored as interfaces, such as documents, are
+// shared with the copy.
+func (c *CollectionOptions) Clone() *CollectionOptions {
+	return cloneOptions(c).(*CollectionOptions)
+}
+
+// Merge returns a copy of the CollectionOptions that is updated with the options that are set in other, using the same
+// rules as MergeCollectionOptions.
+func (c *CollectionOptions) Merge(other *CollectionOptions) *CollectionOptions {
+	return MergeCollectionOptions(c, other).Clone()
+}
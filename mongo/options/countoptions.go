@@ -92,3 +92,15 @@ func MergeCountOptions(opts ...*CountOptions) *CountOptions {
 
 	return countOpts
 }
+
+// Clone returns a deep copy of the CountOptions. Values that are stored as interfaces, such as documents, are shared
+// with the copy.
+func (co *CountOptions) Clone() *CountOptions {
+	return cloneOptions(co).(*CountOptions)
+}
+
+// Merge returns a copy of the CountOptions that is updated with the options that are set in other, using the same rules
+// as MergeCountOptions.
+func (co *CountOptions) Merge(other *CountOptions) *CountOptions {
+	return MergeCountOptions(co, other).Clone()
+}
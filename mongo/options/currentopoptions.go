@@ -0,0 +1,156 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import "time"
+
+// CurrentOpOptions represents options that can be used to configure a Client.CurrentOp operation. The filter fields
+// are combined, so an operation is only returned if it matches all of the fields that are set.
+type CurrentOpOptions struct {
+	// If true, operations of all users are returned. Otherwise, only the operations of the current user are
+	// returned. The default value is false.
+	AllUsers *bool
+
+	// If true, idle connections are returned in addition to active operations. The default value is false.
+	IdleConnections *bool
+
+	// If true, idle cursors are returned. The default value is false.
+	IdleCursors *bool
+
+	// If true, idle sessions are returned. The default value is true.
+	IdleSessions *bool
+
+	// If true and the client is connected to a mongos, the operations of the mongos itself are returned instead of
+	// the operations of the shards. The default value is false.
+	LocalOps *bool
+
+	// Only return operations that have been running for at least this long. The default value is 0, which returns
+	// operations regardless of their running time.
+	MinRunningTime *time.Duration
+
+	// Only return operations on this namespace, in the form "<database>.<collection>".
+	Namespace *string
+
+	// Only return operations of the client at this "host:port" address.
+	Client *string
+
+	// Only return operations of clients with this application name.
+	AppName *string
+
+	// An additional filter for the operations. It must be a document and is applied in a $match stage after the
+	// other filter fields.
+	Filter interface{}
+}
+
+// CurrentOp creates a new CurrentOpOptions instance.
+func CurrentOp() *CurrentOpOptions {
+	return &CurrentOpOptions{}
+}
+
+// SetAllUsers sets the value for the AllUsers field.
+func (c *CurrentOpOptions) SetAllUsers(b bool) *CurrentOpOptions {
+	c.AllUsers = &b
+	return c
+}
+
+// SetIdleConnections sets the value for the IdleConnections field.
+func (c *CurrentOpOptions) SetIdleConnections(b bool) *CurrentOpOptions {
+	c.IdleConnections = &b
+	return c
+}
+
+// SetIdleCursors sets the value for the IdleCursors field.
+func (c *CurrentOpOptions) SetIdleCursors(b bool) *CurrentOpOptions {
+	c.IdleCursors = &b
+	return c
+}
+
+// SetIdleSessions sets the value for the IdleSessions field.
+func (c *CurrentOpOptions) SetIdleSessions(b bool) *CurrentOpOptions {
+	c.IdleSessions = &b
+	return c
+}
+
+// SetLocalOps sets the value for the LocalOps field.
+func (c *CurrentOpOptions) SetLocalOps(b bool) *CurrentOpOptions {
+	c.LocalOps = &b
+	return c
+}
+
+// SetMinRunningTime sets the value for the MinRunningTime field.
+func (c *CurrentOpOptions) SetMinRunningTime(d time.Duration) *CurrentOpOptions {
+	c.MinRunningTime = &d
+	return c
+}
+
+// SetNamespace sets the value for the Namespace field.
+func (c *CurrentOpOptions) SetNamespace(ns string) *CurrentOpOptions {
+	c.Namespace = &ns
+	return c
+}
+
+// SetClient sets the value for the Client field.
+func (c *CurrentOpOptions) SetClient(addr string) *CurrentOpOptions {
+	c.Client = &addr
+	return c
+}
+
+// SetAppName sets the value for the AppName field.
+func (c *CurrentOpOptions) SetAppName(name string) *CurrentOpOptions {
+	c.AppName = &name
+	return c
+}
+
+// SetFilter sets the value for the Filter field.
+func (c *CurrentOpOptions) SetFilter(filter interface{}) *CurrentOpOptions {
+	c.Filter = filter
+	return c
+}
+
+// MergeCurrentOpOptions combines the given CurrentOpOptions instances into a single CurrentOpOptions in a
+// last-one-wins fashion.
+func MergeCurrentOpOptions(opts ...*CurrentOpOptions) *CurrentOpOptions {
+	c := CurrentOp()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+
+		if opt.AllUsers != nil {
+			c.AllUsers = opt.AllUsers
+		}
+		if opt.IdleConnections != nil {
+			c.IdleConnections = opt.IdleConnections
+		}
+		if opt.IdleCursors != nil {
+			c.IdleCursors = opt.IdleCursors
+		}
+		if opt.IdleSessions != nil {
+			c.IdleSessions = opt.IdleSessions
+		}
+		if opt.LocalOps != nil {
+			c.LocalOps = opt.LocalOps
+		}
+		if opt.MinRunningTime != nil {
+			c.MinRunningTime = opt.MinRunningTime
+		}
+		if opt.Namespace != nil {
+			c.Namespace = opt.Namespace
+		}
+		if opt.Client != nil {
+			c.Client = opt.Client
+		}
+		if opt.AppName != nil {
+			c.AppName = opt.AppName
+		}
+		if opt.Filter != nil {
+			c.Filter = opt.Filter
+		}
+	}
+
+	return c
+}
@@ -23,6 +23,15 @@ func DataKey() *DataKeyOptions {
 // {region: string, key: string}.
 //
 // If being used with a local KMS provider, this option is not applicable and should not be specified.
+//
+// If being used with the KMIP KMS provider, this option is optional and may be a document with the following format:
+// {keyId: string, endpoint: string}. If keyId is omitted, a new 96-byte secret is created on the KMIP server. If
+// endpoint is omitted, the endpoint of the KMS provider options is used.
+//
+// If being used with the Azure KMS provider, this option is required and must be a document with the following format:
+// {keyVaultEndpoint: string, keyName: string}. If being used with the GCP KMS provider, this option is required and
+// must be a document with the following format: {projectId: string, location: string, keyRing: string, keyName:
+// string}. Named providers, such as "aws:primary", take the master key of their provider type.
 func (dk *DataKeyOptions) SetMasterKey(masterKey interface{}) *DataKeyOptions {
 	dk.MasterKey = masterKey
 	return dk
@@ -86,3 +86,15 @@ func MergeDatabaseOptions(opts ...*DatabaseOptions) *DatabaseOptions {
 
 	return d
 }
+
+// Clone returns a deep copy of the DatabaseOptions. Values that are stored as interfaces, such as documents, are shared
+// with the copy.
+func (d *DatabaseOptions) Clone() *DatabaseOptions {
+	return cloneOptions(d).(*DatabaseOptions)
+}
+
+// Merge returns a copy of the DatabaseOptions that is updated with the options that are set in other, using the same
+// rules as MergeDatabaseOptions.
+func (d *DatabaseOptions) Merge(other *DatabaseOptions) *DatabaseOptions {
+	return MergeDatabaseOptions(d, other).Clone()
+}
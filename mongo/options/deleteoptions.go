@@ -39,3 +39,15 @@ func MergeDeleteOptions(opts ...*DeleteOptions) *DeleteOptions {
 
 	return dOpts
 }
+
+// Clone returns a deep copy of the DeleteOptions. Values that are stored as interfaces, such as documents, are shared
+// with the copy.
+func (do *DeleteOptions) Clone() *DeleteOptions {
+	return cloneOptions(do).(*DeleteOptions)
+}
+
+// Merge returns a copy of the DeleteOptions that is updated with the options that are set in other, using the same
+// rules as MergeDeleteOptions.
+func (do *DeleteOptions) Merge(other *DeleteOptions) *DeleteOptions {
+	return MergeDeleteOptions(do, other).Clone()
+}
@@ -55,3 +55,15 @@ func MergeDistinctOptions(opts ...*DistinctOptions) *DistinctOptions {
 
 	return distinctOpts
 }
+
+// Clone returns a deep copy of the DistinctOptions. Values that are stored as interfaces, such as documents, are shared
+// with the copy.
+func (do *DistinctOptions) Clone() *DistinctOptions {
+	return cloneOptions(do).(*DistinctOptions)
+}
+
+// Merge returns a copy of the DistinctOptions that is updated with the options that are set in other, using the same
+// rules as MergeDistinctOptions.
+func (do *DistinctOptions) Merge(other *DistinctOptions) *DistinctOptions {
+	return MergeDistinctOptions(do, other).Clone()
+}
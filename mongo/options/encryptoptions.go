@@ -7,14 +7,53 @@
 package options
 
 import (
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// These constants specify the algorithms of Queryable Encryption that can be used with EncryptOptions.SetAlgorithm.
+const (
+	// IndexedAlgorithm encrypts a value so that it can be queried for equality.
+	IndexedAlgorithm = "Indexed"
+	// UnindexedAlgorithm encrypts a value that cannot be queried.
+	UnindexedAlgorithm = "Unindexed"
+	// RangeAlgorithm encrypts a value so that it can be queried with range queries. It requires RangeOptions.
+	RangeAlgorithm = "Range"
+)
+
+// These constants specify the query types of Queryable Encryption that can be used with EncryptOptions.SetQueryType.
+const (
+	// QueryTypeEquality is used to encrypt a value for an equality query with the Indexed algorithm.
+	QueryTypeEquality = "equality"
+	// QueryTypeRange is used to encrypt an expression for a range query with the Range algorithm.
+	QueryTypeRange = "range"
+)
+
 // EncryptOptions represents options to explicitly encrypt a value.
 type EncryptOptions struct {
-	KeyID      *primitive.Binary
-	KeyAltName *string
-	Algorithm  string
+	KeyID            *primitive.Binary
+	KeyAltName       *string
+	Algorithm        string
+	QueryType        string
+	ContentionFactor *int64
+	RangeOptions     *RangeOptions
+}
+
+// RangeOptions specifies the options of the Range algorithm. They must match the options of the encrypted field in
+// the encryptedFields of the collection.
+type RangeOptions struct {
+	// The minimum value of the field. This is required if Precision is set.
+	Min *bson.RawValue
+	// The maximum value of the field. This is required if Precision is set.
+	Max *bson.RawValue
+	// The number of bits each edge of the range is split into. Higher values reduce the storage used by the index at
+	// the cost of query performance.
+	Sparsity *int64
+	// The number of digits after the decimal point that are kept for double and decimal128 values.
+	Precision *int32
+	// The number of top levels of the hypergraph of the range that are trimmed. Higher values reduce the storage used
+	// by the index at the cost of query performance.
+	TrimFactor *int32
 }
 
 // Encrypt creates a new EncryptOptions instance.
@@ -35,12 +74,63 @@ func (e *EncryptOptions) SetKeyAltName(keyAltName string) *EncryptOptions {
 }
 
 // SetAlgorithm specifies an algorithm to use for encryption. This should be AEAD_AES_256_CBC_HMAC_SHA_512-Deterministic
-// or AEAD_AES_256_CBC_HMAC_SHA_512-Random. This is required.
+// or AEAD_AES_256_CBC_HMAC_SHA_512-Random, or one of the Queryable Encryption algorithms IndexedAlgorithm,
+// UnindexedAlgorithm, and RangeAlgorithm. This is required.
 func (e *EncryptOptions) SetAlgorithm(algorithm string) *EncryptOptions {
 	e.Algorithm = algorithm
 	return e
 }
 
+// SetQueryType specifies the query type the encrypted value or expression is used for. This should be
+// QueryTypeEquality or QueryTypeRange, and is only allowed with the IndexedAlgorithm and RangeAlgorithm algorithms.
+func (e *EncryptOptions) SetQueryType(queryType string) *EncryptOptions {
+	e.QueryType = queryType
+	return e
+}
+
+// SetContentionFactor specifies the contention factor of the encrypted field. This is only allowed with the
+// IndexedAlgorithm and RangeAlgorithm algorithms.
+func (e *EncryptOptions) SetContentionFactor(contentionFactor int64) *EncryptOptions {
+	e.ContentionFactor = &contentionFactor
+	return e
+}
+
+// SetRangeOptions specifies the options of the RangeAlgorithm algorithm. This is required with RangeAlgorithm.
+func (e *EncryptOptions) SetRangeOptions(ro RangeOptions) *EncryptOptions {
+	e.RangeOptions = &ro
+	return e
+}
+
+// SetMin sets the value for the Min field.
+func (ro *RangeOptions) SetMin(min bson.RawValue) *RangeOptions {
+	ro.Min = &min
+	return ro
+}
+
+// SetMax sets the value for the Max field.
+func (ro *RangeOptions) SetMax(max bson.RawValue) *RangeOptions {
+	ro.Max = &max
+	return ro
+}
+
+// SetSparsity sets the value for the Sparsity field.
+func (ro *RangeOptions) SetSparsity(sparsity int64) *RangeOptions {
+	ro.Sparsity = &sparsity
+	return ro
+}
+
+// SetPrecision sets the value for the Precision field.
+func (ro *RangeOptions) SetPrecision(precision int32) *RangeOptions {
+	ro.Precision = &precision
+	return ro
+}
+
+// SetTrimFactor sets the value for the TrimFactor field.
+func (ro *RangeOptions) SetTrimFactor(trimFactor int32) *RangeOptions {
+	ro.TrimFactor = &trimFactor
+	return ro
+}
+
 // MergeEncryptOptions combines the argued EncryptOptions in a last-one wins fashion.
 func MergeEncryptOptions(opts ...*EncryptOptions) *EncryptOptions {
 	eo := Encrypt()
@@ -58,6 +148,15 @@ func MergeEncryptOptions(opts ...*EncryptOptions) *EncryptOptions {
 		if opt.Algorithm != "" {
 			eo.Algorithm = opt.Algorithm
 		}
+		if opt.QueryType != "" {
+			eo.QueryType = opt.QueryType
+		}
+		if opt.ContentionFactor != nil {
+			eo.ContentionFactor = opt.ContentionFactor
+		}
+		if opt.RangeOptions != nil {
+			eo.RangeOptions = opt.RangeOptions
+		}
 	}
 
 	return eo
@@ -42,3 +42,15 @@ func MergeEstimatedDocumentCountOptions(opts ...*EstimatedDocumentCountOptions)
 
 	return e
 }
+
+// Clone returns a deep copy of the EstimatedDocumentCountOptions. Values that are stored as interfaces, such as
+// documents, are shared with the copy.
+func (eco *EstimatedDocumentCountOptions) Clone() *EstimatedDocumentCountOptions {
+	return cloneOptions(eco).(*EstimatedDocumentCountOptions)
+}
+
+// Merge returns a copy of the EstimatedDocumentCountOptions that is updated with the options that are set in other,
+// using the same rules as MergeEstimatedDocumentCountOptions.
+func (eco *EstimatedDocumentCountOptions) Merge(other *EstimatedDocumentCountOptions) *EstimatedDocumentCountOptions {
+	return MergeEstimatedDocumentCountOptions(eco, other).Clone()
+}
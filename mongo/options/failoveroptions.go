@@ -0,0 +1,98 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// FailoverOptions represents options that can be used to configure a failover.Client.
+type FailoverOptions struct {
+	// The interval between health checks of each cluster. The default value is 10 seconds.
+	HealthCheckInterval *time.Duration
+
+	// The maximum amount of time a single health check can take. The default value is 5 seconds.
+	HealthCheckTimeout *time.Duration
+
+	// The number of consecutive failed health checks after which a cluster is considered unhealthy. The default value
+	// is 3.
+	FailureThreshold *int
+
+	// If true, operations are moved back to the most preferred healthy cluster once it becomes healthy again. If
+	// false, operations stay on the cluster that was failed over to until it becomes unhealthy. The default value is
+	// false.
+	FailBack *bool
+
+	// The monitor used to report failover events.
+	Monitor *event.FailoverMonitor
+}
+
+// Failover creates a new FailoverOptions instance.
+func Failover() *FailoverOptions {
+	return &FailoverOptions{}
+}
+
+// SetHealthCheckInterval sets the value for the HealthCheckInterval field.
+func (f *FailoverOptions) SetHealthCheckInterval(d time.Duration) *FailoverOptions {
+	f.HealthCheckInterval = &d
+	return f
+}
+
+// SetHealthCheckTimeout sets the value for the HealthCheckTimeout field.
+func (f *FailoverOptions) SetHealthCheckTimeout(d time.Duration) *FailoverOptions {
+	f.HealthCheckTimeout = &d
+	return f
+}
+
+// SetFailureThreshold sets the value for the FailureThreshold field.
+func (f *FailoverOptions) SetFailureThreshold(n int) *FailoverOptions {
+	f.FailureThreshold = &n
+	return f
+}
+
+// SetFailBack sets the value for the FailBack field.
+func (f *FailoverOptions) SetFailBack(b bool) *FailoverOptions {
+	f.FailBack = &b
+	return f
+}
+
+// SetMonitor sets the value for the Monitor field.
+func (f *FailoverOptions) SetMonitor(m *event.FailoverMonitor) *FailoverOptions {
+	f.Monitor = m
+	return f
+}
+
+// MergeFailoverOptions combines the given FailoverOptions instances into a single FailoverOptions in a last-one-wins
+// fashion.
+func MergeFailoverOptions(opts ...*FailoverOptions) *FailoverOptions {
+	f := Failover()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+
+		if opt.HealthCheckInterval != nil {
+			f.HealthCheckInterval = opt.HealthCheckInterval
+		}
+		if opt.HealthCheckTimeout != nil {
+			f.HealthCheckTimeout = opt.HealthCheckTimeout
+		}
+		if opt.FailureThreshold != nil {
+			f.FailureThreshold = opt.FailureThreshold
+		}
+		if opt.FailBack != nil {
+			f.FailBack = opt.FailBack
+		}
+		if opt.Monitor != nil {
+			f.Monitor = opt.Monitor
+		}
+	}
+
+	return f
+}
@@ -37,6 +37,12 @@ type FindOptions struct {
 	// that the cursor will be closed by the server when the last batch of documents is retrieved.
 	CursorType *CursorType
 
+	// If true, the server will stream the batches after the first one on a single connection instead of waiting for a
+	// getMore for each batch. The connection is dedicated to the cursor until the server has sent the last batch or the
+	// cursor is closed. This option is only valid for MongoDB versions >= 4.2 and is ignored for previous server
+	// versions and for tailable cursors. The default value is false.
+	Exhaust *bool
+
 	// The index to use for the operation. This should either be the index name as a string or the index specification
 	// as a document. The default value is nil, which means that no hint will be sent.
 	Hint interface{}
@@ -75,6 +81,13 @@ type FindOptions struct {
 	// is nil, which means all fields will be included.
 	Projection interface{}
 
+	// The number of batches to fetch from the server in the background while the application is still iterating the
+	// current batch. Prefetching overlaps network time with processing for large scans at the cost of holding up to
+	// PrefetchDepth extra batches in memory. The default value is 0, which means that batches are only fetched when
+	// the current batch has been exhausted. This option is ignored for tailable cursors and for operations executed
+	// with an explicit session.
+	PrefetchDepth *int32
+
 	// If true, the documents returned by the operation will only contain fields corresponding to the index used. The
 	// default value is false.
 	ReturnKey *bool
@@ -135,6 +148,12 @@ func (f *FindOptions) SetCursorType(ct CursorType) *FindOptions {
 	return f
 }
 
+// SetExhaust sets the value for the Exhaust field.
+func (f *FindOptions) SetExhaust(b bool) *FindOptions {
+	f.Exhaust = &b
+	return f
+}
+
 // SetHint sets the value for the Hint field.
 func (f *FindOptions) SetHint(hint interface{}) *FindOptions {
 	f.Hint = hint
@@ -189,6 +208,12 @@ func (f *FindOptions) SetProjection(projection interface{}) *FindOptions {
 	return f
 }
 
+// SetPrefetchDepth sets the value for the PrefetchDepth field.
+func (f *FindOptions) SetPrefetchDepth(i int32) *FindOptions {
+	f.PrefetchDepth = &i
+	return f
+}
+
 // SetReturnKey sets the value for the ReturnKey field.
 func (f *FindOptions) SetReturnKey(b bool) *FindOptions {
 	f.ReturnKey = &b
@@ -244,6 +269,9 @@ func MergeFindOptions(opts ...*FindOptions) *FindOptions {
 		if opt.CursorType != nil {
 			fo.CursorType = opt.CursorType
 		}
+		if opt.Exhaust != nil {
+			fo.Exhaust = opt.Exhaust
+		}
 		if opt.Hint != nil {
 			fo.Hint = opt.Hint
 		}
@@ -271,6 +299,9 @@ func MergeFindOptions(opts ...*FindOptions) *FindOptions {
 		if opt.Projection != nil {
 			fo.Projection = opt.Projection
 		}
+		if opt.PrefetchDepth != nil {
+			fo.PrefetchDepth = opt.PrefetchDepth
+		}
 		if opt.ReturnKey != nil {
 			fo.ReturnKey = opt.ReturnKey
 		}
@@ -291,6 +322,18 @@ func MergeFindOptions(opts ...*FindOptions) *FindOptions {
 	return fo
 }
 
+// Clone returns a deep copy of the FindOptions. Values that are stored as interfaces, such as documents, are shared
+// with the copy.
+func (f *FindOptions) Clone() *FindOptions {
+	return cloneOptions(f).(*FindOptions)
+}
+
+// Merge returns a copy of the FindOptions that is updated with the options that are set in other, using the same rules
+// as MergeFindOptions.
+func (f *FindOptions) Merge(other *FindOptions) *FindOptions {
+	return MergeFindOptions(f, other).Clone()
+}
+
 // FindOneOptions represents options that can be used to configure a FindOne operation.
 type FindOneOptions struct {
 	// If true, an operation on a sharded cluster can return partial results if some shards are down rather than
@@ -545,6 +588,18 @@ func MergeFindOneOptions(opts ...*FindOneOptions) *FindOneOptions {
 	return fo
 }
 
+// Clone returns a deep copy of the FindOneOptions. Values that are stored as interfaces, such as documents, are shared
+// with the copy.
+func (f *FindOneOptions) Clone() *FindOneOptions {
+	return cloneOptions(f).(*FindOneOptions)
+}
+
+// Merge returns a copy of the FindOneOptions that is updated with the options that are set in other, using the same
+// rules as MergeFindOneOptions.
+func (f *FindOneOptions) Merge(other *FindOneOptions) *FindOneOptions {
+	return MergeFindOneOptions(f, other).Clone()
+}
+
 // FindOneAndReplaceOptions represents options that can be used to configure a FindOneAndReplace instance.
 type FindOneAndReplaceOptions struct {
 	// If true, writes executed as part of the operation will opt out of document-level validation on the server. This
@@ -676,6 +731,18 @@ func MergeFindOneAndReplaceOptions(opts ...*FindOneAndReplaceOptions) *FindOneAn
 	return fo
 }
 
+// Clone returns a deep copy of the FindOneAndReplaceOptions. Values that are stored as interfaces, such as documents,
+// are shared with the copy.
+func (f *FindOneAndReplaceOptions) Clone() *FindOneAndReplaceOptions {
+	return cloneOptions(f).(*FindOneAndReplaceOptions)
+}
+
+// Merge returns a copy of the FindOneAndReplaceOptions that is updated with the options that are set in other, using
+// the same rules as MergeFindOneAndReplaceOptions.
+func (f *FindOneAndReplaceOptions) Merge(other *FindOneAndReplaceOptions) *FindOneAndReplaceOptions {
+	return MergeFindOneAndReplaceOptions(f, other).Clone()
+}
+
 // FindOneAndUpdateOptions represents options that can be used to configure a FindOneAndUpdate options.
 type FindOneAndUpdateOptions struct {
 	// A set of filters specifying to which array elements an update should apply. This option is only valid for MongoDB
@@ -821,6 +888,18 @@ func MergeFindOneAndUpdateOptions(opts ...*FindOneAndUpdateOptions) *FindOneAndU
 	return fo
 }
 
+// Clone returns a deep copy of the FindOneAndUpdateOptions. Values that are stored as interfaces, such as documents,
+// are shared with the copy.
+func (f *FindOneAndUpdateOptions) Clone() *FindOneAndUpdateOptions {
+	return cloneOptions(f).(*FindOneAndUpdateOptions)
+}
+
+// Merge returns a copy of the FindOneAndUpdateOptions that is updated with the options that are set in other, using the
+// same rules as MergeFindOneAndUpdateOptions.
+func (f *FindOneAndUpdateOptions) Merge(other *FindOneAndUpdateOptions) *FindOneAndUpdateOptions {
+	return MergeFindOneAndUpdateOptions(f, other).Clone()
+}
+
 // FindOneAndDeleteOptions represents options that can be used to configure a FindOneAndDelete operation.
 type FindOneAndDeleteOptions struct {
 	// Specifies a collation to use for string comparisons during the operation. This option is only valid for MongoDB
@@ -895,3 +974,15 @@ func MergeFindOneAndDeleteOptions(opts ...*FindOneAndDeleteOptions) *FindOneAndD
 
 	return fo
 }
+
+// Clone returns a deep copy of the FindOneAndDeleteOptions. Values that are stored as interfaces, such as documents,
+// are shared with the copy.
+func (f *FindOneAndDeleteOptions) Clone() *FindOneAndDeleteOptions {
+	return cloneOptions(f).(*FindOneAndDeleteOptions)
+}
+
+// Merge returns a copy of the FindOneAndDeleteOptions that is updated with the options that are set in other, using the
+// same rules as MergeFindOneAndDeleteOptions.
+func (f *FindOneAndDeleteOptions) Merge(other *FindOneAndDeleteOptions) *FindOneAndDeleteOptions {
+	return MergeFindOneAndDeleteOptions(f, other).Clone()
+}
@@ -44,6 +44,29 @@ type BucketOptions struct {
 	// The read preference for the bucket. The default value is the read preference of the database from which the
 	// bucket is created.
 	ReadPreference *readpref.ReadPref
+
+	// The maximum number of concurrent insert operations used to write the chunks of a file. Chunks are buffered and
+	// written in batches of UploadBufferSize bytes, and each batch is split between this many inserts. The default
+	// value is 1, which means that chunks are written by a single insert at a time.
+	UploadConcurrency *int
+
+	// The maximum number of concurrent find operations used to read ahead the chunks of a file from a download
+	// stream. Chunks are still returned by the stream in order. Each find operation reads up to 4 MiB of chunks, so a
+	// stream can buffer up to this many times 4 MiB of the file. The default value is 1, which means that chunks are
+	// read one batch at a time as the stream is read.
+	DownloadConcurrency *int
+
+	// The name of the algorithm used to compute a checksum of each uploaded file, such as "sha256" or "sha512". Other
+	// algorithms can be registered with gridfs.RegisterChecksumAlgorithm. The checksum is stored in the "checksum"
+	// field of the document in the files collection and is verified when the file is downloaded. The default value is
+	// nil, which means that no checksum is computed.
+	ChecksumAlgorithm *string
+
+	// The codec used to compress the data of each chunk of uploaded files, either "zstd" or "gzip". The codec is
+	// stored in the "compression" field of the document in the files collection, and chunks are decompressed when the
+	// file is downloaded. Compressed files can only be read by clients that support chunk compression. The default
+	// value is nil, which means that chunks are not compressed.
+	ChunkCompression *string
 }
 
 // GridFSBucket creates a new BucketOptions instance.
@@ -84,6 +107,30 @@ func (b *BucketOptions) SetReadPreference(rp *readpref.ReadPref) *BucketOptions
 	return b
 }
 
+// SetUploadConcurrency sets the value for the UploadConcurrency field.
+func (b *BucketOptions) SetUploadConcurrency(n int) *BucketOptions {
+	b.UploadConcurrency = &n
+	return b
+}
+
+// SetDownloadConcurrency sets the value for the DownloadConcurrency field.
+func (b *BucketOptions) SetDownloadConcurrency(n int) *BucketOptions {
+	b.DownloadConcurrency = &n
+	return b
+}
+
+// SetChecksumAlgorithm sets the value for the ChecksumAlgorithm field.
+func (b *BucketOptions) SetChecksumAlgorithm(name string) *BucketOptions {
+	b.ChecksumAlgorithm = &name
+	return b
+}
+
+// SetChunkCompression sets the value for the ChunkCompression field.
+func (b *BucketOptions) SetChunkCompression(codec string) *BucketOptions {
+	b.ChunkCompression = &codec
+	return b
+}
+
 // MergeBucketOptions combines the given BucketOptions instances into a single BucketOptions in a last-one-wins fashion.
 func MergeBucketOptions(opts ...*BucketOptions) *BucketOptions {
 	b := GridFSBucket()
@@ -107,6 +154,18 @@ func MergeBucketOptions(opts ...*BucketOptions) *BucketOptions {
 		if opt.ReadPreference != nil {
 			b.ReadPreference = opt.ReadPreference
 		}
+		if opt.UploadConcurrency != nil {
+			b.UploadConcurrency = opt.UploadConcurrency
+		}
+		if opt.DownloadConcurrency != nil {
+			b.DownloadConcurrency = opt.DownloadConcurrency
+		}
+		if opt.ChecksumAlgorithm != nil {
+			b.ChecksumAlgorithm = opt.ChecksumAlgorithm
+		}
+		if opt.ChunkCompression != nil {
+			b.ChunkCompression = opt.ChunkCompression
+		}
 	}
 
 	return b
@@ -124,6 +183,14 @@ type UploadOptions struct {
 
 	// The BSON registry to use for converting filters to BSON documents. The default value is bson.DefaultRegistry.
 	Registry *bsoncodec.Registry
+
+	// The name of the algorithm used to compute a checksum of the file. An empty string means that no checksum is
+	// computed. The default value is the ChecksumAlgorithm of the bucket.
+	ChecksumAlgorithm *string
+
+	// The codec used to compress the data of each chunk of the file. An empty string means that chunks are not
+	// compressed. The default value is the ChunkCompression of the bucket.
+	ChunkCompression *string
 }
 
 // GridFSUpload creates a new UploadOptions instance.
@@ -143,6 +210,18 @@ func (u *UploadOptions) SetMetadata(doc interface{}) *UploadOptions {
 	return u
 }
 
+// SetChecksumAlgorithm sets the value for the ChecksumAlgorithm field.
+func (u *UploadOptions) SetChecksumAlgorithm(name string) *UploadOptions {
+	u.ChecksumAlgorithm = &name
+	return u
+}
+
+// SetChunkCompression sets the value for the ChunkCompression field.
+func (u *UploadOptions) SetChunkCompression(codec string) *UploadOptions {
+	u.ChunkCompression = &codec
+	return u
+}
+
 // MergeUploadOptions combines the given UploadOptions instances into a single UploadOptions in a last-one-wins fashion.
 func MergeUploadOptions(opts ...*UploadOptions) *UploadOptions {
 	u := GridFSUpload()
@@ -160,6 +239,12 @@ func MergeUploadOptions(opts ...*UploadOptions) *UploadOptions {
 		if opt.Registry != nil {
 			u.Registry = opt.Registry
 		}
+		if opt.ChecksumAlgorithm != nil {
+			u.ChecksumAlgorithm = opt.ChecksumAlgorithm
+		}
+		if opt.ChunkCompression != nil {
+			u.ChunkCompression = opt.ChunkCompression
+		}
 	}
 
 	return u
@@ -318,3 +403,67 @@ func MergeGridFSFindOptions(opts ...*GridFSFindOptions) *GridFSFindOptions {
 
 	return fo
 }
+
+// GridFSCleanupOptions represents options that can be used to configure a GridFS Cleanup operation.
+type GridFSCleanupOptions struct {
+	// If true, orphaned chunks and incomplete files are deleted. The default value is false, which means that they are
+	// only reported.
+	Delete *bool
+
+	// The maximum number of files whose chunks or documents are deleted by each delete operation. The default value is
+	// 1000.
+	BatchSize *int32
+
+	// The minimum age of orphaned chunks. Chunks are written before the document in the files collection, so the
+	// chunks of files that are being uploaded, and of paused uploads that can be continued with
+	// Bucket.ResumeUploadStream, look orphaned. The chunks of a file are only reported if the newest of them was
+	// written at least this long ago. This should be set to more than the duration of the longest upload, including
+	// the time that uploads are paused. The default value is 24 hours. A value of 0 reports all orphaned chunks
+	// regardless of their age.
+	MinChunkAge *time.Duration
+}
+
+// GridFSCleanup creates a new GridFSCleanupOptions instance.
+func GridFSCleanup() *GridFSCleanupOptions {
+	return &GridFSCleanupOptions{}
+}
+
+// SetDelete sets the value for the Delete field.
+func (c *GridFSCleanupOptions) SetDelete(b bool) *GridFSCleanupOptions {
+	c.Delete = &b
+	return c
+}
+
+// SetBatchSize sets the value for the BatchSize field.
+func (c *GridFSCleanupOptions) SetBatchSize(i int32) *GridFSCleanupOptions {
+	c.BatchSize = &i
+	return c
+}
+
+// SetMinChunkAge sets the value for the MinChunkAge field.
+func (c *GridFSCleanupOptions) SetMinChunkAge(d time.Duration) *GridFSCleanupOptions {
+	c.MinChunkAge = &d
+	return c
+}
+
+// MergeGridFSCleanupOptions combines the given GridFSCleanupOptions instances into a single GridFSCleanupOptions in a
+// last-one-wins fashion.
+func MergeGridFSCleanupOptions(opts ...*GridFSCleanupOptions) *GridFSCleanupOptions {
+	c := GridFSCleanup()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.Delete != nil {
+			c.Delete = opt.Delete
+		}
+		if opt.BatchSize != nil {
+			c.BatchSize = opt.BatchSize
+		}
+		if opt.MinChunkAge != nil {
+			c.MinChunkAge = opt.MinChunkAge
+		}
+	}
+
+	return c
+}
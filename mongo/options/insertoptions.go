@@ -42,6 +42,18 @@ func MergeInsertOneOptions(opts ...*InsertOneOptions) *InsertOneOptions {
 	return ioOpts
 }
 
+// Clone returns a deep copy of the InsertOneOptions. Values that are stored as interfaces, such as documents, are
+// shared with the copy.
+func (ioo *InsertOneOptions) Clone() *InsertOneOptions {
+	return cloneOptions(ioo).(*InsertOneOptions)
+}
+
+// Merge returns a copy of the InsertOneOptions that is updated with the options that are set in other, using the same
+// rules as MergeInsertOneOptions.
+func (ioo *InsertOneOptions) Merge(other *InsertOneOptions) *InsertOneOptions {
+	return MergeInsertOneOptions(ioo, other).Clone()
+}
+
 // InsertManyOptions represents options that can be used to configure an InsertMany operation.
 type InsertManyOptions struct {
 	// If true, writes executed as part of the operation will opt out of document-level validation on the server. This
@@ -91,3 +103,15 @@ func MergeInsertManyOptions(opts ...*InsertManyOptions) *InsertManyOptions {
 
 	return imOpts
 }
+
+// Clone returns a deep copy of the InsertManyOptions. Values that are stored as interfaces, such as documents, are
+// shared with the copy.
+func (imo *InsertManyOptions) Clone() *InsertManyOptions {
+	return cloneOptions(imo).(*InsertManyOptions)
+}
+
+// Merge returns a copy of the InsertManyOptions that is updated with the options that are set in other, using the same
+// rules as MergeInsertManyOptions.
+func (imo *InsertManyOptions) Merge(other *InsertManyOptions) *InsertManyOptions {
+	return MergeInsertManyOptions(imo, other).Clone()
+}
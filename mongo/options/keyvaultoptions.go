@@ -0,0 +1,118 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"time"
+)
+
+// ListKeysOptions represents all possible options used to list the data keys of a key vault collection.
+type ListKeysOptions struct {
+	// If set, only the data keys with this alternate name are listed.
+	KeyAltName *string
+
+	// If set, only the data keys created at or after this time are listed.
+	CreatedAfter *time.Time
+
+	// If set, only the data keys created before this time are listed.
+	CreatedBefore *time.Time
+}
+
+// ListKeys creates a new ListKeysOptions instance.
+func ListKeys() *ListKeysOptions {
+	return &ListKeysOptions{}
+}
+
+// SetKeyAltName sets the value for the KeyAltName field.
+func (lko *ListKeysOptions) SetKeyAltName(keyAltName string) *ListKeysOptions {
+	lko.KeyAltName = &keyAltName
+	return lko
+}
+
+// SetCreatedAfter sets the value for the CreatedAfter field.
+func (lko *ListKeysOptions) SetCreatedAfter(t time.Time) *ListKeysOptions {
+	lko.CreatedAfter = &t
+	return lko
+}
+
+// SetCreatedBefore sets the value for the CreatedBefore field.
+func (lko *ListKeysOptions) SetCreatedBefore(t time.Time) *ListKeysOptions {
+	lko.CreatedBefore = &t
+	return lko
+}
+
+// MergeListKeysOptions combines the given ListKeysOptions instances into a single ListKeysOptions in a last-one-wins
+// fashion.
+func MergeListKeysOptions(opts ...*ListKeysOptions) *ListKeysOptions {
+	lko := ListKeys()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+
+		if opt.KeyAltName != nil {
+			lko.KeyAltName = opt.KeyAltName
+		}
+		if opt.CreatedAfter != nil {
+			lko.CreatedAfter = opt.CreatedAfter
+		}
+		if opt.CreatedBefore != nil {
+			lko.CreatedBefore = opt.CreatedBefore
+		}
+	}
+
+	return lko
+}
+
+// PurgeUnusedKeysOptions represents all possible options used to delete the data keys that are not used by any
+// collection.
+type PurgeUnusedKeysOptions struct {
+	// The minimum age of the data keys that are deleted. Data keys created more recently are kept, because they may
+	// not be referenced by a collection yet. The default value is 24 hours.
+	MinAge *time.Duration
+
+	// If true, the unused data keys are returned but not deleted. The default value is true, so DryRun must be set
+	// to false explicitly to delete data keys.
+	DryRun *bool
+}
+
+// PurgeUnusedKeys creates a new PurgeUnusedKeysOptions instance.
+func PurgeUnusedKeys() *PurgeUnusedKeysOptions {
+	return &PurgeUnusedKeysOptions{}
+}
+
+// SetMinAge sets the value for the MinAge field.
+func (puko *PurgeUnusedKeysOptions) SetMinAge(d time.Duration) *PurgeUnusedKeysOptions {
+	puko.MinAge = &d
+	return puko
+}
+
+// SetDryRun sets the value for the DryRun field.
+func (puko *PurgeUnusedKeysOptions) SetDryRun(dryRun bool) *PurgeUnusedKeysOptions {
+	puko.DryRun = &dryRun
+	return puko
+}
+
+// MergePurgeUnusedKeysOptions combines the given PurgeUnusedKeysOptions instances into a single
+// PurgeUnusedKeysOptions in a last-one-wins fashion.
+func MergePurgeUnusedKeysOptions(opts ...*PurgeUnusedKeysOptions) *PurgeUnusedKeysOptions {
+	puko := PurgeUnusedKeys()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+
+		if opt.MinAge != nil {
+			puko.MinAge = opt.MinAge
+		}
+		if opt.DryRun != nil {
+			puko.DryRun = opt.DryRun
+		}
+	}
+
+	return puko
+}
@@ -0,0 +1,92 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import "go.mongodb.org/mongo-driver/internal/logger"
+
+// LogLevel is the severity of a log message.
+type LogLevel int
+
+// Log levels. A component configured at LogLevelDebug also logs the messages of LogLevelInfo.
+const (
+	LogLevelOff   = LogLevel(logger.LevelOff)
+	LogLevelInfo  = LogLevel(logger.LevelInfo)
+	LogLevelDebug = LogLevel(logger.LevelDebug)
+)
+
+// LogComponent is a part of the driver that produces log messages.
+type LogComponent int
+
+// Log components.
+const (
+	// LogComponentAll configures the level of every component.
+	LogComponentAll = LogComponent(logger.ComponentAll)
+	// LogComponentCommand logs commands that are started, succeed, and fail.
+	LogComponentCommand = LogComponent(logger.ComponentCommand)
+	// LogComponentTopology logs changes to the servers in a deployment.
+	LogComponentTopology = LogComponent(logger.ComponentTopology)
+	// LogComponentServerSelection logs the selection of servers for operations.
+	LogComponentServerSelection = LogComponent(logger.ComponentServerSelection)
+	// LogComponentConnection logs connection pool and connection events.
+	LogComponentConnection = LogComponent(logger.ComponentConnection)
+	// LogComponentClient logs warnings about the use of a Client, such as unknown URI options with the
+	// UnknownURIOptionWarn policy, and sessions and cursors that are not ended or closed within the thresholds set
+	// through ClientOptions.SetSessionLeakThreshold and SetCursorLeakThreshold. These messages are logged at
+	// LogLevelInfo.
+	LogComponentClient = LogComponent(logger.ComponentClient)
+)
+
+// LogSink receives the log messages of a Client. The level is the LogLevel of the message, and keysAndValues
+// alternate between string keys and their values. Any logr.LogSink can be used as a LogSink.
+type LogSink interface {
+	Info(level int, message string, keysAndValues ...interface{})
+}
+
+// LoggerOptions represents options used to configure the logging of driver internals. Options that are not set can
+// be configured through the environment variables described by the MongoDB logging specification: MONGODB_LOG_ALL,
+// MONGODB_LOG_COMMAND, MONGODB_LOG_TOPOLOGY, MONGODB_LOG_SERVER_SELECTION, MONGODB_LOG_CONNECTION, MONGODB_LOG_CLIENT,
+// MONGODB_LOG_PATH, and MONGODB_LOG_MAX_DOCUMENT_LENGTH. Each level variable is set to a level name such as "info" or
+// "debug", and MONGODB_LOG_PATH to "stdout", "stderr", or the path of a file.
+type LoggerOptions struct {
+	// The level of each component. Components that are not set use the level of LogComponentAll, if it is set, or the
+	// level from the environment. The default is LogLevelOff for every component.
+	ComponentLevels map[LogComponent]LogLevel
+
+	// The sink that receives log messages. The default writes messages as JSON lines to the destination given by
+	// MONGODB_LOG_PATH, or to stderr.
+	Sink LogSink
+
+	// The length at which extended JSON documents in log messages, such as commands and replies, are truncated. The
+	// default value is 1000.
+	MaxDocumentLength uint
+}
+
+// Logger creates a new LoggerOptions instance.
+func Logger() *LoggerOptions {
+	return &LoggerOptions{}
+}
+
+// SetComponentLevel sets the level of a component.
+func (l *LoggerOptions) SetComponentLevel(component LogComponent, level LogLevel) *LoggerOptions {
+	if l.ComponentLevels == nil {
+		l.ComponentLevels = make(map[LogComponent]LogLevel)
+	}
+	l.ComponentLevels[component] = level
+	return l
+}
+
+// SetSink sets the value for the Sink field.
+func (l *LoggerOptions) SetSink(sink LogSink) *LoggerOptions {
+	l.Sink = sink
+	return l
+}
+
+// SetMaxDocumentLength sets the value for the MaxDocumentLength field.
+func (l *LoggerOptions) SetMaxDocumentLength(n uint) *LoggerOptions {
+	l.MaxDocumentLength = n
+	return l
+}
@@ -7,6 +7,7 @@
 package options
 
 import (
+	"crypto/tls"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -159,3 +160,71 @@ func (me MarshalError) Error() string {
 }
 
 var defaultRegistry = bson.DefaultRegistry
+
+var (
+	tlsConfigType  = reflect.TypeOf((*tls.Config)(nil))
+	optionsPkgPath = reflect.TypeOf(Collation{}).PkgPath()
+)
+
+// cloneOptions returns a deep copy of opts, which must be a pointer to an options struct. Pointers, slices, maps, and
+// structs defined in this package are copied, and TLS configurations are cloned. Values stored in interfaces, such as
+// documents, and other types, such as functions, monitors, and read and write concerns, are shared because they are
+// either immutable or not owned by the options.
+func cloneOptions(opts interface{}) interface{} {
+	v := reflect.ValueOf(opts)
+	if v.IsNil() {
+		return opts
+	}
+	return deepCopyValue(v).Interface()
+}
+
+func deepCopyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if v.Type() == tlsConfigType {
+			return reflect.ValueOf(v.Interface().(*tls.Config).Clone())
+		}
+		if v.Elem().Kind() == reflect.Struct && v.Type().Elem().PkgPath() != optionsPkgPath {
+			return v
+		}
+		cp := reflect.New(v.Type().Elem())
+		cp.Elem().Set(deepCopyValue(v.Elem()))
+		return cp
+	case reflect.Struct:
+		if v.Type().PkgPath() != optionsPkgPath {
+			return v
+		}
+		// the unexported fields are copied with the struct because they cannot be set individually
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				cp.Field(i).Set(deepCopyValue(v.Field(i)))
+			}
+		}
+		return cp
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(deepCopyValue(v.Index(i)))
+		}
+		return cp
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, key := range v.MapKeys() {
+			cp.SetMapIndex(key, deepCopyValue(v.MapIndex(key)))
+		}
+		return cp
+	default:
+		return v
+	}
+}
@@ -68,3 +68,15 @@ func MergeReplaceOptions(opts ...*ReplaceOptions) *ReplaceOptions {
 
 	return rOpts
 }
+
+// Clone returns a deep copy of the ReplaceOptions. Values that are stored as interfaces, such as documents, are shared
+// with the copy.
+func (ro *ReplaceOptions) Clone() *ReplaceOptions {
+	return cloneOptions(ro).(*ReplaceOptions)
+}
+
+// Merge returns a copy of the ReplaceOptions that is updated with the options that are set in other, using the same
+// rules as MergeReplaceOptions.
+func (ro *ReplaceOptions) Merge(other *ReplaceOptions) *ReplaceOptions {
+	return MergeReplaceOptions(ro, other).Clone()
+}
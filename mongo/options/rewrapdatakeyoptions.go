@@ -0,0 +1,55 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+// RewrapManyDataKeyOptions represents all possible options used to re-encrypt data keys.
+type RewrapManyDataKeyOptions struct {
+	// The KMS provider of the new master key. If this is not set, the data keys are re-encrypted with their current
+	// master key.
+	Provider *string
+
+	// A KMS-specific key used to encrypt the data keys. This requires Provider to be set. See
+	// DataKeyOptions.SetMasterKey for the format of the master key of each provider.
+	MasterKey interface{}
+}
+
+// RewrapManyDataKey creates a new RewrapManyDataKeyOptions instance.
+func RewrapManyDataKey() *RewrapManyDataKeyOptions {
+	return &RewrapManyDataKeyOptions{}
+}
+
+// SetProvider sets the value for the Provider field.
+func (rmdko *RewrapManyDataKeyOptions) SetProvider(provider string) *RewrapManyDataKeyOptions {
+	rmdko.Provider = &provider
+	return rmdko
+}
+
+// SetMasterKey sets the value for the MasterKey field.
+func (rmdko *RewrapManyDataKeyOptions) SetMasterKey(masterKey interface{}) *RewrapManyDataKeyOptions {
+	rmdko.MasterKey = masterKey
+	return rmdko
+}
+
+// MergeRewrapManyDataKeyOptions combines the given RewrapManyDataKeyOptions instances into a single
+// RewrapManyDataKeyOptions in a last-one-wins fashion.
+func MergeRewrapManyDataKeyOptions(opts ...*RewrapManyDataKeyOptions) *RewrapManyDataKeyOptions {
+	rmdko := RewrapManyDataKey()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+
+		if opt.Provider != nil {
+			rmdko.Provider = opt.Provider
+		}
+		if opt.MasterKey != nil {
+			rmdko.MasterKey = opt.MasterKey
+		}
+	}
+
+	return rmdko
+}
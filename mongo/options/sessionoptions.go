@@ -9,6 +9,7 @@ package options
 import (
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/readconcern"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
@@ -38,6 +39,16 @@ type SessionOptions struct {
 	// The default maximum amount of time that a CommitTransaction operation executed in the session can run on the
 	// server. The default value is nil, which means that that there is no time limit for execution.
 	DefaultMaxCommitTime *time.Duration
+
+	// If true, reads in the session use snapshot read concern, so that they all see the data as it was at a single
+	// point in time, and causal consistency is disabled. Transactions cannot be started in a snapshot session. Snapshot
+	// reads require MongoDB 5.0 or later. The default value is false.
+	Snapshot *bool
+
+	// The point in time that reads in a snapshot session see. This option is ignored if Snapshot is not true. The
+	// default value is nil, which means that the time is chosen by the server for the first read in the session and
+	// used for all subsequent reads.
+	SnapshotTime *primitive.Timestamp
 }
 
 // Session creates a new SessionOptions instance.
@@ -77,6 +88,18 @@ func (s *SessionOptions) SetDefaultMaxCommitTime(mct *time.Duration) *SessionOpt
 	return s
 }
 
+// SetSnapshot sets the value for the Snapshot field.
+func (s *SessionOptions) SetSnapshot(b bool) *SessionOptions {
+	s.Snapshot = &b
+	return s
+}
+
+// SetSnapshotTime sets the value for the SnapshotTime field.
+func (s *SessionOptions) SetSnapshotTime(t primitive.Timestamp) *SessionOptions {
+	s.SnapshotTime = &t
+	return s
+}
+
 // MergeSessionOptions combines the given SessionOptions instances into a single SessionOptions in a last-one-wins
 // fashion.
 func MergeSessionOptions(opts ...*SessionOptions) *SessionOptions {
@@ -100,6 +123,12 @@ func MergeSessionOptions(opts ...*SessionOptions) *SessionOptions {
 		if opt.DefaultMaxCommitTime != nil {
 			s.DefaultMaxCommitTime = opt.DefaultMaxCommitTime
 		}
+		if opt.Snapshot != nil {
+			s.Snapshot = opt.Snapshot
+		}
+		if opt.SnapshotTime != nil {
+			s.SnapshotTime = opt.SnapshotTime
+		}
 	}
 
 	return s
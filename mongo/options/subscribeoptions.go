@@ -0,0 +1,72 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+// OverflowPolicy specifies what a ChangeStreamBroadcaster does when a subscriber's buffer is full. See OverflowBlock,
+// OverflowDropNewest, OverflowDropOldest, and OverflowDisconnect.
+type OverflowPolicy int8
+
+const (
+	// OverflowBlock specifies that the broadcaster should wait until the subscriber has room in its buffer. A slow
+	// subscriber with this policy delays delivery to every other subscriber.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest specifies that the event that does not fit in the subscriber's buffer should be discarded
+	// for that subscriber.
+	OverflowDropNewest
+	// OverflowDropOldest specifies that the oldest event in the subscriber's buffer should be discarded to make room
+	// for the new event.
+	OverflowDropOldest
+	// OverflowDisconnect specifies that the subscription should be closed with ErrSubscriberOverflow.
+	OverflowDisconnect
+)
+
+// SubscribeOptions represents options that can be used to configure a ChangeStreamBroadcaster subscription.
+type SubscribeOptions struct {
+	// The number of events that can be buffered for the subscriber before the OverflowPolicy is applied. The default
+	// value is 0, which means that the subscriber must be receiving when an event is broadcast.
+	BufferSize *int
+
+	// Specifies what happens when an event is broadcast while the subscriber's buffer is full. The default value is
+	// OverflowBlock.
+	OverflowPolicy *OverflowPolicy
+}
+
+// Subscribe creates a new SubscribeOptions instance.
+func Subscribe() *SubscribeOptions {
+	return &SubscribeOptions{}
+}
+
+// SetBufferSize sets the value for the BufferSize field.
+func (s *SubscribeOptions) SetBufferSize(i int) *SubscribeOptions {
+	s.BufferSize = &i
+	return s
+}
+
+// SetOverflowPolicy sets the value for the OverflowPolicy field.
+func (s *SubscribeOptions) SetOverflowPolicy(p OverflowPolicy) *SubscribeOptions {
+	s.OverflowPolicy = &p
+	return s
+}
+
+// MergeSubscribeOptions combines the given SubscribeOptions instances into a single SubscribeOptions in a
+// last-one-wins fashion.
+func MergeSubscribeOptions(opts ...*SubscribeOptions) *SubscribeOptions {
+	sOpts := Subscribe()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.BufferSize != nil {
+			sOpts.BufferSize = opt.BufferSize
+		}
+		if opt.OverflowPolicy != nil {
+			sOpts.OverflowPolicy = opt.OverflowPolicy
+		}
+	}
+
+	return sOpts
+}
@@ -0,0 +1,101 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TailOptions represents options that can be used to configure a Tail operation.
+type TailOptions struct {
+	// The maximum number of documents to be included in each batch returned by the server.
+	BatchSize *int32
+
+	// The maximum amount of time that the server should wait for new documents to satisfy the tailable cursor query.
+	// This option is only valid for MongoDB versions >= 3.2 and is ignored for previous server versions.
+	MaxAwaitTime *time.Duration
+
+	// A document describing which fields will be included in the documents returned by the operation. If the default
+	// resume filter is used, the projection must include the _id field. The default value is nil, which means all
+	// fields will be included.
+	Projection interface{}
+
+	// A function that is called with the last document returned by the cursor to create the filter used when the
+	// cursor is re-created. The default value is nil, which means that the original filter will be combined with
+	// {_id: {$gt: <_id of the last document>}}. This default requires that documents are inserted into the capped
+	// collection in ascending _id order, which is the case for driver-generated ObjectIDs from a single process. A
+	// ResumeFilter should be specified if that is not the case.
+	ResumeFilter func(lastDocument bson.Raw) interface{}
+
+	// The amount of time to wait before re-creating a cursor that was closed by the server without an error, which
+	// happens if the collection is empty or all documents have been deleted. The default value is one second.
+	RetryInterval *time.Duration
+}
+
+// Tail creates a new TailOptions instance.
+func Tail() *TailOptions {
+	return &TailOptions{}
+}
+
+// SetBatchSize sets the value for the BatchSize field.
+func (t *TailOptions) SetBatchSize(i int32) *TailOptions {
+	t.BatchSize = &i
+	return t
+}
+
+// SetMaxAwaitTime sets the value for the MaxAwaitTime field.
+func (t *TailOptions) SetMaxAwaitTime(d time.Duration) *TailOptions {
+	t.MaxAwaitTime = &d
+	return t
+}
+
+// SetProjection sets the value for the Projection field.
+func (t *TailOptions) SetProjection(projection interface{}) *TailOptions {
+	t.Projection = projection
+	return t
+}
+
+// SetResumeFilter sets the value for the ResumeFilter field.
+func (t *TailOptions) SetResumeFilter(fn func(lastDocument bson.Raw) interface{}) *TailOptions {
+	t.ResumeFilter = fn
+	return t
+}
+
+// SetRetryInterval sets the value for the RetryInterval field.
+func (t *TailOptions) SetRetryInterval(d time.Duration) *TailOptions {
+	t.RetryInterval = &d
+	return t
+}
+
+// MergeTailOptions combines the given TailOptions instances into a single TailOptions in a last-one-wins fashion.
+func MergeTailOptions(opts ...*TailOptions) *TailOptions {
+	tOpts := Tail()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.BatchSize != nil {
+			tOpts.BatchSize = opt.BatchSize
+		}
+		if opt.MaxAwaitTime != nil {
+			tOpts.MaxAwaitTime = opt.MaxAwaitTime
+		}
+		if opt.Projection != nil {
+			tOpts.Projection = opt.Projection
+		}
+		if opt.ResumeFilter != nil {
+			tOpts.ResumeFilter = opt.ResumeFilter
+		}
+		if opt.RetryInterval != nil {
+			tOpts.RetryInterval = opt.RetryInterval
+		}
+	}
+
+	return tOpts
+}
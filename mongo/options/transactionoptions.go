@@ -9,6 +9,7 @@ package options
 import (
 	"time"
 
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo/readconcern"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
@@ -35,6 +36,45 @@ type TransactionOptions struct {
 	// server. The default value is nil, which means that the default maximum commit time of the session used to
 	// start the transaction will be used.
 	MaxCommitTime *time.Duration
+
+	// The maximum amount of time that Session.WithTransaction spends retrying the transaction and its commit after
+	// errors with the TransientTransactionError and UnknownTransactionCommitResult labels. This option is only used by
+	// WithTransaction. The default value is 120 seconds.
+	RetryTimeout *time.Duration
+
+	// The delay before the first retry of WithTransaction. The delay is doubled for each further retry. This option is
+	// only used by WithTransaction. The default value is nil, which means that retries are not delayed.
+	RetryBackoff *time.Duration
+
+	// The maximum delay between retries of WithTransaction. The default value is nil, which means that the delay is
+	// only limited by RetryTimeout.
+	MaxRetryBackoff *time.Duration
+
+	// The fraction of each delay between retries of WithTransaction that is randomized, from 0 to 1. A delay d is
+	// replaced by a random delay between d*(1-RetryJitter) and d, so that sessions that conflict with each other do not
+	// retry at the same time. The default value is 0, which means that delays are not randomized.
+	RetryJitter *float64
+
+	// A function that returns the delay before a retry of WithTransaction, given the number of previous retries of
+	// the transaction and its commit. If set, RetryBackoff, MaxRetryBackoff, and RetryJitter are ignored. The default
+	// value is nil.
+	RetryBackoffFunc func(retries int) time.Duration
+
+	// The maximum number of times that WithTransaction runs the transaction again after an error with the
+	// TransientTransactionError label. A value of 0 means that the transaction is not retried. The default value is
+	// nil, which means that retries are only limited by RetryTimeout.
+	MaxTransactionRetries *int
+
+	// The maximum number of times that WithTransaction retries committing a transaction after an error with the
+	// UnknownTransactionCommitResult label. The limit applies to each run of the transaction. A value of 0 means that
+	// the commit is not retried. The default value is nil, which means that retries are only limited by RetryTimeout.
+	MaxCommitRetries *int
+
+	// A function that is called as the commit of the transaction progresses, which is useful to report on slow commits
+	// with a write concern of "majority". See the event.CommitProgressEvent documentation for the stages that are
+	// reported. The function may be called from a goroutine other than the one committing the transaction. The
+	// default value is nil.
+	CommitProgress func(*event.CommitProgressEvent)
 }
 
 // Transaction creates a new TransactionOptions instance.
@@ -66,6 +106,54 @@ func (t *TransactionOptions) SetMaxCommitTime(mct *time.Duration) *TransactionOp
 	return t
 }
 
+// SetRetryTimeout sets the value for the RetryTimeout field.
+func (t *TransactionOptions) SetRetryTimeout(d time.Duration) *TransactionOptions {
+	t.RetryTimeout = &d
+	return t
+}
+
+// SetRetryBackoff sets the value for the RetryBackoff field.
+func (t *TransactionOptions) SetRetryBackoff(d time.Duration) *TransactionOptions {
+	t.RetryBackoff = &d
+	return t
+}
+
+// SetMaxRetryBackoff sets the value for the MaxRetryBackoff field.
+func (t *TransactionOptions) SetMaxRetryBackoff(d time.Duration) *TransactionOptions {
+	t.MaxRetryBackoff = &d
+	return t
+}
+
+// SetRetryJitter sets the value for the RetryJitter field.
+func (t *TransactionOptions) SetRetryJitter(f float64) *TransactionOptions {
+	t.RetryJitter = &f
+	return t
+}
+
+// SetRetryBackoffFunc sets the value for the RetryBackoffFunc field.
+func (t *TransactionOptions) SetRetryBackoffFunc(fn func(retries int) time.Duration) *TransactionOptions {
+	t.RetryBackoffFunc = fn
+	return t
+}
+
+// SetMaxTransactionRetries sets the value for the MaxTransactionRetries field.
+func (t *TransactionOptions) SetMaxTransactionRetries(n int) *TransactionOptions {
+	t.MaxTransactionRetries = &n
+	return t
+}
+
+// SetMaxCommitRetries sets the value for the MaxCommitRetries field.
+func (t *TransactionOptions) SetMaxCommitRetries(n int) *TransactionOptions {
+	t.MaxCommitRetries = &n
+	return t
+}
+
+// SetCommitProgress sets the value for the CommitProgress field.
+func (t *TransactionOptions) SetCommitProgress(fn func(*event.CommitProgressEvent)) *TransactionOptions {
+	t.CommitProgress = fn
+	return t
+}
+
 // MergeTransactionOptions combines the given TransactionOptions instances into a single TransactionOptions in a
 // last-one-wins fashion.
 func MergeTransactionOptions(opts ...*TransactionOptions) *TransactionOptions {
@@ -86,6 +174,30 @@ func MergeTransactionOptions(opts ...*TransactionOptions) *TransactionOptions {
 		if opt.MaxCommitTime != nil {
 			t.MaxCommitTime = opt.MaxCommitTime
 		}
+		if opt.RetryTimeout != nil {
+			t.RetryTimeout = opt.RetryTimeout
+		}
+		if opt.RetryBackoff != nil {
+			t.RetryBackoff = opt.RetryBackoff
+		}
+		if opt.MaxRetryBackoff != nil {
+			t.MaxRetryBackoff = opt.MaxRetryBackoff
+		}
+		if opt.RetryJitter != nil {
+			t.RetryJitter = opt.RetryJitter
+		}
+		if opt.RetryBackoffFunc != nil {
+			t.RetryBackoffFunc = opt.RetryBackoffFunc
+		}
+		if opt.MaxTransactionRetries != nil {
+			t.MaxTransactionRetries = opt.MaxTransactionRetries
+		}
+		if opt.MaxCommitRetries != nil {
+			t.MaxCommitRetries = opt.MaxCommitRetries
+		}
+		if opt.CommitProgress != nil {
+			t.CommitProgress = opt.CommitProgress
+		}
 	}
 
 	return t
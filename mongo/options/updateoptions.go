@@ -81,3 +81,15 @@ func MergeUpdateOptions(opts ...*UpdateOptions) *UpdateOptions {
 
 	return uOpts
 }
+
+// Clone returns a deep copy of the UpdateOptions. Values that are stored as interfaces, such as documents, are shared
+// with the copy.
+func (uo *UpdateOptions) Clone() *UpdateOptions {
+	return cloneOptions(uo).(*UpdateOptions)
+}
+
+// Merge returns a copy of the UpdateOptions that is updated with the options that are set in other, using the same
+// rules as MergeUpdateOptions.
+func (uo *UpdateOptions) Merge(other *UpdateOptions) *UpdateOptions {
+	return MergeUpdateOptions(uo, other).Clone()
+}
@@ -0,0 +1,56 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import "go.mongodb.org/mongo-driver/mongo/readpref"
+
+// WaitUntilReadyOptions represents options that can be used to configure a Client.WaitUntilReady call.
+type WaitUntilReadyOptions struct {
+	// The read preference that servers must satisfy to be considered ready. The default value is readpref.Primary(),
+	// meaning a writable primary must be available. Use readpref.Nearest() to wait for any data-bearing member.
+	ReadPreference *readpref.ReadPref
+
+	// The minimum number of servers that must satisfy ReadPreference. The default value is 1.
+	MinServers *int
+}
+
+// WaitUntilReady creates a new WaitUntilReadyOptions instance.
+func WaitUntilReady() *WaitUntilReadyOptions {
+	return &WaitUntilReadyOptions{}
+}
+
+// SetReadPreference sets the value for the ReadPreference field.
+func (w *WaitUntilReadyOptions) SetReadPreference(rp *readpref.ReadPref) *WaitUntilReadyOptions {
+	w.ReadPreference = rp
+	return w
+}
+
+// SetMinServers sets the value for the MinServers field.
+func (w *WaitUntilReadyOptions) SetMinServers(n int) *WaitUntilReadyOptions {
+	w.MinServers = &n
+	return w
+}
+
+// MergeWaitUntilReadyOptions combines the given WaitUntilReadyOptions instances into a single WaitUntilReadyOptions
+// in a last-one-wins fashion.
+func MergeWaitUntilReadyOptions(opts ...*WaitUntilReadyOptions) *WaitUntilReadyOptions {
+	w := WaitUntilReady()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+
+		if opt.ReadPreference != nil {
+			w.ReadPreference = opt.ReadPreference
+		}
+		if opt.MinServers != nil {
+			w.MinServers = opt.MinServers
+		}
+	}
+
+	return w
+}
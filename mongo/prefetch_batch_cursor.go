@@ -0,0 +1,191 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/session"
+)
+
+// prefetchedBatch is a batch retrieved by the prefetch goroutine along with the state of the underlying cursor at the
+// time the batch was retrieved.
+type prefetchedBatch struct {
+	batch *bsoncore.DocumentSequence
+	id    int64
+	err   error
+	final bool
+}
+
+// prefetchBatchCursor is a batchCursor that wraps another batchCursor and retrieves up to depth batches ahead of the
+// batch currently being iterated in a background goroutine. The wrapped cursor is used exclusively by the background
+// goroutine until it exits.
+type prefetchBatchCursor struct {
+	bc      batchCursor
+	depth   int
+	batches chan prefetchedBatch
+	done    chan struct{}
+	cancel  context.CancelFunc
+
+	// batchSize is a batch size set by SetBatchSize that has not yet been applied to the wrapped cursor.
+	sizeMu    sync.Mutex
+	batchSize *int32
+
+	started bool
+	current *bsoncore.DocumentSequence
+	id      int64
+	err     error
+}
+
+var _ batchCursor = (*prefetchBatchCursor)(nil)
+
+func newPrefetchBatchCursor(bc batchCursor, depth int) *prefetchBatchCursor {
+	return &prefetchBatchCursor{
+		bc:      bc,
+		depth:   depth,
+		current: new(bsoncore.DocumentSequence),
+		id:      bc.ID(),
+	}
+}
+
+// canPrefetch returns true if a cursor for an operation executed with the given session and options can be wrapped in a
+// prefetchBatchCursor. Prefetching is not done for explicit sessions because sessions cannot be used concurrently.
+func canPrefetch(sess *session.Client, depth *int32) bool {
+	if depth == nil || *depth <= 0 {
+		return false
+	}
+	return sess == nil || sess.SessionType != session.Explicit
+}
+
+func (p *prefetchBatchCursor) start() {
+	p.started = true
+	p.batches = make(chan prefetchedBatch, p.depth)
+	p.done = make(chan struct{})
+
+	var ctx context.Context
+	ctx, p.cancel = context.WithCancel(context.Background())
+	go p.prefetch(ctx)
+}
+
+func (p *prefetchBatchCursor) prefetch(ctx context.Context) {
+	defer close(p.done)
+	defer close(p.batches)
+
+	for {
+		p.applyBatchSize()
+		if !p.bc.Next(ctx) {
+			err := p.bc.Err()
+			id := p.bc.ID()
+			if err == nil && id != 0 {
+				// empty batch, but the cursor is still valid.
+				continue
+			}
+			p.send(ctx, prefetchedBatch{batch: new(bsoncore.DocumentSequence), id: id, err: err, final: true})
+			return
+		}
+
+		// The wrapped cursor may reuse the memory for its batch, so the documents must be copied before the next
+		// getMore is sent.
+		src := p.bc.Batch()
+		batch := &bsoncore.DocumentSequence{
+			Style: src.Style,
+			Data:  append([]byte(nil), src.Data...),
+		}
+		id := p.bc.ID()
+		if !p.send(ctx, prefetchedBatch{batch: batch, id: id}) || id == 0 {
+			return
+		}
+	}
+}
+
+func (p *prefetchBatchCursor) send(ctx context.Context, pb prefetchedBatch) bool {
+	select {
+	case p.batches <- pb:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// SetBatchSize sets the batch size used by the wrapped cursor for getMore commands sent after the batches that have
+// already been prefetched.
+func (p *prefetchBatchCursor) SetBatchSize(size int32) {
+	p.sizeMu.Lock()
+	p.batchSize = &size
+	p.sizeMu.Unlock()
+}
+
+// applyBatchSize passes a batch size set by SetBatchSize to the wrapped cursor. It must only be called by the prefetch
+// goroutine.
+func (p *prefetchBatchCursor) applyBatchSize() {
+	p.sizeMu.Lock()
+	size := p.batchSize
+	p.batchSize = nil
+	p.sizeMu.Unlock()
+
+	if bss, ok := p.bc.(batchSizeSetter); ok && size != nil {
+		bss.SetBatchSize(*size)
+	}
+}
+
+// ID returns the cursor ID as of the batch currently being iterated.
+func (p *prefetchBatchCursor) ID() int64 { return p.id }
+
+// Next waits for the next prefetched batch or for ctx to expire.
+func (p *prefetchBatchCursor) Next(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+	if !p.started {
+		p.start()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	select {
+	case pb, ok := <-p.batches:
+		if !ok {
+			p.current = new(bsoncore.DocumentSequence)
+			p.id = 0
+			return false
+		}
+		p.current = pb.batch
+		p.id = pb.id
+		if pb.final {
+			p.err = pb.err
+			return false
+		}
+		return true
+	case <-ctx.Done():
+		p.err = ctx.Err()
+		return false
+	}
+}
+
+// Batch returns the batch currently being iterated.
+func (p *prefetchBatchCursor) Batch() *bsoncore.DocumentSequence { return p.current }
+
+// Server returns the server for the wrapped cursor.
+func (p *prefetchBatchCursor) Server() driver.Server { return p.bc.Server() }
+
+// Err returns the last error encountered.
+func (p *prefetchBatchCursor) Err() error { return p.err }
+
+// Close stops the prefetch goroutine, discards any prefetched batches, and closes the wrapped cursor.
+func (p *prefetchBatchCursor) Close(ctx context.Context) error {
+	if p.started {
+		p.cancel()
+		<-p.done
+	}
+	p.id = 0
+	p.current = new(bsoncore.DocumentSequence)
+	return p.bc.Close(ctx)
+}
@@ -0,0 +1,70 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/session"
+)
+
+func TestPrefetchBatchCursor(t *testing.T) {
+	t.Run("iterates all documents", func(t *testing.T) {
+		tbc := newTestBatchCursor(4, 3)
+		cursor, err := newCursor(newPrefetchBatchCursor(tbc, 2), nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+
+		var docs []bson.D
+		err = cursor.All(context.Background(), &docs)
+		assert.Nil(t, err, "All error: %v", err)
+		assert.Equal(t, 12, len(docs), "expected 12 documents, got %v", len(docs))
+		for index, doc := range docs {
+			expected := bson.D{{"foo", int32(index)}}
+			assert.Equal(t, expected, doc, "expected doc %v, got %v", expected, doc)
+		}
+		assert.True(t, tbc.closed, "expected wrapped cursor to be closed but was not")
+	})
+	t.Run("fetches ahead of the consumer", func(t *testing.T) {
+		tbc := newTestBatchCursor(5, 1)
+		pbc := newPrefetchBatchCursor(tbc, 2)
+		defer pbc.Close(context.Background())
+
+		assert.True(t, pbc.Next(context.Background()), "expected Next to return true")
+		// The goroutine should buffer two more batches and then block trying to send a third.
+		deadline := time.Now().Add(5 * time.Second)
+		for len(pbc.batches) < 2 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		assert.Equal(t, 2, len(pbc.batches), "expected 2 prefetched batches, got %v", len(pbc.batches))
+	})
+	t.Run("Close stops prefetching", func(t *testing.T) {
+		tbc := newTestBatchCursor(10, 1)
+		pbc := newPrefetchBatchCursor(tbc, 1)
+		assert.True(t, pbc.Next(context.Background()), "expected Next to return true")
+
+		err := pbc.Close(context.Background())
+		assert.Nil(t, err, "Close error: %v", err)
+		assert.True(t, tbc.closed, "expected wrapped cursor to be closed but was not")
+		assert.Equal(t, int64(0), pbc.ID(), "expected ID 0 after Close, got %v", pbc.ID())
+	})
+	t.Run("canPrefetch", func(t *testing.T) {
+		depth := int32(2)
+		zero := int32(0)
+		explicit := &session.Client{SessionType: session.Explicit}
+		implicit := &session.Client{SessionType: session.Implicit}
+
+		assert.False(t, canPrefetch(nil, nil), "expected no prefetch without depth")
+		assert.False(t, canPrefetch(nil, &zero), "expected no prefetch with zero depth")
+		assert.False(t, canPrefetch(explicit, &depth), "expected no prefetch with explicit session")
+		assert.True(t, canPrefetch(implicit, &depth), "expected prefetch with implicit session")
+		assert.True(t, canPrefetch(nil, &depth), "expected prefetch without session")
+	})
+}
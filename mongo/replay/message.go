@@ -0,0 +1,239 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package replay
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+// maxWireMessageSize bounds the size of the messages that the proxy accepts so that a corrupt length prefix does not
+// cause a huge allocation.
+const maxWireMessageSize = 64 * 1024 * 1024
+
+// message is a decoded command or reply.
+type message struct {
+	requestID  int32
+	responseTo int32
+	flags      wiremessage.MsgFlag
+	db         string
+	// document is the body of the message. Document sequences are added to it as arrays.
+	document bson.Raw
+}
+
+// readMessage reads a single wire message from r.
+func readMessage(r io.Reader) ([]byte, error) {
+	var sizeBuf [4]byte
+	if _, err := io.ReadFull(r, sizeBuf[:]); err != nil {
+		return nil, err
+	}
+	size := int32(binary.LittleEndian.Uint32(sizeBuf[:]))
+	if size < 16 || size > maxWireMessageSize {
+		return nil, fmt.Errorf("invalid wire message size %d", size)
+	}
+	wm := make([]byte, size)
+	copy(wm, sizeBuf[:])
+	if _, err := io.ReadFull(r, wm[4:]); err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
+// decodeMessage decodes a command or reply, decompressing it first if necessary. OP_QUERY commands and OP_REPLY
+// replies, which are used for the handshake of new connections, are decoded as well. It returns false if wm is a
+// message of another type.
+func decodeMessage(wm []byte) (message, bool, error) {
+	wm, err := decompress(wm)
+	if err != nil {
+		return message{}, false, err
+	}
+	_, requestID, responseTo, opcode, rem, ok := wiremessage.ReadHeader(wm)
+	if !ok {
+		return message{}, false, errors.New("malformed wire message header")
+	}
+	msg := message{requestID: requestID, responseTo: responseTo}
+	switch opcode {
+	case wiremessage.OpMsg:
+		return decodeOpMsg(msg, rem)
+	case wiremessage.OpQuery:
+		return decodeOpQuery(msg, rem)
+	case wiremessage.OpReply:
+		return decodeOpReply(msg, rem)
+	default:
+		return message{}, false, nil
+	}
+}
+
+func decodeOpQuery(msg message, rem []byte) (message, bool, error) {
+	var ok bool
+	var collName string
+	var query bsoncore.Document
+	if _, rem, ok = wiremessage.ReadQueryFlags(rem); !ok {
+		return message{}, false, errors.New("malformed OP_QUERY flags")
+	}
+	if collName, rem, ok = wiremessage.ReadQueryFullCollectionName(rem); !ok {
+		return message{}, false, errors.New("malformed OP_QUERY collection name")
+	}
+	if _, rem, ok = wiremessage.ReadQueryNumberToSkip(rem); !ok {
+		return message{}, false, errors.New("malformed OP_QUERY number to skip")
+	}
+	if _, rem, ok = wiremessage.ReadQueryNumberToReturn(rem); !ok {
+		return message{}, false, errors.New("malformed OP_QUERY number to return")
+	}
+	if query, _, ok = wiremessage.ReadQueryQuery(rem); !ok {
+		return message{}, false, errors.New("malformed OP_QUERY query")
+	}
+	// commands are sent to the "<db>.$cmd" collection and can be wrapped in a $query document
+	if !strings.HasSuffix(collName, ".$cmd") {
+		return message{}, false, nil
+	}
+	msg.db = strings.TrimSuffix(collName, ".$cmd")
+	if wrapped, err := query.LookupErr("$query"); err == nil {
+		if doc, ok := wrapped.DocumentOK(); ok {
+			query = doc
+		}
+	}
+	msg.document = append(bson.Raw(nil), query...)
+	return msg, true, nil
+}
+
+func decodeOpReply(msg message, rem []byte) (message, bool, error) {
+	var ok bool
+	var doc bsoncore.Document
+	if _, rem, ok = wiremessage.ReadReplyFlags(rem); !ok {
+		return message{}, false, errors.New("malformed OP_REPLY flags")
+	}
+	if _, rem, ok = wiremessage.ReadReplyCursorID(rem); !ok {
+		return message{}, false, errors.New("malformed OP_REPLY cursor ID")
+	}
+	if _, rem, ok = wiremessage.ReadReplyStartingFrom(rem); !ok {
+		return message{}, false, errors.New("malformed OP_REPLY starting from")
+	}
+	if _, rem, ok = wiremessage.ReadReplyNumberReturned(rem); !ok {
+		return message{}, false, errors.New("malformed OP_REPLY number returned")
+	}
+	if doc, _, ok = wiremessage.ReadReplyDocument(rem); !ok {
+		return message{}, false, errors.New("malformed OP_REPLY document")
+	}
+	msg.document = append(bson.Raw(nil), doc...)
+	return msg, true, nil
+}
+
+func decodeOpMsg(msg message, rem []byte) (message, bool, error) {
+	var ok bool
+	var err error
+	if msg.flags, rem, ok = wiremessage.ReadMsgFlags(rem); !ok {
+		return message{}, false, errors.New("malformed OP_MSG flags")
+	}
+	if msg.flags&wiremessage.ChecksumPresent != 0 {
+		if len(rem) < 4 {
+			return message{}, false, errors.New("malformed OP_MSG checksum")
+		}
+		rem = rem[:len(rem)-4]
+	}
+
+	var body bsoncore.Document
+	var sequences bson.D
+	for len(rem) > 0 {
+		var stype wiremessage.SectionType
+		if stype, rem, ok = wiremessage.ReadMsgSectionType(rem); !ok {
+			return message{}, false, errors.New("malformed OP_MSG section")
+		}
+		switch stype {
+		case wiremessage.SingleDocument:
+			if body, rem, ok = wiremessage.ReadMsgSectionSingleDocument(rem); !ok {
+				return message{}, false, errors.New("malformed OP_MSG document section")
+			}
+		case wiremessage.DocumentSequence:
+			var identifier string
+			var docs []bsoncore.Document
+			if identifier, docs, rem, ok = wiremessage.ReadMsgSectionDocumentSequence(rem); !ok {
+				return message{}, false, errors.New("malformed OP_MSG document sequence section")
+			}
+			arr := make(bson.A, 0, len(docs))
+			for _, doc := range docs {
+				arr = append(arr, bson.Raw(doc))
+			}
+			sequences = append(sequences, bson.E{Key: identifier, Value: arr})
+		default:
+			return message{}, false, fmt.Errorf("unsupported OP_MSG section type %d", stype)
+		}
+	}
+	if body == nil {
+		return message{}, false, errors.New("OP_MSG without a body document")
+	}
+
+	msg.document = append(bson.Raw(nil), body...)
+	if len(sequences) > 0 {
+		var doc bson.D
+		if err := bson.Unmarshal(body, &doc); err != nil {
+			return message{}, false, err
+		}
+		if msg.document, err = bson.Marshal(append(doc, sequences...)); err != nil {
+			return message{}, false, err
+		}
+	}
+	if db, err := msg.document.LookupErr("$db"); err == nil {
+		msg.db, _ = db.StringValueOK()
+	}
+	return msg, true, nil
+}
+
+// decompress returns the message compressed in an OP_COMPRESSED, or wm itself if it is not compressed.
+func decompress(wm []byte) ([]byte, error) {
+	length, requestID, responseTo, opcode, rem, ok := wiremessage.ReadHeader(wm)
+	if !ok || len(wm) < int(length) {
+		return nil, errors.New("malformed wire message: insufficient bytes")
+	}
+	if opcode != wiremessage.OpCompressed {
+		return wm, nil
+	}
+	if opcode, rem, ok = wiremessage.ReadCompressedOriginalOpCode(rem); !ok {
+		return nil, errors.New("malformed OP_COMPRESSED: missing original opcode")
+	}
+	uncompressedSize, rem, ok := wiremessage.ReadCompressedUncompressedSize(rem)
+	if !ok {
+		return nil, errors.New("malformed OP_COMPRESSED: missing uncompressed size")
+	}
+	compressorID, rem, ok := wiremessage.ReadCompressedCompressorID(rem)
+	if !ok {
+		return nil, errors.New("malformed OP_COMPRESSED: missing compressor ID")
+	}
+	compressed, _, ok := wiremessage.ReadCompressedCompressedMessage(rem, length-25)
+	if !ok {
+		return nil, errors.New("malformed OP_COMPRESSED: insufficient bytes for compressed wiremessage")
+	}
+
+	uncompressed, err := driver.DecompressPayload(compressed, driver.CompressionOpts{
+		Compressor:       compressorID,
+		UncompressedSize: uncompressedSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := wiremessage.AppendHeader(make([]byte, 0, uncompressedSize+16), uncompressedSize+16, requestID, responseTo,
+		opcode)
+	return append(out, uncompressed...), nil
+}
+
+// appendReply appends an OP_MSG containing reply to dst.
+func appendReply(dst []byte, responseTo int32, reply bson.Raw) []byte {
+	var wmindex int32
+	wmindex, dst = wiremessage.AppendHeaderStart(dst, wiremessage.NextRequestID(), responseTo, wiremessage.OpMsg)
+	dst = wiremessage.AppendMsgFlags(dst, 0)
+	dst = wiremessage.AppendMsgSectionType(dst, wiremessage.SingleDocument)
+	dst = append(dst, reply...)
+	return bsoncore.UpdateLength(dst, wmindex, int32(len(dst[wmindex:])))
+}
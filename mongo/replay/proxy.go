@@ -0,0 +1,229 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package replay
+
+import (
+	"crypto/tls"
+	"net"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+// unrecordedCommands are the handshake and authentication commands, which the Replayer does not need.
+var unrecordedCommands = map[string]bool{
+	"hello":        true,
+	"isMaster":     true,
+	"ismaster":     true,
+	"saslStart":    true,
+	"saslContinue": true,
+	"authenticate": true,
+	"getnonce":     true,
+}
+
+// Proxy is a TCP proxy that forwards connections to a MongoDB server and records the commands and replies that are
+// exchanged on them. Messages are forwarded unchanged.
+type Proxy struct {
+	upstream  string
+	tlsConfig *tls.Config
+	listener  net.Listener
+	wg        sync.WaitGroup
+
+	mu        sync.Mutex
+	conns     map[net.Conn]struct{}
+	recording Recording
+	closed    bool
+}
+
+// StartProxy starts a proxy that listens on a random local port and forwards connections to the server at upstream,
+// which is a "host:port" address. If tlsConfig is not nil, the connections to the server use TLS. Clients must
+// connect to the proxy without TLS; see Proxy.Addr.
+func StartProxy(upstream string, tlsConfig *tls.Config) (*Proxy, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	p := &Proxy{
+		upstream:  upstream,
+		tlsConfig: tlsConfig,
+		listener:  listener,
+		conns:     make(map[net.Conn]struct{}),
+	}
+	p.wg.Add(1)
+	go p.accept()
+	return p, nil
+}
+
+// Addr returns the "host:port" address that the proxy listens on. Clients should connect to it with a direct
+// connection so that they do not discover and connect to the other members of the deployment.
+func (p *Proxy) Addr() string {
+	return p.listener.Addr().String()
+}
+
+// Recording returns a copy of the exchanges that have been recorded so far.
+func (p *Proxy) Recording() *Recording {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rec := p.recording
+	rec.Exchanges = append([]Exchange(nil), p.recording.Exchanges...)
+	return &rec
+}
+
+// Close stops the proxy and closes all of its connections. The recording remains available.
+func (p *Proxy) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	err := p.listener.Close()
+	for conn := range p.conns {
+		_ = conn.Close()
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	return err
+}
+
+func (p *Proxy) accept() {
+	defer p.wg.Done()
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		p.wg.Add(1)
+		go p.serve(conn)
+	}
+}
+
+// track adds conn to the connections that are closed by Close. It returns false if the proxy is already closed.
+func (p *Proxy) track(conn net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false
+	}
+	p.conns[conn] = struct{}{}
+	return true
+}
+
+func (p *Proxy) untrack(conn net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.conns, conn)
+}
+
+func (p *Proxy) dial() (net.Conn, error) {
+	if p.tlsConfig != nil {
+		return tls.Dial("tcp", p.upstream, p.tlsConfig)
+	}
+	return net.Dial("tcp", p.upstream)
+}
+
+// serve forwards messages between a client connection and a new connection to the server until either is closed.
+func (p *Proxy) serve(client net.Conn) {
+	defer p.wg.Done()
+	defer func() { _ = client.Close() }()
+
+	if !p.track(client) {
+		return
+	}
+	defer p.untrack(client)
+
+	server, err := p.dial()
+	if err != nil {
+		return
+	}
+	defer func() { _ = server.Close() }()
+	if !p.track(server) {
+		return
+	}
+	defer p.untrack(server)
+
+	// requests that are waiting for a reply, by request ID
+	var pendingMu sync.Mutex
+	pending := make(map[int32]Exchange)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		forward(server, client, func(msg message) {
+			exchange := Exchange{Database: msg.db, Command: msg.document}
+			if msg.flags&wiremessage.MoreToCome != 0 {
+				// unacknowledged writes do not have a reply
+				p.record(exchange, nil)
+				return
+			}
+			pendingMu.Lock()
+			pending[msg.requestID] = exchange
+			pendingMu.Unlock()
+		})
+		_ = server.Close()
+	}()
+
+	forward(client, server, func(msg message) {
+		pendingMu.Lock()
+		exchange, ok := pending[msg.responseTo]
+		// streamed replies continue to answer the same request
+		if ok && msg.flags&wiremessage.MoreToCome == 0 {
+			delete(pending, msg.responseTo)
+		}
+		pendingMu.Unlock()
+		if ok {
+			p.record(exchange, msg.document)
+		}
+	})
+	_ = client.Close()
+	<-done
+}
+
+// forward copies messages from src to dst until either connection fails. Each OP_MSG is passed to observe before it
+// is forwarded.
+func forward(dst, src net.Conn, observe func(message)) {
+	for {
+		wm, err := readMessage(src)
+		if err != nil {
+			return
+		}
+		if msg, ok, err := decodeMessage(wm); err == nil && ok {
+			observe(msg)
+		}
+		if _, err := dst.Write(wm); err != nil {
+			return
+		}
+	}
+}
+
+// record adds an exchange to the recording. Handshake replies are not recorded, but the wire version and session
+// timeout they advertise are.
+func (p *Proxy) record(exchange Exchange, reply bson.Raw) {
+	exchange.Reply = reply
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !unrecordedCommands[exchange.CommandName()] {
+		p.recording.Exchanges = append(p.recording.Exchanges, exchange)
+		return
+	}
+	if reply == nil {
+		return
+	}
+	if v, err := reply.LookupErr("maxWireVersion"); err == nil {
+		if n, ok := coreValue(v).AsInt32OK(); ok {
+			p.recording.MaxWireVersion = n
+		}
+	}
+	if v, err := reply.LookupErr("logicalSessionTimeoutMinutes"); err == nil {
+		if n, ok := coreValue(v).AsInt32OK(); ok {
+			p.recording.SessionTimeoutMinutes = n
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package replay records the commands that a client sends to a MongoDB deployment together with the replies, and
+// replays the replies to a client later without a deployment. This makes it possible to write hermetic integration
+// tests from a live run and to reproduce the traffic of an application deterministically.
+//
+// Recording is done by a Proxy, which forwards the connections of a client to a server and records every command and
+// reply that it sees:
+//
+//	proxy, err := replay.StartProxy("localhost:27017", nil)
+//	if err != nil { return err }
+//	client, err := mongo.Connect(ctx, options.Client().SetHosts([]string{proxy.Addr()}).SetDirect(true))
+//	// run the workload, then disconnect the client
+//	proxy.Close()
+//	_, err = proxy.Recording().WriteTo(file)
+//
+// A Replayer answers the commands of a client with the recorded replies:
+//
+//	rec, err := replay.ReadRecording(file)
+//	if err != nil { return err }
+//	replayer := replay.NewReplayer(rec)
+//	client, err := mongo.Connect(ctx, replayer.ClientOptions())
+//
+// Commands are matched to recorded exchanges by command name, database, and the value of the first element of the
+// command, which is the collection name for most commands and the cursor ID for getMore. Exchanges with the same key
+// are replayed in the order in which they were recorded, so the workload must issue them in the same order. Other
+// fields of the commands, such as documents, filters, and session IDs, are not compared.
+//
+// Handshake and authentication commands are not recorded because the Replayer does not need them. The Proxy
+// understands compressed messages, but it cannot inspect TLS connections, so the client must connect to it without
+// TLS. The connection from the Proxy to the server can use TLS.
+package replay
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+const (
+	defaultMaxWireVersion        int32 = 8
+	defaultSessionTimeoutMinutes int32 = 30
+)
+
+// Exchange is a command and the reply that the server sent for it.
+type Exchange struct {
+	Database string   `bson:"db"`
+	Command  bson.Raw `bson:"command"`
+	// Reply is nil for unacknowledged writes, which do not have a reply.
+	Reply bson.Raw `bson:"reply,omitempty"`
+}
+
+// CommandName returns the name of the command, which is the key of its first element.
+func (e Exchange) CommandName() string {
+	elems, err := e.Command.Elements()
+	if err != nil || len(elems) == 0 {
+		return ""
+	}
+	return elems[0].Key()
+}
+
+// key returns the key used to match commands to exchanges.
+func (e Exchange) key() string {
+	return commandKey(e.Database, e.Command)
+}
+
+// String implements the fmt.Stringer interface.
+func (e Exchange) String() string {
+	return e.key()
+}
+
+// commandKey returns the command name, the database, and the value of the first element if it is a string or an
+// integer, e.g. "find db.coll" or "getMore db 12345".
+func commandKey(db string, cmd bson.Raw) string {
+	elems, err := cmd.Elements()
+	if err != nil || len(elems) == 0 {
+		return ""
+	}
+	first := elems[0]
+	if s, ok := first.Value().StringValueOK(); ok {
+		return fmt.Sprintf("%s %s.%s", first.Key(), db, s)
+	}
+	if n, ok := coreValue(first.Value()).AsInt64OK(); ok {
+		return fmt.Sprintf("%s %s %d", first.Key(), db, n)
+	}
+	return fmt.Sprintf("%s %s", first.Key(), db)
+}
+
+func coreValue(v bson.RawValue) bsoncore.Value {
+	return bsoncore.Value{Type: v.Type, Data: v.Value}
+}
+
+// Recording is a sequence of exchanges between a client and a server.
+type Recording struct {
+	// MaxWireVersion and SessionTimeoutMinutes are copied from the handshake replies of the server so that the
+	// Replayer advertises the same capabilities. They are zero if no handshake was recorded, in which case defaults
+	// are used during replay.
+	MaxWireVersion        int32      `bson:"maxWireVersion"`
+	SessionTimeoutMinutes int32      `bson:"sessionTimeoutMinutes"`
+	Exchanges             []Exchange `bson:"exchanges"`
+}
+
+// WriteTo writes the recording to w as a canonical extended JSON document, which preserves the types of all values. It
+// implements the io.WriterTo interface.
+func (r *Recording) WriteTo(w io.Writer) (int64, error) {
+	exchanges := r.Exchanges
+	if exchanges == nil {
+		exchanges = []Exchange{}
+	}
+	data, err := bson.MarshalExtJSON(Recording{
+		MaxWireVersion:        r.MaxWireVersion,
+		SessionTimeoutMinutes: r.SessionTimeoutMinutes,
+		Exchanges:             exchanges,
+	}, true, false)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// ReadRecording reads a recording written by Recording.WriteTo from r.
+func ReadRecording(r io.Reader) (*Recording, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var rec Recording
+	if err := bson.UnmarshalExtJSON(data, true, &rec); err != nil {
+		return nil, fmt.Errorf("error reading recording: %v", err)
+	}
+	return &rec, nil
+}
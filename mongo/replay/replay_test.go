@@ -0,0 +1,286 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+func marshal(t *testing.T, doc interface{}) bson.Raw {
+	t.Helper()
+
+	raw, err := bson.Marshal(doc)
+	assert.Nil(t, err, "Marshal error: %v", err)
+	return raw
+}
+
+// upstreamReply returns the reply of the fake server started by startUpstream.
+func upstreamReply(cmd bson.Raw) bson.D {
+	elems, _ := cmd.Elements()
+	switch elems[0].Key() {
+	case "hello", "isMaster", "ismaster":
+		return bson.D{
+			{"ismaster", true},
+			{"maxBsonObjectSize", int32(16777216)},
+			{"maxMessageSizeBytes", int32(48000000)},
+			{"maxWriteBatchSize", int32(100000)},
+			{"maxWireVersion", int32(9)},
+			{"logicalSessionTimeoutMinutes", int32(15)},
+			{"ok", 1.0},
+		}
+	case "find":
+		return bson.D{
+			{"cursor", bson.D{
+				{"id", int64(0)},
+				{"ns", "db.coll"},
+				{"firstBatch", bson.A{bson.D{{"_id", int64(1)}, {"x", "a"}}}},
+			}},
+			{"ok", 1.0},
+		}
+	case "insert":
+		return bson.D{{"n", int32(1)}, {"ok", 1.0}}
+	default:
+		return bson.D{{"ok", 1.0}}
+	}
+}
+
+// startUpstream starts a minimal server that answers OP_QUERY commands with an OP_REPLY and OP_MSG commands with an
+// OP_MSG.
+func startUpstream(t *testing.T) net.Listener {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err, "Listen error: %v", err)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				for {
+					wm, err := readMessage(conn)
+					if err != nil {
+						return
+					}
+					msg, ok, err := decodeMessage(wm)
+					if err != nil || !ok {
+						return
+					}
+					reply, _ := bson.Marshal(upstreamReply(msg.document))
+					_, _, _, opcode, _, _ := wiremessage.ReadHeader(wm)
+					var out []byte
+					if opcode == wiremessage.OpQuery {
+						out = appendOpReply(nil, msg.requestID, reply)
+					} else {
+						out = appendReply(nil, msg.requestID, reply)
+					}
+					if _, err := conn.Write(out); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener
+}
+
+func appendOpReply(dst []byte, responseTo int32, reply bson.Raw) []byte {
+	var wmindex int32
+	wmindex, dst = wiremessage.AppendHeaderStart(dst, wiremessage.NextRequestID(), responseTo, wiremessage.OpReply)
+	dst = wiremessage.AppendReplyFlags(dst, 0)
+	dst = wiremessage.AppendReplyCursorID(dst, 0)
+	dst = wiremessage.AppendReplyStartingFrom(dst, 0)
+	dst = wiremessage.AppendReplyNumberReturned(dst, 1)
+	dst = append(dst, reply...)
+	return bsoncore.UpdateLength(dst, wmindex, int32(len(dst[wmindex:])))
+}
+
+func TestCommandKey(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cmd      bson.D
+		expected string
+	}{
+		{"collection", bson.D{{"find", "coll"}, {"filter", bson.D{}}}, "find db.coll"},
+		{"cursor ID", bson.D{{"getMore", int64(42)}, {"collection", "coll"}}, "getMore db 42"},
+		{"int32", bson.D{{"ping", int32(1)}}, "ping db 1"},
+		{"other", bson.D{{"listDatabases", true}}, "listDatabases db"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := commandKey("db", marshal(t, tc.cmd))
+			assert.Equal(t, tc.expected, got, "expected key %q, got %q", tc.expected, got)
+		})
+	}
+}
+
+func TestRecording(t *testing.T) {
+	rec := &Recording{
+		MaxWireVersion:        9,
+		SessionTimeoutMinutes: 15,
+		Exchanges: []Exchange{
+			{
+				Database: "db",
+				Command:  marshal(t, bson.D{{"insert", "coll"}, {"documents", bson.A{bson.D{{"x", int64(1)}}}}}),
+				Reply:    marshal(t, bson.D{{"n", int32(1)}, {"ok", 1.0}}),
+			},
+			{
+				Database: "db",
+				Command:  marshal(t, bson.D{{"insert", "coll"}, {"writeConcern", bson.D{{"w", int32(0)}}}}),
+			},
+		},
+	}
+	var buf bytes.Buffer
+	_, err := rec.WriteTo(&buf)
+	assert.Nil(t, err, "WriteTo error: %v", err)
+
+	got, err := ReadRecording(&buf)
+	assert.Nil(t, err, "ReadRecording error: %v", err)
+	assert.Equal(t, rec.MaxWireVersion, got.MaxWireVersion, "expected max wire version %v, got %v",
+		rec.MaxWireVersion, got.MaxWireVersion)
+	assert.Equal(t, rec.SessionTimeoutMinutes, got.SessionTimeoutMinutes, "expected session timeout %v, got %v",
+		rec.SessionTimeoutMinutes, got.SessionTimeoutMinutes)
+	assert.Equal(t, len(rec.Exchanges), len(got.Exchanges), "expected %d exchanges, got %d",
+		len(rec.Exchanges), len(got.Exchanges))
+	for i, exchange := range rec.Exchanges {
+		assert.Equal(t, exchange.Database, got.Exchanges[i].Database, "expected database %q, got %q",
+			exchange.Database, got.Exchanges[i].Database)
+		// the raw bytes are only equal if all types were preserved
+		assert.True(t, bytes.Equal(exchange.Command, got.Exchanges[i].Command), "expected command %v, got %v",
+			exchange.Command, got.Exchanges[i].Command)
+		assert.True(t, bytes.Equal(exchange.Reply, got.Exchanges[i].Reply), "expected reply %v, got %v",
+			exchange.Reply, got.Exchanges[i].Reply)
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	ctx := context.Background()
+	rec := &Recording{
+		Exchanges: []Exchange{
+			{
+				Database: "db",
+				Command:  marshal(t, bson.D{{"find", "coll"}}),
+				Reply: marshal(t, bson.D{
+					{"cursor", bson.D{
+						{"id", int64(7)},
+						{"ns", "db.coll"},
+						{"firstBatch", bson.A{bson.D{{"_id", int32(1)}}}},
+					}},
+					{"ok", 1.0},
+				}),
+			},
+			{
+				Database: "db",
+				Command:  marshal(t, bson.D{{"getMore", int64(7)}, {"collection", "coll"}}),
+				Reply: marshal(t, bson.D{
+					{"cursor", bson.D{
+						{"id", int64(0)},
+						{"ns", "db.coll"},
+						{"nextBatch", bson.A{bson.D{{"_id", int32(2)}}}},
+					}},
+					{"ok", 1.0},
+				}),
+			},
+			{
+				Database: "db",
+				Command:  marshal(t, bson.D{{"count", "coll"}}),
+				Reply:    marshal(t, bson.D{{"n", int32(2)}, {"ok", 1.0}}),
+			},
+		},
+	}
+	replayer := NewReplayer(rec)
+	client, err := mongo.Connect(ctx, replayer.ClientOptions())
+	assert.Nil(t, err, "Connect error: %v", err)
+	defer func() { _ = client.Disconnect(ctx) }()
+	coll := client.Database("db").Collection("coll")
+
+	cursor, err := coll.Find(ctx, bson.D{{"x", 1}})
+	assert.Nil(t, err, "Find error: %v", err)
+	var docs []bson.D
+	err = cursor.All(ctx, &docs)
+	assert.Nil(t, err, "All error: %v", err)
+	expected := []bson.D{{{"_id", int32(1)}}, {{"_id", int32(2)}}}
+	assert.Equal(t, expected, docs, "expected documents %v, got %v", expected, docs)
+
+	remaining := replayer.Remaining()
+	assert.Equal(t, 1, len(remaining), "expected 1 remaining exchange, got %d", len(remaining))
+	assert.Equal(t, "count db.coll", remaining[0].String(), "expected remaining count, got %v", remaining[0])
+
+	// the find exchange has been replayed already
+	_, err = coll.Find(ctx, bson.D{})
+	assert.NotNil(t, err, "expected Find error, got nil")
+	unmatched := replayer.Unmatched()
+	assert.Equal(t, []string{"find db.coll"}, unmatched, "expected unmatched find, got %v", unmatched)
+}
+
+func TestProxy(t *testing.T) {
+	ctx := context.Background()
+	upstream := startUpstream(t)
+	defer func() { _ = upstream.Close() }()
+
+	proxy, err := StartProxy(upstream.Addr().String(), nil)
+	assert.Nil(t, err, "StartProxy error: %v", err)
+
+	opts := options.Client().SetHosts([]string{proxy.Addr()}).SetDirect(true).SetRetryWrites(false)
+	client, err := mongo.Connect(ctx, opts)
+	assert.Nil(t, err, "Connect error: %v", err)
+	coll := client.Database("db").Collection("coll")
+	_, err = coll.InsertOne(ctx, bson.D{{"_id", int64(1)}, {"x", "a"}})
+	assert.Nil(t, err, "InsertOne error: %v", err)
+	var found bson.D
+	err = coll.FindOne(ctx, bson.D{}).Decode(&found)
+	assert.Nil(t, err, "FindOne error: %v", err)
+	_ = client.Disconnect(ctx)
+	_ = proxy.Close()
+
+	rec := proxy.Recording()
+	assert.Equal(t, int32(9), rec.MaxWireVersion, "expected max wire version 9, got %v", rec.MaxWireVersion)
+	assert.Equal(t, int32(15), rec.SessionTimeoutMinutes, "expected session timeout 15, got %v",
+		rec.SessionTimeoutMinutes)
+	var keys []string
+	for _, exchange := range rec.Exchanges {
+		if exchange.CommandName() != "endSessions" {
+			keys = append(keys, exchange.String())
+		}
+	}
+	expected := []string{"insert db.coll", "find db.coll"}
+	assert.Equal(t, expected, keys, "expected recorded commands %v, got %v", expected, keys)
+	insert := rec.Exchanges[0].Command.Lookup("documents").Array().Index(0).Value().Document()
+	assert.Equal(t, "a", insert.Lookup("x").StringValue(), "expected inserted document in command, got %v", insert)
+
+	// replaying the recording produces the same results without the server
+	var buf bytes.Buffer
+	_, err = rec.WriteTo(&buf)
+	assert.Nil(t, err, "WriteTo error: %v", err)
+	rec, err = ReadRecording(&buf)
+	assert.Nil(t, err, "ReadRecording error: %v", err)
+
+	replayer := NewReplayer(rec)
+	client, err = mongo.Connect(ctx, replayer.ClientOptions())
+	assert.Nil(t, err, "Connect error: %v", err)
+	defer func() { _ = client.Disconnect(ctx) }()
+	coll = client.Database("db").Collection("coll")
+	_, err = coll.InsertOne(ctx, bson.D{{"_id", int64(1)}, {"x", "a"}})
+	assert.Nil(t, err, "InsertOne error: %v", err)
+	var replayed bson.D
+	err = coll.FindOne(ctx, bson.D{}).Decode(&replayed)
+	assert.Nil(t, err, "FindOne error: %v", err)
+	assert.Equal(t, found, replayed, "expected document %v, got %v", found, replayed)
+	assert.Equal(t, 0, len(replayer.Unmatched()), "expected no unmatched commands, got %v", replayer.Unmatched())
+}
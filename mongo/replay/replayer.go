@@ -0,0 +1,274 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+const (
+	replayAddress          = address.Address("replay:27017")
+	maxDocumentSize uint32 = 16777216
+	maxMessageSize  uint32 = 48000000
+	maxBatchCount   uint32 = 100000
+)
+
+var connectionID uint64
+
+// Replayer answers the commands of a client with the replies of a Recording. It is safe for concurrent use, but
+// exchanges with the same key are only replayed in a deterministic order if the client issues them sequentially.
+type Replayer struct {
+	recording *Recording
+
+	mu        sync.Mutex
+	used      []bool
+	unmatched []string
+}
+
+// NewReplayer creates a Replayer for rec.
+func NewReplayer(rec *Recording) *Replayer {
+	return &Replayer{
+		recording: rec,
+		used:      make([]bool, len(rec.Exchanges)),
+	}
+}
+
+// ClientOptions returns options that connect a client to r. Additional options can be merged into the returned
+// options, but options that configure the topology or servers, such as hosts, cannot be used together with them.
+func (r *Replayer) ClientOptions() *options.ClientOptions {
+	opts := options.Client()
+	opts.Deployment = &deployment{replayer: r}
+	return opts
+}
+
+// Remaining returns the exchanges that have not been replayed yet, in the order in which they were recorded.
+func (r *Replayer) Remaining() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var remaining []Exchange
+	for i, exchange := range r.recording.Exchanges {
+		if !r.used[i] {
+			remaining = append(remaining, exchange)
+		}
+	}
+	return remaining
+}
+
+// Unmatched returns the keys of the commands that the client sent but for which no recorded exchange was left, in the
+// order in which they were sent.
+func (r *Replayer) Unmatched() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.unmatched...)
+}
+
+func (r *Replayer) maxWireVersion() int32 {
+	if r.recording.MaxWireVersion == 0 {
+		return defaultMaxWireVersion
+	}
+	return r.recording.MaxWireVersion
+}
+
+func (r *Replayer) sessionTimeoutMinutes() uint32 {
+	if r.recording.SessionTimeoutMinutes == 0 {
+		return uint32(defaultSessionTimeoutMinutes)
+	}
+	return uint32(r.recording.SessionTimeoutMinutes)
+}
+
+// reply returns the reply for a command. Handshake commands are answered with a reply that advertises the recorded
+// capabilities. Other commands are answered with the reply of the first exchange with the same key that has not been
+// replayed yet, or with an error reply if there is none.
+func (r *Replayer) reply(db string, cmd bson.Raw) (bson.Raw, error) {
+	exchange := Exchange{Database: db, Command: cmd}
+	if unrecordedCommands[exchange.CommandName()] {
+		return bson.Marshal(bson.D{
+			{"ismaster", true},
+			{"helloOk", true},
+			{"maxBsonObjectSize", int32(maxDocumentSize)},
+			{"maxMessageSizeBytes", int32(maxMessageSize)},
+			{"maxWriteBatchSize", int32(maxBatchCount)},
+			{"logicalSessionTimeoutMinutes", int32(r.sessionTimeoutMinutes())},
+			{"minWireVersion", int32(0)},
+			{"maxWireVersion", r.maxWireVersion()},
+			{"ok", 1.0},
+		})
+	}
+
+	key := exchange.key()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, recorded := range r.recording.Exchanges {
+		if r.used[i] || recorded.key() != key {
+			continue
+		}
+		r.used[i] = true
+		return recorded.Reply, nil
+	}
+	r.unmatched = append(r.unmatched, key)
+	return bson.Marshal(bson.D{
+		{"ok", 0.0},
+		{"errmsg", fmt.Sprintf("no recorded reply for command %s", key)},
+	})
+}
+
+// deployment implements the driver.Deployment interface by handing out connections that answer commands from a
+// Replayer. No network connections are made.
+type deployment struct {
+	replayer *Replayer
+	once     sync.Once
+	updates  chan description.Topology
+}
+
+var _ driver.Deployment = &deployment{}
+var _ driver.Server = &deployment{}
+var _ driver.Connector = &deployment{}
+var _ driver.Disconnector = &deployment{}
+var _ driver.Subscriber = &deployment{}
+
+// SelectServer implements the driver.Deployment interface. It always returns the deployment itself.
+func (d *deployment) SelectServer(context.Context, description.ServerSelector) (driver.Server, error) {
+	return d, nil
+}
+
+// SupportsRetryWrites implements the driver.Deployment interface.
+func (d *deployment) SupportsRetryWrites() bool {
+	return true
+}
+
+// Kind implements the driver.Deployment interface. It always returns description.Single.
+func (d *deployment) Kind() description.TopologyKind {
+	return description.Single
+}
+
+// Connection implements the driver.Server interface.
+func (d *deployment) Connection(context.Context) (driver.Connection, error) {
+	return &connection{
+		replayer: d.replayer,
+		id:       fmt.Sprintf("replay[%d]", atomic.AddUint64(&connectionID, 1)),
+	}, nil
+}
+
+// Connect is a no-op method which implements the driver.Connector interface.
+func (d *deployment) Connect() error {
+	return nil
+}
+
+// Disconnect is a no-op method which implements the driver.Disconnector interface.
+func (d *deployment) Disconnect(context.Context) error {
+	return nil
+}
+
+// Subscribe implements the driver.Subscriber interface. The subscription receives a single topology description that
+// advertises support for sessions.
+func (d *deployment) Subscribe() (*driver.Subscription, error) {
+	d.once.Do(func() {
+		d.updates = make(chan description.Topology, 1)
+		d.updates <- description.Topology{
+			SessionTimeoutMinutes: d.replayer.sessionTimeoutMinutes(),
+		}
+	})
+	return &driver.Subscription{
+		Updates: d.updates,
+	}, nil
+}
+
+// Unsubscribe is a no-op method which implements the driver.Subscriber interface.
+func (d *deployment) Unsubscribe(*driver.Subscription) error {
+	return nil
+}
+
+// pendingReply is a reply that has not been read yet and the ID of the request that it answers.
+type pendingReply struct {
+	responseTo int32
+	reply      bson.Raw
+}
+
+// connection implements the driver.Connection interface. The reply for each command written to it is returned by the
+// next call to ReadWireMessage.
+type connection struct {
+	replayer *Replayer
+	id       string
+	replies  []pendingReply
+}
+
+var _ driver.Connection = &connection{}
+
+// WriteWireMessage implements the driver.Connection interface.
+func (c *connection) WriteWireMessage(_ context.Context, wm []byte) error {
+	msg, ok, err := decodeMessage(wm)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("replay: unsupported wire message")
+	}
+	reply, err := c.replayer.reply(msg.db, msg.document)
+	if err != nil {
+		return err
+	}
+	// unacknowledged writes do not have a reply
+	if msg.flags&wiremessage.MoreToCome == 0 && reply != nil {
+		c.replies = append(c.replies, pendingReply{responseTo: msg.requestID, reply: reply})
+	}
+	return nil
+}
+
+// ReadWireMessage implements the driver.Connection interface.
+func (c *connection) ReadWireMessage(_ context.Context, dst []byte) ([]byte, error) {
+	if len(c.replies) == 0 {
+		return dst, errors.New("replay: no reply available")
+	}
+	next := c.replies[0]
+	c.replies = c.replies[1:]
+	return appendReply(dst, next.responseTo, next.reply), nil
+}
+
+// Description implements the driver.Connection interface.
+func (c *connection) Description() description.Server {
+	return description.Server{
+		Addr:                  replayAddress,
+		CanonicalAddr:         replayAddress,
+		MaxDocumentSize:       maxDocumentSize,
+		MaxMessageSize:        maxMessageSize,
+		MaxBatchCount:         maxBatchCount,
+		SessionTimeoutMinutes: c.replayer.sessionTimeoutMinutes(),
+		Kind:                  description.RSPrimary,
+		WireVersion: &description.VersionRange{
+			Max: c.replayer.maxWireVersion(),
+		},
+	}
+}
+
+// Close is a no-op method which implements the driver.Connection interface.
+func (*connection) Close() error {
+	return nil
+}
+
+// ID implements the driver.Connection interface.
+func (c *connection) ID() string {
+	return c.id
+}
+
+// Address implements the driver.Connection interface.
+func (*connection) Address() address.Address {
+	return replayAddress
+}
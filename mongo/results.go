@@ -34,6 +34,13 @@ type BulkWriteResult struct {
 	UpsertedIDs map[int64]interface{}
 }
 
+// RewrapManyDataKeyResult is the result type returned by a ClientEncryption.RewrapManyDataKey operation.
+type RewrapManyDataKeyResult struct {
+	// The result of the bulk write that updated the re-encrypted data keys in the key vault collection. This is nil if
+	// no data keys matched the filter.
+	*BulkWriteResult
+}
+
 // InsertOneResult is the result type returned by an InsertOne operation.
 type InsertOneResult struct {
 	// The _id of the inserted document. A value generated by the driver will be of type primitive.ObjectID.
@@ -0,0 +1,735 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package search
+
+import (
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Operator is implemented by Atlas Search operators and collectors. SearchOperator returns the name of the operator
+// (e.g. "text") and its specification document, or an error if the operator is not valid.
+type Operator interface {
+	SearchOperator() (string, bson.D, error)
+}
+
+// Fuzzy specifies options for fuzzy matching in the text and autocomplete operators.
+type Fuzzy struct {
+	// The maximum number of single-character edits required to match the query. The value must be 1 or 2. The default
+	// value is 2.
+	MaxEdits *int32
+
+	// The number of characters at the beginning of each term that must exactly match. The default value is 0.
+	PrefixLength *int32
+
+	// The maximum number of variations to generate and search for. The default value is 50.
+	MaxExpansions *int32
+}
+
+func (f *Fuzzy) document() bson.D {
+	doc := bson.D{}
+	if f.MaxEdits != nil {
+		doc = append(doc, bson.E{"maxEdits", *f.MaxEdits})
+	}
+	if f.PrefixLength != nil {
+		doc = append(doc, bson.E{"prefixLength", *f.PrefixLength})
+	}
+	if f.MaxExpansions != nil {
+		doc = append(doc, bson.E{"maxExpansions", *f.MaxExpansions})
+	}
+	return doc
+}
+
+// TextOperator represents the text search operator. See
+// https://www.mongodb.com/docs/atlas/atlas-search/text/ for more information.
+type TextOperator struct {
+	// The string or strings to search for. This field is required.
+	Query []string
+
+	// The indexed field or fields to search. This must be a string, a []string, or a wildcard document. This field is
+	// required.
+	Path interface{}
+
+	// Options for fuzzy matching. Fuzzy cannot be used together with Synonyms.
+	Fuzzy *Fuzzy
+
+	// The name of a synonym mapping definition in the index to use.
+	Synonyms *string
+
+	// A document used to modify the score of matching documents (e.g. bson.D{{"boost", bson.D{{"value", 3}}}}).
+	Score interface{}
+}
+
+// Text creates a new TextOperator that searches the given path for any of the given query strings.
+func Text(path interface{}, query ...string) *TextOperator {
+	return &TextOperator{Path: path, Query: query}
+}
+
+// SetFuzzy sets the value for the Fuzzy field.
+func (t *TextOperator) SetFuzzy(f *Fuzzy) *TextOperator {
+	t.Fuzzy = f
+	return t
+}
+
+// SetSynonyms sets the value for the Synonyms field.
+func (t *TextOperator) SetSynonyms(s string) *TextOperator {
+	t.Synonyms = &s
+	return t
+}
+
+// SetScore sets the value for the Score field.
+func (t *TextOperator) SetScore(score interface{}) *TextOperator {
+	t.Score = score
+	return t
+}
+
+// SearchOperator implements the Operator interface.
+func (t *TextOperator) SearchOperator() (string, bson.D, error) {
+	if len(t.Query) == 0 {
+		return "", nil, errors.New("text query must be specified")
+	}
+	if err := validatePath(t.Path); err != nil {
+		return "", nil, err
+	}
+	if t.Fuzzy != nil && t.Synonyms != nil {
+		return "", nil, errors.New("text fuzzy and synonyms cannot both be specified")
+	}
+
+	spec := bson.D{{"query", queryValue(t.Query)}, {"path", t.Path}}
+	if t.Fuzzy != nil {
+		spec = append(spec, bson.E{"fuzzy", t.Fuzzy.document()})
+	}
+	if t.Synonyms != nil {
+		spec = append(spec, bson.E{"synonyms", *t.Synonyms})
+	}
+	if t.Score != nil {
+		spec = append(spec, bson.E{"score", t.Score})
+	}
+	return "text", spec, nil
+}
+
+// These constants are the valid values for the AutocompleteOperator TokenOrder field.
+const (
+	TokenOrderAny        = "any"
+	TokenOrderSequential = "sequential"
+)
+
+// AutocompleteOperator represents the autocomplete search operator. See
+// https://www.mongodb.com/docs/atlas/atlas-search/autocomplete/ for more information.
+type AutocompleteOperator struct {
+	// The string or strings to search for. This field is required.
+	Query []string
+
+	// The indexed autocomplete type field to search. This field is required.
+	Path string
+
+	// The order in which to search for tokens. This must be TokenOrderAny or TokenOrderSequential. The default value
+	// is TokenOrderAny.
+	TokenOrder *string
+
+	// Options for fuzzy matching.
+	Fuzzy *Fuzzy
+
+	// A document used to modify the score of matching documents.
+	Score interface{}
+}
+
+// Autocomplete creates a new AutocompleteOperator that searches the given path for any of the given query strings.
+func Autocomplete(path string, query ...string) *AutocompleteOperator {
+	return &AutocompleteOperator{Path: path, Query: query}
+}
+
+// SetTokenOrder sets the value for the TokenOrder field.
+func (a *AutocompleteOperator) SetTokenOrder(order string) *AutocompleteOperator {
+	a.TokenOrder = &order
+	return a
+}
+
+// SetFuzzy sets the value for the Fuzzy field.
+func (a *AutocompleteOperator) SetFuzzy(f *Fuzzy) *AutocompleteOperator {
+	a.Fuzzy = f
+	return a
+}
+
+// SetScore sets the value for the Score field.
+func (a *AutocompleteOperator) SetScore(score interface{}) *AutocompleteOperator {
+	a.Score = score
+	return a
+}
+
+// SearchOperator implements the Operator interface.
+func (a *AutocompleteOperator) SearchOperator() (string, bson.D, error) {
+	if len(a.Query) == 0 {
+		return "", nil, errors.New("autocomplete query must be specified")
+	}
+	if a.Path == "" {
+		return "", nil, errors.New("autocomplete path must be specified")
+	}
+	if a.TokenOrder != nil && *a.TokenOrder != TokenOrderAny && *a.TokenOrder != TokenOrderSequential {
+		return "", nil, fmt.Errorf("autocomplete tokenOrder must be %q or %q, got %q", TokenOrderAny,
+			TokenOrderSequential, *a.TokenOrder)
+	}
+
+	spec := bson.D{{"query", queryValue(a.Query)}, {"path", a.Path}}
+	if a.TokenOrder != nil {
+		spec = append(spec, bson.E{"tokenOrder", *a.TokenOrder})
+	}
+	if a.Fuzzy != nil {
+		spec = append(spec, bson.E{"fuzzy", a.Fuzzy.document()})
+	}
+	if a.Score != nil {
+		spec = append(spec, bson.E{"score", a.Score})
+	}
+	return "autocomplete", spec, nil
+}
+
+// RangeOperator represents the range search operator, which matches numeric and date values within a range. See
+// https://www.mongodb.com/docs/atlas/atlas-search/range/ for more information.
+type RangeOperator struct {
+	// The indexed field or fields to search. This field is required.
+	Path interface{}
+
+	// The exclusive lower bound. Gt and Gte cannot both be specified.
+	Gt interface{}
+
+	// The inclusive lower bound. Gt and Gte cannot both be specified.
+	Gte interface{}
+
+	// The exclusive upper bound. Lt and Lte cannot both be specified.
+	Lt interface{}
+
+	// The inclusive upper bound. Lt and Lte cannot both be specified.
+	Lte interface{}
+
+	// A document used to modify the score of matching documents.
+	Score interface{}
+}
+
+// Range creates a new RangeOperator for the given path. At least one bound must be set before the operator is used.
+func Range(path interface{}) *RangeOperator {
+	return &RangeOperator{Path: path}
+}
+
+// SetGt sets the value for the Gt field.
+func (r *RangeOperator) SetGt(v interface{}) *RangeOperator {
+	r.Gt = v
+	return r
+}
+
+// SetGte sets the value for the Gte field.
+func (r *RangeOperator) SetGte(v interface{}) *RangeOperator {
+	r.Gte = v
+	return r
+}
+
+// SetLt sets the value for the Lt field.
+func (r *RangeOperator) SetLt(v interface{}) *RangeOperator {
+	r.Lt = v
+	return r
+}
+
+// SetLte sets the value for the Lte field.
+func (r *RangeOperator) SetLte(v interface{}) *RangeOperator {
+	r.Lte = v
+	return r
+}
+
+// SetScore sets the value for the Score field.
+func (r *RangeOperator) SetScore(score interface{}) *RangeOperator {
+	r.Score = score
+	return r
+}
+
+// SearchOperator implements the Operator interface.
+func (r *RangeOperator) SearchOperator() (string, bson.D, error) {
+	if err := validatePath(r.Path); err != nil {
+		return "", nil, err
+	}
+	if r.Gt != nil && r.Gte != nil {
+		return "", nil, errors.New("range gt and gte cannot both be specified")
+	}
+	if r.Lt != nil && r.Lte != nil {
+		return "", nil, errors.New("range lt and lte cannot both be specified")
+	}
+	if r.Gt == nil && r.Gte == nil && r.Lt == nil && r.Lte == nil {
+		return "", nil, errors.New("range must specify at least one bound")
+	}
+
+	spec := bson.D{{"path", r.Path}}
+	for _, bound := range []bson.E{{"gt", r.Gt}, {"gte", r.Gte}, {"lt", r.Lt}, {"lte", r.Lte}} {
+		if bound.Value != nil {
+			spec = append(spec, bound)
+		}
+	}
+	if r.Score != nil {
+		spec = append(spec, bson.E{"score", r.Score})
+	}
+	return "range", spec, nil
+}
+
+// CompoundOperator represents the compound search operator, which combines other operators into a single query. See
+// https://www.mongodb.com/docs/atlas/atlas-search/compound/ for more information.
+type CompoundOperator struct {
+	// Clauses that must match for a document to be included in the results.
+	Must []Operator
+
+	// Clauses that must not match for a document to be included in the results.
+	MustNot []Operator
+
+	// Clauses that should match. Documents that match more should clauses are scored higher.
+	Should []Operator
+
+	// Clauses that must match but do not contribute to the score of matching documents.
+	Filter []Operator
+
+	// The minimum number of Should clauses that must match for a document to be included in the results.
+	MinimumShouldMatch *int32
+
+	// A document used to modify the score of matching documents.
+	Score interface{}
+}
+
+// Compound creates a new empty CompoundOperator. At least one clause must be added before the operator is used.
+func Compound() *CompoundOperator {
+	return &CompoundOperator{}
+}
+
+// AddMust appends the given operators to the Must field.
+func (c *CompoundOperator) AddMust(ops ...Operator) *CompoundOperator {
+	c.Must = append(c.Must, ops...)
+	return c
+}
+
+// AddMustNot appends the given operators to the MustNot field.
+func (c *CompoundOperator) AddMustNot(ops ...Operator) *CompoundOperator {
+	c.MustNot = append(c.MustNot, ops...)
+	return c
+}
+
+// AddShould appends the given operators to the Should field.
+func (c *CompoundOperator) AddShould(ops ...Operator) *CompoundOperator {
+	c.Should = append(c.Should, ops...)
+	return c
+}
+
+// AddFilter appends the given operators to the Filter field.
+func (c *CompoundOperator) AddFilter(ops ...Operator) *CompoundOperator {
+	c.Filter = append(c.Filter, ops...)
+	return c
+}
+
+// SetMinimumShouldMatch sets the value for the MinimumShouldMatch field.
+func (c *CompoundOperator) SetMinimumShouldMatch(i int32) *CompoundOperator {
+	c.MinimumShouldMatch = &i
+	return c
+}
+
+// SetScore sets the value for the Score field.
+func (c *CompoundOperator) SetScore(score interface{}) *CompoundOperator {
+	c.Score = score
+	return c
+}
+
+// SearchOperator implements the Operator interface.
+func (c *CompoundOperator) SearchOperator() (string, bson.D, error) {
+	if len(c.Must)+len(c.MustNot)+len(c.Should)+len(c.Filter) == 0 {
+		return "", nil, errors.New("compound must specify at least one clause")
+	}
+	if c.MinimumShouldMatch != nil && int(*c.MinimumShouldMatch) > len(c.Should) {
+		return "", nil, fmt.Errorf("compound minimumShouldMatch (%d) cannot exceed the number of should clauses (%d)",
+			*c.MinimumShouldMatch, len(c.Should))
+	}
+
+	spec := bson.D{}
+	clauses := []struct {
+		name string
+		ops  []Operator
+	}{
+		{"must", c.Must},
+		{"mustNot", c.MustNot},
+		{"should", c.Should},
+		{"filter", c.Filter},
+	}
+	for _, clause := range clauses {
+		if len(clause.ops) == 0 {
+			continue
+		}
+		arr := make(bson.A, 0, len(clause.ops))
+		for _, op := range clause.ops {
+			doc, err := operatorDocument(op)
+			if err != nil {
+				return "", nil, err
+			}
+			arr = append(arr, doc)
+		}
+		spec = append(spec, bson.E{clause.name, arr})
+	}
+	if c.MinimumShouldMatch != nil {
+		spec = append(spec, bson.E{"minimumShouldMatch", *c.MinimumShouldMatch})
+	}
+	if c.Score != nil {
+		spec = append(spec, bson.E{"score", c.Score})
+	}
+	return "compound", spec, nil
+}
+
+// Facet is implemented by the facet definitions that can be used with a FacetCollector.
+type Facet interface {
+	// FacetName returns the name of the facet. This is the key of the facet in the collector and in the results.
+	FacetName() string
+
+	// FacetDefinition returns the facet definition document.
+	FacetDefinition() (bson.D, error)
+}
+
+// StringFacet represents a facet that groups string values into buckets.
+type StringFacet struct {
+	Name       string
+	Path       string
+	NumBuckets *int32
+}
+
+// FacetName implements the Facet interface.
+func (sf *StringFacet) FacetName() string { return sf.Name }
+
+// FacetDefinition implements the Facet interface.
+func (sf *StringFacet) FacetDefinition() (bson.D, error) {
+	if sf.Path == "" {
+		return nil, fmt.Errorf("string facet %q path must be specified", sf.Name)
+	}
+	def := bson.D{{"type", "string"}, {"path", sf.Path}}
+	if sf.NumBuckets != nil {
+		def = append(def, bson.E{"numBuckets", *sf.NumBuckets})
+	}
+	return def, nil
+}
+
+// BoundariesFacet represents a number or date facet that groups values into buckets using the given boundaries.
+type BoundariesFacet struct {
+	Name string
+	Path string
+
+	// The type of facet. This must be "number" or "date".
+	Type string
+
+	// The boundaries of the buckets, in ascending order. At least two boundaries must be specified.
+	Boundaries []interface{}
+
+	// The name of the bucket for values outside of the boundaries. The default is nil, which means those values are
+	// not counted.
+	Default *string
+}
+
+// FacetName implements the Facet interface.
+func (bf *BoundariesFacet) FacetName() string { return bf.Name }
+
+// FacetDefinition implements the Facet interface.
+func (bf *BoundariesFacet) FacetDefinition() (bson.D, error) {
+	if bf.Type != "number" && bf.Type != "date" {
+		return nil, fmt.Errorf("facet %q type must be \"number\" or \"date\", got %q", bf.Name, bf.Type)
+	}
+	if bf.Path == "" {
+		return nil, fmt.Errorf("facet %q path must be specified", bf.Name)
+	}
+	if len(bf.Boundaries) < 2 {
+		return nil, fmt.Errorf("facet %q must specify at least two boundaries", bf.Name)
+	}
+	def := bson.D{{"type", bf.Type}, {"path", bf.Path}, {"boundaries", bson.A(bf.Boundaries)}}
+	if bf.Default != nil {
+		def = append(def, bson.E{"default", *bf.Default})
+	}
+	return def, nil
+}
+
+// StringFacetOf creates a new StringFacet with the given name and path.
+func StringFacetOf(name, path string) *StringFacet {
+	return &StringFacet{Name: name, Path: path}
+}
+
+// NumberFacetOf creates a new BoundariesFacet for numeric values with the given name, path, and boundaries.
+func NumberFacetOf(name, path string, boundaries ...interface{}) *BoundariesFacet {
+	return &BoundariesFacet{Name: name, Path: path, Type: "number", Boundaries: boundaries}
+}
+
+// DateFacetOf creates a new BoundariesFacet for date values with the given name, path, and boundaries.
+func DateFacetOf(name, path string, boundaries ...interface{}) *BoundariesFacet {
+	return &BoundariesFacet{Name: name, Path: path, Type: "date", Boundaries: boundaries}
+}
+
+// FacetCollector represents the facet collector, which groups the results of an operator by the values of the
+// specified facets. The facet collector can be used in place of an operator in a $search or $searchMeta stage. See
+// https://www.mongodb.com/docs/atlas/atlas-search/facet/ for more information.
+type FacetCollector struct {
+	// The operator used to select the documents to facet. The default value is nil, which means all documents are
+	// faceted.
+	Operator Operator
+
+	// The facets to compute. At least one facet must be specified.
+	Facets []Facet
+}
+
+// FacetOver creates a new FacetCollector that groups the documents selected by op using the given facets.
+func FacetOver(op Operator, facets ...Facet) *FacetCollector {
+	return &FacetCollector{Operator: op, Facets: facets}
+}
+
+// SearchOperator implements the Operator interface.
+func (fc *FacetCollector) SearchOperator() (string, bson.D, error) {
+	if len(fc.Facets) == 0 {
+		return "", nil, errors.New("facet must specify at least one facet")
+	}
+
+	spec := bson.D{}
+	if fc.Operator != nil {
+		doc, err := operatorDocument(fc.Operator)
+		if err != nil {
+			return "", nil, err
+		}
+		spec = append(spec, bson.E{"operator", doc})
+	}
+	facets := make(bson.D, 0, len(fc.Facets))
+	for _, f := range fc.Facets {
+		if f.FacetName() == "" {
+			return "", nil, errors.New("facet name must be specified")
+		}
+		def, err := f.FacetDefinition()
+		if err != nil {
+			return "", nil, err
+		}
+		facets = append(facets, bson.E{f.FacetName(), def})
+	}
+	spec = append(spec, bson.E{"facets", facets})
+	return "facet", spec, nil
+}
+
+// Highlight specifies options for returning highlighted snippets of the matching text. Highlighting can only be used
+// with a $search stage.
+type Highlight struct {
+	// The field or fields to highlight. This field is required.
+	Path interface{}
+
+	// The maximum number of characters to examine in each document. The default value is 500,000.
+	MaxCharsToExamine *int32
+
+	// The maximum number of high-scoring passages to return per field. The default value is 5.
+	MaxNumPassages *int32
+}
+
+// These constants are the valid values for the Count Type field.
+const (
+	CountLowerBound = "lowerBound"
+	CountTotal      = "total"
+)
+
+// Count specifies options for counting the documents that match the query.
+type Count struct {
+	// The type of count. This must be CountLowerBound or CountTotal. The default value is CountLowerBound.
+	Type *string
+
+	// The number of documents to count exactly when Type is CountLowerBound. The default value is 1000.
+	Threshold *int32
+}
+
+// Stage represents a $search or $searchMeta aggregation stage. See
+// https://www.mongodb.com/docs/atlas/atlas-search/query-syntax/ for more information.
+type Stage struct {
+	// The name of the Atlas Search index to use. The default is nil, which means the index named "default" is used.
+	Index *string
+
+	// The operator or collector to run. This field is required.
+	Operator Operator
+
+	// Options for highlighting matched text. This can only be used with a $search stage.
+	Highlight *Highlight
+
+	// Options for counting matching documents.
+	Count *Count
+
+	// If true, the search returns only the fields stored in the index. The default value is false. This can only be
+	// used with a $search stage.
+	ReturnStoredSource *bool
+
+	meta bool
+}
+
+// Search creates a new Stage that runs op in a $search stage.
+func Search(op Operator) *Stage {
+	return &Stage{Operator: op}
+}
+
+// SearchMeta creates a new Stage that runs op in a $searchMeta stage. The results of the stage can be decoded into a
+// MetaResult.
+func SearchMeta(op Operator) *Stage {
+	return &Stage{Operator: op, meta: true}
+}
+
+// SetIndex sets the value for the Index field.
+func (s *Stage) SetIndex(index string) *Stage {
+	s.Index = &index
+	return s
+}
+
+// SetHighlight sets the value for the Highlight field.
+func (s *Stage) SetHighlight(h *Highlight) *Stage {
+	s.Highlight = h
+	return s
+}
+
+// SetCount sets the value for the Count field.
+func (s *Stage) SetCount(c *Count) *Stage {
+	s.Count = c
+	return s
+}
+
+// SetReturnStoredSource sets the value for the ReturnStoredSource field.
+func (s *Stage) SetReturnStoredSource(b bool) *Stage {
+	s.ReturnStoredSource = &b
+	return s
+}
+
+// Stage validates the stage and returns it as a document that can be used as an element of a mongo.Pipeline.
+func (s *Stage) Stage() (bson.D, error) {
+	name := "$search"
+	if s.meta {
+		name = "$searchMeta"
+	}
+	if s.Operator == nil {
+		return nil, fmt.Errorf("%s operator must be specified", name)
+	}
+	if s.meta && s.Highlight != nil {
+		return nil, errors.New("$searchMeta does not support highlight")
+	}
+	if s.meta && s.ReturnStoredSource != nil {
+		return nil, errors.New("$searchMeta does not support returnStoredSource")
+	}
+
+	spec := bson.D{}
+	if s.Index != nil {
+		spec = append(spec, bson.E{"index", *s.Index})
+	}
+	opName, opSpec, err := s.Operator.SearchOperator()
+	if err != nil {
+		return nil, err
+	}
+	spec = append(spec, bson.E{opName, opSpec})
+
+	if s.Highlight != nil {
+		if err := validatePath(s.Highlight.Path); err != nil {
+			return nil, err
+		}
+		hl := bson.D{{"path", s.Highlight.Path}}
+		if s.Highlight.MaxCharsToExamine != nil {
+			hl = append(hl, bson.E{"maxCharsToExamine", *s.Highlight.MaxCharsToExamine})
+		}
+		if s.Highlight.MaxNumPassages != nil {
+			hl = append(hl, bson.E{"maxNumPassages", *s.Highlight.MaxNumPassages})
+		}
+		spec = append(spec, bson.E{"highlight", hl})
+	}
+	if s.Count != nil {
+		count := bson.D{}
+		if s.Count.Type != nil {
+			if *s.Count.Type != CountLowerBound && *s.Count.Type != CountTotal {
+				return nil, fmt.Errorf("count type must be %q or %q, got %q", CountLowerBound, CountTotal,
+					*s.Count.Type)
+			}
+			count = append(count, bson.E{"type", *s.Count.Type})
+		}
+		if s.Count.Threshold != nil {
+			count = append(count, bson.E{"threshold", *s.Count.Threshold})
+		}
+		spec = append(spec, bson.E{"count", count})
+	}
+	if s.ReturnStoredSource != nil {
+		spec = append(spec, bson.E{"returnStoredSource", *s.ReturnStoredSource})
+	}
+	return bson.D{{name, spec}}, nil
+}
+
+// MarshalBSON implements the bson.Marshaler interface so a Stage can be used directly as a pipeline stage.
+func (s *Stage) MarshalBSON() ([]byte, error) {
+	stage, err := s.Stage()
+	if err != nil {
+		return nil, err
+	}
+	return bson.Marshal(stage)
+}
+
+// MetaResult is the document returned by a $searchMeta stage. It can also be decoded from the $$SEARCH_META variable
+// after a $search stage.
+type MetaResult struct {
+	Count *MetaCount             `bson:"count,omitempty"`
+	Facet map[string]FacetResult `bson:"facet,omitempty"`
+}
+
+// MetaCount contains the count of matching documents. Only the field corresponding to the requested count type is
+// set.
+type MetaCount struct {
+	LowerBound *int64 `bson:"lowerBound,omitempty"`
+	Total      *int64 `bson:"total,omitempty"`
+}
+
+// FacetResult contains the buckets computed for a facet.
+type FacetResult struct {
+	Buckets []FacetBucket `bson:"buckets"`
+}
+
+// FacetBucket represents a single facet bucket. ID is the bucket value for string facets and the lower boundary
+// (or the default bucket name) for number and date facets.
+type FacetBucket struct {
+	ID    interface{} `bson:"_id"`
+	Count int64       `bson:"count"`
+}
+
+// HighlightResult represents a single highlight entry returned by the {$meta: "searchHighlights"} projection.
+type HighlightResult struct {
+	Path  string          `bson:"path"`
+	Texts []HighlightText `bson:"texts"`
+	Score float64         `bson:"score"`
+}
+
+// HighlightText is a section of highlighted text. Type is "hit" if the text matched the query and "text" otherwise.
+type HighlightText struct {
+	Value string `bson:"value"`
+	Type  string `bson:"type"`
+}
+
+func operatorDocument(op Operator) (bson.D, error) {
+	if op == nil {
+		return nil, errors.New("operator must not be nil")
+	}
+	name, spec, err := op.SearchOperator()
+	if err != nil {
+		return nil, err
+	}
+	return bson.D{{name, spec}}, nil
+}
+
+func queryValue(query []string) interface{} {
+	if len(query) == 1 {
+		return query[0]
+	}
+	return query
+}
+
+func validatePath(path interface{}) error {
+	switch p := path.(type) {
+	case nil:
+		return errors.New("search path must be specified")
+	case string:
+		if p == "" {
+			return errors.New("search path must not be empty")
+		}
+	case []string:
+		if len(p) == 0 {
+			return errors.New("search path must not be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package search
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+func TestSearchStage(t *testing.T) {
+	t.Run("compound with highlight", func(t *testing.T) {
+		maxEdits := int32(1)
+		stage, err := Search(
+			Compound().
+				AddMust(Text("title", "baseball").SetFuzzy(&Fuzzy{MaxEdits: &maxEdits})).
+				AddFilter(Range("year").SetGte(1990).SetLt(2000)),
+		).SetIndex("movies").SetHighlight(&Highlight{Path: "title"}).Stage()
+		assert.Nil(t, err, "Stage error: %v", err)
+
+		expected := bson.D{{"$search", bson.D{
+			{"index", "movies"},
+			{"compound", bson.D{
+				{"must", bson.A{bson.D{{"text", bson.D{
+					{"query", "baseball"},
+					{"path", "title"},
+					{"fuzzy", bson.D{{"maxEdits", int32(1)}}},
+				}}}}},
+				{"filter", bson.A{bson.D{{"range", bson.D{
+					{"path", "year"},
+					{"gte", 1990},
+					{"lt", 2000},
+				}}}}},
+			}},
+			{"highlight", bson.D{{"path", "title"}}},
+		}}}
+		assert.Equal(t, expected, stage, "expected stage %v, got %v", expected, stage)
+	})
+	t.Run("searchMeta facet", func(t *testing.T) {
+		countType := CountTotal
+		stage, err := SearchMeta(FacetOver(
+			Autocomplete("title", "star").SetTokenOrder(TokenOrderSequential),
+			StringFacetOf("genres", "genres"),
+			NumberFacetOf("years", "year", 1980, 1990, 2000),
+		)).SetCount(&Count{Type: &countType}).Stage()
+		assert.Nil(t, err, "Stage error: %v", err)
+
+		expected := bson.D{{"$searchMeta", bson.D{
+			{"facet", bson.D{
+				{"operator", bson.D{{"autocomplete", bson.D{
+					{"query", "star"},
+					{"path", "title"},
+					{"tokenOrder", "sequential"},
+				}}}},
+				{"facets", bson.D{
+					{"genres", bson.D{{"type", "string"}, {"path", "genres"}}},
+					{"years", bson.D{{"type", "number"}, {"path", "year"}, {"boundaries", bson.A{1980, 1990, 2000}}}},
+				}},
+			}},
+			{"count", bson.D{{"type", "total"}}},
+		}}}
+		assert.Equal(t, expected, stage, "expected stage %v, got %v", expected, stage)
+	})
+	t.Run("decode meta result", func(t *testing.T) {
+		doc, err := bson.Marshal(bson.D{
+			{"count", bson.D{{"total", int64(42)}}},
+			{"facet", bson.D{{"genres", bson.D{{"buckets", bson.A{
+				bson.D{{"_id", "Drama"}, {"count", int64(30)}},
+			}}}}}},
+		})
+		assert.Nil(t, err, "Marshal error: %v", err)
+
+		var res MetaResult
+		err = bson.Unmarshal(doc, &res)
+		assert.Nil(t, err, "Unmarshal error: %v", err)
+		assert.NotNil(t, res.Count, "expected count to be set")
+		assert.Equal(t, int64(42), *res.Count.Total, "expected total 42, got %v", *res.Count.Total)
+		assert.Equal(t, 1, len(res.Facet["genres"].Buckets), "expected 1 bucket, got %v", len(res.Facet["genres"].Buckets))
+		assert.Equal(t, "Drama", res.Facet["genres"].Buckets[0].ID, "expected bucket Drama, got %v",
+			res.Facet["genres"].Buckets[0].ID)
+	})
+	t.Run("validation", func(t *testing.T) {
+		testCases := []struct {
+			name  string
+			stage *Stage
+		}{
+			{"missing operator", Search(nil)},
+			{"empty text query", Search(Text("title"))},
+			{"fuzzy with synonyms", Search(Text("title", "a").SetFuzzy(&Fuzzy{}).SetSynonyms("s"))},
+			{"invalid token order", Search(Autocomplete("title", "a").SetTokenOrder("random"))},
+			{"range without bounds", Search(Range("year"))},
+			{"range gt and gte", Search(Range("year").SetGt(1).SetGte(1))},
+			{"empty compound", Search(Compound())},
+			{"facet without facets", SearchMeta(FacetOver(nil))},
+			{"highlight with searchMeta", SearchMeta(Text("t", "a")).SetHighlight(&Highlight{Path: "t"})},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := tc.stage.Stage()
+				assert.NotNil(t, err, "expected error, got nil")
+			})
+		}
+	})
+}
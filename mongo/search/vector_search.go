@@ -0,0 +1,173 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package search contains builders for the Atlas Search and Atlas Vector Search aggregation stages. The stages built by
+// this package can be used as elements of a mongo.Pipeline or passed directly to Collection.Aggregate as part of a
+// slice of pipeline stages.
+package search // import "go.mongodb.org/mongo-driver/mongo/search"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxNumCandidates is the maximum value for the numCandidates field supported by the server.
+const maxNumCandidates = 10000
+
+// VectorSearchStage represents a $vectorSearch aggregation stage. See
+// https://www.mongodb.com/docs/atlas/atlas-vector-search/vector-search-stage/ for more information.
+type VectorSearchStage struct {
+	// The name of the Atlas Vector Search index to use. This field is required.
+	Index string
+
+	// The indexed vector type field to search. This field is required.
+	Path string
+
+	// The vector to use as the query. This must be a primitive.Vector, a primitive.Binary with subtype
+	// bsontype.BinaryVector, or a non-empty []float64, []float32, or []int8. This field is required.
+	QueryVector interface{}
+
+	// The number of nearest neighbors to use during the search. This must be greater than or equal to Limit and less
+	// than or equal to 10000. This field is required unless Exact is true, in which case it must not be set.
+	NumCandidates *int64
+
+	// The number of documents to return in the results. This must be greater than 0.
+	Limit int64
+
+	// A filter document that uses match query operators on indexed fields to pre-filter the documents to search. The
+	// default value is nil, which means that no pre-filter will be applied.
+	Filter interface{}
+
+	// If true, an exact nearest neighbor search will be run instead of an approximate one. The default value is
+	// false.
+	Exact *bool
+}
+
+// VectorSearch creates a new VectorSearchStage with the required fields set.
+func VectorSearch(index, path string, queryVector interface{}, limit int64) *VectorSearchStage {
+	return &VectorSearchStage{
+		Index:       index,
+		Path:        path,
+		QueryVector: queryVector,
+		Limit:       limit,
+	}
+}
+
+// SetNumCandidates sets the value for the NumCandidates field.
+func (vs *VectorSearchStage) SetNumCandidates(n int64) *VectorSearchStage {
+	vs.NumCandidates = &n
+	return vs
+}
+
+// SetFilter sets the value for the Filter field.
+func (vs *VectorSearchStage) SetFilter(filter interface{}) *VectorSearchStage {
+	vs.Filter = filter
+	return vs
+}
+
+// SetExact sets the value for the Exact field.
+func (vs *VectorSearchStage) SetExact(b bool) *VectorSearchStage {
+	vs.Exact = &b
+	return vs
+}
+
+// Validate returns an error if the stage is missing required fields or if any of its fields contain invalid values.
+func (vs *VectorSearchStage) Validate() error {
+	if vs.Index == "" {
+		return errors.New("$vectorSearch index must be specified")
+	}
+	if vs.Path == "" {
+		return errors.New("$vectorSearch path must be specified")
+	}
+	if err := validateQueryVector(vs.QueryVector); err != nil {
+		return err
+	}
+	if vs.Limit <= 0 {
+		return fmt.Errorf("$vectorSearch limit must be greater than 0, got %d", vs.Limit)
+	}
+
+	exact := vs.Exact != nil && *vs.Exact
+	switch {
+	case exact && vs.NumCandidates != nil:
+		return errors.New("$vectorSearch numCandidates cannot be specified for an exact search")
+	case !exact && vs.NumCandidates == nil:
+		return errors.New("$vectorSearch numCandidates must be specified for an approximate search")
+	case vs.NumCandidates != nil && (*vs.NumCandidates < vs.Limit || *vs.NumCandidates > maxNumCandidates):
+		return fmt.Errorf("$vectorSearch numCandidates must be between limit (%d) and %d, got %d", vs.Limit,
+			maxNumCandidates, *vs.NumCandidates)
+	}
+	return nil
+}
+
+// Stage validates the stage and returns it as a document that can be used as an element of a mongo.Pipeline.
+func (vs *VectorSearchStage) Stage() (bson.D, error) {
+	if err := vs.Validate(); err != nil {
+		return nil, err
+	}
+
+	spec := bson.D{
+		{"index", vs.Index},
+		{"path", vs.Path},
+		{"queryVector", vs.QueryVector},
+	}
+	if vs.NumCandidates != nil {
+		spec = append(spec, bson.E{"numCandidates", *vs.NumCandidates})
+	}
+	spec = append(spec, bson.E{"limit", vs.Limit})
+	if vs.Filter != nil {
+		spec = append(spec, bson.E{"filter", vs.Filter})
+	}
+	if vs.Exact != nil {
+		spec = append(spec, bson.E{"exact", *vs.Exact})
+	}
+	return bson.D{{"$vectorSearch", spec}}, nil
+}
+
+// MarshalBSON implements the bson.Marshaler interface so a VectorSearchStage can be used directly as a pipeline stage.
+func (vs *VectorSearchStage) MarshalBSON() ([]byte, error) {
+	stage, err := vs.Stage()
+	if err != nil {
+		return nil, err
+	}
+	return bson.Marshal(stage)
+}
+
+func validateQueryVector(qv interface{}) error {
+	var length int
+	switch vec := qv.(type) {
+	case nil:
+		return errors.New("$vectorSearch queryVector must be specified")
+	case primitive.Vector:
+		if vec.IsZero() {
+			return errors.New("$vectorSearch queryVector must not be a zero Vector")
+		}
+		return nil
+	case primitive.Binary:
+		if vec.Subtype != bsontype.BinaryVector {
+			return fmt.Errorf("$vectorSearch queryVector binary must have subtype %#x, got %#x", bsontype.BinaryVector,
+				vec.Subtype)
+		}
+		_, err := primitive.NewVectorFromBinary(vec)
+		return err
+	case []float64:
+		length = len(vec)
+	case []float32:
+		length = len(vec)
+	case []int8:
+		length = len(vec)
+	default:
+		return fmt.Errorf("$vectorSearch queryVector must be a vector or numeric slice, got %T", qv)
+	}
+
+	if length == 0 {
+		return errors.New("$vectorSearch queryVector must not be empty")
+	}
+	return nil
+}
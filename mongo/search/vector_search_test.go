@@ -0,0 +1,71 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package search
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+func TestVectorSearchStage(t *testing.T) {
+	t.Run("stage document", func(t *testing.T) {
+		vec := primitive.NewFloat32Vector([]float32{0.5, 0.25})
+		stage, err := VectorSearch("vector_index", "embedding", vec, 5).
+			SetNumCandidates(100).
+			SetFilter(bson.D{{"year", bson.D{{"$gt", 1995}}}}).
+			Stage()
+		assert.Nil(t, err, "Stage error: %v", err)
+
+		expected := bson.D{{"$vectorSearch", bson.D{
+			{"index", "vector_index"},
+			{"path", "embedding"},
+			{"queryVector", vec},
+			{"numCandidates", int64(100)},
+			{"limit", int64(5)},
+			{"filter", bson.D{{"year", bson.D{{"$gt", 1995}}}}},
+		}}}
+		assert.Equal(t, expected, stage, "expected stage %v, got %v", expected, stage)
+	})
+	t.Run("marshals as pipeline stage", func(t *testing.T) {
+		pipeline := []interface{}{
+			VectorSearch("idx", "embedding", []float64{1, 2, 3}, 1).SetExact(true),
+		}
+		b, err := bson.Marshal(bson.D{{"pipeline", pipeline}})
+		assert.Nil(t, err, "Marshal error: %v", err)
+
+		exact, err := bson.Raw(b).LookupErr("pipeline", "0", "$vectorSearch", "exact")
+		assert.Nil(t, err, "LookupErr error: %v", err)
+		assert.True(t, exact.Boolean(), "expected exact to be true")
+	})
+	t.Run("validation", func(t *testing.T) {
+		testCases := []struct {
+			name  string
+			stage *VectorSearchStage
+		}{
+			{"missing index", VectorSearch("", "p", []float64{1}, 1).SetNumCandidates(10)},
+			{"missing path", VectorSearch("i", "", []float64{1}, 1).SetNumCandidates(10)},
+			{"missing query vector", VectorSearch("i", "p", nil, 1).SetNumCandidates(10)},
+			{"empty query vector", VectorSearch("i", "p", []float32{}, 1).SetNumCandidates(10)},
+			{"invalid query vector", VectorSearch("i", "p", "foo", 1).SetNumCandidates(10)},
+			{"wrong binary subtype", VectorSearch("i", "p", primitive.Binary{Data: []byte{1}}, 1).SetNumCandidates(10)},
+			{"zero limit", VectorSearch("i", "p", []float64{1}, 0).SetNumCandidates(10)},
+			{"missing numCandidates", VectorSearch("i", "p", []float64{1}, 1)},
+			{"numCandidates below limit", VectorSearch("i", "p", []float64{1}, 10).SetNumCandidates(5)},
+			{"numCandidates above max", VectorSearch("i", "p", []float64{1}, 10).SetNumCandidates(10001)},
+			{"numCandidates with exact", VectorSearch("i", "p", []float64{1}, 1).SetNumCandidates(10).SetExact(true)},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := tc.stage.Stage()
+				assert.NotNil(t, err, "expected error, got nil")
+			})
+		}
+	})
+}
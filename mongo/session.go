@@ -9,11 +9,15 @@ package mongo
 import (
 	"context"
 	"errors"
+	"math"
+	"math/rand"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 	"go.mongodb.org/mongo-driver/x/bsonx"
 	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 	"go.mongodb.org/mongo-driver/x/mongo/driver"
@@ -62,16 +66,28 @@ type sessionKey struct {
 // active transaction for this session or the transaction has been committed or aborted.
 //
 // WithTransaction starts a transaction on this session and runs the fn callback. Errors with the
-// TransientTransactionError and UnknownTransactionCommitResult labels are retried for up to 120 seconds. Inside the
-// callback, sessCtx must be used as the Context parameter for any operations that should be part of the transaction. If
-// the ctx parameter already has a Session attached to it, it will be replaced by this session. The fn callback may be
-// run multiple times during WithTransaction due to retry attempts, so it must be idempotent. Non-retryable operation
-// errors or any operation errors that occur after the timeout expires will be returned without retrying. For a usage
-// example, see the Client.StartSession method documentation.
+// TransientTransactionError and UnknownTransactionCommitResult labels are retried for up to the RetryTimeout of the
+// transaction options, which defaults to 120 seconds, waiting between retries as configured by the RetryBackoff,
+// MaxRetryBackoff, RetryJitter, and RetryBackoffFunc options. The number of retries can be limited with the
+// MaxTransactionRetries and MaxCommitRetries options. The generic WithTransaction function can be used instead of this
+// method to get a result of a specific type with Go 1.18 or later. Inside the callback, sessCtx must be used as the
+// Context parameter for any operations that should be part of the transaction. If the ctx parameter already has a
+// Session attached to it, it will be replaced by this session. The fn callback may be run multiple times during
+// WithTransaction due to retry attempts, so it must be idempotent. Non-retryable operation errors or any operation
+// errors that occur after the timeout expires will be returned without retrying. For a usage example, see the
+// Client.StartSession method documentation.
 //
 // ClusterTime, OperationTime, and Client return the session's current operation time, the session's current cluster
 // time, and the Client associated with the session, respectively.
 //
+// SnapshotTime returns the point in time that reads in a snapshot session see. It is nil if the session is not a
+// snapshot session or the server has not chosen the time yet, which it does for the first read in the session.
+//
+// LogicalSessionID returns the session ID document, which is sent to the server as the lsid field of commands, and
+// TxnNumber returns the transaction number most recently used by the session for a transaction or a retryable write,
+// or 0 if there is none. They can be logged to correlate the operations of the session with the server logs and the
+// output of the currentOp command.
+//
 // EndSession method should abort any existing transactions and close the session.
 //
 // AdvanceClusterTime and AdvanceOperationTime are for internal use only and must not be called.
@@ -83,6 +99,9 @@ type Session interface {
 		opts ...*options.TransactionOptions) (interface{}, error)
 	ClusterTime() bson.Raw
 	OperationTime() *primitive.Timestamp
+	SnapshotTime() *primitive.Timestamp
+	LogicalSessionID() bson.Raw
+	TxnNumber() int64
 	Client() *Client
 	EndSession(context.Context)
 
@@ -104,7 +123,14 @@ type sessionImpl struct {
 	clientSession       *session.Client
 	client              *Client
 	deployment          driver.Deployment
-	didCommitAfterStart bool // true if commit was called after start with no other operations
+	didCommitAfterStart bool        // true if commit was called after start with no other operations
+	leakTimer           *time.Timer // reports the session if it is not ended, nil if leak detection is disabled
+	transactionStart    time.Time   // time the current transaction was started
+
+	// commitProgress is the CommitProgress option of the current transaction and commitAttempts is the number of
+	// times its commit has been sent to the server.
+	commitProgress func(*event.CommitProgressEvent)
+	commitAttempts int
 }
 
 var _ Session = &sessionImpl{}
@@ -127,14 +153,23 @@ func (s *sessionImpl) EndSession(ctx context.Context) {
 		_ = s.AbortTransaction(ctx)
 	}
 	s.clientSession.EndSession()
+	if s.leakTimer != nil {
+		s.leakTimer.Stop()
+	}
 }
 
 // WithTransaction implements the Session interface.
 func (s *sessionImpl) WithTransaction(ctx context.Context, fn func(sessCtx SessionContext) (interface{}, error),
 	opts ...*options.TransactionOptions) (interface{}, error) {
-	timeout := time.NewTimer(withTransactionTimeout)
+	topts := options.MergeTransactionOptions(opts...)
+	retryTimeout := withTransactionTimeout
+	if topts.RetryTimeout != nil {
+		retryTimeout = *topts.RetryTimeout
+	}
+	timeout := time.NewTimer(retryTimeout)
 	defer timeout.Stop()
 	var err error
+	var retries, transactionRetries int
 	for {
 		err = s.StartTransaction(opts...)
 		if err != nil {
@@ -154,7 +189,12 @@ func (s *sessionImpl) WithTransaction(ctx context.Context, fn func(sessCtx Sessi
 			}
 
 			if cerr, ok := err.(CommandError); ok {
-				if cerr.HasErrorLabel(driver.TransientTransactionError) {
+				if cerr.HasErrorLabel(driver.TransientTransactionError) &&
+					withinRetryLimit(topts.MaxTransactionRetries, transactionRetries) &&
+					waitTransactionRetry(ctx, timeout, transactionRetryDelay(topts, retries)) {
+					retries++
+					transactionRetries++
+					s.publishTransactionEvent(event.TransactionRetried, driver.TransientTransactionError, err)
 					continue
 				}
 			}
@@ -166,6 +206,7 @@ func (s *sessionImpl) WithTransaction(ctx context.Context, fn func(sessCtx Sessi
 			return res, nil
 		}
 
+		var commitRetries int
 	CommitLoop:
 		for {
 			err = s.CommitTransaction(ctx)
@@ -180,10 +221,20 @@ func (s *sessionImpl) WithTransaction(ctx context.Context, fn func(sessCtx Sessi
 			}
 
 			if cerr, ok := err.(CommandError); ok {
-				if cerr.HasErrorLabel(driver.UnknownTransactionCommitResult) && !cerr.IsMaxTimeMSExpiredError() {
-					continue
-				}
-				if cerr.HasErrorLabel(driver.TransientTransactionError) {
+				retryable := cerr.HasErrorLabel(driver.UnknownTransactionCommitResult) &&
+					!cerr.IsMaxTimeMSExpiredError() && withinRetryLimit(topts.MaxCommitRetries, commitRetries)
+				transient := cerr.HasErrorLabel(driver.TransientTransactionError) &&
+					withinRetryLimit(topts.MaxTransactionRetries, transactionRetries)
+				if (retryable || transient) &&
+					waitTransactionRetry(ctx, timeout, transactionRetryDelay(topts, retries)) {
+					retries++
+					if retryable {
+						commitRetries++
+						s.publishTransactionEvent(event.TransactionRetried, driver.UnknownTransactionCommitResult, err)
+						continue
+					}
+					transactionRetries++
+					s.publishTransactionEvent(event.TransactionRetried, driver.TransientTransactionError, err)
 					break CommitLoop
 				}
 			}
@@ -192,6 +243,56 @@ func (s *sessionImpl) WithTransaction(ctx context.Context, fn func(sessCtx Sessi
 	}
 }
 
+// withinRetryLimit returns true if another retry is allowed by a retry limit, given the number of previous retries.
+func withinRetryLimit(limit *int, retries int) bool {
+	return limit == nil || retries < *limit
+}
+
+// transactionRetryDelay returns the delay before a retry of WithTransaction, given the number of previous retries.
+func transactionRetryDelay(topts *options.TransactionOptions, retries int) time.Duration {
+	if topts.RetryBackoffFunc != nil {
+		return topts.RetryBackoffFunc(retries)
+	}
+	if topts.RetryBackoff == nil || *topts.RetryBackoff <= 0 {
+		return 0
+	}
+
+	delay := *topts.RetryBackoff
+	for i := 0; i < retries && (topts.MaxRetryBackoff == nil || delay < *topts.MaxRetryBackoff); i++ {
+		if delay > math.MaxInt64/2 {
+			break
+		}
+		delay *= 2
+	}
+	if topts.MaxRetryBackoff != nil && delay > *topts.MaxRetryBackoff {
+		delay = *topts.MaxRetryBackoff
+	}
+	if topts.RetryJitter != nil && *topts.RetryJitter > 0 {
+		jitter := math.Min(*topts.RetryJitter, 1)
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+	return delay
+}
+
+// waitTransactionRetry waits for the delay before a retry of WithTransaction. It returns false if the retry timeout
+// expires or ctx is done before the delay elapses.
+func waitTransactionRetry(ctx context.Context, timeout *time.Timer, delay time.Duration) bool {
+	if delay <= 0 {
+		return true
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-timeout.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
 // StartTransaction implements the Session interface.
 func (s *sessionImpl) StartTransaction(opts ...*options.TransactionOptions) error {
 	err := s.clientSession.CheckStartTransaction()
@@ -202,6 +303,8 @@ func (s *sessionImpl) StartTransaction(opts ...*options.TransactionOptions) erro
 	s.didCommitAfterStart = false
 
 	topts := options.MergeTransactionOptions(opts...)
+	s.commitProgress = topts.CommitProgress
+	s.commitAttempts = 0
 	coreOpts := &session.TransactionOptions{
 		ReadConcern:    topts.ReadConcern,
 		ReadPreference: topts.ReadPreference,
@@ -209,7 +312,45 @@ func (s *sessionImpl) StartTransaction(opts ...*options.TransactionOptions) erro
 		MaxCommitTime:  topts.MaxCommitTime,
 	}
 
-	return s.clientSession.StartTransaction(coreOpts)
+	err = s.clientSession.StartTransaction(coreOpts)
+	if err == nil {
+		s.transactionStart = time.Now()
+		s.publishTransactionEvent(event.TransactionStarted, "", nil)
+	}
+	if err == nil && s.client.tracer != nil {
+		_, s.clientSession.TransactionSpan = s.client.tracer.StartSpan(context.Background(), "transaction")
+		s.clientSession.TransactionSpan.SetAttributes(event.SpanAttribute{Key: event.AttributeDBSystem, Value: "mongodb"})
+	}
+	return err
+}
+
+// publishTransactionEvent publishes an event for the current transaction to the TransactionMonitor of the client, if
+// there is one.
+func (s *sessionImpl) publishTransactionEvent(eventType, reason string, err error) {
+	monitor := s.client.txnMonitor
+	if monitor == nil || monitor.Event == nil {
+		return
+	}
+	monitor.Event(&event.TransactionEvent{
+		Type:      eventType,
+		TxnNumber: s.clientSession.TxnNumber,
+		Duration:  time.Since(s.transactionStart),
+		Reason:    reason,
+		Error:     err,
+	})
+}
+
+// endTransactionSpan ends the span of the current transaction, if any, recording err if it is not nil.
+func (s *sessionImpl) endTransactionSpan(err error) {
+	span := s.clientSession.TransactionSpan
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+	s.clientSession.TransactionSpan = nil
 }
 
 // AbortTransaction implements the Session interface.
@@ -219,6 +360,9 @@ func (s *sessionImpl) AbortTransaction(ctx context.Context) error {
 		return err
 	}
 
+	defer s.endTransactionSpan(nil)
+	defer s.publishTransactionEvent(event.TransactionAborted, "", nil)
+
 	// Do not run the abort command if the transaction is in starting state
 	if s.clientSession.TransactionStarting() || s.didCommitAfterStart {
 		return s.clientSession.AbortTransaction()
@@ -245,28 +389,37 @@ func (s *sessionImpl) CommitTransaction(ctx context.Context) error {
 		return err
 	}
 
+	s.publishTransactionEvent(event.TransactionCommitStarted, "", nil)
+
 	// Do not run the commit command if the transaction is in started state
 	if s.clientSession.TransactionStarting() || s.didCommitAfterStart {
 		s.didCommitAfterStart = true
-		return s.clientSession.CommitTransaction()
+		err = s.clientSession.CommitTransaction()
+		s.endCommit(err)
+		return err
 	}
 
 	if s.clientSession.TransactionCommitted() {
 		s.clientSession.RetryingCommit = true
+		// A retried commit uses a write concern of majority.
+		s.clientSession.UpdateCommitTransactionWriteConcern()
 	}
 
 	selector := makePinnedSelector(s.clientSession, description.WriteSelector())
 
+	wc := s.clientSession.CurrentWc
 	s.clientSession.Committing = true
 	op := operation.NewCommitTransaction().
 		Session(s.clientSession).ClusterClock(s.client.clock).Database("admin").Deployment(s.deployment).
-		WriteConcern(s.clientSession.CurrentWc).ServerSelector(selector).Retry(driver.RetryOncePerCommand).
+		WriteConcern(wc).ServerSelector(selector).Retry(driver.RetryOncePerCommand).
 		CommandMonitor(s.client.monitor).RecoveryToken(bsoncore.Document(s.clientSession.RecoveryToken))
 	if s.clientSession.CurrentMct != nil {
 		op.MaxTimeMS(int64(*s.clientSession.CurrentMct / time.Millisecond))
 	}
 
+	stopProgress := s.startCommitProgress(wc)
 	err = op.Execute(ctx)
+	stopProgress()
 	s.clientSession.Committing = false
 	commitErr := s.clientSession.CommitTransaction()
 
@@ -274,9 +427,59 @@ func (s *sessionImpl) CommitTransaction(ctx context.Context) error {
 	s.clientSession.UpdateCommitTransactionWriteConcern()
 
 	if err != nil {
-		return replaceErrors(err)
+		err = replaceErrors(err)
+	} else {
+		err = commitErr
+	}
+	s.endCommit(err)
+	return err
+}
+
+// startCommitProgress reports that a commit attempt is being sent with the write concern wc to the CommitProgress
+// function of the transaction, if there is one, and starts a timer to report when the wtimeout of wc is approaching.
+// The returned function must be called when the attempt has finished.
+func (s *sessionImpl) startCommitProgress(wc *writeconcern.WriteConcern) func() {
+	s.commitAttempts++
+	fn := s.commitProgress
+	if fn == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+	txnNumber, attempt := s.clientSession.TxnNumber, s.commitAttempts
+	var wtimeout time.Duration
+	if wc != nil {
+		wtimeout = wc.GetWTimeout()
+	}
+	report := func(stage string) {
+		fn(&event.CommitProgressEvent{
+			Stage:     stage,
+			TxnNumber: txnNumber,
+			Attempt:   attempt,
+			Elapsed:   time.Since(start),
+			WTimeout:  wtimeout,
+		})
+	}
+
+	if s.clientSession.RetryingCommit {
+		report(event.CommitRetriedWithMajority)
+	}
+	report(event.CommitSent)
+	if wtimeout <= 0 {
+		return func() {}
+	}
+	timer := time.AfterFunc(wtimeout*4/5, func() { report(event.CommitWTimeoutApproaching) })
+	return func() { timer.Stop() }
+}
+
+// endCommit publishes the outcome of a commit attempt and ends the span of the transaction.
+func (s *sessionImpl) endCommit(err error) {
+	if err != nil {
+		s.publishTransactionEvent(event.TransactionCommitFailed, "", err)
+	} else {
+		s.publishTransactionEvent(event.TransactionCommitSucceeded, "", nil)
 	}
-	return commitErr
+	s.endTransactionSpan(err)
 }
 
 // ClusterTime implements the Session interface.
@@ -294,6 +497,28 @@ func (s *sessionImpl) OperationTime() *primitive.Timestamp {
 	return s.clientSession.OperationTime
 }
 
+// SnapshotTime implements the Session interface.
+func (s *sessionImpl) SnapshotTime() *primitive.Timestamp {
+	return s.clientSession.SnapshotTime
+}
+
+// LogicalSessionID implements the Session interface.
+func (s *sessionImpl) LogicalSessionID() bson.Raw {
+	if s.clientSession.Server == nil {
+		return nil
+	}
+	id, _ := s.clientSession.SessionID.MarshalBSON()
+	return id
+}
+
+// TxnNumber implements the Session interface.
+func (s *sessionImpl) TxnNumber() int64 {
+	if s.clientSession.Server == nil {
+		return 0
+	}
+	return s.clientSession.TxnNumber
+}
+
 // AdvanceOperationTime implements the Session interface.
 func (s *sessionImpl) AdvanceOperationTime(ts *primitive.Timestamp) error {
 	return s.clientSession.AdvanceOperationTime(ts)
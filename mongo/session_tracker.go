@@ -0,0 +1,43 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"runtime/debug"
+	"time"
+
+	"go.mongodb.org/mongo-driver/internal/logger"
+)
+
+// sessionTracker reports explicit sessions that are not ended within a threshold to the client's logger.
+type sessionTracker struct {
+	threshold time.Duration
+	logger    *logger.Logger
+}
+
+func newSessionTracker(threshold time.Duration, log *logger.Logger) *sessionTracker {
+	return &sessionTracker{
+		threshold: threshold,
+		logger:    log,
+	}
+}
+
+// track records the stack trace of the calling goroutine and returns a timer that reports the session once the
+// threshold has passed. The timer must be stopped when the session is ended. It returns nil if t is nil.
+func (t *sessionTracker) track() *time.Timer {
+	if t == nil {
+		return nil
+	}
+
+	started := time.Now()
+	stack := string(debug.Stack())
+	return time.AfterFunc(t.threshold, func() {
+		t.logger.Print(logger.ComponentClient, logger.LevelInfo, logger.SessionNotEnded,
+			"ageMS", float64(time.Since(started))/float64(time.Millisecond),
+			"stack", stack)
+	})
+}
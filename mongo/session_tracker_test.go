@@ -0,0 +1,84 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/session"
+)
+
+// chanSink is an options.LogSink that sends the messages it receives, formatted with their keys and values, to a
+// channel.
+type chanSink chan string
+
+func (s chanSink) Info(_ int, msg string, keysAndValues ...interface{}) {
+	s <- fmt.Sprintln(append([]interface{}{msg}, keysAndValues...)...)
+}
+
+func TestSessionTracker(t *testing.T) {
+	newClient := func(t *testing.T, logged chan string) *Client {
+		loggerOpts := options.Logger().SetComponentLevel(options.LogComponentClient, options.LogLevelInfo).
+			SetSink(chanSink(logged))
+		client, err := NewClient(options.Client().SetSessionLeakThreshold(10 * time.Millisecond).
+			SetLoggerOptions(loggerOpts))
+		assert.Nil(t, err, "NewClient error: %v", err)
+		client.sessionPool = session.NewPool(nil)
+		return client
+	}
+
+	t.Run("reports sessions that are not ended", func(t *testing.T) {
+		logged := make(chan string, 1)
+		client := newClient(t, logged)
+		sess, err := client.StartSession()
+		assert.Nil(t, err, "StartSession error: %v", err)
+		defer sess.EndSession(context.Background())
+
+		stats := client.SessionPoolStats()
+		assert.Equal(t, uint64(1), stats.CheckedOut, "expected 1 checked out session, got %v", stats.CheckedOut)
+
+		select {
+		case msg := <-logged:
+			assert.True(t, strings.HasPrefix(msg, "Session not ended"), "unexpected message %v", msg)
+			assert.True(t, strings.Contains(msg, "TestSessionTracker"),
+				"expected start stack to include the test function, got %v", msg)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for session leak report")
+		}
+	})
+	t.Run("ended sessions are not reported", func(t *testing.T) {
+		logged := make(chan string, 1)
+		client := newClient(t, logged)
+		sess, err := client.StartSession()
+		assert.Nil(t, err, "StartSession error: %v", err)
+		sess.EndSession(context.Background())
+
+		stats := client.SessionPoolStats()
+		assert.Equal(t, uint64(0), stats.CheckedOut, "expected no checked out sessions, got %v", stats.CheckedOut)
+
+		select {
+		case msg := <-logged:
+			t.Fatalf("unexpected session leak report: %v", msg)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+	t.Run("logging disabled", func(t *testing.T) {
+		client, err := NewClient(options.Client().SetSessionLeakThreshold(10 * time.Millisecond))
+		assert.Nil(t, err, "NewClient error: %v", err)
+		assert.Nil(t, client.sessionTracker, "expected sessions not to be tracked without client logging")
+	})
+	t.Run("tracking disabled", func(t *testing.T) {
+		var tracker *sessionTracker
+		assert.Nil(t, tracker.track(), "expected no timer when tracking is disabled")
+	})
+}
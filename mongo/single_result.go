@@ -18,6 +18,26 @@ import (
 // any documents.
 var ErrNoDocuments = errors.New("mongo: no documents in result")
 
+// DecodeError is returned by DecodeTo when the document returned by an operation could not be unmarshalled. It allows
+// callers to distinguish a document that could not be decoded from an operation error or ErrNoDocuments.
+type DecodeError struct {
+	// Err is the error returned while unmarshalling the document.
+	Err error
+
+	// Document is the document that could not be unmarshalled.
+	Document bson.Raw
+}
+
+// Error implements the error interface.
+func (de DecodeError) Error() string {
+	return "mongo: error decoding result: " + de.Err.Error()
+}
+
+// Unwrap returns the underlying unmarshalling error.
+func (de DecodeError) Unwrap() error {
+	return de.Err
+}
+
 // SingleResult represents a single document returned from an operation. If the operation resulted in an error, all
 // SingleResult methods will return that error. If the operation did not return any documents, all SingleResult methods
 // will return ErrNoDocuments.
@@ -28,6 +48,25 @@ type SingleResult struct {
 	reg *bsoncodec.Registry
 }
 
+// NewSingleResultFromDocument creates a SingleResult with the provided error, registry, and an underlying Cursor
+// pre-loaded with the provided document. If no registry is provided, bson.DefaultRegistry will be used. If err is not
+// nil, the SingleResult reports it from all of its methods; if document is nil and err is nil, it reports
+// ErrNoDocuments. This is useful for stubbing operations that return a SingleResult in unit tests.
+func NewSingleResultFromDocument(document interface{}, err error, registry *bsoncodec.Registry) *SingleResult {
+	if registry == nil {
+		registry = bson.DefaultRegistry
+	}
+	if err != nil || document == nil {
+		return &SingleResult{err: err, reg: registry}
+	}
+
+	cur, cerr := NewCursorFromDocuments([]interface{}{document}, nil, registry)
+	if cerr != nil {
+		return &SingleResult{err: cerr, reg: registry}
+	}
+	return &SingleResult{cur: cur, reg: registry}
+}
+
 // Decode will unmarshal the document represented by this SingleResult into v. If there was an error from the operation
 // that created this SingleResult, that error will be returned. If the operation returned no documents, Decode will
 // return ErrNoDocuments.
@@ -62,6 +101,31 @@ func (sr *SingleResult) DecodeBytes() (bson.Raw, error) {
 	return sr.rdr, nil
 }
 
+// Raw returns the document represented by this SingleResult. The returned document is not a copy and must not be
+// modified. If there was an error from the operation that created this SingleResult, that error will be returned. If
+// the operation returned no documents, Raw will return (nil, ErrNoDocuments).
+func (sr *SingleResult) Raw() (bson.Raw, error) {
+	if sr.err = sr.setRdrContents(); sr.err != nil {
+		return nil, sr.err
+	}
+	return sr.rdr, nil
+}
+
+// decode unmarshals the document represented by this SingleResult into v. Unlike Decode, unmarshalling errors are
+// returned as a DecodeError.
+func (sr *SingleResult) decode(v interface{}) error {
+	if sr.err = sr.setRdrContents(); sr.err != nil {
+		return sr.err
+	}
+	if sr.reg == nil {
+		return bson.ErrNilRegistry
+	}
+	if err := bson.UnmarshalWithRegistry(sr.reg, sr.rdr, v); err != nil {
+		return DecodeError{Err: err, Document: sr.rdr}
+	}
+	return nil
+}
+
 // setRdrContents will set the contents of rdr by iterating the underlying cursor if necessary.
 func (sr *SingleResult) setRdrContents() error {
 	switch {
@@ -0,0 +1,25 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build go1.18
+// +build go1.18
+
+package mongo
+
+// DecodeTo unmarshals the document represented by sr into a new value of type T. If there was an error from the
+// operation that created sr, that error is returned. If the operation returned no documents, ErrNoDocuments is
+// returned. If the document could not be unmarshalled, a DecodeError is returned. In every error case, the zero value
+// of T is returned.
+//
+// This function requires Go 1.18 or later.
+func DecodeTo[T any](sr *SingleResult) (T, error) {
+	var val T
+	if err := sr.decode(&val); err != nil {
+		var zero T
+		return zero, err
+	}
+	return val, nil
+}
@@ -0,0 +1,51 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build go1.18
+// +build go1.18
+
+package mongo
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+func TestDecodeTo(t *testing.T) {
+	type Document struct {
+		Foo int32
+	}
+
+	t.Run("success", func(t *testing.T) {
+		c, err := newCursor(newTestBatchCursor(1, 1), bson.DefaultRegistry)
+		assert.Nil(t, err, "newCursor error: %v", err)
+
+		doc, err := DecodeTo[Document](&SingleResult{cur: c, reg: bson.DefaultRegistry})
+		assert.Nil(t, err, "DecodeTo error: %v", err)
+		assert.Equal(t, Document{Foo: 0}, doc, "expected document %v, got %v", Document{}, doc)
+	})
+	t.Run("no documents", func(t *testing.T) {
+		_, err := DecodeTo[Document](&SingleResult{reg: bson.DefaultRegistry})
+		assert.Equal(t, ErrNoDocuments, err, "expected error %v, got %v", ErrNoDocuments, err)
+	})
+	t.Run("operation error", func(t *testing.T) {
+		opErr := errors.New("operation error")
+		_, err := DecodeTo[Document](&SingleResult{err: opErr, reg: bson.DefaultRegistry})
+		assert.Equal(t, opErr, err, "expected error %v, got %v", opErr, err)
+	})
+	t.Run("decode error", func(t *testing.T) {
+		c, err := newCursor(newTestBatchCursor(1, 1), bson.DefaultRegistry)
+		assert.Nil(t, err, "newCursor error: %v", err)
+
+		_, err = DecodeTo[struct{ Foo string }](&SingleResult{cur: c, reg: bson.DefaultRegistry})
+		de, ok := err.(DecodeError)
+		assert.True(t, ok, "expected error of type %T, got %v", DecodeError{}, err)
+		assert.NotNil(t, de.Document, "expected DecodeError to contain the document")
+	})
+}
@@ -44,8 +44,35 @@ func TestSingleResult(t *testing.T) {
 		})
 	})
 
+	t.Run("Raw", func(t *testing.T) {
+		c, err := newCursor(newTestBatchCursor(1, 1), bson.DefaultRegistry)
+		assert.Nil(t, err, "newCursor error: %v", err)
+
+		sr := &SingleResult{cur: c, reg: bson.DefaultRegistry}
+		raw, err := sr.Raw()
+		assert.Nil(t, err, "Raw error: %v", err)
+		decodeBytes, err := sr.DecodeBytes()
+		assert.Nil(t, err, "DecodeBytes error: %v", err)
+		assert.True(t, &raw[0] == &decodeBytes[0], "expected Raw to return the underlying document")
+
+		_, err = (&SingleResult{}).Raw()
+		assert.Equal(t, ErrNoDocuments, err, "expected error %v, got %v", ErrNoDocuments, err)
+	})
+
 	t.Run("Err", func(t *testing.T) {
 		sr := &SingleResult{}
 		assert.Equal(t, ErrNoDocuments, sr.Err(), "expected error %v, got %v", ErrNoDocuments, sr.Err())
 	})
+	t.Run("NewSingleResultFromDocument", func(t *testing.T) {
+		var doc struct{ X int }
+		err := NewSingleResultFromDocument(bson.D{{"x", 1}}, nil, nil).Decode(&doc)
+		assert.Nil(t, err, "Decode error: %v", err)
+		assert.Equal(t, 1, doc.X, "expected x to be 1, got %v", doc.X)
+
+		opErr := errors.New("operation error")
+		err = NewSingleResultFromDocument(bson.D{{"x", 1}}, opErr, nil).Err()
+		assert.Equal(t, opErr, err, "expected error %v, got %v", opErr, err)
+		err = NewSingleResultFromDocument(nil, nil, nil).Err()
+		assert.Equal(t, ErrNoDocuments, err, "expected error %v, got %v", ErrNoDocuments, err)
+	})
 }
@@ -0,0 +1,242 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// WiredTigerCacheStats contains statistics of the WiredTiger cache. Sizes are in bytes.
+type WiredTigerCacheStats struct {
+	BytesInCache          int64 `bson:"bytes currently in the cache"`
+	MaxBytes              int64 `bson:"maximum bytes configured"` // only reported by serverStatus
+	DirtyBytes            int64 `bson:"tracked dirty bytes in the cache"`
+	PagesReadIntoCache    int64 `bson:"pages read into cache"`
+	PagesWrittenFromCache int64 `bson:"pages written from cache"`
+}
+
+// ServerStatus is a subset of the result of the serverStatus command. It is returned by Database.ServerStatus.
+type ServerStatus struct {
+	Host      string    `bson:"host"`
+	Version   string    `bson:"version"`
+	Process   string    `bson:"process"`
+	LocalTime time.Time `bson:"localTime"`
+
+	// Uptime is the time that the server has been running for. It is derived from UptimeMillis.
+	Uptime       time.Duration `bson:"-"`
+	UptimeMillis int64         `bson:"uptimeMillis"`
+
+	Connections struct {
+		Current      int64 `bson:"current"`
+		Available    int64 `bson:"available"`
+		TotalCreated int64 `bson:"totalCreated"`
+		Active       int64 `bson:"active"`
+	} `bson:"connections"`
+
+	Opcounters struct {
+		Insert  int64 `bson:"insert"`
+		Query   int64 `bson:"query"`
+		Update  int64 `bson:"update"`
+		Delete  int64 `bson:"delete"`
+		GetMore int64 `bson:"getmore"`
+		Command int64 `bson:"command"`
+	} `bson:"opcounters"`
+
+	// Mem contains the memory usage of the server in mebibytes.
+	Mem struct {
+		Bits     int64 `bson:"bits"`
+		Resident int64 `bson:"resident"`
+		Virtual  int64 `bson:"virtual"`
+	} `bson:"mem"`
+
+	Network struct {
+		BytesIn     int64 `bson:"bytesIn"`
+		BytesOut    int64 `bson:"bytesOut"`
+		NumRequests int64 `bson:"numRequests"`
+	} `bson:"network"`
+
+	// WiredTigerCache is only set if the server uses the WiredTiger storage engine.
+	WiredTigerCache *WiredTigerCacheStats `bson:"-"`
+
+	// Raw is the complete result of the command, which contains sections that are not part of this type.
+	Raw bson.Raw `bson:"-"`
+}
+
+// DatabaseStats is a subset of the result of the dbStats command. It is returned by Database.Stats. Sizes are in
+// bytes.
+type DatabaseStats struct {
+	DB          string  `bson:"db"`
+	Collections int64   `bson:"collections"`
+	Views       int64   `bson:"views"`
+	Objects     int64   `bson:"objects"`
+	AvgObjSize  float64 `bson:"avgObjSize"`
+	DataSize    int64   `bson:"dataSize"`
+	StorageSize int64   `bson:"storageSize"`
+	Indexes     int64   `bson:"indexes"`
+	IndexSize   int64   `bson:"indexSize"`
+	TotalSize   int64   `bson:"totalSize"`
+	FSUsedSize  int64   `bson:"fsUsedSize"`
+	FSTotalSize int64   `bson:"fsTotalSize"`
+
+	// Raw is the complete result of the command, which contains fields that are not part of this type.
+	Raw bson.Raw `bson:"-"`
+}
+
+// CollectionStats is a subset of the storage statistics returned by the $collStats aggregation stage. It is returned
+// by Collection.Stats. Sizes are in bytes.
+type CollectionStats struct {
+	Namespace string
+	Shard     string // only set on sharded clusters
+	Host      string
+
+	Count           int64
+	Size            int64
+	AvgObjSize      float64
+	StorageSize     int64
+	FreeStorageSize int64
+	Capped          bool
+
+	NumIndexes     int64
+	TotalIndexSize int64
+	TotalSize      int64
+	IndexSizes     map[string]int64 // keyed by index name
+
+	// WiredTigerCache is only set if the server uses the WiredTiger storage engine. The MaxBytes field is not set.
+	WiredTigerCache *WiredTigerCacheStats
+
+	// Raw is the complete document returned by the server, which contains fields that are not part of this type.
+	Raw bson.Raw
+}
+
+// collStatsDocument is the document returned by $collStats, which nests the storage statistics.
+type collStatsDocument struct {
+	Namespace    string `bson:"ns"`
+	Shard        string `bson:"shard"`
+	Host         string `bson:"host"`
+	StorageStats struct {
+		Count           int64            `bson:"count"`
+		Size            int64            `bson:"size"`
+		AvgObjSize      float64          `bson:"avgObjSize"`
+		StorageSize     int64            `bson:"storageSize"`
+		FreeStorageSize int64            `bson:"freeStorageSize"`
+		Capped          bool             `bson:"capped"`
+		NumIndexes      int64            `bson:"nindexes"`
+		TotalIndexSize  int64            `bson:"totalIndexSize"`
+		TotalSize       int64            `bson:"totalSize"`
+		IndexSizes      map[string]int64 `bson:"indexSizes"`
+		WiredTiger      *struct {
+			Cache WiredTigerCacheStats `bson:"cache"`
+		} `bson:"wiredTiger"`
+	} `bson:"storageStats"`
+}
+
+// ServerStatus runs the serverStatus command against the server selected by the read preference of the database and
+// returns a subset of its result that is useful for monitoring. The complete result is available in the Raw field.
+//
+// For more information about the command, see https://docs.mongodb.com/manual/reference/command/serverStatus/.
+func (db *Database) ServerStatus(ctx context.Context) (ServerStatus, error) {
+	raw, err := db.RunCommand(ctx, bson.D{{"serverStatus", 1}}).DecodeBytes()
+	if err != nil {
+		return ServerStatus{}, err
+	}
+	return decodeServerStatus(raw)
+}
+
+// Stats runs the dbStats command and returns a subset of its result. The complete result is available in the Raw
+// field.
+//
+// For more information about the command, see https://docs.mongodb.com/manual/reference/command/dbStats/.
+func (db *Database) Stats(ctx context.Context) (DatabaseStats, error) {
+	raw, err := db.RunCommand(ctx, bson.D{{"dbStats", 1}}).DecodeBytes()
+	if err != nil {
+		return DatabaseStats{}, err
+	}
+
+	stats := DatabaseStats{Raw: raw}
+	if err := bson.Unmarshal(raw, &stats); err != nil {
+		return DatabaseStats{}, err
+	}
+	return stats, nil
+}
+
+// Stats runs a $collStats aggregation with storage statistics and returns its results. On a sharded cluster, there
+// is one result per shard that holds data of the collection. Otherwise, there is a single result.
+//
+// For more information about the stage, see https://docs.mongodb.com/manual/reference/operator/aggregation/collStats/.
+func (coll *Collection) Stats(ctx context.Context) ([]CollectionStats, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	pipeline := bson.A{bson.D{{"$collStats", bson.D{{"storageStats", bson.D{}}}}}}
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	stats := make([]CollectionStats, 0, 1)
+	for cursor.Next(ctx) {
+		s, err := decodeCollectionStats(cursor.Current)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, cursor.Err()
+}
+
+func decodeServerStatus(raw bson.Raw) (ServerStatus, error) {
+	status := ServerStatus{Raw: raw}
+	if err := bson.Unmarshal(raw, &status); err != nil {
+		return ServerStatus{}, err
+	}
+	status.Uptime = time.Duration(status.UptimeMillis) * time.Millisecond
+
+	if cache, err := raw.LookupErr("wiredTiger", "cache"); err == nil {
+		status.WiredTigerCache = &WiredTigerCacheStats{}
+		if err := cache.Unmarshal(status.WiredTigerCache); err != nil {
+			return ServerStatus{}, err
+		}
+	}
+	return status, nil
+}
+
+func decodeCollectionStats(doc bson.Raw) (CollectionStats, error) {
+	// copy the document because the cursor reuses its buffer
+	raw := make(bson.Raw, len(doc))
+	copy(raw, doc)
+
+	var wire collStatsDocument
+	if err := bson.Unmarshal(raw, &wire); err != nil {
+		return CollectionStats{}, err
+	}
+	storage := wire.StorageStats
+	stats := CollectionStats{
+		Namespace:       wire.Namespace,
+		Shard:           wire.Shard,
+		Host:            wire.Host,
+		Count:           storage.Count,
+		Size:            storage.Size,
+		AvgObjSize:      storage.AvgObjSize,
+		StorageSize:     storage.StorageSize,
+		FreeStorageSize: storage.FreeStorageSize,
+		Capped:          storage.Capped,
+		NumIndexes:      storage.NumIndexes,
+		TotalIndexSize:  storage.TotalIndexSize,
+		TotalSize:       storage.TotalSize,
+		IndexSizes:      storage.IndexSizes,
+		Raw:             raw,
+	}
+	if storage.WiredTiger != nil {
+		cache := storage.WiredTiger.Cache
+		stats.WiredTigerCache = &cache
+	}
+	return stats, nil
+}
@@ -0,0 +1,100 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+func TestStats(t *testing.T) {
+	cache := bson.D{
+		{"bytes currently in the cache", int64(1024)},
+		{"maximum bytes configured", int64(4096)},
+		{"tracked dirty bytes in the cache", int64(16)},
+		{"pages read into cache", int64(3)},
+		{"pages written from cache", int64(2)},
+	}
+
+	t.Run("server status", func(t *testing.T) {
+		raw, err := bson.Marshal(bson.D{
+			{"host", "db1:27017"},
+			{"version", "4.4.0"},
+			{"uptime", 12.0},
+			{"uptimeMillis", int64(12345)},
+			{"connections", bson.D{{"current", int32(5)}, {"available", int32(100)}, {"totalCreated", int32(9)}}},
+			{"opcounters", bson.D{{"insert", int64(1)}, {"getmore", int64(4)}}},
+			{"mem", bson.D{{"bits", int32(64)}, {"resident", int32(80)}}},
+			{"network", bson.D{{"bytesIn", int64(100)}, {"numRequests", int64(10)}}},
+			{"wiredTiger", bson.D{{"cache", cache}}},
+			{"ok", 1.0},
+		})
+		assert.Nil(t, err, "Marshal error: %v", err)
+
+		status, err := decodeServerStatus(raw)
+		assert.Nil(t, err, "decodeServerStatus error: %v", err)
+		assert.Equal(t, "db1:27017", status.Host, "expected host db1:27017, got %v", status.Host)
+		assert.Equal(t, 12345*time.Millisecond, status.Uptime, "expected uptime 12.345s, got %v", status.Uptime)
+		assert.Equal(t, int64(5), status.Connections.Current, "expected 5 connections, got %v",
+			status.Connections.Current)
+		assert.Equal(t, int64(4), status.Opcounters.GetMore, "expected 4 getMores, got %v", status.Opcounters.GetMore)
+		assert.Equal(t, int64(80), status.Mem.Resident, "expected 80MiB resident, got %v", status.Mem.Resident)
+		assert.Equal(t, int64(10), status.Network.NumRequests, "expected 10 requests, got %v",
+			status.Network.NumRequests)
+		expected := &WiredTigerCacheStats{
+			BytesInCache:          1024,
+			MaxBytes:              4096,
+			DirtyBytes:            16,
+			PagesReadIntoCache:    3,
+			PagesWrittenFromCache: 2,
+		}
+		assert.Equal(t, expected, status.WiredTigerCache, "expected cache %v, got %v", expected,
+			status.WiredTigerCache)
+
+		raw, err = bson.Marshal(bson.D{{"host", "db1:27017"}, {"ok", 1.0}})
+		assert.Nil(t, err, "Marshal error: %v", err)
+		status, err = decodeServerStatus(raw)
+		assert.Nil(t, err, "decodeServerStatus error: %v", err)
+		assert.Nil(t, status.WiredTigerCache, "expected no cache statistics, got %v", status.WiredTigerCache)
+	})
+	t.Run("collection stats", func(t *testing.T) {
+		raw, err := bson.Marshal(bson.D{
+			{"ns", "db.coll"},
+			{"shard", "shard01"},
+			{"host", "db1:27018"},
+			{"storageStats", bson.D{
+				{"size", int32(500)},
+				{"count", int32(10)},
+				{"avgObjSize", int32(50)},
+				{"storageSize", int32(4096)},
+				{"nindexes", int32(2)},
+				{"totalIndexSize", int32(8192)},
+				{"indexSizes", bson.D{{"_id_", int32(4096)}, {"x_1", int32(4096)}}},
+				{"wiredTiger", bson.D{{"cache", cache}}},
+			}},
+		})
+		assert.Nil(t, err, "Marshal error: %v", err)
+
+		stats, err := decodeCollectionStats(raw)
+		assert.Nil(t, err, "decodeCollectionStats error: %v", err)
+		assert.Equal(t, "db.coll", stats.Namespace, "expected namespace db.coll, got %v", stats.Namespace)
+		assert.Equal(t, "shard01", stats.Shard, "expected shard shard01, got %v", stats.Shard)
+		assert.Equal(t, int64(10), stats.Count, "expected count 10, got %v", stats.Count)
+		assert.Equal(t, 50.0, stats.AvgObjSize, "expected average size 50, got %v", stats.AvgObjSize)
+		expectedSizes := map[string]int64{"_id_": 4096, "x_1": 4096}
+		assert.Equal(t, expectedSizes, stats.IndexSizes, "expected index sizes %v, got %v", expectedSizes,
+			stats.IndexSizes)
+		assert.NotNil(t, stats.WiredTigerCache, "expected cache statistics, got nil")
+		assert.Equal(t, int64(1024), stats.WiredTigerCache.BytesInCache, "expected 1024 bytes in cache, got %v",
+			stats.WiredTigerCache.BytesInCache)
+		_, err = stats.Raw.LookupErr("storageStats", "size")
+		assert.Nil(t, err, "expected size in raw document, got %v", err)
+	})
+}
@@ -0,0 +1,234 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+)
+
+const errorCursorNotFound int32 = 43
+
+// defaultTailRetryInterval is the default amount of time to wait before re-creating a tailable cursor that was closed
+// by the server without an error.
+const defaultTailRetryInterval = time.Second
+
+// ErrTailCursorClosed is returned by TailCursor.Err if Next or TryNext is called after the cursor has been closed.
+var ErrTailCursorClosed = errors.New("tail cursor is closed")
+
+// TailCursor is a tailable-await cursor over a capped collection that transparently re-creates the underlying cursor
+// if it is killed on the server (CursorNotFound), if a network error occurs, or if the server closes it because there
+// were no documents to return. The new cursor resumes after the last document that was returned.
+//
+// A TailCursor is created with Collection.Tail.
+type TailCursor struct {
+	// Current contains the BSON bytes of the current document. This property is only valid until the next call to
+	// Next or TryNext. If continued access is required, a copy must be made.
+	Current bson.Raw
+
+	coll          *Collection
+	filter        interface{}
+	opts          *options.TailOptions
+	retryInterval time.Duration
+
+	cursor  *Cursor
+	lastDoc bson.Raw
+	closed  bool
+	err     error
+}
+
+func newTailCursor(ctx context.Context, coll *Collection, filter interface{}, opts ...*options.TailOptions) (*TailCursor, error) {
+	if filter == nil {
+		return nil, ErrNilDocument
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tc := &TailCursor{
+		coll:          coll,
+		filter:        filter,
+		opts:          options.MergeTailOptions(opts...),
+		retryInterval: defaultTailRetryInterval,
+	}
+	if tc.opts.RetryInterval != nil {
+		tc.retryInterval = *tc.opts.RetryInterval
+	}
+	if err := tc.open(ctx); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// open creates a new tailable-await cursor, resuming after the last document returned if there is one.
+func (tc *TailCursor) open(ctx context.Context) error {
+	filter, err := tc.resumeFilter()
+	if err != nil {
+		return err
+	}
+
+	fo := options.Find().SetCursorType(options.TailableAwait)
+	if tc.opts.BatchSize != nil {
+		fo.SetBatchSize(*tc.opts.BatchSize)
+	}
+	if tc.opts.MaxAwaitTime != nil {
+		fo.SetMaxAwaitTime(*tc.opts.MaxAwaitTime)
+	}
+	if tc.opts.Projection != nil {
+		fo.SetProjection(tc.opts.Projection)
+	}
+
+	cursor, err := tc.coll.Find(ctx, filter, fo)
+	if err != nil {
+		return err
+	}
+	tc.cursor = cursor
+	return nil
+}
+
+// resumeFilter returns the filter to use when creating a cursor.
+func (tc *TailCursor) resumeFilter() (interface{}, error) {
+	if tc.lastDoc == nil {
+		return tc.filter, nil
+	}
+	if tc.opts.ResumeFilter != nil {
+		return tc.opts.ResumeFilter(tc.lastDoc), nil
+	}
+
+	id, err := tc.lastDoc.LookupErr("_id")
+	if err != nil {
+		return nil, errors.New("cannot resume tailable cursor: the last document returned does not have an _id field")
+	}
+	return bson.D{{"$and", bson.A{tc.filter, bson.D{{"_id", bson.D{{"$gt", id}}}}}}}, nil
+}
+
+// ID returns the ID of the current underlying cursor, or 0 if there is no open cursor.
+func (tc *TailCursor) ID() int64 {
+	if tc.cursor == nil {
+		return 0
+	}
+	return tc.cursor.ID()
+}
+
+// Next gets the next document for this cursor. It returns true if there were no errors and a document is available.
+//
+// Next blocks until a document is available, a non-resumable error occurs, or ctx expires. If the underlying cursor
+// cannot be continued, a new one is created and iteration continues. If ctx expires, the error will be set to
+// ctx.Err(). In an error case, Next will return false.
+//
+// If Next returns false, subsequent calls will also return false.
+func (tc *TailCursor) Next(ctx context.Context) bool {
+	return tc.next(ctx, false)
+}
+
+// TryNext attempts to get the next document for this cursor. It returns true if there were no errors and the next
+// document is available.
+//
+// TryNext returns false if a non-resumable error occurs, the next document is not yet available, or ctx expires. If
+// the underlying cursor cannot be continued, it is re-created on a following call to TryNext. If TryNext returns
+// false and Err returns nil, it is safe to call TryNext again.
+func (tc *TailCursor) TryNext(ctx context.Context) bool {
+	return tc.next(ctx, true)
+}
+
+func (tc *TailCursor) next(ctx context.Context, nonBlocking bool) bool {
+	if tc.closed {
+		tc.err = ErrTailCursorClosed
+	}
+	if tc.err != nil {
+		return false
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for {
+		if tc.cursor == nil {
+			if tc.err = tc.open(ctx); tc.err != nil {
+				return false
+			}
+		}
+
+		if tc.cursor.next(ctx, nonBlocking) {
+			tc.Current = tc.cursor.Current
+			tc.lastDoc = append(tc.lastDoc[:0], tc.Current...)
+			return true
+		}
+
+		err := replaceErrors(tc.cursor.Err())
+		if err == nil && tc.cursor.ID() != 0 {
+			// TryNext did a getMore that returned no documents.
+			return false
+		}
+		if err != nil && !isTailResumableError(err) {
+			tc.err = err
+			return false
+		}
+
+		// The cursor was killed, the connection failed, or the server closed the cursor because there were no
+		// documents to return. Any error from Close is ignored because the cursor will be replaced.
+		_ = tc.cursor.Close(ctx)
+		tc.cursor = nil
+		if nonBlocking {
+			return false
+		}
+		if err == nil && !tc.wait(ctx) {
+			// Wait before re-creating a cursor the server closed without an error so an empty collection is not polled
+			// in a tight loop.
+			return false
+		}
+	}
+}
+
+// wait blocks for the retry interval or until ctx expires. It returns false if ctx expired.
+func (tc *TailCursor) wait(ctx context.Context) bool {
+	timer := time.NewTimer(tc.retryInterval)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		tc.err = ctx.Err()
+		return false
+	}
+}
+
+// isTailResumableError returns true if a tailable cursor that failed with err can be re-created.
+func isTailResumableError(err error) bool {
+	ce, ok := err.(CommandError)
+	if !ok {
+		return false
+	}
+	return ce.Code == errorCursorNotFound || ce.HasErrorLabel(driver.NetworkError)
+}
+
+// Decode will unmarshal the current document into val and return any errors from the unmarshalling process without any
+// modification. If val is nil or is a typed nil, an error will be returned.
+func (tc *TailCursor) Decode(val interface{}) error {
+	return bson.UnmarshalWithRegistry(tc.coll.registry, tc.Current, val)
+}
+
+// Err returns the last error seen by the TailCursor, or nil if no error has occurred.
+func (tc *TailCursor) Err() error { return tc.err }
+
+// Close closes this cursor. Next and TryNext must not be called after Close has been called. Close is idempotent.
+func (tc *TailCursor) Close(ctx context.Context) error {
+	tc.closed = true
+	if tc.cursor == nil {
+		return nil
+	}
+
+	err := tc.cursor.Close(ctx)
+	tc.cursor = nil
+	return replaceErrors(err)
+}
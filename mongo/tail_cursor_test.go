@@ -0,0 +1,98 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+)
+
+func TestTailCursor(t *testing.T) {
+	t.Run("resume filter", func(t *testing.T) {
+		filter := bson.D{{"x", 1}}
+		last, err := bson.Marshal(bson.D{{"_id", int32(5)}, {"x", 1}})
+		assert.Nil(t, err, "Marshal error: %v", err)
+
+		t.Run("original filter before first document", func(t *testing.T) {
+			tc := &TailCursor{filter: filter, opts: options.Tail()}
+			got, err := tc.resumeFilter()
+			assert.Nil(t, err, "resumeFilter error: %v", err)
+			assert.Equal(t, filter, got, "expected filter %v, got %v", filter, got)
+		})
+		t.Run("default resumes after last _id", func(t *testing.T) {
+			tc := &TailCursor{filter: filter, opts: options.Tail(), lastDoc: last}
+			got, err := tc.resumeFilter()
+			assert.Nil(t, err, "resumeFilter error: %v", err)
+
+			gotBytes, err := bson.Marshal(got)
+			assert.Nil(t, err, "Marshal error: %v", err)
+			want, err := bson.Marshal(bson.D{{"$and", bson.A{filter, bson.D{{"_id", bson.D{{"$gt", int32(5)}}}}}}})
+			assert.Nil(t, err, "Marshal error: %v", err)
+			assert.Equal(t, bson.Raw(want), bson.Raw(gotBytes), "expected filter %v, got %v", bson.Raw(want), bson.Raw(gotBytes))
+		})
+		t.Run("missing _id", func(t *testing.T) {
+			noID, err := bson.Marshal(bson.D{{"x", 1}})
+			assert.Nil(t, err, "Marshal error: %v", err)
+			tc := &TailCursor{filter: filter, opts: options.Tail(), lastDoc: noID}
+			_, err = tc.resumeFilter()
+			assert.NotNil(t, err, "expected error, got nil")
+		})
+		t.Run("user provided", func(t *testing.T) {
+			custom := bson.D{{"ts", bson.D{{"$gt", 10}}}}
+			var passed bson.Raw
+			opts := options.Tail().SetResumeFilter(func(doc bson.Raw) interface{} {
+				passed = doc
+				return custom
+			})
+			tc := &TailCursor{filter: filter, opts: opts, lastDoc: last}
+			got, err := tc.resumeFilter()
+			assert.Nil(t, err, "resumeFilter error: %v", err)
+			assert.Equal(t, custom, got, "expected filter %v, got %v", custom, got)
+			assert.Equal(t, bson.Raw(last), passed, "expected last document %v, got %v", bson.Raw(last), passed)
+		})
+	})
+	t.Run("resumable errors", func(t *testing.T) {
+		testCases := []struct {
+			name      string
+			err       error
+			resumable bool
+		}{
+			{"cursor not found", CommandError{Code: errorCursorNotFound}, true},
+			{"network error", CommandError{Labels: []string{driver.NetworkError}}, true},
+			{"capped position lost", CommandError{Code: errorCappedPositionLost}, false},
+			{"other error", errors.New("foo"), false},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				got := isTailResumableError(tc.err)
+				assert.Equal(t, tc.resumable, got, "expected resumable %v, got %v", tc.resumable, got)
+			})
+		}
+	})
+	t.Run("iterates underlying cursor", func(t *testing.T) {
+		cursor, err := newCursor(newTestBatchCursor(1, 3), nil)
+		assert.Nil(t, err, "newCursor error: %v", err)
+		tc := &TailCursor{opts: options.Tail(), cursor: cursor}
+
+		for i := int32(0); i < 3; i++ {
+			assert.True(t, tc.Next(context.Background()), "expected document %v, got error %v", i, tc.Err())
+			assert.Equal(t, i, tc.Current.Lookup("foo").Int32(), "expected foo %v, got %v", i, tc.Current)
+			assert.Equal(t, i, tc.lastDoc.Lookup("foo").Int32(), "expected last document to be recorded")
+		}
+
+		err = tc.Close(context.Background())
+		assert.Nil(t, err, "Close error: %v", err)
+		assert.False(t, tc.Next(context.Background()), "expected Next to return false after Close")
+		assert.Equal(t, ErrTailCursorClosed, tc.Err(), "expected error %v, got %v", ErrTailCursorClosed, tc.Err())
+	})
+}
@@ -0,0 +1,178 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testcluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// pollInterval is the time to wait between checks of whether a process is ready.
+const pollInterval = 100 * time.Millisecond
+
+// process is a running mongod or mongos.
+type process struct {
+	name    string // used in errors, e.g. "shard mongod"
+	addr    string
+	logPath string
+	cmd     *exec.Cmd
+	exited  chan struct{}
+	waitErr error // only valid after exited is closed
+}
+
+// freePort returns a local port that is not in use. Another process can take the port before it is used, but this is
+// unlikely for tests.
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = listener.Close() }()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// startProcess starts the binary bin from binDir with the given arguments, which must not include --port, --bind_ip,
+// or --logpath. The log of the process is written to logPath.
+func startProcess(name, binDir, bin, logPath string, args ...string) (*process, error) {
+	path := bin
+	if binDir != "" {
+		path = filepath.Join(binDir, bin)
+	}
+	path, err := exec.LookPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("testcluster: cannot find %s: %v", bin, err)
+	}
+	port, err := freePort()
+	if err != nil {
+		return nil, fmt.Errorf("testcluster: cannot find a free port: %v", err)
+	}
+
+	p := &process{
+		name:    name,
+		addr:    net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+		logPath: logPath,
+		exited:  make(chan struct{}),
+	}
+	args = append([]string{"--port", strconv.Itoa(port), "--bind_ip", "127.0.0.1", "--logpath", logPath}, args...)
+	p.cmd = exec.Command(path, args...)
+	if err := p.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("testcluster: cannot start %s: %v", name, err)
+	}
+	go func() {
+		p.waitErr = p.cmd.Wait()
+		close(p.exited)
+	}()
+	return p, nil
+}
+
+// connect creates a client that is connected directly to p.
+func (p *process) connect(ctx context.Context) (*mongo.Client, error) {
+	opts := options.Client().SetHosts([]string{p.addr}).SetDirect(true).SetServerSelectionTimeout(time.Second)
+	return mongo.Connect(ctx, opts)
+}
+
+// runUntil runs cmd against p until done returns true for its result. Command errors are retried because the process
+// may not accept them yet, for example while it starts up or elects itself. It returns the last error if ctx expires
+// first.
+func (p *process) runUntil(ctx context.Context, client *mongo.Client, cmd bson.D, done func(bson.M) bool) error {
+	var lastErr error
+	for {
+		var res bson.M
+		err := client.Database("admin").RunCommand(ctx, cmd).Decode(&res)
+		if err == nil && done(res) {
+			return nil
+		}
+		if err != nil {
+			lastErr = err
+		}
+
+		select {
+		case <-p.exited:
+			return fmt.Errorf("testcluster: %s exited: %v; see the log at %s", p.name, p.waitErr, p.logPath)
+		case <-ctx.Done():
+			if lastErr == nil {
+				lastErr = ctx.Err()
+			}
+			return fmt.Errorf("testcluster: %s is not ready: %v; see the log at %s", p.name, lastErr, p.logPath)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// waitReady waits until p accepts commands.
+func (p *process) waitReady(ctx context.Context) error {
+	client, err := p.connect(ctx)
+	if err != nil {
+		return fmt.Errorf("testcluster: cannot connect to %s: %v", p.name, err)
+	}
+	defer func() { _ = client.Disconnect(context.Background()) }()
+
+	return p.runUntil(ctx, client, bson.D{{"ping", 1}}, func(bson.M) bool { return true })
+}
+
+// initiate initiates a replica set with p as its only member and waits until p is the primary.
+func (p *process) initiate(ctx context.Context, replSet string, configsvr bool) error {
+	client, err := p.connect(ctx)
+	if err != nil {
+		return fmt.Errorf("testcluster: cannot connect to %s: %v", p.name, err)
+	}
+	defer func() { _ = client.Disconnect(context.Background()) }()
+
+	config := bson.D{
+		{"_id", replSet},
+		{"members", bson.A{bson.D{{"_id", 0}, {"host", p.addr}}}},
+	}
+	if configsvr {
+		config = append(config, bson.E{"configsvr", true})
+	}
+	cmd := bson.D{{"replSetInitiate", config}}
+	if err := client.Database("admin").RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("testcluster: cannot initiate replica set %s: %v", replSet, err)
+	}
+
+	return p.runUntil(ctx, client, bson.D{{"isMaster", 1}}, func(res bson.M) bool {
+		primary, _ := res["ismaster"].(bool)
+		return primary
+	})
+}
+
+// stop asks p to shut down and kills it if it has not exited after timeout.
+func (p *process) stop(timeout time.Duration) error {
+	select {
+	case <-p.exited:
+		return nil
+	default:
+	}
+
+	if err := p.cmd.Process.Signal(os.Interrupt); err != nil {
+		return p.kill()
+	}
+	select {
+	case <-p.exited:
+		return nil
+	case <-time.After(timeout):
+		return p.kill()
+	}
+}
+
+func (p *process) kill() error {
+	if err := p.cmd.Process.Kill(); err != nil {
+		return fmt.Errorf("testcluster: cannot stop %s: %v", p.name, err)
+	}
+	<-p.exited
+	return nil
+}
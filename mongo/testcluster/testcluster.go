@@ -0,0 +1,233 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package testcluster starts throwaway MongoDB deployments for tests from the mongod and mongos binaries. The
+// deployments run on local ports with their data in a temporary directory and are configured for transactions and
+// change streams, which require a replica set or a sharded cluster:
+//
+//	func TestMain(m *testing.M) {
+//		cluster, err := testcluster.StartReplicaSet(context.Background(), nil)
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		code := m.Run()
+//		_ = cluster.Stop()
+//		os.Exit(code)
+//	}
+//
+// Tests then create clients with Cluster.Connect, or use Cluster.URI to configure the code under test. Test commands
+// are enabled on every process, so fail points can be used. The deployments have no authentication and TLS.
+package testcluster // import "go.mongodb.org/mongo-driver/mongo/testcluster"
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultStartupTimeout = 2 * time.Minute
+	shutdownTimeout       = 10 * time.Second
+	replicaSetName        = "rs0"
+	configReplicaSetName  = "configRS"
+	shardReplicaSetName   = "shardRS"
+)
+
+// Options configures the deployments started by this package. A nil *Options uses the defaults of all fields.
+type Options struct {
+	// BinDir is the directory that contains the mongod and mongos binaries. If it is empty, the binaries are looked up
+	// in the PATH.
+	BinDir string
+
+	// Dir is the directory in which the data files and logs are stored. If it is empty, a temporary directory is
+	// created and removed by Cluster.Stop. A directory given here is not removed.
+	Dir string
+
+	// StartupTimeout is the maximum time to wait for the deployment to become ready. The default is two minutes.
+	StartupTimeout time.Duration
+
+	// MongodArgs are additional command line arguments for every mongod, such as
+	// []string{"--setParameter", "transactionLifetimeLimitSeconds=5"}.
+	MongodArgs []string
+}
+
+// Cluster is a running deployment. Its processes keep running until Stop is called, so a Cluster must always be
+// stopped to not leak processes.
+type Cluster struct {
+	uri       string
+	dir       string
+	removeDir bool
+	processes []*process // in the order in which they are stopped
+}
+
+// StartReplicaSet starts a replica set with a single mongod and waits until it has elected a primary.
+func StartReplicaSet(ctx context.Context, opts *Options) (*Cluster, error) {
+	opts = withDefaults(opts)
+	c, err := newCluster(opts)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, opts.StartupTimeout)
+	defer cancel()
+
+	rs, err := c.startMongod(ctx, opts, "", replicaSetName)
+	if err != nil {
+		_ = c.Stop()
+		return nil, err
+	}
+	c.uri = fmt.Sprintf("mongodb://%s/?replicaSet=%s", rs.addr, replicaSetName)
+	return c, nil
+}
+
+// StartShardedCluster starts a sharded cluster with a single mongos and a single shard. The config servers and the
+// shard are replica sets with a single mongod each. It waits until the shard has been added to the cluster.
+func StartShardedCluster(ctx context.Context, opts *Options) (*Cluster, error) {
+	opts = withDefaults(opts)
+	c, err := newCluster(opts)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, opts.StartupTimeout)
+	defer cancel()
+
+	mongos, err := c.startSharded(ctx, opts)
+	if err != nil {
+		_ = c.Stop()
+		return nil, err
+	}
+	c.uri = fmt.Sprintf("mongodb://%s/", mongos.addr)
+	return c, nil
+}
+
+func withDefaults(opts *Options) *Options {
+	merged := Options{}
+	if opts != nil {
+		merged = *opts
+	}
+	if merged.StartupTimeout <= 0 {
+		merged.StartupTimeout = defaultStartupTimeout
+	}
+	return &merged
+}
+
+func newCluster(opts *Options) (*Cluster, error) {
+	if opts.Dir != "" {
+		return &Cluster{dir: opts.Dir}, nil
+	}
+	dir, err := ioutil.TempDir("", "testcluster")
+	if err != nil {
+		return nil, fmt.Errorf("testcluster: cannot create a temporary directory: %v", err)
+	}
+	return &Cluster{dir: dir, removeDir: true}, nil
+}
+
+// startMongod starts a mongod that is the only member of the replica set replSet. The role is "--configsvr",
+// "--shardsvr", or empty.
+func (c *Cluster) startMongod(ctx context.Context, opts *Options, role, replSet string) (*process, error) {
+	dbPath := filepath.Join(c.dir, replSet)
+	if err := os.MkdirAll(dbPath, 0700); err != nil {
+		return nil, fmt.Errorf("testcluster: cannot create the data directory: %v", err)
+	}
+	args := []string{
+		"--dbpath", dbPath,
+		"--replSet", replSet,
+		"--oplogSize", "128",
+		"--setParameter", "enableTestCommands=1",
+	}
+	if role != "" {
+		args = append(args, role)
+	}
+	args = append(args, opts.MongodArgs...)
+
+	name := replSet + " mongod"
+	p, err := startProcess(name, opts.BinDir, "mongod", filepath.Join(c.dir, replSet+".log"), args...)
+	if err != nil {
+		return nil, err
+	}
+	// add the process before waiting so that it is stopped if it does not become ready
+	c.processes = append([]*process{p}, c.processes...)
+
+	if err := p.waitReady(ctx); err != nil {
+		return nil, err
+	}
+	if err := p.initiate(ctx, replSet, role == "--configsvr"); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (c *Cluster) startSharded(ctx context.Context, opts *Options) (*process, error) {
+	config, err := c.startMongod(ctx, opts, "--configsvr", configReplicaSetName)
+	if err != nil {
+		return nil, err
+	}
+	shard, err := c.startMongod(ctx, opts, "--shardsvr", shardReplicaSetName)
+	if err != nil {
+		return nil, err
+	}
+
+	configDB := configReplicaSetName + "/" + config.addr
+	args := []string{"--configdb", configDB, "--setParameter", "enableTestCommands=1"}
+	mongos, err := startProcess("mongos", opts.BinDir, "mongos", filepath.Join(c.dir, "mongos.log"), args...)
+	if err != nil {
+		return nil, err
+	}
+	c.processes = append([]*process{mongos}, c.processes...)
+	if err := mongos.waitReady(ctx); err != nil {
+		return nil, err
+	}
+
+	client, err := mongos.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("testcluster: cannot connect to mongos: %v", err)
+	}
+	defer func() { _ = client.Disconnect(context.Background()) }()
+
+	cmd := bson.D{{"addShard", shardReplicaSetName + "/" + shard.addr}}
+	if err := client.Database("admin").RunCommand(ctx, cmd).Err(); err != nil {
+		return nil, fmt.Errorf("testcluster: cannot add the shard: %v", err)
+	}
+	return mongos, nil
+}
+
+// URI returns the connection string of the deployment.
+func (c *Cluster) URI() string {
+	return c.uri
+}
+
+// Connect creates a client that is connected to the deployment. The given options are merged after the connection
+// string, so they can add to it but should not set the hosts or the replica set name.
+func (c *Cluster) Connect(ctx context.Context, opts ...*options.ClientOptions) (*mongo.Client, error) {
+	merged := options.MergeClientOptions(append([]*options.ClientOptions{options.Client().ApplyURI(c.uri)}, opts...)...)
+	return mongo.Connect(ctx, merged)
+}
+
+// Stop shuts down all processes of the deployment and removes its data if it is stored in a temporary directory.
+// Processes that do not shut down within ten seconds are killed. Stop returns the first error that occurred.
+func (c *Cluster) Stop() error {
+	var firstErr error
+	for _, p := range c.processes {
+		if err := p.stop(shutdownTimeout); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	c.processes = nil
+
+	if c.removeDir {
+		if err := os.RemoveAll(c.dir); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("testcluster: cannot remove %s: %v", c.dir, err)
+		}
+		c.removeDir = false
+	}
+	return firstErr
+}
@@ -0,0 +1,93 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testcluster
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+func TestStartErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("missing binary", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "testcluster-test")
+		assert.Nil(t, err, "TempDir error: %v", err)
+		defer func() { _ = os.RemoveAll(dir) }()
+
+		_, err = StartReplicaSet(ctx, &Options{BinDir: dir})
+		assert.NotNil(t, err, "expected StartReplicaSet error, got nil")
+		assert.True(t, strings.Contains(err.Error(), "cannot find mongod"), "expected missing mongod error, got %v", err)
+	})
+	t.Run("process exits", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("requires a shell script")
+		}
+		binDir, err := ioutil.TempDir("", "testcluster-test")
+		assert.Nil(t, err, "TempDir error: %v", err)
+		defer func() { _ = os.RemoveAll(binDir) }()
+		err = ioutil.WriteFile(filepath.Join(binDir, "mongod"), []byte("#!/bin/sh\nexit 3\n"), 0700)
+		assert.Nil(t, err, "WriteFile error: %v", err)
+		dataDir := filepath.Join(binDir, "data")
+
+		_, err = StartReplicaSet(ctx, &Options{BinDir: binDir, Dir: dataDir})
+		assert.NotNil(t, err, "expected StartReplicaSet error, got nil")
+		assert.True(t, strings.Contains(err.Error(), "rs0 mongod exited"), "expected exit error, got %v", err)
+		// a directory given in the options is not removed
+		_, err = os.Stat(dataDir)
+		assert.Nil(t, err, "expected data directory to exist, got %v", err)
+	})
+}
+
+func TestCluster(t *testing.T) {
+	if _, err := exec.LookPath("mongod"); err != nil {
+		t.Skip("mongod is not in the PATH")
+	}
+	ctx := context.Background()
+
+	testCases := []struct {
+		name  string
+		start func(context.Context, *Options) (*Cluster, error)
+	}{
+		{"replica set", StartReplicaSet},
+		{"sharded cluster", StartShardedCluster},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster, err := tc.start(ctx, nil)
+			assert.Nil(t, err, "start error: %v", err)
+			defer func() {
+				dir := cluster.dir
+				err := cluster.Stop()
+				assert.Nil(t, err, "Stop error: %v", err)
+				_, err = os.Stat(dir)
+				assert.True(t, os.IsNotExist(err), "expected temporary directory to be removed, got %v", err)
+			}()
+
+			client, err := cluster.Connect(ctx)
+			assert.Nil(t, err, "Connect error: %v", err)
+			defer func() { _ = client.Disconnect(ctx) }()
+			coll := client.Database("db").Collection("coll")
+			_, err = coll.InsertOne(ctx, bson.D{{"x", 1}})
+			assert.Nil(t, err, "InsertOne error: %v", err)
+
+			// change streams require a replica set or a sharded cluster
+			stream, err := coll.Watch(ctx, []bson.D{})
+			assert.Nil(t, err, "Watch error: %v", err)
+			_ = stream.Close(ctx)
+		})
+	}
+}
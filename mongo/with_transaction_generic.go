@@ -0,0 +1,32 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build go1.18
+// +build go1.18
+
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WithTransaction runs fn in a transaction on sess as described by Session.WithTransaction, and returns the result of
+// the last call to fn as a value of type T. If fn is not called, the zero value of T is returned.
+//
+// This function requires Go 1.18 or later.
+func WithTransaction[T any](ctx context.Context, sess Session, fn func(sessCtx SessionContext) (T, error),
+	opts ...*options.TransactionOptions) (T, error) {
+
+	var result T
+	_, err := sess.WithTransaction(ctx, func(sessCtx SessionContext) (interface{}, error) {
+		var err error
+		result, err = fn(sessCtx)
+		return nil, err
+	}, opts...)
+	return result, err
+}
@@ -0,0 +1,66 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build go1.18
+// +build go1.18
+
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// retryingSession is a Session whose WithTransaction calls fn the given number of times.
+type retryingSession struct {
+	Session
+	calls int
+}
+
+func (s retryingSession) WithTransaction(ctx context.Context, fn func(SessionContext) (interface{}, error),
+	_ ...*options.TransactionOptions) (interface{}, error) {
+
+	var res interface{}
+	var err error
+	for i := 0; i < s.calls; i++ {
+		res, err = fn(contextWithSession(ctx, s))
+	}
+	return res, err
+}
+
+func TestWithTransactionGeneric(t *testing.T) {
+	t.Run("returns typed result of last attempt", func(t *testing.T) {
+		var attempt int
+		res, err := WithTransaction(context.Background(), retryingSession{calls: 3},
+			func(SessionContext) (int, error) {
+				attempt++
+				return attempt * 10, nil
+			})
+		assert.Nil(t, err, "WithTransaction error: %v", err)
+		assert.Equal(t, 30, res, "expected result of last attempt, got %v", res)
+	})
+	t.Run("returns error", func(t *testing.T) {
+		testErr := errors.New("test error")
+		res, err := WithTransaction(context.Background(), retryingSession{calls: 1},
+			func(SessionContext) (string, error) {
+				return "partial", testErr
+			})
+		assert.Equal(t, testErr, err, "expected error %v, got %v", testErr, err)
+		assert.Equal(t, "partial", res, "expected result returned with error, got %v", res)
+	})
+	t.Run("zero value if fn is not called", func(t *testing.T) {
+		res, err := WithTransaction(context.Background(), retryingSession{calls: 0},
+			func(SessionContext) (*int, error) {
+				return new(int), nil
+			})
+		assert.Nil(t, err, "WithTransaction error: %v", err)
+		assert.Nil(t, res, "expected nil result, got %v", res)
+	})
+}
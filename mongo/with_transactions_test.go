@@ -24,6 +24,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 	"go.mongodb.org/mongo-driver/x/mongo/driver"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/session"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
 )
 
@@ -31,6 +32,105 @@ var (
 	connsCheckedOut int
 )
 
+func TestTransactionRetryDelay(t *testing.T) {
+	t.Run("no backoff", func(t *testing.T) {
+		delay := transactionRetryDelay(options.Transaction(), 5)
+		assert.Equal(t, time.Duration(0), delay, "expected no delay, got %v", delay)
+	})
+	t.Run("exponential backoff", func(t *testing.T) {
+		opts := options.Transaction().SetRetryBackoff(10 * time.Millisecond).SetMaxRetryBackoff(50 * time.Millisecond)
+		for retries, expected := range []time.Duration{10, 20, 40, 50, 50} {
+			delay := transactionRetryDelay(opts, retries)
+			assert.Equal(t, expected*time.Millisecond, delay, "unexpected delay for retry %v: %v", retries, delay)
+		}
+		delay := transactionRetryDelay(options.Transaction().SetRetryBackoff(time.Second), 100)
+		assert.True(t, delay > 0, "expected uncapped delay not to overflow, got %v", delay)
+	})
+	t.Run("jitter", func(t *testing.T) {
+		opts := options.Transaction().SetRetryBackoff(100 * time.Millisecond).SetRetryJitter(0.5)
+		for i := 0; i < 20; i++ {
+			delay := transactionRetryDelay(opts, 0)
+			assert.True(t, delay >= 50*time.Millisecond && delay <= 100*time.Millisecond,
+				"expected delay between 50ms and 100ms, got %v", delay)
+		}
+	})
+	t.Run("backoff function", func(t *testing.T) {
+		opts := options.Transaction().SetRetryBackoff(time.Second).
+			SetRetryBackoffFunc(func(retries int) time.Duration { return time.Duration(retries) * time.Millisecond })
+		delay := transactionRetryDelay(opts, 3)
+		assert.Equal(t, 3*time.Millisecond, delay, "expected delay from backoff function, got %v", delay)
+	})
+	t.Run("retry limits", func(t *testing.T) {
+		assert.True(t, withinRetryLimit(nil, 1000), "expected no limit when unset")
+		limit := 2
+		assert.True(t, withinRetryLimit(&limit, 1), "expected retry below limit to be allowed")
+		assert.False(t, withinRetryLimit(&limit, 2), "expected retry at limit not to be allowed")
+		zero := 0
+		assert.False(t, withinRetryLimit(&zero, 0), "expected no retries with a limit of 0")
+	})
+	t.Run("wait stops at timeout", func(t *testing.T) {
+		timeout := time.NewTimer(10 * time.Millisecond)
+		defer timeout.Stop()
+		ok := waitTransactionRetry(context.Background(), timeout, time.Minute)
+		assert.False(t, ok, "expected wait to stop when the retry timeout expires")
+	})
+}
+
+func TestTransactionEvents(t *testing.T) {
+	var events []*event.TransactionEvent
+	client := setupClient(options.Client().SetTransactionMonitor(&event.TransactionMonitor{
+		Event: func(evt *event.TransactionEvent) { events = append(events, evt) },
+	}))
+	client.sessionPool = session.NewPool(nil)
+	sess, err := client.StartSession()
+	assert.Nil(t, err, "StartSession error: %v", err)
+	defer sess.EndSession(bgCtx)
+
+	assertTypes := func(t *testing.T, expected ...string) {
+		t.Helper()
+		var types []string
+		for _, evt := range events {
+			types = append(types, evt.Type)
+		}
+		assert.Equal(t, expected, types, "expected events %v, got %v", expected, types)
+		events = nil
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		err := sess.StartTransaction()
+		assert.Nil(t, err, "StartTransaction error: %v", err)
+		err = sess.CommitTransaction(bgCtx)
+		assert.Nil(t, err, "CommitTransaction error: %v", err)
+		txnNumber := events[0].TxnNumber
+		assertTypes(t, event.TransactionStarted, event.TransactionCommitStarted, event.TransactionCommitSucceeded)
+		assert.True(t, txnNumber > 0, "expected transaction number, got %v", txnNumber)
+		assert.Equal(t, txnNumber, sess.TxnNumber(), "expected transaction number %v, got %v", txnNumber,
+			sess.TxnNumber())
+	})
+	t.Run("abort", func(t *testing.T) {
+		err := sess.StartTransaction()
+		assert.Nil(t, err, "StartTransaction error: %v", err)
+		err = sess.AbortTransaction(bgCtx)
+		assert.Nil(t, err, "AbortTransaction error: %v", err)
+		assertTypes(t, event.TransactionStarted, event.TransactionAborted)
+	})
+	t.Run("retry", func(t *testing.T) {
+		var attempts int
+		_, err := sess.WithTransaction(bgCtx, func(SessionContext) (interface{}, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, CommandError{Labels: []string{driver.TransientTransactionError}}
+			}
+			return nil, nil
+		})
+		assert.Nil(t, err, "WithTransaction error: %v", err)
+		retried := events[2]
+		assertTypes(t, event.TransactionStarted, event.TransactionAborted, event.TransactionRetried,
+			event.TransactionStarted, event.TransactionCommitStarted, event.TransactionCommitSucceeded)
+		assert.Equal(t, driver.TransientTransactionError, retried.Reason, "unexpected retry reason %v", retried.Reason)
+	})
+}
+
 func TestConvenientTransactions(t *testing.T) {
 	client := setupConvenientTransactions(t)
 	db := client.Database("TestConvenientTransactions")
@@ -53,6 +53,7 @@ type HandshakeOptions struct {
 	Authenticator         Authenticator
 	Compressors           []string
 	DBUser                string
+	LoadBalanced          bool
 	PerformAuthentication func(description.Server) bool
 }
 
@@ -70,6 +71,7 @@ func (ah *authHandshaker) GetDescription(ctx context.Context, addr address.Addre
 	desc, err := operation.NewIsMaster().
 		AppName(ah.options.AppName).
 		Compressors(ah.options.Compressors).
+		LoadBalanced(ah.options.LoadBalanced).
 		SASLSupportedMechs(ah.options.DBUser).
 		GetDescription(ctx, addr, conn)
 	if err != nil {
@@ -5,12 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/session"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
 )
 
 // BatchCursor is a batch implementation of a cursor. It returns documents in entire batches instead
@@ -31,6 +33,15 @@ type BatchCursor struct {
 	postBatchResumeToken bsoncore.Document
 	crypt                *Crypt
 
+	// pinnedConn is the connection the cursor is pinned to when the deployment is behind a load balancer.
+	pinnedConn PinnedConnection
+
+	// exhaust cursor fields
+	exhaust            bool
+	exhaustConn        Connection // the connection the server is streaming batches on, if any
+	exhaustCmd         bsoncore.Document
+	exhaustUnmonitored bool // the events of the current getMore are skipped by the monitor's filter or sampler
+
 	// legacy server (< 3.2) fields
 	legacy      bool // This field is provided for ListCollectionsBatchCursor.
 	limit       int32
@@ -42,6 +53,7 @@ type BatchCursor struct {
 type CursorResponse struct {
 	Server               Server
 	Desc                 description.Server
+	Connection           PinnedConnection // the connection the cursor is pinned to, which must already be pinned
 	FirstBatch           *bsoncore.DocumentSequence
 	Database             string
 	Collection           string
@@ -49,7 +61,9 @@ type CursorResponse struct {
 	postBatchResumeToken bsoncore.Document
 }
 
-// NewCursorResponse constructs a cursor response from the given response and server. This method
+// NewCursorResponse constructs a cursor response from the given response and server. If the server was passed to
+// ProcessResponseFn for a deployment behind a load balancer and the cursor is not exhausted, the connection the
+// response was received on is pinned to the cursor. This method
 // can be used within the ProcessResponse method for an operation.
 func NewCursorResponse(response bsoncore.Document, server Server, desc description.Server) (CursorResponse, error) {
 	cur, ok := response.Lookup("cursor").DocumentOK()
@@ -93,6 +107,16 @@ func NewCursorResponse(response bsoncore.Document, server Server, desc descripti
 			}
 		}
 	}
+
+	if ps, ok := server.(pinnedServer); ok {
+		curresp.Server = ps.Server
+		if curresp.ID != 0 {
+			if err := ps.conn.PinToCursor(); err != nil {
+				return CursorResponse{}, err
+			}
+			curresp.Connection = ps.conn
+		}
+	}
 	return curresp, nil
 }
 
@@ -103,8 +127,15 @@ type CursorOptions struct {
 	Limit          int32
 	CommandMonitor *event.CommandMonitor
 	Crypt          *Crypt
+
+	// Exhaust specifies that getMore commands should be sent with the exhaustAllowed flag so the server can stream
+	// the remaining batches on a single connection. This is ignored for servers that do not support exhaust cursors.
+	Exhaust bool
 }
 
+// exhaustMinWireVersion is the minimum wire version needed to use the OP_MSG exhaustAllowed flag.
+const exhaustMinWireVersion = 8
+
 // NewBatchCursor creates a new BatchCursor from the provided parameters.
 func NewBatchCursor(cr CursorResponse, clientSession *session.Client, clock *session.ClusterClock, opts CursorOptions) (*BatchCursor, error) {
 	ds := cr.FirstBatch
@@ -121,6 +152,9 @@ func NewBatchCursor(cr CursorResponse, clientSession *session.Client, clock *ses
 		firstBatch:           true,
 		postBatchResumeToken: cr.postBatchResumeToken,
 		crypt:                opts.Crypt,
+		pinnedConn:           cr.Connection,
+		// A pinned connection is shared with the operations that pinned it, so it cannot be used to stream batches.
+		exhaust: opts.Exhaust && cr.Connection == nil,
 	}
 
 	if ds != nil {
@@ -143,6 +177,11 @@ func NewBatchCursor(cr CursorResponse, clientSession *session.Client, clock *ses
 		}
 	}
 
+	if cr.Connection != nil {
+		// Every command for the cursor is sent on the connection it is pinned to.
+		bc.server = pinnedServer{Server: cr.Server, conn: cr.Connection}
+	}
+
 	bc.currentBatch = ds
 	return bc, nil
 }
@@ -194,7 +233,11 @@ func (bc *BatchCursor) Close(ctx context.Context) error {
 		ctx = context.Background()
 	}
 
+	// If the server is still streaming batches, the connection cannot be reused and must be closed.
+	bc.closeExhaustConnection(true)
+
 	err := bc.KillCursor(ctx)
+	bc.unpinConnection()
 	bc.id = 0
 	bc.currentBatch.Data = nil
 	bc.currentBatch.Style = 0
@@ -203,6 +246,28 @@ func (bc *BatchCursor) Close(ctx context.Context) error {
 	return err
 }
 
+// SetBatchSize sets the batchSize used for subsequent getMore commands. A size of 0 or less uses the server's default
+// batch size. If the server is already streaming batches for an exhaust cursor, the new size does not take effect
+// until the stream ends.
+func (bc *BatchCursor) SetBatchSize(size int32) {
+	if size < 0 {
+		size = 0
+	}
+	bc.batchSize = size
+}
+
+// unpinConnection unpins the connection this cursor is pinned to, if any, and closes it. Closing the connection
+// returns it to its pool unless it is also pinned to a transaction.
+func (bc *BatchCursor) unpinConnection() {
+	if bc.pinnedConn == nil {
+		return
+	}
+
+	_ = bc.pinnedConn.UnpinFromCursor()
+	_ = bc.pinnedConn.Close()
+	bc.pinnedConn = nil
+}
+
 // Server returns the server for this cursor.
 func (bc *BatchCursor) Server() Server {
 	return bc.server
@@ -252,7 +317,7 @@ func (bc *BatchCursor) getMore(ctx context.Context) {
 		}
 	}
 
-	bc.err = Operation{
+	op := Operation{
 		CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
 			dst = bsoncore.AppendInt64Element(dst, "getMore", bc.id)
 			dst = bsoncore.AppendStringElement(dst, "collection", bc.collection)
@@ -303,7 +368,16 @@ func (bc *BatchCursor) getMore(ctx context.Context) {
 		Legacy:         LegacyGetMore,
 		CommandMonitor: bc.cmdMonitor,
 		Crypt:          bc.crypt,
-	}.Execute(ctx, nil)
+	}
+
+	if bc.exhaust {
+		bc.err = bc.exhaustGetMore(ctx, op)
+	} else {
+		bc.err = op.Execute(ctx, nil)
+	}
+	if bc.id == 0 {
+		bc.unpinConnection()
+	}
 
 	// Required for legacy operations which don't support limit.
 	if bc.limit != 0 && bc.numReturned >= bc.limit {
@@ -320,3 +394,154 @@ func (bc *BatchCursor) getMore(ctx context.Context) {
 func (bc *BatchCursor) PostBatchResumeToken() bsoncore.Document {
 	return bc.postBatchResumeToken
 }
+
+// exhaustGetMore executes a getMore for an exhaust cursor. If the server is not already streaming batches for this
+// cursor, a connection is checked out and a getMore is sent with the exhaustAllowed flag. Otherwise, the next streamed
+// batch is read from the connection the server is streaming on. The connection is kept until the server sends a reply
+// without the moreToCome flag.
+func (bc *BatchCursor) exhaustGetMore(ctx context.Context, op Operation) error {
+	conn := bc.exhaustConn
+	if conn == nil {
+		var err error
+		conn, err = bc.server.Connection(ctx)
+		if err != nil {
+			return err
+		}
+
+		desc := description.SelectedServer{Server: conn.Description(), Kind: description.Single}
+		if desc.WireVersion == nil || desc.WireVersion.Max < exhaustMinWireVersion || op.shouldEncrypt() {
+			// The server does not support exhaust, so fall back to a regular getMore on the checked out connection.
+			op.Deployment = SingleConnectionDeployment{C: conn}
+			err = op.Execute(ctx, nil)
+			_ = conn.Close()
+			return err
+		}
+
+		op.ExhaustAllowed = true
+		wm, startedInfo, err := op.createWireMessage(ctx, nil, desc)
+		if err != nil {
+			_ = conn.Close()
+			return err
+		}
+		startedInfo.connID = conn.ID()
+		startedInfo.cmdName = "getMore"
+		startedInfo.unmonitored = !op.commandMonitored(startedInfo.cmdName, startedInfo.cmd)
+		bc.exhaustUnmonitored = startedInfo.unmonitored
+		op.publishStartedEvent(ctx, startedInfo)
+
+		if compressor, ok := conn.(Compressor); ok && op.canCompress(startedInfo.cmdName) {
+			if wm, err = compressor.CompressWireMessage(wm, nil); err != nil {
+				_ = conn.Close()
+				return err
+			}
+		}
+		if err = conn.WriteWireMessage(ctx, wm); err != nil {
+			err = bc.exhaustNetworkError(op, err)
+			bc.publishExhaustFinished(ctx, op, conn, startedInfo.requestID, nil, err)
+			_ = conn.Close()
+			return err
+		}
+
+		bc.exhaustConn = conn
+		bc.exhaustCmd = startedInfo.cmd
+		return bc.readExhaustReply(ctx, op, startedInfo.requestID)
+	}
+
+	// The server is streaming replies, so there is no command to send. A started event is still published for each
+	// reply so command monitors see a started and finished event for every batch.
+	requestID := wiremessage.NextRequestID()
+	bc.exhaustUnmonitored = !op.commandMonitored("getMore", bc.exhaustCmd)
+	op.publishStartedEvent(ctx, startedInformation{
+		cmd:         bc.exhaustCmd,
+		requestID:   requestID,
+		connID:      conn.ID(),
+		cmdName:     "getMore",
+		unmonitored: bc.exhaustUnmonitored,
+	})
+	return bc.readExhaustReply(ctx, op, requestID)
+}
+
+// readExhaustReply reads a single getMore reply from the exhaust connection and processes it.
+func (bc *BatchCursor) readExhaustReply(ctx context.Context, op Operation, requestID int32) error {
+	conn := bc.exhaustConn
+	startTime := time.Now()
+
+	wm, err := conn.ReadWireMessage(ctx, nil)
+	if err != nil {
+		err = bc.exhaustNetworkError(op, err)
+		bc.publishExhaustFinishedAt(ctx, op, conn, requestID, startTime, nil, err)
+		bc.closeExhaustConnection(true)
+		return err
+	}
+	if wm, err = op.decompressWireMessage(wm); err != nil {
+		bc.closeExhaustConnection(true)
+		return err
+	}
+	moreToCome := wiremessage.IsMsgMoreToCome(wm)
+
+	res, err := op.decodeResult(wm)
+	op.updateClusterTimes(res)
+	op.updateOperationTime(res)
+	if ep, ok := bc.server.(ErrorProcessor); ok {
+		ep.ProcessError(err, conn)
+	}
+	bc.publishExhaustFinishedAt(ctx, op, conn, requestID, startTime, res, err)
+	if err != nil {
+		// The server stops streaming after an error reply, but the state of the connection is unknown.
+		bc.closeExhaustConnection(true)
+		return err
+	}
+
+	if op.Crypt != nil {
+		if res, err = op.Crypt.Decrypt(ctx, res); err != nil {
+			bc.closeExhaustConnection(moreToCome)
+			return err
+		}
+	}
+	if !moreToCome {
+		bc.closeExhaustConnection(false)
+	}
+	return op.ProcessResponseFn(res, bc.server, conn.Description())
+}
+
+func (bc *BatchCursor) exhaustNetworkError(op Operation, err error) error {
+	if op.Client != nil {
+		op.Client.MarkDirty()
+	}
+	return Error{Message: err.Error(), Labels: []string{NetworkError}, Wrapped: err}
+}
+
+func (bc *BatchCursor) publishExhaustFinished(ctx context.Context, op Operation, conn Connection, requestID int32,
+	res bsoncore.Document, err error) {
+
+	bc.publishExhaustFinishedAt(ctx, op, conn, requestID, time.Now(), res, err)
+}
+
+func (bc *BatchCursor) publishExhaustFinishedAt(ctx context.Context, op Operation, conn Connection, requestID int32,
+	startTime time.Time, res bsoncore.Document, err error) {
+
+	op.publishFinishedEvent(ctx, finishedInformation{
+		cmdName:     "getMore",
+		requestID:   requestID,
+		response:    res,
+		cmdErr:      err,
+		connID:      conn.ID(),
+		startTime:   startTime,
+		unmonitored: bc.exhaustUnmonitored,
+	})
+}
+
+// closeExhaustConnection releases the connection used to stream batches for an exhaust cursor, if there is one. If
+// expire is true, the connection is closed instead of being returned to the pool because the server may still send
+// replies on it.
+func (bc *BatchCursor) closeExhaustConnection(expire bool) {
+	if bc.exhaustConn == nil {
+		return
+	}
+	if expirable, ok := bc.exhaustConn.(Expirable); ok && expire {
+		_ = expirable.Expire()
+	}
+	_ = bc.exhaustConn.Close()
+	bc.exhaustConn = nil
+	bc.exhaustCmd = nil
+}
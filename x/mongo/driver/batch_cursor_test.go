@@ -0,0 +1,164 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+type exhaustConnection struct {
+	desc    description.Server
+	written [][]byte
+	replies [][]byte
+	closed  int
+	expired int
+}
+
+func (c *exhaustConnection) WriteWireMessage(_ context.Context, wm []byte) error {
+	c.written = append(c.written, wm)
+	return nil
+}
+
+func (c *exhaustConnection) ReadWireMessage(_ context.Context, _ []byte) ([]byte, error) {
+	wm := c.replies[0]
+	c.replies = c.replies[1:]
+	return wm, nil
+}
+
+func (c *exhaustConnection) Description() description.Server { return c.desc }
+func (c *exhaustConnection) Close() error                    { c.closed++; return nil }
+func (c *exhaustConnection) Expire() error                   { c.expired++; return nil }
+func (c *exhaustConnection) Alive() bool                     { return c.expired == 0 }
+func (c *exhaustConnection) ID() string                      { return "exhaust" }
+func (c *exhaustConnection) Address() address.Address        { return "localhost:27017" }
+
+type exhaustServer struct {
+	conn *exhaustConnection
+}
+
+func (s *exhaustServer) Connection(context.Context) (Connection, error) { return s.conn, nil }
+
+func makeGetMoreReply(t *testing.T, id int64, moreToCome bool, docs ...bsoncore.Document) []byte {
+	t.Helper()
+
+	aidx, arr := bsoncore.AppendArrayStart(nil)
+	for i, doc := range docs {
+		arr = bsoncore.AppendDocumentElement(arr, string(rune('0'+i)), doc)
+	}
+	arr, _ = bsoncore.AppendArrayEnd(arr, aidx)
+
+	reply := bsoncore.BuildDocumentFromElements(nil,
+		bsoncore.AppendDocumentElement(nil, "cursor", bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt64Element(nil, "id", id),
+			bsoncore.AppendStringElement(nil, "ns", "db.coll"),
+			bsoncore.AppendArrayElement(nil, "nextBatch", arr),
+		)),
+		bsoncore.AppendDoubleElement(nil, "ok", 1),
+	)
+
+	var flags wiremessage.MsgFlag
+	if moreToCome {
+		flags = wiremessage.MoreToCome
+	}
+	idx, wm := wiremessage.AppendHeaderStart(nil, wiremessage.NextRequestID(), 0, wiremessage.OpMsg)
+	wm = wiremessage.AppendMsgFlags(wm, flags)
+	wm = wiremessage.AppendMsgSectionType(wm, wiremessage.SingleDocument)
+	wm = append(wm, reply...)
+	return bsoncore.UpdateLength(wm, idx, int32(len(wm[idx:])))
+}
+
+func TestBatchCursor(t *testing.T) {
+	t.Run("exhaust", func(t *testing.T) {
+		doc := bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "x", 1))
+		newCursor := func(t *testing.T, wireVersion int32, replies ...[]byte) (*BatchCursor, *exhaustConnection) {
+			t.Helper()
+
+			conn := &exhaustConnection{
+				desc:    description.Server{WireVersion: &description.VersionRange{Max: wireVersion}},
+				replies: replies,
+			}
+			cr := CursorResponse{
+				Server:     &exhaustServer{conn: conn},
+				Desc:       conn.desc,
+				FirstBatch: &bsoncore.DocumentSequence{Style: bsoncore.ArrayStyle},
+				Database:   "db",
+				Collection: "coll",
+				ID:         42,
+			}
+			bc, err := NewBatchCursor(cr, nil, nil, CursorOptions{Exhaust: true})
+			assert.Nil(t, err, "NewBatchCursor error: %v", err)
+			assert.False(t, bc.Next(context.Background()), "expected empty first batch")
+			return bc, conn
+		}
+		flagsOf := func(t *testing.T, wm []byte) wiremessage.MsgFlag {
+			t.Helper()
+
+			_, _, _, _, rem, ok := wiremessage.ReadHeader(wm)
+			assert.True(t, ok, "could not read header")
+			flags, _, ok := wiremessage.ReadMsgFlags(rem)
+			assert.True(t, ok, "could not read flags")
+			return flags
+		}
+
+		t.Run("batches are streamed on one connection", func(t *testing.T) {
+			bc, conn := newCursor(t, 8,
+				makeGetMoreReply(t, 42, true, doc),
+				makeGetMoreReply(t, 42, true, doc, doc),
+				makeGetMoreReply(t, 0, false, doc),
+			)
+
+			var counts []int
+			for bc.Next(context.Background()) {
+				counts = append(counts, bc.Batch().DocumentCount())
+			}
+			assert.Nil(t, bc.Err(), "cursor error: %v", bc.Err())
+			assert.Equal(t, []int{1, 2, 1}, counts, "expected batch sizes %v, got %v", []int{1, 2, 1}, counts)
+			assert.Equal(t, 1, len(conn.written), "expected 1 getMore to be sent, got %d", len(conn.written))
+			flags := flagsOf(t, conn.written[0])
+			assert.True(t, flags&wiremessage.ExhaustAllowed == wiremessage.ExhaustAllowed,
+				"expected exhaustAllowed flag to be set")
+			assert.Equal(t, 1, conn.closed, "expected connection to be returned once, got %d", conn.closed)
+			assert.Equal(t, 0, conn.expired, "expected connection not to be expired")
+		})
+		t.Run("close while streaming expires connection", func(t *testing.T) {
+			bc, conn := newCursor(t, 8,
+				makeGetMoreReply(t, 42, true, doc),
+				makeGetMoreReply(t, 0, false, doc),
+			)
+
+			assert.True(t, bc.Next(context.Background()), "expected a batch, got error %v", bc.Err())
+			bc.server = nil // prevent killCursors from being sent
+			err := bc.Close(context.Background())
+			assert.Nil(t, err, "Close error: %v", err)
+			assert.Equal(t, 1, conn.expired, "expected connection to be expired")
+			assert.Equal(t, 1, conn.closed, "expected connection to be closed")
+			assert.Nil(t, bc.exhaustConn, "expected exhaust connection to be released")
+		})
+		t.Run("old servers send a getMore per batch", func(t *testing.T) {
+			bc, conn := newCursor(t, 7,
+				makeGetMoreReply(t, 42, false, doc),
+				makeGetMoreReply(t, 0, false, doc),
+			)
+
+			for bc.Next(context.Background()) {
+			}
+			assert.Nil(t, bc.Err(), "cursor error: %v", bc.Err())
+			assert.Equal(t, 2, len(conn.written), "expected 2 getMores to be sent, got %d", len(conn.written))
+			for _, wm := range conn.written {
+				flags := flagsOf(t, wm)
+				assert.True(t, flags&wiremessage.ExhaustAllowed == 0, "expected exhaustAllowed flag not to be set")
+			}
+		})
+	})
+}
@@ -0,0 +1,257 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+func TestCommandMonitoringDetails(t *testing.T) {
+	var started []*event.CommandStartedEvent
+	var succeeded []*event.CommandSucceededEvent
+	var failed []*event.CommandFailedEvent
+	newOperation := func(conn *mockConnection, includeWireMessages bool) Operation {
+		started, succeeded, failed = nil, nil, nil
+		return Operation{
+			CommandFn: func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+				return bsoncore.AppendStringElement(dst, "find", "coll"), nil
+			},
+			Database:   "db",
+			Deployment: SingleConnectionDeployment{C: conn},
+			CommandMonitor: &event.CommandMonitor{
+				Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+					started = append(started, evt)
+				},
+				Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+					succeeded = append(succeeded, evt)
+				},
+				Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+					failed = append(failed, evt)
+				},
+				IncludeWireMessages: includeWireMessages,
+			},
+		}
+	}
+	serverConnID := int32(42)
+	serviceID := primitive.NewObjectID()
+	newConnection := func() *mockConnection {
+		return &mockConnection{
+			rDesc: description.Server{
+				WireVersion:        &description.VersionRange{Max: 8},
+				ServerConnectionID: &serverConnID,
+				ServiceID:          &serviceID,
+			},
+			rAddr: "db.example.com:27017",
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		conn := newConnection()
+		idx, wm := wiremessage.AppendHeaderStart(nil, 1, 0, wiremessage.OpMsg)
+		wm = wiremessage.AppendMsgFlags(wm, 0)
+		wm = wiremessage.AppendMsgSectionType(wm, wiremessage.SingleDocument)
+		wm = bsoncore.BuildDocumentFromElements(wm, bsoncore.AppendInt32Element(nil, "ok", 1))
+		conn.rReadWM = bsoncore.UpdateLength(wm, idx, int32(len(wm)))
+		reply := append([]byte(nil), conn.rReadWM...)
+
+		err := newOperation(conn, true).Execute(context.Background(), nil)
+		assert.Nil(t, err, "Execute error: %v", err)
+
+		assert.Equal(t, 1, len(started), "expected 1 started event, got %d", len(started))
+		assert.Equal(t, serverConnID, *started[0].ServerConnectionID, "unexpected server connection ID")
+		assert.Equal(t, serviceID, *started[0].ServiceID, "unexpected service ID")
+		assert.Equal(t, "", started[0].Compressor, "expected no compressor")
+		assert.False(t, started[0].Retry, "expected first attempt not to be a retry")
+		assert.Equal(t, conn.pWriteWM, started[0].WireMessage, "unexpected command wire message")
+
+		assert.Equal(t, 1, len(succeeded), "expected 1 succeeded event, got %d", len(succeeded))
+		evt := succeeded[0]
+		assert.Equal(t, serverConnID, *evt.ServerConnectionID, "unexpected server connection ID")
+		assert.Equal(t, serviceID, *evt.ServiceID, "unexpected service ID")
+		assert.Equal(t, len(reply), evt.ReplySize, "unexpected reply size")
+		assert.Equal(t, reply, evt.WireMessage, "unexpected reply wire message")
+	})
+	t.Run("wire messages omitted by default", func(t *testing.T) {
+		conn := newConnection()
+		conn.rReadErr = errors.New("connection reset")
+
+		_ = newOperation(conn, false).Execute(context.Background(), nil)
+		assert.Equal(t, 1, len(started), "expected 1 started event, got %d", len(started))
+		assert.Nil(t, started[0].WireMessage, "expected no command wire message")
+		assert.Equal(t, 1, len(failed), "expected 1 failed event, got %d", len(failed))
+		assert.Nil(t, failed[0].WireMessage, "expected no reply wire message")
+		assert.Equal(t, 0, failed[0].ReplySize, "expected no reply size")
+	})
+	t.Run("retry", func(t *testing.T) {
+		conn := newConnection()
+		conn.rReadErr = errors.New("connection reset")
+
+		op := newOperation(conn, false)
+		op.Type = Read
+		op.RetryMode = new(RetryMode)
+		*op.RetryMode = RetryOnce
+		_ = op.Execute(context.Background(), nil)
+
+		assert.Equal(t, 2, len(started), "expected 2 started events, got %d", len(started))
+		assert.False(t, started[0].Retry, "expected first attempt not to be a retry")
+		assert.True(t, started[1].Retry, "expected second attempt to be a retry")
+		assert.Equal(t, 2, len(failed), "expected 2 failed events, got %d", len(failed))
+		assert.True(t, failed[1].Retry, "expected second attempt to be a retry")
+	})
+	t.Run("compressorName", func(t *testing.T) {
+		idx, wm := wiremessage.AppendHeaderStart(nil, 1, 0, wiremessage.OpCompressed)
+		wm = wiremessage.AppendCompressedOriginalOpCode(wm, wiremessage.OpMsg)
+		wm = wiremessage.AppendCompressedUncompressedSize(wm, 0)
+		wm = wiremessage.AppendCompressedCompressorID(wm, wiremessage.CompressorZstd)
+		wm = bsoncore.UpdateLength(wm, idx, int32(len(wm)))
+
+		assert.Equal(t, "zstd", compressorName(wm), "unexpected compressor name")
+		assert.Equal(t, "", compressorName(newConnection().rReadWM), "expected no compressor name")
+	})
+}
+
+func TestCommandMonitoringSampling(t *testing.T) {
+	execute := func(monitor *event.CommandMonitor, coll string) {
+		conn := &mockConnection{
+			rDesc:    description.Server{WireVersion: &description.VersionRange{Max: 8}},
+			rReadErr: errors.New("connection reset"),
+		}
+		_ = Operation{
+			CommandFn: func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+				return bsoncore.AppendStringElement(dst, "find", coll), nil
+			},
+			Database:       "db",
+			Deployment:     SingleConnectionDeployment{C: conn},
+			CommandMonitor: monitor,
+		}.Execute(context.Background(), nil)
+	}
+	var started, failed int
+	newMonitor := func() *event.CommandMonitor {
+		started, failed = 0, 0
+		return &event.CommandMonitor{
+			Started: func(context.Context, *event.CommandStartedEvent) { started++ },
+			Failed:  func(context.Context, *event.CommandFailedEvent) { failed++ },
+		}
+	}
+
+	t.Run("filter", func(t *testing.T) {
+		monitor := newMonitor()
+		var calls []string
+		monitor.Filter = func(commandName, database, collection string) bool {
+			calls = append(calls, commandName+" "+database+"."+collection)
+			return collection == "included"
+		}
+		execute(monitor, "included")
+		execute(monitor, "excluded")
+
+		assert.Equal(t, []string{"find db.included", "find db.excluded"}, calls, "unexpected filter calls")
+		assert.Equal(t, 1, started, "expected 1 started event, got %d", started)
+		assert.Equal(t, 1, failed, "expected 1 failed event, got %d", failed)
+	})
+	t.Run("sampler", func(t *testing.T) {
+		monitor := newMonitor()
+		monitor.Sampler = event.RateSampler(3)
+		for i := 0; i < 6; i++ {
+			execute(monitor, "coll")
+		}
+
+		assert.Equal(t, 2, started, "expected 2 started events, got %d", started)
+		assert.Equal(t, 2, failed, "expected 2 failed events, got %d", failed)
+	})
+	t.Run("probability sampler", func(t *testing.T) {
+		monitor := newMonitor()
+		monitor.Sampler = event.ProbabilitySampler(0)
+		execute(monitor, "coll")
+		assert.Equal(t, 0, started, "expected no started events, got %d", started)
+
+		monitor.Sampler = event.ProbabilitySampler(1)
+		execute(monitor, "coll")
+		assert.Equal(t, 1, started, "expected 1 started event, got %d", started)
+	})
+}
+
+func TestCommandMonitoringRedaction(t *testing.T) {
+	var started *event.CommandStartedEvent
+	var succeeded *event.CommandSucceededEvent
+	execute := func(monitor *event.CommandMonitor, cmdName string) {
+		started, succeeded = nil, nil
+		monitor.IncludeWireMessages = true
+		monitor.Started = func(_ context.Context, evt *event.CommandStartedEvent) { started = evt }
+		monitor.Succeeded = func(_ context.Context, evt *event.CommandSucceededEvent) { succeeded = evt }
+
+		idx, wm := wiremessage.AppendHeaderStart(nil, 1, 0, wiremessage.OpMsg)
+		wm = wiremessage.AppendMsgFlags(wm, 0)
+		wm = wiremessage.AppendMsgSectionType(wm, wiremessage.SingleDocument)
+		wm = bsoncore.BuildDocumentFromElements(wm,
+			bsoncore.AppendInt32Element(nil, "ok", 1),
+			bsoncore.AppendStringElement(nil, "secret", "s3cr3t"),
+		)
+		conn := &mockConnection{
+			rDesc:   description.Server{WireVersion: &description.VersionRange{Max: 8}},
+			rReadWM: bsoncore.UpdateLength(wm, idx, int32(len(wm))),
+		}
+		err := Operation{
+			CommandFn: func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+				dst = bsoncore.AppendStringElement(dst, cmdName, "coll")
+				return bsoncore.AppendStringElement(dst, "secret", "s3cr3t"), nil
+			},
+			Database:       "db",
+			Deployment:     SingleConnectionDeployment{C: conn},
+			CommandMonitor: monitor,
+		}.Execute(context.Background(), nil)
+		assert.Nil(t, err, "Execute error: %v", err)
+	}
+
+	t.Run("redacted commands", func(t *testing.T) {
+		monitor := &event.CommandMonitor{RedactedCommands: []string{"FIND"}}
+		execute(monitor, "find")
+		assert.Nil(t, started.Command, "expected command to be redacted")
+		assert.Nil(t, started.WireMessage, "expected command wire message to be redacted")
+		assert.Equal(t, 0, len(succeeded.Reply), "expected reply to be redacted")
+		assert.Nil(t, succeeded.WireMessage, "expected reply wire message to be redacted")
+
+		execute(monitor, "insert")
+		assert.NotNil(t, started.Command, "expected insert command not to be redacted")
+		assert.NotNil(t, started.WireMessage, "expected insert wire message not to be redacted")
+	})
+	t.Run("redact function", func(t *testing.T) {
+		var names []string
+		monitor := &event.CommandMonitor{
+			Redact: func(commandName string, doc bson.Raw) bson.Raw {
+				names = append(names, commandName)
+				elems, _ := doc.Elements()
+				var kept [][]byte
+				for _, elem := range elems {
+					if elem.Key() != "secret" {
+						kept = append(kept, elem)
+					}
+				}
+				return bsoncore.BuildDocumentFromElements(nil, kept...)
+			},
+		}
+		execute(monitor, "find")
+		assert.Equal(t, []string{"find", "find"}, names, "expected command and reply to be redacted")
+		_, err := started.Command.LookupErr("secret")
+		assert.NotNil(t, err, "expected secret to be removed from command")
+		_, err = started.Command.LookupErr("find")
+		assert.Nil(t, err, "expected find to be kept in command")
+		_, err = succeeded.Reply.LookupErr("secret")
+		assert.NotNil(t, err, "expected secret to be removed from reply")
+		assert.Nil(t, started.WireMessage, "expected no command wire message")
+		assert.Nil(t, succeeded.WireMessage, "expected no reply wire message")
+	})
+}
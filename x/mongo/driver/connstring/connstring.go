@@ -9,6 +9,7 @@ package connstring // import "go.mongodb.org/mongo-driver/x/mongo/driver/connstr
 import (
 	"errors"
 	"fmt"
+	"math/rand"
 	"net"
 	"net/url"
 	"strconv"
@@ -23,7 +24,25 @@ import (
 
 // Parse parses the provided uri and returns a URI object.
 func Parse(s string) (ConnString, error) {
-	p := parser{dnsResolver: dns.DefaultResolver}
+	return ParseWithSRVDefaults(s, "", 0)
+}
+
+// ParseWithSRVDefaults parses the provided uri and returns a URI object. If the uri uses the mongodb+srv scheme,
+// srvServiceName and srvMaxHosts are used for the SRV lookup unless the uri includes the srvServiceName or srvMaxHosts
+// options, respectively. If srvServiceName is empty, the default service name is used. If srvMaxHosts is 0, there is
+// no maximum number of hosts.
+func ParseWithSRVDefaults(s, srvServiceName string, srvMaxHosts int) (ConnString, error) {
+	return ParseWithResolver(s, dns.DefaultResolver, srvServiceName, srvMaxHosts)
+}
+
+// ParseWithResolver is like ParseWithSRVDefaults, but uses resolver for the SRV and TXT lookups done for a uri using
+// the mongodb+srv scheme.
+func ParseWithResolver(s string, resolver *dns.Resolver, srvServiceName string, srvMaxHosts int) (ConnString, error) {
+	p := parser{dnsResolver: resolver}
+	if strings.HasPrefix(s, SchemeMongoDBSRV+"://") {
+		p.SRVServiceName = srvServiceName
+		p.SRVMaxHosts = srvMaxHosts
+	}
 	err := p.parse(s)
 	if err != nil {
 		err = internal.WrapErrorf(err, "error parsing uri")
@@ -49,16 +68,25 @@ type ConnString struct {
 	Hosts                              []string
 	J                                  bool
 	JSet                               bool
+	LoadBalanced                       bool
+	LoadBalancedSet                    bool
 	LocalThreshold                     time.Duration
 	LocalThresholdSet                  bool
 	MaxConnIdleTime                    time.Duration
 	MaxConnIdleTimeSet                 bool
+	MaxConnecting                      uint64
+	MaxConnectingSet                   bool
 	MaxPoolSize                        uint64
 	MaxPoolSizeSet                     bool
 	MinPoolSize                        uint64
 	MinPoolSizeSet                     bool
 	Password                           string
 	PasswordSet                        bool
+	ProxyHost                          string
+	ProxyPort                          int
+	ProxyPortSet                       bool
+	ProxyUsername                      string
+	ProxyPassword                      string
 	ReadConcernLevel                   string
 	ReadPreference                     string
 	ReadPreferenceTagSets              []map[string]string
@@ -70,6 +98,8 @@ type ConnString struct {
 	MaxStalenessSet                    bool
 	ReplicaSet                         string
 	Scheme                             string
+	SRVMaxHosts                        int
+	SRVServiceName                     string
 	ServerSelectionTimeout             time.Duration
 	ServerSelectionTimeoutSet          bool
 	SocketTimeout                      time.Duration
@@ -129,6 +159,8 @@ type parser struct {
 	ConnString
 
 	dnsResolver *dns.Resolver
+	srvNameSet  bool  // whether the srvServiceName option was specified in the URI
+	srvMaxSet   bool  // whether the srvMaxHosts option was specified in the URI
 	tlsssl      *bool // used to determine if tls and ssl options are both specified and set differently.
 }
 
@@ -199,13 +231,7 @@ func (p *parser) parse(original string) error {
 	}
 
 	var connectionArgsFromTXT []string
-	parsedHosts := strings.Split(hosts, ",")
-
 	if p.Scheme == SchemeMongoDBSRV {
-		parsedHosts, err = p.dnsResolver.ParseHosts(hosts, true)
-		if err != nil {
-			return err
-		}
 		connectionArgsFromTXT, err = p.dnsResolver.GetConnectionArgsFromTXT(hosts)
 		if err != nil {
 			return err
@@ -216,16 +242,6 @@ func (p *parser) parse(original string) error {
 		p.SSLSet = true
 	}
 
-	for _, host := range parsedHosts {
-		err = p.addHost(host)
-		if err != nil {
-			return internal.WrapErrorf(err, "invalid host \"%s\"", host)
-		}
-	}
-	if len(p.Hosts) == 0 {
-		return fmt.Errorf("must have at least 1 host")
-	}
-
 	uri = uri[len(hosts):]
 
 	extractedDatabase, err := extractDatabaseFromURI(uri)
@@ -246,6 +262,41 @@ func (p *parser) parse(original string) error {
 		}
 	}
 
+	// The SRV lookup is done after the options have been parsed because it depends on the srvServiceName option.
+	parsedHosts := strings.Split(hosts, ",")
+	if p.Scheme == SchemeMongoDBSRV {
+		if p.SRVServiceName == "" {
+			p.SRVServiceName = dns.DefaultSRVServiceName
+		}
+		parsedHosts, err = p.dnsResolver.ParseHostsWithServiceName(hosts, p.SRVServiceName, true)
+		if err != nil {
+			return err
+		}
+		parsedHosts = SelectSRVHosts(parsedHosts, p.SRVMaxHosts)
+	}
+
+	for _, host := range parsedHosts {
+		err = p.addHost(host)
+		if err != nil {
+			return internal.WrapErrorf(err, "invalid host \"%s\"", host)
+		}
+	}
+	if len(p.Hosts) == 0 {
+		return fmt.Errorf("must have at least 1 host")
+	}
+
+	if err = p.validateSRV(); err != nil {
+		return err
+	}
+
+	if err = p.validateLoadBalanced(); err != nil {
+		return err
+	}
+
+	if err = p.validateProxy(); err != nil {
+		return err
+	}
+
 	err = p.setDefaultAuthParams(extractedDatabase.db)
 	if err != nil {
 		return err
@@ -273,6 +324,72 @@ func (p *parser) parse(original string) error {
 	return nil
 }
 
+func (p *parser) validateSRV() error {
+	if p.Scheme != SchemeMongoDBSRV {
+		if p.srvMaxSet {
+			return errors.New("srvMaxHosts can only be specified with mongodb+srv")
+		}
+		if p.srvNameSet {
+			return errors.New("srvServiceName can only be specified with mongodb+srv")
+		}
+		return nil
+	}
+	if p.SRVMaxHosts > 0 && p.ReplicaSet != "" {
+		return errors.New("srvMaxHosts cannot be specified with replicaSet")
+	}
+	return nil
+}
+
+func (p *parser) validateProxy() error {
+	if p.ProxyHost == "" {
+		if p.ProxyPortSet {
+			return errors.New("proxyPort cannot be specified without proxyHost")
+		}
+		if p.ProxyUsername != "" || p.ProxyPassword != "" {
+			return errors.New("proxyUsername and proxyPassword cannot be specified without proxyHost")
+		}
+		return nil
+	}
+	if (p.ProxyUsername == "") != (p.ProxyPassword == "") {
+		return errors.New("proxyUsername and proxyPassword must be specified together")
+	}
+	return nil
+}
+
+func (p *parser) validateLoadBalanced() error {
+	if !p.LoadBalanced {
+		return nil
+	}
+	if len(p.Hosts) > 1 {
+		return errors.New("loadBalanced cannot be set to true if multiple hosts are specified")
+	}
+	if p.ReplicaSet != "" {
+		return errors.New("loadBalanced cannot be set to true if a replica set name is specified")
+	}
+	if p.Connect == SingleConnect {
+		return errors.New("loadBalanced cannot be set to true if a direct connection is specified")
+	}
+	if p.SRVMaxHosts > 0 {
+		return errors.New("loadBalanced cannot be set to true if srvMaxHosts is specified")
+	}
+	return nil
+}
+
+// SelectSRVHosts returns at most maxHosts hosts chosen at random from hosts. If maxHosts is 0 or is greater than or
+// equal to the number of hosts, all of the hosts are returned.
+func SelectSRVHosts(hosts []string, maxHosts int) []string {
+	if maxHosts <= 0 || maxHosts >= len(hosts) {
+		return hosts
+	}
+
+	shuffled := append([]string(nil), hosts...)
+	for i := len(shuffled) - 1; i > 0; i-- {
+		j := rand.Intn(i + 1)
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	}
+	return shuffled[:maxHosts]
+}
+
 func (p *parser) setDefaultAuthParams(dbName string) error {
 	switch strings.ToLower(p.AuthMechanism) {
 	case "plain":
@@ -516,6 +633,17 @@ func (p *parser) addOption(pair string) error {
 		}
 
 		p.JSet = true
+	case "loadbalanced":
+		switch value {
+		case "true":
+			p.LoadBalanced = true
+		case "false":
+			p.LoadBalanced = false
+		default:
+			return fmt.Errorf("invalid value for %s: %s", key, value)
+		}
+
+		p.LoadBalancedSet = true
 	case "localthresholdms":
 		n, err := strconv.Atoi(value)
 		if err != nil || n < 0 {
@@ -530,6 +658,13 @@ func (p *parser) addOption(pair string) error {
 		}
 		p.MaxConnIdleTime = time.Duration(n) * time.Millisecond
 		p.MaxConnIdleTimeSet = true
+	case "maxconnecting":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid value for %s: %s", key, value)
+		}
+		p.MaxConnecting = uint64(n)
+		p.MaxConnectingSet = true
 	case "maxpoolsize":
 		n, err := strconv.Atoi(value)
 		if err != nil || n < 0 {
@@ -544,6 +679,22 @@ func (p *parser) addOption(pair string) error {
 		}
 		p.MinPoolSize = uint64(n)
 		p.MinPoolSizeSet = true
+	case "proxyhost":
+		if value == "" {
+			return fmt.Errorf("invalid value for %s: %s", key, value)
+		}
+		p.ProxyHost = value
+	case "proxyport":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("invalid value for %s: %s", key, value)
+		}
+		p.ProxyPort = n
+		p.ProxyPortSet = true
+	case "proxyusername":
+		p.ProxyUsername = value
+	case "proxypassword":
+		p.ProxyPassword = value
 	case "readconcernlevel":
 		p.ReadConcernLevel = value
 	case "readpreference":
@@ -595,6 +746,19 @@ func (p *parser) addOption(pair string) error {
 		}
 
 		p.RetryReadsSet = true
+	case "srvmaxhosts":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid value for %s: %s", key, value)
+		}
+		p.SRVMaxHosts = n
+		p.srvMaxSet = true
+	case "srvservicename":
+		if value == "" {
+			return fmt.Errorf("invalid value for %s: %s", key, value)
+		}
+		p.SRVServiceName = value
+		p.srvNameSet = true
 	case "serverselectiontimeoutms":
 		n, err := strconv.Atoi(value)
 		if err != nil || n < 0 {
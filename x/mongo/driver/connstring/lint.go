@@ -0,0 +1,169 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package connstring
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/x/mongo/driver/dns"
+)
+
+// LintCategory is the category of a LintWarning.
+type LintCategory string
+
+// These constants are the categories of the problems reported by Lint.
+const (
+	// LintUnknownOption is reported for options that are not recognized and are therefore ignored.
+	LintUnknownOption LintCategory = "unknownOption"
+	// LintDeprecatedOption is reported for options that have been superseded by another option.
+	LintDeprecatedOption LintCategory = "deprecatedOption"
+	// LintDuplicateOption is reported for options that are specified more than once.
+	LintDuplicateOption LintCategory = "duplicateOption"
+	// LintConflictingOptions is reported for options that have no effect or contradict each other.
+	LintConflictingOptions LintCategory = "conflictingOptions"
+	// LintInsecure is reported for settings that weaken the security of connections.
+	LintInsecure LintCategory = "insecure"
+)
+
+// LintWarning is a problem found in a connection string by Lint. Options contains the lower-case names of the options
+// that caused the warning.
+type LintWarning struct {
+	Category LintCategory
+	Options  []string
+	Message  string
+}
+
+// String returns a description of the warning.
+func (w LintWarning) String() string {
+	return fmt.Sprintf("%s: %s", w.Category, w.Message)
+}
+
+// deprecatedOptions maps deprecated options to the options that replace them.
+var deprecatedOptions = map[string]string{
+	"ssl":                             "tls",
+	"sslclientcertificatekeyfile":     "tlsCertificateKeyFile",
+	"sslclientcertificatekeypassword": "tlsCertificateKeyFilePassword",
+	"sslinsecure":                     "tlsInsecure",
+	"sslcertificateauthorityfile":     "tlsCAFile",
+	"wtimeout":                        "wtimeoutMS",
+	"heartbeatintervalms":             "heartbeatFrequencyMS",
+	"maxstaleness":                    "maxStalenessSeconds",
+}
+
+// tlsOptions contains the options that configure and enable TLS.
+var tlsOptions = []string{
+	"tlscafile", "sslcertificateauthorityfile", "tlscertificatekeyfile", "sslclientcertificatekeyfile",
+	"tlscertificatekeyfilepassword", "sslclientcertificatekeypassword", "tlscertificatefile", "tlsprivatekeyfile",
+	"tlsinsecure", "sslinsecure",
+}
+
+// lintResolver answers SRV lookups with a placeholder host and TXT lookups with no records, so that mongodb+srv
+// connection strings can be linted without DNS queries.
+var lintResolver = &dns.Resolver{
+	LookupSRV: func(_, _, name string) (string, []*net.SRV, error) {
+		return "", []*net.SRV{{Target: "srv-host." + name, Port: 27017}}, nil
+	},
+	LookupTXT: func(string) ([]string, error) { return nil, nil },
+}
+
+// Lint parses the connection string s and returns warnings about options that are unknown, deprecated, duplicated,
+// conflicting, or insecure. It does not connect to the deployment or perform DNS lookups, so options from the TXT
+// record of a mongodb+srv connection string are not checked, and warnings that depend on the topology are based on
+// the options alone. An error is returned if the connection string cannot be parsed. The warnings are sorted by
+// category.
+func Lint(s string) ([]LintWarning, error) {
+	cs, err := ParseWithResolver(s, lintResolver, "", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []LintWarning
+	warn := func(category LintCategory, opts []string, format string, args ...interface{}) {
+		warnings = append(warnings, LintWarning{Category: category, Options: opts, Message: fmt.Sprintf(format, args...)})
+	}
+	set := func(opt string) bool { return len(cs.Options[opt]) > 0 }
+	primary := cs.ReadPreference == "" || strings.EqualFold(cs.ReadPreference, "primary")
+
+	for _, opt := range sortedOptionNames(cs.UnknownOptions) {
+		warn(LintUnknownOption, []string{opt}, "unknown option %q is ignored", opt)
+	}
+	for _, opt := range sortedOptionNames(cs.Options) {
+		if replacement, ok := deprecatedOptions[opt]; ok {
+			warn(LintDeprecatedOption, []string{opt}, "option %q is deprecated, use %q instead", opt, replacement)
+		}
+		if n := len(cs.Options[opt]); n > 1 && opt != "readpreferencetags" {
+			warn(LintDuplicateOption, []string{opt}, "option %q is specified %d times, only the last value is used",
+				opt, n)
+		}
+	}
+
+	if cs.RetryWritesSet && cs.RetryWrites && cs.Connect == SingleConnect && cs.ReplicaSet == "" {
+		warn(LintConflictingOptions, []string{"retrywrites", "connect"}, "retryWrites has no effect on standalone "+
+			"servers, and a direct connection without a replicaSet is usually to a standalone server")
+	}
+	if cs.WNumberSet && cs.WNumber == 0 {
+		if cs.RetryWritesSet && cs.RetryWrites {
+			warn(LintConflictingOptions, []string{"w", "retrywrites"}, "unacknowledged writes (w=0) are not retried")
+		}
+		if cs.WTimeoutSet {
+			warn(LintConflictingOptions, []string{"w", "wtimeoutms"},
+				"wtimeoutMS has no effect on unacknowledged writes (w=0)")
+		}
+	}
+	if cs.MaxPoolSizeSet && cs.MinPoolSizeSet && cs.MaxPoolSize != 0 && cs.MinPoolSize > cs.MaxPoolSize {
+		warn(LintConflictingOptions, []string{"minpoolsize", "maxpoolsize"},
+			"minPoolSize %d is greater than maxPoolSize %d", cs.MinPoolSize, cs.MaxPoolSize)
+	}
+	if cs.MaxStalenessSet && primary {
+		warn(LintConflictingOptions, []string{"maxstalenessseconds", "readpreference"},
+			"maxStalenessSeconds cannot be used with the primary read preference")
+	}
+	if len(cs.ReadPreferenceTagSets) > 0 && primary {
+		warn(LintConflictingOptions, []string{"readpreferencetags", "readpreference"},
+			"readPreferenceTags cannot be used with the primary read preference")
+	}
+	if cs.Connect == SingleConnect && len(cs.Hosts) > 1 {
+		warn(LintConflictingOptions, []string{"connect"}, "a direct connection can only be made to a single host")
+	}
+
+	// the TLS options enable TLS, so whether TLS is enabled depends on their order if tls=false is also specified
+	if tlsDisabled := append(cs.Options["tls"], cs.Options["ssl"]...); len(tlsDisabled) > 0 &&
+		tlsDisabled[len(tlsDisabled)-1] == "false" {
+		for _, opt := range tlsOptions {
+			if set(opt) {
+				warn(LintConflictingOptions, []string{opt, "tls"}, "option %q enables TLS, which conflicts with tls=false",
+					opt)
+			}
+		}
+	}
+	if cs.SSL && cs.SSLInsecure {
+		warn(LintInsecure, []string{"tlsinsecure"}, "tlsInsecure disables certificate and host name verification")
+		if cs.SSLCaFileSet {
+			warn(LintConflictingOptions, []string{"tlscafile", "tlsinsecure"},
+				"tlsCAFile has no effect because tlsInsecure disables certificate verification")
+		}
+	}
+	if !cs.SSL && strings.EqualFold(cs.AuthMechanism, "PLAIN") {
+		warn(LintInsecure, []string{"authmechanism", "tls"},
+			"the PLAIN mechanism sends the password in clear text without TLS")
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool { return warnings[i].Category < warnings[j].Category })
+	return warnings, nil
+}
+
+func sortedOptionNames(opts map[string][]string) []string {
+	names := make([]string, 0, len(opts))
+	for name := range opts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,128 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package connstring
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces the secrets removed from connection strings by Redact.
+const Redacted = "REDACTED"
+
+// secretOptions contains the lower-case names of the URI options whose values are secrets.
+var secretOptions = map[string]bool{
+	"proxypassword":                   true,
+	"tlscertificatekeyfilepassword":   true,
+	"sslclientcertificatekeypassword": true,
+}
+
+// secretAuthProperties contains the upper-case names of the authMechanismProperties that are secrets.
+var secretAuthProperties = map[string]bool{
+	"AWS_SESSION_TOKEN": true,
+}
+
+// Redact returns the connection string s with its secrets replaced by Redacted, so that it can be included in logs,
+// error messages, and diagnostics. The password in the user information, the proxyPassword and
+// tlsCertificateKeyFilePassword options, and the AWS_SESSION_TOKEN auth mechanism property are redacted. Everything
+// else, including the username, is preserved as it was written. Redact does not validate s, and redacts what the
+// driver would interpret as secrets even if s is not a valid connection string.
+func Redact(s string) string {
+	var b strings.Builder
+
+	rest := s
+	if idx := strings.Index(rest, "://"); idx != -1 {
+		b.WriteString(rest[:idx+3])
+		rest = rest[idx+3:]
+	}
+
+	// The user information ends at the last @ sign in the authority, which ends at the first / or ?, so that a
+	// password containing an unescaped @ sign is redacted as a whole.
+	authority := rest
+	if idx := strings.IndexAny(rest, "/?"); idx != -1 {
+		authority = rest[:idx]
+	}
+	if at := strings.LastIndex(authority, "@"); at != -1 {
+		userInfo := rest[:at]
+		if colon := strings.Index(userInfo, ":"); colon != -1 {
+			userInfo = userInfo[:colon+1] + Redacted
+		}
+		b.WriteString(userInfo + "@")
+		rest = rest[at+1:]
+	}
+
+	query := strings.Index(rest, "?")
+	if query == -1 {
+		b.WriteString(rest)
+		return b.String()
+	}
+	b.WriteString(rest[:query+1])
+	rest = rest[query+1:]
+
+	for len(rest) > 0 {
+		pair := rest
+		var sep string
+		if idx := strings.IndexAny(rest, "&;"); idx != -1 {
+			pair, sep = rest[:idx], rest[idx:idx+1]
+			rest = rest[idx+1:]
+		} else {
+			rest = ""
+		}
+		b.WriteString(redactOption(pair) + sep)
+	}
+	return b.String()
+}
+
+// redactOption redacts the value of a key=value pair from the query of a connection string if it is a secret.
+func redactOption(pair string) string {
+	kv := strings.SplitN(pair, "=", 2)
+	if len(kv) != 2 {
+		return pair
+	}
+	key, err := url.QueryUnescape(kv[0])
+	if err != nil {
+		key = kv[0]
+	}
+
+	switch key = strings.ToLower(key); {
+	case secretOptions[key]:
+		return kv[0] + "=" + Redacted
+	case key == "authmechanismproperties":
+		return kv[0] + "=" + redactAuthProperties(kv[1])
+	default:
+		return pair
+	}
+}
+
+// redactAuthProperties redacts the values of the secret properties in an authMechanismProperties value, which is a
+// comma-separated list of key:value pairs.
+func redactAuthProperties(value string) string {
+	unescaped, err := url.QueryUnescape(value)
+	if err != nil {
+		// the value is invalid, so it is impossible to tell which parts of it are secrets
+		return Redacted
+	}
+
+	props := strings.Split(unescaped, ",")
+	redacted := false
+	for i, prop := range props {
+		kv := strings.SplitN(prop, ":", 2)
+		if len(kv) == 2 && secretAuthProperties[strings.ToUpper(kv[0])] {
+			props[i] = kv[0] + ":" + Redacted
+			redacted = true
+		}
+	}
+	if !redacted {
+		return value
+	}
+
+	result := strings.Join(props, ",")
+	if unescaped != value {
+		result = url.QueryEscape(result)
+	}
+	return result
+}
@@ -9,11 +9,14 @@ package driver
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/mongocrypt"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/mongocrypt/options"
@@ -33,14 +36,30 @@ type KeyRetrieverFn func(ctx context.Context, filter bsoncore.Document) ([]bsonc
 // MarkCommandFn is a callback used to add encryption markings to a command.
 type MarkCommandFn func(ctx context.Context, db string, cmd bsoncore.Document) (bsoncore.Document, error)
 
+// KmsCredentialsFn is a callback used to retrieve the credentials of a KMS provider. It returns the options of the
+// provider and the time at which they expire. A zero expiration means the credentials are not cached.
+type KmsCredentialsFn func(ctx context.Context, provider string) (map[string]interface{}, time.Time, error)
+
 // CryptOptions specifies options to configure a Crypt instance.
 type CryptOptions struct {
 	CollInfoFn           CollectionInfoFn
 	KeyFn                KeyRetrieverFn
 	MarkFn               MarkCommandFn
 	KmsProviders         map[string]map[string]interface{}
+	TLSConfig            map[string]*tls.Config
+	KmsCredentialsFn     map[string]KmsCredentialsFn
 	SchemaMap            map[string]bsoncore.Document
 	BypassAutoEncryption bool
+
+	// CryptSharedLibPath, if set, is the path of the crypt_shared library. Otherwise, the library is searched for in
+	// CryptSharedLibSearchPaths.
+	CryptSharedLibPath        string
+	CryptSharedLibSearchPaths []string
+	// CryptSharedLibRequired causes NewCrypt to return an error if the crypt_shared library cannot be loaded.
+	CryptSharedLibRequired bool
+	// CryptSharedLibMinVersion, if set, is the minimum version of the crypt_shared library in the form
+	// "major.minor.patch".
+	CryptSharedLibMinVersion string
 }
 
 // Crypt consumes the libmongocrypt.MongoCrypt type to iterate the mongocrypt state machine and perform encryption
@@ -50,27 +69,116 @@ type Crypt struct {
 	collInfoFn CollectionInfoFn
 	keyFn      KeyRetrieverFn
 	markFn     MarkCommandFn
+	tlsConfig  map[string]*tls.Config
+	// onDemand contains the names of the KMS providers that were configured with an empty document and whose
+	// credentials are fetched from the environment when they are needed.
+	onDemand    []string
+	credentials *kmsCredentialsCache
+	// cryptSharedLibVersion is the version string of the loaded crypt_shared library, or the empty string if the
+	// library was not loaded.
+	cryptSharedLibVersion string
 
 	BypassAutoEncryption bool
 }
 
 // NewCrypt creates a new Crypt instance configured with the given AutoEncryptionOptions.
 func NewCrypt(opts *CryptOptions) (*Crypt, error) {
+	if err := validateKmsTLSConfig(opts); err != nil {
+		return nil, err
+	}
 	c := &Crypt{
 		collInfoFn:           opts.CollInfoFn,
 		keyFn:                opts.KeyFn,
 		markFn:               opts.MarkFn,
+		tlsConfig:            opts.TLSConfig,
+		onDemand:             onDemandKmsProviders(opts),
+		credentials:          newKmsCredentialsCache(opts.KmsCredentialsFn),
 		BypassAutoEncryption: opts.BypassAutoEncryption,
 	}
-	mc, err := mongocrypt.NewMongoCrypt(createMongoCryptOptions(opts))
+	mcOpts, err := createMongoCryptOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	mc, err := mongocrypt.NewMongoCrypt(mcOpts)
 	if err != nil {
 		return nil, err
 	}
+	if err = checkCryptSharedLib(opts, mc.CryptSharedLibVersion()); err != nil {
+		mc.Close()
+		return nil, err
+	}
 
 	c.mongoCrypt = mc
+	c.cryptSharedLibVersion = mc.CryptSharedLibVersionString()
 	return c, nil
 }
 
+// validateKmsTLSConfig returns an error if a TLS configuration is given for a KMS provider that is not configured,
+// which is usually a misspelled provider name, or if a configuration skips the verification of the KMS certificate.
+func validateKmsTLSConfig(opts *CryptOptions) error {
+	for provider, cfg := range opts.TLSConfig {
+		if _, ok := opts.KmsProviders[provider]; !ok {
+			return fmt.Errorf("TLS configuration given for KMS provider %q, which is not configured", provider)
+		}
+		if cfg != nil && cfg.InsecureSkipVerify {
+			return fmt.Errorf("insecure TLS configuration given for KMS provider %q", provider)
+		}
+	}
+	return nil
+}
+
+// checkCryptSharedLib returns an error if the crypt_shared library is required but was not loaded or if the loaded
+// version is older than the minimum version. A version of 0 means that the library was not loaded.
+func checkCryptSharedLib(opts *CryptOptions, version uint64) error {
+	if version == 0 {
+		if opts.CryptSharedLibRequired {
+			return errors.New("the crypt_shared library is required but could not be loaded")
+		}
+		return nil
+	}
+	if opts.CryptSharedLibMinVersion == "" {
+		return nil
+	}
+
+	minVersion, err := encodeCryptSharedLibVersion(opts.CryptSharedLibMinVersion)
+	if err != nil {
+		return err
+	}
+	if version < minVersion {
+		return fmt.Errorf("the crypt_shared library version %d.%d.%d is older than the required version %s",
+			version>>48, version>>32&0xffff, version>>16&0xffff, opts.CryptSharedLibMinVersion)
+	}
+	return nil
+}
+
+// encodeCryptSharedLibVersion encodes a "major.minor.patch" version in the format used by libmongocrypt.
+func encodeCryptSharedLibVersion(version string) (uint64, error) {
+	parts := strings.Split(version, ".")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid crypt_shared library version %q", version)
+	}
+
+	var encoded uint64
+	for i := 0; i < 3; i++ {
+		var n uint64
+		if i < len(parts) {
+			var err error
+			if n, err = strconv.ParseUint(parts[i], 10, 16); err != nil {
+				return 0, fmt.Errorf("invalid crypt_shared library version %q", version)
+			}
+		}
+		encoded |= n << uint(48-16*i)
+	}
+	return encoded, nil
+}
+
+// CryptSharedLibVersion returns the version string of the loaded crypt_shared library, or the empty string if the
+// library was not loaded. When the library is loaded, commands are marked for encryption by the library instead of
+// by mongocryptd.
+func (c *Crypt) CryptSharedLibVersion() string {
+	return c.cryptSharedLibVersion
+}
+
 // Encrypt encrypts the given command.
 func (c *Crypt) Encrypt(ctx context.Context, db string, cmd bsoncore.Document) (bsoncore.Document, error) {
 	if c.BypassAutoEncryption {
@@ -108,6 +216,38 @@ func (c *Crypt) CreateDataKey(ctx context.Context, kmsProvider string, opts *opt
 	return c.executeStateMachine(ctx, cryptCtx, "")
 }
 
+// RewrapDataKey re-encrypts the data keys that match the given filter with the given options and returns the
+// re-encrypted key documents.
+func (c *Crypt) RewrapDataKey(ctx context.Context, filter bsoncore.Document,
+	opts *options.RewrapManyDataKeyOptions) ([]bsoncore.Document, error) {
+
+	cryptCtx, err := c.mongoCrypt.CreateRewrapManyDataKeyContext(filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cryptCtx.Close()
+
+	res, err := c.executeStateMachine(ctx, cryptCtx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// the result is a document of the form {v: [key documents]}
+	arr, ok := res.Lookup("v").ArrayOK()
+	if !ok {
+		return nil, nil
+	}
+	values, err := arr.Values()
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]bsoncore.Document, 0, len(values))
+	for _, val := range values {
+		docs = append(docs, val.Document())
+	}
+	return docs, nil
+}
+
 // EncryptExplicit encrypts the given value with the given options.
 func (c *Crypt) EncryptExplicit(ctx context.Context, val bsoncore.Value, opts *options.ExplicitEncryptionOptions) (byte, []byte, error) {
 	idx, doc := bsoncore.AppendDocumentStart(nil)
@@ -129,6 +269,33 @@ func (c *Crypt) EncryptExplicit(ctx context.Context, val bsoncore.Value, opts *o
 	return sub, data, nil
 }
 
+// EncryptExplicitExpression encrypts the bounds of the given match or aggregate expression with the given options and
+// returns the expression with the encrypted bounds.
+func (c *Crypt) EncryptExplicitExpression(ctx context.Context, expr bsoncore.Document,
+	opts *options.ExplicitEncryptionOptions) (bsoncore.Document, error) {
+
+	idx, doc := bsoncore.AppendDocumentStart(nil)
+	doc = bsoncore.AppendDocumentElement(doc, "v", expr)
+	doc, _ = bsoncore.AppendDocumentEnd(doc, idx)
+
+	cryptCtx, err := c.mongoCrypt.CreateExplicitEncryptionExpressionContext(doc, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cryptCtx.Close()
+
+	res, err := c.executeStateMachine(ctx, cryptCtx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, ok := res.Lookup("v").DocumentOK()
+	if !ok {
+		return nil, errors.New("encrypted expression is not a document")
+	}
+	return encrypted, nil
+}
+
 // DecryptExplicit decrypts the given encrypted value.
 func (c *Crypt) DecryptExplicit(ctx context.Context, subtype byte, data []byte) (bsoncore.Value, error) {
 	idx, doc := bsoncore.AppendDocumentStart(nil)
@@ -167,6 +334,8 @@ func (c *Crypt) executeStateMachine(ctx context.Context, cryptCtx *mongocrypt.Co
 			err = c.retrieveKeys(ctx, cryptCtx)
 		case mongocrypt.NeedKms:
 			err = c.decryptKeys(ctx, cryptCtx)
+		case mongocrypt.NeedKmsCredentials:
+			err = c.provideKmsProviders(ctx, cryptCtx)
 		case mongocrypt.Ready:
 			return cryptCtx.Finish()
 		default:
@@ -234,6 +403,14 @@ func (c *Crypt) retrieveKeys(ctx context.Context, cryptCtx *mongocrypt.Context)
 	return cryptCtx.CompleteOperation()
 }
 
+func (c *Crypt) provideKmsProviders(ctx context.Context, cryptCtx *mongocrypt.Context) error {
+	providers, err := c.credentials.fetch(ctx, c.onDemand)
+	if err != nil {
+		return err
+	}
+	return cryptCtx.ProvideKmsProviders(providers)
+}
+
 func (c *Crypt) decryptKeys(ctx context.Context, cryptCtx *mongocrypt.Context) error {
 	for {
 		kmsCtx := cryptCtx.NextKmsContext()
@@ -265,7 +442,11 @@ func (c *Crypt) decryptKey(ctx context.Context, kmsCtx *mongocrypt.KmsContext) e
 		addr = fmt.Sprintf("%s:%d", host, defaultKmsPort)
 	}
 
-	conn, err := tls.Dial("tcp", addr, &tls.Config{})
+	tlsCfg := &tls.Config{}
+	if cfg, ok := c.tlsConfig[kmsCtx.KMSProvider()]; ok && cfg != nil {
+		tlsCfg = cfg.Clone()
+	}
+	conn, err := tls.Dial("tcp", addr, tlsCfg)
 	if err != nil {
 		return err
 	}
@@ -298,10 +479,37 @@ func (c *Crypt) decryptKey(ctx context.Context, kmsCtx *mongocrypt.KmsContext) e
 	}
 }
 
-func createMongoCryptOptions(opts *CryptOptions) *options.MongoCryptOptions {
-	mcOpts := options.MongoCrypt().SetLocalSchemaMap(opts.SchemaMap)
+// onDemandKmsProviders returns the names of the providers whose credentials are fetched on demand. A provider is
+// configured this way by setting its options to an empty map. Its credentials are then retrieved with its callback or,
+// if it has none, fetched from the environment.
+func onDemandKmsProviders(opts *CryptOptions) []string {
+	var names []string
+	for provider, providerOpts := range opts.KmsProviders {
+		if len(providerOpts) != 0 {
+			continue
+		}
+		_, builtin := kmsCredentialsFetchers[provider]
+		if fn := opts.KmsCredentialsFn[provider]; fn != nil || builtin {
+			names = append(names, provider)
+		}
+	}
+	return names
+}
+
+func createMongoCryptOptions(opts *CryptOptions) (*options.MongoCryptOptions, error) {
+	mcOpts := options.MongoCrypt().SetLocalSchemaMap(opts.SchemaMap).
+		SetCryptSharedLibSearchPaths(opts.CryptSharedLibSearchPaths).
+		SetCryptSharedLibOverridePath(opts.CryptSharedLibPath)
+	// providers without typed options, such as azure, gcp, and named providers like "aws:primary", and providers
+	// whose credentials are fetched on demand are passed to libmongocrypt as a document
+	other := make(map[string]map[string]interface{})
 	// KMS providers options
 	for provider, providerOpts := range opts.KmsProviders {
+		if len(providerOpts) == 0 {
+			other[provider] = providerOpts
+			continue
+		}
+
 		switch provider {
 		case "aws":
 			awsOpts := options.AwsKmsProvider()
@@ -326,8 +534,27 @@ func createMongoCryptOptions(opts *CryptOptions) *options.MongoCryptOptions {
 				}
 			}
 			mcOpts.SetLocalProviderOptions(localOpts)
+		case "kmip":
+			kmipOpts := options.KmipKmsProvider()
+
+			if endpoint, ok := providerOpts["endpoint"]; ok {
+				if endpointStr, ok := endpoint.(string); ok {
+					kmipOpts.SetEndpoint(endpointStr)
+				}
+			}
+			mcOpts.SetKmipProviderOptions(kmipOpts)
+		default:
+			other[provider] = providerOpts
 		}
 	}
 
-	return mcOpts
+	if len(other) > 0 {
+		doc, err := bson.Marshal(other)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling KMS providers: %v", err)
+		}
+		mcOpts.SetKmsProviders(doc)
+	}
+	mcOpts.SetUseNeedKmsCredentialsState(len(onDemandKmsProviders(opts)) > 0)
+	return mcOpts, nil
 }
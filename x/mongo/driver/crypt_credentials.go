@@ -0,0 +1,207 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// The endpoints of the credential services. They are variables so they can be overridden in tests.
+var (
+	awsContainerCredentialsHost = "http://169.254.170.2"
+	azureIMDSTokenURL           = "http://169.254.169.254/metadata/identity/oauth2/token" +
+		"?api-version=2018-02-01&resource=https%3A%2F%2Fvault.azure.net"
+	gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// kmsCredentialsExpiryWindow is how long before their expiration cached credentials are refreshed.
+const kmsCredentialsExpiryWindow = time.Minute
+
+// kmsCredentialsFetcher fetches the credentials of a KMS provider from the environment. It returns the provider
+// options and the time at which they expire. A zero expiration means the credentials are not cached.
+type kmsCredentialsFetcher func(ctx context.Context, client *http.Client) (map[string]interface{}, time.Time, error)
+
+// kmsCredentialsFetchers contains the KMS providers that support on-demand credentials.
+var kmsCredentialsFetchers = map[string]kmsCredentialsFetcher{
+	"aws":   fetchAwsCredentials,
+	"azure": fetchAzureCredentials,
+	"gcp":   fetchGcpCredentials,
+}
+
+type cachedKmsCredentials struct {
+	opts    map[string]interface{}
+	expires time.Time
+}
+
+// kmsCredentialsCache fetches on-demand KMS credentials and caches them until shortly before they expire. The
+// credentials of a provider are retrieved with its callback, if it has one, or fetched from the environment.
+type kmsCredentialsCache struct {
+	client    *http.Client
+	callbacks map[string]KmsCredentialsFn
+
+	mu    sync.Mutex
+	cache map[string]cachedKmsCredentials
+}
+
+func newKmsCredentialsCache(callbacks map[string]KmsCredentialsFn) *kmsCredentialsCache {
+	return &kmsCredentialsCache{
+		client:    &http.Client{Timeout: defaultKmsTimeout},
+		callbacks: callbacks,
+		cache:     make(map[string]cachedKmsCredentials),
+	}
+}
+
+// fetch returns a KMS providers document with the credentials of the given providers.
+func (k *kmsCredentialsCache) fetch(ctx context.Context, providers []string) (bsoncore.Document, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	kmsProviders := make(map[string]map[string]interface{}, len(providers))
+	for _, provider := range providers {
+		cached, ok := k.cache[provider]
+		if !ok || time.Now().Add(kmsCredentialsExpiryWindow).After(cached.expires) {
+			opts, expires, err := k.retrieve(ctx, provider)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching credentials for KMS provider %q: %v", provider, err)
+			}
+			cached = cachedKmsCredentials{opts: opts, expires: expires}
+			if !expires.IsZero() {
+				k.cache[provider] = cached
+			}
+		}
+		kmsProviders[provider] = cached.opts
+	}
+
+	return bson.Marshal(kmsProviders)
+}
+
+// retrieve retrieves the credentials of the given provider with its callback or from the environment.
+func (k *kmsCredentialsCache) retrieve(ctx context.Context, provider string) (map[string]interface{}, time.Time, error) {
+	if fn := k.callbacks[provider]; fn != nil {
+		opts, expires, err := fn(ctx, provider)
+		if err == nil && len(opts) == 0 {
+			err = errors.New("credentials callback returned no credentials")
+		}
+		return opts, expires, err
+	}
+
+	fetcher, ok := kmsCredentialsFetchers[provider]
+	if !ok {
+		return nil, time.Time{}, fmt.Errorf("on-demand credentials are not supported for KMS provider %q", provider)
+	}
+	return fetcher(ctx, k.client)
+}
+
+// fetchAwsCredentials reads AWS credentials from the environment variables or, if they are not set, from the ECS
+// container credentials endpoint.
+func fetchAwsCredentials(ctx context.Context, client *http.Client) (map[string]interface{}, time.Time, error) {
+	if accessKeyID := os.Getenv("AWS_ACCESS_KEY_ID"); accessKeyID != "" {
+		opts := map[string]interface{}{
+			"accessKeyId":     accessKeyID,
+			"secretAccessKey": os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		}
+		if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+			opts["sessionToken"] = token
+		}
+		return opts, time.Time{}, nil
+	}
+
+	relativeURI := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI")
+	if relativeURI == "" {
+		return nil, time.Time{}, fmt.Errorf("no AWS credentials found in the environment")
+	}
+
+	var res struct {
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string
+		Token           string
+		Expiration      time.Time
+	}
+	if err := getJSON(ctx, client, awsContainerCredentialsHost+relativeURI, nil, &res); err != nil {
+		return nil, time.Time{}, err
+	}
+	opts := map[string]interface{}{
+		"accessKeyId":     res.AccessKeyID,
+		"secretAccessKey": res.SecretAccessKey,
+	}
+	if res.Token != "" {
+		opts["sessionToken"] = res.Token
+	}
+	return opts, res.Expiration, nil
+}
+
+// fetchAzureCredentials requests an access token for Azure Key Vault from the Azure Instance Metadata Service.
+func fetchAzureCredentials(ctx context.Context, client *http.Client) (map[string]interface{}, time.Time, error) {
+	return fetchAccessToken(ctx, client, azureIMDSTokenURL, map[string]string{"Metadata": "true"})
+}
+
+// fetchGcpCredentials requests an access token for the default service account from the GCP metadata server.
+func fetchGcpCredentials(ctx context.Context, client *http.Client) (map[string]interface{}, time.Time, error) {
+	return fetchAccessToken(ctx, client, gcpMetadataTokenURL, map[string]string{"Metadata-Flavor": "Google"})
+}
+
+// fetchAccessToken requests an OAuth access token from a metadata service.
+func fetchAccessToken(ctx context.Context, client *http.Client, url string,
+	header map[string]string) (map[string]interface{}, time.Time, error) {
+
+	var res struct {
+		AccessToken string `json:"access_token"`
+		// Azure reports the lifetime of the token as a string and GCP reports it as a number.
+		ExpiresIn json.Number `json:"expires_in"`
+	}
+	if err := getJSON(ctx, client, url, header, &res); err != nil {
+		return nil, time.Time{}, err
+	}
+	if res.AccessToken == "" {
+		return nil, time.Time{}, fmt.Errorf("no access token in response from %s", url)
+	}
+
+	var expires time.Time
+	if secs, err := strconv.Atoi(res.ExpiresIn.String()); err == nil {
+		expires = time.Now().Add(time.Duration(secs) * time.Second)
+	}
+	return map[string]interface{}{"accessToken": res.AccessToken}, expires, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, header map[string]string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for k, val := range header {
+		req.Header.Set(k, val)
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s: %s", resp.StatusCode, url, body)
+	}
+	return json.Unmarshal(body, v)
+}
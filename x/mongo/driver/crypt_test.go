@@ -0,0 +1,179 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+func TestCreateMongoCryptOptions(t *testing.T) {
+	mcOpts, err := createMongoCryptOptions(&CryptOptions{
+		KmsProviders: map[string]map[string]interface{}{
+			"kmip":        {"endpoint": "kmip.example.com:5696"},
+			"local":       {"key": []byte("key")},
+			"aws:primary": {"accessKeyId": "id", "secretAccessKey": "secret"},
+			"azure":       {},
+		},
+	})
+	assert.Nil(t, err, "createMongoCryptOptions error: %v", err)
+
+	assert.NotNil(t, mcOpts.KmipProviderOpts, "expected KMIP provider options")
+	assert.Equal(t, "kmip.example.com:5696", mcOpts.KmipProviderOpts.Endpoint,
+		"unexpected KMIP endpoint %v", mcOpts.KmipProviderOpts.Endpoint)
+	assert.NotNil(t, mcOpts.LocalProviderOpts, "expected local provider options")
+	assert.Nil(t, mcOpts.AwsProviderOpts, "expected no AWS provider options")
+
+	named, err := mcOpts.KmsProviders.LookupErr("aws:primary", "accessKeyId")
+	assert.Nil(t, err, "expected named provider options, got %v", mcOpts.KmsProviders)
+	assert.Equal(t, "id", named.StringValue(), "unexpected access key ID %v", named)
+	azure, err := mcOpts.KmsProviders.LookupErr("azure")
+	assert.Nil(t, err, "expected azure provider options, got %v", mcOpts.KmsProviders)
+	elems, _ := azure.Document().Elements()
+	assert.Equal(t, 0, len(elems), "expected empty azure provider options, got %v", azure)
+	assert.True(t, mcOpts.UseNeedKmsCredentialsState, "expected on-demand credentials to be enabled")
+
+	onDemand := onDemandKmsProviders(&CryptOptions{
+		KmsProviders: map[string]map[string]interface{}{"azure": {}, "kmip": {}, "vault": {}},
+		KmsCredentialsFn: map[string]KmsCredentialsFn{
+			"vault": func(context.Context, string) (map[string]interface{}, time.Time, error) { return nil, time.Time{}, nil },
+		},
+	})
+	sort.Strings(onDemand)
+	assert.Equal(t, []string{"azure", "vault"}, onDemand, "unexpected on-demand providers %v", onDemand)
+}
+
+func TestKmsCredentials(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case r.Header.Get("Metadata") == "true":
+			_, _ = fmt.Fprint(w, `{"access_token": "azure-token", "expires_in": "3600"}`)
+		case r.Header.Get("Metadata-Flavor") == "Google":
+			_, _ = fmt.Fprint(w, `{"access_token": "gcp-token", "expires_in": 3600}`)
+		case r.URL.Path == "/creds":
+			_, _ = fmt.Fprint(w, `{"AccessKeyId": "id", "SecretAccessKey": "secret", "Token": "token",
+				"Expiration": "2100-01-01T00:00:00Z"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	defer func(aws, azure, gcp string) {
+		awsContainerCredentialsHost, azureIMDSTokenURL, gcpMetadataTokenURL = aws, azure, gcp
+	}(awsContainerCredentialsHost, azureIMDSTokenURL, gcpMetadataTokenURL)
+	awsContainerCredentialsHost, azureIMDSTokenURL, gcpMetadataTokenURL = srv.URL, srv.URL+"/azure", srv.URL+"/gcp"
+
+	defer os.Setenv("AWS_ACCESS_KEY_ID", os.Getenv("AWS_ACCESS_KEY_ID"))
+	defer os.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"))
+	_ = os.Unsetenv("AWS_ACCESS_KEY_ID")
+	_ = os.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "/creds")
+
+	t.Run("fetch", func(t *testing.T) {
+		requests = 0
+		cache := newKmsCredentialsCache(nil)
+		doc, err := cache.fetch(context.Background(), []string{"aws", "azure", "gcp"})
+		assert.Nil(t, err, "fetch error: %v", err)
+
+		var got map[string]map[string]string
+		err = bson.Unmarshal(doc, &got)
+		assert.Nil(t, err, "Unmarshal error: %v", err)
+		want := map[string]map[string]string{
+			"aws":   {"accessKeyId": "id", "secretAccessKey": "secret", "sessionToken": "token"},
+			"azure": {"accessToken": "azure-token"},
+			"gcp":   {"accessToken": "gcp-token"},
+		}
+		assert.Equal(t, want, got, "expected credentials %v, got %v", want, got)
+
+		_, err = cache.fetch(context.Background(), []string{"aws", "azure", "gcp"})
+		assert.Nil(t, err, "fetch error: %v", err)
+		assert.Equal(t, 3, requests, "expected credentials to be cached, got %d requests", requests)
+	})
+	t.Run("callback", func(t *testing.T) {
+		requests = 0
+		var calls []string
+		cache := newKmsCredentialsCache(map[string]KmsCredentialsFn{
+			"aws": func(_ context.Context, provider string) (map[string]interface{}, time.Time, error) {
+				calls = append(calls, provider)
+				return map[string]interface{}{"accessKeyId": "sts-id", "secretAccessKey": "sts-secret"},
+					time.Now().Add(30 * time.Second), nil
+			},
+		})
+		for i := 0; i < 2; i++ {
+			doc, err := cache.fetch(context.Background(), []string{"aws"})
+			assert.Nil(t, err, "fetch error: %v", err)
+			id := doc.Lookup("aws", "accessKeyId").StringValue()
+			assert.Equal(t, "sts-id", id, "unexpected access key ID %q", id)
+		}
+		// credentials that expire within the expiry window are refreshed each time
+		assert.Equal(t, []string{"aws", "aws"}, calls, "unexpected callback calls %v", calls)
+		assert.Equal(t, 0, requests, "expected no requests to the environment, got %d", requests)
+	})
+	t.Run("error", func(t *testing.T) {
+		_ = os.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "/missing")
+		_, err := newKmsCredentialsCache(nil).fetch(context.Background(), []string{"aws"})
+		assert.NotNil(t, err, "expected fetch error")
+	})
+}
+
+func TestCheckCryptSharedLib(t *testing.T) {
+	version, err := encodeCryptSharedLibVersion("6.0.1")
+	assert.Nil(t, err, "encodeCryptSharedLibVersion error: %v", err)
+	assert.Equal(t, uint64(6)<<48|uint64(1)<<16, version, "unexpected encoded version %x", version)
+	_, err = encodeCryptSharedLibVersion("6.x")
+	assert.NotNil(t, err, "expected error for invalid version")
+
+	testCases := []struct {
+		name    string
+		opts    *CryptOptions
+		version uint64
+		wantErr bool
+	}{
+		{"not loaded", &CryptOptions{}, 0, false},
+		{"required but not loaded", &CryptOptions{CryptSharedLibRequired: true}, 0, true},
+		{"required and loaded", &CryptOptions{CryptSharedLibRequired: true}, version, false},
+		{"minimum version", &CryptOptions{CryptSharedLibMinVersion: "6.0"}, version, false},
+		{"older than minimum version", &CryptOptions{CryptSharedLibMinVersion: "6.1.0"}, version, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkCryptSharedLib(tc.opts, tc.version)
+			assert.Equal(t, tc.wantErr, err != nil, "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestValidateKmsTLSConfig(t *testing.T) {
+	providers := map[string]map[string]interface{}{"kmip": {"endpoint": "kmip.example.com"}, "aws:dr": {}}
+	testCases := []struct {
+		name    string
+		cfg     map[string]*tls.Config
+		wantErr bool
+	}{
+		{"configured providers", map[string]*tls.Config{"kmip": {}, "aws:dr": {ServerName: "kms.example.com"}}, false},
+		{"unknown provider", map[string]*tls.Config{"kmlp": {}}, true},
+		{"insecure", map[string]*tls.Config{"kmip": {InsecureSkipVerify: true}}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateKmsTLSConfig(&CryptOptions{KmsProviders: providers, TLSConfig: tc.cfg})
+			assert.Equal(t, tc.wantErr, err != nil, "unexpected error: %v", err)
+		})
+	}
+}
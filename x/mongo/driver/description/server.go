@@ -46,6 +46,8 @@ type Server struct {
 	Members               []address.Address
 	ReadOnly              bool
 	SessionTimeoutMinutes uint32
+	ServerConnectionID    *int32              // the server's ID for the connection the description was read from
+	ServiceID             *primitive.ObjectID // the ID of the backend server behind a load balancer
 	SetName               string
 	SetVersion            uint32
 	Tags                  tag.Set
@@ -90,6 +92,14 @@ func NewServer(addr address.Address, response bsoncore.Document) Server {
 				desc.LastError = err
 				return desc
 			}
+		case "connectionId":
+			i64, ok := element.Value().AsInt64OK()
+			if !ok {
+				desc.LastError = fmt.Errorf("expected 'connectionId' to be an integer but it's a BSON %s", element.Value().Type)
+				return desc
+			}
+			serverConnID := int32(i64)
+			desc.ServerConnectionID = &serverConnID
 		case "electionId":
 			desc.ElectionID, ok = element.Value().ObjectIDOK()
 			if !ok {
@@ -225,6 +235,13 @@ func NewServer(addr address.Address, response bsoncore.Document) Server {
 				desc.LastError = fmt.Errorf("expected 'secondary' to be a boolean but it's a BSON %s", element.Value().Type)
 				return desc
 			}
+		case "serviceId":
+			oid, ok := element.Value().ObjectIDOK()
+			if !ok {
+				desc.LastError = fmt.Errorf("expected 'serviceId' to be an ObjectId but it's a BSON %s", element.Value().Type)
+				return desc
+			}
+			desc.ServiceID = &oid
 		case "setName":
 			desc.SetName, ok = element.Value().StringValueOK()
 			if !ok {
@@ -302,7 +319,8 @@ func (s Server) DataBearing() bool {
 	return s.Kind == RSPrimary ||
 		s.Kind == RSSecondary ||
 		s.Kind == Mongos ||
-		s.Kind == Standalone
+		s.Kind == Standalone ||
+		s.Kind == LoadBalancer
 }
 
 // SelectServer selects this server if it is in the list of given candidates.
@@ -16,6 +16,7 @@ const (
 	ReplicaSetNoPrimary   TopologyKind = 4 + ReplicaSet
 	ReplicaSetWithPrimary TopologyKind = 8 + ReplicaSet
 	Sharded               TopologyKind = 256
+	LoadBalanced          TopologyKind = 1024
 )
 
 // String implements the fmt.Stringer interface.
@@ -31,6 +32,8 @@ func (kind TopologyKind) String() string {
 		return "ReplicaSetWithPrimary"
 	case Sharded:
 		return "Sharded"
+	case LoadBalanced:
+		return "LoadBalanced"
 	}
 
 	return "Unknown"
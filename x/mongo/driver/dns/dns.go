@@ -7,6 +7,7 @@
 package dns
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -24,14 +25,45 @@ type Resolver struct {
 // DefaultResolver is a Resolver that uses the default Resolver from the net package.
 var DefaultResolver = &Resolver{net.LookupSRV, net.LookupTXT}
 
+// HostResolver is the interface implemented by types that can resolve host names and SRV and TXT records. It can be
+// implemented to use a resolution mechanism other than the system DNS resolver. *net.Resolver implements HostResolver.
+type HostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+var _ HostResolver = (*net.Resolver)(nil)
+
+// NewResolver creates a Resolver that performs SRV and TXT lookups using hr.
+func NewResolver(hr HostResolver) *Resolver {
+	return &Resolver{
+		LookupSRV: func(service, proto, name string) (string, []*net.SRV, error) {
+			return hr.LookupSRV(context.Background(), service, proto, name)
+		},
+		LookupTXT: func(name string) ([]string, error) {
+			return hr.LookupTXT(context.Background(), name)
+		},
+	}
+}
+
+// DefaultSRVServiceName is the service name used for SRV lookups if none is specified.
+const DefaultSRVServiceName = "mongodb"
+
 // ParseHosts uses the srv string to get the hosts.
 func (r *Resolver) ParseHosts(host string, stopOnErr bool) ([]string, error) {
+	return r.ParseHostsWithServiceName(host, DefaultSRVServiceName, stopOnErr)
+}
+
+// ParseHostsWithServiceName uses the srv string to get the hosts, looking up SRV records for the given service name
+// instead of the default "mongodb" service.
+func (r *Resolver) ParseHostsWithServiceName(host, srvName string, stopOnErr bool) ([]string, error) {
 	parsedHosts := strings.Split(host, ",")
 
 	if len(parsedHosts) != 1 {
 		return nil, fmt.Errorf("URI with SRV must include one and only one hostname")
 	}
-	return r.fetchSeedlistFromSRV(parsedHosts[0], stopOnErr)
+	return r.fetchSeedlistFromSRV(parsedHosts[0], srvName, stopOnErr)
 }
 
 // GetConnectionArgsFromTXT gets the TXT record associated with the host and returns the connection arguments.
@@ -64,7 +96,7 @@ func (r *Resolver) GetConnectionArgsFromTXT(host string) ([]string, error) {
 	return connectionArgsFromTXT, nil
 }
 
-func (r *Resolver) fetchSeedlistFromSRV(host string, stopOnErr bool) ([]string, error) {
+func (r *Resolver) fetchSeedlistFromSRV(host, srvName string, stopOnErr bool) ([]string, error) {
 	var err error
 
 	_, _, err = net.SplitHostPort(host)
@@ -75,7 +107,7 @@ func (r *Resolver) fetchSeedlistFromSRV(host string, stopOnErr bool) ([]string,
 		return nil, fmt.Errorf("URI with srv must not include a port number")
 	}
 
-	_, addresses, err := r.LookupSRV("mongodb", "tcp", host)
+	_, addresses, err := r.LookupSRV(srvName, "tcp", host)
 	if err != nil {
 		return nil, err
 	}
@@ -120,8 +152,9 @@ func validateSRVResult(recordFromSRV, inputHostName string) error {
 }
 
 var allowedTXTOptions = map[string]struct{}{
-	"authsource": {},
-	"replicaset": {},
+	"authsource":   {},
+	"loadbalanced": {},
+	"replicaset":   {},
 }
 
 func validateTXTResult(paramsFromTXT []string) error {
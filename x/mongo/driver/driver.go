@@ -2,7 +2,10 @@ package driver // import "go.mongodb.org/mongo-driver/x/mongo/driver"
 
 import (
 	"context"
+	"time"
 
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
 )
@@ -54,6 +57,18 @@ type Connection interface {
 	Address() address.Address
 }
 
+// PinnedConnection represents a Connection that can be pinned by one or more cursors or transactions. A pinned
+// connection is not returned to its pool when it is closed. Connections are pinned when the deployment is behind a
+// load balancer, because the commands for a cursor or transaction must be sent to the same backend server as the
+// command that started it.
+type PinnedConnection interface {
+	Connection
+	PinToCursor() error
+	PinToTransaction() error
+	UnpinFromCursor() error
+	UnpinFromTransaction() error
+}
+
 // LocalAddresser is a type that is able to supply its local address
 type LocalAddresser interface {
 	LocalAddress() address.Address
@@ -74,9 +89,38 @@ type Compressor interface {
 
 // ErrorProcessor implementations can handle processing errors, which may modify their internal state.
 // If this type is implemented by a Server, then Operation.Execute will call it's ProcessError
-// method after it decodes a wire message.
+// method after it decodes a wire message. The conn parameter is the connection the error occurred on.
 type ErrorProcessor interface {
-	ProcessError(error)
+	ProcessError(err error, conn Connection)
+}
+
+// TracerProvider is implemented by a Deployment that traces the operations run against it. If the Tracer method
+// returns a non-nil event.Tracer, Operation.Execute will start a span for the operation.
+type TracerProvider interface {
+	Tracer() event.Tracer
+}
+
+// SlowOperationReporter is implemented by a Deployment that reports slow operations. If SlowOperationThreshold
+// returns a positive duration and SlowOperationMonitor returns a non-nil monitor, Operation.Execute reports every
+// command that takes longer than the threshold to the monitor.
+type SlowOperationReporter interface {
+	SlowOperationThreshold() time.Duration
+	SlowOperationMonitor() *event.SlowOperationMonitor
+}
+
+// ProfilerLabeler is implemented by a Deployment that labels the goroutines running operations against it. If
+// OperationProfilerLabels returns true, Operation.Execute sets pprof labels with the name and namespace of the command
+// on the calling goroutine while the command runs, and restores the labels of the context passed to Execute when it
+// returns.
+type ProfilerLabeler interface {
+	OperationProfilerLabels() bool
+}
+
+// CommentProvider is implemented by a Deployment that derives a comment for each command from the context of the
+// operation. If CommandComment returns a value with a non-zero type, Operation.Execute adds it as the comment field of
+// every OP_MSG command that does not already have one, provided the server supports comments on all commands.
+type CommentProvider interface {
+	CommandComment(ctx context.Context) bsoncore.Value
 }
 
 // Handshaker is the interface implemented by types that can perform a MongoDB
@@ -141,6 +185,29 @@ type nopCloserConnection struct{ Connection }
 
 func (ncc nopCloserConnection) Close() error { return nil }
 
+// pinnedServer is a Server that always returns a connection that has been pinned to a cursor or transaction. The
+// connections returned from the Connection method have a no-op Close method. Errors are reported to the server the
+// connection was checked out from.
+type pinnedServer struct {
+	Server
+	conn PinnedConnection
+}
+
+var _ Server = pinnedServer{}
+var _ ErrorProcessor = pinnedServer{}
+
+// Connection implements the Server interface. It always returns the pinned connection.
+func (ps pinnedServer) Connection(context.Context) (Connection, error) {
+	return nopCloserConnection{ps.conn}, nil
+}
+
+// ProcessError implements the ErrorProcessor interface.
+func (ps pinnedServer) ProcessError(err error, conn Connection) {
+	if ep, ok := ps.Server.(ErrorProcessor); ok {
+		ep.ProcessError(err, conn)
+	}
+}
+
 // TODO(GODRIVER-617): We can likely use 1 type for both the Type and the RetryMode by using
 // 2 bits for the mode and 1 bit for the type. Although in the practical sense, we might not want to
 // do that since the type of retryability is tied to the operation itself and isn't going change,
@@ -89,6 +89,9 @@ func (lcbc *ListCollectionsBatchCursor) Next(ctx context.Context) bool {
 // DocumentSequence is only valid until the next call to Next or Close.
 func (lcbc *ListCollectionsBatchCursor) Batch() *bsoncore.DocumentSequence { return lcbc.currentBatch }
 
+// SetBatchSize sets the batchSize used for subsequent getMore commands.
+func (lcbc *ListCollectionsBatchCursor) SetBatchSize(size int32) { lcbc.bc.SetBatchSize(size) }
+
 // Server returns a pointer to the cursor's server.
 func (lcbc *ListCollectionsBatchCursor) Server() Server { return lcbc.bc.server }
 
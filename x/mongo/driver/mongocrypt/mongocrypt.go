@@ -26,6 +26,7 @@ import (
 const (
 	AwsProvider   = "aws"
 	LocalProvider = "local"
+	KmipProvider  = "kmip"
 )
 
 // ErrInvalidProvider is returned when an invalid KMS provider is given.
@@ -54,9 +55,16 @@ func NewMongoCrypt(opts *options.MongoCryptOptions) (*MongoCrypt, error) {
 	if err := crypt.setAwsProviderOpts(opts.AwsProviderOpts); err != nil {
 		return nil, err
 	}
+	if err := crypt.setKmsProviders(opts); err != nil {
+		return nil, err
+	}
+	if opts.UseNeedKmsCredentialsState {
+		C.mongocrypt_setopt_use_need_kms_credentials_state(crypt.wrapped)
+	}
 	if err := crypt.setLocalSchemaMap(opts.LocalSchemaMap); err != nil {
 		return nil, err
 	}
+	crypt.setCryptSharedLibOpts(opts)
 
 	// initialize handle
 	if !C.mongocrypt_init(crypt.wrapped) {
@@ -151,8 +159,22 @@ func (m *MongoCrypt) CreateDataKeyContext(kmsProvider string, opts *options.Data
 		ok = bool(C.mongocrypt_ctx_setopt_masterkey_aws_endpoint(ctx.wrapped, endpointCStr, -1))
 	case LocalProvider:
 		ok = bool(C.mongocrypt_ctx_setopt_masterkey_local(ctx.wrapped))
-	default:
+	case "":
 		return nil, ErrInvalidProvider
+	default:
+		// other providers, including named providers such as "aws:primary", take the master key document with the
+		// provider name added to it
+		idx, kek := bsoncore.AppendDocumentStart(nil)
+		kek = bsoncore.AppendStringElement(kek, "provider", kmsProvider)
+		elems, _ := opts.MasterKey.Elements()
+		for _, elem := range elems {
+			kek = append(kek, elem...)
+		}
+		kek, _ = bsoncore.AppendDocumentEnd(kek, idx)
+
+		kekBinary := newBinaryFromBytes(kek)
+		defer kekBinary.close()
+		ok = bool(C.mongocrypt_ctx_setopt_key_encryption_key(ctx.wrapped, kekBinary.wrapped))
 	}
 	if !ok {
 		return nil, ctx.createErrorFromStatus()
@@ -172,7 +194,39 @@ func (m *MongoCrypt) CreateDataKeyContext(kmsProvider string, opts *options.Data
 
 // CreateExplicitEncryptionContext creates a Context to use for explicit encryption.
 func (m *MongoCrypt) CreateExplicitEncryptionContext(doc bsoncore.Document, opts *options.ExplicitEncryptionOptions) (*Context, error) {
+	ctx, err := m.newExplicitEncryptionContext(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	docBinary := newBinaryFromBytes(doc)
+	defer docBinary.close()
+	if ok := C.mongocrypt_ctx_explicit_encrypt_init(ctx.wrapped, docBinary.wrapped); !ok {
+		return nil, ctx.createErrorFromStatus()
+	}
+
+	return ctx, nil
+}
+
+// CreateExplicitEncryptionExpressionContext creates a Context to use for explicitly encrypting the bounds of a match
+// or aggregate expression with the range algorithm.
+func (m *MongoCrypt) CreateExplicitEncryptionExpressionContext(doc bsoncore.Document, opts *options.ExplicitEncryptionOptions) (*Context, error) {
+	ctx, err := m.newExplicitEncryptionContext(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	docBinary := newBinaryFromBytes(doc)
+	defer docBinary.close()
+	if ok := C.mongocrypt_ctx_explicit_encrypt_expression_init(ctx.wrapped, docBinary.wrapped); !ok {
+		return nil, ctx.createErrorFromStatus()
+	}
 
+	return ctx, nil
+}
+
+// newExplicitEncryptionContext creates a Context configured with the given explicit encryption options.
+func (m *MongoCrypt) newExplicitEncryptionContext(opts *options.ExplicitEncryptionOptions) (*Context, error) {
 	ctx := newContext(C.mongocrypt_ctx_new(m.wrapped))
 	if ctx.wrapped == nil {
 		return nil, m.createErrorFromStatus()
@@ -198,10 +252,24 @@ func (m *MongoCrypt) CreateExplicitEncryptionContext(doc bsoncore.Document, opts
 		return nil, ctx.createErrorFromStatus()
 	}
 
-	docBinary := newBinaryFromBytes(doc)
-	defer docBinary.close()
-	if ok := C.mongocrypt_ctx_explicit_encrypt_init(ctx.wrapped, docBinary.wrapped); !ok {
-		return nil, ctx.createErrorFromStatus()
+	if opts.QueryType != "" {
+		queryStr := C.CString(opts.QueryType)
+		defer C.free(unsafe.Pointer(queryStr))
+		if ok := C.mongocrypt_ctx_setopt_query_type(ctx.wrapped, queryStr, -1); !ok {
+			return nil, ctx.createErrorFromStatus()
+		}
+	}
+	if opts.ContentionFactor != nil {
+		if ok := C.mongocrypt_ctx_setopt_contention_factor(ctx.wrapped, C.int64_t(*opts.ContentionFactor)); !ok {
+			return nil, ctx.createErrorFromStatus()
+		}
+	}
+	if opts.RangeOptions != nil {
+		rangeBinary := newBinaryFromBytes(opts.RangeOptions.Document())
+		defer rangeBinary.close()
+		if ok := C.mongocrypt_ctx_setopt_algorithm_range(ctx.wrapped, rangeBinary.wrapped); !ok {
+			return nil, ctx.createErrorFromStatus()
+		}
 	}
 
 	return ctx, nil
@@ -223,6 +291,58 @@ func (m *MongoCrypt) CreateExplicitDecryptionContext(doc bsoncore.Document) (*Co
 	return ctx, nil
 }
 
+// CreateRewrapManyDataKeyContext creates a Context to use for re-encrypting the data keys that match the given filter.
+// If no provider is given, the data keys are re-encrypted with their current master key.
+func (m *MongoCrypt) CreateRewrapManyDataKeyContext(filter bsoncore.Document, opts *options.RewrapManyDataKeyOptions) (*Context, error) {
+	ctx := newContext(C.mongocrypt_ctx_new(m.wrapped))
+	if ctx.wrapped == nil {
+		return nil, m.createErrorFromStatus()
+	}
+
+	if opts.Provider != nil {
+		// the key encryption key document is the master key with the provider name added to it
+		idx, kek := bsoncore.AppendDocumentStart(nil)
+		kek = bsoncore.AppendStringElement(kek, "provider", *opts.Provider)
+		elems, _ := opts.MasterKey.Elements()
+		for _, elem := range elems {
+			kek = append(kek, elem...)
+		}
+		kek, _ = bsoncore.AppendDocumentEnd(kek, idx)
+
+		kekBinary := newBinaryFromBytes(kek)
+		defer kekBinary.close()
+		if ok := C.mongocrypt_ctx_setopt_key_encryption_key(ctx.wrapped, kekBinary.wrapped); !ok {
+			return nil, ctx.createErrorFromStatus()
+		}
+	}
+
+	filterBinary := newBinaryFromBytes(filter)
+	defer filterBinary.close()
+
+	if ok := C.mongocrypt_ctx_rewrap_many_datakey_init(ctx.wrapped, filterBinary.wrapped); !ok {
+		return nil, ctx.createErrorFromStatus()
+	}
+	return ctx, nil
+}
+
+// CryptSharedLibVersion returns the version of the loaded crypt_shared library, encoded as four 16-bit numbers from
+// high to low: major, minor, patch, and a non-zero value for a non-release build. It returns 0 if the library was not
+// loaded.
+func (m *MongoCrypt) CryptSharedLibVersion() uint64 {
+	return uint64(C.mongocrypt_crypt_shared_lib_version(m.wrapped))
+}
+
+// CryptSharedLibVersionString returns the version string of the loaded crypt_shared library, or the empty string if
+// the library was not loaded.
+func (m *MongoCrypt) CryptSharedLibVersionString() string {
+	var length C.uint32_t
+	str := C.mongocrypt_crypt_shared_lib_version_string(m.wrapped, &length)
+	if str == nil {
+		return ""
+	}
+	return C.GoStringN(str, C.int(length))
+}
+
 // Close cleans up any resources associated with the given MongoCrypt instance.
 func (m *MongoCrypt) Close() {
 	C.mongocrypt_destroy(m.wrapped)
@@ -261,6 +381,36 @@ func (m *MongoCrypt) setAwsProviderOpts(opts *options.AwsKmsProviderOptions) err
 	return nil
 }
 
+// setKmsProviders sets the options for the KMIP KMS provider and the KMS providers that do not have a dedicated type
+// in mongocrypt.
+func (m *MongoCrypt) setKmsProviders(opts *options.MongoCryptOptions) error {
+	elems, _ := opts.KmsProviders.Elements()
+	if opts.KmipProviderOpts != nil {
+		elems = append(elems, bsoncore.BuildDocumentElement(nil, KmipProvider,
+			bsoncore.AppendStringElement(nil, "endpoint", opts.KmipProviderOpts.Endpoint)))
+	}
+	if len(elems) == 0 {
+		return nil
+	}
+
+	providers := bsoncore.BuildDocumentFromElements(nil, elemsToBytes(elems)...)
+	providersBinary := newBinaryFromBytes(providers)
+	defer providersBinary.close()
+
+	if ok := C.mongocrypt_setopt_kms_providers(m.wrapped, providersBinary.wrapped); !ok {
+		return m.createErrorFromStatus()
+	}
+	return nil
+}
+
+func elemsToBytes(elems []bsoncore.Element) [][]byte {
+	b := make([][]byte, 0, len(elems))
+	for _, elem := range elems {
+		b = append(b, elem)
+	}
+	return b
+}
+
 // setLocalSchemaMap sets the local schema map in mongocrypt.
 func (m *MongoCrypt) setLocalSchemaMap(schemaMap map[string]bsoncore.Document) error {
 	if len(schemaMap) == 0 {
@@ -283,6 +433,20 @@ func (m *MongoCrypt) setLocalSchemaMap(schemaMap map[string]bsoncore.Document) e
 	return nil
 }
 
+// setCryptSharedLibOpts sets the paths used to load the crypt_shared library in mongocrypt.
+func (m *MongoCrypt) setCryptSharedLibOpts(opts *options.MongoCryptOptions) {
+	for _, path := range opts.CryptSharedLibSearchPaths {
+		cPath := C.CString(path)
+		C.mongocrypt_setopt_append_crypt_shared_lib_search_path(m.wrapped, cPath)
+		C.free(unsafe.Pointer(cPath))
+	}
+	if opts.CryptSharedLibOverridePath != "" {
+		cPath := C.CString(opts.CryptSharedLibOverridePath)
+		C.mongocrypt_setopt_set_crypt_shared_lib_path_override(m.wrapped, cPath)
+		C.free(unsafe.Pointer(cPath))
+	}
+}
+
 // createErrorFromStatus creates a new Error based on the status of the MongoCrypt instance.
 func (m *MongoCrypt) createErrorFromStatus() error {
 	status := C.mongocrypt_status_new()
@@ -70,6 +70,18 @@ func (c *Context) NextKmsContext() *KmsContext {
 	return newKmsContext(ctx)
 }
 
+// ProvideKmsProviders provides the credentials of the KMS providers that were requested in the NeedKmsCredentials
+// state.
+func (c *Context) ProvideKmsProviders(kmsProviders bsoncore.Document) error {
+	providersBinary := newBinaryFromBytes(kmsProviders)
+	defer providersBinary.close()
+
+	if ok := C.mongocrypt_ctx_provide_kms_providers(c.wrapped, providersBinary.wrapped); !ok {
+		return c.createErrorFromStatus()
+	}
+	return nil
+}
+
 // FinishKmsContexts signals that all KMS contexts have been completed.
 func (c *Context) FinishKmsContexts() error {
 	if ok := C.mongocrypt_ctx_kms_done(c.wrapped); !ok {
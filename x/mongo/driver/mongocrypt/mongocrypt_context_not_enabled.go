@@ -40,6 +40,12 @@ func (c *Context) NextKmsContext() *KmsContext {
 	panic(cseNotSupportedMsg)
 }
 
+// ProvideKmsProviders provides the credentials of the KMS providers that were requested in the NeedKmsCredentials
+// state.
+func (c *Context) ProvideKmsProviders(kmsProviders bsoncore.Document) error {
+	panic(cseNotSupportedMsg)
+}
+
 // FinishKmsContexts signals that all KMS contexts have been completed.
 func (c *Context) FinishKmsContexts() error {
 	panic(cseNotSupportedMsg)
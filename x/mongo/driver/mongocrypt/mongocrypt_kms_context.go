@@ -32,6 +32,11 @@ func (kc *KmsContext) HostName() (string, error) {
 	return C.GoString(hostname), nil
 }
 
+// KMSProvider gets the name of the KMS provider that the KMS belongs to, e.g. "aws" or "kmip".
+func (kc *KmsContext) KMSProvider() string {
+	return C.GoString(C.mongocrypt_kms_ctx_get_kms_provider(kc.wrapped, nil))
+}
+
 // Message returns the message to send to the KMS.
 func (kc *KmsContext) Message() ([]byte, error) {
 	msgBinary := newBinary()
@@ -16,6 +16,11 @@ func (kc *KmsContext) HostName() (string, error) {
 	panic(cseNotSupportedMsg)
 }
 
+// KMSProvider gets the name of the KMS provider that the KMS belongs to, e.g. "aws" or "kmip".
+func (kc *KmsContext) KMSProvider() string {
+	panic(cseNotSupportedMsg)
+}
+
 // Message returns the message to send to the KMS.
 func (kc *KmsContext) Message() ([]byte, error) {
 	panic(cseNotSupportedMsg)
@@ -43,11 +43,36 @@ func (m *MongoCrypt) CreateExplicitEncryptionContext(doc bsoncore.Document, opts
 	panic(cseNotSupportedMsg)
 }
 
+// CreateExplicitEncryptionExpressionContext creates a Context to use for explicitly encrypting the bounds of a match
+// or aggregate expression with the range algorithm.
+func (m *MongoCrypt) CreateExplicitEncryptionExpressionContext(doc bsoncore.Document, opts *options.ExplicitEncryptionOptions) (*Context, error) {
+	panic(cseNotSupportedMsg)
+}
+
 // CreateExplicitDecryptionContext creates a Context to use for explicit decryption.
 func (m *MongoCrypt) CreateExplicitDecryptionContext(doc bsoncore.Document) (*Context, error) {
 	panic(cseNotSupportedMsg)
 }
 
+// CreateRewrapManyDataKeyContext creates a Context to use for re-encrypting the data keys that match the given filter.
+// If no provider is given, the data keys are re-encrypted with their current master key.
+func (m *MongoCrypt) CreateRewrapManyDataKeyContext(filter bsoncore.Document, opts *options.RewrapManyDataKeyOptions) (*Context, error) {
+	panic(cseNotSupportedMsg)
+}
+
+// CryptSharedLibVersion returns the version of the loaded crypt_shared library, encoded as four 16-bit numbers from
+// high to low: major, minor, patch, and a non-zero value for a non-release build. It returns 0 if the library was not
+// loaded.
+func (m *MongoCrypt) CryptSharedLibVersion() uint64 {
+	panic(cseNotSupportedMsg)
+}
+
+// CryptSharedLibVersionString returns the version string of the loaded crypt_shared library, or the empty string if
+// the library was not loaded.
+func (m *MongoCrypt) CryptSharedLibVersionString() string {
+	panic(cseNotSupportedMsg)
+}
+
 // Close cleans up any resources associated with the given MongoCrypt instance.
 func (m *MongoCrypt) Close() {
 	panic(cseNotSupportedMsg)
@@ -36,9 +36,21 @@ func (dko *DataKeyOptions) SetMasterKey(key bsoncore.Document) *DataKeyOptions {
 
 // ExplicitEncryptionOptions specifies options for configuring an explicit encryption context.
 type ExplicitEncryptionOptions struct {
-	KeyID      *primitive.Binary
-	KeyAltName *string
-	Algorithm  string
+	KeyID            *primitive.Binary
+	KeyAltName       *string
+	Algorithm        string
+	QueryType        string
+	ContentionFactor *int64
+	RangeOptions     *ExplicitRangeOptions
+}
+
+// ExplicitRangeOptions specifies options for the range algorithm of an explicit encryption context.
+type ExplicitRangeOptions struct {
+	Min        *bsoncore.Value
+	Max        *bsoncore.Value
+	Sparsity   *int64
+	Precision  *int32
+	TrimFactor *int32
 }
 
 // ExplicitEncryption creates a new ExplicitEncryptionOptions instance.
@@ -63,3 +75,67 @@ func (eeo *ExplicitEncryptionOptions) SetAlgorithm(algorithm string) *ExplicitEn
 	eeo.Algorithm = algorithm
 	return eeo
 }
+
+// SetQueryType specifies the query type.
+func (eeo *ExplicitEncryptionOptions) SetQueryType(queryType string) *ExplicitEncryptionOptions {
+	eeo.QueryType = queryType
+	return eeo
+}
+
+// SetContentionFactor specifies the contention factor.
+func (eeo *ExplicitEncryptionOptions) SetContentionFactor(contentionFactor int64) *ExplicitEncryptionOptions {
+	eeo.ContentionFactor = &contentionFactor
+	return eeo
+}
+
+// SetRangeOptions specifies the options of the range algorithm.
+func (eeo *ExplicitEncryptionOptions) SetRangeOptions(ro ExplicitRangeOptions) *ExplicitEncryptionOptions {
+	eeo.RangeOptions = &ro
+	return eeo
+}
+
+// Document returns the range options as a document of the form {min, max, sparsity, precision, trimFactor}. Options
+// that are not set are omitted.
+func (ro *ExplicitRangeOptions) Document() bsoncore.Document {
+	idx, doc := bsoncore.AppendDocumentStart(nil)
+	if ro.Min != nil {
+		doc = bsoncore.AppendValueElement(doc, "min", *ro.Min)
+	}
+	if ro.Max != nil {
+		doc = bsoncore.AppendValueElement(doc, "max", *ro.Max)
+	}
+	if ro.Sparsity != nil {
+		doc = bsoncore.AppendInt64Element(doc, "sparsity", *ro.Sparsity)
+	}
+	if ro.Precision != nil {
+		doc = bsoncore.AppendInt32Element(doc, "precision", *ro.Precision)
+	}
+	if ro.TrimFactor != nil {
+		doc = bsoncore.AppendInt32Element(doc, "trimFactor", *ro.TrimFactor)
+	}
+	doc, _ = bsoncore.AppendDocumentEnd(doc, idx)
+	return doc
+}
+
+// RewrapManyDataKeyOptions specifies options for re-encrypting data keys.
+type RewrapManyDataKeyOptions struct {
+	Provider  *string
+	MasterKey bsoncore.Document
+}
+
+// RewrapManyDataKey creates a new RewrapManyDataKeyOptions instance.
+func RewrapManyDataKey() *RewrapManyDataKeyOptions {
+	return &RewrapManyDataKeyOptions{}
+}
+
+// SetProvider sets the KMS provider of the new master key.
+func (rmdko *RewrapManyDataKeyOptions) SetProvider(provider string) *RewrapManyDataKeyOptions {
+	rmdko.Provider = &provider
+	return rmdko
+}
+
+// SetMasterKey sets the new master key.
+func (rmdko *RewrapManyDataKeyOptions) SetMasterKey(key bsoncore.Document) *RewrapManyDataKeyOptions {
+	rmdko.MasterKey = key
+	return rmdko
+}
@@ -14,7 +14,20 @@ import (
 type MongoCryptOptions struct {
 	AwsProviderOpts   *AwsKmsProviderOptions
 	LocalProviderOpts *LocalKmsProviderOptions
+	KmipProviderOpts  *KmipKmsProviderOptions
 	LocalSchemaMap    map[string]bsoncore.Document
+
+	// KmsProviders is a document with the options of KMS providers that do not have a dedicated type, such as
+	// "azure", "gcp", and named providers like "aws:primary". It is combined with the provider-specific options.
+	KmsProviders bsoncore.Document
+	// UseNeedKmsCredentialsState enables the NeedKmsCredentials state, which is entered when a KMS provider that was
+	// configured with an empty document needs credentials.
+	UseNeedKmsCredentialsState bool
+	// CryptSharedLibSearchPaths are the directories that are searched for the crypt_shared library. The special value
+	// "$SYSTEM" searches the default library paths of the system.
+	CryptSharedLibSearchPaths []string
+	// CryptSharedLibOverridePath is the path of the crypt_shared library. If it is set, the search paths are not used.
+	CryptSharedLibOverridePath string
 }
 
 // MongoCrypt creates a new MongoCryptOptions instance.
@@ -34,6 +47,36 @@ func (mo *MongoCryptOptions) SetLocalProviderOptions(localOpts *LocalKmsProvider
 	return mo
 }
 
+// SetKmipProviderOptions specifies KMIP KMS provider options.
+func (mo *MongoCryptOptions) SetKmipProviderOptions(kmipOpts *KmipKmsProviderOptions) *MongoCryptOptions {
+	mo.KmipProviderOpts = kmipOpts
+	return mo
+}
+
+// SetKmsProviders specifies the options of KMS providers that do not have a dedicated type.
+func (mo *MongoCryptOptions) SetKmsProviders(kmsProviders bsoncore.Document) *MongoCryptOptions {
+	mo.KmsProviders = kmsProviders
+	return mo
+}
+
+// SetUseNeedKmsCredentialsState specifies whether KMS provider credentials can be requested on demand.
+func (mo *MongoCryptOptions) SetUseNeedKmsCredentialsState(b bool) *MongoCryptOptions {
+	mo.UseNeedKmsCredentialsState = b
+	return mo
+}
+
+// SetCryptSharedLibSearchPaths specifies the directories that are searched for the crypt_shared library.
+func (mo *MongoCryptOptions) SetCryptSharedLibSearchPaths(paths []string) *MongoCryptOptions {
+	mo.CryptSharedLibSearchPaths = paths
+	return mo
+}
+
+// SetCryptSharedLibOverridePath specifies the path of the crypt_shared library.
+func (mo *MongoCryptOptions) SetCryptSharedLibOverridePath(path string) *MongoCryptOptions {
+	mo.CryptSharedLibOverridePath = path
+	return mo
+}
+
 // SetLocalSchemaMap specifies the local schema map.
 func (mo *MongoCryptOptions) SetLocalSchemaMap(localSchemaMap map[string]bsoncore.Document) *MongoCryptOptions {
 	mo.LocalSchemaMap = localSchemaMap
@@ -44,3 +44,19 @@ func (lkpo *LocalKmsProviderOptions) SetMasterKey(key []byte) *LocalKmsProviderO
 	lkpo.MasterKey = key
 	return lkpo
 }
+
+// KmipKmsProviderOptions specifies options for configuring a KMIP KMS provider.
+type KmipKmsProviderOptions struct {
+	Endpoint string
+}
+
+// KmipKmsProvider creates a new KmipKmsProviderOptions instance.
+func KmipKmsProvider() *KmipKmsProviderOptions {
+	return &KmipKmsProviderOptions{}
+}
+
+// SetEndpoint specifies the host and optional port of the KMIP server.
+func (kkpo *KmipKmsProviderOptions) SetEndpoint(endpoint string) *KmipKmsProviderOptions {
+	kkpo.Endpoint = endpoint
+	return kkpo
+}
@@ -5,7 +5,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime/pprof"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -34,6 +36,9 @@ var (
 	ErrReplyDocumentMismatch = errors.New("number of documents returned does not match numberReturned field")
 	// ErrNonPrimaryReadPref is returned when a read is attempted in a transaction with a non-primary read preference.
 	ErrNonPrimaryReadPref = errors.New("read preference in a transaction must be primary")
+	// ErrSnapshotUnsupported is returned when a read is attempted in a snapshot session on a server that does not
+	// support snapshot reads.
+	ErrSnapshotUnsupported = errors.New("snapshot reads require MongoDB 5.0 or later")
 )
 
 const (
@@ -41,6 +46,10 @@ const (
 	cryptMaxBsonObjectSize uint32 = 2097152
 	// minimum wire version necessary to use automatic encryption
 	cryptMinWireVersion int32 = 8
+	// minimum wire version necessary to add a comment to any command
+	genericCommentWireVersion int32 = 9
+	// minimum wire version necessary to read with snapshot read concern outside of a transaction
+	readSnapshotMinWireVersion int32 = 13
 )
 
 // InvalidOperationError is returned from Validate and indicates that a required field is missing
@@ -69,16 +78,25 @@ type startedInformation struct {
 	cmdName                  string
 	documentSequenceIncluded bool
 	connID                   string
+	serverConnID             *int32
+	serviceID                *primitive.ObjectID
+	compressor               string
+	retry                    bool
+	wm                       []byte
+	unmonitored              bool
 }
 
 // finishedInformation keeps track of all of the information necessary for monitoring success and failure events.
 type finishedInformation struct {
-	cmdName   string
-	requestID int32
-	response  bsoncore.Document
-	cmdErr    error
-	connID    string
-	startTime time.Time
+	cmdName     string
+	requestID   int32
+	response    bsoncore.Document
+	cmdErr      error
+	connID      string
+	startTime   time.Time
+	reply       *replyRecorder
+	started     startedInformation
+	unmonitored bool
 }
 
 // Operation is used to execute an operation. It contains all of the common code required to
@@ -179,6 +197,12 @@ type Operation struct {
 
 	// Crypt specifies a Crypt object to use for automatic client side encryption and decryption.
 	Crypt *Crypt
+
+	// ExhaustAllowed specifies whether the exhaustAllowed flag should be set on the OP_MSG sent for this operation,
+	// allowing the server to stream multiple replies with the moreToCome flag set. Execute does not read streamed
+	// replies, so this should only be set by callers that read them directly from the connection, such as
+	// BatchCursor.
+	ExhaustAllowed bool
 }
 
 // shouldEncrypt returns true if this operation should automatically be encrypted.
@@ -213,6 +237,48 @@ func (op Operation) selectServer(ctx context.Context) (Server, error) {
 	return op.Deployment.SelectServer(ctx, selector)
 }
 
+// getServerAndConnection selects a server and checks out a connection from it. If the operation is part of a
+// transaction that is pinned to a connection, the pinned connection is used instead. When the deployment is behind a
+// load balancer, the connection used by the first operation in a transaction is pinned to the transaction.
+func (op Operation) getServerAndConnection(ctx context.Context) (Server, Connection, error) {
+	srvr, err := op.selectServer(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if op.Client != nil && op.Client.PinnedConnection != nil {
+		srvr = pinnedServer{Server: srvr, conn: op.Client.PinnedConnection}
+	}
+
+	conn, err := srvr.Connection(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if op.Deployment.Kind() == description.LoadBalanced && op.Client != nil && op.Client.TransactionStarting() &&
+		op.Client.PinnedConnection == nil {
+		if pc, ok := conn.(PinnedConnection); ok {
+			if err := pc.PinToTransaction(); err != nil {
+				_ = conn.Close()
+				return nil, nil, err
+			}
+			op.Client.PinnedConnection = pc
+		}
+	}
+	return srvr, conn, nil
+}
+
+// responseServer returns the Server passed to ProcessResponseFn. When the deployment is behind a load balancer, the
+// returned Server is bound to conn so that a cursor created from the response can pin it (see NewCursorResponse).
+func (op Operation) responseServer(srvr Server, conn Connection) Server {
+	if _, ok := srvr.(pinnedServer); ok || op.Deployment.Kind() != description.LoadBalanced {
+		return srvr
+	}
+	if pc, ok := conn.(PinnedConnection); ok {
+		return pinnedServer{Server: srvr, conn: pc}
+	}
+	return srvr
+}
+
 // Validate validates this operation, ensuring the fields are set properly.
 func (op Operation) Validate() error {
 	if op.CommandFn == nil {
@@ -238,12 +304,18 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 		return err
 	}
 
-	srvr, err := op.selectServer(ctx)
-	if err != nil {
-		return err
+	if op.operationProfilerLabels() {
+		defer pprof.SetGoroutineLabels(ctx)
 	}
 
-	conn, err := srvr.Connection(ctx)
+	ctx, trace := op.startTrace(ctx)
+	err = op.execute(ctx, scratch, trace)
+	trace.end(err)
+	return err
+}
+
+func (op Operation) execute(ctx context.Context, scratch []byte, trace *operationTrace) error {
+	srvr, conn, err := op.getServerAndConnection(ctx)
 	if err != nil {
 		return err
 	}
@@ -307,6 +379,7 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 		}
 	}
 	batching := op.Batches.Valid()
+	var retrying bool
 	for {
 		if batching {
 			targetBatchSize := desc.MaxDocumentSize
@@ -335,15 +408,11 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 			return err
 		}
 
-		// set extra data and send event if possible
-		startedInfo.connID = conn.ID()
-		startedInfo.cmdName = op.getCommandName(startedInfo.cmd)
-		op.publishStartedEvent(ctx, startedInfo)
-
 		// get the moreToCome flag information before we compress
 		moreToCome := wiremessage.IsMsgMoreToCome(wm)
 
 		// compress wiremessage if allowed
+		startedInfo.cmdName = op.getCommandName(startedInfo.cmd)
 		if compressor, ok := conn.(Compressor); ok && op.canCompress(startedInfo.cmdName) {
 			wm, err = compressor.CompressWireMessage(wm, nil)
 			if err != nil {
@@ -351,11 +420,30 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 			}
 		}
 
+		// set extra data and send event if possible
+		connDesc := conn.Description()
+		startedInfo.connID = conn.ID()
+		startedInfo.serverConnID = connDesc.ServerConnectionID
+		startedInfo.serviceID = connDesc.ServiceID
+		startedInfo.compressor = compressorName(wm)
+		startedInfo.retry = retrying
+		startedInfo.unmonitored = !op.commandMonitored(startedInfo.cmdName, startedInfo.cmd)
+		if !startedInfo.unmonitored && op.CommandMonitor.IncludeWireMessages {
+			startedInfo.wm = append([]byte(nil), wm...)
+		}
+		if op.operationProfilerLabels() {
+			ctx = op.labelGoroutine(ctx, startedInfo.cmdName, startedInfo.cmd)
+		}
+		op.publishStartedEvent(ctx, startedInfo)
+		trace.started(startedInfo.cmdName, startedInfo.cmd, conn.Address())
+
 		finishedInfo := finishedInformation{
-			cmdName:   startedInfo.cmdName,
-			requestID: startedInfo.requestID,
-			startTime: time.Now(),
-			connID:    startedInfo.connID,
+			cmdName:     startedInfo.cmdName,
+			requestID:   startedInfo.requestID,
+			startTime:   time.Now(),
+			connID:      startedInfo.connID,
+			started:     startedInfo,
+			unmonitored: startedInfo.unmonitored,
 		}
 
 		// roundtrip using either the full roundTripper or a special one for when the moreToCome
@@ -364,20 +452,27 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 		if moreToCome {
 			roundTrip = op.moreToComeRoundTrip
 		}
-		res, err = roundTrip(ctx, conn, wm)
+		roundTripConn := conn
+		if !startedInfo.unmonitored {
+			finishedInfo.reply = &replyRecorder{Connection: conn, keep: op.CommandMonitor.IncludeWireMessages}
+			roundTripConn = finishedInfo.reply
+		}
+		res, err = roundTrip(ctx, roundTripConn, wm)
 		if ep, ok := srvr.(ErrorProcessor); ok {
-			ep.ProcessError(err)
+			ep.ProcessError(err, conn)
 		}
 
 		finishedInfo.response = res
 		finishedInfo.cmdErr = err
 		op.publishFinishedEvent(ctx, finishedInfo)
+		op.reportSlowOperation(startedInfo, time.Since(finishedInfo.startTime), conn.Address())
 
 		// Pull out $clusterTime and operationTime and update session and clock. We handle this before
 		// handling the error to ensure we are properly gossiping the cluster time.
 		op.updateClusterTimes(res)
 		op.updateOperationTime(res)
 		op.Client.UpdateRecoveryToken(bson.Raw(res))
+		op.Client.UpdateSnapshotTime(res)
 
 		// automatically attempt to decrypt all results if client side encryption enabled
 		if op.Crypt != nil {
@@ -390,7 +485,7 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 		}
 		var perr error
 		if op.ProcessResponseFn != nil {
-			perr = op.ProcessResponseFn(res, srvr, desc.Server)
+			perr = op.ProcessResponseFn(res, op.responseServer(srvr, conn), desc.Server)
 		}
 		switch tt := err.(type) {
 		case WriteCommandError:
@@ -407,13 +502,11 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 
 			if retryable && retryableErr && retries != 0 {
 				retries--
+				retrying = true
+				trace.retried()
 				original, err = err, nil
 				conn.Close() // Avoid leaking the connection.
-				srvr, err = op.selectServer(ctx)
-				if err != nil {
-					return original
-				}
-				conn, err = srvr.Connection(ctx)
+				srvr, conn, err = op.getServerAndConnection(ctx)
 				if err != nil || conn == nil || !op.retryable(conn.Description()) {
 					if conn != nil {
 						conn.Close()
@@ -474,13 +567,11 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 
 			if retryable && retryableErr && retries != 0 {
 				retries--
+				retrying = true
+				trace.retried()
 				original, err = err, nil
 				conn.Close() // Avoid leaking the connection.
-				srvr, err = op.selectServer(ctx)
-				if err != nil {
-					return original
-				}
-				conn, err = srvr.Connection(ctx)
+				srvr, conn, err = op.getServerAndConnection(ctx)
 				if err != nil || conn == nil || !op.retryable(conn.Description()) {
 					if conn != nil {
 						conn.Close()
@@ -522,6 +613,7 @@ func (op Operation) Execute(ctx context.Context, scratch []byte) error {
 				}
 			}
 			op.Batches.ClearBatch()
+			retrying = false
 			continue
 		}
 		break
@@ -620,6 +712,49 @@ func (op *Operation) moreToComeRoundTrip(ctx context.Context, conn Connection, w
 	return bsoncore.BuildDocument(nil, bsoncore.AppendInt32Element(nil, "ok", 1)), err
 }
 
+// replyRecorder is a Connection that records the size and compressor of the wire messages it reads, and optionally
+// a copy of the last one, so they can be included in command monitoring events.
+type replyRecorder struct {
+	Connection
+	keep       bool
+	size       int
+	compressor string
+	wm         []byte
+}
+
+func (r *replyRecorder) ReadWireMessage(ctx context.Context, dst []byte) ([]byte, error) {
+	wm, err := r.Connection.ReadWireMessage(ctx, dst)
+	if err != nil {
+		return wm, err
+	}
+	r.size = len(wm)
+	r.compressor = compressorName(wm)
+	if r.keep {
+		r.wm = append([]byte(nil), wm...)
+	}
+	return wm, nil
+}
+
+// compressorName returns the name of the compressor used for wm, or an empty string if wm is not an OP_COMPRESSED
+// message.
+func compressorName(wm []byte) string {
+	_, _, _, opcode, rem, ok := wiremessage.ReadHeader(wm)
+	if !ok || opcode != wiremessage.OpCompressed {
+		return ""
+	}
+	if _, rem, ok = wiremessage.ReadCompressedOriginalOpCode(rem); !ok {
+		return ""
+	}
+	if _, rem, ok = wiremessage.ReadCompressedUncompressedSize(rem); !ok {
+		return ""
+	}
+	id, _, ok := wiremessage.ReadCompressedCompressorID(rem)
+	if !ok {
+		return ""
+	}
+	return id.String()
+}
+
 // decompressWireMessage handles decompressing a wiremessage. If the wiremessage
 // is not compressed, this method will return the wiremessage.
 func (Operation) decompressWireMessage(wm []byte) ([]byte, error) {
@@ -759,6 +894,9 @@ func (op Operation) createMsgWireMessage(ctx context.Context, dst []byte, desc d
 	if op.WriteConcern != nil && !writeconcern.AckWrite(op.WriteConcern) && (op.Batches == nil || len(op.Batches.Documents) == 0) {
 		flags = wiremessage.MoreToCome
 	}
+	if op.ExhaustAllowed {
+		flags |= wiremessage.ExhaustAllowed
+	}
 	info.requestID = wiremessage.NextRequestID()
 	wmindex, dst = wiremessage.AppendHeaderStart(dst, info.requestID, 0, wiremessage.OpMsg)
 	dst = wiremessage.AppendMsgFlags(dst, flags)
@@ -771,6 +909,7 @@ func (op Operation) createMsgWireMessage(ctx context.Context, dst []byte, desc d
 	if err != nil {
 		return dst, info, err
 	}
+	dst = op.addContextComment(ctx, dst, idx, desc)
 	dst, err = op.addReadConcern(dst, desc)
 	if err != nil {
 		return dst, info, err
@@ -854,6 +993,31 @@ func (op Operation) addCommandFields(ctx context.Context, dst []byte, desc descr
 	return dst, nil
 }
 
+// addContextComment adds the comment derived from ctx by the deployment to the command started at idx in dst. The
+// comment is not added if the server does not support comments on all commands or if the command already has one.
+func (op Operation) addContextComment(ctx context.Context, dst []byte, idx int32, desc description.SelectedServer) []byte {
+	provider, ok := op.Deployment.(CommentProvider)
+	if !ok || desc.WireVersion == nil || desc.WireVersion.Max < genericCommentWireVersion {
+		return dst
+	}
+	comment := provider.CommandComment(ctx)
+	if comment.Type == 0 {
+		return dst
+	}
+	// The command document has not been terminated yet, so its elements are read directly after the length.
+	for rem := dst[idx+4:]; len(rem) > 0; {
+		elem, next, ok := bsoncore.ReadElement(rem)
+		if !ok {
+			break
+		}
+		if elem.Key() == "comment" {
+			return dst
+		}
+		rem = next
+	}
+	return bsoncore.AppendValueElement(dst, "comment", comment)
+}
+
 func (op Operation) addReadConcern(dst []byte, desc description.SelectedServer) ([]byte, error) {
 	if op.MinimumReadConcernWireVersion > 0 && (desc.WireVersion == nil || !desc.WireVersion.Includes(op.MinimumReadConcernWireVersion)) {
 		return dst, nil
@@ -870,6 +1034,15 @@ func (op Operation) addReadConcern(dst []byte, desc description.SelectedServer)
 		rc = readconcern.New()
 	}
 
+	// reads in a snapshot session use snapshot read concern, at the snapshot time once it is known
+	snapshot := rc != nil && client != nil && client.Snapshot
+	if snapshot {
+		if desc.WireVersion == nil || !desc.WireVersion.Includes(readSnapshotMinWireVersion) {
+			return dst, ErrSnapshotUnsupported
+		}
+		rc = readconcern.Snapshot()
+	}
+
 	if rc == nil {
 		return dst, nil
 	}
@@ -884,6 +1057,11 @@ func (op Operation) addReadConcern(dst []byte, desc description.SelectedServer)
 		data = bsoncore.AppendTimestampElement(data, "afterClusterTime", client.OperationTime.T, client.OperationTime.I)
 		data, _ = bsoncore.AppendDocumentEnd(data, 0)
 	}
+	if snapshot && client.SnapshotTime != nil {
+		data = data[:len(data)-1] // remove the null byte
+		data = bsoncore.AppendTimestampElement(data, "atClusterTime", client.SnapshotTime.T, client.SnapshotTime.I)
+		data, _ = bsoncore.AppendDocumentEnd(data, 0)
+	}
 
 	if len(data) == bsoncore.EmptyDocumentLength {
 		return dst, nil
@@ -1264,18 +1442,52 @@ func (op *Operation) canMonitor(cmd string) bool {
 		cmd == "updateUser" || cmd == "copydbgetnonce" || cmd == "copydbsaslstart" || cmd == "copydb")
 }
 
+// redacted returns true if the command and reply of the given command are left out of command monitoring events,
+// either because the command is security sensitive or because the command monitor lists it as redacted.
+func (op Operation) redacted(cmdName string) bool {
+	if !op.canMonitor(cmdName) {
+		return true
+	}
+	for _, name := range op.CommandMonitor.RedactedCommands {
+		if strings.EqualFold(name, cmdName) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandMonitored returns true if the events of the given command should be published to the operation's command
+// monitor, according to the filter and sampler of the monitor.
+func (op Operation) commandMonitored(cmdName string, cmd bsoncore.Document) bool {
+	monitor := op.CommandMonitor
+	if monitor == nil {
+		return false
+	}
+	if monitor.Filter != nil {
+		var coll string
+		if elem, err := cmd.IndexErr(0); err == nil && elem.Value().Type == bsontype.String {
+			coll = elem.Value().StringValue()
+		}
+		if !monitor.Filter(cmdName, op.Database, coll) {
+			return false
+		}
+	}
+	return monitor.Sampler == nil || monitor.Sampler.Sample()
+}
+
 // publishStartedEvent publishes a CommandStartedEvent to the operation's command monitor if possible. If the command is
 // an unacknowledged write, a CommandSucceededEvent will be published as well. If started events are not being monitored,
 // no events are published.
 func (op Operation) publishStartedEvent(ctx context.Context, info startedInformation) {
-	if op.CommandMonitor == nil || op.CommandMonitor.Started == nil {
+	if op.CommandMonitor == nil || op.CommandMonitor.Started == nil || info.unmonitored {
 		return
 	}
 
 	// Make a copy of the command. Redact if the command is security sensitive and cannot be monitored.
 	// If there was a type 1 payload for the current batch, convert it to a BSON array.
 	var cmdCopy []byte
-	if op.canMonitor(info.cmdName) {
+	redacted := op.redacted(info.cmdName)
+	if !redacted {
 		cmdCopy = make([]byte, len(info.cmd))
 		copy(cmdCopy, info.cmd)
 		if info.documentSequenceIncluded {
@@ -1283,14 +1495,24 @@ func (op Operation) publishStartedEvent(ctx context.Context, info startedInforma
 			cmdCopy = op.addBatchArray(cmdCopy)
 			cmdCopy, _ = bsoncore.AppendDocumentEnd(cmdCopy, 0) // add back 0 byte and update length
 		}
+		if op.CommandMonitor.Redact != nil {
+			cmdCopy = op.CommandMonitor.Redact(info.cmdName, cmdCopy)
+		}
 	}
 
 	started := &event.CommandStartedEvent{
-		Command:      cmdCopy,
-		DatabaseName: op.Database,
-		CommandName:  info.cmdName,
-		RequestID:    int64(info.requestID),
-		ConnectionID: info.connID,
+		Command:            cmdCopy,
+		DatabaseName:       op.Database,
+		CommandName:        info.cmdName,
+		RequestID:          int64(info.requestID),
+		ConnectionID:       info.connID,
+		ServerConnectionID: info.serverConnID,
+		ServiceID:          info.serviceID,
+		Compressor:         info.compressor,
+		Retry:              info.retry,
+	}
+	if !redacted && op.CommandMonitor.Redact == nil {
+		started.WireMessage = info.wm
 	}
 	op.CommandMonitor.Started(ctx, started)
 }
@@ -1302,7 +1524,8 @@ func (op Operation) publishFinishedEvent(ctx context.Context, info finishedInfor
 	if _, ok := info.cmdErr.(WriteCommandError); ok {
 		success = true
 	}
-	if op.CommandMonitor == nil || (success && op.CommandMonitor.Succeeded == nil) || (!success && op.CommandMonitor.Failed == nil) {
+	if op.CommandMonitor == nil || info.unmonitored || (success && op.CommandMonitor.Succeeded == nil) ||
+		(!success && op.CommandMonitor.Failed == nil) {
 		return
 	}
 
@@ -1313,18 +1536,32 @@ func (op Operation) publishFinishedEvent(ctx context.Context, info finishedInfor
 	}
 
 	finished := event.CommandFinishedEvent{
-		CommandName:   info.cmdName,
-		RequestID:     int64(info.requestID),
-		ConnectionID:  info.connID,
-		DurationNanos: durationNanos,
+		CommandName:        info.cmdName,
+		RequestID:          int64(info.requestID),
+		ConnectionID:       info.connID,
+		DurationNanos:      durationNanos,
+		ServerConnectionID: info.started.serverConnID,
+		ServiceID:          info.started.serviceID,
+		Retry:              info.started.retry,
+	}
+	redacted := op.redacted(info.cmdName)
+	if info.reply != nil {
+		finished.ReplySize = info.reply.size
+		finished.Compressor = info.reply.compressor
+		if !redacted && op.CommandMonitor.Redact == nil {
+			finished.WireMessage = info.reply.wm
+		}
 	}
 
 	if success {
 		res := bson.Raw{}
-		// Only copy the reply for commands that are not security sensitive
-		if op.canMonitor(info.cmdName) {
+		// Only copy the reply for commands that are not redacted
+		if !redacted {
 			res = make([]byte, len(info.response))
 			copy(res, info.response)
+			if op.CommandMonitor.Redact != nil {
+				res = op.CommandMonitor.Redact(info.cmdName, res)
+			}
 		}
 		successEvent := &event.CommandSucceededEvent{
 			Reply:                res,
@@ -1338,5 +1575,8 @@ func (op Operation) publishFinishedEvent(ctx context.Context, info finishedInfor
 		Failure:              info.cmdErr.Error(),
 		CommandFinishedEvent: finished,
 	}
+	if cmdErr, ok := info.cmdErr.(Error); ok {
+		failedEvent.Code = cmdErr.Code
+	}
 	op.CommandMonitor.Failed(ctx, failedEvent)
 }
@@ -30,6 +30,9 @@ type Command struct {
 	srvr           driver.Server
 	desc           description.Server
 	crypt          *driver.Crypt
+	createCursor   bool
+	cursorRes      driver.CursorResponse
+	cursorErr      error
 }
 
 // NewCommand constructs and returns a new Command.
@@ -40,7 +43,10 @@ func (c *Command) Result() bsoncore.Document { return c.result }
 
 // ResultCursor parses the command response as a cursor and returns the resulting BatchCursor.
 func (c *Command) ResultCursor(opts driver.CursorOptions) (*driver.BatchCursor, error) {
-	cursorRes, err := driver.NewCursorResponse(c.result, c.srvr, c.desc)
+	cursorRes, err := c.cursorRes, c.cursorErr
+	if !c.createCursor {
+		cursorRes, err = driver.NewCursorResponse(c.result, c.srvr, c.desc)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -62,6 +68,9 @@ func (c *Command) Execute(ctx context.Context) error {
 			c.result = resp
 			c.srvr = srvr
 			c.desc = desc
+			if c.createCursor {
+				c.cursorRes, c.cursorErr = driver.NewCursorResponse(resp, srvr, desc)
+			}
 			return nil
 		},
 		Client:         c.session,
@@ -75,6 +84,18 @@ func (c *Command) Execute(ctx context.Context) error {
 	}.Execute(ctx, nil)
 }
 
+// CreateCursor specifies whether the response is parsed as a cursor as soon as it is received. This must be set if
+// ResultCursor will be called so that the cursor's connection can be pinned when the deployment is behind a load
+// balancer.
+func (c *Command) CreateCursor(create bool) *Command {
+	if c == nil {
+		c = new(Command)
+	}
+
+	c.createCursor = create
+	return c
+}
+
 // Command sets the command to be run.
 func (c *Command) Command(command bsoncore.Document) *Command {
 	if c == nil {
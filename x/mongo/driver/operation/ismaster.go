@@ -21,6 +21,7 @@ import (
 type IsMaster struct {
 	appname            string
 	compressors        []string
+	loadBalanced       bool
 	saslSupportedMechs string
 	d                  driver.Deployment
 	clock              *session.ClusterClock
@@ -55,6 +56,12 @@ func (im *IsMaster) Compressors(compressors []string) *IsMaster {
 	return im
 }
 
+// LoadBalanced specifies whether or not this operation is being sent over a connection to a load balanced cluster.
+func (im *IsMaster) LoadBalanced(lb bool) *IsMaster {
+	im.loadBalanced = lb
+	return im
+}
+
 // SASLSupportedMechs retrieves the supported SASL mechanism for the given user when this operation
 // is run.
 func (im *IsMaster) SASLSupportedMechs(username string) *IsMaster {
@@ -68,6 +75,11 @@ func (im *IsMaster) Deployment(d driver.Deployment) *IsMaster {
 	return im
 }
 
+// Reply returns the raw reply of the server to this operation.
+func (im *IsMaster) Reply() bsoncore.Document {
+	return im.res
+}
+
 // Result returns the result of executing this operation.
 func (im *IsMaster) Result(addr address.Address) description.Server {
 	desc := description.Server{Addr: addr, CanonicalAddr: addr, LastUpdateTime: time.Now().UTC()}
@@ -103,6 +115,14 @@ func (im *IsMaster) Result(addr address.Address) description.Server {
 				desc.LastError = err
 				return desc
 			}
+		case "connectionId":
+			i64, ok := element.Value().AsInt64OK()
+			if !ok {
+				desc.LastError = fmt.Errorf("expected 'connectionId' to be an integer but it's a BSON %s", element.Value().Type)
+				return desc
+			}
+			serverConnID := int32(i64)
+			desc.ServerConnectionID = &serverConnID
 		case "electionId":
 			desc.ElectionID, ok = element.Value().ObjectIDOK()
 			if !ok {
@@ -238,6 +258,13 @@ func (im *IsMaster) Result(addr address.Address) description.Server {
 				desc.LastError = fmt.Errorf("expected 'secondary' to be a boolean but it's a BSON %s", element.Value().Type)
 				return desc
 			}
+		case "serviceId":
+			oid, ok := element.Value().ObjectIDOK()
+			if !ok {
+				desc.LastError = fmt.Errorf("expected 'serviceId' to be an ObjectId but it's a BSON %s", element.Value().Type)
+				return desc
+			}
+			desc.ServiceID = &oid
 		case "setName":
 			desc.SetName, ok = element.Value().StringValueOK()
 			if !ok {
@@ -349,6 +376,9 @@ func (im *IsMaster) handshakeCommand(dst []byte, desc description.SelectedServer
 	if im.saslSupportedMechs != "" {
 		dst = bsoncore.AppendStringElement(dst, "saslSupportedMechs", im.saslSupportedMechs)
 	}
+	if im.loadBalanced {
+		dst = bsoncore.AppendBooleanElement(dst, "loadBalanced", true)
+	}
 	var idx int32
 	idx, dst = bsoncore.AppendArrayElementStart(dst, "compression")
 	for i, compressor := range im.compressors {
@@ -419,7 +449,12 @@ func (im *IsMaster) GetDescription(ctx context.Context, _ address.Address, c dri
 	if err != nil {
 		return description.Server{}, err
 	}
-	return im.Result(c.Address()), nil
+
+	desc := im.Result(c.Address())
+	if im.loadBalanced && desc.ServiceID == nil {
+		return description.Server{}, errors.New("driver attempted to initialize in load balancing mode, but the server does not support this mode")
+	}
+	return desc, nil
 }
 
 // FinishHandshake implements the Handshaker interface. This is a no-op function because a non-authenticated connection
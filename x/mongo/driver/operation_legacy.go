@@ -38,13 +38,15 @@ func (op Operation) legacyFind(ctx context.Context, dst []byte, srvr Server, con
 		return err
 	}
 	startedInfo.connID = conn.ID()
+	startedInfo.unmonitored = !op.commandMonitored(startedInfo.cmdName, startedInfo.cmd)
 	op.publishStartedEvent(ctx, startedInfo)
 
 	finishedInfo := finishedInformation{
-		cmdName:   startedInfo.cmdName,
-		requestID: startedInfo.requestID,
-		startTime: time.Now(),
-		connID:    startedInfo.connID,
+		cmdName:     startedInfo.cmdName,
+		requestID:   startedInfo.requestID,
+		startTime:   time.Now(),
+		connID:      startedInfo.connID,
+		unmonitored: startedInfo.unmonitored,
 	}
 
 	finishedInfo.response, finishedInfo.cmdErr = op.roundTripLegacyCursor(ctx, wm, srvr, conn, collName, firstBatchIdentifier)
@@ -209,13 +211,15 @@ func (op Operation) legacyGetMore(ctx context.Context, dst []byte, srvr Server,
 	}
 
 	startedInfo.connID = conn.ID()
+	startedInfo.unmonitored = !op.commandMonitored(startedInfo.cmdName, startedInfo.cmd)
 	op.publishStartedEvent(ctx, startedInfo)
 
 	finishedInfo := finishedInformation{
-		cmdName:   startedInfo.cmdName,
-		requestID: startedInfo.requestID,
-		startTime: time.Now(),
-		connID:    startedInfo.connID,
+		cmdName:     startedInfo.cmdName,
+		requestID:   startedInfo.requestID,
+		startTime:   time.Now(),
+		connID:      startedInfo.connID,
+		unmonitored: startedInfo.unmonitored,
 	}
 	finishedInfo.response, finishedInfo.cmdErr = op.roundTripLegacyCursor(ctx, wm, srvr, conn, collName, nextBatchIdentifier)
 	op.publishFinishedEvent(ctx, finishedInfo)
@@ -284,20 +288,22 @@ func (op Operation) legacyKillCursors(ctx context.Context, dst []byte, srvr Serv
 	}
 
 	startedInfo.connID = conn.ID()
+	startedInfo.unmonitored = !op.commandMonitored(startedInfo.cmdName, startedInfo.cmd)
 	op.publishStartedEvent(ctx, startedInfo)
 
 	// skip startTime because OP_KILL_CURSORS does not return a response
 	finishedInfo := finishedInformation{
-		cmdName:   "killCursors",
-		requestID: startedInfo.requestID,
-		connID:    startedInfo.connID,
+		cmdName:     "killCursors",
+		requestID:   startedInfo.requestID,
+		connID:      startedInfo.connID,
+		unmonitored: startedInfo.unmonitored,
 	}
 
 	err = conn.WriteWireMessage(ctx, wm)
 	if err != nil {
 		err = Error{Message: err.Error(), Labels: []string{TransientTransactionError, NetworkError}}
 		if ep, ok := srvr.(ErrorProcessor); ok {
-			ep.ProcessError(err)
+			ep.ProcessError(err, conn)
 		}
 
 		finishedInfo.cmdErr = err
@@ -376,13 +382,15 @@ func (op Operation) legacyListCollections(ctx context.Context, dst []byte, srvr
 		return err
 	}
 	startedInfo.connID = conn.ID()
+	startedInfo.unmonitored = !op.commandMonitored(startedInfo.cmdName, startedInfo.cmd)
 	op.publishStartedEvent(ctx, startedInfo)
 
 	finishedInfo := finishedInformation{
-		cmdName:   startedInfo.cmdName,
-		requestID: startedInfo.requestID,
-		startTime: time.Now(),
-		connID:    startedInfo.connID,
+		cmdName:     startedInfo.cmdName,
+		requestID:   startedInfo.requestID,
+		startTime:   time.Now(),
+		connID:      startedInfo.connID,
+		unmonitored: startedInfo.unmonitored,
 	}
 
 	finishedInfo.response, finishedInfo.cmdErr = op.roundTripLegacyCursor(ctx, wm, srvr, conn, collName, firstBatchIdentifier)
@@ -503,13 +511,15 @@ func (op Operation) legacyListIndexes(ctx context.Context, dst []byte, srvr Serv
 		return err
 	}
 	startedInfo.connID = conn.ID()
+	startedInfo.unmonitored = !op.commandMonitored(startedInfo.cmdName, startedInfo.cmd)
 	op.publishStartedEvent(ctx, startedInfo)
 
 	finishedInfo := finishedInformation{
-		cmdName:   startedInfo.cmdName,
-		requestID: startedInfo.requestID,
-		startTime: time.Now(),
-		connID:    startedInfo.connID,
+		cmdName:     startedInfo.cmdName,
+		requestID:   startedInfo.requestID,
+		startTime:   time.Now(),
+		connID:      startedInfo.connID,
+		unmonitored: startedInfo.unmonitored,
 	}
 
 	finishedInfo.response, finishedInfo.cmdErr = op.roundTripLegacyCursor(ctx, wm, srvr, conn, collName, firstBatchIdentifier)
@@ -635,7 +645,7 @@ func (op Operation) appendLegacyQueryDocument(dst []byte, filter bsoncore.Docume
 func (op Operation) roundTripLegacyCursor(ctx context.Context, wm []byte, srvr Server, conn Connection, collName, identifier string) (bsoncore.Document, error) {
 	wm, err := op.roundTripLegacy(ctx, conn, wm)
 	if ep, ok := srvr.(ErrorProcessor); ok {
-		ep.ProcessError(err)
+		ep.ProcessError(err, conn)
 	}
 	if err != nil {
 		return nil, err
@@ -247,6 +247,38 @@ func TestOperation(t *testing.T) {
 			}
 		}
 	})
+	t.Run("addReadConcern snapshot", func(t *testing.T) {
+		sess := &session.Client{Snapshot: true}
+		desc := description.SelectedServer{Server: description.Server{WireVersion: &description.VersionRange{Max: 13}}}
+		op := Operation{ReadConcern: readconcern.New(), Client: sess}
+
+		want := bsoncore.AppendDocumentElement(nil, "readConcern", bsoncore.BuildDocument(nil,
+			bsoncore.AppendStringElement(nil, "level", "snapshot"),
+		))
+		got, err := op.addReadConcern(nil, desc)
+		noerr(t, err)
+		if !bytes.Equal(got, want) {
+			t.Errorf("ReadConcern elements do not match. got %v; want %v", got, want)
+		}
+
+		sess.UpdateSnapshotTime(bsoncore.BuildDocumentFromElements(nil, bsoncore.BuildDocumentElement(nil, "cursor",
+			bsoncore.AppendTimestampElement(nil, "atClusterTime", 10, 2),
+		)))
+		want = bsoncore.AppendDocumentElement(nil, "readConcern", bsoncore.BuildDocument(nil,
+			bsoncore.AppendStringElement(nil, "level", "snapshot"),
+			bsoncore.AppendTimestampElement(nil, "atClusterTime", 10, 2),
+		))
+		got, err = op.addReadConcern(nil, desc)
+		noerr(t, err)
+		if !bytes.Equal(got, want) {
+			t.Errorf("ReadConcern elements do not match. got %v; want %v", got, want)
+		}
+
+		_, err = op.addReadConcern(nil, description.SelectedServer{})
+		if err != ErrSnapshotUnsupported {
+			t.Errorf("expected error %v, got %v", ErrSnapshotUnsupported, err)
+		}
+	})
 	t.Run("addWriteConcern", func(t *testing.T) {
 		want := bsoncore.AppendDocumentElement(nil, "writeConcern", bsoncore.BuildDocumentFromElements(
 			nil, bsoncore.AppendStringElement(nil, "w", "majority"),
@@ -489,8 +521,76 @@ func TestOperation(t *testing.T) {
 			})
 		}
 	})
+	t.Run("context comment", func(t *testing.T) {
+		type traceKey struct{}
+		ctx := context.WithValue(context.Background(), traceKey{}, "trace-1234")
+		deployment := commentDeployment{
+			SingleConnectionDeployment: SingleConnectionDeployment{C: new(mockConnection)},
+			fn: func(ctx context.Context) bsoncore.Value {
+				id, _ := ctx.Value(traceKey{}).(string)
+				return bsoncore.Value{Type: bsontype.String, Data: bsoncore.AppendString(nil, id)}
+			},
+		}
+		comment := func(t *testing.T, cmd bsoncore.Document, maxWireVersion int32) bsoncore.Value {
+			t.Helper()
+			op := Operation{
+				Database:   "foobar",
+				Deployment: deployment,
+				CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+					elems, _ := cmd.Elements()
+					for _, elem := range elems {
+						dst = append(dst, elem...)
+					}
+					return dst, nil
+				},
+			}
+			desc := description.SelectedServer{
+				Server: description.Server{WireVersion: &description.VersionRange{Max: maxWireVersion}},
+			}
+			wm, _, err := op.createMsgWireMessage(ctx, nil, desc)
+			noerr(t, err)
+
+			// 16 (msg header) + 4 (flags) + 1 (section type)
+			doc := bsoncore.Document(wm[21:])
+			noerr(t, doc.Validate())
+			val, err := doc.LookupErr("comment")
+			if err != nil {
+				return bsoncore.Value{}
+			}
+			return val
+		}
+		find := bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendStringElement(nil, "find", "coll"))
+
+		t.Run("added", func(t *testing.T) {
+			got := comment(t, find, 9)
+			if got.StringValue() != "trace-1234" {
+				t.Errorf("expected comment %q, got %v", "trace-1234", got)
+			}
+		})
+		t.Run("not added for old servers", func(t *testing.T) {
+			if got := comment(t, find, 8); got.Type != 0 {
+				t.Errorf("expected no comment, got %v", got)
+			}
+		})
+		t.Run("existing comment kept", func(t *testing.T) {
+			cmd := bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendStringElement(nil, "find", "coll"),
+				bsoncore.AppendStringElement(nil, "comment", "explicit"),
+			)
+			if got := comment(t, cmd, 9); got.StringValue() != "explicit" {
+				t.Errorf("expected comment %q, got %v", "explicit", got)
+			}
+		})
+	})
+}
+
+type commentDeployment struct {
+	SingleConnectionDeployment
+	fn func(context.Context) bsoncore.Value
 }
 
+func (d commentDeployment) CommandComment(ctx context.Context) bsoncore.Value { return d.fn(ctx) }
+
 type mockDeployment struct {
 	params struct {
 		selector description.ServerSelector
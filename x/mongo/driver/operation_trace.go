@@ -0,0 +1,97 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
+)
+
+// operationTrace records the progress of an operation on its span. A nil *operationTrace records nothing.
+type operationTrace struct {
+	span     event.Span
+	database string
+	retries  int
+}
+
+// startTrace starts a span for the operation if its deployment provides a tracer. The span is linked to the span of
+// the transaction the operation is part of, if any.
+func (op Operation) startTrace(ctx context.Context) (context.Context, *operationTrace) {
+	tp, ok := op.Deployment.(TracerProvider)
+	if !ok {
+		return ctx, nil
+	}
+	tracer := tp.Tracer()
+	if tracer == nil {
+		return ctx, nil
+	}
+
+	var links []event.Span
+	if op.Client != nil && op.Client.TransactionSpan != nil {
+		links = append(links, op.Client.TransactionSpan)
+	}
+	ctx, span := tracer.StartSpan(ctx, op.Database, links...)
+	span.SetAttributes(
+		event.SpanAttribute{Key: event.AttributeDBSystem, Value: "mongodb"},
+		event.SpanAttribute{Key: event.AttributeDBName, Value: op.Database},
+	)
+	return ctx, &operationTrace{span: span, database: op.Database}
+}
+
+// started records the command being sent and the server it is sent to. It is called for every attempt, so a retried
+// operation reports the server of its last attempt.
+func (t *operationTrace) started(cmdName string, cmd bsoncore.Document, addr address.Address) {
+	if t == nil {
+		return
+	}
+
+	name := cmdName + " " + t.database
+	attrs := []event.SpanAttribute{{Key: event.AttributeDBOperation, Value: cmdName}}
+	// Commands that operate on a collection name it in their first element.
+	if elem, err := cmd.IndexErr(0); err == nil && elem.Value().Type == bsontype.String {
+		coll := elem.Value().StringValue()
+		name += "." + coll
+		attrs = append(attrs, event.SpanAttribute{Key: event.AttributeDBCollection, Value: coll})
+	}
+	host, port := addr.String(), ""
+	if idx := strings.LastIndexByte(host, ':'); idx >= 0 {
+		host, port = host[:idx], host[idx+1:]
+	}
+	attrs = append(attrs, event.SpanAttribute{Key: event.AttributeNetPeerName, Value: host})
+	if p, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, event.SpanAttribute{Key: event.AttributeNetPeerPort, Value: p})
+	}
+
+	t.span.SetName(name)
+	t.span.SetAttributes(attrs...)
+}
+
+// retried records that the operation is being retried.
+func (t *operationTrace) retried() {
+	if t != nil {
+		t.retries++
+	}
+}
+
+// end records the outcome of the operation and ends its span.
+func (t *operationTrace) end(err error) {
+	if t == nil {
+		return
+	}
+
+	t.span.SetAttributes(event.SpanAttribute{Key: event.AttributeRetryAttempts, Value: t.retries})
+	if err != nil {
+		t.span.RecordError(err)
+	}
+	t.span.End()
+}
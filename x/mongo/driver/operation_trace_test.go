@@ -0,0 +1,136 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/session"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/uuid"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+type testSpan struct {
+	name  string
+	attrs map[string]interface{}
+	links []event.Span
+	err   error
+	ended bool
+}
+
+func (s *testSpan) SetName(name string) { s.name = name }
+func (s *testSpan) SetAttributes(attrs ...event.SpanAttribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+func (s *testSpan) RecordError(err error) { s.err = err }
+func (s *testSpan) End()                  { s.ended = true }
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) StartSpan(ctx context.Context, name string, links ...event.Span) (context.Context, event.Span) {
+	span := &testSpan{name: name, attrs: make(map[string]interface{}), links: links}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+type tracingDeployment struct {
+	SingleConnectionDeployment
+	tracer event.Tracer
+}
+
+func (d tracingDeployment) Tracer() event.Tracer { return d.tracer }
+
+func TestOperationTracing(t *testing.T) {
+	newOperation := func(conn *mockConnection, tracer event.Tracer) Operation {
+		return Operation{
+			CommandFn: func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+				return bsoncore.AppendStringElement(dst, "find", "coll"), nil
+			},
+			Database:   "db",
+			Deployment: tracingDeployment{SingleConnectionDeployment{C: conn}, tracer},
+			Type:       Read,
+		}
+	}
+	newConnection := func() *mockConnection {
+		return &mockConnection{
+			rDesc: description.Server{WireVersion: &description.VersionRange{Max: 8}},
+			rAddr: "db.example.com:27017",
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		conn := newConnection()
+		idx, wm := wiremessage.AppendHeaderStart(nil, 1, 0, wiremessage.OpMsg)
+		wm = wiremessage.AppendMsgFlags(wm, 0)
+		wm = wiremessage.AppendMsgSectionType(wm, wiremessage.SingleDocument)
+		wm = bsoncore.BuildDocumentFromElements(wm, bsoncore.AppendInt32Element(nil, "ok", 1))
+		conn.rReadWM = bsoncore.UpdateLength(wm, idx, int32(len(wm)))
+
+		tracer := new(testTracer)
+		txnSpan := &testSpan{}
+		op := newOperation(conn, tracer)
+		id, err := uuid.New()
+		noerr(t, err)
+		sess, err := session.NewClientSession(session.NewPool(nil), id, session.Explicit)
+		noerr(t, err)
+		sess.TransactionSpan = txnSpan
+		op.Client = sess
+		op.Clock = new(session.ClusterClock)
+		err = op.Execute(context.Background(), nil)
+		assert.Nil(t, err, "Execute error: %v", err)
+
+		assert.Equal(t, 1, len(tracer.spans), "expected 1 span, got %d", len(tracer.spans))
+		span := tracer.spans[0]
+		assert.Equal(t, "find db.coll", span.name, "unexpected span name")
+		assert.True(t, span.ended, "expected span to be ended")
+		assert.Nil(t, span.err, "expected no error to be recorded, got %v", span.err)
+		assert.Equal(t, 1, len(span.links), "expected span to be linked to the transaction span")
+		want := map[string]interface{}{
+			event.AttributeDBSystem:      "mongodb",
+			event.AttributeDBName:        "db",
+			event.AttributeDBOperation:   "find",
+			event.AttributeDBCollection:  "coll",
+			event.AttributeNetPeerName:   "db.example.com",
+			event.AttributeNetPeerPort:   27017,
+			event.AttributeRetryAttempts: 0,
+		}
+		assert.Equal(t, want, span.attrs, "unexpected span attributes")
+	})
+	t.Run("records retries and error", func(t *testing.T) {
+		conn := newConnection()
+		conn.rReadErr = errors.New("connection reset")
+
+		tracer := new(testTracer)
+		op := newOperation(conn, tracer)
+		op.RetryMode = new(RetryMode)
+		*op.RetryMode = RetryOnce
+		err := op.Execute(context.Background(), nil)
+		assert.NotNil(t, err, "expected Execute error, got nil")
+
+		span := tracer.spans[0]
+		assert.Equal(t, 1, span.attrs[event.AttributeRetryAttempts], "unexpected retry attempts")
+		assert.NotNil(t, span.err, "expected error to be recorded")
+		assert.True(t, span.ended, "expected span to be ended")
+	})
+	t.Run("no tracer", func(t *testing.T) {
+		conn := newConnection()
+		conn.rReadErr = errors.New("connection reset")
+
+		err := newOperation(conn, nil).Execute(context.Background(), nil)
+		assert.NotNil(t, err, "expected Execute error, got nil")
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"context"
+	"runtime/pprof"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// Names of the pprof labels set by the driver.
+const (
+	// ProfilerLabelCommand is the name of the command being run.
+	ProfilerLabelCommand = "mongodb.command"
+	// ProfilerLabelNamespace is the namespace of the command being run, e.g. "db.coll", or the database for commands
+	// that do not operate on a collection.
+	ProfilerLabelNamespace = "mongodb.namespace"
+	// ProfilerLabelComponent is the driver component that runs a background goroutine, e.g. "monitor".
+	ProfilerLabelComponent = "mongodb.component"
+	// ProfilerLabelAddress is the address of the server that a background goroutine works for.
+	ProfilerLabelAddress = "mongodb.address"
+)
+
+// operationProfilerLabels returns true if the goroutine running the operation should be labeled.
+func (op Operation) operationProfilerLabels() bool {
+	labeler, ok := op.Deployment.(ProfilerLabeler)
+	return ok && labeler.OperationProfilerLabels()
+}
+
+// labelGoroutine sets pprof labels with the name and namespace of cmd on the current goroutine and returns ctx with
+// the labels added. The caller must restore the labels of the goroutine when the operation finishes.
+func (op Operation) labelGoroutine(ctx context.Context, cmdName string, cmd bsoncore.Document) context.Context {
+	ns := op.Database
+	if elem, err := cmd.IndexErr(0); err == nil && elem.Value().Type == bsontype.String {
+		ns += "." + elem.Value().StringValue()
+	}
+	ctx = pprof.WithLabels(ctx, pprof.Labels(ProfilerLabelCommand, cmdName, ProfilerLabelNamespace, ns))
+	pprof.SetGoroutineLabels(ctx)
+	return ctx
+}
@@ -0,0 +1,66 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"runtime/pprof"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+)
+
+type labeledDeployment struct {
+	SingleConnectionDeployment
+	enabled bool
+}
+
+func (d labeledDeployment) OperationProfilerLabels() bool { return d.enabled }
+
+// ctxConnection records the context passed to WriteWireMessage.
+type ctxConnection struct {
+	*mockConnection
+	ctx context.Context
+}
+
+func (c *ctxConnection) WriteWireMessage(ctx context.Context, wm []byte) error {
+	c.ctx = ctx
+	return c.mockConnection.WriteWireMessage(ctx, wm)
+}
+
+func TestOperationProfilerLabels(t *testing.T) {
+	execute := func(enabled bool) context.Context {
+		conn := &ctxConnection{mockConnection: &mockConnection{
+			rDesc:    description.Server{WireVersion: &description.VersionRange{Max: 8}},
+			rReadErr: errors.New("connection reset"),
+		}}
+		_ = Operation{
+			CommandFn: func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+				return bsoncore.AppendStringElement(dst, "find", "coll"), nil
+			},
+			Database:   "db",
+			Deployment: labeledDeployment{SingleConnectionDeployment{C: conn}, enabled},
+		}.Execute(context.Background(), nil)
+		return conn.ctx
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		ctx := execute(true)
+		cmd, _ := pprof.Label(ctx, ProfilerLabelCommand)
+		assert.Equal(t, "find", cmd, "unexpected command label")
+		ns, _ := pprof.Label(ctx, ProfilerLabelNamespace)
+		assert.Equal(t, "db.coll", ns, "unexpected namespace label")
+	})
+	t.Run("disabled", func(t *testing.T) {
+		ctx := execute(false)
+		_, ok := pprof.Label(ctx, ProfilerLabelCommand)
+		assert.False(t, ok, "expected no command label")
+	})
+}
@@ -7,14 +7,18 @@
 package session // import "go.mongodb.org/mongo-driver/x/mongo/driver/session"
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo/readconcern"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/uuid"
 )
@@ -40,6 +44,9 @@ var ErrCommitAfterAbort = errors.New("cannot call commitTransaction after callin
 // ErrUnackWCUnsupported is returned if an unacknowledged write concern is supported for a transaciton.
 var ErrUnackWCUnsupported = errors.New("transactions do not support unacknowledged write concerns")
 
+// ErrSnapshotTransaction is returned if a transaction is started in a snapshot session.
+var ErrSnapshotTransaction = errors.New("transactions are not supported in snapshot sessions")
+
 // Type describes the type of the session
 type Type uint8
 
@@ -75,6 +82,8 @@ type Client struct {
 	Aborting       bool
 	RetryWrite     bool
 	RetryRead      bool
+	Snapshot       bool                 // reads use snapshot read concern at SnapshotTime
+	SnapshotTime   *primitive.Timestamp // nil until the first snapshot read returns an atClusterTime
 
 	// options for the current transaction
 	// most recently set by transactionopt
@@ -89,10 +98,30 @@ type Client struct {
 	transactionWc            *writeconcern.WriteConcern
 	transactionMaxCommitTime *time.Duration
 
-	pool          *Pool
-	state         state
-	PinnedServer  *description.Server
-	RecoveryToken bson.Raw
+	pool             *Pool
+	state            state
+	PinnedServer     *description.Server
+	PinnedConnection LoadBalancedTransactionConnection
+	RecoveryToken    bson.Raw
+
+	// TransactionSpan is the span of the transaction in progress, if tracing is enabled. The spans of operations
+	// run in the transaction are linked to it.
+	TransactionSpan event.Span
+}
+
+// LoadBalancedTransactionConnection represents a connection that is pinned to a transaction when the deployment is
+// behind a load balancer. It has the same methods as driver.PinnedConnection.
+type LoadBalancedTransactionConnection interface {
+	WriteWireMessage(context.Context, []byte) error
+	ReadWireMessage(ctx context.Context, dst []byte) ([]byte, error)
+	Description() description.Server
+	Close() error
+	ID() string
+	Address() address.Address
+	PinToCursor() error
+	PinToTransaction() error
+	UnpinFromCursor() error
+	UnpinFromTransaction() error
 }
 
 func getClusterTime(clusterTime bson.Raw) (uint32, uint32) {
@@ -156,6 +185,12 @@ func NewClientSession(pool *Pool, clientID uuid.UUID, sessionType Type, opts ...
 	if mergedOpts.DefaultMaxCommitTime != nil {
 		c.transactionMaxCommitTime = mergedOpts.DefaultMaxCommitTime
 	}
+	if mergedOpts.Snapshot != nil && *mergedOpts.Snapshot {
+		// Reads in a snapshot session all see the same point in time, so causal consistency does not apply.
+		c.Snapshot = true
+		c.Consistent = false
+		c.SnapshotTime = mergedOpts.SnapshotTime
+	}
 
 	servSess, err := pool.GetSession()
 	if err != nil {
@@ -207,6 +242,24 @@ func (c *Client) UpdateUseTime() error {
 	return nil
 }
 
+// UpdateSnapshotTime sets the session's snapshot time from the atClusterTime field of the server response, or of its
+// cursor document, if the session is a snapshot session that does not have a snapshot time yet.
+func (c *Client) UpdateSnapshotTime(response bsoncore.Document) {
+	if c == nil || !c.Snapshot || c.SnapshotTime != nil {
+		return
+	}
+
+	doc := response
+	if cursor, ok := response.Lookup("cursor").DocumentOK(); ok {
+		doc = cursor
+	}
+	t, i, ok := doc.Lookup("atClusterTime").TimestampOK()
+	if !ok {
+		return
+	}
+	c.SnapshotTime = &primitive.Timestamp{T: t, I: i}
+}
+
 // UpdateRecoveryToken updates the session's recovery token from the server response.
 func (c *Client) UpdateRecoveryToken(response bson.Raw) {
 	if c == nil {
@@ -221,11 +274,27 @@ func (c *Client) UpdateRecoveryToken(response bson.Raw) {
 	c.RecoveryToken = token.Document()
 }
 
-// ClearPinnedServer sets the PinnedServer to nil.
+// ClearPinnedServer sets the PinnedServer to nil and unpins the PinnedConnection, if any.
 func (c *Client) ClearPinnedServer() {
 	if c != nil {
 		c.PinnedServer = nil
+		_ = c.UnpinConnection()
+	}
+}
+
+// UnpinConnection unpins the PinnedConnection from the transaction, closes it, and sets it to nil. Closing the
+// connection returns it to its pool unless it is also pinned to a cursor.
+func (c *Client) UnpinConnection() error {
+	if c == nil || c.PinnedConnection == nil {
+		return nil
 	}
+
+	err := c.PinnedConnection.UnpinFromTransaction()
+	if closeErr := c.PinnedConnection.Close(); err == nil {
+		err = closeErr
+	}
+	c.PinnedConnection = nil
+	return err
 }
 
 // EndSession ends the session.
@@ -235,6 +304,7 @@ func (c *Client) EndSession() {
 	}
 
 	c.Terminated = true
+	_ = c.UnpinConnection()
 	c.pool.ReturnSession(c.Server)
 
 	return
@@ -267,6 +337,9 @@ func (c *Client) CheckStartTransaction() error {
 	if c.state == InProgress || c.state == Starting {
 		return ErrTransactInProgress
 	}
+	if c.Snapshot {
+		return ErrSnapshotTransaction
+	}
 	return nil
 }
 
@@ -311,6 +384,7 @@ func (c *Client) StartTransaction(opts *TransactionOptions) error {
 
 	c.state = Starting
 	c.PinnedServer = nil
+	_ = c.UnpinConnection()
 	return nil
 }
 
@@ -399,5 +473,6 @@ func (c *Client) clearTransactionOpts() {
 	c.CurrentRp = nil
 	c.CurrentRc = nil
 	c.PinnedServer = nil
+	_ = c.UnpinConnection()
 	c.RecoveryToken = nil
 }
@@ -9,6 +9,7 @@ package session
 import (
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/readconcern"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
@@ -21,6 +22,8 @@ type ClientOptions struct {
 	DefaultWriteConcern   *writeconcern.WriteConcern
 	DefaultReadPreference *readpref.ReadPref
 	DefaultMaxCommitTime  *time.Duration
+	Snapshot              *bool
+	SnapshotTime          *primitive.Timestamp
 }
 
 // TransactionOptions represents all possible options for starting a transaction in a session.
@@ -52,6 +55,12 @@ func mergeClientOptions(opts ...*ClientOptions) *ClientOptions {
 		if opt.DefaultMaxCommitTime != nil {
 			c.DefaultMaxCommitTime = opt.DefaultMaxCommitTime
 		}
+		if opt.Snapshot != nil {
+			c.Snapshot = opt.Snapshot
+		}
+		if opt.SnapshotTime != nil {
+			c.SnapshotTime = opt.SnapshotTime
+		}
 	}
 
 	return c
@@ -11,6 +11,7 @@ import (
 
 	"crypto/rand"
 
+	"go.mongodb.org/mongo-driver/clock"
 	"go.mongodb.org/mongo-driver/x/bsonx"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/uuid"
 )
@@ -23,6 +24,8 @@ type Server struct {
 	TxnNumber int64
 	LastUsed  time.Time
 	Dirty     bool
+
+	clock clock.Clock // the source of LastUsed, or nil to use clock.System
 }
 
 // returns whether or not a session has expired given a timeout in minutes
@@ -31,17 +34,17 @@ func (ss *Server) expired(timeoutMinutes uint32) bool {
 	if timeoutMinutes <= 0 {
 		return true
 	}
-	timeUnused := time.Since(ss.LastUsed).Minutes()
+	timeUnused := clock.Since(ss.clock, ss.LastUsed).Minutes()
 	return timeUnused > float64(timeoutMinutes-1)
 }
 
 // update the last used time for this session.
 // must be called whenever this server session is used to send a command to the server.
 func (ss *Server) updateUseTime() {
-	ss.LastUsed = time.Now()
+	ss.LastUsed = clock.OrSystem(ss.clock).Now()
 }
 
-func newServerSession() (*Server, error) {
+func newServerSession(c clock.Clock) (*Server, error) {
 	id, err := uuid.New()
 	if err != nil {
 		return nil, err
@@ -51,7 +54,8 @@ func newServerSession() (*Server, error) {
 
 	return &Server{
 		SessionID: idDoc,
-		LastUsed:  time.Now(),
+		LastUsed:  clock.OrSystem(c).Now(),
+		clock:     c,
 	}, nil
 }
 
@@ -16,7 +16,7 @@ import (
 func TestServerSession(t *testing.T) {
 
 	t.Run("Expired", func(t *testing.T) {
-		sess, err := newServerSession()
+		sess, err := newServerSession(nil)
 		require.Nil(t, err, "Unexpected error")
 		if !sess.expired(0) {
 			t.Errorf("session should be expired")
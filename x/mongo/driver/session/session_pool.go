@@ -7,12 +7,18 @@
 package session
 
 import (
+	"errors"
 	"sync"
 
+	"go.mongodb.org/mongo-driver/clock"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/x/bsonx"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
 )
 
+// ErrPoolDraining is returned by GetSession after Drain has been called.
+var ErrPoolDraining = errors.New("client is draining and not accepting new operations")
+
 // Node represents a server session in a linked list
 type Node struct {
 	*Server
@@ -27,12 +33,15 @@ type Pool struct {
 	tail     *Node
 	timeout  uint32
 	mutex    sync.Mutex // mutex to protect list and sessionTimeout
+	clock    clock.Clock
 
-	checkedOut int // number of sessions checked out of pool
+	checkedOut int    // number of sessions checked out of pool
+	expired    uint64 // number of sessions discarded because they expired
+	draining   bool   // if true, no new sessions are checked out
 }
 
 func (p *Pool) createServerSession() (*Server, error) {
-	s, err := newServerSession()
+	s, err := newServerSession(p.clock)
 	if err != nil {
 		return nil, err
 	}
@@ -43,8 +52,15 @@ func (p *Pool) createServerSession() (*Server, error) {
 
 // NewPool creates a new server session pool
 func NewPool(descChan <-chan description.Topology) *Pool {
+	return NewPoolWithClock(descChan, nil)
+}
+
+// NewPoolWithClock creates a new server session pool that uses c to determine when sessions were last used and whether
+// they have expired. If c is nil, clock.System is used.
+func NewPoolWithClock(descChan <-chan description.Topology, c clock.Clock) *Pool {
 	p := &Pool{
 		descChan: descChan,
+		clock:    c,
 	}
 
 	return p
@@ -65,6 +81,10 @@ func (p *Pool) GetSession() (*Server, error) {
 	p.mutex.Lock() // prevent changing the linked list while seeing if sessions have expired
 	defer p.mutex.Unlock()
 
+	if p.draining {
+		return nil, ErrPoolDraining
+	}
+
 	// empty pool
 	if p.head == nil && p.tail == nil {
 		return p.createServerSession()
@@ -75,6 +95,7 @@ func (p *Pool) GetSession() (*Server, error) {
 		// pull session from head of queue and return if it is valid for at least 1 more minute
 		if p.head.expired(p.timeout) {
 			p.head = p.head.next
+			p.expired++
 			continue
 		}
 
@@ -117,10 +138,15 @@ func (p *Pool) ReturnSession(ss *Server) {
 			p.tail.prev.next = nil
 		}
 		p.tail = p.tail.prev
+		p.expired++
+	}
+	if p.tail == nil {
+		p.head = nil
 	}
 
 	// session expired
 	if ss.expired(p.timeout) {
+		p.expired++
 		return
 	}
 
@@ -176,5 +202,30 @@ func (p *Pool) String() string {
 
 // CheckedOut returns number of sessions checked out from pool.
 func (p *Pool) CheckedOut() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	return p.checkedOut
 }
+
+// Stats returns a snapshot of the number of sessions that are checked out, available in the pool, and discarded because
+// they expired.
+func (p *Pool) Stats() event.SessionPoolStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	stats := event.SessionPoolStats{
+		CheckedOut: uint64(p.checkedOut),
+		Expired:    p.expired,
+	}
+	for node := p.head; node != nil; node = node.next {
+		stats.Pooled++
+	}
+	return stats
+}
+
+// Drain stops the pool from checking out new sessions. Sessions that are already checked out can still be returned.
+func (p *Pool) Drain() {
+	p.mutex.Lock()
+	p.draining = true
+	p.mutex.Unlock()
+}
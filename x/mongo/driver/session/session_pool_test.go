@@ -8,7 +8,9 @@ package session
 
 import (
 	"testing"
+	"time"
 
+	"go.mongodb.org/mongo-driver/clock"
 	"go.mongodb.org/mongo-driver/internal/testutil/helpers"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
 )
@@ -68,4 +70,78 @@ func TestSessionPool(t *testing.T) {
 			t.Errorf("Expired sessions not removed!")
 		}
 	})
+
+	t.Run("TestDrain", func(t *testing.T) {
+		descChan := make(chan description.Topology)
+		p := NewPool(descChan)
+
+		sess, err := p.GetSession()
+		testhelpers.RequireNil(t, err, "error getting session %s", err)
+
+		p.Drain()
+		if _, err = p.GetSession(); err != ErrPoolDraining {
+			t.Errorf("expected ErrPoolDraining, got %v", err)
+		}
+
+		p.ReturnSession(sess)
+		if p.CheckedOut() != 0 {
+			t.Errorf("expected checked out sessions to be returned while draining, got %d", p.CheckedOut())
+		}
+	})
+	t.Run("TestStats", func(t *testing.T) {
+		descChan := make(chan description.Topology)
+		p := NewPool(descChan)
+		p.timeout = 30
+
+		first, err := p.GetSession()
+		testhelpers.RequireNil(t, err, "error getting session %s", err)
+		second, err := p.GetSession()
+		testhelpers.RequireNil(t, err, "error getting session %s", err)
+		p.ReturnSession(first)
+
+		stats := p.Stats()
+		if stats.CheckedOut != 1 || stats.Pooled != 1 || stats.Expired != 0 {
+			t.Errorf("expected 1 checked out and 1 pooled session, got %+v", stats)
+		}
+
+		// Returned sessions will be stale
+		p.timeout = 0
+		p.ReturnSession(second)
+
+		stats = p.Stats()
+		if stats.CheckedOut != 0 || stats.Pooled != 0 || stats.Expired != 2 {
+			t.Errorf("expected 2 expired sessions, got %+v", stats)
+		}
+	})
+	t.Run("TestClock", func(t *testing.T) {
+		fake := clock.NewFake(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+		p := NewPoolWithClock(make(chan description.Topology), fake)
+		p.timeout = 30
+
+		first, err := p.GetSession()
+		testhelpers.RequireNil(t, err, "error getting session %s", err)
+		if !first.LastUsed.Equal(fake.Now()) {
+			t.Errorf("expected last used time %v, got %v", fake.Now(), first.LastUsed)
+		}
+
+		fake.Advance(10 * time.Minute)
+		first.updateUseTime()
+		p.ReturnSession(first)
+		second, err := p.GetSession()
+		testhelpers.RequireNil(t, err, "error getting session %s", err)
+		if !second.SessionID.Equal(first.SessionID) {
+			t.Errorf("expected session %s to be reused, got %s", first.SessionID, second.SessionID)
+		}
+
+		p.ReturnSession(second)
+		fake.Advance(29*time.Minute + time.Second)
+		third, err := p.GetSession()
+		testhelpers.RequireNil(t, err, "error getting session %s", err)
+		if third.SessionID.Equal(first.SessionID) {
+			t.Errorf("expected session %s to expire", first.SessionID)
+		}
+		if p.Stats().Expired != 1 {
+			t.Errorf("expected 1 expired session, got %+v", p.Stats())
+		}
+	})
 }
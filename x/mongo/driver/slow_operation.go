@@ -0,0 +1,110 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
+)
+
+// summaryOmittedFields are command fields added by the driver that are left out of command summaries.
+var summaryOmittedFields = map[string]bool{
+	"lsid":         true,
+	"$clusterTime": true,
+	"$db":          true,
+}
+
+// reportSlowOperation reports the command described by info to the slow operation monitor of the deployment if the
+// command took longer than the configured threshold.
+func (op Operation) reportSlowOperation(info startedInformation, duration time.Duration, addr address.Address) {
+	reporter, ok := op.Deployment.(SlowOperationReporter)
+	if !ok {
+		return
+	}
+	threshold, monitor := reporter.SlowOperationThreshold(), reporter.SlowOperationMonitor()
+	if threshold <= 0 || duration <= threshold || monitor == nil || monitor.SlowOperation == nil {
+		return
+	}
+
+	evt := &event.SlowOperationEvent{
+		CommandName: info.cmdName,
+		Namespace:   op.Database,
+		Duration:    duration,
+		Address:     addr.String(),
+	}
+	if elem, err := info.cmd.IndexErr(0); err == nil && elem.Value().Type == bsontype.String {
+		evt.Namespace += "." + elem.Value().StringValue()
+	}
+	if op.canMonitor(info.cmdName) {
+		evt.CommandSummary = summarizeCommand(info.cmd)
+	}
+	monitor.SlowOperation(evt)
+}
+
+// summarizeCommand returns cmd as extended JSON with every value replaced by "?", except for the value of the first
+// element, which names the collection for most commands.
+func summarizeCommand(cmd bsoncore.Document) string {
+	var sb strings.Builder
+	elems, err := cmd.Elements()
+	if err != nil {
+		return ""
+	}
+
+	sb.WriteByte('{')
+	first := true
+	for i, elem := range elems {
+		if summaryOmittedFields[elem.Key()] {
+			continue
+		}
+		if !first {
+			sb.WriteString(", ")
+		}
+		first = false
+		sb.WriteString(strconv.Quote(elem.Key()) + ": ")
+		if val := elem.Value(); i == 0 && val.Type == bsontype.String {
+			sb.WriteString(strconv.Quote(val.StringValue()))
+			continue
+		}
+		summarizeValue(&sb, elem.Value())
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func summarizeValue(sb *strings.Builder, val bsoncore.Value) {
+	switch val.Type {
+	case bsontype.EmbeddedDocument:
+		sb.WriteByte('{')
+		elems, _ := val.Document().Elements()
+		for i, elem := range elems {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(strconv.Quote(elem.Key()) + ": ")
+			summarizeValue(sb, elem.Value())
+		}
+		sb.WriteByte('}')
+	case bsontype.Array:
+		sb.WriteByte('[')
+		vals, _ := val.Array().Values()
+		for i, v := range vals {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			summarizeValue(sb, v)
+		}
+		sb.WriteByte(']')
+	default:
+		sb.WriteString(`"?"`)
+	}
+}
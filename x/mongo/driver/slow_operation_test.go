@@ -0,0 +1,101 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+)
+
+type slowOperationDeployment struct {
+	SingleConnectionDeployment
+	threshold time.Duration
+	monitor   *event.SlowOperationMonitor
+}
+
+func (d slowOperationDeployment) SlowOperationThreshold() time.Duration { return d.threshold }
+func (d slowOperationDeployment) SlowOperationMonitor() *event.SlowOperationMonitor {
+	return d.monitor
+}
+
+func TestSlowOperation(t *testing.T) {
+	elems := [][]byte{
+		bsoncore.AppendStringElement(nil, "find", "coll"),
+		bsoncore.BuildDocumentElement(nil, "filter",
+			bsoncore.AppendStringElement(nil, "name", "alice"),
+			bsoncore.BuildDocumentElement(nil, "age", bsoncore.AppendInt32Element(nil, "$gt", 21)),
+		),
+		bsoncore.BuildArrayElement(nil, "sort", bsoncore.Value{Type: bsontype.Int32, Data: bsoncore.AppendInt32(nil, 1)}),
+	}
+
+	t.Run("summarizeCommand", func(t *testing.T) {
+		want := `{"find": "coll", "filter": {"name": "?", "age": {"$gt": "?"}}, "sort": ["?"]}`
+		cmd := bsoncore.BuildDocument(nil, append(elems, bsoncore.AppendStringElement(nil, "$db", "db"))...)
+		got := summarizeCommand(cmd)
+		assert.Equal(t, want, got, "unexpected command summary")
+	})
+	t.Run("reports commands over threshold", func(t *testing.T) {
+		testCases := []struct {
+			name      string
+			threshold time.Duration
+			reported  bool
+		}{
+			{"over threshold", time.Nanosecond, true},
+			{"under threshold", time.Hour, false},
+			{"disabled", 0, false},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				var events []*event.SlowOperationEvent
+				conn := &mockConnection{
+					rDesc:    description.Server{WireVersion: &description.VersionRange{Max: 8}},
+					rAddr:    "db.example.com:27017",
+					rReadErr: errors.New("connection reset"),
+				}
+				op := Operation{
+					CommandFn: func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+						for _, elem := range elems {
+							dst = append(dst, elem...)
+						}
+						return dst, nil
+					},
+					Database: "db",
+					Deployment: slowOperationDeployment{
+						SingleConnectionDeployment{C: conn},
+						tc.threshold,
+						&event.SlowOperationMonitor{
+							SlowOperation: func(evt *event.SlowOperationEvent) { events = append(events, evt) },
+						},
+					},
+				}
+				_ = op.Execute(context.Background(), nil)
+
+				if !tc.reported {
+					assert.Equal(t, 0, len(events), "expected no slow operation events, got %d", len(events))
+					return
+				}
+				assert.Equal(t, 1, len(events), "expected 1 slow operation event, got %d", len(events))
+				evt := events[0]
+				assert.Equal(t, "find", evt.CommandName, "unexpected command name")
+				assert.Equal(t, "db.coll", evt.Namespace, "unexpected namespace")
+				assert.Equal(t, "db.example.com:27017", evt.Address, "unexpected address")
+				assert.True(t, evt.Duration > 0, "expected positive duration")
+				want := `{"find": "coll", "filter": {"name": "?", "age": {"$gt": "?"}}, "sort": ["?"], ` +
+					`"$readPreference": {"mode": "?"}}`
+				assert.Equal(t, want, evt.CommandSummary, "unexpected command summary")
+			})
+		}
+	})
+}
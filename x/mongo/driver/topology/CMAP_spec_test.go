@@ -111,7 +111,7 @@ func runCMAPTest(t *testing.T, testFileName string) {
 			return time.Duration(test.PoolOptions.MaxIdleTimeMS) * time.Millisecond
 		}),
 		WithConnectionPoolMonitor(func(monitor *event.PoolMonitor) *event.PoolMonitor {
-			return &event.PoolMonitor{func(event *event.PoolEvent) { testInfo.originalEventChan <- event }}
+			return &event.PoolMonitor{Event: func(event *event.PoolEvent) { testInfo.originalEventChan <- event }}
 		}))
 	testHelpers.RequireNil(t, err, "error creating server: %v", err)
 	s.connectionstate = connected
@@ -178,7 +178,8 @@ func runCMAPTest(t *testing.T, testFileName string) {
 		testInfo.finalEventChan <- temp
 	}
 
-	checkEvents(t, test.Events, testInfo.finalEventChan, test.Ignore)
+	// ConnectionCheckOutQueued events are not part of the CMAP specification.
+	checkEvents(t, test.Events, testInfo.finalEventChan, append(test.Ignore, event.CheckOutQueued))
 
 }
 
@@ -417,7 +418,7 @@ func runOperationInThread(t *testing.T, operation map[string]interface{}, testIn
 		}
 		return c.Close()
 	case "clear":
-		s.pool.clear()
+		s.pool.clear(nil)
 	case "close":
 		return s.pool.disconnect(context.Background())
 	default:
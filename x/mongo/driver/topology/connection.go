@@ -12,12 +12,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net"
+	"net/http/httptrace"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 	"go.mongodb.org/mongo-driver/x/mongo/driver"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
@@ -43,16 +47,23 @@ type connection struct {
 	zliblevel            int
 	zstdLevel            int
 	connected            int32 // must be accessed using the sync/atomic package
+	checkedOut           int32 // must be accessed using the sync/atomic package
 	connectDone          chan struct{}
 	connectErr           error
 	config               *connectionConfig
 	cancelConnectContext context.CancelFunc
 	connectContextMade   chan struct{}
+	timings              event.ConnectionTimings // time spent in each phase of connect
 
 	// pool related fields
 	pool       *pool
 	poolID     uint64
 	generation uint64
+
+	// serviceID and serviceGeneration identify the backend server behind a load balancer that the connection is
+	// connected to. They are guarded by the pool's mutex.
+	serviceID         *primitive.ObjectID
+	serviceGeneration uint64
 }
 
 // newConnection handles the creation of a connection. It does not connect the connection.
@@ -62,9 +73,14 @@ func newConnection(ctx context.Context, addr address.Address, opts ...Connection
 		return nil, err
 	}
 
+	var jitter float64
+	if cfg.expiryJitter > 0 {
+		jitter = rand.Float64()
+	}
+
 	var lifetimeDeadline time.Time
 	if cfg.lifeTimeout > 0 {
-		lifetimeDeadline = time.Now().Add(cfg.lifeTimeout)
+		lifetimeDeadline = time.Now().Add(cfg.lifeTimeout - jitterFor(cfg.lifeTimeout, cfg.expiryJitter, jitter))
 	}
 
 	id := fmt.Sprintf("%s[-%d]", addr, nextConnectionID())
@@ -72,7 +88,7 @@ func newConnection(ctx context.Context, addr address.Address, opts ...Connection
 	c := &connection{
 		id:                 id,
 		addr:               addr,
-		idleTimeout:        cfg.idleTimeout,
+		idleTimeout:        cfg.idleTimeout - jitterFor(cfg.idleTimeout, cfg.expiryJitter, jitter),
 		lifetimeDeadline:   lifetimeDeadline,
 		readTimeout:        cfg.readTimeout,
 		writeTimeout:       cfg.writeTimeout,
@@ -96,19 +112,33 @@ func (c *connection) connect(ctx context.Context) {
 	ctx, c.cancelConnectContext = context.WithCancel(ctx)
 	close(c.connectContextMade)
 
+	// The standard library dialer reports DNS lookups through the hooks of an httptrace.ClientTrace.
+	var dnsStart, dnsDone time.Time
+	dialCtx := httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:  func(httptrace.DNSDoneInfo) { dnsDone = time.Now() },
+	})
+
 	var err error
-	c.nc, err = c.config.dialer.DialContext(ctx, c.addr.Network(), c.addr.String())
+	start := time.Now()
+	c.nc, err = c.config.dialer.DialContext(dialCtx, c.addr.Network(), c.addr.String())
 	if err != nil {
 		atomic.StoreInt32(&c.connected, disconnected)
 		c.connectErr = ConnectionError{Wrapped: err, init: true}
 		return
 	}
+	c.timings.TCPConnect = time.Since(start)
+	if !dnsStart.IsZero() && dnsDone.After(dnsStart) {
+		c.timings.DNS = dnsDone.Sub(dnsStart)
+		c.timings.TCPConnect -= c.timings.DNS
+	}
 
 	if c.config.tlsConfig != nil {
 		tlsConfig := c.config.tlsConfig.Clone()
 
 		// store the result of configureTLS in a separate variable than c.nc to avoid overwriting c.nc with nil in
 		// error cases.
+		start = time.Now()
 		tlsNc, err := configureTLS(ctx, c.nc, c.addr, tlsConfig)
 		if err != nil {
 			if c.nc != nil {
@@ -119,6 +149,7 @@ func (c *connection) connect(ctx context.Context) {
 			return
 		}
 		c.nc = tlsNc
+		c.timings.TLS = time.Since(start)
 	}
 
 	c.bumpIdleDeadline()
@@ -130,9 +161,13 @@ func (c *connection) connect(ctx context.Context) {
 	}
 
 	handshakeConn := initConnection{c}
+	start = time.Now()
 	c.desc, err = handshaker.GetDescription(ctx, c.addr, handshakeConn)
+	c.timings.Hello = time.Since(start)
 	if err == nil {
+		start = time.Now()
 		err = handshaker.FinishHandshake(ctx, handshakeConn)
+		c.timings.Auth = time.Since(start)
 	}
 	if err != nil {
 		if c.nc != nil {
@@ -299,6 +334,7 @@ func (c *connection) close() error {
 		atomic.StoreInt32(&c.connected, disconnected)
 		return err
 	}
+	c.pool.stats.connectionClosed(event.ReasonConnectionErrored)
 	return c.pool.closeConnection(c)
 }
 
@@ -316,6 +352,18 @@ func (c *connection) expired() bool {
 	return atomic.LoadInt32(&c.connected) == disconnected
 }
 
+// jitterFor returns the amount by which timeout is shortened for a connection that was assigned the given fraction of
+// the maximum jitter. At most half of timeout is removed.
+func jitterFor(timeout, maxJitter time.Duration, fraction float64) time.Duration {
+	if maxJitter > timeout/2 {
+		maxJitter = timeout / 2
+	}
+	if maxJitter <= 0 {
+		return 0
+	}
+	return time.Duration(fraction * float64(maxJitter))
+}
+
 func (c *connection) bumpIdleDeadline() {
 	if c.idleTimeout > 0 {
 		c.idleDeadline.Store(time.Now().Add(c.idleTimeout))
@@ -358,10 +406,16 @@ type Connection struct {
 	s *Server
 
 	mu sync.RWMutex
+
+	// cursorPins and txnPins are the number of cursors and transactions the connection is pinned to. A pinned
+	// connection is not returned to the pool when it is closed.
+	cursorPins int
+	txnPins    int
 }
 
 var _ driver.Connection = (*Connection)(nil)
 var _ driver.Expirable = (*Connection)(nil)
+var _ driver.PinnedConnection = (*Connection)(nil)
 
 // WriteWireMessage handles writing a wire message to the underlying connection.
 func (c *Connection) WriteWireMessage(ctx context.Context, wm []byte) error {
@@ -428,14 +482,15 @@ func (c *Connection) Description() description.Server {
 }
 
 // Close returns this connection to the connection pool. This method may not closeConnection the underlying
-// socket.
+// socket. If the connection is pinned to a cursor or transaction, Close is a no-op.
 func (c *Connection) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.connection == nil {
+	if c.connection == nil || c.cursorPins > 0 || c.txnPins > 0 {
 		return nil
 	}
 	if c.s != nil {
+		defer c.s.releaseOperation()
 		defer c.s.sem.Release(1)
 	}
 	err := c.pool.put(c.connection)
@@ -452,12 +507,55 @@ func (c *Connection) Expire() error {
 	}
 	if c.s != nil {
 		c.s.sem.Release(1)
+		c.s.releaseOperation()
 	}
 	err := c.close()
 	c.connection = nil
 	return err
 }
 
+// PinToCursor pins this connection to a cursor so that it is not returned to the pool when it is closed.
+func (c *Connection) PinToCursor() error {
+	return c.pin(&c.cursorPins)
+}
+
+// PinToTransaction pins this connection to a transaction so that it is not returned to the pool when it is closed.
+func (c *Connection) PinToTransaction() error {
+	return c.pin(&c.txnPins)
+}
+
+// UnpinFromCursor unpins this connection from a cursor. The connection is returned to the pool by the next call to
+// Close once it is no longer pinned.
+func (c *Connection) UnpinFromCursor() error {
+	return c.unpin(&c.cursorPins, "cursor")
+}
+
+// UnpinFromTransaction unpins this connection from a transaction. The connection is returned to the pool by the next
+// call to Close once it is no longer pinned.
+func (c *Connection) UnpinFromTransaction() error {
+	return c.unpin(&c.txnPins, "transaction")
+}
+
+func (c *Connection) pin(pins *int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.connection == nil {
+		return ErrConnectionClosed
+	}
+	*pins++
+	return nil
+}
+
+func (c *Connection) unpin(pins *int, owner string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if *pins == 0 {
+		return fmt.Errorf("connection is not pinned to a %s", owner)
+	}
+	*pins--
+	return nil
+}
+
 // Alive returns if the connection is still alive.
 func (c *Connection) Alive() bool {
 	return c.connection != nil
@@ -3,12 +3,15 @@ package topology
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/x/mongo/driver"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/dns"
 )
 
 // Dialer is used to make network connections.
@@ -28,7 +31,172 @@ func (df DialerFunc) DialContext(ctx context.Context, network, address string) (
 // will also change the Dialer used for this package. This should only be changed why all
 // of the connections being made need to use a different Dialer. Most of the time, using a
 // WithDialer option is more appropriate than changing this variable.
-var DefaultDialer Dialer = &net.Dialer{}
+var DefaultDialer Dialer = newDefaultDialer(0)
+
+// fallbackDelay is how long a dial waits for a connection attempt to an address before starting an attempt to the
+// next address of the host in parallel. This is the Connection Attempt Delay recommended by RFC 8305.
+const fallbackDelay = 250 * time.Millisecond
+
+// newDefaultDialer returns a net.Dialer that races IPv6 and IPv4 connection attempts for dual-stack hosts after the
+// fallback delay recommended by RFC 8305, so a broken IPv6 route does not stall connection establishment.
+func newDefaultDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout:       timeout,
+		FallbackDelay: fallbackDelay,
+	}
+}
+
+// socketOptionsDialer is a Dialer that sets socket options that net.Dialer does not support on the connections it
+// dials.
+type socketOptionsDialer struct {
+	*net.Dialer
+	keepAlive      time.Duration
+	keepAliveCount int
+	userTimeout    time.Duration
+}
+
+// DialContext implements the Dialer interface.
+func (d *socketOptionsDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	nc, err := d.Dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	if err = setSocketOptions(nc, d.keepAlive, d.keepAliveCount, d.userTimeout); err != nil {
+		_ = nc.Close()
+		return nil, err
+	}
+	return nc, nil
+}
+
+// resolvingDialer is a Dialer that looks up the addresses of a host using a dns.HostResolver and dials them using
+// the forward Dialer. Like the Happy Eyeballs algorithm of RFC 8305, the addresses are interleaved by address family
+// and a connection attempt to the next address is started if the previous attempt fails or has not succeeded after
+// the fallback delay. The first connection that is established is returned and the other attempts are canceled, so a
+// host whose IPv6 or IPv4 addresses are unreachable does not stall connection establishment.
+type resolvingDialer struct {
+	forward  Dialer
+	resolver dns.HostResolver
+	delay    time.Duration // the fallback delay, fallbackDelay if 0
+}
+
+// DialContext implements the Dialer interface.
+func (d *resolvingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if strings.HasPrefix(network, "unix") {
+		return d.forward.DialContext(ctx, network, address)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		// let the forward Dialer handle addresses that are not host names
+		return d.forward.DialContext(ctx, network, address)
+	}
+
+	addrs, err := d.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for host %s", host)
+	}
+	addrs = interleaveAddressFamilies(addrs)
+	for i, addr := range addrs {
+		addrs[i] = net.JoinHostPort(addr, port)
+	}
+	return d.dialParallel(ctx, network, addrs)
+}
+
+type dialResult struct {
+	nc  net.Conn
+	err error
+}
+
+// dialParallel dials addrs in order, starting the next attempt when the previous one fails or after the fallback
+// delay, and returns the first connection that is established. If all attempts fail, the error of the first attempt
+// is returned.
+func (d *resolvingDialer) dialParallel(ctx context.Context, network string, addrs []string) (net.Conn, error) {
+	delay := d.delay
+	if delay <= 0 {
+		delay = fallbackDelay
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the channel is buffered so that attempts that finish after the winner do not block
+	results := make(chan dialResult, len(addrs))
+	var next, pending int
+	var fallback <-chan time.Time
+	start := func() {
+		addr := addrs[next]
+		next++
+		pending++
+		go func() {
+			nc, err := d.forward.DialContext(ctx, network, addr)
+			results <- dialResult{nc: nc, err: err}
+		}()
+		fallback = nil
+		if next < len(addrs) {
+			fallback = time.After(delay)
+		}
+	}
+
+	start()
+	var firstErr error
+	for pending > 0 {
+		select {
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				cancel()
+				go closeLateConnections(results, pending)
+				return res.nc, nil
+			}
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			if next < len(addrs) && ctx.Err() == nil {
+				start()
+			}
+		case <-fallback:
+			start()
+		}
+	}
+	return nil, firstErr
+}
+
+// closeLateConnections closes the connections of the n attempts that were still running when another attempt
+// succeeded.
+func closeLateConnections(results <-chan dialResult, n int) {
+	for i := 0; i < n; i++ {
+		if res := <-results; res.nc != nil {
+			_ = res.nc.Close()
+		}
+	}
+}
+
+// interleaveAddressFamilies reorders addrs so that IPv6 and IPv4 addresses alternate, starting with the family of the
+// first address, as recommended by RFC 8305. The order within each family is kept.
+func interleaveAddressFamilies(addrs []string) []string {
+	var first, second []string
+	firstIsV4 := net.ParseIP(addrs[0]).To4() != nil
+	for _, addr := range addrs {
+		if (net.ParseIP(addr).To4() != nil) == firstIsV4 {
+			first = append(first, addr)
+		} else {
+			second = append(second, addr)
+		}
+	}
+
+	interleaved := make([]string, 0, len(addrs))
+	for i := 0; i < len(first) || i < len(second); i++ {
+		if i < len(first) {
+			interleaved = append(interleaved, first[i])
+		}
+		if i < len(second) {
+			interleaved = append(interleaved, second[i])
+		}
+	}
+	return interleaved
+}
 
 // Handshaker is the interface implemented by types that can perform a MongoDB
 // handshake over a provided driver.Connection. This is used during connection
@@ -41,11 +209,17 @@ type connectionConfig struct {
 	dialer         Dialer
 	handshaker     Handshaker
 	idleTimeout    time.Duration
+	keepAlive      time.Duration
+	keepAliveCount int
+	userTimeout    time.Duration
 	lifeTimeout    time.Duration
+	expiryJitter   time.Duration
 	cmdMonitor     *event.CommandMonitor
 	readTimeout    time.Duration
 	writeTimeout   time.Duration
 	tlsConfig      *tls.Config
+	proxy          *ProxyConfig
+	resolver       dns.HostResolver
 	compressors    []string
 	zlibLevel      *int
 	zstdLevel      *int
@@ -68,7 +242,23 @@ func newConnectionConfig(opts ...ConnectionOption) (*connectionConfig, error) {
 	}
 
 	if cfg.dialer == nil {
-		cfg.dialer = &net.Dialer{Timeout: cfg.connectTimeout}
+		d := newDefaultDialer(cfg.connectTimeout)
+		d.KeepAlive = cfg.keepAlive
+		cfg.dialer = d
+		if cfg.keepAlive > 0 || cfg.keepAliveCount > 0 || cfg.userTimeout > 0 {
+			cfg.dialer = &socketOptionsDialer{
+				Dialer:         d,
+				keepAlive:      cfg.keepAlive,
+				keepAliveCount: cfg.keepAliveCount,
+				userTimeout:    cfg.userTimeout,
+			}
+		}
+	}
+	if cfg.resolver != nil {
+		cfg.dialer = &resolvingDialer{forward: cfg.dialer, resolver: cfg.resolver}
+	}
+	if cfg.proxy != nil {
+		cfg.dialer = &socks5Dialer{forward: cfg.dialer, proxy: *cfg.proxy}
 	}
 
 	return cfg, nil
@@ -110,7 +300,8 @@ func WithConnectTimeout(fn func(time.Duration) time.Duration) ConnectionOption {
 	}
 }
 
-// WithDialer configures the Dialer to use when making a new connection to MongoDB.
+// WithDialer configures the Dialer to use when making a new connection to MongoDB. The Dialer is called with the
+// address of the server as it is configured, unless a resolver is configured with WithHostResolver.
 func WithDialer(fn func(Dialer) Dialer) ConnectionOption {
 	return func(c *connectionConfig) error {
 		c.dialer = fn(c.dialer)
@@ -143,6 +334,17 @@ func WithLifeTimeout(fn func(time.Duration) time.Duration) ConnectionOption {
 	}
 }
 
+// WithExpiryJitter configures the maximum amount of time by which the idle timeout and lifetime of each connection are
+// shortened. Each connection is assigned a random fraction of the jitter when it is created, so that connections that
+// are created together do not expire and get replaced together. The jitter applied to a timeout is at most half of
+// that timeout. If the jitter is 0, connections expire exactly after the configured idle timeout and lifetime.
+func WithExpiryJitter(fn func(time.Duration) time.Duration) ConnectionOption {
+	return func(c *connectionConfig) error {
+		c.expiryJitter = fn(c.expiryJitter)
+		return nil
+	}
+}
+
 // WithReadTimeout configures the maximum read time for a connection.
 func WithReadTimeout(fn func(time.Duration) time.Duration) ConnectionOption {
 	return func(c *connectionConfig) error {
@@ -167,6 +369,55 @@ func WithTLSConfig(fn func(*tls.Config) *tls.Config) ConnectionOption {
 	}
 }
 
+// WithHostResolver configures a resolver used to look up the addresses of a host before dialing it. The Dialer is then
+// called with IP addresses, one for each connection attempt. Attempts to the addresses of a host are raced as described
+// in RFC 8305, so the Dialer may be called concurrently and the context of attempts that lose the race is canceled. If
+// no resolver is configured, host names are resolved by the Dialer.
+func WithHostResolver(fn func(dns.HostResolver) dns.HostResolver) ConnectionOption {
+	return func(c *connectionConfig) error {
+		c.resolver = fn(c.resolver)
+		return nil
+	}
+}
+
+// WithKeepAlive configures the keepalive period of connections made with the default Dialer. On Linux, this is both
+// how long a connection is idle before the first keepalive probe and the interval between probes. If zero, the system
+// default is used. If negative, keepalive probes are disabled.
+func WithKeepAlive(fn func(time.Duration) time.Duration) ConnectionOption {
+	return func(c *connectionConfig) error {
+		c.keepAlive = fn(c.keepAlive)
+		return nil
+	}
+}
+
+// WithKeepAliveCount configures the number of unanswered keepalive probes after which a connection made with the
+// default Dialer is considered dead. This is only supported on Linux. If zero, the system default is used.
+func WithKeepAliveCount(fn func(int) int) ConnectionOption {
+	return func(c *connectionConfig) error {
+		c.keepAliveCount = fn(c.keepAliveCount)
+		return nil
+	}
+}
+
+// WithTCPUserTimeout configures the TCP_USER_TIMEOUT socket option of connections made with the default Dialer: the
+// maximum amount of time that transmitted data may remain unacknowledged before the connection is closed. This is
+// only supported on Linux. If zero, the system default is used.
+func WithTCPUserTimeout(fn func(time.Duration) time.Duration) ConnectionOption {
+	return func(c *connectionConfig) error {
+		c.userTimeout = fn(c.userTimeout)
+		return nil
+	}
+}
+
+// WithProxy configures a SOCKS5 proxy that connections are dialed through. The proxy is dialed using the configured
+// Dialer.
+func WithProxy(fn func(*ProxyConfig) *ProxyConfig) ConnectionOption {
+	return func(c *connectionConfig) error {
+		c.proxy = fn(c.proxy)
+		return nil
+	}
+}
+
 // WithMonitor configures a event for command monitoring.
 func WithMonitor(fn func(*event.CommandMonitor) *event.CommandMonitor) ConnectionOption {
 	return func(c *connectionConfig) error {
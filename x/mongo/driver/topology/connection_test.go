@@ -18,6 +18,7 @@ import (
 	"go.mongodb.org/mongo-driver/x/mongo/driver"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/dns"
 )
 
 type netErr struct {
@@ -35,6 +36,20 @@ func (n netErr) Temporary() bool {
 	return false
 }
 
+type fakeHostResolver map[string][]string
+
+func (r fakeHostResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	return r[host], nil
+}
+
+func (fakeHostResolver) LookupSRV(context.Context, string, string, string) (string, []*net.SRV, error) {
+	return "", nil, nil
+}
+
+func (fakeHostResolver) LookupTXT(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+
 func TestConnection(t *testing.T) {
 	t.Run("connection", func(t *testing.T) {
 		t.Run("newConnection", func(t *testing.T) {
@@ -45,6 +60,120 @@ func TestConnection(t *testing.T) {
 					t.Errorf("errors do not match. got %v; want %v", got, want)
 				}
 			})
+			t.Run("expiry jitter", func(t *testing.T) {
+				for i := 0; i < 20; i++ {
+					start := time.Now()
+					conn, err := newConnection(context.Background(), address.Address(""),
+						WithIdleTimeout(func(time.Duration) time.Duration { return 10 * time.Minute }),
+						WithLifeTimeout(func(time.Duration) time.Duration { return 4 * time.Minute }),
+						WithExpiryJitter(func(time.Duration) time.Duration { return 4 * time.Minute }),
+					)
+					noerr(t, err)
+					if conn.idleTimeout < 6*time.Minute || conn.idleTimeout > 10*time.Minute {
+						t.Errorf("expected idle timeout between 6m and 10m, got %v", conn.idleTimeout)
+					}
+					// The jitter is capped at half of the 4 minute lifetime.
+					lifetime := conn.lifetimeDeadline.Sub(start)
+					if lifetime < 2*time.Minute || lifetime > 4*time.Minute+time.Second {
+						t.Errorf("expected lifetime between 2m and 4m, got %v", lifetime)
+					}
+				}
+			})
+			t.Run("default dialer races address families", func(t *testing.T) {
+				conn, err := newConnection(context.Background(), address.Address(""),
+					WithConnectTimeout(func(time.Duration) time.Duration { return 5 * time.Second }),
+				)
+				noerr(t, err)
+				d, ok := conn.config.dialer.(*net.Dialer)
+				if !ok {
+					t.Fatalf("expected default dialer to be a *net.Dialer, got %T", conn.config.dialer)
+				}
+				if d.FallbackDelay != fallbackDelay {
+					t.Errorf("expected a %v fallback delay, got %v", fallbackDelay, d.FallbackDelay)
+				}
+				if d.Timeout != 5*time.Second {
+					t.Errorf("expected dial timeout to match connect timeout. got %v; want %v", d.Timeout, 5*time.Second)
+				}
+			})
+			t.Run("custom dialer is called with the host name", func(t *testing.T) {
+				var dialed string
+				forward := DialerFunc(func(_ context.Context, _, address string) (net.Conn, error) {
+					dialed = address
+					return &net.TCPConn{}, nil
+				})
+				conn, err := newConnection(context.Background(), address.Address("localhost:27017"),
+					WithDialer(func(Dialer) Dialer { return forward }),
+				)
+				noerr(t, err)
+				_, err = conn.config.dialer.DialContext(context.Background(), "tcp", "localhost:27017")
+				noerr(t, err)
+				if dialed != "localhost:27017" {
+					t.Errorf("expected the dialer to be called with the host name, got %v", dialed)
+				}
+			})
+			t.Run("fallback to the next address family", func(t *testing.T) {
+				// the IPv6 address is black-holed, so its attempt only ends when it is canceled
+				canceled := make(chan struct{})
+				forward := DialerFunc(func(ctx context.Context, _, address string) (net.Conn, error) {
+					if address == "[2001:db8::1]:27017" {
+						<-ctx.Done()
+						close(canceled)
+						return nil, ctx.Err()
+					}
+					return &net.TCPConn{}, nil
+				})
+				d := &resolvingDialer{
+					forward:  forward,
+					resolver: fakeHostResolver{"db.example.com": {"2001:db8::1", "2001:db8::2", "10.0.0.1"}},
+					delay:    10 * time.Millisecond,
+				}
+				start := time.Now()
+				_, err := d.DialContext(context.Background(), "tcp", "db.example.com:27017")
+				noerr(t, err)
+				if elapsed := time.Since(start); elapsed < 10*time.Millisecond || elapsed > 5*time.Second {
+					t.Errorf("expected the IPv4 attempt to start after the fallback delay, took %v", elapsed)
+				}
+				select {
+				case <-canceled:
+				case <-time.After(5 * time.Second):
+					t.Errorf("expected the losing attempt to be canceled")
+				}
+			})
+			t.Run("interleave address families", func(t *testing.T) {
+				got := interleaveAddressFamilies([]string{"2001:db8::1", "2001:db8::2", "10.0.0.1", "10.0.0.2", "10.0.0.3"})
+				want := []string{"2001:db8::1", "10.0.0.1", "2001:db8::2", "10.0.0.2", "10.0.0.3"}
+				if !cmp.Equal(got, want) {
+					t.Errorf("addresses were not interleaved. got %v; want %v", got, want)
+				}
+			})
+			t.Run("host resolver", func(t *testing.T) {
+				var dialed []string
+				forward := DialerFunc(func(_ context.Context, _, address string) (net.Conn, error) {
+					dialed = append(dialed, address)
+					if address == "10.0.0.1:27017" {
+						return nil, errors.New("unreachable")
+					}
+					return &net.TCPConn{}, nil
+				})
+				conn, err := newConnection(context.Background(), address.Address("db.example.com:27017"),
+					WithDialer(func(Dialer) Dialer { return forward }),
+					WithHostResolver(func(dns.HostResolver) dns.HostResolver {
+						return fakeHostResolver{"db.example.com": {"10.0.0.1", "10.0.0.2"}}
+					}),
+				)
+				noerr(t, err)
+				_, err = conn.config.dialer.DialContext(context.Background(), "tcp", "db.example.com:27017")
+				noerr(t, err)
+				want := []string{"10.0.0.1:27017", "10.0.0.2:27017"}
+				if !cmp.Equal(dialed, want) {
+					t.Errorf("resolved addresses were not dialed in order. got %v; want %v", dialed, want)
+				}
+
+				_, err = conn.config.dialer.DialContext(context.Background(), "tcp", "unknown.example.com:27017")
+				if err == nil {
+					t.Errorf("expected error dialing a host with no addresses, got nil")
+				}
+			})
 		})
 		t.Run("connect", func(t *testing.T) {
 			t.Run("dialer error", func(t *testing.T) {
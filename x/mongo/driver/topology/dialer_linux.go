@@ -0,0 +1,58 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build linux
+// +build linux
+
+package topology
+
+import (
+	"net"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// setSocketOptions sets the TCP keepalive and TCP_USER_TIMEOUT socket options on nc. keepAlive is used for both the
+// TCP_KEEPIDLE and TCP_KEEPINTVL options, as the Go runtime does not set the latter consistently across versions.
+// Options with a zero or negative value are left unchanged.
+func setSocketOptions(nc net.Conn, keepAlive time.Duration, keepAliveCount int, userTimeout time.Duration) error {
+	tc, ok := nc.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+	rc, err := tc.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var serr error
+	err = rc.Control(func(fd uintptr) {
+		if keepAlive > 0 {
+			secs := int((keepAlive + time.Second - 1) / time.Second)
+			if serr = unix.SetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_KEEPIDLE, secs); serr != nil {
+				return
+			}
+			if serr = unix.SetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_KEEPINTVL, secs); serr != nil {
+				return
+			}
+		}
+		if keepAliveCount > 0 {
+			serr = unix.SetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_KEEPCNT, keepAliveCount)
+			if serr != nil {
+				return
+			}
+		}
+		if userTimeout > 0 {
+			ms := int(userTimeout / time.Millisecond)
+			serr = unix.SetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_USER_TIMEOUT, ms)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return serr
+}
@@ -0,0 +1,57 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build linux
+// +build linux
+
+package topology
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"golang.org/x/sys/unix"
+)
+
+func TestSetSocketOptions(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	assert.Nil(t, err, "Listen error: %v", err)
+	defer func() { _ = l.Close() }()
+
+	cfg, err := newConnectionConfig(
+		WithKeepAlive(func(time.Duration) time.Duration { return 5 * time.Second }),
+		WithKeepAliveCount(func(int) int { return 3 }),
+		WithTCPUserTimeout(func(time.Duration) time.Duration { return 20 * time.Second }),
+	)
+	assert.Nil(t, err, "newConnectionConfig error: %v", err)
+
+	nc, err := cfg.dialer.DialContext(context.Background(), "tcp", l.Addr().String())
+	assert.Nil(t, err, "DialContext error: %v", err)
+	defer func() { _ = nc.Close() }()
+
+	rc, err := nc.(*net.TCPConn).SyscallConn()
+	assert.Nil(t, err, "SyscallConn error: %v", err)
+
+	var count, userTimeout, interval int
+	var gerr error
+	err = rc.Control(func(fd uintptr) {
+		if count, gerr = unix.GetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_KEEPCNT); gerr != nil {
+			return
+		}
+		if interval, gerr = unix.GetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_KEEPINTVL); gerr != nil {
+			return
+		}
+		userTimeout, gerr = unix.GetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_USER_TIMEOUT)
+	})
+	assert.Nil(t, err, "Control error: %v", err)
+	assert.Nil(t, gerr, "GetsockoptInt error: %v", gerr)
+	assert.Equal(t, 3, count, "expected TCP_KEEPCNT %v, got %v", 3, count)
+	assert.Equal(t, 5, interval, "expected TCP_KEEPINTVL %v, got %v", 5, interval)
+	assert.Equal(t, 20000, userTimeout, "expected TCP_USER_TIMEOUT %v, got %v", 20000, userTimeout)
+}
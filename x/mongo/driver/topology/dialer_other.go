@@ -0,0 +1,21 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build !linux
+// +build !linux
+
+package topology
+
+import (
+	"net"
+	"time"
+)
+
+// setSocketOptions is a no-op on platforms other than Linux. The keepalive period is set by net.Dialer, and the
+// TCP_KEEPCNT and TCP_USER_TIMEOUT socket options are not supported.
+func setSocketOptions(net.Conn, time.Duration, int, time.Duration) error {
+	return nil
+}
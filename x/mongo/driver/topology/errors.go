@@ -1,6 +1,11 @@
 package topology
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
+)
 
 // ConnectionError represents a connection error.
 type ConnectionError struct {
@@ -20,3 +25,17 @@ func (e ConnectionError) Error() string {
 	}
 	return fmt.Sprintf("connection(%s) %s", e.ConnectionID, e.message)
 }
+
+// ServerOverloadedError is returned when an operation could not start because the server already has the maximum
+// number of operations in progress and no slot became available within the operation queue timeout.
+type ServerOverloadedError struct {
+	Address       address.Address
+	MaxOperations uint64
+	Wait          time.Duration
+}
+
+// Error implements the error interface.
+func (e ServerOverloadedError) Error() string {
+	return fmt.Sprintf("server %s is overloaded: %d operations in progress, waited %v for one to finish",
+		e.Address, e.MaxOperations, e.Wait)
+}
@@ -0,0 +1,118 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+)
+
+// Policies for choosing among suitable mongos servers.
+const (
+	// MongosSelectionRandom chooses a mongos uniformly at random, or using weighted selection if it is enabled.
+	MongosSelectionRandom = "random"
+	// MongosSelectionRoundRobin cycles through the suitable mongos servers in address order.
+	MongosSelectionRoundRobin = "roundRobin"
+	// MongosSelectionLeastOperations chooses the mongos with the fewest operations in progress.
+	MongosSelectionLeastOperations = "leastOperations"
+)
+
+// pickMongos chooses one of the suitable mongos servers according to the configured mongos selection policy. It
+// returns false if the policy does not apply, in which case the default selection is used.
+func (t *Topology) pickMongos(suitable []description.Server) (description.Server, bool) {
+	if len(suitable) < 2 || suitable[0].Kind != description.Mongos {
+		return description.Server{}, false
+	}
+
+	switch t.cfg.mongosSelection {
+	case MongosSelectionRoundRobin:
+		sorted := make([]description.Server, len(suitable))
+		copy(sorted, suitable)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].Addr < sorted[j].Addr })
+		next := atomic.AddUint64(&t.mongosNext, 1) - 1
+		return sorted[next%uint64(len(sorted))], true
+	case MongosSelectionLeastOperations:
+		best, bestOps := suitable[0], t.operationCount(suitable[0].Addr)
+		for _, s := range suitable[1:] {
+			ops := t.operationCount(s.Addr)
+			if ops < bestOps || (ops == bestOps && s.AverageRTT < best.AverageRTT) {
+				best, bestOps = s, ops
+			}
+		}
+		return best, true
+	default:
+		return description.Server{}, false
+	}
+}
+
+// rebalanceMongos periodically rebalances the connections to the mongos servers of a sharded deployment until the
+// topology is disconnected.
+func (t *Topology) rebalanceMongos() {
+	defer t.rebalancewg.Done()
+
+	ticker := time.NewTicker(t.cfg.mongosRebalance)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-t.rebalanceDone:
+			return
+		}
+		if t.Description().Kind != description.Sharded {
+			continue
+		}
+
+		var mongoses []*Server
+		t.serversLock.Lock()
+		for _, server := range t.servers {
+			if server.Description().Kind == description.Mongos {
+				mongoses = append(mongoses, server)
+			}
+		}
+		t.serversLock.Unlock()
+
+		rebalanceConnections(mongoses)
+	}
+}
+
+// rebalanceConnections closes idle connections to servers that hold more than an even share of the open connections
+// to all of the given servers. It does not close idle connections that are needed to keep the minimum pool size.
+func rebalanceConnections(servers []*Server) {
+	if len(servers) < 2 {
+		return
+	}
+
+	stats := make([]event.PoolStats, len(servers))
+	var total uint64
+	for i, server := range servers {
+		stats[i] = server.PoolStats()
+		total += stats[i].InUse + stats[i].Idle
+	}
+	share := (total + uint64(len(servers)) - 1) / uint64(len(servers))
+
+	for i, server := range servers {
+		open := stats[i].InUse + stats[i].Idle
+		if open <= share {
+			continue
+		}
+		// Connections below the minimum pool size would be re-established by the maintenance of the pool, so they are
+		// never closed.
+		minSize := server.pool.conns.minSize
+		if stats[i].Idle <= minSize {
+			continue
+		}
+		excess := open - share
+		if excess > stats[i].Idle-minSize {
+			excess = stats[i].Idle - minSize
+		}
+		server.pool.shrink(int(excess))
+	}
+}
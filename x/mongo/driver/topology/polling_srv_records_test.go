@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
@@ -269,3 +270,37 @@ func TestPollSRVRecords(t *testing.T) {
 		_ = topo.Disconnect(context.Background())
 	})
 }
+
+func TestSRVPollingIntervalValidation(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		_, err := New(WithSRVPollingInterval(func(time.Duration) time.Duration { return interval }))
+		require.Error(t, err, "expected error for SRV polling interval %v", interval)
+	}
+}
+
+func TestSRVHostsChangedEvent(t *testing.T) {
+	var events []*event.SRVHostsChangedEvent
+	monitor := &event.TopologyMonitor{
+		SRVHostsChanged: func(evt *event.SRVHostsChangedEvent) {
+			events = append(events, evt)
+		},
+	}
+	topo, err := New(
+		WithSeedList(func(...string) []string { return []string{"a.example.com:27017", "b.example.com:27017"} }),
+		WithSRVPollingInterval(func(time.Duration) time.Duration { return time.Second }),
+		WithTopologyMonitor(func(*event.TopologyMonitor) *event.TopologyMonitor { return monitor }),
+	)
+	require.NoError(t, err, "Could not create the topology: %v", err)
+	require.Equal(t, time.Second, topo.rescanSRVInterval, "expected polling interval to be configured")
+	err = topo.Connect()
+	require.NoError(t, err, "Could not Connect to the topology: %v", err)
+	defer func() { _ = topo.Disconnect(context.Background()) }()
+
+	require.True(t, topo.processSRVResults([]string{"a.example.com:27017", "b.example.com:27017"}))
+	require.Len(t, events, 0, "expected no event when the hosts are unchanged")
+
+	require.True(t, topo.processSRVResults([]string{"a.example.com:27017", "c.example.com:27017"}))
+	require.Len(t, events, 1, "expected an event when the hosts change")
+	require.Equal(t, []string{"c.example.com:27017"}, events[0].Added)
+	require.Equal(t, []string{"b.example.com:27017"}, events[0].Removed)
+}
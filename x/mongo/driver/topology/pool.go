@@ -12,6 +12,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
 )
@@ -38,15 +39,27 @@ type PoolError string
 // maintainInterval is the interval at which the background routine to close stale connections will be run.
 var maintainInterval = time.Minute
 
+// defaultMaxConnecting is the default maximum number of connections that a pool will establish at the same time.
+const defaultMaxConnecting = 2
+
 func (pe PoolError) Error() string { return string(pe) }
 
 // poolConfig contains all aspects of the pool that can be configured
 type poolConfig struct {
-	Address     address.Address
-	MinPoolSize uint64
-	MaxPoolSize uint64 // MaxPoolSize is not used because handling the max number of connections in the pool is handled in server. This is only used for command monitoring
-	MaxIdleTime time.Duration
-	PoolMonitor *event.PoolMonitor
+	Address       address.Address
+	MinPoolSize   uint64
+	MaxPoolSize   uint64 // MaxPoolSize is not used because handling the max number of connections in the pool is handled in server. This is only used for command monitoring
+	MaxConnecting uint64
+	MaxIdleTime   time.Duration
+	PoolMonitor   *event.PoolMonitor
+
+	// ClearBackoff is the initial delay before new connections are established after the pool is cleared. It doubles
+	// with each consecutive clear up to MaxClearBackoff. A ClearBackoff of 0 disables the delay.
+	ClearBackoff    time.Duration
+	MaxClearBackoff time.Duration
+
+	// MaxConnectionRate is the maximum number of new connections established per second. 0 means no limit.
+	MaxConnectionRate uint64
 }
 
 // checkOutResult is all the values that can be returned from a checkOut
@@ -58,15 +71,21 @@ type checkOutResult struct {
 
 // pool is a wrapper of resource pool that follows the CMAP spec for connection pools
 type pool struct {
+	stats      poolStats // first field so that its 64-bit counters are aligned for atomic access
 	address    address.Address
 	opts       []ConnectionOption
 	conns      *resourcePool // pool for non-checked out connections
 	generation uint64        // must be accessed using atomic package
 	monitor    *event.PoolMonitor
+	connecting chan struct{} // limits the number of connections being established at the same time
+	throttle   *connectThrottle
 
 	connected int32 // Must be accessed using the sync/atomic package.
 	nextid    uint64
 	opened    map[uint64]*connection // opened holds all of the currently open connections.
+
+	// serviceGenerations holds the generation of each backend server behind a load balancer, keyed by serviceId.
+	serviceGenerations map[primitive.ObjectID]uint64
 	sync.Mutex
 }
 
@@ -97,7 +116,10 @@ func connectionExpiredFunc(v interface{}) bool {
 	}
 
 	res := disconnected || stale || idle
-	if res && c.pool.monitor != nil {
+	if res {
+		c.pool.stats.connectionClosed(reason)
+	}
+	if res && c.pool.monitored(event.ConnectionClosed) {
 		c.pool.monitor.Event(&event.PoolEvent{
 			Type:         event.ConnectionClosed,
 			Address:      c.pool.address.String(),
@@ -128,7 +150,9 @@ func (p *pool) connectionInitFunc() interface{} {
 		return nil
 	}
 
-	go c.connect(context.Background())
+	go func() {
+		_ = c.pool.establish(context.Background(), c)
+	}()
 
 	return c
 }
@@ -141,12 +165,21 @@ func newPool(config poolConfig, connOpts ...ConnectionOption) (*pool, error) {
 		opts = append(opts, WithIdleTimeout(func(_ time.Duration) time.Duration { return config.MaxIdleTime }))
 	}
 
+	maxConnecting := config.MaxConnecting
+	if maxConnecting == 0 {
+		maxConnecting = defaultMaxConnecting
+	}
+
 	pool := &pool{
-		address:   config.Address,
-		monitor:   config.PoolMonitor,
-		connected: disconnected,
-		opened:    make(map[uint64]*connection),
-		opts:      opts,
+		address:    config.Address,
+		monitor:    config.PoolMonitor,
+		connecting: make(chan struct{}, maxConnecting),
+		throttle:   newConnectThrottle(config.ClearBackoff, config.MaxClearBackoff, config.MaxConnectionRate),
+		connected:  disconnected,
+		opened:     make(map[uint64]*connection),
+		opts:       opts,
+
+		serviceGenerations: make(map[primitive.ObjectID]uint64),
 	}
 
 	// we do not pass in config.MaxPoolSize because we manage the max size at this level rather than the resource pool level
@@ -158,7 +191,7 @@ func newPool(config poolConfig, connOpts ...ConnectionOption) (*pool, error) {
 		InitFn:           pool.connectionInitFunc,
 	}
 
-	if pool.monitor != nil {
+	if pool.monitored(event.PoolCreated) {
 		pool.monitor.Event(&event.PoolEvent{
 			Type: event.PoolCreated,
 			PoolOptions: &event.MonitorPoolOptions{
@@ -182,9 +215,44 @@ func newPool(config poolConfig, connOpts ...ConnectionOption) (*pool, error) {
 // drain drains the pool by increasing the generation ID.
 func (p *pool) drain() { atomic.AddUint64(&p.generation, 1) }
 
-// stale checks if a given connection's generation is below the generation of the pool
+// openedBefore returns the number of open connections whose generation is older than generation.
+func (p *pool) openedBefore(generation uint64) int {
+	p.Lock()
+	defer p.Unlock()
+
+	var n int
+	for _, c := range p.opened {
+		if c.generation < generation {
+			n++
+		}
+	}
+	return n
+}
+
+// stale checks if a given connection's generation is below the generation of the pool or, for a connection to a
+// server behind a load balancer, below the generation of the backend server it is connected to.
 func (p *pool) stale(c *connection) bool {
-	return c == nil || c.generation < atomic.LoadUint64(&p.generation)
+	if c == nil || c.generation < atomic.LoadUint64(&p.generation) {
+		return true
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	return c.serviceID != nil && c.serviceGeneration < p.serviceGenerations[*c.serviceID]
+}
+
+// setServiceGeneration records the serviceId returned in c's handshake along with the current generation for that
+// serviceId. It must be called after c has finished connecting.
+func (p *pool) setServiceGeneration(c *connection) {
+	id := c.desc.ServiceID
+	if id == nil {
+		return
+	}
+
+	p.Lock()
+	c.serviceID = id
+	c.serviceGeneration = p.serviceGenerations[*id]
+	p.Unlock()
 }
 
 // connect puts the pool into the connected state, allowing it to be used and will allow items to begin being processed from the wait queue
@@ -244,7 +312,8 @@ func (p *pool) disconnect(ctx context.Context) error {
 	}
 	p.Unlock()
 	for _, pc := range toClose {
-		if p.monitor != nil {
+		p.stats.connectionClosed(event.ReasonPoolClosed)
+		if p.monitored(event.ConnectionClosed) {
 			p.monitor.Event(&event.PoolEvent{
 				Type:         event.ConnectionClosed,
 				Address:      p.address.String(),
@@ -256,7 +325,7 @@ func (p *pool) disconnect(ctx context.Context) error {
 	}
 	atomic.StoreInt32(&p.connected, disconnected)
 
-	if p.monitor != nil {
+	if p.monitored(event.PoolClosedEvent) {
 		p.monitor.Event(&event.PoolEvent{
 			Type:    event.PoolClosedEvent,
 			Address: p.address.String(),
@@ -276,8 +345,9 @@ func (p *pool) makeNewConnection(ctx context.Context) (*connection, string, erro
 	c.pool = p
 	c.poolID = atomic.AddUint64(&p.nextid, 1)
 	c.generation = atomic.LoadUint64(&p.generation)
+	atomic.AddUint64(&p.stats.created, 1)
 
-	if p.monitor != nil {
+	if p.monitored(event.ConnectionCreated) {
 		p.monitor.Event(&event.PoolEvent{
 			Type:         event.ConnectionCreated,
 			Address:      p.address.String(),
@@ -286,7 +356,8 @@ func (p *pool) makeNewConnection(ctx context.Context) (*connection, string, erro
 	}
 
 	if atomic.LoadInt32(&p.connected) != connected {
-		if p.monitor != nil {
+		p.stats.connectionClosed(event.ReasonPoolClosed)
+		if p.monitored(event.ConnectionClosed) {
 			p.monitor.Event(&event.PoolEvent{
 				Type:         event.ConnectionClosed,
 				Address:      p.address.String(),
@@ -306,102 +377,201 @@ func (p *pool) makeNewConnection(ctx context.Context) (*connection, string, erro
 
 }
 
+// establish connects c and waits for the connection handshake to finish. If the maximum number of connections are
+// already being established, establish waits until one of them finishes or ctx expires. If c has already been
+// connected, establish only waits for it to finish connecting.
+func (p *pool) establish(ctx context.Context, c *connection) error {
+	if atomic.LoadInt32(&c.connected) != initialized {
+		return c.wait()
+	}
+
+	select {
+	case p.connecting <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-p.connecting }()
+
+	if err := p.throttle.wait(ctx); err != nil {
+		return err
+	}
+
+	atomic.AddUint64(&p.stats.pending, 1)
+	defer atomicSubtract1Uint64(&p.stats.pending)
+
+	start := time.Now()
+	c.connect(ctx)
+	if err := c.wait(); err != nil {
+		return err
+	}
+	p.throttle.succeeded()
+	p.setServiceGeneration(c)
+
+	if p.monitored(event.ConnectionReady) {
+		timings := c.timings
+		p.monitor.Event(&event.PoolEvent{
+			Type:         event.ConnectionReady,
+			Address:      p.address.String(),
+			ConnectionID: c.poolID,
+			Duration:     time.Since(start),
+			Timings:      &timings,
+		})
+	}
+	return nil
+}
+
+// checkOutTiming holds the timings of a connection check out.
+type checkOutTiming struct {
+	start       time.Time
+	queued      time.Duration // time spent in the wait queue
+	establish   time.Duration // time spent establishing a new connection
+	established bool          // whether the check out established a new connection
+}
+
 // Checkout returns a connection from the pool
 func (p *pool) get(ctx context.Context) (*connection, error) {
+	return p.checkOut(ctx, checkOutTiming{start: time.Now()})
+}
+
+// checkOut returns a connection from the pool. The timings of the check out so far are passed in t, so that they are
+// included in the check out events and statistics.
+func (p *pool) checkOut(ctx context.Context, t checkOutTiming) (*connection, error) {
 
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
 	if atomic.LoadInt32(&p.connected) != connected {
-		if p.monitor != nil {
-			p.monitor.Event(&event.PoolEvent{
-				Type:    event.GetFailed,
-				Address: p.address.String(),
-				Reason:  event.ReasonPoolClosed,
-			})
-		}
+		p.checkOutFailed(t, event.ReasonPoolClosed)
 		return nil, ErrPoolDisconnected
 	}
 
 	connVal := p.conns.Get()
 	if c, ok := connVal.(*connection); ok && connVal != nil {
 		// call connect if not connected
-		if atomic.LoadInt32(&c.connected) == initialized {
-			c.connect(ctx)
+		t.established = atomic.LoadInt32(&c.connected) == initialized
+		establishStart := time.Now()
+		err := p.establish(ctx, c)
+		if t.established {
+			t.establish = time.Since(establishStart)
 		}
-
-		err := c.wait()
 		if err != nil {
-			if p.monitor != nil {
-				p.monitor.Event(&event.PoolEvent{
-					Type:    event.GetFailed,
-					Address: p.address.String(),
-					Reason:  event.ReasonConnectionErrored,
-				})
+			reason := event.ReasonConnectionErrored
+			if err == ctx.Err() && atomic.LoadInt32(&c.connected) == initialized {
+				// The connection was never connected, so it can be used by another request.
+				_ = p.conns.Put(c)
+				reason = event.ReasonTimedOut
 			}
+			p.checkOutFailed(t, reason)
 			return nil, err
 		}
 
-		if p.monitor != nil {
-			p.monitor.Event(&event.PoolEvent{
-				Type:         event.GetSucceeded,
-				Address:      p.address.String(),
-				ConnectionID: c.poolID,
-			})
-		}
+		p.checkOutSucceeded(c, t)
 		return c, nil
 	}
 
 	select {
 	case <-ctx.Done():
-		if p.monitor != nil {
-			p.monitor.Event(&event.PoolEvent{
-				Type:    event.GetFailed,
-				Address: p.address.String(),
-				Reason:  event.ReasonTimedOut,
-			})
-		}
+		p.checkOutFailed(t, event.ReasonTimedOut)
 		return nil, ctx.Err()
 	default:
 		c, reason, err := p.makeNewConnection(ctx)
 
 		if err != nil {
-			if p.monitor != nil {
-				p.monitor.Event(&event.PoolEvent{
-					Type:    event.GetFailed,
-					Address: p.address.String(),
-					Reason:  reason,
-				})
-			}
+			p.checkOutFailed(t, reason)
 			return nil, err
 		}
 
-		c.connect(ctx)
 		// wait for conn to be connected
-		err = c.wait()
-		if err != nil {
-			if p.monitor != nil {
+		t.established = true
+		establishStart := time.Now()
+		err = p.establish(ctx, c)
+		t.establish = time.Since(establishStart)
+		if err != nil && err == ctx.Err() && atomic.LoadInt32(&c.connected) == initialized {
+			p.stats.connectionClosed(event.ReasonTimedOut)
+			if p.monitored(event.ConnectionClosed) {
 				p.monitor.Event(&event.PoolEvent{
-					Type:    event.GetFailed,
-					Address: p.address.String(),
-					Reason:  reason,
+					Type:         event.ConnectionClosed,
+					Address:      p.address.String(),
+					ConnectionID: c.poolID,
+					Reason:       event.ReasonTimedOut,
 				})
 			}
+			_ = p.closeConnection(c)
+			reason = event.ReasonTimedOut
+		}
+		if err != nil {
+			p.checkOutFailed(t, reason)
 			return nil, err
 		}
 
-		if p.monitor != nil {
-			p.monitor.Event(&event.PoolEvent{
-				Type:         event.GetSucceeded,
-				Address:      p.address.String(),
-				ConnectionID: c.poolID,
-			})
-		}
+		p.checkOutSucceeded(c, t)
 		return c, nil
 	}
 }
 
+// checkOutSucceeded marks c as checked out, records the timings of the check out, and publishes a
+// ConnectionCheckedOut event.
+func (p *pool) checkOutSucceeded(c *connection, t checkOutTiming) {
+	p.checkedOut(c)
+	total := time.Since(t.start)
+	p.stats.checkOutSucceeded(t, total)
+	if p.monitored(event.GetSucceeded) {
+		p.monitor.Event(&event.PoolEvent{
+			Type:              event.GetSucceeded,
+			Address:           p.address.String(),
+			ConnectionID:      c.poolID,
+			WaitQueueLength:   atomic.LoadUint64(&p.stats.waiting),
+			Duration:          total,
+			WaitQueueDuration: t.queued,
+			EstablishDuration: t.establish,
+		})
+	}
+}
+
+// checkOutFailed publishes a ConnectionCheckOutFailed event for a check out that failed for the given reason.
+func (p *pool) checkOutFailed(t checkOutTiming, reason string) {
+	if p.monitored(event.GetFailed) {
+		p.monitor.Event(&event.PoolEvent{
+			Type:              event.GetFailed,
+			Address:           p.address.String(),
+			Reason:            reason,
+			WaitQueueLength:   atomic.LoadUint64(&p.stats.waiting),
+			Duration:          time.Since(t.start),
+			WaitQueueDuration: t.queued,
+			EstablishDuration: t.establish,
+		})
+	}
+}
+
+// warmUp fills the pool up to its minimum size the same way the background maintenance of the pool does and waits for
+// the idle connections to be established. Connections are not checked out, so no check out events are published. It
+// returns an error if a connection cannot be established or ctx expires.
+func (p *pool) warmUp(ctx context.Context) error {
+	p.conns.Maintain()
+
+	for _, v := range p.conns.Values() {
+		c, ok := v.(*connection)
+		if !ok || c == nil {
+			continue
+		}
+
+		established := make(chan error, 1)
+		go func() {
+			established <- p.establish(ctx, c)
+		}()
+		select {
+		case err := <-established:
+			if err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
 // closeConnection closes a connection, not the pool itself. This method will actually closeConnection the connection,
 // making it unusable, to instead return the connection to the pool, use put.
 func (p *pool) closeConnection(c *connection) error {
@@ -411,6 +581,7 @@ func (p *pool) closeConnection(c *connection) error {
 	p.Lock()
 	delete(p.opened, c.poolID)
 	p.Unlock()
+	p.checkedIn(c)
 
 	if atomic.LoadInt32(&c.connected) == connected {
 		c.closeConnectContext()
@@ -431,10 +602,23 @@ func (p *pool) closeConnection(c *connection) error {
 	return nil
 }
 
+// monitored returns true if an event of the given type should be published to the pool monitor, according to the
+// filter and sampler of the monitor.
+func (p *pool) monitored(eventType string) bool {
+	monitor := p.monitor
+	if monitor == nil {
+		return false
+	}
+	if monitor.Filter != nil && !monitor.Filter(eventType, p.address.String()) {
+		return false
+	}
+	return monitor.Sampler == nil || monitor.Sampler.Sample()
+}
+
 // put returns a connection to this pool. If the pool is connected, the connection is not
 // stale, and there is space in the cache, the connection is returned to the cache.
 func (p *pool) put(c *connection) error {
-	if p.monitor != nil {
+	if p.monitored(event.ConnectionReturned) {
 		var cid uint64
 		var addr string
 		if c != nil {
@@ -456,20 +640,51 @@ func (p *pool) put(c *connection) error {
 		return ErrWrongPool
 	}
 
+	p.checkedIn(c)
 	_ = p.conns.Put(c)
 
 	return nil
 }
 
-// clear clears the pool by incrementing the generation and then maintaining the pool
-func (p *pool) clear() {
-	if p.monitor != nil {
+// shrink closes up to n idle connections.
+func (p *pool) shrink(n int) {
+	for i := 0; i < n; i++ {
+		c, ok := p.conns.Get().(*connection)
+		if !ok || c == nil {
+			return
+		}
+
+		p.stats.connectionClosed(event.ReasonRebalanced)
+		if p.monitored(event.ConnectionClosed) {
+			p.monitor.Event(&event.PoolEvent{
+				Type:         event.ConnectionClosed,
+				Address:      p.address.String(),
+				ConnectionID: c.poolID,
+				Reason:       event.ReasonRebalanced,
+			})
+		}
+		_ = p.closeConnection(c)
+	}
+}
+
+// clear clears the pool by incrementing the generation and then maintaining the pool. If serviceID is not nil, only
+// the connections to the backend server with that serviceId are cleared.
+func (p *pool) clear(serviceID *primitive.ObjectID) {
+	if p.monitored(event.PoolCleared) {
 		p.monitor.Event(&event.PoolEvent{
-			Type:    event.PoolCleared,
-			Address: p.address.String(),
+			Type:      event.PoolCleared,
+			Address:   p.address.String(),
+			ServiceID: serviceID,
 		})
 	}
 
-	p.drain()
+	if serviceID == nil {
+		p.drain()
+	} else {
+		p.Lock()
+		p.serviceGenerations[*serviceID]++
+		p.Unlock()
+	}
+	p.throttle.cleared()
 	p.conns.Maintain()
 }
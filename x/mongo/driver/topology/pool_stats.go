@@ -0,0 +1,128 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// checkOutWaitSamples is the number of recent check out durations used to compute check out wait percentiles.
+const checkOutWaitSamples = 1024
+
+// poolStats holds the counters used to build an event.PoolStats for a pool.
+type poolStats struct {
+	inUse   uint64 // must be accessed using the sync/atomic package
+	pending uint64 // must be accessed using the sync/atomic package
+	waiting uint64 // must be accessed using the sync/atomic package
+	created uint64 // must be accessed using the sync/atomic package
+
+	mu          sync.Mutex
+	closed      map[string]uint64
+	waits       durationSamples // total check out durations
+	queueWaits  durationSamples // time spent in the wait queue by check outs
+	establishes durationSamples // time spent establishing new connections by check outs
+}
+
+// durationSamples is a ring buffer of recent durations.
+type durationSamples struct {
+	samples []time.Duration
+	next    int
+}
+
+func (ds *durationSamples) add(d time.Duration) {
+	if len(ds.samples) < checkOutWaitSamples {
+		ds.samples = append(ds.samples, d)
+		return
+	}
+	ds.samples[ds.next] = d
+	ds.next = (ds.next + 1) % checkOutWaitSamples
+}
+
+// percentiles returns the 50th, 90th, and 99th percentiles of the samples, or zeros if there are no samples. It must
+// be called with the mutex of the poolStats held.
+func (ds *durationSamples) percentiles() (p50, p90, p99 time.Duration) {
+	if len(ds.samples) == 0 {
+		return 0, 0, 0
+	}
+	sorted := append([]time.Duration(nil), ds.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return percentile(sorted, 50), percentile(sorted, 90), percentile(sorted, 99)
+}
+
+func (ps *poolStats) connectionClosed(reason string) {
+	ps.mu.Lock()
+	if ps.closed == nil {
+		ps.closed = make(map[string]uint64)
+	}
+	ps.closed[reason]++
+	ps.mu.Unlock()
+}
+
+// checkOutSucceeded records the timings of a successful check out. The establish duration is only recorded if the
+// check out established a new connection.
+func (ps *poolStats) checkOutSucceeded(t checkOutTiming, total time.Duration) {
+	ps.mu.Lock()
+	ps.waits.add(total)
+	ps.queueWaits.add(t.queued)
+	if t.established {
+		ps.establishes.add(t.establish)
+	}
+	ps.mu.Unlock()
+}
+
+// checkedOut marks c as checked out of the pool.
+func (p *pool) checkedOut(c *connection) {
+	if atomic.CompareAndSwapInt32(&c.checkedOut, 0, 1) {
+		atomic.AddUint64(&p.stats.inUse, 1)
+	}
+}
+
+// checkedIn marks c as no longer checked out of the pool. It is safe to call checkedIn for connections that are not
+// checked out.
+func (p *pool) checkedIn(c *connection) {
+	if atomic.CompareAndSwapInt32(&c.checkedOut, 1, 0) {
+		atomicSubtract1Uint64(&p.stats.inUse)
+	}
+}
+
+// Stats returns a snapshot of the pool's statistics.
+func (p *pool) Stats() event.PoolStats {
+	ps := &p.stats
+	stats := event.PoolStats{
+		Address:         p.address.String(),
+		InUse:           atomic.LoadUint64(&ps.inUse),
+		Idle:            atomic.LoadUint64(&p.conns.size),
+		Pending:         atomic.LoadUint64(&ps.pending),
+		WaitQueueLength: atomic.LoadUint64(&ps.waiting),
+		Created:         atomic.LoadUint64(&ps.created),
+		Closed:          make(map[string]uint64),
+	}
+
+	ps.mu.Lock()
+	for reason, n := range ps.closed {
+		stats.Closed[reason] = n
+	}
+	stats.CheckOutWaitP50, stats.CheckOutWaitP90, stats.CheckOutWaitP99 = ps.waits.percentiles()
+	stats.WaitQueueP50, stats.WaitQueueP90, stats.WaitQueueP99 = ps.queueWaits.percentiles()
+	stats.EstablishP50, stats.EstablishP90, stats.EstablishP99 = ps.establishes.percentiles()
+	ps.mu.Unlock()
+	return stats
+}
+
+// percentile returns the pth percentile of sorted using the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
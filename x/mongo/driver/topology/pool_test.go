@@ -8,7 +8,11 @@ import (
 	"testing"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
 )
 
 func TestPool(t *testing.T) {
@@ -364,6 +368,45 @@ func TestPool(t *testing.T) {
 				t.Errorf("Should return error from calling New. got %v; want %v", got, want)
 			}
 		})
+		t.Run("limits connections being established to maxConnecting", func(t *testing.T) {
+			var inflight, maxInflight int32
+			var dialer DialerFunc = func(context.Context, string, string) (net.Conn, error) {
+				n := atomic.AddInt32(&inflight, 1)
+				for {
+					m := atomic.LoadInt32(&maxInflight)
+					if n <= m || atomic.CompareAndSwapInt32(&maxInflight, m, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				atomic.AddInt32(&inflight, -1)
+				return nil, errors.New("dial error")
+			}
+			pc := poolConfig{
+				Address:       address.Address(""),
+				MaxConnecting: 1,
+			}
+			p, err := newPool(pc, WithDialer(func(Dialer) Dialer { return dialer }))
+			noerr(t, err)
+			err = p.connect()
+			noerr(t, err)
+
+			errs := make(chan error, 3)
+			for i := 0; i < 3; i++ {
+				go func() {
+					_, err := p.get(context.Background())
+					errs <- err
+				}()
+			}
+			for i := 0; i < 3; i++ {
+				if err := <-errs; err == nil {
+					t.Errorf("expected dial error, got nil")
+				}
+			}
+			if got := atomic.LoadInt32(&maxInflight); got != 1 {
+				t.Errorf("Incorrect number of concurrent dials. got %d; want %d", got, 1)
+			}
+		})
 		t.Run("adds connection to inflight pool", func(t *testing.T) {
 			cleanup := make(chan struct{})
 			addr := bootstrapConnections(t, 1, func(nc net.Conn) {
@@ -519,6 +562,240 @@ func TestPool(t *testing.T) {
 			close(cleanup)
 		})
 	})
+	t.Run("warmUp", func(t *testing.T) {
+		cleanup := make(chan struct{})
+		defer close(cleanup)
+		addr := bootstrapConnections(t, 2, func(nc net.Conn) {
+			<-cleanup
+			_ = nc.Close()
+		})
+		d := newdialer(&net.Dialer{})
+		var checkOuts int32
+		pc := poolConfig{
+			Address:     address.Address(addr.String()),
+			MinPoolSize: 2,
+			PoolMonitor: &event.PoolMonitor{Event: func(evt *event.PoolEvent) {
+				switch evt.Type {
+				case event.GetSucceeded, event.GetFailed, event.ConnectionReturned:
+					atomic.AddInt32(&checkOuts, 1)
+				}
+			}},
+		}
+		p, err := newPool(pc, WithDialer(func(Dialer) Dialer { return d }))
+		noerr(t, err)
+		err = p.connect()
+		noerr(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		err = p.warmUp(ctx)
+		noerr(t, err)
+		if n := atomic.LoadInt32(&checkOuts); n != 0 {
+			t.Errorf("Expected warmUp not to check out connections, got %d check out events", n)
+		}
+		if idle := p.Stats().Idle; idle != 2 {
+			t.Errorf("Incorrect number of idle connections. got %d; want %d", idle, 2)
+		}
+		p.Lock()
+		for _, c := range p.opened {
+			if atomic.LoadInt32(&c.connected) != connected {
+				t.Errorf("Expected connection %d to be connected", c.poolID)
+			}
+		}
+		p.Unlock()
+		if d.lenopened() != 2 {
+			t.Errorf("Incorrect number of dialed connections. got %d; want %d", d.lenopened(), 2)
+		}
+	})
+	t.Run("clear", func(t *testing.T) {
+		t.Run("only marks connections for the given service as stale", func(t *testing.T) {
+			p, err := newPool(poolConfig{Address: address.Address("")})
+			noerr(t, err)
+			id1, id2 := primitive.NewObjectID(), primitive.NewObjectID()
+			c1 := &connection{generation: p.generation, serviceID: &id1}
+			c2 := &connection{generation: p.generation, serviceID: &id2}
+
+			p.clear(&id1)
+			if !p.stale(c1) {
+				t.Errorf("Expected connection for cleared service to be stale")
+			}
+			if p.stale(c2) {
+				t.Errorf("Expected connection for other service not to be stale")
+			}
+
+			p.clear(nil)
+			if !p.stale(c2) {
+				t.Errorf("Expected all connections to be stale after clearing the whole pool")
+			}
+		})
+		t.Run("delays reconnecting with backoff", func(t *testing.T) {
+			ct := newConnectThrottle(time.Second, 4*time.Second, 0)
+			ct.cleared()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			if err := ct.wait(ctx); err != context.DeadlineExceeded {
+				t.Errorf("Expected wait to block until the backoff expires, got %v", err)
+			}
+
+			ct.cleared()
+			ct.cleared()
+			ct.cleared()
+			if d := time.Until(ct.backoffEnd); d < 2*time.Second-time.Millisecond*100 || d > 4*time.Second {
+				t.Errorf("Expected backoff to be capped at 4s with jitter, got %v", d)
+			}
+
+			ct.succeeded()
+			ct.backoffEnd = time.Time{}
+			if err := ct.wait(context.Background()); err != nil {
+				t.Errorf("Expected wait to return immediately without a backoff, got %v", err)
+			}
+			ct.cleared()
+			if d := time.Until(ct.backoffEnd); d > time.Second {
+				t.Errorf("Expected backoff to be reset after a successful connection, got %v", d)
+			}
+		})
+		t.Run("limits connection rate", func(t *testing.T) {
+			ct := newConnectThrottle(0, 0, 20)
+			ct.cleared()
+			start := time.Now()
+			for i := 0; i < 3; i++ {
+				noerr(t, ct.wait(context.Background()))
+			}
+			if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+				t.Errorf("Expected 3 connection attempts at 20/s to take at least 100ms, took %v", elapsed)
+			}
+		})
+	})
+	t.Run("monitor filter and sampler", func(t *testing.T) {
+		var events []*event.PoolEvent
+		monitor := &event.PoolMonitor{
+			Event: func(evt *event.PoolEvent) { events = append(events, evt) },
+			Filter: func(eventType, address string) bool {
+				return eventType == event.PoolCleared && address == "localhost:27017"
+			},
+			Sampler: event.RateSampler(2),
+		}
+		p, err := newPool(poolConfig{Address: address.Address("localhost:27017"), PoolMonitor: monitor})
+		noerr(t, err)
+
+		for i := 0; i < 4; i++ {
+			p.clear(nil)
+			_ = p.put(nil)
+		}
+		if len(events) != 2 {
+			t.Fatalf("Incorrect number of events. got %d; want %d", len(events), 2)
+		}
+		for _, evt := range events {
+			if evt.Type != event.PoolCleared {
+				t.Errorf("Incorrect event type. got %v; want %v", evt.Type, event.PoolCleared)
+			}
+		}
+	})
+	t.Run("Stats", func(t *testing.T) {
+		cleanup := make(chan struct{})
+		defer close(cleanup)
+		addr := bootstrapConnections(t, 1, func(nc net.Conn) {
+			<-cleanup
+			_ = nc.Close()
+		})
+		d := newdialer(&net.Dialer{})
+		pc := poolConfig{
+			Address: address.Address(addr.String()),
+		}
+		p, err := newPool(pc, WithDialer(func(Dialer) Dialer { return d }))
+		noerr(t, err)
+		err = p.connect()
+		noerr(t, err)
+
+		c, err := p.get(context.Background())
+		noerr(t, err)
+		stats := p.Stats()
+		if stats.InUse != 1 || stats.Idle != 0 || stats.Created != 1 {
+			t.Errorf("Incorrect stats after check out. got %+v", stats)
+		}
+		err = p.put(c)
+		noerr(t, err)
+		stats = p.Stats()
+		if stats.InUse != 0 || stats.Idle != 1 {
+			t.Errorf("Incorrect stats after check in. got %+v", stats)
+		}
+		err = p.disconnect(context.Background())
+		noerr(t, err)
+		if got := p.Stats().Closed[event.ReasonPoolClosed]; got != 1 {
+			t.Errorf("Incorrect number of closed connections. got %d; want %d", got, 1)
+		}
+	})
+	t.Run("ConnectionReady timings", func(t *testing.T) {
+		cleanup := make(chan struct{})
+		defer close(cleanup)
+		addr := bootstrapConnections(t, 1, func(nc net.Conn) {
+			<-cleanup
+			_ = nc.Close()
+		})
+		_, port, err := net.SplitHostPort(addr.String())
+		noerr(t, err)
+		var ready []*event.PoolEvent
+		pc := poolConfig{
+			Address: address.Address("localhost:" + port),
+			PoolMonitor: &event.PoolMonitor{Event: func(evt *event.PoolEvent) {
+				if evt.Type == event.ConnectionReady {
+					ready = append(ready, evt)
+				}
+			}},
+		}
+		p, err := newPool(pc, WithHandshaker(func(Handshaker) Handshaker {
+			return &testHandshaker{
+				getDescription: func(context.Context, address.Address, driver.Connection) (description.Server, error) {
+					time.Sleep(5 * time.Millisecond)
+					return description.Server{}, nil
+				},
+				finishHandshake: func(context.Context, driver.Connection) error {
+					time.Sleep(10 * time.Millisecond)
+					return nil
+				},
+			}
+		}))
+		noerr(t, err)
+		err = p.connect()
+		noerr(t, err)
+		defer func() { _ = p.disconnect(context.Background()) }()
+
+		_, err = p.get(context.Background())
+		noerr(t, err)
+		if len(ready) != 1 {
+			t.Fatalf("expected 1 ConnectionReady event, got %d", len(ready))
+		}
+		timings := ready[0].Timings
+		if timings == nil {
+			t.Fatal("expected ConnectionReady event to have timings")
+		}
+		if timings.TCPConnect <= 0 || timings.TLS != 0 {
+			t.Errorf("unexpected dial timings: %+v", timings)
+		}
+		if timings.Hello < 5*time.Millisecond || timings.Auth < 10*time.Millisecond {
+			t.Errorf("unexpected handshake timings: %+v", timings)
+		}
+		if total := timings.DNS + timings.TCPConnect + timings.Hello + timings.Auth; ready[0].Duration < total {
+			t.Errorf("expected duration %v to include all phases (%v)", ready[0].Duration, total)
+		}
+	})
+	t.Run("percentile", func(t *testing.T) {
+		var waits []time.Duration
+		for i := 1; i <= 100; i++ {
+			waits = append(waits, time.Duration(i)*time.Millisecond)
+		}
+		for _, tc := range []struct {
+			p    int
+			want time.Duration
+		}{{50, 50 * time.Millisecond}, {90, 90 * time.Millisecond}, {99, 99 * time.Millisecond}} {
+			if got := percentile(waits, tc.p); got != tc.want {
+				t.Errorf("Incorrect p%d. got %v; want %v", tc.p, got, tc.want)
+			}
+		}
+		if got := percentile(waits[:1], 99); got != time.Millisecond {
+			t.Errorf("Incorrect p99 for one sample. got %v; want %v", got, time.Millisecond)
+		}
+	})
 	t.Run("Connection", func(t *testing.T) {
 		t.Run("Connection Close Does Not Error After Pool Is Disconnected", func(t *testing.T) {
 			cleanup := make(chan struct{})
@@ -576,6 +853,38 @@ func TestPool(t *testing.T) {
 				t.Errorf("Should not return connection to pool twice. got %d; want %d", p.conns.size, 1)
 			}
 		})
+		t.Run("pinned connection is not returned to pool on close", func(t *testing.T) {
+			cleanup := make(chan struct{})
+			defer close(cleanup)
+			addr := bootstrapConnections(t, 1, func(nc net.Conn) {
+				<-cleanup
+				_ = nc.Close()
+			})
+			d := newdialer(&net.Dialer{})
+			pc := poolConfig{
+				Address: address.Address(addr.String()),
+			}
+			p, err := newPool(pc, WithDialer(func(Dialer) Dialer { return d }))
+			noerr(t, err)
+			err = p.connect()
+			noerr(t, err)
+			c, err := p.get(context.Background())
+			noerr(t, err)
+			c1 := &Connection{connection: c}
+			noerr(t, c1.PinToCursor())
+			noerr(t, c1.Close())
+			if p.conns.size != 0 {
+				t.Errorf("Pinned connection should not be returned to pool. got %d; want %d", p.conns.size, 0)
+			}
+			noerr(t, c1.UnpinFromCursor())
+			if err = c1.UnpinFromCursor(); err == nil {
+				t.Errorf("Expected error unpinning a connection that is not pinned, got nil")
+			}
+			noerr(t, c1.Close())
+			if p.conns.size != 1 {
+				t.Errorf("Unpinned connection should be returned to pool. got %d; want %d", p.conns.size, 1)
+			}
+		})
 		t.Run("close does not panic if expires before connected", func(t *testing.T) {
 			cleanup := make(chan struct{})
 			defer close(cleanup)
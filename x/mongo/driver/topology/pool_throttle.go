@@ -0,0 +1,102 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// defaultMaxClearBackoff is the upper bound on the delay before reconnecting after a pool clear if no maximum is
+// configured.
+const defaultMaxClearBackoff = 10 * time.Second
+
+// connectThrottle delays the establishment of new connections after the pool has been cleared and limits the rate at
+// which new connections are established, so that a pool clear does not cause every waiting operation to reconnect to
+// the server at once.
+type connectThrottle struct {
+	mu         sync.Mutex
+	base       time.Duration // initial backoff after a clear; 0 disables backoff
+	max        time.Duration // upper bound on the backoff
+	interval   time.Duration // minimum time between connection attempts; 0 disables rate limiting
+	clears     uint          // consecutive clears since a connection was last established
+	backoffEnd time.Time     // no connection attempts are started before this time
+	next       time.Time     // earliest time the next connection attempt may start
+}
+
+func newConnectThrottle(base, max time.Duration, rate uint64) *connectThrottle {
+	if max <= 0 {
+		max = defaultMaxClearBackoff
+	}
+	t := &connectThrottle{base: base, max: max}
+	if rate > 0 {
+		t.interval = time.Second / time.Duration(rate)
+	}
+	return t
+}
+
+// cleared records a pool clear. Each consecutive clear doubles the backoff, up to the configured maximum. The actual
+// delay is chosen uniformly from [backoff/2, backoff) so that clients do not reconnect in lockstep.
+func (t *connectThrottle) cleared() {
+	if t.base <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	backoff := t.base
+	for i := uint(0); i < t.clears && backoff < t.max; i++ {
+		backoff *= 2
+	}
+	if backoff > t.max {
+		backoff = t.max
+	}
+	t.clears++
+
+	half := backoff / 2
+	t.backoffEnd = time.Now().Add(half + time.Duration(rand.Int63n(int64(half)+1)))
+}
+
+// succeeded records that a connection was established, resetting the backoff.
+func (t *connectThrottle) succeeded() {
+	t.mu.Lock()
+	t.clears = 0
+	t.mu.Unlock()
+}
+
+// wait blocks until a new connection attempt may be started or ctx is done.
+func (t *connectThrottle) wait(ctx context.Context) error {
+	t.mu.Lock()
+	now := time.Now()
+	start := now
+	if t.backoffEnd.After(start) {
+		start = t.backoffEnd
+	}
+	if t.interval > 0 {
+		if t.next.After(start) {
+			start = t.next
+		}
+		t.next = start.Add(t.interval)
+	}
+	t.mu.Unlock()
+
+	delay := start.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
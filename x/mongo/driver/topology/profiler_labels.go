@@ -0,0 +1,29 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"context"
+	"runtime/pprof"
+
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+)
+
+// runLabeled runs fn, with pprof labels naming the driver component and, if it is not empty, the server address if
+// enabled is true, so that CPU profiles attribute the work of background goroutines to the driver. Goroutines started
+// by fn inherit the labels.
+func runLabeled(enabled bool, component, addr string, fn func()) {
+	if !enabled {
+		fn()
+		return
+	}
+	labels := []string{driver.ProfilerLabelComponent, component}
+	if addr != "" {
+		labels = append(labels, driver.ProfilerLabelAddress, addr)
+	}
+	pprof.Do(context.Background(), pprof.Labels(labels...), func(context.Context) { fn() })
+}
@@ -127,6 +127,18 @@ func (rp *resourcePool) Get() interface{} {
 	return nil
 }
 
+// Values returns the resources in the pool without removing them.
+func (rp *resourcePool) Values() []interface{} {
+	rp.Lock()
+	defer rp.Unlock()
+
+	values := make([]interface{}, 0, atomic.LoadUint64(&rp.size))
+	for curr := rp.start; curr != nil; curr = curr.next {
+		values = append(values, curr.value)
+	}
+	return values
+}
+
 // Put puts the resource back into the pool if it will not exceed the max size of the pool
 func (rp *resourcePool) Put(v interface{}) bool {
 	if rp.expiredFn(v) {
@@ -0,0 +1,61 @@
+// Copyright (C) MongoDB, Inc. 2017-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// rttSamples is the number of recent heartbeat round trip times used to compute round trip time statistics.
+const rttSamples = 100
+
+// rttStats holds the round trip times measured by the heartbeats of a server.
+type rttStats struct {
+	mu      sync.Mutex
+	samples []time.Duration // ring buffer of recent round trip times
+	next    int
+	current time.Duration
+	average time.Duration
+}
+
+func (rs *rttStats) add(rtt, average time.Duration) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.current = rtt
+	rs.average = average
+	if len(rs.samples) < rttSamples {
+		rs.samples = append(rs.samples, rtt)
+		return
+	}
+	rs.samples[rs.next] = rtt
+	rs.next = (rs.next + 1) % rttSamples
+}
+
+// stats returns a snapshot of the round trip time statistics for the server at addr.
+func (rs *rttStats) stats(addr string) event.RTTStats {
+	rs.mu.Lock()
+	stats := event.RTTStats{
+		Address: addr,
+		Current: rs.current,
+		Average: rs.average,
+		Samples: len(rs.samples),
+	}
+	samples := append([]time.Duration(nil), rs.samples...)
+	rs.mu.Unlock()
+
+	if len(samples) > 0 {
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		stats.Min = samples[0]
+		stats.P90 = percentile(samples, 90)
+	}
+	return stats
+}
@@ -16,7 +16,11 @@ import (
 	"sync/atomic"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/clock"
 	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 	"go.mongodb.org/mongo-driver/x/mongo/driver"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
@@ -43,6 +47,17 @@ type SelectedServer struct {
 	Kind description.TopologyKind
 }
 
+// RTTStats returns a snapshot of the round trip times measured by this server's heartbeats.
+func (s *Server) RTTStats() event.RTTStats {
+	return s.rtt.stats(s.address.String())
+}
+
+// RecentErrors returns the most recent errors returned by operations and heartbeats run against this server, oldest
+// first.
+func (s *Server) RecentErrors() []event.ServerError {
+	return s.errors.recent()
+}
+
 // Description returns a description of the server as of the last heartbeat.
 func (ss *SelectedServer) Description() description.SelectedServer {
 	sdesc := ss.Server.Description()
@@ -80,13 +95,18 @@ func connectionStateString(state int32) string {
 
 // Server is a single server within a topology.
 type Server struct {
+	// heartbeatGeneration is incremented to replace the monitoring connection. It is the first field so that it is
+	// aligned for atomic access and must be accessed using the sync/atomic package.
+	heartbeatGeneration uint64
+
 	cfg             *serverConfig
 	address         address.Address
 	connectionstate int32
 
 	// connection related fields
-	pool *pool
-	sem  *semaphore.Weighted
+	pool  *pool
+	sem   *semaphore.Weighted
+	opSem *semaphore.Weighted // limits in-flight operations; nil if there is no limit
 
 	// goroutine management fields
 	done          chan struct{}
@@ -94,11 +114,18 @@ type Server struct {
 	disconnecting chan struct{}
 	closewg       sync.WaitGroup
 
+	// suspension related fields
+	suspendLock sync.Mutex
+	resumed     chan struct{} // closed when the server is resumed; nil if the server is not suspended
+
 	// description related fields
 	desc                   atomic.Value // holds a description.Server
 	updateTopologyCallback atomic.Value
 	averageRTTSet          bool
 	averageRTT             time.Duration
+	rtt                    rttStats
+	errors                 serverErrors
+	profilerLabels         bool // whether the monitoring goroutine is labeled
 
 	// subscriber related fields
 	subLock             sync.Mutex
@@ -146,15 +173,23 @@ func NewServer(addr address.Address, opts ...ServerOption) (*Server, error) {
 
 		subscribers: make(map[uint64]chan description.Server),
 	}
+	if cfg.maxOperations > 0 {
+		s.opSem = semaphore.NewWeighted(int64(cfg.maxOperations))
+	}
 	s.desc.Store(description.Server{Addr: addr})
 
 	callback := func(desc description.Server) { s.updateDescription(desc, false) }
 	pc := poolConfig{
-		Address:     addr,
-		MinPoolSize: cfg.minConns,
-		MaxPoolSize: cfg.maxConns,
-		MaxIdleTime: cfg.connectionPoolMaxIdleTime,
-		PoolMonitor: cfg.poolMonitor,
+		Address:       addr,
+		MinPoolSize:   cfg.minConns,
+		MaxPoolSize:   cfg.maxConns,
+		MaxConnecting: cfg.maxConnecting,
+		MaxIdleTime:   cfg.connectionPoolMaxIdleTime,
+		PoolMonitor:   cfg.poolMonitor,
+
+		ClearBackoff:      cfg.clearBackoff,
+		MaxClearBackoff:   cfg.maxClearBackoff,
+		MaxConnectionRate: cfg.maxConnectionRate,
 	}
 
 	s.pool, err = newPool(pc, withServerDescriptionCallback(callback, cfg.connectionOpts...)...)
@@ -170,10 +205,16 @@ func (s *Server) Connect(updateCallback func(description.Server)) error {
 	if !atomic.CompareAndSwapInt32(&s.connectionstate, disconnected, connected) {
 		return ErrServerConnected
 	}
-	s.desc.Store(description.Server{Addr: s.address})
 	s.updateTopologyCallback.Store(updateCallback)
-	go s.update()
-	s.closewg.Add(1)
+
+	if s.cfg.loadBalanced {
+		// Servers behind a load balancer are not monitored, so the description never changes.
+		s.desc.Store(description.Server{Addr: s.address, Kind: description.LoadBalancer})
+	} else {
+		s.desc.Store(description.Server{Addr: s.address})
+		go runLabeled(s.profilerLabels, "monitor", s.address.String(), s.update)
+		s.closewg.Add(1)
+	}
 	return s.pool.connect()
 }
 
@@ -194,14 +235,16 @@ func (s *Server) Disconnect(ctx context.Context) error {
 	s.updateTopologyCallback.Store((func(description.Server))(nil))
 
 	// For every call to Connect there must be at least 1 goroutine that is
-	// waiting on the done channel.
-	select {
-	case <-ctx.Done():
-		// signal a disconnect and still wait for receiver of done
-		// to finish.
-		close(s.disconnecting)
-		s.done <- struct{}{}
-	case s.done <- struct{}{}:
+	// waiting on the done channel, unless the server is not monitored.
+	if !s.cfg.loadBalanced {
+		select {
+		case <-ctx.Done():
+			// signal a disconnect and still wait for receiver of done
+			// to finish.
+			close(s.disconnecting)
+			s.done <- struct{}{}
+		case s.done <- struct{}{}:
+		}
 	}
 	err := s.pool.disconnect(ctx)
 	if err != nil {
@@ -216,11 +259,13 @@ func (s *Server) Disconnect(ctx context.Context) error {
 
 // Connection gets a connection to the server.
 func (s *Server) Connection(ctx context.Context) (driver.Connection, error) {
+	t := checkOutTiming{start: time.Now()}
 
-	if s.pool.monitor != nil {
+	if s.pool.monitored("ConnectionCheckOutStarted") {
 		s.pool.monitor.Event(&event.PoolEvent{
-			Type:    "ConnectionCheckOutStarted",
-			Address: s.pool.address.String(),
+			Type:            "ConnectionCheckOutStarted",
+			Address:         s.pool.address.String(),
+			WaitQueueLength: atomic.LoadUint64(&s.pool.stats.waiting),
 		})
 	}
 
@@ -228,21 +273,36 @@ func (s *Server) Connection(ctx context.Context) (driver.Connection, error) {
 		return nil, ErrServerClosed
 	}
 
-	err := s.sem.Acquire(ctx, 1)
-	if err != nil {
-		if s.pool.monitor != nil {
+	if err := s.acquireOperation(ctx); err != nil {
+		t.queued = time.Since(t.start)
+		s.pool.checkOutFailed(t, event.ReasonTimedOut)
+		return nil, err
+	}
+
+	var err error
+	if !s.sem.TryAcquire(1) {
+		waiting := atomic.AddUint64(&s.pool.stats.waiting, 1)
+		if s.pool.monitored(event.CheckOutQueued) {
 			s.pool.monitor.Event(&event.PoolEvent{
-				Type:    "ConnectionCheckOutFailed",
-				Address: s.pool.address.String(),
-				Reason:  "timeout",
+				Type:            event.CheckOutQueued,
+				Address:         s.pool.address.String(),
+				WaitQueueLength: waiting,
 			})
 		}
+		err = s.sem.Acquire(ctx, 1)
+		atomicSubtract1Uint64(&s.pool.stats.waiting)
+	}
+	t.queued = time.Since(t.start)
+	if err != nil {
+		s.releaseOperation()
+		s.pool.checkOutFailed(t, event.ReasonTimedOut)
 		return nil, ErrWaitQueueTimeout
 	}
 
-	conn, err := s.pool.get(ctx)
+	conn, err := s.pool.checkOut(ctx, t)
 	if err != nil {
 		s.sem.Release(1)
+		s.releaseOperation()
 		wrappedConnErr := unwrapConnectionError(err)
 		if wrappedConnErr == nil {
 			return nil, err
@@ -262,6 +322,64 @@ func (s *Server) Connection(ctx context.Context) (driver.Connection, error) {
 	return &Connection{connection: conn, s: s}, nil
 }
 
+// acquireOperation reserves one of the server's in-flight operation slots. If all slots are taken, it waits for up to
+// the operation queue timeout and then returns a ServerOverloadedError.
+func (s *Server) acquireOperation(ctx context.Context) error {
+	if s.opSem == nil {
+		return nil
+	}
+	if s.opSem.TryAcquire(1) {
+		return nil
+	}
+
+	waitCtx := ctx
+	if s.cfg.operationQueueTimeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, s.cfg.operationQueueTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	if err := s.opSem.Acquire(waitCtx, 1); err != nil {
+		if ctx.Err() != nil {
+			return ErrWaitQueueTimeout
+		}
+		return ServerOverloadedError{
+			Address:       s.address,
+			MaxOperations: s.cfg.maxOperations,
+			Wait:          time.Since(start),
+		}
+	}
+	return nil
+}
+
+// releaseOperation releases an in-flight operation slot acquired by acquireOperation.
+func (s *Server) releaseOperation() {
+	if s.opSem != nil {
+		s.opSem.Release(1)
+	}
+}
+
+// WarmUp blocks until the server's connection pool has established at least the minimum number of connections
+// configured with WithMinConnections, a connection cannot be established, or ctx expires.
+func (s *Server) WarmUp(ctx context.Context) error {
+	if atomic.LoadInt32(&s.connectionstate) != connected {
+		return ErrServerClosed
+	}
+	return s.pool.warmUp(ctx)
+}
+
+// operationCount returns the number of connections that are checked out of the server's pool or are waiting to be
+// checked out.
+func (s *Server) operationCount() uint64 {
+	return atomic.LoadUint64(&s.pool.stats.inUse) + atomic.LoadUint64(&s.pool.stats.waiting)
+}
+
+// PoolStats returns a snapshot of the statistics for this server's connection pool.
+func (s *Server) PoolStats() event.PoolStats {
+	return s.pool.Stats()
+}
+
 // Description returns a description of the server as of the last heartbeat.
 func (s *Server) Description() description.Server {
 	return s.desc.Load().(description.Server)
@@ -315,8 +433,96 @@ func (s *Server) RequestImmediateCheck() {
 	}
 }
 
-// ProcessError handles SDAM error handling and implements driver.ErrorProcessor.
-func (s *Server) ProcessError(err error) {
+// recyclePollInterval is the interval at which RecycleConnections checks whether all connections have been replaced.
+const recyclePollInterval = 10 * time.Millisecond
+
+// RecycleConnections marks every connection to the server, whether idle or checked out, for replacement and blocks
+// until all of them have been closed or ctx is done. Unlike clearing the pool, operations in progress are not
+// interrupted: idle connections are closed immediately, and a checked-out connection is closed when it is checked back
+// in. The monitoring connection is replaced by a new one for an immediate heartbeat. New connections are established
+// as they are needed, so connections are replaced gradually.
+//
+// Connections that are pinned to a cursor or a transaction, as they are for load balanced deployments, are only
+// checked in when the cursor is closed or the transaction ends, so RecycleConnections can block until then. ctx
+// should have a deadline if such connections may be open.
+func (s *Server) RecycleConnections(ctx context.Context) error {
+	return s.waitRecycled(ctx, s.recycle())
+}
+
+// recycle marks every open connection for replacement, closes the idle connections, and requests a heartbeat on a new
+// monitoring connection. It returns the generation of the replacement connections.
+func (s *Server) recycle() uint64 {
+	generation := atomic.AddUint64(&s.pool.generation, 1)
+	if atomic.LoadInt32(&s.pool.connected) == connected {
+		// close the idle connections, which are now stale
+		s.pool.conns.Maintain()
+	}
+	atomic.AddUint64(&s.heartbeatGeneration, 1)
+	s.RequestImmediateCheck()
+	return generation
+}
+
+// waitRecycled blocks until no connections older than generation are open or ctx is done.
+func (s *Server) waitRecycled(ctx context.Context, generation uint64) error {
+	ticker := time.NewTicker(recyclePollInterval)
+	defer ticker.Stop()
+	for s.pool.openedBefore(generation) > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Suspend stops the server from sending heartbeats until Resume is called. It is intended for environments such as
+// AWS Lambda where the process is frozen between invocations. Operations can still be run on a suspended server.
+func (s *Server) Suspend() {
+	s.suspendLock.Lock()
+	defer s.suspendLock.Unlock()
+	if s.resumed == nil {
+		s.resumed = make(chan struct{})
+	}
+}
+
+// Resume resumes heartbeats on a server stopped by Suspend and checks the server immediately. Connections in the pool
+// are kept, and the pool is only cleared if the check or an operation fails, as it is for a server that was never
+// suspended.
+func (s *Server) Resume() {
+	s.suspendLock.Lock()
+	resumed := s.resumed
+	s.resumed = nil
+	s.suspendLock.Unlock()
+	if resumed == nil {
+		return
+	}
+
+	close(resumed)
+	s.RequestImmediateCheck()
+}
+
+// suspension returns a channel that is closed when the server is resumed, or nil if the server is not suspended.
+func (s *Server) suspension() chan struct{} {
+	s.suspendLock.Lock()
+	defer s.suspendLock.Unlock()
+	return s.resumed
+}
+
+// ProcessError handles SDAM error handling and implements driver.ErrorProcessor. If the server is behind a load
+// balancer, the description is never changed and only the connections to the backend server identified by the
+// serviceId of conn are cleared.
+func (s *Server) ProcessError(err error, conn driver.Connection) {
+	if err == nil {
+		return
+	}
+	s.errors.add(s.address.String(), errorSourceOperation, err)
+
+	var serviceID *primitive.ObjectID
+	if s.cfg.loadBalanced && conn != nil {
+		serviceID = conn.Description().ServiceID
+	}
+
 	// Invalidate server description if not master or node recovering error occurs.
 	// These errors can be reported as a command error or a write concern error.
 	if cerr, ok := err.(driver.Error); ok && (cerr.NodeIsRecovering() || cerr.NotMaster()) {
@@ -328,7 +534,7 @@ func (s *Server) ProcessError(err error) {
 		// If the node is shutting down or is older than 4.2, we synchronously clear the pool
 		if cerr.NodeIsShuttingDown() || desc.WireVersion == nil || desc.WireVersion.Max < 8 {
 			s.RequestImmediateCheck()
-			s.pool.clear()
+			s.pool.clear(serviceID)
 		}
 		return
 	}
@@ -341,7 +547,7 @@ func (s *Server) ProcessError(err error) {
 		// If the node is shutting down or is older than 4.2, we synchronously clear the pool
 		if wcerr.NodeIsShuttingDown() || desc.WireVersion == nil || desc.WireVersion.Max < 8 {
 			s.RequestImmediateCheck()
-			s.pool.clear()
+			s.pool.clear(serviceID)
 		}
 		return
 	}
@@ -364,15 +570,15 @@ func (s *Server) ProcessError(err error) {
 	desc.LastError = err
 	// updates description to unknown
 	s.updateDescription(desc, false)
-	s.pool.clear()
+	s.pool.clear(serviceID)
 }
 
 // update handles performing heartbeats and updating any subscribers of the
 // newest description.Server retrieved.
 func (s *Server) update() {
 	defer s.closewg.Done()
-	heartbeatTicker := time.NewTicker(s.cfg.heartbeatInterval)
-	rateLimiter := time.NewTicker(minHeartbeatInterval)
+	heartbeatTicker := s.cfg.wallClock.NewTicker(s.cfg.heartbeatInterval)
+	rateLimiter := s.cfg.wallClock.NewTicker(s.cfg.minHeartbeatInterval)
 	defer heartbeatTicker.Stop()
 	defer rateLimiter.Stop()
 	checkNow := s.checkNow
@@ -392,6 +598,7 @@ func (s *Server) update() {
 	var conn *connection
 	var desc description.Server
 
+	connGeneration := atomic.LoadUint64(&s.heartbeatGeneration)
 	desc, conn = s.heartbeat(nil)
 	s.updateDescription(desc, true)
 
@@ -418,7 +625,7 @@ func (s *Server) update() {
 		}
 
 		select {
-		case <-heartbeatTicker.C:
+		case <-heartbeatTicker.C():
 		case <-checkNow:
 		case <-done:
 			closeServer()
@@ -426,12 +633,35 @@ func (s *Server) update() {
 		}
 
 		select {
-		case <-rateLimiter.C:
+		case <-rateLimiter.C():
 		case <-done:
 			closeServer()
 			return
 		}
 
+		if resumed := s.suspension(); resumed != nil {
+			select {
+			case <-resumed:
+			case <-done:
+				closeServer()
+				return
+			}
+			// The heartbeat connection may have been closed by the server or a load balancer while the process was
+			// suspended, so a new one is established for the first heartbeat after resuming.
+			if conn != nil {
+				_ = conn.close()
+				conn = nil
+			}
+		}
+		// The monitoring connection is replaced after RecycleConnections.
+		if generation := atomic.LoadUint64(&s.heartbeatGeneration); generation != connGeneration {
+			if conn != nil {
+				_ = conn.close()
+				conn = nil
+			}
+			connGeneration = generation
+		}
+
 		desc, conn = s.heartbeat(conn)
 		s.updateDescription(desc, false)
 	}
@@ -442,6 +672,11 @@ func (s *Server) update() {
 // parameter is used to determine if this is the first description from the
 // server.
 func (s *Server) updateDescription(desc description.Server, initial bool) {
+	if s.cfg.loadBalanced {
+		// The description of a server behind a load balancer does not change.
+		return
+	}
+
 	defer func() {
 		//  ¯\_(ツ)_/¯
 		_ = recover()
@@ -495,6 +730,7 @@ func (s *Server) heartbeat(conn *connection) (description.Server, *connection) {
 	for i := 1; i <= maxRetry; i++ {
 		var now time.Time
 		var descPtr *description.Server
+		var reply bsoncore.Document
 
 		if conn != nil && conn.expired() {
 			if conn.nc != nil {
@@ -510,11 +746,18 @@ func (s *Server) heartbeat(conn *connection) (description.Server, *connection) {
 				WithWriteTimeout(func(time.Duration) time.Duration { return s.cfg.heartbeatTimeout }),
 			}
 			opts = append(opts, s.cfg.connectionOpts...)
+			if s.cfg.heartbeatConnectTimeout > 0 {
+				opts = append(opts, WithConnectTimeout(func(time.Duration) time.Duration {
+					return s.cfg.heartbeatConnectTimeout
+				}))
+			}
 			// We override whatever handshaker is currently attached to the options with a basic
 			// one because need to make sure we don't do auth.
+			var handshaker *operation.IsMaster
 			opts = append(opts, WithHandshaker(func(h Handshaker) Handshaker {
-				now = time.Now()
-				return operation.NewIsMaster().AppName(s.cfg.appname).Compressors(s.cfg.compressionOpts)
+				now = s.cfg.wallClock.Now()
+				handshaker = operation.NewIsMaster().AppName(s.cfg.appname).Compressors(s.cfg.compressionOpts)
+				return handshaker
 			}))
 
 			// Override any command monitors specified in options with nil to avoid monitoring heartbeats.
@@ -523,26 +766,30 @@ func (s *Server) heartbeat(conn *connection) (description.Server, *connection) {
 			}))
 
 			conn, err = newConnection(ctx, s.address, opts...)
+			s.publishHeartbeatStarted(conn.id)
 
 			conn.connect(ctx)
 
 			err = conn.wait()
 			if err == nil {
 				descPtr = &conn.desc
+				reply = handshaker.Reply()
 			}
 		}
 
 		// do a heartbeat because a new connection wasn't created so a handshake was not performed
 		if descPtr == nil && err == nil {
-			now = time.Now()
+			now = s.cfg.wallClock.Now()
 			op := operation.
 				NewIsMaster().
 				ClusterClock(s.cfg.clock).
 				Deployment(driver.SingleConnectionDeployment{initConnection{conn}})
+			s.publishHeartbeatStarted(conn.id)
 			err = op.Execute(ctx)
 			if err == nil {
 				tmpDesc := op.Result(s.address)
 				descPtr = &tmpDesc
+				reply = op.Reply()
 			} else {
 				// close the connection here rather than in the error check below to avoid calling Close on a net.Conn
 				// that wasn't successfully created
@@ -552,6 +799,8 @@ func (s *Server) heartbeat(conn *connection) (description.Server, *connection) {
 
 		// we do a retry if the server is connected, if succeed return new server desc (see below)
 		if err != nil {
+			s.publishHeartbeatFailed(conn.id, clock.Since(s.cfg.wallClock, now), err)
+			s.errors.add(s.address.String(), errorSourceHeartbeat, err)
 			saved = err
 			conn = nil
 			if wrappedConnErr := unwrapConnectionError(err); wrappedConnErr != nil {
@@ -565,7 +814,8 @@ func (s *Server) heartbeat(conn *connection) (description.Server, *connection) {
 		}
 
 		desc = *descPtr
-		delay := time.Since(now)
+		delay := clock.Since(s.cfg.wallClock, now)
+		s.publishHeartbeatSucceeded(conn.id, delay, reply)
 		desc = desc.SetAverageRTT(s.updateAverageRTT(delay))
 		desc.HeartbeatInterval = s.cfg.heartbeatInterval
 		set = true
@@ -584,6 +834,32 @@ func (s *Server) heartbeat(conn *connection) (description.Server, *connection) {
 	return desc, conn
 }
 
+func (s *Server) publishHeartbeatStarted(connID string) {
+	if monitor := s.cfg.serverMonitor; monitor != nil && monitor.ServerHeartbeatStarted != nil {
+		monitor.ServerHeartbeatStarted(&event.ServerHeartbeatStartedEvent{ConnectionID: connID})
+	}
+}
+
+func (s *Server) publishHeartbeatSucceeded(connID string, duration time.Duration, reply bsoncore.Document) {
+	if monitor := s.cfg.serverMonitor; monitor != nil && monitor.ServerHeartbeatSucceeded != nil {
+		monitor.ServerHeartbeatSucceeded(&event.ServerHeartbeatSucceededEvent{
+			DurationNanos: duration.Nanoseconds(),
+			Reply:         bson.Raw(reply),
+			ConnectionID:  connID,
+		})
+	}
+}
+
+func (s *Server) publishHeartbeatFailed(connID string, duration time.Duration, err error) {
+	if monitor := s.cfg.serverMonitor; monitor != nil && monitor.ServerHeartbeatFailed != nil {
+		monitor.ServerHeartbeatFailed(&event.ServerHeartbeatFailedEvent{
+			DurationNanos: duration.Nanoseconds(),
+			Failure:       err,
+			ConnectionID:  connID,
+		})
+	}
+}
+
 func (s *Server) updateAverageRTT(delay time.Duration) time.Duration {
 	if !s.averageRTTSet {
 		s.averageRTT = delay
@@ -591,6 +867,7 @@ func (s *Server) updateAverageRTT(delay time.Duration) time.Duration {
 		alpha := 0.2
 		s.averageRTT = time.Duration(alpha*float64(delay) + (1-alpha)*float64(s.averageRTT))
 	}
+	s.rtt.add(delay, s.averageRTT)
 	return s.averageRTT
 }
 
@@ -0,0 +1,53 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// recentErrorCount is the number of recent errors kept for each server.
+const recentErrorCount = 10
+
+// Sources of the errors kept for a server.
+const (
+	errorSourceOperation = "operation"
+	errorSourceHeartbeat = "heartbeat"
+)
+
+// serverErrors holds the most recent errors of a server.
+type serverErrors struct {
+	mu   sync.Mutex
+	errs []event.ServerError // ring buffer of recent errors
+	next int
+}
+
+func (se *serverErrors) add(addr, source string, err error) {
+	e := event.ServerError{Address: addr, Time: time.Now(), Source: source, Error: err}
+
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	if len(se.errs) < recentErrorCount {
+		se.errs = append(se.errs, e)
+		return
+	}
+	se.errs[se.next] = e
+	se.next = (se.next + 1) % recentErrorCount
+}
+
+// recent returns the recent errors, oldest first.
+func (se *serverErrors) recent() []event.ServerError {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	errs := make([]event.ServerError, 0, len(se.errs))
+	errs = append(errs, se.errs[se.next:]...)
+	return append(errs, se.errs[:se.next]...)
+}
@@ -11,6 +11,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/clock"
 	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/session"
 )
@@ -19,14 +20,25 @@ var defaultRegistry = bson.NewRegistryBuilder().Build()
 
 type serverConfig struct {
 	clock                     *session.ClusterClock
+	wallClock                 clock.Clock
 	compressionOpts           []string
 	connectionOpts            []ConnectionOption
 	appname                   string
 	heartbeatInterval         time.Duration
 	heartbeatTimeout          time.Duration
+	heartbeatConnectTimeout   time.Duration
+	minHeartbeatInterval      time.Duration
+	loadBalanced              bool
 	maxConns                  uint64
+	maxConnecting             uint64
+	clearBackoff              time.Duration
+	maxClearBackoff           time.Duration
+	maxConnectionRate         uint64
+	maxOperations             uint64
+	operationQueueTimeout     time.Duration
 	minConns                  uint64
 	poolMonitor               *event.PoolMonitor
+	serverMonitor             *event.ServerMonitor
 	connectionPoolMaxIdleTime time.Duration
 	registry                  *bsoncodec.Registry
 }
@@ -36,7 +48,9 @@ func newServerConfig(opts ...ServerOption) (*serverConfig, error) {
 		heartbeatInterval: 10 * time.Second,
 		heartbeatTimeout:  10 * time.Second,
 		maxConns:          100,
+		maxConnecting:     defaultMaxConnecting,
 		registry:          defaultRegistry,
+		wallClock:         clock.System,
 	}
 
 	for _, opt := range opts {
@@ -46,6 +60,10 @@ func newServerConfig(opts ...ServerOption) (*serverConfig, error) {
 		}
 	}
 
+	if cfg.minHeartbeatInterval <= 0 {
+		cfg.minHeartbeatInterval = minHeartbeatInterval
+	}
+
 	return cfg, nil
 }
 
@@ -93,6 +111,26 @@ func WithHeartbeatTimeout(fn func(time.Duration) time.Duration) ServerOption {
 	}
 }
 
+// WithHeartbeatConnectTimeout configures how long to wait for a heartbeat socket to connect, overriding both the
+// heartbeat timeout and the connect timeout of the server's connection options. If zero, the connect timeout of the
+// server's connection options is used.
+func WithHeartbeatConnectTimeout(fn func(time.Duration) time.Duration) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.heartbeatConnectTimeout = fn(cfg.heartbeatConnectTimeout)
+		return nil
+	}
+}
+
+// WithMinHeartbeatInterval configures the minimum amount of time between heartbeats of a server, which limits how
+// often an immediate check requested after an error can be performed. If zero or negative, the default of 500ms is
+// used.
+func WithMinHeartbeatInterval(fn func(time.Duration) time.Duration) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.minHeartbeatInterval = fn(cfg.minHeartbeatInterval)
+		return nil
+	}
+}
+
 // WithMaxConnections configures the maximum number of connections to allow for
 // a given server. If max is 0, then the default will be math.MaxInt64.
 func WithMaxConnections(fn func(uint64) uint64) ServerOption {
@@ -102,6 +140,63 @@ func WithMaxConnections(fn func(uint64) uint64) ServerOption {
 	}
 }
 
+// WithMaxConnecting configures the maximum number of connections to a given server that can be establishing a
+// connection (dialing and performing the handshake) at the same time. If max is 0, the default of 2 will be used.
+func WithMaxConnecting(fn func(uint64) uint64) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.maxConnecting = fn(cfg.maxConnecting)
+		return nil
+	}
+}
+
+// WithPoolClearBackoff configures the initial delay before new connections to a server are established after its
+// connection pool is cleared. The delay doubles with each consecutive clear, is reset once a connection is established,
+// and is randomized by up to half its length. If the backoff is 0, connections are re-established immediately.
+func WithPoolClearBackoff(fn func(time.Duration) time.Duration) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.clearBackoff = fn(cfg.clearBackoff)
+		return nil
+	}
+}
+
+// WithMaxPoolClearBackoff configures the upper bound on the delay configured with WithPoolClearBackoff. If max is 0,
+// the default of 10 seconds will be used.
+func WithMaxPoolClearBackoff(fn func(time.Duration) time.Duration) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.maxClearBackoff = fn(cfg.maxClearBackoff)
+		return nil
+	}
+}
+
+// WithMaxConnectionRate configures the maximum number of new connections per second that can be established to a
+// given server. If rate is 0, there is no limit.
+func WithMaxConnectionRate(fn func(uint64) uint64) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.maxConnectionRate = fn(cfg.maxConnectionRate)
+		return nil
+	}
+}
+
+// WithMaxOperations configures the maximum number of operations that can be in progress on a given server at the same
+// time, independent of the maximum number of connections. Operations over the limit wait for one to finish for up to
+// the timeout configured with WithOperationQueueTimeout. If max is 0, there is no limit.
+func WithMaxOperations(fn func(uint64) uint64) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.maxOperations = fn(cfg.maxOperations)
+		return nil
+	}
+}
+
+// WithOperationQueueTimeout configures how long an operation waits for an in-progress operation to finish when the
+// limit configured with WithMaxOperations is reached before a ServerOverloadedError is returned. If the timeout is 0,
+// operations wait until their context is done.
+func WithOperationQueueTimeout(fn func(time.Duration) time.Duration) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.operationQueueTimeout = fn(cfg.operationQueueTimeout)
+		return nil
+	}
+}
+
 // WithMinConnections configures the minimum number of connections to allow for
 // a given server. If min is 0, then there is no lower limit to the number of
 // connections.
@@ -130,6 +225,14 @@ func WithConnectionPoolMonitor(fn func(*event.PoolMonitor) *event.PoolMonitor) S
 	}
 }
 
+// WithServerMonitor configures the monitor for the heartbeats sent to the server.
+func WithServerMonitor(fn func(*event.ServerMonitor) *event.ServerMonitor) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.serverMonitor = fn(cfg.serverMonitor)
+		return nil
+	}
+}
+
 // WithClock configures the ClusterClock for the server to use.
 func WithClock(fn func(clock *session.ClusterClock) *session.ClusterClock) ServerOption {
 	return func(cfg *serverConfig) error {
@@ -138,6 +241,15 @@ func WithClock(fn func(clock *session.ClusterClock) *session.ClusterClock) Serve
 	}
 }
 
+// WithServerWallClock configures the Clock used for the heartbeats of the server and the measurement of their round
+// trip times. Unlike WithClock, which configures the cluster time sent to the server, it is the source of local time.
+func WithServerWallClock(fn func(clock.Clock) clock.Clock) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.wallClock = clock.OrSystem(fn(cfg.wallClock))
+		return nil
+	}
+}
+
 // WithRegistry configures the registry for the server to use when creating
 // cursors.
 func WithRegistry(fn func(*bsoncodec.Registry) *bsoncodec.Registry) ServerOption {
@@ -146,3 +258,12 @@ func WithRegistry(fn func(*bsoncodec.Registry) *bsoncodec.Registry) ServerOption
 		return nil
 	}
 }
+
+// WithServerLoadBalanced specifies whether or not the server is behind a load balancer. Servers behind a load
+// balancer are not monitored and their connections are cleared per backend server, identified by serviceId.
+func WithServerLoadBalanced(fn func(bool) bool) ServerOption {
+	return func(cfg *serverConfig) error {
+		cfg.loadBalanced = fn(cfg.loadBalanced)
+		return nil
+	}
+}
@@ -8,6 +8,7 @@ package topology
 
 import (
 	"context"
+	"errors"
 	"net"
 	"runtime"
 	"sync"
@@ -17,6 +18,9 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/clock"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 	"go.mongodb.org/mongo-driver/x/mongo/driver"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
@@ -194,6 +198,196 @@ func TestServer(t *testing.T) {
 		wg.Wait()
 		close(cleanup)
 	})
+	t.Run("check out timings and wait queue events", func(t *testing.T) {
+		cleanup := make(chan struct{})
+		defer close(cleanup)
+		addr := bootstrapConnections(t, 1, func(nc net.Conn) {
+			<-cleanup
+			_ = nc.Close()
+		})
+		d := newdialer(&net.Dialer{})
+		var mu sync.Mutex
+		var events []*event.PoolEvent
+		s, err := NewServer(address.Address(addr.String()),
+			WithConnectionOptions(func(option ...ConnectionOption) []ConnectionOption {
+				return []ConnectionOption{WithDialer(func(_ Dialer) Dialer { return d })}
+			}),
+			WithMaxConnections(func(uint64) uint64 { return 1 }),
+			WithConnectionPoolMonitor(func(*event.PoolMonitor) *event.PoolMonitor {
+				return &event.PoolMonitor{Event: func(evt *event.PoolEvent) {
+					mu.Lock()
+					events = append(events, evt)
+					mu.Unlock()
+				}}
+			}),
+		)
+		noerr(t, err)
+		s.connectionstate = connected
+		err = s.pool.connect()
+		noerr(t, err)
+
+		conn, err := s.Connection(context.Background())
+		noerr(t, err)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			conn, err := s.Connection(context.Background())
+			if err != nil {
+				t.Errorf("Connection error: %v", err)
+				return
+			}
+			_ = conn.Close()
+		}()
+		for deadline := time.Now().Add(time.Second); s.PoolStats().WaitQueueLength != 1; {
+			if time.Now().After(deadline) {
+				t.Fatal("timed out waiting for the check out to be queued")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		err = conn.Close()
+		noerr(t, err)
+		<-done
+
+		mu.Lock()
+		defer mu.Unlock()
+		var queued, checkedOut []*event.PoolEvent
+		for _, evt := range events {
+			switch evt.Type {
+			case event.CheckOutQueued:
+				queued = append(queued, evt)
+			case event.GetSucceeded:
+				checkedOut = append(checkedOut, evt)
+			}
+		}
+		require.Len(t, queued, 1, "expected 1 queued event")
+		require.Equal(t, uint64(1), queued[0].WaitQueueLength, "unexpected wait queue length")
+		require.Len(t, checkedOut, 2, "expected 2 checked out events")
+		require.True(t, checkedOut[0].EstablishDuration > 0, "expected the first check out to establish a connection")
+		require.True(t, checkedOut[1].WaitQueueDuration > 0, "expected the second check out to wait in the queue")
+		require.Equal(t, time.Duration(0), checkedOut[1].EstablishDuration, "expected the idle connection to be reused")
+		require.True(t, checkedOut[1].Duration >= checkedOut[1].WaitQueueDuration, "expected total duration to include the wait")
+
+		stats := s.PoolStats()
+		require.True(t, stats.WaitQueueP99 >= checkedOut[1].WaitQueueDuration, "unexpected wait queue percentile %v", stats.WaitQueueP99)
+		require.True(t, stats.EstablishP50 > 0, "expected an establish percentile")
+	})
+	t.Run("operation limit returns overloaded error", func(t *testing.T) {
+		cleanup := make(chan struct{})
+		addr := bootstrapConnections(t, 2, func(nc net.Conn) {
+			<-cleanup
+			_ = nc.Close()
+		})
+		d := newdialer(&net.Dialer{})
+		s, err := NewServer(address.Address(addr.String()),
+			WithConnectionOptions(func(option ...ConnectionOption) []ConnectionOption {
+				return []ConnectionOption{WithDialer(func(_ Dialer) Dialer { return d })}
+			}),
+			WithMaxOperations(func(uint64) uint64 { return 1 }),
+			WithOperationQueueTimeout(func(time.Duration) time.Duration { return 10 * time.Millisecond }))
+		noerr(t, err)
+		s.connectionstate = connected
+		err = s.pool.connect()
+		noerr(t, err)
+
+		conn, err := s.Connection(context.Background())
+		noerr(t, err)
+
+		_, err = s.Connection(context.Background())
+		overloaded, ok := err.(ServerOverloadedError)
+		require.True(t, ok, "expected ServerOverloadedError, got %v (%T)", err, err)
+		require.Equal(t, uint64(1), overloaded.MaxOperations, "unexpected operation limit")
+		require.Equal(t, s.address, overloaded.Address, "unexpected address")
+
+		noerr(t, conn.Close())
+		conn, err = s.Connection(context.Background())
+		noerr(t, err)
+		noerr(t, conn.Close())
+		close(cleanup)
+	})
+	t.Run("RecycleConnections", func(t *testing.T) {
+		cleanup := make(chan struct{})
+		addr := bootstrapConnections(t, 3, func(nc net.Conn) {
+			<-cleanup
+			_ = nc.Close()
+		})
+		d := newdialer(&net.Dialer{})
+		s, err := NewServer(address.Address(addr.String()),
+			WithConnectionOptions(func(option ...ConnectionOption) []ConnectionOption {
+				return []ConnectionOption{WithDialer(func(_ Dialer) Dialer { return d })}
+			}))
+		noerr(t, err)
+		s.connectionstate = connected
+		noerr(t, s.pool.connect())
+
+		inUse, err := s.Connection(context.Background())
+		noerr(t, err)
+		idle, err := s.Connection(context.Background())
+		noerr(t, err)
+		noerr(t, idle.Close())
+
+		done := make(chan error, 1)
+		go func() { done <- s.RecycleConnections(context.Background()) }()
+		time.Sleep(20 * time.Millisecond)
+		select {
+		case err = <-done:
+			t.Fatalf("expected RecycleConnections to wait for connections to be replaced, returned %v", err)
+		default:
+		}
+		require.Equal(t, 1, d.lenclosed(), "expected the idle connection to be closed up front")
+
+		// Checking in the connection in use closes it, and the next checkout opens a replacement connection.
+		noerr(t, inUse.Close())
+		select {
+		case err = <-done:
+			noerr(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for RecycleConnections to return")
+		}
+		require.Equal(t, 2, d.lenclosed(), "expected the checked in connection to be closed")
+		conn, err := s.Connection(context.Background())
+		noerr(t, err)
+		require.Equal(t, 3, d.lenopened(), "expected one replacement connection to be opened")
+		noerr(t, conn.Close())
+		close(cleanup)
+	})
+	t.Run("RecycleConnections idle pool", func(t *testing.T) {
+		var dials int32
+		d := DialerFunc(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			atomic.AddInt32(&dials, 1)
+			nc, err := (&channelNetConnDialer{}).DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			// a reply for a heartbeat on the connection after the handshake
+			return nc, nc.(*drivertest.ChannelNetConn).AddResponse(makeIsMasterReply())
+		})
+		s, err := NewServer(address.Address("localhost:27017"),
+			WithHeartbeatInterval(func(time.Duration) time.Duration { return time.Minute }),
+			WithMinHeartbeatInterval(func(time.Duration) time.Duration { return time.Millisecond }),
+			WithConnectionOptions(func(...ConnectionOption) []ConnectionOption {
+				return []ConnectionOption{WithDialer(func(Dialer) Dialer { return d })}
+			}))
+		require.Nil(t, err, "error from NewServer: %v", err)
+		require.Nil(t, s.Connect(nil), "error from Connect")
+		defer func() { _ = s.Disconnect(context.Background()) }()
+
+		waitForDials := func(n int32, msg string) {
+			t.Helper()
+			deadline := time.Now().Add(time.Second)
+			for atomic.LoadInt32(&dials) < n {
+				if time.Now().After(deadline) {
+					t.Fatal(msg)
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}
+		waitForDials(1, "expected a monitoring connection to be dialed")
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.Nil(t, s.RecycleConnections(ctx), "expected RecycleConnections to return for an idle pool")
+		waitForDials(2, "expected the monitoring connection to be replaced")
+	})
 	t.Run("WriteConcernError", func(t *testing.T) {
 		s, err := NewServer(address.Address("localhost"))
 		require.NoError(t, err)
@@ -206,7 +400,7 @@ func TestServer(t *testing.T) {
 		s.pool.connected = connected
 
 		wce := driver.WriteConcernError{"", 10107, "not master", []byte{}, []string{}}
-		s.ProcessError(wce)
+		s.ProcessError(wce, nil)
 
 		// should set ServerDescription to Unknown
 		resultDesc := s.Description()
@@ -230,7 +424,7 @@ func TestServer(t *testing.T) {
 		s.pool.connected = connected
 
 		wce := driver.WriteConcernError{}
-		s.ProcessError(&wce)
+		s.ProcessError(&wce, nil)
 
 		// should not be a LastError
 		require.Nil(t, s.Description().LastError)
@@ -290,6 +484,184 @@ func TestServer(t *testing.T) {
 			t.Fatal("client metadata not expected in heartbeat but found")
 		}
 	})
+	t.Run("heartbeat connect timeout", func(t *testing.T) {
+		dialer := &channelNetConnDialer{}
+		s, err := NewServer(address.Address("localhost:27017"),
+			WithConnectionOptions(func(connOpts ...ConnectionOption) []ConnectionOption {
+				return append(connOpts,
+					WithDialer(func(Dialer) Dialer { return dialer }),
+					WithConnectTimeout(func(time.Duration) time.Duration { return 30 * time.Second }),
+				)
+			}),
+			WithHeartbeatConnectTimeout(func(time.Duration) time.Duration { return 2 * time.Second }),
+		)
+		require.Nil(t, err, "error from NewServer: %v", err)
+
+		_, conn := s.heartbeat(nil)
+		require.NotNil(t, conn, "no connection dialed")
+		require.Equal(t, 2*time.Second, conn.config.connectTimeout,
+			"expected heartbeat connect timeout to override the connect timeout")
+	})
+	t.Run("recent errors", func(t *testing.T) {
+		s, err := NewServer(address.Address("localhost:27017"))
+		require.NoError(t, err)
+
+		s.ProcessError(nil, nil)
+		for i := 0; i < recentErrorCount+2; i++ {
+			s.ProcessError(driver.Error{Code: int32(i), Message: "error"}, nil)
+		}
+		errs := s.RecentErrors()
+		require.Len(t, errs, recentErrorCount, "expected the number of recent errors to be bounded")
+		require.Equal(t, int32(2), errs[0].Error.(driver.Error).Code, "expected the oldest errors to be dropped")
+		require.Equal(t, int32(recentErrorCount+1), errs[len(errs)-1].Error.(driver.Error).Code, "expected newest error last")
+		require.Equal(t, "operation", errs[0].Source, "unexpected error source")
+		require.Equal(t, "localhost:27017", errs[0].Address, "unexpected error address")
+	})
+	t.Run("heartbeat events", func(t *testing.T) {
+		var started []*event.ServerHeartbeatStartedEvent
+		var succeeded []*event.ServerHeartbeatSucceededEvent
+		var failed []*event.ServerHeartbeatFailedEvent
+		monitor := &event.ServerMonitor{
+			ServerHeartbeatStarted:   func(evt *event.ServerHeartbeatStartedEvent) { started = append(started, evt) },
+			ServerHeartbeatSucceeded: func(evt *event.ServerHeartbeatSucceededEvent) { succeeded = append(succeeded, evt) },
+			ServerHeartbeatFailed:    func(evt *event.ServerHeartbeatFailedEvent) { failed = append(failed, evt) },
+		}
+		newServer := func(d Dialer) *Server {
+			s, err := NewServer(address.Address("localhost:27017"),
+				WithServerMonitor(func(*event.ServerMonitor) *event.ServerMonitor { return monitor }),
+				WithConnectionOptions(func(connOpts ...ConnectionOption) []ConnectionOption {
+					return append(connOpts, WithDialer(func(Dialer) Dialer { return d }))
+				}),
+			)
+			require.Nil(t, err, "error from NewServer: %v", err)
+			return s
+		}
+
+		_, conn := newServer(&channelNetConnDialer{}).heartbeat(nil)
+		require.NotNil(t, conn, "no connection dialed")
+		require.Equal(t, 1, len(started), "expected 1 started event")
+		require.Equal(t, conn.id, started[0].ConnectionID, "unexpected connection ID")
+		require.Equal(t, 1, len(succeeded), "expected 1 succeeded event")
+		require.Equal(t, conn.id, succeeded[0].ConnectionID, "unexpected connection ID")
+		wantReply := bson.Raw(bsoncore.BuildDocumentFromElements(nil, bsoncore.AppendInt32Element(nil, "ok", 1)))
+		require.Equal(t, wantReply, succeeded[0].Reply, "unexpected reply")
+		require.True(t, succeeded[0].DurationNanos > 0, "expected positive duration")
+		require.Equal(t, 0, len(failed), "expected no failed events")
+
+		started, succeeded = nil, nil
+		_, _ = newServer(DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+			return nil, errors.New("dial error")
+		})).heartbeat(nil)
+		require.Equal(t, 1, len(started), "expected 1 started event")
+		require.Equal(t, 0, len(succeeded), "expected no succeeded events")
+		require.Equal(t, 1, len(failed), "expected 1 failed event")
+		require.NotNil(t, failed[0].Failure, "expected failure")
+	})
+	t.Run("WithMinHeartbeatInterval", func(t *testing.T) {
+		s, err := NewServer(address.Address("localhost"))
+		require.Nil(t, err, "error from NewServer: %v", err)
+		require.Equal(t, minHeartbeatInterval, s.cfg.minHeartbeatInterval, "expected default min heartbeat interval")
+
+		s, err = NewServer(address.Address("localhost"),
+			WithMinHeartbeatInterval(func(time.Duration) time.Duration { return 50 * time.Millisecond }))
+		require.Nil(t, err, "error from NewServer: %v", err)
+		require.Equal(t, 50*time.Millisecond, s.cfg.minHeartbeatInterval, "expected configured min heartbeat interval")
+	})
+	t.Run("Suspend and Resume", func(t *testing.T) {
+		var dials, blocking int32
+		unblock := make(chan struct{})
+		d := DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+			atomic.AddInt32(&dials, 1)
+			if atomic.LoadInt32(&blocking) == 1 {
+				// hold the heartbeat so that the pool is not cleared by its failure
+				<-unblock
+			}
+			return nil, errors.New("dial error")
+		})
+		s, err := NewServer(address.Address("localhost:27017"),
+			WithHeartbeatInterval(func(time.Duration) time.Duration { return 5 * time.Millisecond }),
+			WithMinHeartbeatInterval(func(time.Duration) time.Duration { return time.Millisecond }),
+			WithConnectionOptions(func(...ConnectionOption) []ConnectionOption {
+				return []ConnectionOption{WithDialer(func(Dialer) Dialer { return d })}
+			}))
+		require.Nil(t, err, "error from NewServer: %v", err)
+		require.Nil(t, s.Connect(nil), "error from Connect")
+		defer func() { _ = s.Disconnect(context.Background()) }()
+		defer close(unblock)
+
+		s.Suspend()
+		time.Sleep(20 * time.Millisecond) // let a heartbeat in progress finish
+		suspended := atomic.LoadInt32(&dials)
+		time.Sleep(50 * time.Millisecond)
+		require.Equal(t, suspended, atomic.LoadInt32(&dials), "expected no heartbeats while suspended")
+
+		generation := atomic.LoadUint64(&s.pool.generation)
+		atomic.StoreInt32(&blocking, 1)
+		s.Resume()
+		deadline := time.Now().Add(5 * time.Second)
+		for atomic.LoadInt32(&dials) == suspended {
+			require.True(t, time.Now().Before(deadline), "expected heartbeats to resume")
+			time.Sleep(time.Millisecond)
+		}
+		require.Equal(t, generation, atomic.LoadUint64(&s.pool.generation), "expected connections to be kept")
+	})
+	t.Run("WithServerWallClock", func(t *testing.T) {
+		fake := clock.NewFake(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+		dials := make(chan struct{}, 10)
+		d := DialerFunc(func(context.Context, string, string) (net.Conn, error) {
+			dials <- struct{}{}
+			return nil, errors.New("dial error")
+		})
+		s, err := NewServer(address.Address("localhost:27017"),
+			WithServerWallClock(func(clock.Clock) clock.Clock { return fake }),
+			WithHeartbeatInterval(func(time.Duration) time.Duration { return 10 * time.Second }),
+			WithMinHeartbeatInterval(func(time.Duration) time.Duration { return 500 * time.Millisecond }),
+			WithConnectionOptions(func(...ConnectionOption) []ConnectionOption {
+				return []ConnectionOption{WithDialer(func(Dialer) Dialer { return d })}
+			}))
+		require.Nil(t, err, "error from NewServer: %v", err)
+		require.Nil(t, s.Connect(nil), "error from Connect")
+		defer func() { _ = s.Disconnect(context.Background()) }()
+
+		waitForDial := func(msg string) {
+			t.Helper()
+			select {
+			case <-dials:
+			case <-time.After(5 * time.Second):
+				t.Fatal(msg)
+			}
+		}
+		waitForDial("expected an initial heartbeat")
+		// the heartbeat and rate limiting tickers
+		fake.BlockUntil(2)
+
+		fake.Advance(10 * time.Second)
+		waitForDial("expected a heartbeat after the heartbeat interval")
+
+		s.RequestImmediateCheck()
+		fake.Advance(500 * time.Millisecond)
+		waitForDial("expected a heartbeat after an immediate check was requested")
+	})
+	t.Run("RTTStats", func(t *testing.T) {
+		s, err := NewServer(address.Address("localhost:27017"))
+		require.Nil(t, err, "error from NewServer: %v", err)
+
+		stats := s.RTTStats()
+		require.Equal(t, 0, stats.Samples, "expected no samples before any heartbeat")
+
+		for i := 1; i <= 10; i++ {
+			s.updateAverageRTT(time.Duration(i) * time.Millisecond)
+		}
+		s.updateAverageRTT(3 * time.Millisecond)
+
+		stats = s.RTTStats()
+		require.Equal(t, "localhost:27017", stats.Address, "unexpected address")
+		require.Equal(t, 11, stats.Samples, "unexpected number of samples")
+		require.Equal(t, 3*time.Millisecond, stats.Current, "expected current RTT to be the last sample")
+		require.Equal(t, time.Millisecond, stats.Min, "unexpected min RTT")
+		require.Equal(t, 9*time.Millisecond, stats.P90, "unexpected p90 RTT")
+		require.Equal(t, s.averageRTT, stats.Average, "expected average RTT to match the server's moving average")
+	})
 	t.Run("WithServerAppName", func(t *testing.T) {
 		name := "test"
 
@@ -0,0 +1,211 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	socks5Version = 0x05
+
+	socks5AuthNone         = 0x00
+	socks5AuthPassword     = 0x02
+	socks5AuthUnacceptable = 0xff
+
+	socks5PasswordVersion = 0x01
+
+	socks5CmdConnect = 0x01
+
+	socks5AddrIPv4   = 0x01
+	socks5AddrDomain = 0x03
+	socks5AddrIPv6   = 0x04
+)
+
+// socks5Replies maps the reply codes defined in RFC 1928 section 6 to their descriptions.
+var socks5Replies = map[byte]string{
+	0x01: "general SOCKS server failure",
+	0x02: "connection not allowed by ruleset",
+	0x03: "network unreachable",
+	0x04: "host unreachable",
+	0x05: "connection refused",
+	0x06: "TTL expired",
+	0x07: "command not supported",
+	0x08: "address type not supported",
+}
+
+// ProxyConfig describes a SOCKS5 proxy that all connections are routed through.
+type ProxyConfig struct {
+	// Address is the host:port of the proxy.
+	Address string
+
+	// Username and Password are used to authenticate to the proxy if set. Both must be set or neither.
+	Username string
+	Password string
+}
+
+// socks5Dialer is a Dialer that establishes connections through a SOCKS5 proxy. The connection to the proxy is made
+// using the forward Dialer.
+type socks5Dialer struct {
+	forward Dialer
+	proxy   ProxyConfig
+}
+
+var _ Dialer = (*socks5Dialer)(nil)
+
+// DialContext implements the Dialer interface.
+func (d *socks5Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("socks5 proxy does not support network %q", network)
+	}
+
+	nc, err := d.forward.DialContext(ctx, "tcp", d.proxy.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = nc.SetDeadline(deadline)
+	}
+	if err = d.handshake(nc, address); err != nil {
+		_ = nc.Close()
+		return nil, fmt.Errorf("socks5 proxy %s: %v", d.proxy.Address, err)
+	}
+	_ = nc.SetDeadline(time.Time{})
+
+	return nc, nil
+}
+
+// handshake negotiates authentication with the proxy and asks it to connect to address, as described in RFC 1928 and
+// RFC 1929.
+func (d *socks5Dialer) handshake(rw io.ReadWriter, address string) error {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("invalid port: %s", portStr)
+	}
+
+	methods := []byte{socks5AuthNone}
+	if d.proxy.Username != "" {
+		methods = append(methods, socks5AuthPassword)
+	}
+	req := append([]byte{socks5Version, byte(len(methods))}, methods...)
+	if _, err = rw.Write(req); err != nil {
+		return err
+	}
+
+	resp := make([]byte, 2)
+	if _, err = io.ReadFull(rw, resp); err != nil {
+		return err
+	}
+	if resp[0] != socks5Version {
+		return fmt.Errorf("unexpected protocol version %d", resp[0])
+	}
+
+	switch resp[1] {
+	case socks5AuthNone:
+	case socks5AuthPassword:
+		if d.proxy.Username == "" {
+			return errors.New("proxy requested username/password authentication but no credentials were provided")
+		}
+		if err = d.authenticate(rw); err != nil {
+			return err
+		}
+	case socks5AuthUnacceptable:
+		return errors.New("no acceptable authentication methods")
+	default:
+		return fmt.Errorf("unsupported authentication method %d", resp[1])
+	}
+
+	req = []byte{socks5Version, socks5CmdConnect, 0x00}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			req = append(req, socks5AddrIPv4)
+			req = append(req, ip4...)
+		} else {
+			req = append(req, socks5AddrIPv6)
+			req = append(req, ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return fmt.Errorf("host name too long: %s", host)
+		}
+		req = append(req, socks5AddrDomain, byte(len(host)))
+		req = append(req, host...)
+	}
+	req = append(req, byte(port>>8), byte(port))
+	if _, err = rw.Write(req); err != nil {
+		return err
+	}
+
+	// The reply is VER, REP, RSV, ATYP followed by the bound address and port.
+	resp = make([]byte, 4)
+	if _, err = io.ReadFull(rw, resp); err != nil {
+		return err
+	}
+	if resp[0] != socks5Version {
+		return fmt.Errorf("unexpected protocol version %d", resp[0])
+	}
+	if resp[1] != 0x00 {
+		if msg, ok := socks5Replies[resp[1]]; ok {
+			return fmt.Errorf("connect to %s failed: %s", address, msg)
+		}
+		return fmt.Errorf("connect to %s failed with reply code %d", address, resp[1])
+	}
+
+	var addrLen int
+	switch resp[3] {
+	case socks5AddrIPv4:
+		addrLen = net.IPv4len
+	case socks5AddrIPv6:
+		addrLen = net.IPv6len
+	case socks5AddrDomain:
+		l := make([]byte, 1)
+		if _, err = io.ReadFull(rw, l); err != nil {
+			return err
+		}
+		addrLen = int(l[0])
+	default:
+		return fmt.Errorf("unknown address type %d", resp[3])
+	}
+	_, err = io.ReadFull(rw, make([]byte, addrLen+2))
+	return err
+}
+
+func (d *socks5Dialer) authenticate(rw io.ReadWriter) error {
+	if len(d.proxy.Username) > 255 || len(d.proxy.Password) > 255 {
+		return errors.New("proxy username and password must be at most 255 bytes")
+	}
+
+	req := []byte{socks5PasswordVersion, byte(len(d.proxy.Username))}
+	req = append(req, d.proxy.Username...)
+	req = append(req, byte(len(d.proxy.Password)))
+	req = append(req, d.proxy.Password...)
+	if _, err := rw.Write(req); err != nil {
+		return err
+	}
+
+	resp := make([]byte, 2)
+	if _, err := io.ReadFull(rw, resp); err != nil {
+		return err
+	}
+	if resp[1] != 0x00 {
+		return errors.New("username/password authentication failed")
+	}
+	return nil
+}
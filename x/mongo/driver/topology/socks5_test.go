@@ -0,0 +1,152 @@
+// Copyright (C) MongoDB, Inc. 2021-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+)
+
+// serveSOCKS5 runs a minimal SOCKS5 server on nc. It accepts the given authentication method, checks the credentials
+// if password authentication is used, and replies to the connect request with reply. The requested destination is
+// sent on dest.
+func serveSOCKS5(nc net.Conn, method byte, username, password string, reply byte, dest chan<- []byte) {
+	defer func() { _ = nc.Close() }()
+
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(nc, buf); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(nc, make([]byte, buf[1])); err != nil {
+		return
+	}
+	if _, err := nc.Write([]byte{socks5Version, method}); err != nil {
+		return
+	}
+
+	if method == socks5AuthPassword {
+		hdr := make([]byte, 2)
+		if _, err := io.ReadFull(nc, hdr); err != nil {
+			return
+		}
+		user := make([]byte, hdr[1])
+		if _, err := io.ReadFull(nc, user); err != nil {
+			return
+		}
+		plen := make([]byte, 1)
+		if _, err := io.ReadFull(nc, plen); err != nil {
+			return
+		}
+		pass := make([]byte, plen[0])
+		if _, err := io.ReadFull(nc, pass); err != nil {
+			return
+		}
+		status := byte(0x00)
+		if string(user) != username || string(pass) != password {
+			status = 0x01
+		}
+		if _, err := nc.Write([]byte{socks5PasswordVersion, status}); err != nil || status != 0x00 {
+			return
+		}
+	}
+
+	hdr := make([]byte, 4)
+	if _, err := io.ReadFull(nc, hdr); err != nil {
+		return
+	}
+	var addr []byte
+	switch hdr[3] {
+	case socks5AddrIPv4:
+		addr = make([]byte, net.IPv4len+2)
+	case socks5AddrIPv6:
+		addr = make([]byte, net.IPv6len+2)
+	case socks5AddrDomain:
+		l := make([]byte, 1)
+		if _, err := io.ReadFull(nc, l); err != nil {
+			return
+		}
+		hdr = append(hdr, l[0])
+		addr = make([]byte, int(l[0])+2)
+	}
+	if _, err := io.ReadFull(nc, addr); err != nil {
+		return
+	}
+	dest <- append(hdr[3:], addr...)
+
+	_, _ = nc.Write([]byte{socks5Version, reply, 0x00, socks5AddrIPv4, 127, 0, 0, 1, 0x04, 0x38})
+}
+
+func TestSOCKS5Dialer(t *testing.T) {
+	testCases := []struct {
+		name     string
+		method   byte
+		username string
+		password string
+		reply    byte
+		target   string
+		wantDest []byte
+		wantErr  bool
+	}{
+		{"no auth domain", socks5AuthNone, "", "", 0x00, "db.example.com:27017",
+			append([]byte{socks5AddrDomain, 14}, append([]byte("db.example.com"), 0x69, 0x89)...), false},
+		{"no auth IPv4", socks5AuthNone, "", "", 0x00, "10.0.0.1:27017",
+			[]byte{socks5AddrIPv4, 10, 0, 0, 1, 0x69, 0x89}, false},
+		{"password auth", socks5AuthPassword, "user", "pencil", 0x00, "10.0.0.1:27017",
+			[]byte{socks5AddrIPv4, 10, 0, 0, 1, 0x69, 0x89}, false},
+		{"connection refused", socks5AuthNone, "", "", 0x05, "10.0.0.1:27017", nil, true},
+		{"no acceptable methods", socks5AuthUnacceptable, "", "", 0x00, "10.0.0.1:27017", nil, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			dest := make(chan []byte, 1)
+			go serveSOCKS5(server, tc.method, tc.username, tc.password, tc.reply, dest)
+
+			d := &socks5Dialer{
+				forward: DialerFunc(func(context.Context, string, string) (net.Conn, error) { return client, nil }),
+				proxy:   ProxyConfig{Address: "proxy:1080", Username: tc.username, Password: tc.password},
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			nc, err := d.DialContext(ctx, "tcp", tc.target)
+			if tc.wantErr {
+				assert.NotNil(t, err, "expected error, got nil")
+				return
+			}
+			assert.Nil(t, err, "DialContext error: %v", err)
+			assert.True(t, nc == client, "expected proxied connection to be returned")
+			assert.Equal(t, tc.wantDest, <-dest, "unexpected destination sent to proxy")
+		})
+	}
+	t.Run("wrong credentials", func(t *testing.T) {
+		client, server := net.Pipe()
+		go serveSOCKS5(server, socks5AuthPassword, "user", "pencil", 0x00, make(chan []byte, 1))
+
+		d := &socks5Dialer{
+			forward: DialerFunc(func(context.Context, string, string) (net.Conn, error) { return client, nil }),
+			proxy:   ProxyConfig{Address: "proxy:1080", Username: "user", Password: "wrong"},
+		}
+		_, err := d.DialContext(context.Background(), "tcp", "10.0.0.1:27017")
+		assert.NotNil(t, err, "expected authentication error, got nil")
+	})
+	t.Run("WithProxy wraps dialer", func(t *testing.T) {
+		cfg, err := newConnectionConfig(WithProxy(func(*ProxyConfig) *ProxyConfig {
+			return &ProxyConfig{Address: "proxy:1080"}
+		}))
+		assert.Nil(t, err, "newConnectionConfig error: %v", err)
+		d, ok := cfg.dialer.(*socks5Dialer)
+		assert.True(t, ok, "expected dialer to be a *socks5Dialer, got %T", cfg.dialer)
+		_, ok = d.forward.(*net.Dialer)
+		assert.True(t, ok, "expected proxy to be dialed with the default dialer, got %T", d.forward)
+	})
+}
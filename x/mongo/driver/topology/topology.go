@@ -14,6 +14,7 @@ import (
 	"context"
 	"errors"
 	"math/rand"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -21,8 +22,14 @@ import (
 
 	"fmt"
 
+	"go.mongodb.org/mongo-driver/clock"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/logger"
+	"go.mongodb.org/mongo-driver/tag"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 	"go.mongodb.org/mongo-driver/x/mongo/driver"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/dns"
 )
@@ -69,6 +76,10 @@ type Topology struct {
 	rescanSRVInterval time.Duration
 	pollHeartbeatTime atomic.Value // holds a bool
 
+	rebalanceDone chan struct{}
+	rebalancewg   sync.WaitGroup
+	mongosNext    uint64 // next mongos for round robin selection; must be accessed using the atomic package
+
 	fsm *fsm
 
 	// This should really be encapsulated into it's own type. This will likely
@@ -86,6 +97,7 @@ type Topology struct {
 	serversLock   sync.Mutex
 	serversClosed bool
 	servers       map[address.Address]*Server
+	suspended     int32 // must be accessed using the atomic package
 }
 
 var _ driver.Deployment = &Topology{}
@@ -114,11 +126,11 @@ func New(opts ...Option) (*Topology, error) {
 		cfg:               cfg,
 		done:              make(chan struct{}),
 		pollingDone:       make(chan struct{}),
-		rescanSRVInterval: 60 * time.Second,
+		rescanSRVInterval: cfg.srvPollingInterval,
 		fsm:               newFSM(),
 		subscribers:       make(map[uint64]chan description.Topology),
 		servers:           make(map[address.Address]*Server),
-		dnsResolver:       dns.DefaultResolver,
+		dnsResolver:       cfg.dnsResolver,
 	}
 	t.desc.Store(description.Topology{})
 
@@ -131,6 +143,11 @@ func New(opts ...Option) (*Topology, error) {
 		t.fsm.Kind = description.Single
 	}
 
+	if cfg.loadBalanced {
+		t.fsm.Kind = description.LoadBalanced
+		cfg.serverOpts = append(cfg.serverOpts, WithServerLoadBalanced(func(bool) bool { return true }))
+	}
+
 	return t, nil
 }
 
@@ -152,13 +169,29 @@ func (t *Topology) Connect() error {
 			return err
 		}
 	}
+	if t.cfg.loadBalanced {
+		// Servers behind a load balancer are not monitored, so the topology description is only set once.
+		for i := range t.fsm.Servers {
+			t.fsm.Servers[i].Kind = description.LoadBalancer
+		}
+		t.desc.Store(description.Topology{
+			Kind:    t.fsm.Kind,
+			Servers: t.fsm.Servers,
+		})
+	}
 	t.serversLock.Unlock()
 
-	if srvPollingRequired(t.cfg.cs.Original) {
-		go t.pollSRVRecords()
+	if srvPollingRequired(t.cfg.cs.Original) && !t.cfg.loadBalanced {
+		go runLabeled(t.cfg.profilerLabels, "srvPoller", "", t.pollSRVRecords)
 		t.pollingwg.Add(1)
 	}
 
+	if t.cfg.mongosRebalance > 0 && !t.cfg.loadBalanced {
+		t.rebalanceDone = make(chan struct{})
+		t.rebalancewg.Add(1)
+		go runLabeled(t.cfg.profilerLabels, "mongosRebalancer", "", t.rebalanceMongos)
+	}
+
 	t.subscriptionsClosed = false // explicitly set in case topology was disconnected and then reconnected
 
 	atomic.StoreInt32(&t.connectionstate, connected)
@@ -197,6 +230,12 @@ func (t *Topology) Disconnect(ctx context.Context) error {
 		t.pollingwg.Wait()
 	}
 
+	if t.rebalanceDone != nil {
+		close(t.rebalanceDone)
+		t.rebalancewg.Wait()
+		t.rebalanceDone = nil
+	}
+
 	t.desc.Store(description.Topology{})
 
 	atomic.StoreInt32(&t.connectionstate, disconnected)
@@ -216,6 +255,73 @@ func (t *Topology) Description() description.Topology {
 	return td
 }
 
+// Snapshot returns a copy of the current description of the topology that is safe to retain and expose outside of
+// the driver. Servers are ordered by address.
+func (t *Topology) Snapshot() event.TopologyDescription {
+	desc := t.Description()
+
+	snapshot := event.TopologyDescription{
+		Kind:    desc.Kind.String(),
+		Servers: make([]event.ServerDescription, 0, len(desc.Servers)),
+	}
+	for _, s := range desc.Servers {
+		snapshot.Servers = append(snapshot.Servers, newServerDescription(s))
+	}
+	sort.Slice(snapshot.Servers, func(i, j int) bool {
+		return snapshot.Servers[i].Address < snapshot.Servers[j].Address
+	})
+	return snapshot
+}
+
+// newServerDescription returns a copy of s for use outside of the driver.
+func newServerDescription(s description.Server) event.ServerDescription {
+	sd := event.ServerDescription{
+		Address:        s.Addr.String(),
+		Kind:           s.Kind.String(),
+		SetName:        s.SetName,
+		LastError:      s.LastError,
+		LastUpdateTime: s.LastUpdateTime,
+	}
+	if len(s.Tags) > 0 {
+		sd.Tags = append(tag.Set(nil), s.Tags...)
+	}
+	if s.AverageRTTSet {
+		sd.AverageRTT = s.AverageRTT
+	}
+	if s.WireVersion != nil {
+		sd.MinWireVersion = s.WireVersion.Min
+		sd.MaxWireVersion = s.WireVersion.Max
+	}
+	return sd
+}
+
+// serverDescriptionChanged returns true if prev and cur differ in anything other than their round trip time and
+// update time.
+func serverDescriptionChanged(prev, cur event.ServerDescription) bool {
+	errString := func(err error) string {
+		if err == nil {
+			return ""
+		}
+		return err.Error()
+	}
+
+	if prev.Kind != cur.Kind || prev.SetName != cur.SetName || errString(prev.LastError) != errString(cur.LastError) {
+		return true
+	}
+	if prev.MinWireVersion != cur.MinWireVersion || prev.MaxWireVersion != cur.MaxWireVersion {
+		return true
+	}
+	if len(prev.Tags) != len(cur.Tags) {
+		return true
+	}
+	for i := range prev.Tags {
+		if prev.Tags[i] != cur.Tags[i] {
+			return true
+		}
+	}
+	return false
+}
+
 // Kind returns the topology kind of this Topology.
 func (t *Topology) Kind() description.TopologyKind { return t.Description().Kind }
 
@@ -282,6 +388,203 @@ func (t *Topology) RequestImmediateCheck() {
 	t.serversLock.Unlock()
 }
 
+// RecycleConnections marks every connection in the topology for replacement and blocks until all of them have been
+// closed or ctx is done. See Server.RecycleConnections for how connections are replaced.
+func (t *Topology) RecycleConnections(ctx context.Context) error {
+	t.serversLock.Lock()
+	servers := make([]*Server, 0, len(t.servers))
+	for _, server := range t.servers {
+		servers = append(servers, server)
+	}
+	t.serversLock.Unlock()
+
+	generations := make([]uint64, len(servers))
+	for i, server := range servers {
+		generations[i] = server.recycle()
+	}
+	for i, server := range servers {
+		if err := server.waitRecycled(ctx, generations[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Suspend stops all heartbeats and SRV polling until Resume is called. It is intended for function-as-a-service
+// environments where the process is frozen between invocations, so that the driver does not act on a burst of
+// heartbeats and timers that fire as soon as the process is thawed. Servers discovered while the topology is suspended
+// are suspended as well.
+func (t *Topology) Suspend() {
+	if !atomic.CompareAndSwapInt32(&t.suspended, 0, 1) {
+		return
+	}
+	t.serversLock.Lock()
+	for _, server := range t.servers {
+		server.Suspend()
+	}
+	t.serversLock.Unlock()
+}
+
+// Resume resumes monitoring stopped by Suspend. Every server is checked immediately, and its connection pool is only
+// cleared if the check fails.
+func (t *Topology) Resume() {
+	if !atomic.CompareAndSwapInt32(&t.suspended, 1, 0) {
+		return
+	}
+	t.serversLock.Lock()
+	for _, server := range t.servers {
+		server.Resume()
+	}
+	t.serversLock.Unlock()
+}
+
+// WarmUp waits for a server matching ss to be available and then blocks until the connection pool of every server
+// matching ss has established at least the minimum number of connections configured with WithMinConnections. It
+// returns the first error encountered, or ctx.Err() if ctx expires first.
+func (t *Topology) WarmUp(ctx context.Context, ss description.ServerSelector) error {
+	if _, err := t.SelectServer(ctx, ss); err != nil {
+		return err
+	}
+
+	desc := t.Description()
+	suitable, err := t.withConfiguredSelector(ss).SelectServer(desc, desc.Servers)
+	if err != nil {
+		return err
+	}
+
+	t.serversLock.Lock()
+	servers := make([]*Server, 0, len(suitable))
+	for _, sd := range suitable {
+		if server, ok := t.servers[sd.Addr]; ok {
+			servers = append(servers, server)
+		}
+	}
+	t.serversLock.Unlock()
+
+	errs := make(chan error, len(servers))
+	for _, server := range servers {
+		go func(server *Server) {
+			errs <- server.WarmUp(ctx)
+		}(server)
+	}
+	for range servers {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// WaitForServers blocks until at least n servers in the topology are suitable for ss, checking the servers again each
+// time the topology description changes. Unlike SelectServer, WaitForServers is not bounded by the server selection
+// timeout and only returns early if ctx is done or ss returns an error.
+func (t *Topology) WaitForServers(ctx context.Context, ss description.ServerSelector, n int) error {
+	if atomic.LoadInt32(&t.connectionstate) != connected {
+		return ErrTopologyClosed
+	}
+
+	sub, err := t.Subscribe()
+	if err != nil {
+		return err
+	}
+	defer t.Unsubscribe(sub)
+
+	// The subscription receives the current description first, so there's no need to check it separately.
+	selectionState := newServerSelectionState(t.withConfiguredSelector(ss), nil)
+	for {
+		var current description.Topology
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case current = <-sub.Updates:
+		}
+
+		suitable, err := t.selectServerFromDescription(ctx, current, selectionState)
+		if err != nil {
+			return err
+		}
+		if len(suitable) >= n {
+			return nil
+		}
+		t.RequestImmediateCheck()
+	}
+}
+
+// RTTStats returns a snapshot of the round trip times measured by the heartbeats of each server in the topology,
+// ordered by server address.
+func (t *Topology) RTTStats() []event.RTTStats {
+	t.serversLock.Lock()
+	defer t.serversLock.Unlock()
+
+	stats := make([]event.RTTStats, 0, len(t.servers))
+	for _, server := range t.servers {
+		stats = append(stats, server.RTTStats())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Address < stats[j].Address })
+	return stats
+}
+
+// RecentErrors returns the most recent errors returned by operations and heartbeats run against each server in the
+// topology, oldest first.
+func (t *Topology) RecentErrors() []event.ServerError {
+	t.serversLock.Lock()
+	var errs []event.ServerError
+	for _, server := range t.servers {
+		errs = append(errs, server.RecentErrors()...)
+	}
+	t.serversLock.Unlock()
+
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Time.Before(errs[j].Time) })
+	return errs
+}
+
+// PoolStats returns a snapshot of the connection pool statistics for each server in the topology, ordered by server
+// address.
+func (t *Topology) PoolStats() []event.PoolStats {
+	t.serversLock.Lock()
+	defer t.serversLock.Unlock()
+
+	stats := make([]event.PoolStats, 0, len(t.servers))
+	for _, server := range t.servers {
+		stats = append(stats, server.PoolStats())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Address < stats[j].Address })
+	return stats
+}
+
+// Tracer returns the tracer configured for the topology, or nil if tracing is disabled. It implements the
+// driver.TracerProvider interface.
+func (t *Topology) Tracer() event.Tracer {
+	return t.cfg.tracer
+}
+
+// OperationProfilerLabels returns true if the goroutines running operations against the topology are labeled. It
+// implements the driver.ProfilerLabeler interface.
+func (t *Topology) OperationProfilerLabels() bool {
+	return t.cfg.opProfilerLabels
+}
+
+// CommandComment returns the comment derived from ctx by the configured comment function, or a zero value if no
+// function is configured. It implements the driver.CommentProvider interface.
+func (t *Topology) CommandComment(ctx context.Context) bsoncore.Value {
+	if t.cfg.commentFn == nil {
+		return bsoncore.Value{}
+	}
+	return t.cfg.commentFn(ctx)
+}
+
+// SlowOperationThreshold returns the duration after which a command is reported to the slow operation monitor. It
+// implements the driver.SlowOperationReporter interface.
+func (t *Topology) SlowOperationThreshold() time.Duration {
+	return t.cfg.slowOpThreshold
+}
+
+// SlowOperationMonitor returns the monitor that is notified of slow operations, or nil if none is configured. It
+// implements the driver.SlowOperationReporter interface.
+func (t *Topology) SlowOperationMonitor() *event.SlowOperationMonitor {
+	return t.cfg.slowOpMonitor
+}
+
 // SupportsSessions returns true if the topology supports sessions.
 func (t *Topology) SupportsSessions() bool {
 	return t.Description().SessionTimeoutMinutes != 0 && t.Description().Kind != description.Single
@@ -299,19 +602,80 @@ func (t *Topology) SelectServer(ctx context.Context, ss description.ServerSelect
 	if atomic.LoadInt32(&t.connectionstate) != connected {
 		return nil, ErrTopologyClosed
 	}
+	ss = t.withConfiguredSelector(ss)
+
+	start := time.Now()
+	monitor := t.cfg.ssMonitor
+	t.logServerSelection(logger.LevelDebug, logger.ServerSelectionStarted, ss)
+	if monitor != nil && monitor.Started != nil {
+		monitor.Started(&event.ServerSelectionStartedEvent{Selector: fmt.Sprintf("%+v", ss)})
+	}
+	selected, candidates, err := t.selectServer(ctx, ss)
+	if err != nil {
+		t.logServerSelection(logger.LevelDebug, logger.ServerSelectionFailed, ss, "failure", err.Error())
+		if monitor != nil && monitor.Failed != nil {
+			monitor.Failed(&event.ServerSelectionFailedEvent{
+				Selector:            fmt.Sprintf("%+v", ss),
+				Failure:             err,
+				Candidates:          serverAddresses(candidates),
+				TopologyDescription: t.String(),
+				Duration:            time.Since(start),
+			})
+		}
+		return nil, err
+	}
+	host, port := logger.SplitAddress(selected.address.String())
+	t.logServerSelection(logger.LevelDebug, logger.ServerSelectionSucceeded, ss, "serverHost", host, "serverPort", port)
+	if monitor != nil && monitor.Succeeded != nil {
+		monitor.Succeeded(&event.ServerSelectionSucceededEvent{
+			Selector:   fmt.Sprintf("%+v", ss),
+			Address:    selected.address.String(),
+			Candidates: serverAddresses(candidates),
+			Duration:   time.Since(start),
+		})
+	}
+	return selected, nil
+}
+
+// serverAddresses returns the addresses of the given servers.
+func serverAddresses(servers []description.Server) []string {
+	addrs := make([]string, 0, len(servers))
+	for _, s := range servers {
+		addrs = append(addrs, s.Addr.String())
+	}
+	return addrs
+}
+
+// logServerSelection logs a server selection message if server selection logging is enabled at the given level.
+func (t *Topology) logServerSelection(level logger.Level, msg string, ss description.ServerSelector,
+	keysAndValues ...interface{}) {
+
+	if !t.cfg.logger.Enabled(logger.ComponentServerSelection, level) {
+		return
+	}
+	kvs := append([]interface{}{"selector", fmt.Sprintf("%+v", ss), "topologyDescription", t.String()}, keysAndValues...)
+	t.cfg.logger.Print(logger.ComponentServerSelection, level, msg, kvs...)
+}
+
+// selectServer selects a server with the given selector. It also returns the servers that were suitable in the last
+// topology description that was checked.
+func (t *Topology) selectServer(ctx context.Context, ss description.ServerSelector) (*SelectedServer,
+	[]description.Server, error) {
+
+	start := t.cfg.wallClock.Now()
 	var ssTimeoutCh <-chan time.Time
 
 	if t.cfg.serverSelectionTimeout > 0 {
-		ssTimeout := time.NewTimer(t.cfg.serverSelectionTimeout)
-		ssTimeoutCh = ssTimeout.C
+		ssTimeout := t.cfg.wallClock.NewTimer(t.cfg.serverSelectionTimeout)
+		ssTimeoutCh = ssTimeout.C()
 		defer ssTimeout.Stop()
 	}
 
 	var doneOnce bool
 	var sub *driver.Subscription
 	selectionState := newServerSelectionState(ss, ssTimeoutCh)
+	var suitable []description.Server
 	for {
-		var suitable []description.Server
 		var selectErr error
 
 		if !doneOnce {
@@ -323,10 +687,24 @@ func (t *Topology) SelectServer(ctx context.Context, ss description.ServerSelect
 			// if the first pass didn't select a server, the previous description did not contain a suitable server, so
 			// we subscribe to the topology and attempt to obtain a server from that subscription
 			if sub == nil {
+				var kvs []interface{}
+				var remaining time.Duration
+				if t.cfg.serverSelectionTimeout > 0 {
+					remaining = t.cfg.serverSelectionTimeout - clock.Since(t.cfg.wallClock, start)
+					kvs = append(kvs, "remainingTimeMS", remaining.Nanoseconds()/int64(time.Millisecond))
+				}
+				t.logServerSelection(logger.LevelInfo, logger.ServerSelectionWaiting, ss, kvs...)
+				if monitor := t.cfg.ssMonitor; monitor != nil && monitor.Waiting != nil {
+					monitor.Waiting(&event.ServerSelectionWaitingEvent{
+						Selector:      fmt.Sprintf("%+v", ss),
+						RemainingTime: remaining,
+					})
+				}
+
 				var err error
 				sub, err = t.Subscribe()
 				if err != nil {
-					return nil, err
+					return nil, suitable, err
 				}
 				defer t.Unsubscribe(sub)
 			}
@@ -334,7 +712,7 @@ func (t *Topology) SelectServer(ctx context.Context, ss description.ServerSelect
 			suitable, selectErr = t.selectServerFromSubscription(ctx, sub.Updates, selectionState)
 		}
 		if selectErr != nil {
-			return nil, selectErr
+			return nil, suitable, selectErr
 		}
 
 		if len(suitable) == 0 {
@@ -342,13 +720,13 @@ func (t *Topology) SelectServer(ctx context.Context, ss description.ServerSelect
 			continue
 		}
 
-		selected := suitable[rand.Intn(len(suitable))]
+		selected := t.pickServer(suitable)
 		selectedS, err := t.FindServer(selected)
 		switch {
 		case err != nil:
-			return nil, err
+			return nil, suitable, err
 		case selectedS != nil:
-			return selectedS, nil
+			return selectedS, suitable, nil
 		default:
 			// We don't have an actual server for the provided description.
 			// This could happen for a number of reasons, including that the
@@ -358,6 +736,59 @@ func (t *Topology) SelectServer(ctx context.Context, ss description.ServerSelect
 	}
 }
 
+// pickServer chooses one of the suitable servers. Mongos servers are chosen according to the configured mongos
+// selection policy, if any. Otherwise, the server is chosen uniformly at random by default. If weighted server
+// selection is enabled, two servers are chosen at random and the one with fewer operations in progress is picked,
+// with ties broken by the lower average round trip time.
+func (t *Topology) pickServer(suitable []description.Server) description.Server {
+	if selected, ok := t.pickMongos(suitable); ok {
+		return selected
+	}
+	if !t.cfg.weightedSelection || len(suitable) < 2 {
+		return suitable[rand.Intn(len(suitable))]
+	}
+
+	i := rand.Intn(len(suitable))
+	j := rand.Intn(len(suitable) - 1)
+	if j >= i {
+		j++
+	}
+	a, b := suitable[i], suitable[j]
+
+	aOps, bOps := t.operationCount(a.Addr), t.operationCount(b.Addr)
+	switch {
+	case aOps < bOps:
+		return a
+	case bOps < aOps:
+		return b
+	case b.AverageRTT < a.AverageRTT:
+		return b
+	default:
+		return a
+	}
+}
+
+// operationCount returns the number of operations that are using or waiting for a connection to the server with the
+// given address.
+func (t *Topology) operationCount(addr address.Address) uint64 {
+	t.serversLock.Lock()
+	server, ok := t.servers[addr]
+	t.serversLock.Unlock()
+	if !ok {
+		return 0
+	}
+	return server.operationCount()
+}
+
+// withConfiguredSelector returns a selector that applies the selector configured with WithServerSelector, if any, to
+// the servers selected by ss.
+func (t *Topology) withConfiguredSelector(ss description.ServerSelector) description.ServerSelector {
+	if t.cfg.serverSelector == nil {
+		return ss
+	}
+	return description.CompositeSelector([]description.ServerSelector{ss, t.cfg.serverSelector})
+}
+
 // SelectServerLegacy selects a server with given a selector. SelectServerLegacy complies with the
 // server selection spec, and will time out after severSelectionTimeout or when the
 // parent context is done.
@@ -365,11 +796,12 @@ func (t *Topology) SelectServerLegacy(ctx context.Context, ss description.Server
 	if atomic.LoadInt32(&t.connectionstate) != connected {
 		return nil, ErrTopologyClosed
 	}
+	ss = t.withConfiguredSelector(ss)
 	var ssTimeoutCh <-chan time.Time
 
 	if t.cfg.serverSelectionTimeout > 0 {
-		ssTimeout := time.NewTimer(t.cfg.serverSelectionTimeout)
-		ssTimeoutCh = ssTimeout.C
+		ssTimeout := t.cfg.wallClock.NewTimer(t.cfg.serverSelectionTimeout)
+		ssTimeoutCh = ssTimeout.C()
 		defer ssTimeout.Stop()
 	}
 
@@ -386,7 +818,7 @@ func (t *Topology) SelectServerLegacy(ctx context.Context, ss description.Server
 			return nil, err
 		}
 
-		selected := suitable[rand.Intn(len(suitable))]
+		selected := t.pickServer(suitable)
 		selectedS, err := t.FindServer(selected)
 		switch {
 		case err != nil:
@@ -511,12 +943,19 @@ func (t *Topology) pollSRVRecords() {
 			doneOnce = true
 			return
 		}
+		if atomic.LoadInt32(&t.suspended) == 1 {
+			continue
+		}
 		topoKind := t.Description().Kind
 		if !(topoKind == description.Unknown || topoKind == description.Sharded) {
 			break
 		}
 
-		parsedHosts, err := t.dnsResolver.ParseHosts(hosts, false)
+		srvName := t.cfg.cs.SRVServiceName
+		if srvName == "" {
+			srvName = dns.DefaultSRVServiceName
+		}
+		parsedHosts, err := t.dnsResolver.ParseHostsWithServiceName(hosts, srvName, false)
 		// DNS problem or no verified hosts returned
 		if err != nil || len(parsedHosts) == 0 {
 			if !t.pollHeartbeatTime.Load().(bool) {
@@ -542,16 +981,38 @@ func (t *Topology) pollSRVRecords() {
 }
 
 func (t *Topology) processSRVResults(parsedHosts []string) bool {
+	// The event is published after serversLock is released so that the monitor can use the topology.
+	var evt *event.SRVHostsChangedEvent
+	defer func() {
+		if evt != nil && t.cfg.topologyMonitor != nil && t.cfg.topologyMonitor.SRVHostsChanged != nil {
+			t.cfg.topologyMonitor.SRVHostsChanged(evt)
+		}
+	}()
+
 	t.serversLock.Lock()
 	defer t.serversLock.Unlock()
 
 	if t.serversClosed {
 		return false
 	}
-	diff := t.fsm.Topology.DiffHostlist(parsedHosts)
+	added, removed := t.updateHostList(parsedHosts, true, t.cfg.cs.SRVMaxHosts)
+	if len(added) > 0 || len(removed) > 0 {
+		evt = &event.SRVHostsChangedEvent{Added: added, Removed: removed}
+	}
+	return true
+}
 
+// updateHostList adds servers for the hosts that are not part of the topology and, if remove is true, removes the
+// servers whose hosts are not in the list. If maxHosts is positive, only enough of the new hosts, chosen at random, are
+// added to bring the number of servers up to maxHosts. It returns the addresses of the servers that were added and
+// removed. serversLock must be held.
+func (t *Topology) updateHostList(hosts []string, remove bool, maxHosts int) (added, removed []string) {
+	diff := t.fsm.Topology.DiffHostlist(hosts)
+	if !remove {
+		diff.Removed = nil
+	}
 	if len(diff.Added) == 0 && len(diff.Removed) == 0 {
-		return true
+		return nil, nil
 	}
 
 	for _, r := range diff.Removed {
@@ -560,6 +1021,7 @@ func (t *Topology) processSRVResults(parsedHosts []string) bool {
 		if !ok {
 			continue
 		}
+		removed = append(removed, addr.String())
 		go func() {
 			cancelCtx, cancel := context.WithCancel(context.Background())
 			cancel()
@@ -568,11 +1030,22 @@ func (t *Topology) processSRVResults(parsedHosts []string) bool {
 		delete(t.servers, addr)
 		t.fsm.removeServerByAddr(addr)
 	}
-	for _, a := range diff.Added {
+	toAdd := diff.Added
+	if maxHosts > 0 {
+		// Only add enough of the new hosts, chosen at random, to bring the number of hosts up to maxHosts.
+		if remaining := maxHosts - len(t.servers); remaining > 0 {
+			toAdd = connstring.SelectSRVHosts(toAdd, remaining)
+		} else {
+			toAdd = nil
+		}
+	}
+	for _, a := range toAdd {
 		addr := address.Address(a).Canonicalize()
 		_ = t.addServer(addr)
 		t.fsm.addServer(addr)
+		added = append(added, addr.String())
 	}
+
 	//store new description
 	newDesc := description.Topology{
 		Kind:                  t.fsm.Kind,
@@ -592,22 +1065,75 @@ func (t *Topology) processSRVResults(parsedHosts []string) bool {
 	}
 	t.subLock.Unlock()
 
-	return true
+	return added, removed
+}
+
+// UpdateHosts merges a new list of hosts into the running topology, for deployments whose membership is managed by a
+// control plane rather than by DNS. Servers are added for hosts that are not part of the topology. For sharded
+// clusters and topologies whose type has not been discovered yet, servers whose hosts are not in the list are removed.
+// The membership of a replica set is determined by its configuration, so no servers are removed from a replica set
+// topology. UpdateHosts returns an error for direct, load balanced, and mongodb+srv topologies.
+func (t *Topology) UpdateHosts(hosts []string) error {
+	if atomic.LoadInt32(&t.connectionstate) != connected {
+		return ErrTopologyClosed
+	}
+	switch {
+	case len(hosts) == 0:
+		return errors.New("at least one host must be specified")
+	case t.cfg.mode == SingleMode || t.cfg.loadBalanced:
+		return errors.New("hosts cannot be updated for a direct or load balanced connection")
+	case srvPollingRequired(t.cfg.cs.Original):
+		return errors.New("hosts cannot be updated for a mongodb+srv connection")
+	}
+
+	t.serversLock.Lock()
+	defer t.serversLock.Unlock()
+	if t.serversClosed {
+		return ErrTopologyClosed
+	}
 
+	remove := t.fsm.Kind == description.Sharded || t.fsm.Kind == description.Unknown
+	t.updateHostList(hosts, remove, 0)
+	return nil
 }
 
 func (t *Topology) apply(ctx context.Context, desc description.Server) {
 	var err error
 
+	// The event is published after serversLock is released so that the monitor can use the topology.
+	var evt *event.ServerDescriptionChangedEvent
+	defer func() {
+		if evt != nil {
+			t.cfg.topologyMonitor.ServerDescriptionChanged(evt)
+		}
+	}()
+
 	t.serversLock.Lock()
 	defer t.serversLock.Unlock()
 
-	if _, ok := t.servers[desc.Addr]; t.serversClosed || !ok {
+	server, ok := t.servers[desc.Addr]
+	if t.serversClosed || !ok {
 		return
 	}
 
 	prev := t.fsm.Topology
 
+	if monitor := t.cfg.topologyMonitor; monitor != nil && monitor.ServerDescriptionChanged != nil {
+		var prevDesc event.ServerDescription
+		if prevServer, found := prev.Server(desc.Addr); found {
+			prevDesc = newServerDescription(prevServer)
+		}
+		newDesc := newServerDescription(desc)
+		if serverDescriptionChanged(prevDesc, newDesc) {
+			evt = &event.ServerDescriptionChangedEvent{
+				Address:             desc.Addr.String(),
+				PreviousDescription: prevDesc,
+				NewDescription:      newDesc,
+				RTT:                 server.RTTStats(),
+			}
+		}
+	}
+
 	current, err := t.fsm.apply(desc)
 	if err != nil {
 		return
@@ -653,10 +1179,17 @@ func (t *Topology) addServer(addr address.Address) error {
 	topoFunc := func(desc description.Server) {
 		t.apply(context.TODO(), desc)
 	}
-	svr, err := ConnectServer(addr, topoFunc, t.cfg.serverOpts...)
+	svr, err := NewServer(addr, t.cfg.serverOpts...)
 	if err != nil {
 		return err
 	}
+	svr.profilerLabels = t.cfg.profilerLabels
+	if atomic.LoadInt32(&t.suspended) == 1 {
+		svr.Suspend()
+	}
+	if err = svr.Connect(topoFunc); err != nil {
+		return err
+	}
 
 	t.servers[addr] = svr
 
@@ -8,6 +8,7 @@ package topology
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
@@ -17,9 +18,15 @@ import (
 	"strings"
 	"time"
 
+	"go.mongodb.org/mongo-driver/clock"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/internal/logger"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 	"go.mongodb.org/mongo-driver/x/mongo/driver"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/auth"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/description"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/dns"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/operation"
 )
 
@@ -33,12 +40,32 @@ type config struct {
 	serverOpts             []ServerOption
 	cs                     connstring.ConnString
 	serverSelectionTimeout time.Duration
+	serverSelector         description.ServerSelector
+	ssMonitor              *event.ServerSelectionMonitor
+	weightedSelection      bool
+	mongosSelection        string
+	mongosRebalance        time.Duration
+	srvPollingInterval     time.Duration
+	topologyMonitor        *event.TopologyMonitor
+	tracer                 event.Tracer
+	logger                 *logger.Logger
+	slowOpThreshold        time.Duration
+	slowOpMonitor          *event.SlowOperationMonitor
+	commentFn              func(context.Context) bsoncore.Value
+	profilerLabels         bool
+	opProfilerLabels       bool
+	loadBalanced           bool
+	dnsResolver            *dns.Resolver
+	wallClock              clock.Clock
 }
 
 func newConfig(opts ...Option) (*config, error) {
 	cfg := &config{
 		seedList:               []string{"localhost:27017"},
 		serverSelectionTimeout: 30 * time.Second,
+		srvPollingInterval:     60 * time.Second,
+		dnsResolver:            dns.DefaultResolver,
+		wallClock:              clock.System,
 	}
 
 	for _, opt := range opts {
@@ -48,6 +75,12 @@ func newConfig(opts ...Option) (*config, error) {
 		}
 	}
 
+	switch cfg.mongosSelection {
+	case "", MongosSelectionRandom, MongosSelectionRoundRobin, MongosSelectionLeastOperations:
+	default:
+		return nil, fmt.Errorf("unknown mongos selection policy %q", cfg.mongosSelection)
+	}
+
 	return cfg, nil
 }
 
@@ -96,6 +129,10 @@ func WithConnString(fn func(connstring.ConnString) connstring.ConnString) Option
 			connOpts = append(connOpts, WithIdleTimeout(func(time.Duration) time.Duration { return cs.MaxConnIdleTime }))
 		}
 
+		if cs.MaxConnectingSet {
+			c.serverOpts = append(c.serverOpts, WithMaxConnecting(func(uint64) uint64 { return cs.MaxConnecting }))
+		}
+
 		if cs.MaxPoolSizeSet {
 			c.serverOpts = append(c.serverOpts, WithMaxConnections(func(uint64) uint64 { return cs.MaxPoolSize }))
 		}
@@ -108,6 +145,10 @@ func WithConnString(fn func(connstring.ConnString) connstring.ConnString) Option
 			c.replicaSetName = cs.ReplicaSet
 		}
 
+		if cs.LoadBalancedSet {
+			c.loadBalanced = cs.LoadBalanced
+		}
+
 		var x509Username string
 		if cs.SSL {
 			tlsConfig := new(tls.Config)
@@ -186,6 +227,7 @@ func WithConnString(fn func(connstring.ConnString) connstring.ConnString) Option
 					AppName:       cs.AppName,
 					Authenticator: authenticator,
 					Compressors:   cs.Compressors,
+					LoadBalanced:  cs.LoadBalanced,
 				}
 				if cs.AuthMechanism == "" {
 					// Required for SASL mechanism negotiation during handshake
@@ -196,7 +238,7 @@ func WithConnString(fn func(connstring.ConnString) connstring.ConnString) Option
 		} else {
 			// We need to add a non-auth Handshaker to the connection options
 			connOpts = append(connOpts, WithHandshaker(func(h driver.Handshaker) driver.Handshaker {
-				return operation.NewIsMaster().AppName(cs.AppName).Compressors(cs.Compressors)
+				return operation.NewIsMaster().AppName(cs.AppName).Compressors(cs.Compressors).LoadBalanced(cs.LoadBalanced)
 			}))
 		}
 
@@ -233,6 +275,16 @@ func WithConnString(fn func(connstring.ConnString) connstring.ConnString) Option
 	}
 }
 
+// WithLoadBalanced configures whether or not the topology is a single server behind a load balancer. Servers behind a
+// load balancer are not monitored, and connections are pinned to cursors and transactions so that their commands are
+// sent to the same backend server.
+func WithLoadBalanced(fn func(bool) bool) Option {
+	return func(cfg *config) error {
+		cfg.loadBalanced = fn(cfg.loadBalanced)
+		return nil
+	}
+}
+
 // WithMode configures the topology's monitor mode.
 func WithMode(fn func(MonitorMode) MonitorMode) Option {
 	return func(cfg *config) error {
@@ -275,6 +327,153 @@ func WithServerSelectionTimeout(fn func(time.Duration) time.Duration) Option {
 	}
 }
 
+// WithDNSResolver configures the resolver used to poll the SRV records of a "mongodb+srv" connection string.
+func WithDNSResolver(fn func(*dns.Resolver) *dns.Resolver) Option {
+	return func(cfg *config) error {
+		cfg.dnsResolver = fn(cfg.dnsResolver)
+		return nil
+	}
+}
+
+// WithServerSelector configures a server selector that is applied to the servers chosen by the selector passed to
+// SelectServer or SelectServerLegacy.
+func WithServerSelector(fn func(description.ServerSelector) description.ServerSelector) Option {
+	return func(cfg *config) error {
+		cfg.serverSelector = fn(cfg.serverSelector)
+		return nil
+	}
+}
+
+// WithSRVPollingInterval configures how often the SRV records of a "mongodb+srv" connection string are polled to
+// discover hosts that have been added to or removed from the deployment. Polling is only done when the topology is
+// sharded or unknown. The interval must be positive.
+func WithSRVPollingInterval(fn func(time.Duration) time.Duration) Option {
+	return func(cfg *config) error {
+		interval := fn(cfg.srvPollingInterval)
+		if interval <= 0 {
+			return fmt.Errorf("SRV polling interval must be positive, got %v", interval)
+		}
+		cfg.srvPollingInterval = interval
+		return nil
+	}
+}
+
+// WithTopologyMonitor configures the monitor that is notified of changes to a topology.
+func WithTopologyMonitor(fn func(*event.TopologyMonitor) *event.TopologyMonitor) Option {
+	return func(cfg *config) error {
+		cfg.topologyMonitor = fn(cfg.topologyMonitor)
+		return nil
+	}
+}
+
+// WithWeightedServerSelection configures whether a topology chooses among suitable servers using the power of two
+// choices, preferring the server with fewer operations in progress, instead of uniformly at random.
+func WithWeightedServerSelection(fn func(bool) bool) Option {
+	return func(cfg *config) error {
+		cfg.weightedSelection = fn(cfg.weightedSelection)
+		return nil
+	}
+}
+
+// WithMongosSelection configures how a topology chooses among suitable mongos servers in a sharded deployment. Because
+// transactions and cursors stay pinned to the mongos that ran their first operation, this also determines how they are
+// spread across mongos servers. The policy must be one of MongosSelectionRandom (the default),
+// MongosSelectionRoundRobin, or MongosSelectionLeastOperations.
+func WithMongosSelection(fn func(string) string) Option {
+	return func(cfg *config) error {
+		cfg.mongosSelection = fn(cfg.mongosSelection)
+		return nil
+	}
+}
+
+// WithMongosRebalanceInterval configures how often a topology rebalances connections across the mongos servers of a
+// sharded deployment. On each rebalance, idle connections to a mongos that holds more than its share of the open
+// connections are closed, so that connections are spread evenly again after a mongos was added or restarted, for
+// example during a deploy. If the interval is 0, connections are not rebalanced.
+func WithMongosRebalanceInterval(fn func(time.Duration) time.Duration) Option {
+	return func(cfg *config) error {
+		cfg.mongosRebalance = fn(cfg.mongosRebalance)
+		return nil
+	}
+}
+
+// WithTracer configures the tracer used to create a span for each operation run against a topology.
+func WithTracer(fn func(event.Tracer) event.Tracer) Option {
+	return func(cfg *config) error {
+		cfg.tracer = fn(cfg.tracer)
+		return nil
+	}
+}
+
+// WithWallClock configures the Clock used for the server selection timeout of a topology. The Clock of its servers is
+// configured with WithServerWallClock.
+func WithWallClock(fn func(clock.Clock) clock.Clock) Option {
+	return func(cfg *config) error {
+		cfg.wallClock = clock.OrSystem(fn(cfg.wallClock))
+		return nil
+	}
+}
+
+// WithLogger configures the logger used to log server selection for a topology.
+func WithLogger(fn func(*logger.Logger) *logger.Logger) Option {
+	return func(cfg *config) error {
+		cfg.logger = fn(cfg.logger)
+		return nil
+	}
+}
+
+// WithSlowOperationThreshold configures the duration after which a command run against a topology is reported to the
+// slow operation monitor. If the threshold is 0, slow operations are not reported.
+func WithSlowOperationThreshold(fn func(time.Duration) time.Duration) Option {
+	return func(cfg *config) error {
+		cfg.slowOpThreshold = fn(cfg.slowOpThreshold)
+		return nil
+	}
+}
+
+// WithProfilerLabels configures whether the background goroutines of a topology, such as server monitors, are labeled
+// with pprof labels naming the driver component and server address.
+func WithProfilerLabels(fn func(bool) bool) Option {
+	return func(cfg *config) error {
+		cfg.profilerLabels = fn(cfg.profilerLabels)
+		return nil
+	}
+}
+
+// WithOperationProfilerLabels configures whether the goroutines running operations against a topology are labeled with
+// pprof labels naming the command and namespace while each command runs.
+func WithOperationProfilerLabels(fn func(bool) bool) Option {
+	return func(cfg *config) error {
+		cfg.opProfilerLabels = fn(cfg.opProfilerLabels)
+		return nil
+	}
+}
+
+// WithCommentFunc configures the function used to derive a comment from the context of each command run against a
+// topology. A returned value with a zero type means the command is sent without a comment.
+func WithCommentFunc(fn func(func(context.Context) bsoncore.Value) func(context.Context) bsoncore.Value) Option {
+	return func(cfg *config) error {
+		cfg.commentFn = fn(cfg.commentFn)
+		return nil
+	}
+}
+
+// WithServerSelectionMonitor configures the monitor that is notified when operations select servers.
+func WithServerSelectionMonitor(fn func(*event.ServerSelectionMonitor) *event.ServerSelectionMonitor) Option {
+	return func(cfg *config) error {
+		cfg.ssMonitor = fn(cfg.ssMonitor)
+		return nil
+	}
+}
+
+// WithSlowOperationMonitor configures the monitor that is notified of slow operations run against a topology.
+func WithSlowOperationMonitor(fn func(*event.SlowOperationMonitor) *event.SlowOperationMonitor) Option {
+	return func(cfg *config) error {
+		cfg.slowOpMonitor = fn(cfg.slowOpMonitor)
+		return nil
+	}
+}
+
 // addCACertFromFile adds a root CA certificate to the configuration given a path
 // to the containing file.
 func addCACertFromFile(cfg *tls.Config, file string) error {
@@ -9,11 +9,18 @@ package topology
 import (
 	"context"
 	"errors"
+	"reflect"
+	"sort"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"go.mongodb.org/mongo-driver/clock"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/internal/testutil/assert"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/tag"
 	"go.mongodb.org/mongo-driver/x/mongo/driver"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/address"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
@@ -200,6 +207,33 @@ func TestServerSelection(t *testing.T) {
 			t.Fatalf("did not receive error from server selection")
 		}
 	})
+	t.Run("timeout uses the wall clock", func(t *testing.T) {
+		fake := clock.NewFake(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+		topo, err := New(
+			WithWallClock(func(clock.Clock) clock.Clock { return fake }),
+			WithServerSelectionTimeout(func(time.Duration) time.Duration { return 30 * time.Second }),
+		)
+		noerr(t, err)
+		atomic.StoreInt32(&topo.connectionstate, connected)
+
+		resp := make(chan error, 1)
+		go func() {
+			_, err := topo.SelectServer(context.Background(), selectNone)
+			resp <- err
+		}()
+		// the server selection timer
+		fake.BlockUntil(1)
+		fake.Advance(30 * time.Second)
+
+		select {
+		case err = <-resp:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for server selection to time out")
+		}
+		assert.NotNil(t, err, "expected server selection error, got nil")
+		assert.True(t, strings.Contains(err.Error(), ErrServerSelectionTimeout.Error()),
+			"expected a server selection timeout, got %v", err)
+	})
 	t.Run("Error", func(t *testing.T) {
 		desc := description.Topology{
 			Servers: []description.Server{
@@ -289,7 +323,7 @@ func TestServerSelection(t *testing.T) {
 		serv, err := topo.FindServer(desc.Servers[0])
 		noerr(t, err)
 		atomic.StoreInt32(&serv.connectionstate, connected)
-		serv.ProcessError(driver.Error{Message: "not master"})
+		serv.ProcessError(driver.Error{Message: "not master"}, nil)
 
 		resp := make(chan []description.Server)
 
@@ -342,6 +376,187 @@ func TestServerSelection(t *testing.T) {
 		selectedAddr := selectedServer.(*SelectedServer).address
 		assert.Equal(t, primaryAddr, selectedAddr, "expected address %v, got %v", primaryAddr, selectedAddr)
 	})
+	t.Run("configured selector", func(t *testing.T) {
+		var candidates []description.Server
+		var selectLast description.ServerSelectorFunc = func(_ description.Topology, servers []description.Server) ([]description.Server, error) {
+			candidates = servers
+			if len(servers) == 0 {
+				return servers, nil
+			}
+			return servers[len(servers)-1:], nil
+		}
+		topo, err := New(WithServerSelector(func(description.ServerSelector) description.ServerSelector { return selectLast }))
+		noerr(t, err)
+		topo.cfg.cs.HeartbeatInterval = time.Minute
+		atomic.StoreInt32(&topo.connectionstate, connected)
+
+		desc := description.Topology{
+			Kind: description.ReplicaSetWithPrimary,
+			Servers: []description.Server{
+				{Addr: address.Address("one"), Kind: description.RSPrimary},
+				{Addr: address.Address("two"), Kind: description.RSSecondary},
+				{Addr: address.Address("three"), Kind: description.RSSecondary},
+			},
+		}
+		topo.desc.Store(desc)
+		for _, srv := range desc.Servers {
+			s, err := ConnectServer(srv.Addr, func(desc description.Server) { topo.apply(context.Background(), desc) })
+			noerr(t, err)
+			topo.servers[srv.Addr] = s
+		}
+
+		topo.subscriptionsClosed = true
+		selectedServer, err := topo.SelectServer(context.Background(), description.ReadPrefSelector(readpref.Secondary()))
+		noerr(t, err)
+		selectedAddr := selectedServer.(*SelectedServer).address
+		assert.Equal(t, address.Address("three"), selectedAddr, "expected address %v, got %v", "three", selectedAddr)
+		assert.Equal(t, 2, len(candidates), "expected the configured selector to receive 2 secondaries, got %v", candidates)
+	})
+	t.Run("monitor", func(t *testing.T) {
+		var started []*event.ServerSelectionStartedEvent
+		var waiting []*event.ServerSelectionWaitingEvent
+		var succeeded []*event.ServerSelectionSucceededEvent
+		var failed []*event.ServerSelectionFailedEvent
+		monitor := &event.ServerSelectionMonitor{
+			Started:   func(evt *event.ServerSelectionStartedEvent) { started = append(started, evt) },
+			Waiting:   func(evt *event.ServerSelectionWaitingEvent) { waiting = append(waiting, evt) },
+			Succeeded: func(evt *event.ServerSelectionSucceededEvent) { succeeded = append(succeeded, evt) },
+			Failed:    func(evt *event.ServerSelectionFailedEvent) { failed = append(failed, evt) },
+		}
+		topo, err := New(
+			WithServerSelectionMonitor(func(*event.ServerSelectionMonitor) *event.ServerSelectionMonitor { return monitor }),
+			WithServerSelectionTimeout(func(time.Duration) time.Duration { return time.Minute }),
+		)
+		noerr(t, err)
+		atomic.StoreInt32(&topo.connectionstate, connected)
+
+		desc := description.Topology{
+			Kind: description.ReplicaSetWithPrimary,
+			Servers: []description.Server{
+				{Addr: address.Address("one"), Kind: description.RSPrimary},
+				{Addr: address.Address("two"), Kind: description.RSSecondary},
+				{Addr: address.Address("three"), Kind: description.RSSecondary},
+			},
+		}
+		topo.desc.Store(desc)
+		for _, srv := range desc.Servers {
+			s, err := ConnectServer(srv.Addr, func(desc description.Server) { topo.apply(context.Background(), desc) })
+			noerr(t, err)
+			topo.servers[srv.Addr] = s
+		}
+		// manually close subscriptions so a selection that has to wait fails
+		topo.subscriptionsClosed = true
+
+		selected, err := topo.SelectServer(context.Background(), description.ReadPrefSelector(readpref.Secondary()))
+		noerr(t, err)
+		assert.Equal(t, 1, len(started), "expected 1 started event, got %d", len(started))
+		assert.Equal(t, 1, len(succeeded), "expected 1 succeeded event, got %d", len(succeeded))
+		evt := succeeded[0]
+		assert.Equal(t, selected.(*SelectedServer).address.String(), evt.Address, "unexpected address")
+		assert.Equal(t, []string{"two:27017", "three:27017"}, evt.Candidates, "unexpected candidates")
+		assert.Equal(t, started[0].Selector, evt.Selector, "expected the same selector description")
+
+		_, err = topo.SelectServer(context.Background(), selectNone)
+		assert.NotNil(t, err, "expected error, got nil")
+		assert.Equal(t, 1, len(waiting), "expected 1 waiting event, got %d", len(waiting))
+		assert.True(t, waiting[0].RemainingTime > 0, "expected remaining time, got %v", waiting[0].RemainingTime)
+		assert.Equal(t, 1, len(failed), "expected 1 failed event, got %d", len(failed))
+		assert.Equal(t, err, failed[0].Failure, "unexpected failure")
+		assert.Equal(t, 0, len(failed[0].Candidates), "expected no candidates, got %v", failed[0].Candidates)
+		assert.True(t, failed[0].TopologyDescription != "", "expected topology description")
+	})
+}
+
+func TestWeightedServerSelection(t *testing.T) {
+	topo, err := New(WithWeightedServerSelection(func(bool) bool { return true }))
+	noerr(t, err)
+
+	suitable := []description.Server{
+		{Addr: address.Address("busy"), AverageRTT: time.Millisecond},
+		{Addr: address.Address("idle"), AverageRTT: 10 * time.Millisecond},
+	}
+	for _, desc := range suitable {
+		s, err := NewServer(desc.Addr)
+		noerr(t, err)
+		topo.servers[desc.Addr] = s
+	}
+
+	// with equal operation counts, the server with the lower RTT is picked.
+	for i := 0; i < 10; i++ {
+		picked := topo.pickServer(suitable)
+		assert.Equal(t, address.Address("busy"), picked.Addr, "expected server %v, got %v", "busy", picked.Addr)
+	}
+
+	atomic.StoreUint64(&topo.servers["busy"].pool.stats.inUse, 5)
+	for i := 0; i < 10; i++ {
+		picked := topo.pickServer(suitable)
+		assert.Equal(t, address.Address("idle"), picked.Addr, "expected server %v, got %v", "idle", picked.Addr)
+	}
+}
+
+func TestMongosSelection(t *testing.T) {
+	newTopology := func(t *testing.T, policy string) (*Topology, []description.Server) {
+		topo, err := New(WithMongosSelection(func(string) string { return policy }))
+		noerr(t, err)
+
+		suitable := []description.Server{
+			{Addr: address.Address("c"), Kind: description.Mongos},
+			{Addr: address.Address("a"), Kind: description.Mongos},
+			{Addr: address.Address("b"), Kind: description.Mongos},
+		}
+		for _, desc := range suitable {
+			s, err := NewServer(desc.Addr)
+			noerr(t, err)
+			topo.servers[desc.Addr] = s
+		}
+		return topo, suitable
+	}
+
+	t.Run("round robin", func(t *testing.T) {
+		topo, suitable := newTopology(t, MongosSelectionRoundRobin)
+		for _, want := range []address.Address{"a", "b", "c", "a"} {
+			picked := topo.pickServer(suitable)
+			assert.Equal(t, want, picked.Addr, "expected server %v, got %v", want, picked.Addr)
+		}
+	})
+	t.Run("least operations", func(t *testing.T) {
+		topo, suitable := newTopology(t, MongosSelectionLeastOperations)
+		atomic.StoreUint64(&topo.servers["a"].pool.stats.inUse, 2)
+		atomic.StoreUint64(&topo.servers["c"].pool.stats.inUse, 1)
+		picked := topo.pickServer(suitable)
+		assert.Equal(t, address.Address("b"), picked.Addr, "expected server %v, got %v", "b", picked.Addr)
+	})
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := New(WithMongosSelection(func(string) string { return "fastest" }))
+		assert.NotNil(t, err, "expected error for unknown mongos selection policy, got nil")
+	})
+}
+
+func TestRebalanceConnections(t *testing.T) {
+	newMongos := func(addr string, idle int, opts ...ServerOption) *Server {
+		s, err := NewServer(address.Address(addr), opts...)
+		noerr(t, err)
+		atomic.StoreInt32(&s.pool.connected, connected)
+		for i := 0; i < idle; i++ {
+			s.pool.conns.Put(&connection{pool: s.pool, poolID: uint64(i), connected: initialized})
+		}
+		return s
+	}
+
+	hot, cold := newMongos("hot", 6), newMongos("cold", 0)
+	rebalanceConnections([]*Server{hot, cold})
+	assert.Equal(t, uint64(3), hot.PoolStats().Idle, "expected excess idle connections to be closed")
+	assert.Equal(t, uint64(0), cold.PoolStats().Idle, "expected no connections to be opened")
+
+	rebalanceConnections([]*Server{hot, cold})
+	assert.Equal(t, uint64(2), hot.PoolStats().Idle, "expected connections to converge to an even share")
+
+	minPoolSize := WithMinConnections(func(uint64) uint64 { return 4 })
+	hot, cold = newMongos("hot", 6, minPoolSize), newMongos("cold", 0, minPoolSize)
+	rebalanceConnections([]*Server{hot, cold})
+	assert.Equal(t, uint64(4), hot.PoolStats().Idle, "expected the minimum pool size to be kept")
+	rebalanceConnections([]*Server{hot, cold})
+	assert.Equal(t, uint64(4), hot.PoolStats().Idle, "expected no connections below the minimum pool size to be closed")
 }
 
 func TestSessionTimeout(t *testing.T) {
@@ -514,3 +729,224 @@ func TestTopology_String_Race(t *testing.T) {
 	<-ch
 	<-ch
 }
+
+func TestTopologySnapshot(t *testing.T) {
+	topo := &Topology{}
+	tags := tag.Set{{Name: "dc", Value: "east"}}
+	lastErr := errors.New("connection refused")
+	topo.desc.Store(description.Topology{
+		Kind: description.ReplicaSetWithPrimary,
+		Servers: []description.Server{
+			{
+				Addr:          address.Address("b:27017"),
+				Kind:          description.RSSecondary,
+				SetName:       "rs0",
+				Tags:          tags,
+				AverageRTT:    5 * time.Millisecond,
+				AverageRTTSet: true,
+				WireVersion:   &description.VersionRange{Min: 0, Max: 9},
+			},
+			{
+				Addr:      address.Address("a:27017"),
+				Kind:      description.Unknown,
+				LastError: lastErr,
+			},
+		},
+	})
+
+	got := topo.Snapshot()
+	want := event.TopologyDescription{
+		Kind: "ReplicaSetWithPrimary",
+		Servers: []event.ServerDescription{
+			{Address: "a:27017", Kind: "Unknown", LastError: lastErr},
+			{
+				Address:        "b:27017",
+				Kind:           "RSSecondary",
+				SetName:        "rs0",
+				Tags:           tags,
+				AverageRTT:     5 * time.Millisecond,
+				MaxWireVersion: 9,
+			},
+		},
+	}
+	assert.True(t, reflect.DeepEqual(want, got), "expected snapshot %v, got %v", want, got)
+
+	got.Servers[1].Tags[0].Value = "west"
+	assert.Equal(t, "east", tags[0].Value, "modifying the snapshot should not modify the topology description")
+
+	empty := (&Topology{}).Snapshot()
+	assert.Equal(t, "Unknown", empty.Kind, "expected kind Unknown for an unconnected topology, got %v", empty.Kind)
+	assert.Equal(t, 0, len(empty.Servers), "expected no servers for an unconnected topology, got %v", empty.Servers)
+}
+
+func TestWaitForServers(t *testing.T) {
+	secondary := func(addr string) description.Server {
+		return description.Server{Addr: address.Address(addr), Kind: description.RSSecondary}
+	}
+	publish := func(topo *Topology, desc description.Topology) {
+		topo.desc.Store(desc)
+		topo.subLock.Lock()
+		defer topo.subLock.Unlock()
+		for _, ch := range topo.subscribers {
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- desc
+		}
+	}
+
+	t.Run("returns once enough servers are suitable", func(t *testing.T) {
+		topo, err := New()
+		noerr(t, err)
+		atomic.StoreInt32(&topo.connectionstate, connected)
+		topo.desc.Store(description.Topology{
+			Kind:    description.ReplicaSetNoPrimary,
+			Servers: []description.Server{secondary("one")},
+		})
+
+		errs := make(chan error, 1)
+		go func() {
+			errs <- topo.WaitForServers(context.Background(), description.ReadPrefSelector(readpref.Nearest()), 2)
+		}()
+
+		deadline := time.Now().Add(testTimeout)
+		for {
+			topo.subLock.Lock()
+			n := len(topo.subscribers)
+			topo.subLock.Unlock()
+			if n > 0 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for WaitForServers to subscribe to the topology")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		select {
+		case err = <-errs:
+			t.Fatalf("WaitForServers returned early with error %v", err)
+		default:
+		}
+
+		publish(topo, description.Topology{
+			Kind:    description.ReplicaSetNoPrimary,
+			Servers: []description.Server{secondary("one"), secondary("two")},
+		})
+		select {
+		case err = <-errs:
+			assert.Nil(t, err, "WaitForServers error: %v", err)
+		case <-time.After(testTimeout):
+			t.Fatalf("timed out waiting for WaitForServers to return")
+		}
+	})
+	t.Run("returns context error", func(t *testing.T) {
+		topo, err := New()
+		noerr(t, err)
+		atomic.StoreInt32(&topo.connectionstate, connected)
+		topo.desc.Store(description.Topology{
+			Kind:    description.ReplicaSetNoPrimary,
+			Servers: []description.Server{secondary("one")},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err = topo.WaitForServers(ctx, description.ReadPrefSelector(readpref.Primary()), 1)
+		assert.Equal(t, context.DeadlineExceeded, err, "expected error %v, got %v", context.DeadlineExceeded, err)
+	})
+}
+
+func TestServerDescriptionChangedEvent(t *testing.T) {
+	var events []*event.ServerDescriptionChangedEvent
+	monitor := &event.TopologyMonitor{
+		ServerDescriptionChanged: func(evt *event.ServerDescriptionChangedEvent) {
+			events = append(events, evt)
+		},
+	}
+	topo, err := New(WithTopologyMonitor(func(*event.TopologyMonitor) *event.TopologyMonitor { return monitor }))
+	noerr(t, err)
+	atomic.StoreInt32(&topo.connectionstate, connected)
+
+	addr := address.Address("one:27017")
+	topo.fsm.Servers = []description.Server{{Addr: addr}}
+	s, err := ConnectServer(addr, nil)
+	noerr(t, err)
+	s.updateAverageRTT(5 * time.Millisecond)
+	topo.servers[addr] = s
+
+	standalone := description.Server{
+		Addr:          addr,
+		Kind:          description.Standalone,
+		AverageRTT:    5 * time.Millisecond,
+		AverageRTTSet: true,
+		WireVersion:   &description.VersionRange{Min: 0, Max: 9},
+	}
+	topo.apply(context.Background(), standalone)
+	assert.Equal(t, 1, len(events), "expected 1 event, got %v", len(events))
+	evt := events[0]
+	assert.Equal(t, addr.String(), evt.Address, "expected address %v, got %v", addr, evt.Address)
+	assert.Equal(t, "Unknown", evt.PreviousDescription.Kind, "expected previous kind Unknown, got %v",
+		evt.PreviousDescription.Kind)
+	assert.Equal(t, "Standalone", evt.NewDescription.Kind, "expected new kind Standalone, got %v",
+		evt.NewDescription.Kind)
+	assert.Equal(t, 5*time.Millisecond, evt.RTT.Current, "expected current RTT 5ms, got %v", evt.RTT.Current)
+
+	// A change to only the round trip time should not publish an event.
+	standalone.AverageRTT = 10 * time.Millisecond
+	topo.apply(context.Background(), standalone)
+	assert.Equal(t, 1, len(events), "expected no event for an RTT change, got %v events", len(events))
+}
+
+func TestUpdateHosts(t *testing.T) {
+	hostsOf := func(topo *Topology) []string {
+		var hosts []string
+		for _, s := range topo.Description().Servers {
+			hosts = append(hosts, s.Addr.String())
+		}
+		sort.Strings(hosts)
+		return hosts
+	}
+	newTopology := func(t *testing.T, opts ...Option) *Topology {
+		opts = append(opts,
+			WithSeedList(func(...string) []string { return []string{"a:27017", "b:27017"} }),
+			WithServerOptions(func(opts ...ServerOption) []ServerOption {
+				return append(opts, WithHeartbeatInterval(func(time.Duration) time.Duration { return time.Minute }))
+			}),
+		)
+		topo, err := New(opts...)
+		noerr(t, err)
+		noerr(t, topo.Connect())
+		return topo
+	}
+
+	t.Run("adds and removes hosts", func(t *testing.T) {
+		topo := newTopology(t)
+		defer func() { _ = topo.Disconnect(context.Background()) }()
+
+		err := topo.UpdateHosts([]string{"b:27017", "c:27017"})
+		noerr(t, err)
+		assert.Equal(t, []string{"b:27017", "c:27017"}, hostsOf(topo), "unexpected hosts after update")
+		_, ok := topo.servers[address.Address("c:27017")]
+		assert.True(t, ok, "expected a server to be created for the added host")
+	})
+	t.Run("does not remove replica set members", func(t *testing.T) {
+		topo := newTopology(t)
+		defer func() { _ = topo.Disconnect(context.Background()) }()
+		topo.serversLock.Lock()
+		topo.fsm.Kind = description.ReplicaSetNoPrimary
+		topo.serversLock.Unlock()
+
+		err := topo.UpdateHosts([]string{"c:27017"})
+		noerr(t, err)
+		assert.Equal(t, []string{"a:27017", "b:27017", "c:27017"}, hostsOf(topo), "unexpected hosts after update")
+	})
+	t.Run("errors", func(t *testing.T) {
+		topo := newTopology(t, WithMode(func(MonitorMode) MonitorMode { return SingleMode }))
+		err := topo.UpdateHosts([]string{"c:27017"})
+		assert.NotNil(t, err, "expected error for a direct connection, got nil")
+
+		_ = topo.Disconnect(context.Background())
+		err = topo.UpdateHosts([]string{"c:27017"})
+		assert.Equal(t, ErrTopologyClosed, err, "expected error %v, got %v", ErrTopologyClosed, err)
+	})
+}
@@ -182,6 +182,22 @@ const (
 	CompressorZstd
 )
 
+// String implements the fmt.Stringer interface.
+func (id CompressorID) String() string {
+	switch id {
+	case CompressorNoOp:
+		return "noop"
+	case CompressorSnappy:
+		return "snappy"
+	case CompressorZLib:
+		return "zlib"
+	case CompressorZstd:
+		return "zstd"
+	default:
+		return "<unknown>"
+	}
+}
+
 const (
 	// DefaultZlibLevel is the default level for zlib compression
 	DefaultZlibLevel = 6